@@ -0,0 +1,134 @@
+package accesslog
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+// The access log is written to the event log, in the same layout as the Java Traffic Router's access.log.
+
+// ResultType is how a request was routed, the access log 'rtype'.
+type ResultType string
+
+const (
+	ResultCZ          = ResultType("CZ")
+	ResultGeo         = ResultType("GEO")
+	ResultMiss        = ResultType("MISS")
+	ResultStaticRoute = ResultType("STATIC_ROUTE")
+	ResultDSRedirect  = ResultType("DS_REDIRECT")
+	ResultDSMiss      = ResultType("DS_MISS")
+	ResultError       = ResultType("ERROR")
+	ResultGeoRedirect = ResultType("GEO_REDIRECT")
+)
+
+// ResultDetails is why a request was routed as it was, the access log 'rdtl'.
+type ResultDetails string
+
+const (
+	DetailsNone                   = ResultDetails("-")
+	DetailsDSNotFound             = ResultDetails("DS_NOT_FOUND")
+	DetailsDSCZOnly               = ResultDetails("DS_CZ_ONLY")
+	DetailsDSClientGeoUnsupported = ResultDetails("DS_CLIENT_GEO_UNSUPPORTED")
+	DetailsGeoNoCacheFound        = ResultDetails("GEO_NO_CACHE_FOUND")
+)
+
+// Result is the routing result of a request, common to HTTP and DNS.
+type Result struct {
+	Type    ResultType
+	Details ResultDetails
+	// Location is the client location used to route, or nil if the client wasn't located.
+	Location *tc.CRConfigLatitudeLongitude
+	// Err is the error routing the request, if any.
+	Err string
+}
+
+// HTTP is an access log record of an HTTP request.
+type HTTP struct {
+	Start      time.Time
+	ClientIP   net.IP
+	ResolverIP net.IP
+	URL        string
+	Method     string
+	Proto      string
+	Result
+	Status      int
+	RedirectURL string
+}
+
+// DNS is an access log record of a DNS query.
+type DNS struct {
+	Start    time.Time
+	ClientIP net.IP
+	ID       uint16
+	FQDN     string
+	Type     string
+	Class    string
+	RCode    string
+	Result
+	Answers []string
+}
+
+// WriteHTTP writes the given HTTP record to the access log. The time is when the request started.
+func WriteHTTP(r HTTP) {
+	log.Eventf(r.Start, "qtype=HTTP chi=%s rhi=%s url=\"%s\" cqhm=%s cqhv=%s rtype=%s rloc=\"%s\" rdtl=%s rerr=\"%s\" rgb=\"-\" pssc=%d ttms=%.3f rurl=\"%s\" rh=\"-\"\n",
+		ipStr(r.ClientIP), ipStr(r.ResolverIP), r.URL, r.Method, r.Proto, r.Type, locStr(r.Location), r.Details, dash(r.Err), r.Status, msSince(r.Start), dash(r.RedirectURL))
+}
+
+// WriteDNS writes the given DNS record to the access log. The time is when the query was received.
+func WriteDNS(r DNS) {
+	ans := "-"
+	if len(r.Answers) > 0 {
+		ans = strings.Join(r.Answers, " ")
+	}
+	log.Eventf(r.Start, "qtype=DNS chi=%s rhi=- ttms=%.3f xn=%d fqdn=%s type=%s class=%s rcode=%s rtype=%s rloc=\"%s\" rdtl=%s rerr=\"%s\" ans=\"%s\"\n",
+		ipStr(r.ClientIP), msSince(r.Start), r.ID, r.FQDN, r.Type, r.Class, r.RCode, r.Result.Type, locStr(r.Location), r.Details, dash(r.Err), ans)
+}
+
+func msSince(t time.Time) float64 {
+	return float64(time.Since(t)) / float64(time.Millisecond)
+}
+
+func ipStr(ip net.IP) string {
+	if ip == nil {
+		return "-"
+	}
+	return ip.String()
+}
+
+func locStr(pos *tc.CRConfigLatitudeLongitude) string {
+	if pos == nil {
+		return "-"
+	}
+	return strconv.FormatFloat(pos.Lat, 'f', 2, 64) + "," + strconv.FormatFloat(pos.Lon, 'f', 2, 64)
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
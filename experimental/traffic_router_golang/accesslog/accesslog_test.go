@@ -0,0 +1,84 @@
+package accesslog
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"bytes"
+	stdlog "log"
+	"net"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+func captureEvents(t *testing.T, f func()) string {
+	buf := &bytes.Buffer{}
+	oldEvent := log.Event
+	log.Event = stdlog.New(buf, "", 0)
+	defer func() { log.Event = oldEvent }()
+	f()
+	return buf.String()
+}
+
+func TestWriteHTTP(t *testing.T) {
+	line := captureEvents(t, func() {
+		WriteHTTP(HTTP{
+			Start:      time.Now(),
+			ClientIP:   net.ParseIP("192.0.2.1"),
+			ResolverIP: net.ParseIP("192.0.2.2"),
+			URL:        "http://cdn.ds.example.net/foo",
+			Method:     "GET",
+			Proto:      "HTTP/1.1",
+			Result: Result{
+				Type:     ResultCZ,
+				Details:  DetailsNone,
+				Location: &tc.CRConfigLatitudeLongitude{Lat: 40.123, Lon: -105.5},
+			},
+			Status:      302,
+			RedirectURL: "http://edge0.ds.example.net/foo",
+		})
+	})
+	expected := regexp.MustCompile(`^\d+\.\d{3} qtype=HTTP chi=192\.0\.2\.1 rhi=192\.0\.2\.2 url="http://cdn\.ds\.example\.net/foo" cqhm=GET cqhv=HTTP/1\.1 rtype=CZ rloc="40\.12,-105\.50" rdtl=- rerr="-" rgb="-" pssc=302 ttms=\d+\.\d{3} rurl="http://edge0\.ds\.example\.net/foo" rh="-"\n$`)
+	if !expected.MatchString(line) {
+		t.Errorf("expected HTTP access log line, actual: %q", line)
+	}
+}
+
+func TestWriteDNS(t *testing.T) {
+	line := captureEvents(t, func() {
+		WriteDNS(DNS{
+			Start:    time.Now(),
+			ClientIP: net.ParseIP("192.0.2.1"),
+			ID:       42,
+			FQDN:     "nonexistent.ds.example.net.",
+			Type:     "A",
+			Class:    "IN",
+			RCode:    "NXDOMAIN",
+			Result:   Result{Type: ResultDSMiss, Details: DetailsDSNotFound},
+		})
+	})
+	expected := regexp.MustCompile(`^\d+\.\d{3} qtype=DNS chi=192\.0\.2\.1 rhi=- ttms=\d+\.\d{3} xn=42 fqdn=nonexistent\.ds\.example\.net\. type=A class=IN rcode=NXDOMAIN rtype=DS_MISS rloc="-" rdtl=DS_NOT_FOUND rerr="-" ans="-"\n$`)
+	if !expected.MatchString(line) {
+		t.Errorf("expected DNS access log line, actual: %q", line)
+	}
+}
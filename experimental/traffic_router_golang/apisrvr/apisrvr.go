@@ -0,0 +1,83 @@
+package apisrvr
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/geo"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/health"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/routerstats"
+)
+
+// Start starts the API server on the given port, which serves the router's stats and health.
+func Start(
+	locator geo.Locator,
+	stats *routerstats.Tracker,
+	hlth *health.Tracker,
+	port uint,
+) *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/crs/stats", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, routerstats.StatsResponse{Stats: stats.Stats()})
+	})
+	mux.HandleFunc("/crs/stats/geolocation", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, locator.Stats())
+	})
+	// The health is 503 if any data source is stale, so load balancers and monitors can check the status code alone.
+	mux.HandleFunc("/crs/health", func(w http.ResponseWriter, r *http.Request) {
+		status := hlth.Status()
+		if !status.Healthy {
+			writeJSONStatus(w, http.StatusServiceUnavailable, status)
+			return
+		}
+		writeJSON(w, status)
+	})
+
+	srvr := http.Server{}
+	srvr.Addr = ":" + strconv.Itoa(int(port))
+	srvr.Handler = mux
+	go func() {
+		err := srvr.ListenAndServe()
+		if err != nil {
+			fmt.Println("Serving API: " + err.Error())
+		}
+	}()
+	return &srvr
+}
+
+func writeJSON(w http.ResponseWriter, obj interface{}) {
+	writeJSONStatus(w, http.StatusOK, obj)
+}
+
+func writeJSONStatus(w http.ResponseWriter, status int, obj interface{}) {
+	bts, err := json.Marshal(obj)
+	if err != nil {
+		fmt.Println("ERROR API marshalling response: " + err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(bts)
+}
@@ -0,0 +1,191 @@
+package chash
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"errors"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+// DefaultHashCount is the number of points of a cache on its cachegroup's ring, if the CRConfig doesn't have its hashCount.
+const DefaultHashCount = 1000
+
+// CHash selects caches by consistent hashing of requests, so the same request goes to the same cache as long as it's available.
+type CHash struct {
+	rings map[tc.CacheGroupName]ring
+	dses  map[tc.DeliveryServiceName]dsHash
+}
+
+// ring is the points of every cache in a cachegroup, sorted by hash.
+type ring []point
+
+type point struct {
+	hash  uint64
+	cache tc.CacheName
+}
+
+// dsHash is how a Delivery Service's requests are hashed.
+type dsHash struct {
+	regex       *regexp.Regexp
+	queryParams []string
+}
+
+// Create creates a CHash, with a ring of every cachegroup's caches, from the given CRConfig.
+func Create(crc *tc.CRConfig) (*CHash, error) {
+	if crc == nil {
+		return nil, errors.New("CRConfig is nil")
+	}
+	c := &CHash{rings: map[tc.CacheGroupName]ring{}, dses: map[tc.DeliveryServiceName]dsHash{}}
+
+	for name, srv := range crc.ContentServers {
+		if srv.CacheGroup == nil {
+			continue
+		}
+		hashID := name
+		if srv.HashId != nil && *srv.HashId != "" {
+			hashID = *srv.HashId
+		}
+		hashCount := DefaultHashCount
+		if srv.HashCount != nil && *srv.HashCount > 0 {
+			hashCount = *srv.HashCount
+		}
+		cg := tc.CacheGroupName(*srv.CacheGroup)
+		for i := 0; i < hashCount; i++ {
+			c.rings[cg] = append(c.rings[cg], point{hash: hash(hashID + "-" + strconv.Itoa(i)), cache: tc.CacheName(name)})
+		}
+	}
+	for _, r := range c.rings {
+		sort.Slice(r, func(i, j int) bool { return r[i].hash < r[j].hash })
+	}
+
+	for name, ds := range crc.DeliveryServices {
+		h := dsHash{queryParams: append([]string(nil), ds.ConsistentHashQueryParams...)}
+		sort.Strings(h.queryParams)
+		if ds.ConsistentHashRegex != nil && *ds.ConsistentHashRegex != "" {
+			regex, err := regexp.Compile(*ds.ConsistentHashRegex)
+			if err != nil {
+				log.Warnln("CRConfig delivery service '" + name + "' consistent hash regex failed to compile, hashing the whole path: " + err.Error())
+			} else {
+				h.regex = regex
+			}
+		}
+		c.dses[tc.DeliveryServiceName(name)] = h
+	}
+	return c, nil
+}
+
+// RequestHash returns the hash of a request to the given Delivery Service.
+func (c *CHash) RequestHash(ds tc.DeliveryServiceName, path string, query url.Values) uint64 {
+	return hash(c.HashString(ds, path, query))
+}
+
+// HashString returns the string hashed for a request to the given Delivery Service. If the Delivery Service has a consistent hash regex which matches the path, that's the concatenation of the regex's capture groups, or the whole match if it has none. The Delivery Service's consistent hash query parameters in the request are appended, sorted by name.
+func (c *CHash) HashString(ds tc.DeliveryServiceName, path string, query url.Values) string {
+	h := c.dses[ds]
+	str := path
+	if h.regex != nil {
+		if match := h.regex.FindStringSubmatch(path); match != nil {
+			if len(match) == 1 {
+				str = match[0]
+			} else {
+				str = strings.Join(match[1:], "")
+			}
+		}
+	}
+
+	params := []string{}
+	for _, param := range h.queryParams {
+		for _, val := range query[param] {
+			params = append(params, url.QueryEscape(param)+"="+url.QueryEscape(val))
+		}
+	}
+	if len(params) > 0 {
+		str += "?" + strings.Join(params, "&")
+	}
+	return str
+}
+
+// Cache returns the cache of the given request hash, from the given available caches of the given cachegroup. That's the first available cache on the cachegroup's ring at or after the hash. Returns false if there are no available caches.
+func (c *CHash) Cache(cg tc.CacheGroupName, available []tc.CacheName, reqHash uint64) (tc.CacheName, bool) {
+	if len(available) == 0 {
+		return "", false
+	}
+	availableSet := make(map[tc.CacheName]struct{}, len(available))
+	for _, cache := range available {
+		availableSet[cache] = struct{}{}
+	}
+
+	r := c.rings[cg]
+	start := sort.Search(len(r), func(i int) bool { return r[i].hash >= reqHash })
+	for i := 0; i < len(r); i++ {
+		pt := r[(start+i)%len(r)]
+		if _, ok := availableSet[pt.cache]; ok {
+			return pt.cache, true
+		}
+	}
+
+	// should only happen if the CRStates and CRConfig disagree
+	log.Warnln("consistent hash: no available cache on the ring of cachegroup '" + string(cg) + "', using the available cache list")
+	return available[reqHash%uint64(len(available))], true
+}
+
+func hash(s string) uint64 {
+	sum := md5.Sum([]byte(s))
+	return binary.BigEndian.Uint64(sum[:8])
+}
+
+// ThsT is the Threadsafe type used by this package.
+type ThsT *CHash
+
+// Ths provides threadsafe access to a ThsT
+type Ths struct {
+	v *ThsT
+	m *sync.RWMutex
+}
+
+// NewThs creates a new Threadsafe Ths container.
+func NewThs() Ths {
+	v := ThsT(nil)
+	return Ths{m: &sync.RWMutex{}, v: &v}
+}
+
+// Set sets the given object in the threadsafe container. The given object MUST NOT be modified after calling this.
+func (t Ths) Set(v ThsT) {
+	t.m.Lock()
+	defer t.m.Unlock()
+	*t.v = v
+}
+
+// Get returns the object held by the threadsafe container. The object MUST NOT be modified.
+func (t Ths) Get() ThsT {
+	t.m.RLock()
+	defer t.m.RUnlock()
+	return *t.v
+}
@@ -0,0 +1,100 @@
+package chash
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+func strP(s string) *string { return &s }
+func intP(i int) *int       { return &i }
+
+func TestHashString(t *testing.T) {
+	crc := &tc.CRConfig{DeliveryServices: map[string]tc.CRConfigDeliveryService{
+		"regex-ds": {ConsistentHashRegex: strP(`^/([^/]+)/[^/]+/(.*)$`), ConsistentHashQueryParams: []string{"b", "a"}},
+		"plain-ds": {},
+	}}
+	c, err := Create(crc)
+	if err != nil {
+		t.Fatalf("creating consistent hash: %v", err)
+	}
+
+	query := url.Values{"a": {"1"}, "b": {"2"}, "c": {"3"}}
+	if actual := c.HashString("regex-ds", "/video/session-123/seg1.ts", query); actual != "videoseg1.ts?a=1&b=2" {
+		t.Errorf("expected regex capture groups and sorted query params 'videoseg1.ts?a=1&b=2', actual: '%v'", actual)
+	}
+	if actual := c.HashString("regex-ds", "/nomatch", nil); actual != "/nomatch" {
+		t.Errorf("expected unmatched path '/nomatch', actual: '%v'", actual)
+	}
+	if actual := c.HashString("plain-ds", "/video/seg1.ts", query); actual != "/video/seg1.ts" {
+		t.Errorf("expected path without query params '/video/seg1.ts', actual: '%v'", actual)
+	}
+}
+
+func TestCache(t *testing.T) {
+	crc := &tc.CRConfig{ContentServers: map[string]tc.CRConfigTrafficOpsServer{}}
+	caches := []tc.CacheName{}
+	for i := 0; i < 5; i++ {
+		name := "edge" + strconv.Itoa(i)
+		crc.ContentServers[name] = tc.CRConfigTrafficOpsServer{CacheGroup: strP("cg0"), HashCount: intP(100)}
+		caches = append(caches, tc.CacheName(name))
+	}
+	c, err := Create(crc)
+	if err != nil {
+		t.Fatalf("creating consistent hash: %v", err)
+	}
+
+	moved := 0
+	used := map[tc.CacheName]struct{}{}
+	for i := 0; i < 1000; i++ {
+		reqHash := c.RequestHash("ds", "/obj"+strconv.Itoa(i), nil)
+		cache, ok := c.Cache("cg0", caches, reqHash)
+		if !ok {
+			t.Fatalf("expected a cache, actual: none")
+		}
+		used[cache] = struct{}{}
+		if again, _ := c.Cache("cg0", caches, reqHash); again != cache {
+			t.Errorf("expected the same request to get the same cache '%v', actual: '%v'", cache, again)
+		}
+
+		// removing a cache must only move its own requests
+		fewer, _ := c.Cache("cg0", caches[1:], reqHash)
+		if cache != caches[0] && fewer != cache {
+			t.Errorf("expected request on available cache '%v' to stay when another is unavailable, actual: '%v'", cache, fewer)
+		}
+		if fewer != cache {
+			moved++
+		}
+	}
+	if len(used) != len(caches) {
+		t.Errorf("expected requests on all %v caches, actual: %v", len(caches), len(used))
+	}
+	if moved == 0 {
+		t.Errorf("expected requests of the unavailable cache to move, actual: none moved")
+	}
+
+	if _, ok := c.Cache("cg0", nil, 0); ok {
+		t.Errorf("expected no cache with no available caches, actual: ok")
+	}
+}
@@ -32,19 +32,29 @@ import (
 )
 
 type Cfg struct {
-	Port                  uint     `json:"port"`
-	Monitors              []*URL   `json:"monitors"`
-	ReqTimeout            Duration `json:"request_timeout_ms"`
-	CRConfigInterval      Duration `json:"crconfig_poll_interval_ms"`
-	CRStatesInterval      Duration `json:"crstates_poll_interval_ms"`
-	CDN                   string   `json:"cdn"`
-	TrafficOpsURI         *URL     `json:"traffic_ops_uri"`
-	TrafficOpsUser        string   `json:"traffic_ops_user"`
-	TrafficOpsPass        string   `json:"traffic_ops_pass"`
-	TrafficOpsInsecure    bool     `json:"traffic_ops_insecure"`
-	TrafficOpsClientCache bool     `json:"traffic_ops_client_cache"`
-	TrafficOpsTimeout     Duration `json:"traffic_ops_timeout_ms"`
-	CoverageZoneFile      string   `json:"coverage_zone_file"`
+	Port                     uint     `json:"port"`
+	DNSPort                  uint     `json:"dns_port"` // UDP and TCP port to serve DNS on, or 0 to not serve DNS
+	APIPort                  uint     `json:"api_port"` // port to serve the stats API on, or 0 to not serve it
+	Monitors                 []*URL   `json:"monitors"`
+	ReqTimeout               Duration `json:"request_timeout_ms"`
+	CRConfigInterval         Duration `json:"crconfig_poll_interval_ms"`
+	CRStatesInterval         Duration `json:"crstates_poll_interval_ms"`
+	SteeringInterval         Duration `json:"steering_poll_interval_ms"`
+	CDN                      string   `json:"cdn"`
+	TrafficOpsURI            *URL     `json:"traffic_ops_uri"`
+	TrafficOpsUser           string   `json:"traffic_ops_user"`
+	TrafficOpsPass           string   `json:"traffic_ops_pass"`
+	TrafficOpsInsecure       bool     `json:"traffic_ops_insecure"`
+	TrafficOpsClientCache    bool     `json:"traffic_ops_client_cache"`
+	TrafficOpsTimeout        Duration `json:"traffic_ops_timeout_ms"`
+	CoverageZoneFile         string   `json:"coverage_zone_file"`
+	GeolocationFile          string   `json:"geolocation_file"` // MaxMind database, or empty to only use the coverage zone
+	GeolocationInterval      Duration `json:"geolocation_poll_interval_ms"`
+	LastKnownGoodDir         string   `json:"last_known_good_dir"`        // directory to save the last valid CRConfig and CRStates in, and load them from if they can't be fetched at startup, or empty to not save them
+	CRConfigStaleLimit       Duration `json:"crconfig_stale_ms"`          // age after which the CRConfig is stale and health checks fail, or 0 to never be stale
+	CRStatesStaleLimit       Duration `json:"crstates_stale_ms"`          // age after which the CRStates are stale and health checks fail, or 0 to never be stale
+	SteeringStaleLimit       Duration `json:"steering_stale_ms"`          // age after which the steering data is stale and health checks fail, or 0 to never be stale
+	StaleCRStatesUnavailable bool     `json:"stale_crstates_unavailable"` // whether to stop routing to caches when the CRStates are stale, because their states are unknown, rather than using the last states
 	LogLocations
 }
 
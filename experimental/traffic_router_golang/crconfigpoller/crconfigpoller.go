@@ -29,11 +29,15 @@ import (
 	"time"
 
 	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/cgsrch"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/chash"
 	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/crconfig"
 	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/crconfigregex"
 	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/fetch"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/health"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/lastknowngood"
 	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/nextcache"
 
+	"github.com/apache/trafficcontrol/lib/go-log"
 	"github.com/apache/trafficcontrol/lib/go-tc"
 )
 
@@ -82,66 +86,116 @@ func createNextCacher(crc *tc.CRConfig) nextcache.NextCacher {
 	return nextcache.New(dses)
 }
 
+// LastKnownGoodFile is the name of the last-known-good CRConfig file, in the last-known-good directory.
+const LastKnownGoodFile = "crconfig.json"
+
+// version returns the version of the given CRConfig, which is its date.
+func version(crc *tc.CRConfig) string {
+	if crc == nil || crc.Stats.DateUnixSeconds == nil {
+		return ""
+	}
+	return strconv.FormatInt(*crc.Stats.DateUnixSeconds, 10)
+}
+
+// Start fetches the CRConfig, and polls it every interval. Each new valid CRConfig is saved in lkgDir, if it isn't empty. If the initial fetch fails, the CRConfig saved in lkgDir is used until a fetch succeeds. Fetches are recorded in hlth. Returns an error if there's no initial CRConfig, from either.
+//
 // TODO implement HTTP poller
-func Start(fetcher fetch.Fetcher, interval time.Duration) (crconfig.Ths, crconfigregex.Ths, cgsrch.Ths, nextcache.Ths, error) {
+func Start(fetcher fetch.Fetcher, interval time.Duration, lkgDir string, hlth *health.Tracker) (crconfig.Ths, crconfigregex.Ths, cgsrch.Ths, nextcache.Ths, chash.Ths, error) {
 	thsCrcRgx := crconfigregex.NewThs()
 	thsCrc := crconfig.NewThs()
 	thsCGSearcher := cgsrch.NewThs()
 	thsNextCacher := nextcache.NewThs()
+	thsCHash := chash.NewThs()
 	prevBts := []byte{}
 	prevCrc := (*tc.CRConfig)(nil)
+	hlth.Register(health.SourceCRConfig)
 
-	get := func() {
-		newBts, err := fetcher.Fetch()
-		if err != nil {
-			fmt.Println("ERROR CRConfig read error: " + err.Error())
-			return
-		}
-
-		if bytes.Equal(newBts, prevBts) {
-			fmt.Println("INFO CRConfig unchanged.")
-			return
-		}
-
-		fmt.Println("INFO CRConfig changed.")
+	// use sets the given CRConfig, if it's valid.
+	use := func(newBts []byte) (*tc.CRConfig, error) {
 		crc := &tc.CRConfig{}
 		if err := json.Unmarshal(newBts, crc); err != nil {
-			fmt.Println("ERROR CRConfig unmarshalling: " + err.Error())
-			return
+			return nil, errors.New("unmarshalling: " + err.Error())
 		}
 
 		if err := valid(crc, prevCrc); err != nil {
-			fmt.Println("ERROR not using invalid new CRConfig: " + err.Error())
-			return
+			return nil, err
 		}
 
 		crcRgx, err := crconfigregex.Get(crc)
 		if err != nil {
-			fmt.Println("ERROR not using invalid new CRConfig: failed to get Regexes " + err.Error())
-			return
+			return nil, errors.New("failed to get Regexes " + err.Error())
 		}
 		cgSearcher, err := cgsrch.Create(crc)
 		if err != nil {
-			fmt.Println("ERROR not using invalid new CRConfig: failed to create Cachegroup searcher: " + err.Error())
+			return nil, errors.New("failed to create Cachegroup searcher: " + err.Error())
 		}
 		nextCacher := createNextCacher(crc)
+		cHash, err := chash.Create(crc)
+		if err != nil {
+			return nil, errors.New("failed to create consistent hash: " + err.Error())
+		}
 
 		thsNextCacher.Set(nextCacher)
+		thsCHash.Set(cHash)
 		thsCGSearcher.Set(cgSearcher)
 		thsCrc.Set(crc)
 		thsCrcRgx.Set(&crcRgx)
 		prevBts = newBts
 		prevCrc = crc
+		return crc, nil
+	}
+
+	get := func() {
+		newBts, err := fetcher.Fetch()
+		if err != nil {
+			fmt.Println("ERROR CRConfig read error: " + err.Error())
+			hlth.Failed(health.SourceCRConfig, err)
+			return
+		}
+
+		if bytes.Equal(newBts, prevBts) {
+			fmt.Println("INFO CRConfig unchanged.")
+			hlth.Succeeded(health.SourceCRConfig, version(prevCrc))
+			return
+		}
+
+		fmt.Println("INFO CRConfig changed.")
+		crc, err := use(newBts)
+		if err != nil {
+			fmt.Println("ERROR not using invalid new CRConfig: " + err.Error())
+			hlth.Failed(health.SourceCRConfig, errors.New("invalid CRConfig: "+err.Error()))
+			return
+		}
+		hlth.Succeeded(health.SourceCRConfig, version(crc))
+		if err := lastknowngood.Save(lkgDir, LastKnownGoodFile, newBts); err != nil {
+			log.Errorln("saving last-known-good CRConfig: " + err.Error())
+		}
 		fmt.Println("INFO CRConfig set new")
 	}
 
 	get()
 
+	if prevCrc == nil && lkgDir != "" {
+		if bts, fetched, err := lastknowngood.Load(lkgDir, LastKnownGoodFile); err != nil {
+			log.Errorln("loading last-known-good CRConfig: " + err.Error())
+		} else if crc, err := use(bts); err != nil {
+			log.Errorln("not using invalid last-known-good CRConfig: " + err.Error())
+		} else {
+			hlth.LoadedLastKnownGood(health.SourceCRConfig, version(crc), fetched)
+			log.Warnln("no initial CRConfig, using last-known-good CRConfig from " + fetched.Format(time.RFC3339))
+		}
+	}
+
+	err := error(nil)
+	if prevCrc == nil {
+		err = errors.New("no CRConfig fetched or last-known-good")
+	}
+
 	go func() {
 		for {
 			time.Sleep(interval)
 			get()
 		}
 	}()
-	return thsCrc, thsCrcRgx, thsCGSearcher, thsNextCacher, nil
+	return thsCrc, thsCrcRgx, thsCGSearcher, thsNextCacher, thsCHash, err
 }
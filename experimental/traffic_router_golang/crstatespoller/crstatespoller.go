@@ -22,7 +22,9 @@ package crstatespoller
 
 import (
 	"bytes"
+	"crypto/md5"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -30,7 +32,10 @@ import (
 	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/crconfig"
 	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/crstates"
 	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/fetch"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/health"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/lastknowngood"
 
+	"github.com/apache/trafficcontrol/lib/go-log"
 	"github.com/apache/trafficcontrol/lib/go-tc"
 )
 
@@ -38,6 +43,10 @@ func updateAvailableServers(crcThs crconfig.Ths, crsThs crstates.Ths, as availab
 	newAS := map[tc.DeliveryServiceName]map[tc.CacheGroupName][]tc.CacheName{}
 	crc := crcThs.Get()
 	crs := crsThs.Get()
+	if crc == nil || crs == nil {
+		fmt.Println("ERROR updateAvailableServers missing CRConfig or CRStates, not updating")
+		return
+	}
 	for serverNameStr, server := range crc.ContentServers {
 		serverName := tc.CacheName(serverNameStr)
 		if !crs.Caches[serverName].IsAvailable {
@@ -60,47 +69,109 @@ func updateAvailableServers(crcThs crconfig.Ths, crsThs crstates.Ths, as availab
 	as.Set(newAS)
 }
 
+// LastKnownGoodFile is the name of the last-known-good CRStates file, in the last-known-good directory.
+const LastKnownGoodFile = "crstates.json"
+
+// version returns the version of the given CRStates. The CRStates has no version of its own, so this is the MD5 of its bytes.
+func version(bts []byte) string {
+	return fmt.Sprintf("%x", md5.Sum(bts))
+}
+
+// Start fetches the CRStates, and polls it every interval. Each new CRStates is saved in lkgDir, if it isn't empty. If the initial fetch fails, the CRStates saved in lkgDir is used until a fetch succeeds. Fetches are recorded in hlth.
+//
+// If staleUnavailable, once the CRStates are stale by the hlth limit, the state of every cache is unknown, and no caches are routed to until the CRStates are fetched again.
+//
 // TODO implement HTTP poller
-func Start(fetcher fetch.Fetcher, interval time.Duration, crc crconfig.Ths) (crstates.Ths, availableservers.AvailableServers, error) {
+func Start(fetcher fetch.Fetcher, interval time.Duration, crc crconfig.Ths, lkgDir string, hlth *health.Tracker, staleUnavailable bool) (crstates.Ths, availableservers.AvailableServers, error) {
 	thsCrs := crstates.NewThs()
 	availableServers := availableservers.New()
 	prevBts := []byte{}
+	unknown := false // whether the CRStates are stale, and no caches are available
+	hlth.Register(health.SourceCRStates)
+
+	use := func(newBts []byte) error {
+		crs := &tc.CRStates{}
+		if err := json.Unmarshal(newBts, crs); err != nil {
+			return errors.New("unmarshalling: " + err.Error())
+		}
+
+		thsCrs.Set(crs)
+		prevBts = newBts
+
+		updateAvailableServers(crc, thsCrs, availableServers) // TODO update AvailableServers when CRStates OR CRConfig is update, via channel and manager goroutine?
+		return nil
+	}
 
 	get := func() {
 		newBts, err := fetcher.Fetch()
 		if err != nil {
 			fmt.Println("ERROR CRStates read error: " + err.Error())
+			hlth.Failed(health.SourceCRStates, err)
 			return
 		}
 
 		if bytes.Equal(newBts, prevBts) {
 			fmt.Println("INFO CRStates unchanged.")
+			hlth.Succeeded(health.SourceCRStates, version(newBts))
 			return
 		}
 
 		fmt.Println("INFO CRStates changed.")
-		crs := &tc.CRStates{}
-		if err := json.Unmarshal(newBts, crs); err != nil {
-			fmt.Println("ERROR CRStates unmarshalling: " + err.Error())
+		if err := use(newBts); err != nil {
+			fmt.Println("ERROR CRStates " + err.Error())
+			hlth.Failed(health.SourceCRStates, errors.New("invalid CRStates: "+err.Error()))
 			return
 		}
-
-		thsCrs.Set(crs)
-		prevBts = newBts
-
-		updateAvailableServers(crc, thsCrs, availableServers) // TODO update AvailableServers when CRStates OR CRConfig is update, via channel and manager goroutine?
+		hlth.Succeeded(health.SourceCRStates, version(newBts))
+		if err := lastknowngood.Save(lkgDir, LastKnownGoodFile, newBts); err != nil {
+			log.Errorln("saving last-known-good CRStates: " + err.Error())
+		}
 
 		fmt.Println("INFO CRStates set new")
-		// TODO update AvailableServers
+	}
+
+	// checkStale stops routing to caches when the CRStates become stale, and resumes when they're fresh again.
+	checkStale := func() {
+		if !staleUnavailable {
+			return
+		}
+		stale := hlth.Stale(health.SourceCRStates)
+		if stale && !unknown {
+			log.Errorln("CRStates are stale, cache states are unknown, not routing to any caches")
+			availableServers.Set(availableservers.AvailableServersMap{})
+		} else if !stale && unknown {
+			log.Infoln("CRStates are fresh, routing to available caches")
+			updateAvailableServers(crc, thsCrs, availableServers)
+		}
+		unknown = stale
 	}
 
 	get()
 
+	if thsCrs.Get() == nil && lkgDir != "" {
+		if bts, fetched, err := lastknowngood.Load(lkgDir, LastKnownGoodFile); err != nil {
+			log.Errorln("loading last-known-good CRStates: " + err.Error())
+		} else if err := use(bts); err != nil {
+			log.Errorln("not using invalid last-known-good CRStates: " + err.Error())
+		} else {
+			hlth.LoadedLastKnownGood(health.SourceCRStates, version(bts), fetched)
+			log.Warnln("no initial CRStates, using last-known-good CRStates from " + fetched.Format(time.RFC3339))
+		}
+	}
+
+	checkStale()
+
+	err := error(nil)
+	if thsCrs.Get() == nil {
+		err = errors.New("no CRStates fetched or last-known-good")
+	}
+
 	go func() {
 		for {
 			time.Sleep(interval)
 			get()
+			checkStale()
 		}
 	}()
-	return thsCrs, availableServers, nil
+	return thsCrs, availableServers, err
 }
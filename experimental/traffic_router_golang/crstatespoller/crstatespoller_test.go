@@ -0,0 +1,98 @@
+package crstatespoller
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/crconfig"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/health"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+type failFetcher struct{}
+
+func (f failFetcher) Fetch() ([]byte, error) { return nil, errors.New("unreachable") }
+
+func testCRConfig() crconfig.Ths {
+	cg := "cg0"
+	crcThs := crconfig.NewThs()
+	crcThs.Set(&tc.CRConfig{ContentServers: map[string]tc.CRConfigTrafficOpsServer{
+		"edge0": {CacheGroup: &cg, DeliveryServices: map[string][]string{"ds0": nil}},
+	}})
+	return crcThs
+}
+
+func TestStartLastKnownGood(t *testing.T) {
+	dir, err := ioutil.TempDir("", "crstatespoller")
+	if err != nil {
+		t.Fatalf("creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, LastKnownGoodFile), []byte(`{"caches":{"edge0":{"isAvailable":true}}}`), 0644); err != nil {
+		t.Fatalf("writing last-known-good: %v", err)
+	}
+
+	hlth := health.NewTracker(map[health.Source]time.Duration{health.SourceCRStates: time.Hour})
+	_, availableServers, err := Start(failFetcher{}, time.Hour, testCRConfig(), dir, hlth, true)
+	if err != nil {
+		t.Fatalf("expected last-known-good CRStates, actual error: %v", err)
+	}
+	if srvrs, err := availableServers.Get("ds0", "cg0"); err != nil || len(srvrs) != 1 {
+		t.Errorf("expected last-known-good available server, actual: %v %v", srvrs, err)
+	}
+	if st := hlth.Status(); !st.Healthy || !st.Sources[health.SourceCRStates].LastKnownGood || st.Sources[health.SourceCRStates].LastError == "" {
+		t.Errorf("expected healthy last-known-good CRStates with the fetch error, actual: %+v", st)
+	}
+}
+
+func TestStartStaleUnavailable(t *testing.T) {
+	dir, err := ioutil.TempDir("", "crstatespoller")
+	if err != nil {
+		t.Fatalf("creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, LastKnownGoodFile)
+	if err := ioutil.WriteFile(path, []byte(`{"caches":{"edge0":{"isAvailable":true}}}`), 0644); err != nil {
+		t.Fatalf("writing last-known-good: %v", err)
+	}
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatalf("setting last-known-good time: %v", err)
+	}
+
+	hlth := health.NewTracker(map[health.Source]time.Duration{health.SourceCRStates: time.Hour})
+	_, availableServers, err := Start(failFetcher{}, time.Hour, testCRConfig(), dir, hlth, true)
+	if err != nil {
+		t.Fatalf("expected last-known-good CRStates, actual error: %v", err)
+	}
+	if srvrs, err := availableServers.Get("ds0", "cg0"); err == nil {
+		t.Errorf("expected no available servers with stale CRStates, actual: %v", srvrs)
+	}
+	if st := hlth.Status(); st.Healthy {
+		t.Errorf("expected unhealthy with stale CRStates, actual: %+v", st)
+	}
+}
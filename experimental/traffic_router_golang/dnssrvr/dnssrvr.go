@@ -0,0 +1,531 @@
+package dnssrvr
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/accesslog"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/availableservers"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/cgsrch"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/crconfig"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/geo"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/nextcache"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/routerstats"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+// MaxUDPSize is the largest UDP response to a query without EDNS. Larger responses are truncated, so the client retries over TCP.
+const MaxUDPSize = 512
+
+// MaxEDNSUDPSize is the largest UDP response, regardless of the size the client advertises with EDNS.
+const MaxEDNSUDPSize = 4096
+
+// TCPIdleTimeout is how long a TCP connection is kept open without a query.
+const TCPIdleTimeout = 10 * time.Second
+
+// Server is a running DNS server, listening on UDP and TCP.
+type Server struct {
+	udp net.PacketConn
+	tcp net.Listener
+}
+
+// Close stops the server from listening.
+func (s *Server) Close() error {
+	udpErr := s.udp.Close()
+	tcpErr := s.tcp.Close()
+	if udpErr != nil {
+		return udpErr
+	}
+	return tcpErr
+}
+
+// Start starts listening for DNS queries on the given port, over UDP and TCP. Delivery Service names are answered from the same CRConfig, CRStates, coverage zone and cachegroup data as HTTP requests: DNS-routed Delivery Services with caches in the cachegroup nearest the resolver, and HTTP-routed Delivery Services with the Traffic Routers.
+func Start(
+	crc crconfig.Ths,
+	availableServers availableservers.AvailableServers,
+	cgSrch cgsrch.Ths,
+	nextCacher nextcache.Ths,
+	locator geo.Locator,
+	stats *routerstats.Tracker,
+	port uint,
+) (*Server, error) {
+	addr := ":" + strconv.Itoa(int(port))
+	udp, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return nil, errors.New("listening on UDP " + addr + ": " + err.Error())
+	}
+	tcp, err := net.Listen("tcp", addr)
+	if err != nil {
+		udp.Close()
+		return nil, errors.New("listening on TCP " + addr + ": " + err.Error())
+	}
+
+	h := newHandler(crc, availableServers, cgSrch, nextCacher, locator, stats)
+	go serveUDP(udp, h)
+	go serveTCP(tcp, h)
+	return &Server{udp: udp, tcp: tcp}, nil
+}
+
+func serveUDP(conn net.PacketConn, h *handler) {
+	buf := make([]byte, 65535) // TODO pool buffers
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Temporary() {
+				continue
+			}
+			log.Errorln("DNS UDP listener stopped: " + err.Error())
+			return
+		}
+		req := append([]byte(nil), buf[:n]...)
+		go func() {
+			resp, err := h.answer(req, addrIP(addr), MaxUDPSize)
+			if err != nil {
+				log.Warnln("DNS UDP query from " + addr.String() + ": " + err.Error())
+				return
+			}
+			if _, err := conn.WriteTo(resp, addr); err != nil {
+				log.Warnln("DNS UDP writing response to " + addr.String() + ": " + err.Error())
+			}
+		}()
+	}
+}
+
+func serveTCP(listener net.Listener, h *handler) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Temporary() {
+				continue
+			}
+			log.Errorln("DNS TCP listener stopped: " + err.Error())
+			return
+		}
+		go serveTCPConn(conn, h)
+	}
+}
+
+// serveTCPConn answers queries on the given connection until it's closed or idle. Each TCP message is prefixed with its 2-byte length.
+func serveTCPConn(conn net.Conn, h *handler) {
+	defer conn.Close()
+	ip := addrIP(conn.RemoteAddr())
+	lenBuf := make([]byte, 2)
+	for {
+		conn.SetDeadline(time.Now().Add(TCPIdleTimeout))
+		if _, err := io.ReadFull(conn, lenBuf); err != nil {
+			return // closed or idle
+		}
+		req := make([]byte, binary.BigEndian.Uint16(lenBuf))
+		if _, err := io.ReadFull(conn, req); err != nil {
+			log.Warnln("DNS TCP reading query from " + conn.RemoteAddr().String() + ": " + err.Error())
+			return
+		}
+		resp, err := h.answer(req, ip, 0)
+		if err != nil {
+			log.Warnln("DNS TCP query from " + conn.RemoteAddr().String() + ": " + err.Error())
+			return
+		}
+		out := make([]byte, 2, 2+len(resp))
+		binary.BigEndian.PutUint16(out, uint16(len(resp)))
+		if _, err := conn.Write(append(out, resp...)); err != nil {
+			log.Warnln("DNS TCP writing response to " + conn.RemoteAddr().String() + ": " + err.Error())
+			return
+		}
+	}
+}
+
+func addrIP(addr net.Addr) net.IP {
+	switch a := addr.(type) {
+	case *net.UDPAddr:
+		return a.IP
+	case *net.TCPAddr:
+		return a.IP
+	}
+	return nil
+}
+
+type handler struct {
+	crc        crconfig.Ths
+	availSrvrs availableservers.AvailableServers
+	cgSrch     cgsrch.Ths
+	nextCacher nextcache.Ths
+	locator    geo.Locator
+	stats      *routerstats.Tracker
+
+	// zones are built from zonesCRC, and rebuilt when the CRConfig changes.
+	zonesM   *sync.Mutex
+	zonesCRC *tc.CRConfig
+	zones    *Zones
+}
+
+func newHandler(crc crconfig.Ths, availSrvrs availableservers.AvailableServers, cgSrch cgsrch.Ths, nextCacher nextcache.Ths, locator geo.Locator, stats *routerstats.Tracker) *handler {
+	return &handler{crc: crc, availSrvrs: availSrvrs, cgSrch: cgSrch, nextCacher: nextCacher, locator: locator, stats: stats, zonesM: &sync.Mutex{}}
+}
+
+// getZones returns the Zones of the current CRConfig, or nil if there's no CRConfig.
+func (h *handler) getZones() *Zones {
+	crc := (*tc.CRConfig)(h.crc.Get())
+	h.zonesM.Lock()
+	defer h.zonesM.Unlock()
+	if crc == h.zonesCRC {
+		return h.zones
+	}
+	zones, err := NewZones(crc)
+	if err != nil {
+		log.Errorln("creating DNS zones: " + err.Error())
+		return h.zones
+	}
+	h.zonesCRC = crc
+	h.zones = zones
+	return zones
+}
+
+// answer returns the response to the given query from the given client IP. If maxSize isn't 0, responses larger than it, or than the client's EDNS size, are truncated. Returns an error if the query is too malformed to respond to.
+func (h *handler) answer(req []byte, clientIP net.IP, maxSize int) ([]byte, error) {
+	start := time.Now()
+	p := dnsmessage.Parser{}
+	hdr, err := p.Start(req)
+	if err != nil {
+		return nil, errors.New("parsing header: " + err.Error())
+	}
+	if hdr.Response {
+		return nil, errors.New("message is a response")
+	}
+	resp := dnsmessage.Message{Header: dnsmessage.Header{ID: hdr.ID, Response: true, OpCode: hdr.OpCode, RecursionDesired: hdr.RecursionDesired}}
+
+	q, err := p.Question()
+	if err != nil {
+		resp.Header.RCode = dnsmessage.RCodeFormatError
+		return pack(resp, maxSize)
+	}
+	resp.Questions = []dnsmessage.Question{q}
+
+	// The result is an error, unless resolving sets it otherwise.
+	result := accesslog.Result{Type: accesslog.ResultError, Details: accesslog.DetailsNone}
+	defer func() {
+		accesslog.WriteDNS(accesslog.DNS{
+			Start:    start,
+			ClientIP: clientIP,
+			ID:       hdr.ID,
+			FQDN:     q.Name.String(),
+			Type:     typeStr(q.Type),
+			Class:    classStr(q.Class),
+			RCode:    rcodeStr(resp.Header.RCode),
+			Result:   result,
+			Answers:  answerStrs(resp.Answers),
+		})
+		h.stats.AddDNS(q.Name.String(), result.Type, time.Since(start))
+	}()
+
+	if ednsSize, ok := getEDNSSize(&p); ok {
+		resp.Additionals = append(resp.Additionals, dnsmessage.Resource{
+			Header: dnsmessage.ResourceHeader{Name: dnsmessage.MustNewName("."), Type: dnsmessage.TypeOPT, Class: dnsmessage.Class(MaxEDNSUDPSize)},
+			Body:   &dnsmessage.OPTResource{},
+		})
+		if maxSize != 0 && ednsSize > maxSize {
+			maxSize = ednsSize
+			if maxSize > MaxEDNSUDPSize {
+				maxSize = MaxEDNSUDPSize
+			}
+		}
+	}
+
+	if hdr.OpCode != 0 || q.Class != dnsmessage.ClassINET {
+		resp.Header.RCode = dnsmessage.RCodeNotImplemented
+		result.Err = "unsupported opcode or class"
+		return pack(resp, maxSize)
+	}
+
+	zones := h.getZones()
+	if zones == nil {
+		resp.Header.RCode = dnsmessage.RCodeServerFailure
+		result.Err = "no CRConfig"
+		return pack(resp, maxSize)
+	}
+	result = h.resolve(&resp, zones, q, clientIP)
+	return pack(resp, maxSize)
+}
+
+// resolve sets the answers of resp to the given question, or the RCode if it can't be answered. Returns how the question was routed, for the access log.
+func (h *handler) resolve(resp *dnsmessage.Message, zones *Zones, q dnsmessage.Question, clientIP net.IP) accesslog.Result {
+	name := strings.ToLower(q.Name.String())
+	zone, ok := zones.Zone(name)
+	if !ok {
+		resp.Header.RCode = dnsmessage.RCodeRefused
+		return accesslog.Result{Type: accesslog.ResultDSMiss, Details: accesslog.DetailsDSNotFound}
+	}
+	resp.Header.Authoritative = true
+
+	// Names which aren't Delivery Services, and Delivery Services routed by HTTP, are answered with static records.
+	result := accesslog.Result{Type: accesslog.ResultStaticRoute, Details: accesslog.DetailsNone}
+
+	if name == zone {
+		switch q.Type {
+		case dnsmessage.TypeSOA:
+			resp.Answers = append(resp.Answers, soaRecord(zones, zone, zones.TTLs.SOA))
+		case dnsmessage.TypeNS:
+			for _, rt := range zones.Routers {
+				resp.Answers = append(resp.Answers, newRecord(q.Name, zones.TTLs.NS, &dnsmessage.NSResource{NS: dnsmessage.MustNewName(rt.FQDN)}))
+				resp.Additionals = append(resp.Additionals, addrRecords(dnsmessage.TypeA, zones.TTLs.A, rt)...)
+				resp.Additionals = append(resp.Additionals, addrRecords(dnsmessage.TypeAAAA, zones.TTLs.AAAA, rt)...)
+			}
+		}
+	} else if ds, ok := zones.DeliveryServices[name]; ok {
+		if q.Type == dnsmessage.TypeA || (q.Type == dnsmessage.TypeAAAA && (ds.IPv6 || !ds.DNS)) {
+			ttl := ds.TTLs.A
+			if q.Type == dnsmessage.TypeAAAA {
+				ttl = ds.TTLs.AAAA
+			}
+			hosts := zones.Routers
+			if ds.DNS {
+				loc := geo.Location{}
+				hosts, loc = h.cacheHosts(zones, ds, q.Type, clientIP)
+				result.Type, result.Location = accesslog.ResultType(loc.Source), &loc.Pos
+				if len(hosts) == 0 {
+					result.Type, result.Details = accesslog.ResultMiss, accesslog.DetailsGeoNoCacheFound
+				}
+			}
+			for _, host := range hosts {
+				for _, rec := range addrRecords(q.Type, ttl, host) {
+					rec.Header.Name = q.Name
+					resp.Answers = append(resp.Answers, rec)
+				}
+			}
+		}
+	} else if recs, ok := zones.Static[name]; ok {
+		for _, rec := range recs {
+			if rec.Type == q.Type || rec.Type == dnsmessage.TypeCNAME {
+				resp.Answers = append(resp.Answers, staticRecord(q.Name, rec))
+			}
+		}
+	} else {
+		resp.Header.RCode = dnsmessage.RCodeNameError
+		result.Type, result.Details = accesslog.ResultDSMiss, accesslog.DetailsDSNotFound
+	}
+
+	if len(resp.Answers) == 0 {
+		minTTL := zones.TTLs.SOA
+		if zones.SOA.Minimum < minTTL {
+			minTTL = zones.SOA.Minimum
+		}
+		resp.Authorities = append(resp.Authorities, soaRecord(zones, zone, minTTL))
+	}
+	return result
+}
+
+// cacheHosts returns the available caches of the given Delivery Service, in the cachegroup nearest the client, which have an address of the given type. At most the Delivery Service's MaxIPs are returned, starting with the next cache of the Delivery Service. Also returns the client's location.
+func (h *handler) cacheHosts(zones *Zones, ds DeliveryService, qtype dnsmessage.Type, clientIP net.IP) ([]Host, geo.Location) {
+	loc := h.locator.Locate(clientIP)
+	cgSrch := h.cgSrch.Get()
+	if cgSrch == nil {
+		return nil, loc
+	}
+	cgDat, ok := cgSrch.Nearest(loc.Pos.Lat, loc.Pos.Lon)
+	if !ok {
+		return nil, loc
+	}
+	srvrs, err := h.availSrvrs.Get(ds.Name, tc.CacheGroupName(cgDat.Obj))
+	if err != nil || len(srvrs) == 0 {
+		log.Infoln("DNS no available servers for ds '" + string(ds.Name) + "' cg '" + string(cgDat.Obj) + "'")
+		return nil, loc
+	}
+
+	start := uint64(0)
+	if nextCacher := h.nextCacher.Get(); nextCacher != nil {
+		if i, ok := nextCacher.NextCache(ds.Name); ok {
+			start = i
+		}
+	}
+
+	hosts := []Host{}
+	for i := 0; i < len(srvrs); i++ {
+		if ds.MaxIPs > 0 && len(hosts) >= ds.MaxIPs {
+			break
+		}
+		host := zones.Servers[srvrs[(start+uint64(i))%uint64(len(srvrs))]]
+		if (qtype == dnsmessage.TypeA && host.IP == nil) || (qtype == dnsmessage.TypeAAAA && host.IP6 == nil) {
+			continue
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts, loc
+}
+
+// typeStr returns the access log name of the given type, e.g. "A" rather than the dnsmessage "TypeA".
+func typeStr(t dnsmessage.Type) string {
+	return strings.TrimPrefix(t.String(), "Type")
+}
+
+func classStr(c dnsmessage.Class) string {
+	if c == dnsmessage.ClassINET {
+		return "IN"
+	}
+	return strings.TrimPrefix(c.String(), "Class")
+}
+
+// rcodeStr returns the access log name of the given RCode, which is the name used by RFC 1035 and most DNS tools.
+func rcodeStr(rc dnsmessage.RCode) string {
+	switch rc {
+	case dnsmessage.RCodeSuccess:
+		return "NOERROR"
+	case dnsmessage.RCodeFormatError:
+		return "FORMERR"
+	case dnsmessage.RCodeServerFailure:
+		return "SERVFAIL"
+	case dnsmessage.RCodeNameError:
+		return "NXDOMAIN"
+	case dnsmessage.RCodeNotImplemented:
+		return "NOTIMP"
+	case dnsmessage.RCodeRefused:
+		return "REFUSED"
+	}
+	return strconv.Itoa(int(rc))
+}
+
+// answerStrs returns the values of the given answers, for the access log.
+func answerStrs(answers []dnsmessage.Resource) []string {
+	strs := make([]string, 0, len(answers))
+	for _, ans := range answers {
+		switch body := ans.Body.(type) {
+		case *dnsmessage.AResource:
+			strs = append(strs, net.IP(body.A[:]).String())
+		case *dnsmessage.AAAAResource:
+			strs = append(strs, net.IP(body.AAAA[:]).String())
+		case *dnsmessage.CNAMEResource:
+			strs = append(strs, body.CNAME.String())
+		case *dnsmessage.NSResource:
+			strs = append(strs, body.NS.String())
+		default:
+			strs = append(strs, typeStr(ans.Header.Type))
+		}
+	}
+	return strs
+}
+
+// getEDNSSize returns the UDP size of the query's EDNS OPT record, if it has one. The parser must be after the question.
+func getEDNSSize(p *dnsmessage.Parser) (int, bool) {
+	if err := p.SkipAllQuestions(); err != nil {
+		return 0, false
+	}
+	if err := p.SkipAllAnswers(); err != nil {
+		return 0, false
+	}
+	if err := p.SkipAllAuthorities(); err != nil {
+		return 0, false
+	}
+	for {
+		rh, err := p.AdditionalHeader()
+		if err != nil {
+			return 0, false
+		}
+		if rh.Type == dnsmessage.TypeOPT {
+			return int(rh.Class), true
+		}
+		if err := p.SkipAdditional(); err != nil {
+			return 0, false
+		}
+	}
+}
+
+func newRecord(name dnsmessage.Name, ttl uint32, body dnsmessage.ResourceBody) dnsmessage.Resource {
+	return dnsmessage.Resource{Header: dnsmessage.ResourceHeader{Name: name, Class: dnsmessage.ClassINET, TTL: ttl}, Body: body}
+}
+
+// addrRecords returns the A or AAAA record of the given host's FQDN, or nothing if it has no address of that type.
+func addrRecords(qtype dnsmessage.Type, ttl uint32, host Host) []dnsmessage.Resource {
+	name, err := dnsmessage.NewName(host.FQDN)
+	if err != nil {
+		name = dnsmessage.MustNewName(".")
+	}
+	if qtype == dnsmessage.TypeA && host.IP != nil && host.IP.To4() != nil {
+		a := dnsmessage.AResource{}
+		copy(a.A[:], host.IP.To4())
+		return []dnsmessage.Resource{newRecord(name, ttl, &a)}
+	}
+	if qtype == dnsmessage.TypeAAAA && host.IP6 != nil && host.IP6.To16() != nil {
+		aaaa := dnsmessage.AAAAResource{}
+		copy(aaaa.AAAA[:], host.IP6.To16())
+		return []dnsmessage.Resource{newRecord(name, ttl, &aaaa)}
+	}
+	return nil
+}
+
+func staticRecord(name dnsmessage.Name, rec StaticRecord) dnsmessage.Resource {
+	switch rec.Type {
+	case dnsmessage.TypeA:
+		a := dnsmessage.AResource{}
+		copy(a.A[:], net.ParseIP(rec.Value).To4())
+		return newRecord(name, rec.TTL, &a)
+	case dnsmessage.TypeAAAA:
+		aaaa := dnsmessage.AAAAResource{}
+		copy(aaaa.AAAA[:], net.ParseIP(rec.Value).To16())
+		return newRecord(name, rec.TTL, &aaaa)
+	case dnsmessage.TypeCNAME:
+		return newRecord(name, rec.TTL, &dnsmessage.CNAMEResource{CNAME: dnsmessage.MustNewName(rec.Value)})
+	}
+	return newRecord(name, rec.TTL, &dnsmessage.TXTResource{TXT: []string{rec.Value}})
+}
+
+// soaRecord returns the SOA record of the given zone. The primary name server is the first Traffic Router.
+func soaRecord(zones *Zones, zone string, ttl uint32) dnsmessage.Resource {
+	ns := "ns." + zone
+	if len(zones.Routers) > 0 {
+		ns = zones.Routers[0].FQDN
+	}
+	return newRecord(dnsmessage.MustNewName(zone), ttl, &dnsmessage.SOAResource{
+		NS:      dnsmessage.MustNewName(ns),
+		MBox:    dnsmessage.MustNewName(fqdn(zones.SOA.Admin + "." + zone)),
+		Serial:  zones.Serial,
+		Refresh: zones.SOA.Refresh,
+		Retry:   zones.SOA.Retry,
+		Expire:  zones.SOA.Expire,
+		MinTTL:  zones.SOA.Minimum,
+	})
+}
+
+// pack packs the given message. If maxSize isn't 0 and the message is larger, its records are removed and it's marked truncated.
+func pack(msg dnsmessage.Message, maxSize int) ([]byte, error) {
+	b, err := msg.Pack()
+	if err != nil {
+		return nil, errors.New("packing response: " + err.Error())
+	}
+	if maxSize == 0 || len(b) <= maxSize {
+		return b, nil
+	}
+	msg.Header.Truncated = true
+	msg.Answers, msg.Authorities = nil, nil
+	b, err = msg.Pack()
+	if err != nil {
+		return nil, errors.New("packing truncated response: " + err.Error())
+	}
+	return b, nil
+}
@@ -0,0 +1,218 @@
+package dnssrvr
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"net"
+	"testing"
+
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/availableservers"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/cgsrch"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/coveragezone"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/crconfig"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/geo"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/nextcache"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/routerstats"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+func strP(s string) *string { return &s }
+func intP(i int) *int       { return &i }
+
+func testHandler(t *testing.T) *handler {
+	online := tc.CRConfigRouterStatus(tc.CacheStatusOnline)
+	crc := &tc.CRConfig{
+		Config: map[string]interface{}{
+			"soa":  map[string]interface{}{"admin": "admin", "minimum": "60"},
+			"ttls": map[string]interface{}{"SOA": "86400"},
+		},
+		ContentRouters: map[string]tc.CRConfigRouter{
+			"tr0": {FQDN: strP("tr0.example.net"), IP: strP("192.0.2.10"), IP6: strP("2001:db8::10/64"), ServerStatus: &online},
+		},
+		ContentServers: map[string]tc.CRConfigTrafficOpsServer{
+			"edge0": {CacheGroup: strP("cg0"), Ip: strP("192.0.2.1"), Ip6: strP("2001:db8::1/64")},
+			"edge1": {CacheGroup: strP("cg0"), Ip: strP("192.0.2.2")},
+			"edge2": {CacheGroup: strP("cg0"), Ip: strP("192.0.2.3")},
+		},
+		DeliveryServices: map[string]tc.CRConfigDeliveryService{
+			"dns-ds": {
+				Domains:              []string{"dns-ds.cdn.example.net"},
+				RoutingName:          strP("edge"),
+				MatchSets:            []*tc.MatchSet{{Protocol: "DNS"}},
+				TTLs:                 &tc.CRConfigTTL{ASeconds: strP("42")},
+				MaxDNSIPsForLocation: intP(2),
+				StaticDNSEntries:     []tc.CRConfigStaticDNSEntry{{Name: "www", TTL: 300, Type: "CNAME", Value: "edge.dns-ds.cdn.example.net"}},
+			},
+			"http-ds": {
+				Domains:     []string{"http-ds.cdn.example.net"},
+				RoutingName: strP("cdn"),
+				MatchSets:   []*tc.MatchSet{{Protocol: "HTTP"}},
+			},
+		},
+		EdgeLocations: map[string]tc.CRConfigLatitudeLongitude{"cg0": {Lat: 1, Lon: 1}},
+	}
+	crcThs := crconfig.NewThs()
+	crcThs.Set(crc)
+
+	cgSearcher, err := cgsrch.Create(crc)
+	if err != nil {
+		t.Fatalf("creating cachegroup searcher: %v", err)
+	}
+	cgSrch := cgsrch.NewThs()
+	cgSrch.Set(cgSearcher)
+
+	nextCacher := nextcache.NewThs()
+	nextCacher.Set(nextcache.New([]tc.DeliveryServiceName{"dns-ds"}))
+
+	availSrvrs := availableservers.New()
+	availSrvrs.Set(availableservers.AvailableServersMap{"dns-ds": {"cg0": {"edge0", "edge1", "edge2"}}})
+
+	cz, err := coveragezone.New(coveragezone.JSONCoverageZones{})
+	if err != nil {
+		t.Fatalf("creating coverage zone: %v", err)
+	}
+	return newHandler(crcThs, availSrvrs, cgSrch, nextCacher, geo.NewLocator(cz, geo.NewThs()), routerstats.NewTracker())
+}
+
+func query(t *testing.T, h *handler, name string, qtype dnsmessage.Type) dnsmessage.Message {
+	req := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: 42},
+		Questions: []dnsmessage.Question{{Name: dnsmessage.MustNewName(name), Type: qtype, Class: dnsmessage.ClassINET}},
+	}
+	reqBts, err := req.Pack()
+	if err != nil {
+		t.Fatalf("packing query: %v", err)
+	}
+	respBts, err := h.answer(reqBts, net.ParseIP("198.51.100.1"), MaxUDPSize)
+	if err != nil {
+		t.Fatalf("answering query: %v", err)
+	}
+	resp := dnsmessage.Message{}
+	if err := resp.Unpack(respBts); err != nil {
+		t.Fatalf("unpacking response: %v", err)
+	}
+	if resp.Header.ID != 42 || !resp.Header.Response {
+		t.Errorf("expected response with query ID 42, actual: %+v", resp.Header)
+	}
+	return resp
+}
+
+func TestAnswerDNSDeliveryService(t *testing.T) {
+	h := testHandler(t)
+
+	resp := query(t, h, "EDGE.dns-ds.cdn.example.net.", dnsmessage.TypeA)
+	if resp.Header.RCode != dnsmessage.RCodeSuccess || !resp.Header.Authoritative {
+		t.Fatalf("expected authoritative success, actual: %+v", resp.Header)
+	}
+	if len(resp.Answers) != 2 {
+		t.Fatalf("expected maxDnsIpsForLocation 2 answers, actual: %+v", resp.Answers)
+	}
+	seen := map[string]struct{}{}
+	for _, ans := range resp.Answers {
+		if ans.Header.TTL != 42 {
+			t.Errorf("expected delivery service A TTL 42, actual: %v", ans.Header.TTL)
+		}
+		a, ok := ans.Body.(*dnsmessage.AResource)
+		if !ok {
+			t.Fatalf("expected A record, actual: %T", ans.Body)
+		}
+		seen[net.IP(a.A[:]).String()] = struct{}{}
+	}
+	if len(seen) != 2 {
+		t.Errorf("expected 2 distinct cache addresses, actual: %v", seen)
+	}
+
+	resp = query(t, h, "edge.dns-ds.cdn.example.net.", dnsmessage.TypeAAAA)
+	if len(resp.Answers) != 0 || len(resp.Authorities) != 1 {
+		t.Errorf("expected no AAAA answers without IPv6 routing, and an SOA authority, actual: %+v", resp)
+	}
+}
+
+func TestAnswerHTTPDeliveryService(t *testing.T) {
+	h := testHandler(t)
+
+	resp := query(t, h, "cdn.http-ds.cdn.example.net.", dnsmessage.TypeAAAA)
+	if len(resp.Answers) != 1 {
+		t.Fatalf("expected 1 Traffic Router answer, actual: %+v", resp.Answers)
+	}
+	aaaa, ok := resp.Answers[0].Body.(*dnsmessage.AAAAResource)
+	if !ok || !net.IP(aaaa.AAAA[:]).Equal(net.ParseIP("2001:db8::10")) {
+		t.Errorf("expected Traffic Router AAAA 2001:db8::10, actual: %+v", resp.Answers[0].Body)
+	}
+}
+
+func TestAnswerZone(t *testing.T) {
+	h := testHandler(t)
+
+	resp := query(t, h, "dns-ds.cdn.example.net.", dnsmessage.TypeSOA)
+	if len(resp.Answers) != 1 {
+		t.Fatalf("expected 1 SOA answer, actual: %+v", resp.Answers)
+	}
+	soa, ok := resp.Answers[0].Body.(*dnsmessage.SOAResource)
+	if !ok {
+		t.Fatalf("expected SOA record, actual: %T", resp.Answers[0].Body)
+	}
+	if soa.NS.String() != "tr0.example.net." || soa.MBox.String() != "admin.dns-ds.cdn.example.net." || soa.MinTTL != 60 || resp.Answers[0].Header.TTL != 86400 {
+		t.Errorf("expected SOA from CRConfig config, actual: %+v ttl %v", soa, resp.Answers[0].Header.TTL)
+	}
+
+	resp = query(t, h, "dns-ds.cdn.example.net.", dnsmessage.TypeNS)
+	if len(resp.Answers) != 1 || len(resp.Additionals) != 2 {
+		t.Errorf("expected 1 NS answer with A and AAAA glue, actual: %+v", resp)
+	}
+
+	resp = query(t, h, "www.dns-ds.cdn.example.net.", dnsmessage.TypeA)
+	if len(resp.Answers) != 1 || resp.Answers[0].Header.Type != dnsmessage.TypeCNAME {
+		t.Errorf("expected static CNAME answer, actual: %+v", resp.Answers)
+	}
+
+	resp = query(t, h, "nonexistent.dns-ds.cdn.example.net.", dnsmessage.TypeA)
+	if resp.Header.RCode != dnsmessage.RCodeNameError || len(resp.Authorities) != 1 {
+		t.Errorf("expected NXDOMAIN with SOA authority, actual: %+v", resp)
+	}
+
+	resp = query(t, h, "example.com.", dnsmessage.TypeA)
+	if resp.Header.RCode != dnsmessage.RCodeRefused || resp.Header.Authoritative {
+		t.Errorf("expected non-authoritative REFUSED for a name outside the zones, actual: %+v", resp.Header)
+	}
+}
+
+func TestAnswerStats(t *testing.T) {
+	h := testHandler(t)
+
+	query(t, h, "edge.dns-ds.cdn.example.net.", dnsmessage.TypeA)
+	query(t, h, "www.dns-ds.cdn.example.net.", dnsmessage.TypeA)
+	query(t, h, "nonexistent.dns-ds.cdn.example.net.", dnsmessage.TypeA)
+
+	stats := h.stats.Stats()
+	if stats.TotalDNSCount != 3 || stats.TotalDSMissCount != 1 {
+		t.Errorf("expected 3 DNS queries with 1 DS miss, actual: %+v", stats)
+	}
+	// the client isn't in a coverage zone and there's no geolocation database, so the delivery service is routed by the default location
+	if tally := stats.DNSMap["edge.dns-ds.cdn.example.net"]; tally.Miss != 1 {
+		t.Errorf("expected delivery service miss count 1, actual: %+v", tally)
+	}
+	if tally := stats.DNSMap["www.dns-ds.cdn.example.net"]; tally.StaticRoute != 1 {
+		t.Errorf("expected static route count 1, actual: %+v", tally)
+	}
+}
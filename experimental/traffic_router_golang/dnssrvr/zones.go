@@ -0,0 +1,269 @@
+package dnssrvr
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+// DefaultTTL is the TTL, in seconds, of records whose TTL isn't in the CRConfig.
+const DefaultTTL = 30
+
+// DefaultSOA is used for the fields missing from the CRConfig config 'soa'. These match the Traffic Ops defaults.
+var DefaultSOA = SOA{Admin: "traffic_ops", Refresh: 28800, Retry: 7200, Expire: 604800, Minimum: 30}
+
+// TTLs are the TTLs, in seconds, of each record type.
+type TTLs struct {
+	A    uint32
+	AAAA uint32
+	NS   uint32
+	SOA  uint32
+}
+
+// SOA is the data of a zone's SOA record, without the zone name or primary name server.
+type SOA struct {
+	Admin   string
+	Refresh uint32
+	Retry   uint32
+	Expire  uint32
+	Minimum uint32
+}
+
+// Host is the name and addresses of a Traffic Router or cache. Either address may be nil.
+type Host struct {
+	FQDN string
+	IP   net.IP
+	IP6  net.IP
+}
+
+// DeliveryService is the DNS data of a Delivery Service routing name.
+type DeliveryService struct {
+	Name tc.DeliveryServiceName
+	// DNS is whether the Delivery Service is DNS-routed. HTTP-routed Delivery Services are answered with the Traffic Routers.
+	DNS  bool
+	TTLs TTLs
+	// MaxIPs is the maximum number of cache addresses in an answer, or 0 for no limit.
+	MaxIPs int
+	IPv6   bool
+}
+
+// StaticRecord is a static DNS entry of a Delivery Service.
+type StaticRecord struct {
+	Type  dnsmessage.Type
+	TTL   uint32
+	Value string
+}
+
+// Zones is the DNS data of a CRConfig. Every Delivery Service domain is a zone, which every Traffic Router is authoritative for. All names are lower case and fully qualified, with a trailing dot.
+type Zones struct {
+	// Serial is the SOA serial of every zone, the CRConfig date.
+	Serial  uint32
+	SOA     SOA
+	TTLs    TTLs
+	Routers []Host
+	// Zones is the set of zone names.
+	Zones map[string]struct{}
+	// DeliveryServices is the Delivery Service of each routing name, e.g. 'edge.my-ds.cdn.example.net.'.
+	DeliveryServices map[string]DeliveryService
+	Static           map[string][]StaticRecord
+	Servers          map[tc.CacheName]Host
+}
+
+// NewZones creates the Zones of the given CRConfig. Delivery Services and static entries with invalid data are logged and skipped.
+func NewZones(crc *tc.CRConfig) (*Zones, error) {
+	if crc == nil {
+		return nil, errors.New("CRConfig is nil")
+	}
+	soaCfg, _ := crc.Config["soa"].(map[string]interface{})
+	ttlCfg, _ := crc.Config["ttls"].(map[string]interface{})
+	z := &Zones{
+		SOA: SOA{
+			Admin:   configStr(soaCfg, "admin", DefaultSOA.Admin),
+			Refresh: configUint32(soaCfg, "refresh", DefaultSOA.Refresh),
+			Retry:   configUint32(soaCfg, "retry", DefaultSOA.Retry),
+			Expire:  configUint32(soaCfg, "expire", DefaultSOA.Expire),
+			Minimum: configUint32(soaCfg, "minimum", DefaultSOA.Minimum),
+		},
+		TTLs: TTLs{
+			A:    configUint32(ttlCfg, "A", DefaultTTL),
+			AAAA: configUint32(ttlCfg, "AAAA", DefaultTTL),
+			NS:   configUint32(ttlCfg, "NS", DefaultTTL),
+			SOA:  configUint32(ttlCfg, "SOA", DefaultTTL),
+		},
+		Zones:            map[string]struct{}{},
+		DeliveryServices: map[string]DeliveryService{},
+		Static:           map[string][]StaticRecord{},
+		Servers:          map[tc.CacheName]Host{},
+	}
+	if crc.Stats.DateUnixSeconds != nil {
+		z.Serial = uint32(*crc.Stats.DateUnixSeconds)
+	}
+
+	for name, rt := range crc.ContentRouters {
+		if rt.ServerStatus == nil || rt.FQDN == nil {
+			log.Warnln("CRConfig router '" + name + "' missing status or FQDN, not using as a name server")
+			continue
+		}
+		if status := tc.CacheStatusFromString(string(*rt.ServerStatus)); status != tc.CacheStatusOnline && status != tc.CacheStatusReported {
+			continue
+		}
+		z.Routers = append(z.Routers, Host{FQDN: fqdn(*rt.FQDN), IP: parseIP(rt.IP), IP6: parseIP(rt.IP6)})
+	}
+
+	for name, srv := range crc.ContentServers {
+		host := Host{IP: parseIP(srv.Ip), IP6: parseIP(srv.Ip6)}
+		if srv.Fqdn != nil {
+			host.FQDN = fqdn(*srv.Fqdn)
+		}
+		z.Servers[tc.CacheName(name)] = host
+	}
+
+	for name, ds := range crc.DeliveryServices {
+		if ds.RoutingName == nil || *ds.RoutingName == "" {
+			log.Warnln("CRConfig delivery service '" + name + "' has no routing name, not serving DNS")
+			continue
+		}
+		d := DeliveryService{
+			Name: tc.DeliveryServiceName(name),
+			DNS:  len(ds.MatchSets) > 0 && ds.MatchSets[0] != nil && strings.ToUpper(ds.MatchSets[0].Protocol) == "DNS",
+			TTLs: z.TTLs,
+			IPv6: ds.IP6RoutingEnabled != nil && *ds.IP6RoutingEnabled,
+		}
+		if ds.TTL != nil && *ds.TTL >= 0 {
+			d.TTLs.A, d.TTLs.AAAA = uint32(*ds.TTL), uint32(*ds.TTL)
+		}
+		if ds.TTLs != nil {
+			d.TTLs.A = ttlStr(ds.TTLs.ASeconds, d.TTLs.A)
+			d.TTLs.AAAA = ttlStr(ds.TTLs.AAAASeconds, d.TTLs.AAAA)
+		}
+		if ds.MaxDNSIPsForLocation != nil && *ds.MaxDNSIPsForLocation > 0 {
+			d.MaxIPs = *ds.MaxDNSIPsForLocation
+		}
+		for _, domain := range ds.Domains {
+			zone := fqdn(domain)
+			z.Zones[zone] = struct{}{}
+			z.DeliveryServices[fqdn(*ds.RoutingName+"."+domain)] = d
+			for _, entry := range ds.StaticDNSEntries {
+				rec, err := newStaticRecord(entry)
+				if err != nil {
+					log.Warnln("CRConfig delivery service '" + name + "' static DNS entry '" + entry.Name + "': " + err.Error() + ", skipping")
+					continue
+				}
+				entryName := fqdn(entry.Name + "." + domain)
+				z.Static[entryName] = append(z.Static[entryName], rec)
+			}
+		}
+	}
+	return z, nil
+}
+
+// Zone returns the zone the given name is in, or false if it isn't in any zone.
+func (z *Zones) Zone(name string) (string, bool) {
+	for {
+		if _, ok := z.Zones[name]; ok {
+			return name, true
+		}
+		i := strings.Index(name, ".")
+		if i < 0 || i == len(name)-1 {
+			return "", false
+		}
+		name = name[i+1:]
+	}
+}
+
+func newStaticRecord(entry tc.CRConfigStaticDNSEntry) (StaticRecord, error) {
+	rec := StaticRecord{TTL: uint32(entry.TTL), Value: entry.Value}
+	switch strings.ToUpper(entry.Type) {
+	case "A":
+		if ip := net.ParseIP(entry.Value); ip == nil || ip.To4() == nil {
+			return rec, errors.New("invalid IPv4 address '" + entry.Value + "'")
+		}
+		rec.Type = dnsmessage.TypeA
+	case "AAAA":
+		if ip := net.ParseIP(entry.Value); ip == nil || ip.To4() != nil {
+			return rec, errors.New("invalid IPv6 address '" + entry.Value + "'")
+		}
+		rec.Type = dnsmessage.TypeAAAA
+	case "CNAME":
+		rec.Type = dnsmessage.TypeCNAME
+		rec.Value = fqdn(entry.Value)
+	case "TXT":
+		rec.Type = dnsmessage.TypeTXT
+	default:
+		return rec, errors.New("unsupported type '" + entry.Type + "'")
+	}
+	return rec, nil
+}
+
+// fqdn returns the given name in lower case, with a trailing dot.
+func fqdn(name string) string {
+	name = strings.ToLower(name)
+	if !strings.HasSuffix(name, ".") {
+		name += "."
+	}
+	return name
+}
+
+// parseIP parses the given address, which may be nil or have a CIDR suffix, returning nil if it's nil or invalid.
+func parseIP(s *string) net.IP {
+	if s == nil || *s == "" {
+		return nil
+	}
+	addr := *s
+	if i := strings.Index(addr, "/"); i >= 0 {
+		addr = addr[:i]
+	}
+	return net.ParseIP(addr)
+}
+
+func ttlStr(s *string, def uint32) uint32 {
+	if s == nil {
+		return def
+	}
+	i, err := strconv.ParseUint(*s, 10, 32)
+	if err != nil {
+		return def
+	}
+	return uint32(i)
+}
+
+func configStr(cfg map[string]interface{}, key string, def string) string {
+	s, ok := cfg[key].(string)
+	if !ok || s == "" {
+		return def
+	}
+	return s
+}
+
+func configUint32(cfg map[string]interface{}, key string, def uint32) uint32 {
+	s, ok := cfg[key].(string)
+	if !ok {
+		return def
+	}
+	return ttlStr(&s, def)
+}
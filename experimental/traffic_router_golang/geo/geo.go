@@ -0,0 +1,246 @@
+package geo
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/coveragezone"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+// DefaultPos is the location of clients which aren't in the coverage zone or the geolocation database.
+// TODO config
+// var DefaultPos = tc.CRConfigLatitudeLongitude{Lat: 39.578968, Lon: -104.934333}
+var DefaultPos = tc.CRConfigLatitudeLongitude{Lat: 39.579244, Lon: -104.934282}
+
+// Source is how a client was located.
+type Source string
+
+const (
+	SourceCoverageZone = Source("CZ")
+	SourceGeo          = Source("GEO")
+	SourceMiss         = Source("MISS")
+)
+
+// Location is the location of a client.
+type Location struct {
+	Pos    tc.CRConfigLatitudeLongitude
+	Source Source
+	// CountryCode is the ISO country code from the geolocation database. It's empty if the client wasn't located by the database, or the database has no country.
+	CountryCode string
+}
+
+// Stats are the counts of client lookups.
+type Stats struct {
+	Lookups          uint64 `json:"lookups"`
+	CoverageZoneHits uint64 `json:"coverageZoneHits"`
+	GeoHits          uint64 `json:"geoHits"`
+	Misses           uint64 `json:"misses"`
+	GeoErrors        uint64 `json:"geoErrors"`
+	GeoLimited       uint64 `json:"geoLimited"`
+	// Database is the metadata of the geolocation database, or nil if none is loaded.
+	Database *Metadata `json:"database"`
+	// DatabaseLoaded is when the geolocation database was last loaded.
+	DatabaseLoaded *time.Time `json:"databaseLoaded"`
+}
+
+// Locator locates clients, by the coverage zone, then the geolocation database. It's safe for use by multiple goroutines.
+type Locator struct {
+	cz    coveragezone.CoverageZone
+	db    Ths
+	stats *Stats
+}
+
+// NewLocator creates a Locator from the given coverage zone and geolocation database.
+func NewLocator(cz coveragezone.CoverageZone, db Ths) Locator {
+	return Locator{cz: cz, db: db, stats: &Stats{}}
+}
+
+// Locate returns the location of the given client IP. Clients in neither the coverage zone nor the geolocation database are at DefaultPos.
+func (l Locator) Locate(ip net.IP) Location {
+	atomic.AddUint64(&l.stats.Lookups, 1)
+	if pos, ok := l.cz.Get(ip); ok {
+		atomic.AddUint64(&l.stats.CoverageZoneHits, 1)
+		return Location{Pos: pos, Source: SourceCoverageZone}
+	}
+
+	if db := l.db.Get(); db != nil {
+		rec, err := db.Reader.Lookup(ip)
+		if err != nil {
+			atomic.AddUint64(&l.stats.GeoErrors, 1)
+			log.Warnln("geolocation lookup of " + ip.String() + ": " + err.Error())
+		} else if loc, ok := recordLocation(rec); ok {
+			atomic.AddUint64(&l.stats.GeoHits, 1)
+			return loc
+		}
+	}
+
+	atomic.AddUint64(&l.stats.Misses, 1)
+	return Location{Pos: DefaultPos, Source: SourceMiss}
+}
+
+// GeoLimited returns whether a client at the given location is denied by the given Delivery Service's geo limit. Clients in the coverage zone are always allowed. Otherwise, clients are denied if the Delivery Service is coverage zone only, or has a country list which the client's country isn't in. Denials are counted in the Stats.
+func (l Locator) GeoLimited(ds tc.CRConfigDeliveryService, loc Location) bool {
+	if loc.Source == SourceCoverageZone {
+		return false
+	}
+	limited := ds.CoverageZoneOnly
+	if !limited && len(ds.GeoEnabled) > 0 {
+		limited = true
+		for _, country := range ds.GeoEnabled {
+			if loc.CountryCode != "" && strings.EqualFold(country.CountryCode, loc.CountryCode) {
+				limited = false
+				break
+			}
+		}
+	}
+	if limited {
+		atomic.AddUint64(&l.stats.GeoLimited, 1)
+	}
+	return limited
+}
+
+// Stats returns the current lookup stats.
+func (l Locator) Stats() Stats {
+	stats := Stats{
+		Lookups:          atomic.LoadUint64(&l.stats.Lookups),
+		CoverageZoneHits: atomic.LoadUint64(&l.stats.CoverageZoneHits),
+		GeoHits:          atomic.LoadUint64(&l.stats.GeoHits),
+		Misses:           atomic.LoadUint64(&l.stats.Misses),
+		GeoErrors:        atomic.LoadUint64(&l.stats.GeoErrors),
+		GeoLimited:       atomic.LoadUint64(&l.stats.GeoLimited),
+	}
+	if db := l.db.Get(); db != nil {
+		meta := db.Reader.Metadata()
+		loaded := db.Loaded
+		stats.Database = &meta
+		stats.DatabaseLoaded = &loaded
+	}
+	return stats
+}
+
+// recordLocation returns the location of a MaxMind City or Country database record. Returns false if the record has no coordinates.
+func recordLocation(rec map[string]interface{}) (Location, bool) {
+	loc := Location{Source: SourceGeo}
+	if country, ok := rec["country"].(map[string]interface{}); ok {
+		loc.CountryCode, _ = country["iso_code"].(string)
+	}
+	recLoc, ok := rec["location"].(map[string]interface{})
+	if !ok {
+		return loc, false
+	}
+	lat, latOk := recLoc["latitude"].(float64)
+	lon, lonOk := recLoc["longitude"].(float64)
+	if !latOk || !lonOk {
+		return loc, false
+	}
+	loc.Pos = tc.CRConfigLatitudeLongitude{Lat: lat, Lon: lon}
+	return loc, true
+}
+
+// DefaultInterval is how often the geolocation database file is checked for changes, if the config doesn't say.
+const DefaultInterval = time.Minute
+
+// Database is a loaded geolocation database.
+type Database struct {
+	Reader *Reader
+	Loaded time.Time
+}
+
+// Start loads the MaxMind database at the given path, and reloads it when the file changes, checking every interval. If a load fails, the last database is kept. If the database can't be loaded initially, an error is returned, but the file is still polled.
+func Start(path string, interval time.Duration) (Ths, error) {
+	ths := NewThs()
+	prevModTime := time.Time{}
+	prevSize := int64(-1)
+
+	get := func() error {
+		fi, err := os.Stat(path)
+		if err != nil {
+			return errors.New("stat: " + err.Error())
+		}
+		if fi.ModTime().Equal(prevModTime) && fi.Size() == prevSize {
+			return nil
+		}
+		buf, err := ioutil.ReadFile(path)
+		if err != nil {
+			return errors.New("reading: " + err.Error())
+		}
+		reader, err := Open(buf)
+		if err != nil {
+			return errors.New("opening: " + err.Error())
+		}
+		ths.Set(&Database{Reader: reader, Loaded: time.Now()})
+		prevModTime = fi.ModTime()
+		prevSize = fi.Size()
+		log.Infof("geolocation database '%v' set new, type '%v' built %v\n", path, reader.Metadata().DatabaseType, time.Unix(int64(reader.Metadata().BuildEpoch), 0))
+		return nil
+	}
+
+	err := get()
+
+	go func() {
+		for {
+			time.Sleep(interval)
+			if err := get(); err != nil {
+				log.Errorln("geolocation database '" + path + "': " + err.Error())
+			}
+		}
+	}()
+	return ths, err
+}
+
+// ThsT is the Threadsafe type used by this package.
+type ThsT *Database
+
+// Ths provides threadsafe access to a ThsT
+type Ths struct {
+	v *ThsT
+	m *sync.RWMutex
+}
+
+// NewThs creates a new Threadsafe Ths container.
+func NewThs() Ths {
+	v := ThsT(nil)
+	return Ths{m: &sync.RWMutex{}, v: &v}
+}
+
+// Set sets the given object in the threadsafe container. The given object MUST NOT be modified after calling this.
+func (t Ths) Set(v ThsT) {
+	t.m.Lock()
+	defer t.m.Unlock()
+	*t.v = v
+}
+
+// Get returns the object held by the threadsafe container. The object MUST NOT be modified.
+func (t Ths) Get() ThsT {
+	t.m.RLock()
+	defer t.m.RUnlock()
+	return *t.v
+}
@@ -0,0 +1,216 @@
+package geo
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"encoding/binary"
+	"math"
+	"net"
+	"testing"
+
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/coveragezone"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+func ctrl(typ int, size int) []byte {
+	if typ <= 7 {
+		return []byte{byte(typ<<5 | size)}
+	}
+	return []byte{byte(size), byte(typ - 7)}
+}
+
+func str(s string) []byte { return append(ctrl(typeString, len(s)), s...) }
+
+func double(f float64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, math.Float64bits(f))
+	return append(ctrl(typeDouble, 8), b...)
+}
+
+func uint32Field(i uint32) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, i)
+	return append(ctrl(typeUint32, 4), b...)
+}
+
+func uint64Field(i uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, i)
+	return append(ctrl(typeUint64, 8), b...)
+}
+
+func mapField(kvs ...[]byte) []byte {
+	b := ctrl(typeMap, len(kvs)/2)
+	for _, kv := range kvs {
+		b = append(b, kv...)
+	}
+	return b
+}
+
+// buildDB builds a database with every address whose first bit is 0 in the record {country: {iso_code: "US"}, location: {latitude: 40.5, longitude: -105.25}}. IPv6 databases have IPv4 under 96 zero bits, like MaxMind's.
+func buildDB(ipVersion int, recordSize int) []byte {
+	// the country code is in the data section before the record, and the record points to it
+	data := str("US")
+	recOffset := len(data)
+	data = append(data, mapField(
+		str("country"), mapField(str("iso_code"), []byte{typePointer << 5, 0}),
+		str("location"), mapField(str("latitude"), double(40.5), str("longitude"), double(-105.25)),
+	)...)
+
+	zeroNodes := 0
+	if ipVersion == 6 {
+		zeroNodes = 96
+	}
+	nodeCount := zeroNodes + 1
+	notFound := uint32(nodeCount)
+	tree := []byte{}
+	for i := 0; i <= zeroNodes; i++ {
+		left := uint32(i + 1)
+		if i == zeroNodes {
+			left = uint32(nodeCount + dataSectionSeparatorSize + recOffset)
+		}
+		switch recordSize {
+		case 24:
+			tree = append(tree, byte(left>>16), byte(left>>8), byte(left), byte(notFound>>16), byte(notFound>>8), byte(notFound))
+		case 28:
+			tree = append(tree, byte(left>>16), byte(left>>8), byte(left), byte((left>>24)<<4|notFound>>24), byte(notFound>>16), byte(notFound>>8), byte(notFound))
+		default:
+			b := make([]byte, 8)
+			binary.BigEndian.PutUint32(b, left)
+			binary.BigEndian.PutUint32(b[4:], notFound)
+			tree = append(tree, b...)
+		}
+	}
+
+	db := append(tree, make([]byte, dataSectionSeparatorSize)...)
+	db = append(db, data...)
+	db = append(db, metadataMarker...)
+	db = append(db, mapField(
+		str("database_type"), str("Test-City"),
+		str("build_epoch"), uint64Field(1500000000),
+		str("ip_version"), uint32Field(uint32(ipVersion)),
+		str("node_count"), uint32Field(uint32(nodeCount)),
+		str("record_size"), uint32Field(uint32(recordSize)),
+	)...)
+	return db
+}
+
+func TestReaderLookup(t *testing.T) {
+	for _, ipVersion := range []int{4, 6} {
+		for _, recordSize := range []int{24, 28, 32} {
+			r, err := Open(buildDB(ipVersion, recordSize))
+			if err != nil {
+				t.Fatalf("ip version %v record size %v: opening: %v", ipVersion, recordSize, err)
+			}
+			if meta := r.Metadata(); meta.DatabaseType != "Test-City" || meta.BuildEpoch != 1500000000 {
+				t.Errorf("ip version %v record size %v: expected metadata, actual: %+v", ipVersion, recordSize, meta)
+			}
+
+			rec, err := r.Lookup(net.ParseIP("192.0.2.1"))
+			if err != nil || rec != nil {
+				t.Errorf("ip version %v record size %v: expected 192.0.2.1 not found, actual: %v %v", ipVersion, recordSize, rec, err)
+			}
+
+			rec, err = r.Lookup(net.ParseIP("10.0.0.1"))
+			if err != nil {
+				t.Fatalf("ip version %v record size %v: looking up 10.0.0.1: %v", ipVersion, recordSize, err)
+			}
+			loc, ok := recordLocation(rec)
+			if !ok || loc.Pos.Lat != 40.5 || loc.Pos.Lon != -105.25 || loc.CountryCode != "US" {
+				t.Errorf("ip version %v record size %v: expected 10.0.0.1 at 40.5,-105.25 in US, actual: %+v %v", ipVersion, recordSize, loc, ok)
+			}
+		}
+	}
+
+	if _, err := Open([]byte("not a database")); err == nil {
+		t.Errorf("expected error opening a file without metadata, actual: nil")
+	}
+}
+
+func TestDecodeCorrupt(t *testing.T) {
+	pointer := func(ptr byte) []byte { return []byte{typePointer << 5, ptr} }
+	selfMap := append(append(ctrl(typeMap, 1), str("a")...), pointer(0)...)
+	hugeArray := append(ctrl(typeArray, 31), 0xFF, 0xFF, 0xFF)
+	tests := map[string][]byte{
+		"pointer to a pointer":     append(pointer(2), pointer(0)...),
+		"map containing itself":    selfMap,
+		"array larger than buffer": hugeArray,
+		"map larger than buffer":   append(ctrl(typeMap, 2), str("a")...),
+	}
+	for name, buf := range tests {
+		if val, _, err := (decoder{buf: buf}).decode(0, 0); err == nil {
+			t.Errorf("%s: expected error, actual: %v", name, val)
+		}
+	}
+}
+
+func TestLocator(t *testing.T) {
+	cz, err := coveragezone.New(coveragezone.JSONCoverageZones{CoverageZones: map[tc.CacheGroupName]coveragezone.JSONCoverageZoneCacheGroup{
+		"cg0": {Coordinates: tc.CRConfigLatitudeLongitude{Lat: 1, Lon: 2}, Network: []string{"10.1.0.0/16"}},
+	}})
+	if err != nil {
+		t.Fatalf("creating coverage zone: %v", err)
+	}
+	reader, err := Open(buildDB(6, 28))
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	db := NewThs()
+	db.Set(&Database{Reader: reader})
+	locator := NewLocator(cz, db)
+
+	czLoc := locator.Locate(net.ParseIP("10.1.2.3"))
+	if czLoc.Source != SourceCoverageZone || czLoc.Pos.Lat != 1 {
+		t.Errorf("expected coverage zone location, actual: %+v", czLoc)
+	}
+	geoLoc := locator.Locate(net.ParseIP("10.2.2.3"))
+	if geoLoc.Source != SourceGeo || geoLoc.Pos.Lat != 40.5 || geoLoc.CountryCode != "US" {
+		t.Errorf("expected geolocation database location, actual: %+v", geoLoc)
+	}
+	missLoc := locator.Locate(net.ParseIP("192.0.2.1"))
+	if missLoc.Source != SourceMiss || missLoc.Pos.Lat != DefaultPos.Lat {
+		t.Errorf("expected default location, actual: %+v", missLoc)
+	}
+
+	czOnly := tc.CRConfigDeliveryService{CoverageZoneOnly: true}
+	usOnly := tc.CRConfigDeliveryService{GeoEnabled: []tc.CRConfigGeoEnabled{{CountryCode: "us"}}}
+	caOnly := tc.CRConfigDeliveryService{GeoEnabled: []tc.CRConfigGeoEnabled{{CountryCode: "CA"}}}
+	if locator.GeoLimited(czOnly, czLoc) || locator.GeoLimited(caOnly, czLoc) {
+		t.Errorf("expected coverage zone clients never geo limited, actual: limited")
+	}
+	if !locator.GeoLimited(czOnly, geoLoc) {
+		t.Errorf("expected coverage zone only to limit geolocated clients, actual: not limited")
+	}
+	if locator.GeoLimited(usOnly, geoLoc) {
+		t.Errorf("expected US client allowed by US country list, actual: limited")
+	}
+	if !locator.GeoLimited(caOnly, geoLoc) || !locator.GeoLimited(usOnly, missLoc) {
+		t.Errorf("expected clients outside the country list limited, actual: not limited")
+	}
+
+	stats := locator.Stats()
+	if stats.Lookups != 3 || stats.CoverageZoneHits != 1 || stats.GeoHits != 1 || stats.Misses != 1 || stats.GeoLimited != 3 {
+		t.Errorf("expected 3 lookups with 1 coverage zone hit, 1 geo hit, 1 miss and 3 geo limited, actual: %+v", stats)
+	}
+	if stats.Database == nil || stats.Database.DatabaseType != "Test-City" {
+		t.Errorf("expected database metadata in stats, actual: %+v", stats.Database)
+	}
+}
@@ -0,0 +1,374 @@
+package geo
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"net"
+	"strconv"
+)
+
+// This is a reader of the MaxMind DB format, as specified at https://maxmind.github.io/MaxMind-DB/. It only reads what's needed for lookups, and decodes each record in full.
+
+// metadataMarker precedes the metadata at the end of the database.
+var metadataMarker = []byte("\xAB\xCD\xEFMaxMind.com")
+
+// maxMetadataSize is how far from the end of the database the metadata marker is searched for.
+const maxMetadataSize = 128 * 1024
+
+// dataSectionSeparatorSize is the size of the zeros between the search tree and the data section.
+const dataSectionSeparatorSize = 16
+
+// Metadata is the metadata of a MaxMind database.
+type Metadata struct {
+	DatabaseType string `json:"databaseType"`
+	BuildEpoch   uint64 `json:"buildEpoch"`
+	IPVersion    uint64 `json:"ipVersion"`
+	NodeCount    uint64 `json:"nodeCount"`
+	RecordSize   uint64 `json:"recordSize"`
+}
+
+// Reader reads a MaxMind database. It's safe for use by multiple goroutines.
+type Reader struct {
+	buf       []byte
+	meta      Metadata
+	treeSize  uint64
+	ipv4Start uint64
+}
+
+// Open creates a Reader of the given MaxMind database. The bytes must not be modified afterward.
+func Open(buf []byte) (*Reader, error) {
+	searchStart := 0
+	if len(buf) > maxMetadataSize {
+		searchStart = len(buf) - maxMetadataSize
+	}
+	markerI := bytes.LastIndex(buf[searchStart:], metadataMarker)
+	if markerI < 0 {
+		return nil, errors.New("metadata marker not found, not a MaxMind database")
+	}
+	metaStart := searchStart + markerI + len(metadataMarker)
+
+	metaI, _, err := (decoder{buf: buf[metaStart:]}).decode(0, 0)
+	if err != nil {
+		return nil, errors.New("decoding metadata: " + err.Error())
+	}
+	metaMap, ok := metaI.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("metadata is not a map")
+	}
+	r := &Reader{buf: buf}
+	r.meta.DatabaseType, _ = metaMap["database_type"].(string)
+	r.meta.BuildEpoch, _ = metaMap["build_epoch"].(uint64)
+	r.meta.IPVersion, _ = metaMap["ip_version"].(uint64)
+	r.meta.NodeCount, _ = metaMap["node_count"].(uint64)
+	r.meta.RecordSize, _ = metaMap["record_size"].(uint64)
+
+	if r.meta.RecordSize != 24 && r.meta.RecordSize != 28 && r.meta.RecordSize != 32 {
+		return nil, errors.New("unsupported record size " + strconv.FormatUint(r.meta.RecordSize, 10))
+	}
+	if r.meta.IPVersion != 4 && r.meta.IPVersion != 6 {
+		return nil, errors.New("unsupported IP version " + strconv.FormatUint(r.meta.IPVersion, 10))
+	}
+	r.treeSize = r.meta.NodeCount * r.meta.RecordSize / 4
+	if r.treeSize+dataSectionSeparatorSize > uint64(metaStart) {
+		return nil, errors.New("search tree larger than the database")
+	}
+
+	// IPv4 addresses in an IPv6 database are under 96 zero bits.
+	if r.meta.IPVersion == 6 {
+		for i := 0; i < 96 && r.ipv4Start < r.meta.NodeCount; i++ {
+			r.ipv4Start = r.record(r.ipv4Start, 0)
+		}
+	}
+	return r, nil
+}
+
+// Metadata returns the database metadata.
+func (r *Reader) Metadata() Metadata { return r.meta }
+
+// Lookup returns the record of the given IP, or nil if it isn't in the database.
+func (r *Reader) Lookup(ip net.IP) (map[string]interface{}, error) {
+	node := uint64(0)
+	bits := net.IP(nil)
+	if ip4 := ip.To4(); ip4 != nil {
+		bits = ip4
+		node = r.ipv4Start
+	} else if r.meta.IPVersion == 6 {
+		bits = ip.To16()
+	}
+	if bits == nil {
+		return nil, errors.New("IP '" + ip.String() + "' is not valid, or is IPv6 in an IPv4 database")
+	}
+
+	for i := 0; i < len(bits)*8 && node < r.meta.NodeCount; i++ {
+		bit := (bits[i/8] >> (7 - uint(i%8))) & 1
+		node = r.record(node, bit)
+	}
+	if node == r.meta.NodeCount {
+		return nil, nil
+	}
+	if node < r.meta.NodeCount {
+		return nil, errors.New("search tree has no data for the whole address")
+	}
+
+	dataOffset := node - r.meta.NodeCount - dataSectionSeparatorSize
+	rec, _, err := (decoder{buf: r.buf[r.treeSize+dataSectionSeparatorSize:]}).decode(uint(dataOffset), 0)
+	if err != nil {
+		return nil, errors.New("decoding record: " + err.Error())
+	}
+	recMap, ok := rec.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("record is not a map")
+	}
+	return recMap, nil
+}
+
+// record returns the left (bit 0) or right (bit 1) record of the given node.
+func (r *Reader) record(node uint64, bit byte) uint64 {
+	switch r.meta.RecordSize {
+	case 24:
+		b := r.buf[node*6+uint64(bit)*3:]
+		return uint64(b[0])<<16 | uint64(b[1])<<8 | uint64(b[2])
+	case 28:
+		b := r.buf[node*7:]
+		if bit == 0 {
+			return uint64(b[3]&0xF0)<<20 | uint64(b[0])<<16 | uint64(b[1])<<8 | uint64(b[2])
+		}
+		return uint64(b[3]&0x0F)<<24 | uint64(b[4])<<16 | uint64(b[5])<<8 | uint64(b[6])
+	default:
+		return uint64(binary.BigEndian.Uint32(r.buf[node*8+uint64(bit)*4:]))
+	}
+}
+
+// Data field types.
+const (
+	typeExtended  = 0
+	typePointer   = 1
+	typeString    = 2
+	typeDouble    = 3
+	typeBytes     = 4
+	typeUint16    = 5
+	typeUint32    = 6
+	typeMap       = 7
+	typeInt32     = 8
+	typeUint64    = 9
+	typeUint128   = 10
+	typeArray     = 11
+	typeContainer = 12
+	typeEndMarker = 13
+	typeBool      = 14
+	typeFloat     = 15
+)
+
+// decoder decodes data fields. Pointers are offsets into buf.
+type decoder struct {
+	buf []byte
+}
+
+var errTruncated = errors.New("unexpected end of data")
+
+// maxDepth is the most maps and arrays a field may be nested in. Pointers allow a corrupt database to nest a map or array in itself, which would otherwise be decoded forever.
+const maxDepth = 512
+
+// decode decodes the field at the given offset, nested in depth maps and arrays, and returns the offset after it. Maps are decoded as map[string]interface{}, arrays as []interface{}, unsigned integers as uint64 (or []byte for uint128), signed integers as int64, and floats as float64.
+func (d decoder) decode(offset uint, depth int) (interface{}, uint, error) {
+	typ, size, offset, err := d.ctrl(offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	if typ != typePointer {
+		return d.decodeValue(typ, size, offset, depth)
+	}
+	ptr, next, err := d.pointer(size, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	typ, size, offset, err = d.ctrl(ptr)
+	if err != nil {
+		return nil, 0, err
+	}
+	if typ == typePointer {
+		return nil, 0, errors.New("pointer to a pointer at offset " + strconv.FormatUint(uint64(ptr), 10))
+	}
+	val, _, err := d.decodeValue(typ, size, offset, depth)
+	return val, next, err
+}
+
+// ctrl decodes the control byte(s) at the given offset, and returns the type, size, and offset of the value. For pointers, the size is the control byte.
+func (d decoder) ctrl(offset uint) (int, uint, uint, error) {
+	if offset >= uint(len(d.buf)) {
+		return 0, 0, 0, errTruncated
+	}
+	ctrlByte := d.buf[offset]
+	offset++
+	typ := int(ctrlByte >> 5)
+	if typ == typePointer {
+		return typ, uint(ctrlByte), offset, nil
+	}
+	if typ == typeExtended {
+		if offset >= uint(len(d.buf)) {
+			return 0, 0, 0, errTruncated
+		}
+		typ = 7 + int(d.buf[offset])
+		offset++
+	}
+
+	size := uint(ctrlByte & 0x1F)
+	if size >= 29 {
+		n := size - 28
+		if offset+n > uint(len(d.buf)) {
+			return 0, 0, 0, errTruncated
+		}
+		v := uint(0)
+		for _, b := range d.buf[offset : offset+n] {
+			v = v<<8 | uint(b)
+		}
+		offset += n
+		switch n {
+		case 1:
+			size = 29 + v
+		case 2:
+			size = 285 + v
+		default:
+			size = 65821 + v
+		}
+	}
+	return typ, size, offset, nil
+}
+
+// pointer decodes the pointer with the given control byte, whose data is at offset, and returns the pointer and the offset after it.
+func (d decoder) pointer(ctrlByte uint, offset uint) (uint, uint, error) {
+	ptrSize := ((ctrlByte >> 3) & 0x3) + 1
+	if offset+ptrSize > uint(len(d.buf)) {
+		return 0, 0, errTruncated
+	}
+	b := d.buf[offset : offset+ptrSize]
+	vvv := ctrlByte & 0x7
+	ptr := uint(0)
+	switch ptrSize {
+	case 1:
+		ptr = vvv<<8 | uint(b[0])
+	case 2:
+		ptr = (vvv<<16 | uint(b[0])<<8 | uint(b[1])) + 2048
+	case 3:
+		ptr = (vvv<<24 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])) + 526336
+	default:
+		ptr = uint(binary.BigEndian.Uint32(b))
+	}
+	return ptr, offset + ptrSize, nil
+}
+
+func (d decoder) decodeValue(typ int, size uint, offset uint, depth int) (interface{}, uint, error) {
+	if typ == typeMap || typ == typeArray {
+		if depth >= maxDepth {
+			return nil, 0, errors.New("maps and arrays nested more than " + strconv.Itoa(maxDepth) + " deep")
+		}
+		depth++
+		// each array element takes at least a byte, and each map entry two, so larger sizes are corrupt, and mustn't be allocated
+		minLen := size
+		if typ == typeMap {
+			minLen = size * 2
+		}
+		if minLen > uint(len(d.buf))-offset {
+			return nil, 0, errTruncated
+		}
+	}
+
+	switch typ {
+	case typeMap:
+		m := make(map[string]interface{}, size)
+		for i := uint(0); i < size; i++ {
+			key, next, err := d.decode(offset, depth)
+			if err != nil {
+				return nil, 0, err
+			}
+			keyStr, ok := key.(string)
+			if !ok {
+				return nil, 0, errors.New("map key is not a string")
+			}
+			val, next, err := d.decode(next, depth)
+			if err != nil {
+				return nil, 0, err
+			}
+			m[keyStr] = val
+			offset = next
+		}
+		return m, offset, nil
+	case typeArray:
+		a := make([]interface{}, 0, size)
+		for i := uint(0); i < size; i++ {
+			val, next, err := d.decode(offset, depth)
+			if err != nil {
+				return nil, 0, err
+			}
+			a = append(a, val)
+			offset = next
+		}
+		return a, offset, nil
+	case typeBool:
+		return size != 0, offset, nil
+	case typeContainer, typeEndMarker:
+		return nil, offset, nil
+	}
+
+	if offset+size > uint(len(d.buf)) {
+		return nil, 0, errTruncated
+	}
+	b := d.buf[offset : offset+size]
+	next := offset + size
+	switch typ {
+	case typeString:
+		return string(b), next, nil
+	case typeBytes, typeUint128:
+		return append([]byte(nil), b...), next, nil
+	case typeDouble:
+		if size != 8 {
+			return nil, 0, errors.New("double size " + strconv.Itoa(int(size)) + ", must be 8")
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), next, nil
+	case typeFloat:
+		if size != 4 {
+			return nil, 0, errors.New("float size " + strconv.Itoa(int(size)) + ", must be 4")
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), next, nil
+	case typeUint16, typeUint32, typeUint64:
+		if size > 8 {
+			return nil, 0, errors.New("unsigned integer size " + strconv.Itoa(int(size)) + " too large")
+		}
+		v := uint64(0)
+		for _, c := range b {
+			v = v<<8 | uint64(c)
+		}
+		return v, next, nil
+	case typeInt32:
+		if size > 4 {
+			return nil, 0, errors.New("int32 size " + strconv.Itoa(int(size)) + " too large")
+		}
+		v := uint32(0)
+		for _, c := range b {
+			v = v<<8 | uint32(c)
+		}
+		return int64(int32(v)), next, nil
+	}
+	return nil, 0, errors.New("unknown data type " + strconv.Itoa(typ))
+}
@@ -0,0 +1,180 @@
+package health
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Source is a data source the router polls.
+type Source string
+
+const (
+	SourceCRConfig = Source("crconfig")
+	SourceCRStates = Source("crstates")
+	SourceSteering = Source("steering")
+)
+
+// SourceStatus is the fetch status of a single data source.
+type SourceStatus struct {
+	// LastSuccess is the time the data in use was fetched, or nil if none has been.
+	LastSuccess *time.Time `json:"lastSuccess"`
+	LastAttempt *time.Time `json:"lastAttempt"`
+	// LastError is the error of the last fetch, or empty if it succeeded.
+	LastError string `json:"lastError,omitempty"`
+	// Version identifies the data in use, e.g. the CRConfig date.
+	Version string `json:"version,omitempty"`
+	// LastKnownGood is whether the data in use was loaded from the on-disk last-known-good copy, rather than fetched since the router started.
+	LastKnownGood bool `json:"lastKnownGood"`
+	// AgeMS is how long ago the data in use was fetched, in milliseconds.
+	AgeMS int64 `json:"ageMs"`
+	// StaleLimitMS is the age after which the data is stale, or 0 if it's never stale.
+	StaleLimitMS int64 `json:"staleLimitMs"`
+	Stale        bool  `json:"stale"`
+}
+
+// Status is the router's health. It's healthy if no data source is stale.
+type Status struct {
+	Healthy bool                    `json:"healthy"`
+	Sources map[Source]SourceStatus `json:"sources"`
+	// Unhealthy is the stale sources, sorted.
+	Unhealthy []Source `json:"unhealthy,omitempty"`
+}
+
+type sourceState struct {
+	lastSuccess   time.Time
+	lastAttempt   time.Time
+	lastError     string
+	version       string
+	lastKnownGood bool
+}
+
+// Tracker tracks the fetch status of each data source. It is safe for multiple goroutines.
+type Tracker struct {
+	m       *sync.Mutex
+	sources map[Source]*sourceState
+	limits  map[Source]time.Duration
+	now     func() time.Time
+}
+
+// NewTracker returns a Tracker with the given staleness limits. Sources without a limit, or with a limit of 0, are never stale.
+func NewTracker(limits map[Source]time.Duration) *Tracker {
+	return &Tracker{m: &sync.Mutex{}, sources: map[Source]*sourceState{}, limits: limits, now: time.Now}
+}
+
+// Register adds the given source, so it's reported before its first fetch.
+func (t *Tracker) Register(src Source) {
+	t.m.Lock()
+	defer t.m.Unlock()
+	t.source(src)
+}
+
+// Succeeded records a successful fetch of the given source, with the version of the new data.
+func (t *Tracker) Succeeded(src Source, version string) {
+	t.m.Lock()
+	defer t.m.Unlock()
+	s := t.source(src)
+	now := t.now()
+	s.lastSuccess = now
+	s.lastAttempt = now
+	s.lastError = ""
+	s.version = version
+	s.lastKnownGood = false
+}
+
+// Failed records a failed fetch of the given source. The data in use, and its age, are unchanged.
+func (t *Tracker) Failed(src Source, err error) {
+	t.m.Lock()
+	defer t.m.Unlock()
+	s := t.source(src)
+	s.lastAttempt = t.now()
+	s.lastError = err.Error()
+}
+
+// LoadedLastKnownGood records that the given source's data was loaded from disk. The fetched time is when it was originally fetched, so its age and staleness continue from then.
+func (t *Tracker) LoadedLastKnownGood(src Source, version string, fetched time.Time) {
+	t.m.Lock()
+	defer t.m.Unlock()
+	s := t.source(src)
+	s.lastSuccess = fetched
+	s.version = version
+	s.lastKnownGood = true
+}
+
+// Stale returns whether the given source's data is older than its staleness limit, or was never fetched. Sources without a limit are never stale.
+func (t *Tracker) Stale(src Source) bool {
+	t.m.Lock()
+	defer t.m.Unlock()
+	return t.stale(src, t.source(src), t.now())
+}
+
+// Status returns the current status of every source.
+func (t *Tracker) Status() Status {
+	t.m.Lock()
+	defer t.m.Unlock()
+	now := t.now()
+	st := Status{Healthy: true, Sources: map[Source]SourceStatus{}}
+	for src, s := range t.sources {
+		ss := SourceStatus{
+			LastError:     s.lastError,
+			Version:       s.version,
+			LastKnownGood: s.lastKnownGood,
+			StaleLimitMS:  int64(t.limits[src] / time.Millisecond),
+			Stale:         t.stale(src, s, now),
+		}
+		if !s.lastSuccess.IsZero() {
+			lastSuccess := s.lastSuccess
+			ss.LastSuccess = &lastSuccess
+			ss.AgeMS = int64(now.Sub(lastSuccess) / time.Millisecond)
+		}
+		if !s.lastAttempt.IsZero() {
+			lastAttempt := s.lastAttempt
+			ss.LastAttempt = &lastAttempt
+		}
+		if ss.Stale {
+			st.Healthy = false
+			st.Unhealthy = append(st.Unhealthy, src)
+		}
+		st.Sources[src] = ss
+	}
+	sort.Slice(st.Unhealthy, func(i, j int) bool { return st.Unhealthy[i] < st.Unhealthy[j] })
+	return st
+}
+
+// source returns the state of the given source, creating it if necessary. Must be called with the lock held.
+func (t *Tracker) source(src Source) *sourceState {
+	s, ok := t.sources[src]
+	if !ok {
+		s = &sourceState{}
+		t.sources[src] = s
+	}
+	return s
+}
+
+// stale must be called with the lock held.
+func (t *Tracker) stale(src Source, s *sourceState, now time.Time) bool {
+	limit := t.limits[src]
+	if limit <= 0 {
+		return false
+	}
+	return s.lastSuccess.IsZero() || now.Sub(s.lastSuccess) > limit
+}
@@ -0,0 +1,66 @@
+package health
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTracker(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+	tr := NewTracker(map[Source]time.Duration{SourceCRConfig: time.Minute, SourceCRStates: 10 * time.Second})
+	tr.now = func() time.Time { return now }
+
+	tr.Register(SourceCRConfig)
+	tr.Register(SourceSteering)
+	if !tr.Stale(SourceCRConfig) {
+		t.Errorf("expected a source with a limit which was never fetched to be stale")
+	}
+	if tr.Stale(SourceSteering) {
+		t.Errorf("expected a source without a limit to never be stale")
+	}
+
+	tr.Succeeded(SourceCRConfig, "1500000000")
+	tr.LoadedLastKnownGood(SourceCRStates, "abc", now.Add(-5*time.Second))
+	st := tr.Status()
+	if !st.Healthy || len(st.Unhealthy) != 0 {
+		t.Errorf("expected healthy, actual: %+v", st)
+	}
+	if crs := st.Sources[SourceCRStates]; !crs.LastKnownGood || crs.AgeMS != 5000 || crs.StaleLimitMS != 10000 || crs.Version != "abc" {
+		t.Errorf("expected last-known-good CRStates 5s old, actual: %+v", crs)
+	}
+
+	now = now.Add(30 * time.Second)
+	tr.Failed(SourceCRStates, errors.New("timeout"))
+	st = tr.Status()
+	if st.Healthy || len(st.Unhealthy) != 1 || st.Unhealthy[0] != SourceCRStates {
+		t.Errorf("expected unhealthy with stale CRStates, actual: %+v", st)
+	}
+	if crs := st.Sources[SourceCRStates]; crs.LastError != "timeout" || crs.LastAttempt == nil || !crs.LastAttempt.Equal(now) || crs.Version != "abc" {
+		t.Errorf("expected failed fetch to keep the version and record the error, actual: %+v", crs)
+	}
+
+	tr.Succeeded(SourceCRStates, "def")
+	if st = tr.Status(); !st.Healthy || st.Sources[SourceCRStates].LastKnownGood || st.Sources[SourceCRStates].LastError != "" {
+		t.Errorf("expected healthy after a successful fetch, actual: %+v", st)
+	}
+}
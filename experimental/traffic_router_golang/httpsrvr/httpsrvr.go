@@ -21,43 +21,83 @@ package httpsrvr
  */
 
 import (
-	"fmt"
+	"encoding/json"
 	"net"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/accesslog"
 	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/availableservers"
 	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/cgsrch"
-	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/coveragezone"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/chash"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/crconfig"
 	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/crconfigregex"
-	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/nextcache"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/geo"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/routerstats"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/steering"
 
 	"github.com/apache/trafficcontrol/lib/go-log"
 	"github.com/apache/trafficcontrol/lib/go-tc"
 )
 
-// TODO config
-// var DefaultPos = tc.CRConfigLatitudeLongitude{Lat: 39.578968, Lon: -104.934333}
-var DefaultPos = tc.CRConfigLatitudeLongitude{Lat: 39.579244, Lon: -104.934282}
-
 // TODO config
 const UseXForwardedFor = true
 
+// statusWriter is a ResponseWriter which remembers the status written, for the access log.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
 func getHandler(
 	regexes crconfigregex.Ths,
 	availSrvrs availableservers.AvailableServers,
 	cgSrchThs cgsrch.Ths,
-	nextCacherThs nextcache.Ths,
-	cz coveragezone.CoverageZone,
+	cHashThs chash.Ths,
+	steeringThs steering.Ths,
+	crcThs crconfig.Ths,
+	locator geo.Locator,
+	stats *routerstats.Tracker,
 ) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		// host := r.Header.Get("Host")
+	return func(rw http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		w := &statusWriter{ResponseWriter: rw}
+		// The result is an error, unless routing sets it otherwise.
+		result := accesslog.Result{Type: accesslog.ResultError, Details: accesslog.DetailsNone}
+		ip := net.IP(nil)
+		defer func() {
+			accesslog.WriteHTTP(accesslog.HTTP{
+				Start:       start,
+				ClientIP:    ip,
+				ResolverIP:  remoteIP(r.RemoteAddr),
+				URL:         requestURL(r),
+				Method:      r.Method,
+				Proto:       r.Proto,
+				Result:      result,
+				Status:      w.status,
+				RedirectURL: w.Header().Get("Location"),
+			})
+			stats.AddHTTP(r.Host, result.Type, time.Since(start))
+		}()
 
 		// TODO parse subdomains more efficiently
 		fqdnParts := strings.Split(r.Host, ".")
 		if len(fqdnParts) < 3 {
-			fmt.Println("EVENT request '" + r.Host + "' doesn't have enough parts (must be 'subsubdomain.subdomain.domain'), returning 404")
+			result.Type, result.Details = accesslog.ResultDSMiss, accesslog.DetailsDSNotFound
 			w.WriteHeader(http.StatusNotFound)
 			return
 		}
@@ -66,87 +106,145 @@ func getHandler(
 		subdomain := fqdnParts[1]
 		domain := strings.Join(fqdnParts[2:len(fqdnParts)-1], ".")
 
-		fmt.Println("DEBUG request '" + r.Host + "' split ssd '" + subsubdomain + "' sd '" + subdomain + "' d '" + domain + "'")
-
 		dsRegexes := (*crconfigregex.Regexes)(regexes.Get())
 
 		dsName, ok := dsRegexes.DeliveryService(domain, subdomain, subsubdomain)
 		if !ok {
-			fmt.Println("EVENT request '" + r.Host + "' has no match, returning 404")
+			result.Type, result.Details = accesslog.ResultDSMiss, accesslog.DetailsDSNotFound
 			w.WriteHeader(http.StatusNotFound)
 			return
 		}
 
-		fmt.Println("EVENT " + r.RemoteAddr + " request '" + r.Host + "' matched " + string(dsName))
-
 		ipStr := r.Header.Get("X-Forwarded-For")
 		if ipStr == "" {
 			err := error(nil)
 			ipStr, _, err = net.SplitHostPort(r.RemoteAddr)
 			if err != nil {
-				fmt.Println("ERROR request from" + r.RemoteAddr + " failed to parse: " + err.Error())
+				result.Err = "parsing remote address: " + err.Error()
 				w.WriteHeader(http.StatusInternalServerError)
 				return
 			}
 		}
 
-		fmt.Println("DEBUG " + r.RemoteAddr + " IP '" + ipStr + "'")
-
-		ip := net.ParseIP(ipStr)
+		ip = net.ParseIP(ipStr)
 		if ip == nil {
-			fmt.Println("ERROR request from" + r.RemoteAddr + " IP failed to parse.")
+			result.Err = "malformed client IP '" + ipStr + "'"
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
 
-		pos, ok := cz.Get(ip)
-		if !ok {
-			pos = DefaultPos
+		cHash := (*chash.CHash)(cHashThs.Get())
+		crc := (*tc.CRConfig)(crcThs.Get())
+		if cHash == nil || crc == nil {
+			// should never happen, the DS regexes come from the same CRConfig
+			result.Err = "no CRConfig"
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		loc := locator.Locate(ip)
+		if loc.Source == geo.SourceMiss {
 			log.Warnln("request from" + r.RemoteAddr + " IP " + ip.String() + " not found, using default")
 		}
-		log.Infof("LATLON: Request from"+r.RemoteAddr+" IP "+ip.String()+" got %+v\n", pos)
+		log.Infof("LATLON: Request from"+r.RemoteAddr+" IP "+ip.String()+" got %+v\n", loc)
+		result.Location = &loc.Pos
+
+		if ds, ok := crc.DeliveryServices[string(dsName)]; ok && locator.GeoLimited(ds, loc) {
+			if ds.GeoLimitRedirectURL != nil && *ds.GeoLimitRedirectURL != "" {
+				result.Type = accesslog.ResultGeoRedirect
+				w.Header().Add("Location", *ds.GeoLimitRedirectURL)
+				w.WriteHeader(http.StatusFound)
+				return
+			}
+			result.Type, result.Details = accesslog.ResultMiss, accesslog.DetailsDSClientGeoUnsupported
+			if ds.CoverageZoneOnly {
+				result.Details = accesslog.DetailsDSCZOnly
+			}
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
 
 		cgSrch := cgSrchThs.Get()
-		cgDat, ok := cgSrch.Nearest(pos.Lat, pos.Lon)
+		cgDat, ok := cgSrch.Nearest(loc.Pos.Lat, loc.Pos.Lon)
 		if !ok {
-			fmt.Println("ERROR request from" + r.RemoteAddr + " has no nearest cachegroup (should only happen if there are no cachegroups)")
+			log.Errorln("request from " + r.RemoteAddr + " has no nearest cachegroup (should only happen if there are no cachegroups)")
+			result.Details = accesslog.DetailsGeoNoCacheFound
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
 		cg := tc.CacheGroupName(cgDat.Obj)
 
-		srvrs, err := availSrvrs.Get(dsName, cg)
-		if err != nil {
-			fmt.Println("EVENT request '" + r.Host + "' with cg '" + string(cg) + "' ds '" + string(dsName) + "' failed to get available servers, returning 404: " + err.Error())
-			w.WriteHeader(http.StatusNotFound)
-			return
+		targets := []tc.DeliveryServiceName{dsName}
+		clientSteering := false
+		steerings := steering.Steering(steeringThs.Get())
+		if steeringTargets, ok := steerings.Targets(dsName, r.URL.Path, cHash.RequestHash(dsName, r.URL.Path, r.URL.Query())); ok {
+			targets = steeringTargets
+			clientSteering = steerings[dsName].ClientSteering
 		}
 
-		fmt.Printf("DEBUG GOT AVAILABLE SERVERS %+v\n", srvrs)
+		scheme := "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
 
-		if len(srvrs) == 0 {
-			fmt.Println("EVENT request '" + r.Host + "' with cg '" + string(cg) + "' ds '" + string(dsName) + "' no available servers, returning 500")
-			w.WriteHeader(http.StatusInternalServerError) // TODO better code?
-			return
+		status := http.StatusNotFound
+		locations := []string{}
+		for _, target := range targets {
+			srvrs, err := availSrvrs.Get(target, cg)
+			if err != nil {
+				log.Debugln("request '" + r.Host + "' with cg '" + string(cg) + "' ds '" + string(target) + "' failed to get available servers: " + err.Error())
+				continue
+			}
+
+			srvr, ok := cHash.Cache(cg, srvrs, cHash.RequestHash(target, r.URL.Path, r.URL.Query()))
+			if !ok {
+				log.Debugln("request '" + r.Host + "' with cg '" + string(cg) + "' ds '" + string(target) + "' no available servers")
+				status = http.StatusInternalServerError // TODO better code?
+				continue
+			}
+
+			dsDomain := subdomain + "." + domain
+			if ds, ok := crc.DeliveryServices[string(target)]; ok && len(ds.Domains) > 0 {
+				dsDomain = ds.Domains[0]
+			}
+
+			newURL := scheme + "://" + string(srvr) + "." + dsDomain + r.URL.Path
+			if r.URL.RawQuery != "" {
+				newURL += "?" + r.URL.RawQuery
+			}
+			locations = append(locations, newURL)
 		}
 
-		nextCacher := nextCacherThs.Get()
-		nextSrvrI, ok := nextCacher.NextCache(dsName)
-		if !ok {
-			// should never happen
-			fmt.Println("ERROR request '" + r.Host + "' with cg '" + string(cg) + "' ds '" + string(dsName) + "' not found in Nextcacher, returning 500")
-			w.WriteHeader(http.StatusInternalServerError) // TODO better code?
+		if len(locations) == 0 {
+			result.Type, result.Details = accesslog.ResultMiss, accesslog.DetailsGeoNoCacheFound
+			w.WriteHeader(status)
 			return
 		}
 
-		srvr := srvrs[nextSrvrI%uint64(len(srvrs))]
+		result.Type = accesslog.ResultType(loc.Source)
 
-		newURL := string(srvr) + "." + subdomain + "." + domain + r.URL.Path
-		if r.URL.RawQuery != "" {
-			newURL += "?" + r.URL.RawQuery
+		// trred=false asks for the location in the body, rather than a redirect. Client steering returns every target's location.
+		if r.URL.Query().Get("trred") == "false" {
+			body := interface{}(struct {
+				Location string `json:"location"`
+			}{Location: locations[0]})
+			if clientSteering {
+				body = struct {
+					Locations []string `json:"locations"`
+				}{Locations: locations}
+			}
+			bts, err := json.Marshal(body)
+			if err != nil {
+				result.Type, result.Err = accesslog.ResultError, "marshalling locations: "+err.Error()
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(bts)
+			return
 		}
 
-		w.Header().Add("Location", newURL)
+		w.Header().Add("Location", locations[0])
 		w.WriteHeader(http.StatusFound)
 	}
 }
@@ -155,18 +253,39 @@ func Start(
 	regexes crconfigregex.Ths,
 	availableServers availableservers.AvailableServers,
 	cgSrch cgsrch.Ths,
-	nextCacher nextcache.Ths,
-	cz coveragezone.CoverageZone,
+	cHash chash.Ths,
+	steerings steering.Ths,
+	crc crconfig.Ths,
+	locator geo.Locator,
+	stats *routerstats.Tracker,
 	port uint,
 ) *http.Server {
 	srvr := http.Server{}
 	srvr.Addr = ":" + strconv.Itoa(int(port))
-	srvr.Handler = getHandler(regexes, availableServers, cgSrch, nextCacher, cz)
+	srvr.Handler = getHandler(regexes, availableServers, cgSrch, cHash, steerings, crc, locator, stats)
 	go func() {
 		err := srvr.ListenAndServe()
 		if err != nil {
-			fmt.Println("Serving: " + err.Error())
+			log.Errorln("Serving: " + err.Error())
 		}
 	}()
 	return &srvr
 }
+
+// remoteIP returns the IP of the given remote address, or nil if it's malformed.
+func remoteIP(addr string) net.IP {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil
+	}
+	return net.ParseIP(host)
+}
+
+// requestURL returns the full URL of the given request, as it was requested by the client.
+func requestURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + r.URL.RequestURI()
+}
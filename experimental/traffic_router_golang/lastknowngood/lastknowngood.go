@@ -0,0 +1,72 @@
+package lastknowngood
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Save writes the given data, which should be the last valid copy of a data source, so the router can start routing with it if the source is unreachable at startup.
+//
+// The data is written to the file with the given name in dir. The file is replaced atomically, so a crash never leaves a partial copy. Does nothing if dir is empty.
+func Save(dir string, name string, bts []byte) error {
+	if dir == "" {
+		return nil
+	}
+	tmp, err := ioutil.TempFile(dir, name+".tmp")
+	if err != nil {
+		return errors.New("creating temp file: " + err.Error())
+	}
+	if _, err := tmp.Write(bts); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return errors.New("writing temp file: " + err.Error())
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return errors.New("closing temp file: " + err.Error())
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, name)); err != nil {
+		os.Remove(tmp.Name())
+		return errors.New("renaming temp file: " + err.Error())
+	}
+	return nil
+}
+
+// Load returns the data of the file with the given name in dir, and when it was saved.
+func Load(dir string, name string) ([]byte, time.Time, error) {
+	if dir == "" {
+		return nil, time.Time{}, errors.New("no last-known-good directory")
+	}
+	path := filepath.Join(dir, name)
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, time.Time{}, errors.New("stat " + path + ": " + err.Error())
+	}
+	bts, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, errors.New("reading " + path + ": " + err.Error())
+	}
+	return bts, fi.ModTime(), nil
+}
@@ -0,0 +1,166 @@
+package routerstats
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/accesslog"
+)
+
+// Tallies is the count of each routing result for a single request host. The JSON is the same as the Java Traffic Router's, which Traffic Stats and Traffic Monitor read.
+type Tallies struct {
+	CZ                uint64 `json:"czCount"`
+	Geo               uint64 `json:"geoCount"`
+	DeepCZ            uint64 `json:"deepCzCount"`
+	Miss              uint64 `json:"missCount"`
+	DSR               uint64 `json:"dsrCount"`
+	Err               uint64 `json:"errCount"`
+	StaticRoute       uint64 `json:"staticRouteCount"`
+	Fed               uint64 `json:"fedCount"`
+	RegionalDenied    uint64 `json:"regionalDeniedCount"`
+	RegionalAlternate uint64 `json:"regionalAlternateCount"`
+}
+
+// Stats is a snapshot of the router's routing stats.
+type Stats struct {
+	DNSMap           map[string]Tallies `json:"dnsMap"`
+	HTTPMap          map[string]Tallies `json:"httpMap"`
+	TotalDNSCount    uint64             `json:"totalDnsCount"`
+	TotalHTTPCount   uint64             `json:"totalHttpCount"`
+	TotalDSMissCount uint64             `json:"totalDsMissCount"`
+	// AppStartTime is the time the router started, in milliseconds since the epoch.
+	AppStartTime int64 `json:"appStartTime"`
+	// AverageDNSTime and AverageHTTPTime are the average time to answer a request, in milliseconds.
+	AverageDNSTime  int64 `json:"averageDnsTime"`
+	AverageHTTPTime int64 `json:"averageHttpTime"`
+}
+
+// StatsResponse is the stats endpoint response, which wraps the stats the same as the Java Traffic Router.
+type StatsResponse struct {
+	Stats Stats `json:"stats"`
+}
+
+// Tracker tracks routing results. It is safe for multiple goroutines.
+type Tracker struct {
+	m             *sync.Mutex
+	start         time.Time
+	dns           map[string]*Tallies
+	http          map[string]*Tallies
+	dnsCount      uint64
+	httpCount     uint64
+	dsMissCount   uint64
+	totalDNSTime  time.Duration
+	totalHTTPTime time.Duration
+}
+
+func NewTracker() *Tracker {
+	return &Tracker{m: &sync.Mutex{}, start: time.Now(), dns: map[string]*Tallies{}, http: map[string]*Tallies{}}
+}
+
+// Host returns the tally key of the given request host, which is lower-cased without a port or trailing dot.
+func Host(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
+
+// AddHTTP records the result of an HTTP request for the given host, which took the given duration.
+func (t *Tracker) AddHTTP(host string, result accesslog.ResultType, dur time.Duration) {
+	t.m.Lock()
+	defer t.m.Unlock()
+	t.httpCount++
+	t.totalHTTPTime += dur
+	t.add(t.http, host, result)
+}
+
+// AddDNS records the result of a DNS query for the given name, which took the given duration.
+func (t *Tracker) AddDNS(name string, result accesslog.ResultType, dur time.Duration) {
+	t.m.Lock()
+	defer t.m.Unlock()
+	t.dnsCount++
+	t.totalDNSTime += dur
+	t.add(t.dns, name, result)
+}
+
+// add must be called with the lock held.
+func (t *Tracker) add(tallies map[string]*Tallies, host string, result accesslog.ResultType) {
+	if result == accesslog.ResultDSMiss {
+		// DS misses aren't tallied per host, because the host isn't a delivery service. This is the same as the Java Traffic Router.
+		t.dsMissCount++
+		return
+	}
+	host = Host(host)
+	tally, ok := tallies[host]
+	if !ok {
+		tally = &Tallies{}
+		tallies[host] = tally
+	}
+	switch result {
+	case accesslog.ResultCZ:
+		tally.CZ++
+	case accesslog.ResultGeo:
+		tally.Geo++
+	case accesslog.ResultMiss:
+		tally.Miss++
+	case accesslog.ResultDSRedirect:
+		tally.DSR++
+	case accesslog.ResultStaticRoute:
+		tally.StaticRoute++
+	case accesslog.ResultGeoRedirect:
+		// a geo limit redirect is the request being sent to the delivery service's alternate URL
+		tally.RegionalAlternate++
+	default:
+		tally.Err++
+	}
+}
+
+// Stats returns a snapshot of the current stats.
+func (t *Tracker) Stats() Stats {
+	t.m.Lock()
+	defer t.m.Unlock()
+	st := Stats{
+		DNSMap:           copyTallies(t.dns),
+		HTTPMap:          copyTallies(t.http),
+		TotalDNSCount:    t.dnsCount,
+		TotalHTTPCount:   t.httpCount,
+		TotalDSMissCount: t.dsMissCount,
+		AppStartTime:     t.start.UnixNano() / int64(time.Millisecond),
+	}
+	if t.dnsCount > 0 {
+		st.AverageDNSTime = int64(t.totalDNSTime/time.Duration(t.dnsCount)) / int64(time.Millisecond)
+	}
+	if t.httpCount > 0 {
+		st.AverageHTTPTime = int64(t.totalHTTPTime/time.Duration(t.httpCount)) / int64(time.Millisecond)
+	}
+	return st
+}
+
+func copyTallies(tallies map[string]*Tallies) map[string]Tallies {
+	c := make(map[string]Tallies, len(tallies))
+	for host, tally := range tallies {
+		c[host] = *tally
+	}
+	return c
+}
@@ -0,0 +1,66 @@
+package routerstats
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/accesslog"
+)
+
+func TestHost(t *testing.T) {
+	for host, expected := range map[string]string{
+		"CDN.ds.Example.net":      "cdn.ds.example.net",
+		"cdn.ds.example.net:8080": "cdn.ds.example.net",
+		"cdn.ds.example.net.":     "cdn.ds.example.net",
+		"[2001:db8::1]:80":        "2001:db8::1",
+	} {
+		if actual := Host(host); actual != expected {
+			t.Errorf("Host(%q) expected %q, actual %q", host, expected, actual)
+		}
+	}
+}
+
+func TestTracker(t *testing.T) {
+	tr := NewTracker()
+	tr.AddHTTP("cdn.ds.example.net:80", accesslog.ResultCZ, 2*time.Millisecond)
+	tr.AddHTTP("CDN.ds.example.net", accesslog.ResultGeo, 4*time.Millisecond)
+	tr.AddHTTP("cdn.ds.example.net", accesslog.ResultError, 6*time.Millisecond)
+	tr.AddHTTP("nonexistent.example.net", accesslog.ResultDSMiss, 0)
+	tr.AddDNS("edge.ds.example.net.", accesslog.ResultMiss, time.Millisecond)
+
+	st := tr.Stats()
+	if st.TotalHTTPCount != 4 || st.TotalDNSCount != 1 || st.TotalDSMissCount != 1 {
+		t.Errorf("expected 4 HTTP, 1 DNS, 1 DS miss, actual: %+v", st)
+	}
+	if expected, actual := (Tallies{CZ: 1, Geo: 1, Err: 1}), st.HTTPMap["cdn.ds.example.net"]; actual != expected {
+		t.Errorf("expected HTTP tallies %+v, actual: %+v", expected, actual)
+	}
+	if _, ok := st.HTTPMap["nonexistent.example.net"]; ok {
+		t.Errorf("expected DS misses not to be tallied per host, actual: %+v", st.HTTPMap)
+	}
+	if expected, actual := (Tallies{Miss: 1}), st.DNSMap["edge.ds.example.net"]; actual != expected {
+		t.Errorf("expected DNS tallies %+v, actual: %+v", expected, actual)
+	}
+	if st.AverageHTTPTime != 3 || st.AverageDNSTime != 1 {
+		t.Errorf("expected average HTTP time 3ms and DNS 1ms, actual: %+v", st)
+	}
+}
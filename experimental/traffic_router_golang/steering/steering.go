@@ -0,0 +1,180 @@
+package steering
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/health"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	to "github.com/apache/trafficcontrol/traffic_ops/client"
+)
+
+// DefaultInterval is how often steering data is fetched from Traffic Ops, if the config doesn't say.
+const DefaultInterval = time.Minute
+
+// Steering is the steering data of each STEERING and CLIENT_STEERING Delivery Service.
+type Steering map[tc.DeliveryServiceName]DeliveryService
+
+// DeliveryService is the steering data of a steering Delivery Service.
+type DeliveryService struct {
+	ClientSteering bool
+	// Targets are sorted by order, then by weight, highest first.
+	Targets []tc.SteeringSteeringTarget
+	Filters []Filter
+}
+
+// Filter steers requests whose path matches Regex to DeliveryService.
+type Filter struct {
+	Regex           *regexp.Regexp
+	DeliveryService tc.DeliveryServiceName
+}
+
+// New creates the Steering of the given Traffic Ops steering data. Filters whose patterns don't compile are logged and skipped.
+func New(steerings []tc.Steering) Steering {
+	s := Steering{}
+	for _, st := range steerings {
+		ds := DeliveryService{ClientSteering: st.ClientSteering, Targets: append([]tc.SteeringSteeringTarget(nil), st.Targets...)}
+		sort.SliceStable(ds.Targets, func(i, j int) bool {
+			if ds.Targets[i].Order != ds.Targets[j].Order {
+				return ds.Targets[i].Order < ds.Targets[j].Order
+			}
+			return ds.Targets[i].Weight > ds.Targets[j].Weight
+		})
+		for _, filter := range st.Filters {
+			regex, err := regexp.Compile(filter.Pattern)
+			if err != nil {
+				log.Warnln("steering delivery service '" + string(st.DeliveryService) + "' filter '" + filter.Pattern + "' failed to compile, skipping: " + err.Error())
+				continue
+			}
+			ds.Filters = append(ds.Filters, Filter{Regex: regex, DeliveryService: filter.DeliveryService})
+		}
+		s[st.DeliveryService] = ds
+	}
+	return s
+}
+
+// Targets returns the target Delivery Services of a request with the given path and consistent hash to the given Delivery Service, most preferred first. Returns false if the Delivery Service isn't a steering Delivery Service.
+//
+// If a filter matches the path, its Delivery Service is the only target. Otherwise, client steering returns every target, and steering returns one target, chosen by the request hash, weighted by the targets' weights.
+func (s Steering) Targets(dsName tc.DeliveryServiceName, path string, reqHash uint64) ([]tc.DeliveryServiceName, bool) {
+	ds, ok := s[dsName]
+	if !ok {
+		return nil, false
+	}
+	for _, filter := range ds.Filters {
+		if filter.Regex.MatchString(path) {
+			return []tc.DeliveryServiceName{filter.DeliveryService}, true
+		}
+	}
+	if len(ds.Targets) == 0 {
+		return []tc.DeliveryServiceName{}, true
+	}
+	if ds.ClientSteering {
+		targets := make([]tc.DeliveryServiceName, 0, len(ds.Targets))
+		for _, target := range ds.Targets {
+			targets = append(targets, target.DeliveryService)
+		}
+		return targets, true
+	}
+
+	totalWeight := uint64(0)
+	for _, target := range ds.Targets {
+		if target.Weight > 0 {
+			totalWeight += uint64(target.Weight)
+		}
+	}
+	if totalWeight == 0 {
+		return []tc.DeliveryServiceName{ds.Targets[0].DeliveryService}, true
+	}
+	w := reqHash % totalWeight
+	for _, target := range ds.Targets {
+		if target.Weight <= 0 {
+			continue
+		}
+		if w < uint64(target.Weight) {
+			return []tc.DeliveryServiceName{target.DeliveryService}, true
+		}
+		w -= uint64(target.Weight)
+	}
+	return []tc.DeliveryServiceName{ds.Targets[0].DeliveryService}, true // should never happen
+}
+
+// Start fetches the steering data from Traffic Ops, and again every interval. If a fetch fails, the last steering data is kept. Fetches are recorded in hlth.
+func Start(toc *to.Session, interval time.Duration, hlth *health.Tracker) Ths {
+	ths := NewThs()
+	ths.Set(ThsT(Steering{}))
+	hlth.Register(health.SourceSteering)
+	get := func() {
+		steerings, reqInf, err := toc.Steering()
+		if err != nil {
+			log.Errorf("getting steering from Traffic Ops (%v): %v\n", reqInf.RemoteAddr, err)
+			hlth.Failed(health.SourceSteering, err)
+			return
+		}
+		ths.Set(ThsT(New(steerings)))
+		hlth.Succeeded(health.SourceSteering, "")
+		log.Infof("steering set new, %v steering delivery services\n", len(steerings))
+	}
+
+	get()
+
+	go func() {
+		for {
+			time.Sleep(interval)
+			get()
+		}
+	}()
+	return ths
+}
+
+// ThsT is the Threadsafe type used by this package.
+type ThsT Steering
+
+// Ths provides threadsafe access to a ThsT
+type Ths struct {
+	v *ThsT
+	m *sync.RWMutex
+}
+
+// NewThs creates a new Threadsafe Ths container.
+func NewThs() Ths {
+	v := ThsT(nil)
+	return Ths{m: &sync.RWMutex{}, v: &v}
+}
+
+// Set sets the given object in the threadsafe container. The given object MUST NOT be modified after calling this.
+func (t Ths) Set(v ThsT) {
+	t.m.Lock()
+	defer t.m.Unlock()
+	*t.v = v
+}
+
+// Get returns the object held by the threadsafe container. The object MUST NOT be modified.
+func (t Ths) Get() ThsT {
+	t.m.RLock()
+	defer t.m.RUnlock()
+	return *t.v
+}
@@ -0,0 +1,72 @@
+package steering
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+func TestTargets(t *testing.T) {
+	s := New([]tc.Steering{
+		{
+			DeliveryService: "steering-ds",
+			Targets: []tc.SteeringSteeringTarget{
+				{DeliveryService: "target-a", Weight: 1},
+				{DeliveryService: "target-b", Weight: 3},
+			},
+			Filters: []tc.SteeringFilter{{DeliveryService: "target-a", Pattern: `.*/force-a/.*`}},
+		},
+		{
+			DeliveryService: "client-steering-ds",
+			ClientSteering:  true,
+			Targets: []tc.SteeringSteeringTarget{
+				{DeliveryService: "target-b", Order: 2},
+				{DeliveryService: "target-a", Order: 1},
+			},
+		},
+	})
+
+	if _, ok := s.Targets("not-steering-ds", "/", 0); ok {
+		t.Errorf("expected no targets for a non-steering delivery service, actual: ok")
+	}
+
+	if targets, _ := s.Targets("steering-ds", "/path/force-a/obj", 3); !reflect.DeepEqual(targets, []tc.DeliveryServiceName{"target-a"}) {
+		t.Errorf("expected filter target 'target-a', actual: %v", targets)
+	}
+
+	counts := map[tc.DeliveryServiceName]int{}
+	for reqHash := uint64(0); reqHash < 400; reqHash++ {
+		targets, _ := s.Targets("steering-ds", "/obj", reqHash)
+		if len(targets) != 1 {
+			t.Fatalf("expected 1 steering target, actual: %v", targets)
+		}
+		counts[targets[0]]++
+	}
+	if counts["target-a"] != 100 || counts["target-b"] != 300 {
+		t.Errorf("expected targets weighted 1:3, actual: %v", counts)
+	}
+
+	if targets, _ := s.Targets("client-steering-ds", "/obj", 0); !reflect.DeepEqual(targets, []tc.DeliveryServiceName{"target-a", "target-b"}) {
+		t.Errorf("expected client steering targets in order [target-a target-b], actual: %v", targets)
+	}
+}
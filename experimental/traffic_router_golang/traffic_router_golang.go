@@ -27,13 +27,19 @@ import (
 	"os"
 	"time"
 
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/apisrvr"
 	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/availableservers"
 	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/config"
 	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/coveragezone"
 	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/crconfigpoller"
 	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/crstatespoller"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/dnssrvr"
 	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/fetch"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/geo"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/health"
 	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/httpsrvr"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/routerstats"
+	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/steering"
 	"github.com/apache/trafficcontrol/experimental/traffic_router_golang/toutil"
 
 	"github.com/apache/trafficcontrol/lib/go-log"
@@ -95,17 +101,53 @@ func main() {
 	// crconfigFetcher := fetch.NewFile("./crconfig.json")
 	// crstatesFetcher := fetch.NewFile("./crstates.json")
 
-	thsCRConfig, thsCRConfigRegexes, thsCGSearcher, thsNextCacher, err := crconfigpoller.Start(crconfigFetcher, time.Duration(cfg.CRConfigInterval))
+	hlth := health.NewTracker(map[health.Source]time.Duration{
+		health.SourceCRConfig: time.Duration(cfg.CRConfigStaleLimit),
+		health.SourceCRStates: time.Duration(cfg.CRStatesStaleLimit),
+		health.SourceSteering: time.Duration(cfg.SteeringStaleLimit),
+	})
+
+	thsCRConfig, thsCRConfigRegexes, thsCGSearcher, thsNextCacher, thsCHash, err := crconfigpoller.Start(crconfigFetcher, time.Duration(cfg.CRConfigInterval), cfg.LastKnownGoodDir, hlth)
 	if err != nil {
 		fmt.Println("Could not get initial CRConfig: ", err)
 	}
 
-	thsCRStates, availableServers, err := crstatespoller.Start(crstatesFetcher, time.Duration(cfg.CRStatesInterval), thsCRConfig)
+	thsCRStates, availableServers, err := crstatespoller.Start(crstatesFetcher, time.Duration(cfg.CRStatesInterval), thsCRConfig, cfg.LastKnownGoodDir, hlth, cfg.StaleCRStatesUnavailable)
 	if err != nil {
 		fmt.Println("Could not get initial CRStates from: ", err)
 	}
 
-	httpsrvr.Start(thsCRConfigRegexes, availableServers, thsCGSearcher, thsNextCacher, cz, cfg.Port)
+	steeringInterval := time.Duration(cfg.SteeringInterval)
+	if steeringInterval == 0 {
+		steeringInterval = steering.DefaultInterval
+	}
+	thsSteering := steering.Start(toClient, steeringInterval, hlth)
+
+	thsGeo := geo.NewThs()
+	if cfg.GeolocationFile != "" {
+		geoInterval := time.Duration(cfg.GeolocationInterval)
+		if geoInterval == 0 {
+			geoInterval = geo.DefaultInterval
+		}
+		if thsGeo, err = geo.Start(cfg.GeolocationFile, geoInterval); err != nil {
+			fmt.Println("Could not load initial geolocation database '" + cfg.GeolocationFile + "': " + err.Error())
+		}
+	}
+	locator := geo.NewLocator(cz, thsGeo)
+	stats := routerstats.NewTracker()
+
+	httpsrvr.Start(thsCRConfigRegexes, availableServers, thsCGSearcher, thsCHash, thsSteering, thsCRConfig, locator, stats, cfg.Port)
+
+	if cfg.DNSPort != 0 {
+		if _, err := dnssrvr.Start(thsCRConfig, availableServers, thsCGSearcher, thsNextCacher, locator, stats, cfg.DNSPort); err != nil {
+			fmt.Println("Error starting DNS server: " + err.Error())
+			os.Exit(1)
+		}
+	}
+
+	if cfg.APIPort != 0 {
+		apisrvr.Start(locator, stats, hlth, cfg.APIPort)
+	}
 
 	// debug
 	for {
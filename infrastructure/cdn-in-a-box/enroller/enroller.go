@@ -46,7 +46,11 @@ type session struct {
 
 func newSession(reqTimeout time.Duration, toURL string, toUser string, toPass string) (session, error) {
 	s, _, err := client.LoginWithAgent(toURL, toUser, toPass, true, "cdn-in-a-box-enroller", true, reqTimeout)
-	return session{s}, err
+	if err != nil {
+		return session{s}, err
+	}
+	s.Retry = client.DefaultRetryOptions
+	return session{s}, nil
 }
 
 func printJSON(label string, b interface{}) {
@@ -808,11 +812,15 @@ func (cfg logConfig) EventLog() log.LogLocation {
 }
 
 func main() {
-	var watchDir, httpPort string
+	var watchDir, httpPort, reconcileDir string
+	var apply, prune bool
 
 	flag.StringVar(&startedFile, "started", startedFile, "file indicating service was started")
 	flag.StringVar(&watchDir, "dir", "", "base directory to watch")
 	flag.StringVar(&httpPort, "http", "", "act as http server for POST on this port (e.g. :7070)")
+	flag.StringVar(&reconcileDir, "reconcile", "", "print the changes which bring Traffic Ops to the state declared in this directory, and exit")
+	flag.BoolVar(&apply, "apply", false, "with -reconcile, apply the changes")
+	flag.BoolVar(&prune, "prune", false, "with -reconcile, delete objects which aren't declared")
 	flag.Parse()
 
 	err := log.InitCfg(logConfig{})
@@ -842,6 +850,14 @@ func main() {
 	}
 	log.Infoln("TrafficOps session established")
 
+	if reconcileDir != "" {
+		if err := reconcile(&toSession, reconcileDir, apply, prune, os.Stdout); err != nil {
+			log.Errorln("reconciling " + reconcileDir + ": " + err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
 	// dispatcher maps an API endpoint name to a function to act on the JSON input Reader
 	dispatcher := map[string]func(*session, io.Reader) error{
 		"types":                   enrollType,
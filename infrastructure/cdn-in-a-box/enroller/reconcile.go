@@ -0,0 +1,1548 @@
+package main
+
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	tc "github.com/apache/trafficcontrol/lib/go-tc"
+	"gopkg.in/yaml.v2"
+)
+
+// object is a Traffic Ops object as generic JSON, which is how the reconciler compares desired and
+// live state.
+type object map[string]interface{}
+
+// reference is a field of an object which refers to an object of another kind by its key, and the
+// field which holds that object's ID when the object is sent to Traffic Ops. References with no ID
+// field are only checked.
+type reference struct {
+	nameField string
+	idField   string
+	kind      string
+}
+
+// kind is a type of Traffic Ops object which the reconciler manages. Kinds without create, update
+// and delete funcs are only read, to resolve references to them.
+type kind struct {
+	// name is the name of the directory of desired objects of the kind, which is the same as the
+	// enroller's watched directory.
+	name string
+	// newObj returns a pointer to a new value of the kind's Go client type.
+	newObj func() interface{}
+	// key returns the name which identifies an object, and matches desired and live objects.
+	key  func(object) string
+	refs []reference
+	// writeOnly fields are never returned by Traffic Ops, so they're never compared.
+	writeOnly []string
+	// unordered fields are arrays of strings, whose order doesn't matter.
+	unordered []string
+	// adjust, if not nil, returns the desired object given the live one; desired is nil for live
+	// objects which weren't declared, in which case adjust returns nil if they aren't desired.
+	adjust func(r *reconciler, desired object, live object) object
+	list   func(r *reconciler) ([]object, error)
+	create func(r *reconciler, obj object) error
+	// update is given the live object, and the object to update it to.
+	update func(r *reconciler, live object, obj object) error
+	delete func(r *reconciler, live object) error
+}
+
+func (k *kind) readOnly() bool {
+	return k.create == nil
+}
+
+// kinds is every kind the reconciler knows about, in dependency order: objects are created and
+// updated in this order, and deleted in the reverse order.
+var kinds = []*kind{
+	{
+		name:   "types",
+		newObj: func() interface{} { return &tc.Type{} },
+		key:    fieldKey("name"),
+		list: func(r *reconciler) ([]object, error) {
+			types, _, err := r.s.GetTypes()
+			if err != nil {
+				return nil, err
+			}
+			return toObjects(types)
+		},
+		create: func(r *reconciler, obj object) error {
+			t := tc.Type{}
+			if err := decodeObject(obj, &t); err != nil {
+				return err
+			}
+			_, _, err := r.s.CreateType(t)
+			return err
+		},
+		update: func(r *reconciler, live object, obj object) error {
+			t := tc.Type{}
+			if err := decodeObject(obj, &t); err != nil {
+				return err
+			}
+			_, _, err := r.s.UpdateTypeByID(t.ID, t)
+			return err
+		},
+		delete: func(r *reconciler, live object) error {
+			_, _, err := r.s.DeleteTypeByID(objectID(live))
+			return err
+		},
+	},
+	{
+		name:   "statuses",
+		newObj: func() interface{} { return &tc.Status{} },
+		key:    fieldKey("name"),
+		list: func(r *reconciler) ([]object, error) {
+			statuses, _, err := r.s.GetStatuses()
+			if err != nil {
+				return nil, err
+			}
+			return toObjects(statuses)
+		},
+		create: func(r *reconciler, obj object) error {
+			st := tc.Status{}
+			if err := decodeObject(obj, &st); err != nil {
+				return err
+			}
+			_, _, err := r.s.CreateStatus(st)
+			return err
+		},
+		update: func(r *reconciler, live object, obj object) error {
+			st := tc.Status{}
+			if err := decodeObject(obj, &st); err != nil {
+				return err
+			}
+			_, _, err := r.s.UpdateStatusByID(st.ID, st)
+			return err
+		},
+		delete: func(r *reconciler, live object) error {
+			_, _, err := r.s.DeleteStatusByID(objectID(live))
+			return err
+		},
+	},
+	{
+		name:   "divisions",
+		newObj: func() interface{} { return &tc.Division{} },
+		key:    fieldKey("name"),
+		list: func(r *reconciler) ([]object, error) {
+			divisions, _, err := r.s.GetDivisions()
+			if err != nil {
+				return nil, err
+			}
+			return toObjects(divisions)
+		},
+		create: func(r *reconciler, obj object) error {
+			d := tc.Division{}
+			if err := decodeObject(obj, &d); err != nil {
+				return err
+			}
+			_, _, err := r.s.CreateDivision(d)
+			return err
+		},
+		update: func(r *reconciler, live object, obj object) error {
+			d := tc.Division{}
+			if err := decodeObject(obj, &d); err != nil {
+				return err
+			}
+			_, _, err := r.s.UpdateDivisionByID(d.ID, d)
+			return err
+		},
+		delete: func(r *reconciler, live object) error {
+			_, _, err := r.s.DeleteDivisionByID(objectID(live))
+			return err
+		},
+	},
+	{
+		name:   "regions",
+		newObj: func() interface{} { return &tc.Region{} },
+		key:    fieldKey("name"),
+		refs:   []reference{{"divisionName", "division", "divisions"}},
+		list: func(r *reconciler) ([]object, error) {
+			regions, _, err := r.s.GetRegions()
+			if err != nil {
+				return nil, err
+			}
+			return toObjects(regions)
+		},
+		create: func(r *reconciler, obj object) error {
+			rg := tc.Region{}
+			if err := decodeObject(obj, &rg); err != nil {
+				return err
+			}
+			_, _, err := r.s.CreateRegion(rg)
+			return err
+		},
+		update: func(r *reconciler, live object, obj object) error {
+			rg := tc.Region{}
+			if err := decodeObject(obj, &rg); err != nil {
+				return err
+			}
+			_, _, err := r.s.UpdateRegionByID(rg.ID, rg)
+			return err
+		},
+		delete: func(r *reconciler, live object) error {
+			_, _, err := r.s.DeleteRegionByID(objectID(live))
+			return err
+		},
+	},
+	{
+		name:   "phys_locations",
+		newObj: func() interface{} { return &tc.PhysLocation{} },
+		key:    fieldKey("name"),
+		refs:   []reference{{"region", "regionId", "regions"}},
+		list: func(r *reconciler) ([]object, error) {
+			physLocations, _, err := r.s.GetPhysLocations(nil)
+			if err != nil {
+				return nil, err
+			}
+			return toObjects(physLocations)
+		},
+		create: func(r *reconciler, obj object) error {
+			pl := tc.PhysLocation{}
+			if err := decodeObject(obj, &pl); err != nil {
+				return err
+			}
+			_, _, err := r.s.CreatePhysLocation(pl)
+			return err
+		},
+		update: func(r *reconciler, live object, obj object) error {
+			pl := tc.PhysLocation{}
+			if err := decodeObject(obj, &pl); err != nil {
+				return err
+			}
+			_, _, err := r.s.UpdatePhysLocationByID(pl.ID, pl)
+			return err
+		},
+		delete: func(r *reconciler, live object) error {
+			_, _, err := r.s.DeletePhysLocationByID(objectID(live))
+			return err
+		},
+	},
+	{
+		name:   "cdns",
+		newObj: func() interface{} { return &tc.CDN{} },
+		key:    fieldKey("name"),
+		list: func(r *reconciler) ([]object, error) {
+			cdns, _, err := r.s.GetCDNs()
+			if err != nil {
+				return nil, err
+			}
+			return toObjects(cdns)
+		},
+		create: func(r *reconciler, obj object) error {
+			cdn := tc.CDN{}
+			if err := decodeObject(obj, &cdn); err != nil {
+				return err
+			}
+			_, _, err := r.s.CreateCDN(cdn)
+			return err
+		},
+		update: func(r *reconciler, live object, obj object) error {
+			cdn := tc.CDN{}
+			if err := decodeObject(obj, &cdn); err != nil {
+				return err
+			}
+			_, _, err := r.s.UpdateCDNByID(cdn.ID, cdn)
+			return err
+		},
+		delete: func(r *reconciler, live object) error {
+			_, _, err := r.s.DeleteCDNByID(objectID(live))
+			return err
+		},
+	},
+	{
+		name:   "tenants",
+		newObj: func() interface{} { return &tc.Tenant{} },
+		key:    fieldKey("name"),
+		refs:   []reference{{"parentName", "parentId", "tenants"}},
+		list: func(r *reconciler) ([]object, error) {
+			tenants, _, err := r.s.Tenants()
+			if err != nil {
+				return nil, err
+			}
+			return toObjects(tenants)
+		},
+		create: func(r *reconciler, obj object) error {
+			t := tc.Tenant{}
+			if err := decodeObject(obj, &t); err != nil {
+				return err
+			}
+			_, err := r.s.CreateTenant(&t)
+			return err
+		},
+		update: func(r *reconciler, live object, obj object) error {
+			t := tc.Tenant{}
+			if err := decodeObject(obj, &t); err != nil {
+				return err
+			}
+			_, err := r.s.UpdateTenant(strconv.Itoa(t.ID), &t)
+			return err
+		},
+		delete: func(r *reconciler, live object) error {
+			_, err := r.s.DeleteTenant(strconv.Itoa(objectID(live)))
+			return err
+		},
+	},
+	{
+		name:      "users",
+		newObj:    func() interface{} { return &tc.User{} },
+		key:       fieldKey("username"),
+		refs:      []reference{{"roleName", "role", "roles"}, {"tenant", "tenantId", "tenants"}},
+		writeOnly: []string{"localPasswd", "confirmLocalPasswd"},
+		list: func(r *reconciler) ([]object, error) {
+			users, _, err := r.s.GetUsers()
+			if err != nil {
+				return nil, err
+			}
+			return toObjects(users)
+		},
+		create: func(r *reconciler, obj object) error {
+			u := tc.User{}
+			if err := decodeObject(obj, &u); err != nil {
+				return err
+			}
+			_, _, err := r.s.CreateUser(&u)
+			return err
+		},
+		update: func(r *reconciler, live object, obj object) error {
+			u := tc.User{}
+			if err := decodeObject(obj, &u); err != nil {
+				return err
+			}
+			_, _, err := r.s.UpdateUserByID(objectID(live), &u)
+			return err
+		},
+		delete: func(r *reconciler, live object) error {
+			_, _, err := r.s.DeleteUserByID(objectID(live))
+			return err
+		},
+	},
+	{
+		name:   "roles",
+		newObj: func() interface{} { return &tc.Role{} },
+		key:    fieldKey("name"),
+		list: func(r *reconciler) ([]object, error) {
+			roles, _, _, err := r.s.GetRoles()
+			if err != nil {
+				return nil, err
+			}
+			return toObjects(roles)
+		},
+	},
+	{
+		name:   "cachegroups",
+		newObj: func() interface{} { return &tc.CacheGroupNullable{} },
+		key:    fieldKey("name"),
+		refs: []reference{
+			{"typeName", "typeId", "types"},
+			{"parentCachegroupName", "parentCachegroupId", "cachegroups"},
+			{"secondaryParentCachegroupName", "secondaryParentCachegroupId", "cachegroups"},
+		},
+		list: func(r *reconciler) ([]object, error) {
+			cgs, _, err := r.s.GetCacheGroupsNullable()
+			if err != nil {
+				return nil, err
+			}
+			return toObjects(cgs)
+		},
+		create: func(r *reconciler, obj object) error {
+			cg := tc.CacheGroupNullable{}
+			if err := decodeObject(obj, &cg); err != nil {
+				return err
+			}
+			_, _, err := r.s.CreateCacheGroupNullable(cg)
+			return err
+		},
+		update: func(r *reconciler, live object, obj object) error {
+			cg := tc.CacheGroupNullable{}
+			if err := decodeObject(obj, &cg); err != nil {
+				return err
+			}
+			_, _, err := r.s.UpdateCacheGroupNullableByID(objectID(live), cg)
+			return err
+		},
+		delete: func(r *reconciler, live object) error {
+			_, _, err := r.s.DeleteCacheGroupByID(objectID(live))
+			return err
+		},
+	},
+	{
+		name:   "coordinates",
+		newObj: func() interface{} { return &tc.Coordinate{} },
+		key:    fieldKey("name"),
+		list: func(r *reconciler) ([]object, error) {
+			coordinates, _, err := r.s.GetCoordinates()
+			if err != nil {
+				return nil, err
+			}
+			return toObjects(coordinates)
+		},
+	},
+	{
+		name:   "asns",
+		newObj: func() interface{} { return &tc.ASN{} },
+		key:    fieldKey("asn"),
+		refs:   []reference{{"cachegroup", "cachegroupId", "cachegroups"}},
+		list: func(r *reconciler) ([]object, error) {
+			asns, _, err := r.s.GetASNs()
+			if err != nil {
+				return nil, err
+			}
+			return toObjects(asns)
+		},
+		create: func(r *reconciler, obj object) error {
+			asn := tc.ASN{}
+			if err := decodeObject(obj, &asn); err != nil {
+				return err
+			}
+			_, _, err := r.s.CreateASN(asn)
+			return err
+		},
+		update: func(r *reconciler, live object, obj object) error {
+			asn := tc.ASN{}
+			if err := decodeObject(obj, &asn); err != nil {
+				return err
+			}
+			_, _, err := r.s.UpdateASNByID(asn.ID, asn)
+			return err
+		},
+		delete: func(r *reconciler, live object) error {
+			_, _, err := r.s.DeleteASNByID(objectID(live))
+			return err
+		},
+	},
+	{
+		name:   "profiles",
+		newObj: func() interface{} { return &tc.Profile{} },
+		key:    fieldKey("name"),
+		refs:   []reference{{"cdnName", "cdn", "cdns"}},
+		list: func(r *reconciler) ([]object, error) {
+			profiles, _, err := r.s.GetProfiles()
+			if err != nil {
+				return nil, err
+			}
+			return toObjects(profiles)
+		},
+		create: func(r *reconciler, obj object) error {
+			p := tc.Profile{}
+			if err := decodeObject(obj, &p); err != nil {
+				return err
+			}
+			_, _, err := r.s.CreateProfile(p)
+			return err
+		},
+		update: func(r *reconciler, live object, obj object) error {
+			p := tc.Profile{}
+			if err := decodeObject(obj, &p); err != nil {
+				return err
+			}
+			_, _, err := r.s.UpdateProfileByID(p.ID, p)
+			return err
+		},
+		delete: func(r *reconciler, live object) error {
+			_, _, err := r.s.DeleteProfileByID(objectID(live))
+			return err
+		},
+	},
+	{
+		name:      "parameters",
+		newObj:    func() interface{} { return &tc.Parameter{} },
+		key:       parameterKey,
+		unordered: []string{"profiles"},
+		adjust:    adjustParameterProfiles,
+		list: func(r *reconciler) ([]object, error) {
+			params, _, err := r.s.GetParameters()
+			if err != nil {
+				return nil, err
+			}
+			return toObjects(params)
+		},
+		create: func(r *reconciler, obj object) error {
+			p := tc.Parameter{}
+			if err := decodeObject(obj, &p); err != nil {
+				return err
+			}
+			p.Profiles = nil
+			if _, _, err := r.s.CreateParameter(p); err != nil {
+				return err
+			}
+			id, err := r.id("parameters", parameterKey(obj))
+			if err != nil {
+				return err
+			}
+			return r.assignParameter(id, nil, strs(obj["profiles"]))
+		},
+		update: func(r *reconciler, live object, obj object) error {
+			id := objectID(live)
+			if !equal(obj["secure"], live["secure"]) {
+				p := tc.Parameter{}
+				if err := decodeObject(obj, &p); err != nil {
+					return err
+				}
+				p.Profiles = nil
+				if _, _, err := r.s.UpdateParameterByID(id, p); err != nil {
+					return err
+				}
+			}
+			return r.assignParameter(id, strs(live["profiles"]), strs(obj["profiles"]))
+		},
+		delete: func(r *reconciler, live object) error {
+			_, _, err := r.s.DeleteParameterByID(objectID(live))
+			return err
+		},
+	},
+	{
+		name:   "servers",
+		newObj: func() interface{} { return &tc.Server{} },
+		key:    fieldKey("hostName"),
+		refs: []reference{
+			{"cachegroup", "cachegroupId", "cachegroups"},
+			{"cdnName", "cdnId", "cdns"},
+			{"physLocation", "physLocationId", "phys_locations"},
+			{"profile", "profileId", "profiles"},
+			{"status", "statusId", "statuses"},
+			{"type", "typeId", "types"},
+		},
+		list: func(r *reconciler) ([]object, error) {
+			servers, _, err := r.s.GetServers()
+			if err != nil {
+				return nil, err
+			}
+			return toObjects(servers)
+		},
+		create: func(r *reconciler, obj object) error {
+			s := tc.Server{}
+			if err := decodeObject(obj, &s); err != nil {
+				return err
+			}
+			_, _, err := r.s.CreateServer(s)
+			return err
+		},
+		update: func(r *reconciler, live object, obj object) error {
+			s := tc.Server{}
+			if err := decodeObject(obj, &s); err != nil {
+				return err
+			}
+			_, _, err := r.s.UpdateServerByID(s.ID, s)
+			return err
+		},
+		delete: func(r *reconciler, live object) error {
+			_, _, err := r.s.DeleteServerByID(objectID(live))
+			return err
+		},
+	},
+	{
+		name:   "deliveryservices",
+		newObj: func() interface{} { return &tc.DeliveryServiceNullable{} },
+		key:    fieldKey("xmlId"),
+		refs: []reference{
+			{"cdnName", "cdnId", "cdns"},
+			{"type", "typeId", "types"},
+			{"tenant", "tenantId", "tenants"},
+			{"profileName", "profileId", "profiles"},
+		},
+		list: func(r *reconciler) ([]object, error) {
+			dses, _, err := r.s.GetDeliveryServicesNullable()
+			if err != nil {
+				return nil, err
+			}
+			return toObjects(dses)
+		},
+		create: func(r *reconciler, obj object) error {
+			ds := tc.DeliveryServiceNullable{}
+			if err := decodeObject(obj, &ds); err != nil {
+				return err
+			}
+			_, err := r.s.CreateDeliveryServiceNullable(&ds)
+			return err
+		},
+		update: func(r *reconciler, live object, obj object) error {
+			ds := tc.DeliveryServiceNullable{}
+			if err := decodeObject(obj, &ds); err != nil {
+				return err
+			}
+			_, err := r.s.UpdateDeliveryServiceNullable(strconv.Itoa(objectID(live)), &ds)
+			return err
+		},
+		delete: func(r *reconciler, live object) error {
+			_, err := r.s.DeleteDeliveryService(strconv.Itoa(objectID(live)))
+			return err
+		},
+	},
+	{
+		name:   "origins",
+		newObj: func() interface{} { return &tc.Origin{} },
+		key:    fieldKey("name"),
+		refs: []reference{
+			{"deliveryService", "deliveryServiceId", "deliveryservices"},
+			{"cachegroup", "cachegroupId", "cachegroups"},
+			{"coordinate", "coordinateId", "coordinates"},
+			{"profile", "profileId", "profiles"},
+			{"tenant", "tenantId", "tenants"},
+		},
+		list: func(r *reconciler) ([]object, error) {
+			origins, _, err := r.s.GetOrigins()
+			if err != nil {
+				return nil, err
+			}
+			return toObjects(origins)
+		},
+		create: func(r *reconciler, obj object) error {
+			o := tc.Origin{}
+			if err := decodeObject(obj, &o); err != nil {
+				return err
+			}
+			_, _, err := r.s.CreateOrigin(o)
+			return err
+		},
+		update: func(r *reconciler, live object, obj object) error {
+			o := tc.Origin{}
+			if err := decodeObject(obj, &o); err != nil {
+				return err
+			}
+			_, _, err := r.s.UpdateOriginByID(objectID(live), o)
+			return err
+		},
+		delete: func(r *reconciler, live object) error {
+			_, _, err := r.s.DeleteOriginByID(objectID(live))
+			return err
+		},
+	},
+	{
+		name:      "deliveryservice_servers",
+		newObj:    func() interface{} { return &tc.DeliveryServiceServers{} },
+		key:       fieldKey("xmlId"),
+		refs:      []reference{{"xmlId", "", "deliveryservices"}},
+		unordered: []string{"serverNames"},
+		list:      listDeliveryServiceServers,
+		create:    assignDeliveryServiceServers,
+		update: func(r *reconciler, live object, obj object) error {
+			return assignDeliveryServiceServers(r, obj)
+		},
+		delete: func(r *reconciler, live object) error {
+			dsID, err := r.id("deliveryservices", str(live["xmlId"]))
+			if err != nil {
+				return err
+			}
+			for _, name := range strs(live["serverNames"]) {
+				serverID, err := r.id("servers", name)
+				if err != nil {
+					return err
+				}
+				if _, _, err := r.s.DeleteDeliveryServiceServer(dsID, serverID); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+}
+
+// deliveryServiceServersLimit is the limit of Delivery Service server assignments requested from
+// Traffic Ops, which is otherwise limited to a small page.
+const deliveryServiceServersLimit = 1 << 30
+
+// listDeliveryServiceServers returns the servers assigned to each Delivery Service which has any,
+// as the tc.DeliveryServiceServers the enroller takes.
+func listDeliveryServiceServers(r *reconciler) ([]object, error) {
+	dses, err := r.liveObjects("deliveryservices")
+	if err != nil {
+		return nil, err
+	}
+	servers, err := r.liveObjects("servers")
+	if err != nil {
+		return nil, err
+	}
+	xmlIDs := map[int]string{}
+	for _, ds := range dses {
+		xmlIDs[objectID(ds)] = str(ds["xmlId"])
+	}
+	hostNames := map[int]string{}
+	for _, server := range servers {
+		hostNames[objectID(server)] = str(server["hostName"])
+	}
+
+	resp, _, err := r.s.GetDeliveryServiceServersN(deliveryServiceServersLimit)
+	if err != nil {
+		return nil, err
+	}
+	assigned := map[string][]string{}
+	for _, dss := range resp.Response {
+		if dss.DeliveryService == nil || dss.Server == nil {
+			continue
+		}
+		xmlID := xmlIDs[*dss.DeliveryService]
+		assigned[xmlID] = append(assigned[xmlID], hostNames[*dss.Server])
+	}
+	objs := make([]object, 0, len(assigned))
+	for xmlID, names := range assigned {
+		sort.Strings(names)
+		objs = append(objs, object{"xmlId": xmlID, "serverNames": toInterfaces(names)})
+	}
+	return objs, nil
+}
+
+// assignDeliveryServiceServers assigns exactly the servers of obj to its Delivery Service.
+func assignDeliveryServiceServers(r *reconciler, obj object) error {
+	dsID, err := r.id("deliveryservices", str(obj["xmlId"]))
+	if err != nil {
+		return err
+	}
+	serverIDs := []int{}
+	for _, name := range strs(obj["serverNames"]) {
+		id, err := r.id("servers", name)
+		if err != nil {
+			return err
+		}
+		serverIDs = append(serverIDs, id)
+	}
+	_, err = r.s.CreateDeliveryServiceServers(dsID, serverIDs, true)
+	return err
+}
+
+// parameterKey identifies a Parameter by its config file, name and value, as the enroller does.
+func parameterKey(obj object) string {
+	return str(obj["configFile"]) + "/" + str(obj["name"]) + "=" + str(obj["value"])
+}
+
+// adjustParameterProfiles returns the desired Parameter with the Profiles it should be assigned to:
+// its declared Profiles, and any it's assigned to whose files don't list their Parameters.
+// Undeclared Parameters are desired only to remove them from Profiles whose files list their
+// Parameters.
+func adjustParameterProfiles(r *reconciler, desired object, live object) object {
+	profiles := map[string]bool{}
+	for _, p := range strs(live["profiles"]) {
+		if !r.paramProfiles[p] {
+			profiles[p] = true
+		}
+	}
+	if desired == nil {
+		if len(profiles) == len(strs(live["profiles"])) {
+			return nil
+		}
+		desired = object{"configFile": live["configFile"], "name": live["name"], "value": live["value"]}
+	}
+	for _, p := range strs(desired["profiles"]) {
+		profiles[p] = true
+	}
+	names := []string{}
+	for p := range profiles {
+		names = append(names, p)
+	}
+	sort.Strings(names)
+	adjusted := copyObject(desired)
+	adjusted["profiles"] = toInterfaces(names)
+	return adjusted
+}
+
+// assignParameter assigns the Parameter to the Profiles it should be assigned to, and unassigns it
+// from those it shouldn't.
+func (r *reconciler) assignParameter(id int, assigned []string, profiles []string) error {
+	current := map[string]bool{}
+	for _, p := range assigned {
+		current[p] = true
+	}
+	desired := map[string]bool{}
+	for _, p := range profiles {
+		desired[p] = true
+		if current[p] {
+			continue
+		}
+		profileID, err := r.id("profiles", p)
+		if err != nil {
+			return err
+		}
+		if _, _, err := r.s.CreateProfileParameter(tc.ProfileParameter{ProfileID: profileID, ParameterID: id}); err != nil {
+			return err
+		}
+	}
+	for _, p := range assigned {
+		if desired[p] {
+			continue
+		}
+		profileID, err := r.id("profiles", p)
+		if err != nil {
+			return err
+		}
+		if _, _, err := r.s.DeleteParameterByProfileParameter(profileID, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// kindsByName is the kinds, keyed on their names.
+var kindsByName = map[string]*kind{}
+
+func init() {
+	for _, k := range kinds {
+		kindsByName[k.name] = k
+	}
+}
+
+func kindByName(name string) *kind {
+	return kindsByName[name]
+}
+
+// desiredState is the desired objects of each kind, as declared in a directory.
+type desiredState struct {
+	objects map[string][]object
+	// managed is the kinds with a directory of desired objects; only their objects are deleted.
+	managed map[string]bool
+	// paramProfiles is the Profiles whose files list their Parameters, which are assigned exactly
+	// those Parameters.
+	paramProfiles map[string]bool
+}
+
+// envVarRegex matches references to environment variables, e.g. $CDN_NAME or ${CDN_NAME}.
+var envVarRegex = regexp.MustCompile(`\$\{?([A-Za-z_][A-Za-z0-9_]*)\}?`)
+
+// expandEnv replaces references to environment variables which are set by their values, as
+// envsubst does for the files the enroller watches for, and leaves all others alone.
+func expandEnv(b []byte) []byte {
+	return envVarRegex.ReplaceAllFunc(b, func(ref []byte) []byte {
+		name := envVarRegex.FindSubmatch(ref)[1]
+		if val, ok := os.LookupEnv(string(name)); ok {
+			return []byte(val)
+		}
+		return ref
+	})
+}
+
+// loadDesiredState reads the desired objects of each kind from the JSON and YAML files in the
+// subdirectory of dir named for the kind, e.g. dir/cdns/*.json. Each file holds one object, in the
+// same form the enroller takes, or an array of them.
+//
+// Profiles' "params" are moved to the Parameters they declare.
+func loadDesiredState(dir string) (desiredState, error) {
+	st := desiredState{objects: map[string][]object{}, managed: map[string]bool{}, paramProfiles: map[string]bool{}}
+	dirs, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return st, errors.New("reading desired state directory: " + err.Error())
+	}
+	for _, d := range dirs {
+		if !d.IsDir() || strings.HasPrefix(d.Name(), ".") {
+			continue
+		}
+		k := kindByName(d.Name())
+		if k == nil || k.readOnly() {
+			return st, errors.New("unsupported object type directory '" + d.Name() + "'")
+		}
+		st.managed[k.name] = true
+		files, err := ioutil.ReadDir(filepath.Join(dir, d.Name()))
+		if err != nil {
+			return st, errors.New("reading " + k.name + " directory: " + err.Error())
+		}
+		keys := map[string]bool{}
+		for _, f := range files {
+			ext := filepath.Ext(f.Name())
+			if f.IsDir() || (ext != ".json" && ext != ".yaml" && ext != ".yml") {
+				continue
+			}
+			path := filepath.Join(k.name, f.Name())
+			objs, err := loadObjects(k, filepath.Join(dir, path))
+			if err != nil {
+				return st, errors.New(path + ": " + err.Error())
+			}
+			for _, obj := range objs {
+				if k.name == "parameters" {
+					addParameter(&st, obj)
+					continue
+				}
+				key := k.key(obj)
+				if keys[key] {
+					return st, errors.New(path + ": duplicate " + k.name + " '" + key + "'")
+				}
+				keys[key] = true
+				st.objects[k.name] = append(st.objects[k.name], obj)
+			}
+		}
+	}
+
+	paramKind := kindByName("parameters")
+	for _, profile := range st.objects["profiles"] {
+		params, ok := profile["params"]
+		if !ok {
+			continue
+		}
+		delete(profile, "params")
+		name := str(profile["name"])
+		st.paramProfiles[name] = true
+		paramList, ok := params.([]interface{})
+		if !ok && params != nil {
+			return st, errors.New("profiles " + name + ": params must be an array")
+		}
+		for _, param := range paramList {
+			obj, err := canonicalObject(paramKind, param)
+			if err != nil {
+				return st, errors.New("profiles " + name + ": params: " + err.Error())
+			}
+			obj["profiles"] = []interface{}{name}
+			addParameter(&st, obj)
+		}
+	}
+	return st, nil
+}
+
+// addParameter adds the desired Parameter, or, if it's already desired, adds its Profiles.
+func addParameter(st *desiredState, obj object) {
+	key := parameterKey(obj)
+	for _, existing := range st.objects["parameters"] {
+		if parameterKey(existing) == key {
+			existing["profiles"] = toInterfaces(append(strs(existing["profiles"]), strs(obj["profiles"])...))
+			return
+		}
+	}
+	st.objects["parameters"] = append(st.objects["parameters"], obj)
+}
+
+// loadObjects reads the objects of the kind in the JSON or YAML file at path.
+func loadObjects(k *kind, path string) ([]object, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	b = expandEnv(b)
+
+	v := interface{}(nil)
+	if filepath.Ext(path) == ".json" {
+		err = json.Unmarshal(b, &v)
+	} else {
+		err = yaml.Unmarshal(b, &v)
+	}
+	if err != nil {
+		return nil, errors.New("decoding: " + err.Error())
+	}
+	items, ok := jsonCompatible(v).([]interface{})
+	if !ok {
+		items = []interface{}{v}
+	}
+
+	objs := []object{}
+	for _, item := range items {
+		obj, err := canonicalObject(k, item)
+		if err != nil {
+			return nil, err
+		}
+		if k.key(obj) == "" {
+			return nil, errors.New("object has no key")
+		}
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
+
+// canonicalObject returns the decoded JSON item as an object of the kind, with its fields named
+// exactly as Traffic Ops names them; they're matched case-insensitively, as encoding/json does.
+// Returns an error if the item isn't a valid object of the kind.
+func canonicalObject(k *kind, item interface{}) (object, error) {
+	b, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+	raw := object{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, errors.New("must be an object or an array of objects")
+	}
+	if err := json.Unmarshal(b, k.newObj()); err != nil {
+		return nil, err
+	}
+	fields := jsonFields(reflect.TypeOf(k.newObj()).Elem())
+	obj := object{}
+	for field, val := range raw {
+		name, ok := fields[strings.ToLower(field)]
+		if !ok {
+			return nil, errors.New("unknown field '" + field + "'")
+		}
+		obj[name] = val
+	}
+	return obj, nil
+}
+
+// jsonFields returns the JSON names of the fields of the struct type t, including those of its
+// embedded structs, keyed on their lower-case names.
+func jsonFields(t reflect.Type) map[string]string {
+	fields := map[string]string{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			for lower, embedded := range jsonFields(ft) {
+				if _, ok := fields[lower]; !ok {
+					fields[lower] = embedded
+				}
+			}
+			continue
+		}
+		if f.PkgPath != "" {
+			continue // unexported
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[strings.ToLower(name)] = name
+	}
+	return fields
+}
+
+// jsonCompatible returns obj, as unmarshalled from YAML, with its maps' keys converted to strings,
+// so that it can be marshalled as JSON.
+func jsonCompatible(obj interface{}) interface{} {
+	switch v := obj.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			m[fmt.Sprint(key)] = jsonCompatible(val)
+		}
+		return m
+	case []interface{}:
+		for i, val := range v {
+			v[i] = jsonCompatible(val)
+		}
+	}
+	return obj
+}
+
+// action is what a change does to an object.
+type action string
+
+const (
+	actionCreate = action("create")
+	actionUpdate = action("update")
+	actionDelete = action("delete")
+)
+
+// fieldDiff is a field of an object which differs from its desired value.
+type fieldDiff struct {
+	field string
+	from  interface{}
+	to    interface{}
+}
+
+// change is a single change to Traffic Ops, which brings one object to its desired state.
+type change struct {
+	action action
+	kind   *kind
+	key    string
+	// obj is the object to create, or the live object with the desired fields applied to update.
+	obj object
+	// live is the object to update or delete.
+	live  object
+	diffs []fieldDiff
+}
+
+// reconciler plans and applies the changes which bring Traffic Ops to a desired state.
+type reconciler struct {
+	desiredState
+	s     *session
+	prune bool
+	// live is the live objects of each kind, loaded as they're needed.
+	live map[string][]object
+	// user is the name of the user the reconciler is logged in as, which it never deletes.
+	user string
+}
+
+// liveObjects returns the objects of the kind which exist in Traffic Ops.
+func (r *reconciler) liveObjects(kindName string) ([]object, error) {
+	if objs, ok := r.live[kindName]; ok {
+		return objs, nil
+	}
+	k := kindByName(kindName)
+	if k == nil {
+		return nil, errors.New("unknown object type " + kindName)
+	}
+	objs, err := k.list(r)
+	if err != nil {
+		return nil, errors.New("getting " + kindName + ": " + err.Error())
+	}
+	r.live[kindName] = objs
+	return objs, nil
+}
+
+// id returns the ID of the live object of the kind with the given key. If there is none, the
+// kind's objects are reloaded, in case it's been created since they were loaded.
+func (r *reconciler) id(kindName string, key string) (int, error) {
+	k := kindByName(kindName)
+	for reloaded := false; ; reloaded = true {
+		objs, err := r.liveObjects(kindName)
+		if err != nil {
+			return 0, err
+		}
+		for _, obj := range objs {
+			if k.key(obj) == key {
+				return objectID(obj), nil
+			}
+		}
+		if reloaded {
+			return 0, errors.New(kindName + " '" + key + "' does not exist")
+		}
+		delete(r.live, kindName)
+	}
+}
+
+// checkRefs returns an error if the object refers to an object which neither exists nor is desired.
+func (r *reconciler) checkRefs(k *kind, obj object) error {
+	for _, ref := range k.refs {
+		name := str(obj[ref.nameField])
+		if name == "" {
+			continue
+		}
+		refKind := kindByName(ref.kind)
+		found := false
+		for _, desired := range r.objects[ref.kind] {
+			if refKind.key(desired) == name {
+				found = true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+		live, err := r.liveObjects(ref.kind)
+		if err != nil {
+			return err
+		}
+		for _, liveObj := range live {
+			if refKind.key(liveObj) == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return errors.New(ref.nameField + " '" + name + "' does not exist, and isn't declared")
+		}
+	}
+	return nil
+}
+
+// referenced returns whether any desired object refers to the object of the kind with the key, in
+// which case it's kept even if it isn't declared, e.g. the root Tenant.
+func (r *reconciler) referenced(kindName string, key string) bool {
+	for _, k := range kinds {
+		for _, ref := range k.refs {
+			if ref.kind != kindName {
+				continue
+			}
+			for _, obj := range r.objects[k.name] {
+				if str(obj[ref.nameField]) == key {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// resolve returns the object with the ID fields of its references set to the IDs of the objects
+// they refer to.
+func (r *reconciler) resolve(k *kind, obj object) (object, error) {
+	resolved := copyObject(obj)
+	for _, ref := range k.refs {
+		val, ok := obj[ref.nameField]
+		if !ok || ref.idField == "" {
+			continue
+		}
+		name := str(val)
+		if name == "" {
+			resolved[ref.idField] = nil
+			continue
+		}
+		id, err := r.id(ref.kind, name)
+		if err != nil {
+			return nil, err
+		}
+		resolved[ref.idField] = id
+	}
+	return resolved, nil
+}
+
+// plan returns the changes which bring Traffic Ops to the desired state, in the order they must be
+// applied: creates and updates in dependency order, then deletes in the reverse order. Objects are
+// only deleted if pruning, if their kind's directory exists, and if no desired object refers to
+// them; the user the reconciler is logged in as is never deleted.
+func (r *reconciler) plan() ([]change, error) {
+	changes := []change{}
+	deletes := []change{}
+	errs := []string{}
+	for _, k := range kinds {
+		if k.readOnly() || (len(r.objects[k.name]) == 0 && !r.managed[k.name]) {
+			continue
+		}
+		live, err := r.liveObjects(k.name)
+		if err != nil {
+			return nil, err
+		}
+		liveByKey := make(map[string]object, len(live))
+		for _, obj := range live {
+			liveByKey[k.key(obj)] = obj
+		}
+
+		kindChanges := []change{}
+		declared := map[string]bool{}
+		for _, obj := range r.objects[k.name] {
+			key := k.key(obj)
+			declared[key] = true
+			if err := r.checkRefs(k, obj); err != nil {
+				errs = append(errs, k.name+" "+key+": "+err.Error())
+				continue
+			}
+			liveObj, ok := liveByKey[key]
+			if !ok {
+				kindChanges = append(kindChanges, change{action: actionCreate, kind: k, key: key, obj: obj})
+				continue
+			}
+			if k.adjust != nil {
+				obj = k.adjust(r, obj, liveObj)
+			}
+			if diffs := k.diff(obj, liveObj); len(diffs) > 0 {
+				kindChanges = append(kindChanges, change{action: actionUpdate, kind: k, key: key, obj: mergeObjects(liveObj, obj), live: liveObj, diffs: diffs})
+			}
+		}
+
+		kindDeletes := []change{}
+		for _, liveObj := range live {
+			key := k.key(liveObj)
+			if declared[key] {
+				continue
+			}
+			if r.prune && r.managed[k.name] && !(k.name == "users" && key == r.user) && !r.referenced(k.name, key) {
+				kindDeletes = append(kindDeletes, change{action: actionDelete, kind: k, key: key, live: liveObj})
+				continue
+			}
+			if k.adjust == nil {
+				continue
+			}
+			if obj := k.adjust(r, nil, liveObj); obj != nil {
+				if diffs := k.diff(obj, liveObj); len(diffs) > 0 {
+					kindChanges = append(kindChanges, change{action: actionUpdate, kind: k, key: key, obj: mergeObjects(liveObj, obj), live: liveObj, diffs: diffs})
+				}
+			}
+		}
+
+		ordered, err := orderChanges(k, kindChanges)
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+		changes = append(changes, ordered...)
+		if ordered, err = orderChanges(k, kindDeletes); err != nil {
+			errs = append(errs, err.Error())
+		}
+		for i := len(ordered) - 1; i >= 0; i-- {
+			deletes = append([]change{ordered[i]}, deletes...)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.New(strings.Join(errs, "; "))
+	}
+	return append(changes, deletes...), nil
+}
+
+// diff returns the fields of the desired object which differ from the live object. Fields the
+// desired object doesn't have aren't compared, nor are write-only fields.
+func (k *kind) diff(desired object, live object) []fieldDiff {
+	fields := make([]string, 0, len(desired))
+	for field := range desired {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	diffs := []fieldDiff{}
+	for _, field := range fields {
+		if contains(k.writeOnly, field) {
+			continue
+		}
+		to, from := desired[field], live[field]
+		if contains(k.unordered, field) {
+			to, from = sortedStrs(to), sortedStrs(from)
+		}
+		if !equal(to, from) {
+			diffs = append(diffs, fieldDiff{field: field, from: live[field], to: desired[field]})
+		}
+	}
+	return diffs
+}
+
+// orderChanges orders the changes to objects of the kind such that each comes after the changes to
+// any objects of the same kind it refers to, e.g. a Tenant after its parent. Returns an error if an
+// object refers to itself, directly or indirectly.
+func orderChanges(k *kind, changes []change) ([]change, error) {
+	byKey := make(map[string]int, len(changes))
+	for i, c := range changes {
+		byKey[c.key] = i
+	}
+	const visiting, visited = 1, 2
+	states := make([]int, len(changes))
+	ordered := make([]change, 0, len(changes))
+	visit := (func(int) error)(nil)
+	visit = func(i int) error {
+		if states[i] == visited {
+			return nil
+		}
+		if states[i] == visiting {
+			return errors.New(k.name + " " + changes[i].key + " refers to itself")
+		}
+		states[i] = visiting
+		obj := changes[i].obj
+		if obj == nil {
+			obj = changes[i].live
+		}
+		for _, ref := range k.refs {
+			if ref.kind != k.name {
+				continue
+			}
+			if j, ok := byKey[str(obj[ref.nameField])]; ok && j != i {
+				if err := visit(j); err != nil {
+					return err
+				}
+			}
+		}
+		states[i] = visited
+		ordered = append(ordered, changes[i])
+		return nil
+	}
+	for i := range changes {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// apply applies the changes in order, writing each to w as it's applied, and stops at the first
+// which fails.
+func (r *reconciler) apply(changes []change, w io.Writer) error {
+	for _, c := range changes {
+		fmt.Fprintf(w, "%sing %s %s\n", strings.TrimSuffix(string(c.action), "e"), c.kind.name, c.key)
+		err := error(nil)
+		switch c.action {
+		case actionCreate, actionUpdate:
+			obj := object(nil)
+			if obj, err = r.resolve(c.kind, c.obj); err != nil {
+				break
+			}
+			if c.action == actionCreate {
+				err = c.kind.create(r, obj)
+			} else {
+				err = c.kind.update(r, c.live, obj)
+			}
+		case actionDelete:
+			err = c.kind.delete(r, c.live)
+		}
+		if err != nil {
+			return fmt.Errorf("%s %s %s: %s", c.action, c.kind.name, c.key, err.Error())
+		}
+	}
+	return nil
+}
+
+// printPlan writes the changes, and a summary of them, to w.
+func printPlan(w io.Writer, changes []change) {
+	counts := map[action]int{}
+	for _, c := range changes {
+		counts[c.action]++
+		switch c.action {
+		case actionCreate:
+			fmt.Fprintf(w, "+ %s %s\n", c.kind.name, c.key)
+		case actionUpdate:
+			fmt.Fprintf(w, "~ %s %s\n", c.kind.name, c.key)
+			for _, d := range c.diffs {
+				from, _ := json.Marshal(d.from)
+				to, _ := json.Marshal(d.to)
+				fmt.Fprintf(w, "    %s: %s -> %s\n", d.field, from, to)
+			}
+		case actionDelete:
+			fmt.Fprintf(w, "- %s %s\n", c.kind.name, c.key)
+		}
+	}
+	fmt.Fprintf(w, "Plan: %d to create, %d to update, %d to delete.\n", counts[actionCreate], counts[actionUpdate], counts[actionDelete])
+}
+
+// reconcile brings Traffic Ops to the desired state declared in dir: it writes the plan of changes
+// to w and, if apply is true, applies them. Undeclared objects are only deleted if prune is true.
+func reconcile(toSession *session, dir string, apply bool, prune bool, w io.Writer) error {
+	st, err := loadDesiredState(dir)
+	if err != nil {
+		return err
+	}
+	r := &reconciler{desiredState: st, s: toSession, prune: prune, live: map[string][]object{}, user: toSession.UserName}
+	changes, err := r.plan()
+	if err != nil {
+		return errors.New("planning: " + err.Error())
+	}
+	printPlan(w, changes)
+	if !apply || len(changes) == 0 {
+		return nil
+	}
+	if err := r.apply(changes, w); err != nil {
+		return errors.New("applying: " + err.Error())
+	}
+	fmt.Fprintln(w, "Applied.")
+	return nil
+}
+
+// toObjects converts a slice of Go client objects to objects.
+func toObjects(v interface{}) ([]object, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	objs := []object{}
+	err = json.Unmarshal(b, &objs)
+	return objs, err
+}
+
+// decodeObject decodes the object into v, a pointer to a Go client object.
+func decodeObject(obj object, v interface{}) error {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// objectID returns the ID of the live object.
+func objectID(obj object) int {
+	id, _ := obj["id"].(float64)
+	return int(id)
+}
+
+func fieldKey(field string) func(object) string {
+	return func(obj object) string {
+		return str(obj[field])
+	}
+}
+
+// str returns the JSON value as a string: strings as they are, numbers without exponents, and
+// null as "".
+func str(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(val)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// strs returns the JSON array as strings, or nil if it isn't an array.
+func strs(v interface{}) []string {
+	arr, ok := v.([]interface{})
+	if !ok {
+		return nil
+	}
+	ss := make([]string, 0, len(arr))
+	for _, val := range arr {
+		ss = append(ss, str(val))
+	}
+	return ss
+}
+
+// sortedStrs returns the JSON array of strings sorted, and without duplicates.
+func sortedStrs(v interface{}) interface{} {
+	ss := strs(v)
+	if ss == nil {
+		return v
+	}
+	sort.Strings(ss)
+	unique := []string{}
+	for i, s := range ss {
+		if i == 0 || s != ss[i-1] {
+			unique = append(unique, s)
+		}
+	}
+	return toInterfaces(unique)
+}
+
+func toInterfaces(ss []string) []interface{} {
+	vals := make([]interface{}, 0, len(ss))
+	for _, s := range ss {
+		vals = append(vals, s)
+	}
+	return vals
+}
+
+// equal returns whether the JSON values are equal; null, false, 0, "" and empty arrays and objects
+// are all equal, because Traffic Ops doesn't distinguish them.
+func equal(a interface{}, b interface{}) bool {
+	return reflect.DeepEqual(a, b) || (isZero(a) && isZero(b))
+}
+
+func isZero(v interface{}) bool {
+	switch val := v.(type) {
+	case nil:
+		return true
+	case bool:
+		return !val
+	case float64:
+		return val == 0
+	case string:
+		return val == ""
+	case []interface{}:
+		return len(val) == 0
+	case map[string]interface{}:
+		return len(val) == 0
+	}
+	return false
+}
+
+func contains(ss []string, s string) bool {
+	for _, val := range ss {
+		if val == s {
+			return true
+		}
+	}
+	return false
+}
+
+func copyObject(obj object) object {
+	cp := make(object, len(obj))
+	for k, v := range obj {
+		cp[k] = v
+	}
+	return cp
+}
+
+// mergeObjects returns the live object with the desired object's fields.
+func mergeObjects(live object, desired object) object {
+	merged := copyObject(live)
+	for k, v := range desired {
+		merged[k] = v
+	}
+	return merged
+}
@@ -0,0 +1,300 @@
+package main
+
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, dir string, name string, contents string) {
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "enroller-reconcile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestLoadDesiredState(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	os.Setenv("RECONCILE_TEST_CDN", "cdn-a")
+	defer os.Unsetenv("RECONCILE_TEST_CDN")
+
+	writeFile(t, dir, "cdns/010-cdn.json", `{"name": "$RECONCILE_TEST_CDN", "domainName": "${RECONCILE_TEST_CDN}.test", "dnssecEnabled": false}`)
+	writeFile(t, dir, "cdns/README", `not an object`)
+	writeFile(t, dir, "users/010-users.yaml", `
+- username: alice
+  rolename: admin
+  tenant: root
+  email: $UNSET_RECONCILE_TEST_VAR
+`)
+	writeFile(t, dir, "profiles/010-edge.json", `{"name": "EDGE", "cdnName": "cdn-a", "type": "ATS_PROFILE", "params": [
+		{"configFile": "records.config", "name": "CONFIG proxy.config.http.cache.http", "value": "INT 1"}
+	]}`)
+	writeFile(t, dir, "parameters/010-params.json", `[
+		{"configFile": "records.config", "name": "CONFIG proxy.config.http.cache.http", "value": "INT 1", "profiles": ["MID"]},
+		{"configFile": "global", "name": "tm.url", "value": "https://tm.test"}
+	]`)
+
+	st, err := loadDesiredState(dir)
+	if err != nil {
+		t.Fatalf("expected no error loading desired state, actual: %v", err)
+	}
+
+	expectedManaged := map[string]bool{"cdns": true, "users": true, "profiles": true, "parameters": true}
+	if !reflect.DeepEqual(st.managed, expectedManaged) {
+		t.Errorf("expected managed kinds %v, actual: %v", expectedManaged, st.managed)
+	}
+
+	cdns := st.objects["cdns"]
+	if len(cdns) != 1 || cdns[0]["name"] != "cdn-a" || cdns[0]["domainName"] != "cdn-a.test" {
+		t.Errorf("expected environment variables in cdns to be expanded, actual: %v", cdns)
+	}
+
+	users := st.objects["users"]
+	if len(users) != 1 {
+		t.Fatalf("expected 1 user, actual: %v", users)
+	}
+	if users[0]["roleName"] != "admin" {
+		t.Errorf("expected user field 'rolename' to be canonicalised to 'roleName', actual: %v", users[0])
+	}
+	if users[0]["email"] != "$UNSET_RECONCILE_TEST_VAR" {
+		t.Errorf("expected unset environment variable to be left alone, actual: %v", users[0]["email"])
+	}
+
+	profiles := st.objects["profiles"]
+	if len(profiles) != 1 {
+		t.Fatalf("expected 1 profile, actual: %v", profiles)
+	}
+	if _, ok := profiles[0]["params"]; ok {
+		t.Errorf("expected profile params to be moved to parameters, actual: %v", profiles[0])
+	}
+	if !st.paramProfiles["EDGE"] {
+		t.Errorf("expected profile EDGE to have its parameters declared")
+	}
+
+	params := map[string]interface{}{}
+	for _, p := range st.objects["parameters"] {
+		params[parameterKey(p)] = sortedStrs(p["profiles"])
+	}
+	expectedParams := map[string]interface{}{
+		"records.config/CONFIG proxy.config.http.cache.http=INT 1": []interface{}{"EDGE", "MID"},
+		"global/tm.url=https://tm.test":                            nil,
+	}
+	if !reflect.DeepEqual(params, expectedParams) {
+		t.Errorf("expected parameters %v, actual: %v", expectedParams, params)
+	}
+}
+
+func TestLoadDesiredStateErrors(t *testing.T) {
+	tests := map[string]map[string]string{
+		"unsupported object type directory": {"widgets/010-widget.json": `{"name": "w"}`},
+		"read-only object type directory":   {"roles/010-role.json": `{"name": "admin"}`},
+		"unknown field":                     {"cdns/010-cdn.json": `{"name": "cdn-a", "colour": "blue"}`},
+		"wrongly typed field":               {"cdns/010-cdn.json": `{"name": "cdn-a", "dnssecEnabled": "yes"}`},
+		"object without a key":              {"cdns/010-cdn.json": `{"domainName": "cdn-a.test"}`},
+		"duplicate object": {
+			"cdns/010-cdn.json": `{"name": "cdn-a"}`,
+			"cdns/020-cdn.yml":  `name: cdn-a`,
+		},
+	}
+	for name, files := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer os.RemoveAll(dir)
+			for file, contents := range files {
+				writeFile(t, dir, file, contents)
+			}
+			if _, err := loadDesiredState(dir); err == nil {
+				t.Errorf("expected an error loading desired state, actual: nil")
+			}
+		})
+	}
+}
+
+// testReconciler returns a reconciler for the desired objects, with the given live objects, which
+// plans without a Traffic Ops session.
+func testReconciler(desired map[string][]object, live map[string][]object, prune bool) *reconciler {
+	st := desiredState{objects: desired, managed: map[string]bool{}, paramProfiles: map[string]bool{}}
+	for k := range desired {
+		st.managed[k] = true
+	}
+	r := &reconciler{desiredState: st, prune: prune, live: map[string][]object{}, user: "admin"}
+	for _, k := range kinds {
+		r.live[k.name] = []object{}
+	}
+	for k, objs := range live {
+		r.live[k] = objs
+	}
+	return r
+}
+
+func summarise(changes []change) []string {
+	summary := []string{}
+	for _, c := range changes {
+		summary = append(summary, string(c.action)+" "+c.kind.name+" "+c.key)
+	}
+	return summary
+}
+
+func TestPlan(t *testing.T) {
+	desired := map[string][]object{
+		"cdns": {
+			{"name": "cdn-a", "domainName": "a.test"},
+			{"name": "cdn-b", "domainName": "b.test", "dnssecEnabled": false},
+			{"name": "cdn-c", "domainName": "c.test"},
+		},
+		"tenants": {
+			{"name": "child", "parentName": "parent", "active": true},
+			{"name": "parent", "parentName": "root", "active": true},
+		},
+		"users": {
+			{"username": "alice", "roleName": "admin", "tenant": "root", "localPasswd": "secret"},
+		},
+	}
+	live := map[string][]object{
+		"cdns": {
+			{"id": float64(1), "name": "cdn-a", "domainName": "old.test"},
+			{"id": float64(2), "name": "cdn-b", "domainName": "b.test", "lastUpdated": "2019-01-01"},
+			{"id": float64(3), "name": "cdn-old", "domainName": "old.test"},
+		},
+		"tenants": {
+			{"id": float64(1), "name": "root", "active": true},
+		},
+		"roles": {
+			{"id": float64(1), "name": "admin"},
+		},
+		"users": {
+			{"id": float64(1), "username": "admin", "roleName": "admin"},
+			{"id": float64(2), "username": "alice", "roleName": "admin", "tenant": "root"},
+			{"id": float64(3), "username": "bob", "roleName": "admin", "tenant": "root"},
+		},
+	}
+
+	changes, err := testReconciler(desired, live, false).plan()
+	if err != nil {
+		t.Fatalf("expected no error planning, actual: %v", err)
+	}
+	expected := []string{
+		"update cdns cdn-a",
+		"create cdns cdn-c",
+		"create tenants parent",
+		"create tenants child",
+	}
+	if actual := summarise(changes); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected changes %v, actual: %v", expected, actual)
+	}
+	if expected := []fieldDiff{{"domainName", "old.test", "a.test"}}; !reflect.DeepEqual(changes[0].diffs, expected) {
+		t.Errorf("expected update diffs %v, actual: %v", expected, changes[0].diffs)
+	}
+	if changes[0].obj["id"] != float64(1) || changes[0].obj["domainName"] != "a.test" {
+		t.Errorf("expected update to apply desired fields to the live object, actual: %v", changes[0].obj)
+	}
+
+	changes, err = testReconciler(desired, live, true).plan()
+	if err != nil {
+		t.Fatalf("expected no error planning with prune, actual: %v", err)
+	}
+	expected = append(expected, "delete users bob", "delete cdns cdn-old")
+	if actual := summarise(changes); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected changes with prune %v, actual: %v", expected, actual)
+	}
+
+	buf := &bytes.Buffer{}
+	printPlan(buf, changes)
+	expectedPlan := `~ cdns cdn-a
+    domainName: "old.test" -> "a.test"
++ cdns cdn-c
++ tenants parent
++ tenants child
+- users bob
+- cdns cdn-old
+Plan: 3 to create, 1 to update, 2 to delete.
+`
+	if buf.String() != expectedPlan {
+		t.Errorf("expected plan:\n%s\nactual:\n%s", expectedPlan, buf.String())
+	}
+}
+
+func TestPlanErrors(t *testing.T) {
+	desired := map[string][]object{
+		"regions": {{"name": "region-a", "divisionName": "nowhere"}},
+		"tenants": {
+			{"name": "a", "parentName": "b"},
+			{"name": "b", "parentName": "a"},
+		},
+	}
+	_, err := testReconciler(desired, nil, false).plan()
+	if err == nil {
+		t.Fatal("expected an error planning, actual: nil")
+	}
+	for _, expected := range []string{"regions region-a: divisionName 'nowhere' does not exist", "refers to itself"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error to contain '%s', actual: %v", expected, err)
+		}
+	}
+}
+
+func TestPlanParameterProfiles(t *testing.T) {
+	desired := map[string][]object{
+		"parameters": {
+			{"configFile": "records.config", "name": "a", "value": "1", "profiles": []interface{}{"EDGE"}},
+		},
+	}
+	live := map[string][]object{
+		"parameters": {
+			{"id": float64(1), "configFile": "records.config", "name": "a", "value": "1", "profiles": []interface{}{"OTHER"}},
+			{"id": float64(2), "configFile": "records.config", "name": "b", "value": "2", "profiles": []interface{}{"EDGE", "OTHER"}},
+			{"id": float64(3), "configFile": "records.config", "name": "c", "value": "3", "profiles": []interface{}{"OTHER"}},
+		},
+	}
+	r := testReconciler(desired, live, false)
+	r.paramProfiles["EDGE"] = true
+
+	changes, err := r.plan()
+	if err != nil {
+		t.Fatalf("expected no error planning, actual: %v", err)
+	}
+	expected := []string{"update parameters records.config/a=1", "update parameters records.config/b=2"}
+	if actual := summarise(changes); !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("expected changes %v, actual: %v", expected, actual)
+	}
+	expectedProfiles := [][]interface{}{{"EDGE", "OTHER"}, {"OTHER"}}
+	for i, c := range changes {
+		if !reflect.DeepEqual(c.obj["profiles"], expectedProfiles[i]) {
+			t.Errorf("expected %s profiles %v, actual: %v", c.key, expectedProfiles[i], c.obj["profiles"])
+		}
+	}
+}
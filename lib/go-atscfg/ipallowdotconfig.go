@@ -47,6 +47,8 @@ const ParamCoalesceNumberV6 = "coalesce_number_v6"
 type IPAllowServer struct {
 	IPAddress  string
 	IP6Address string
+	// IPAddresses are the addresses of all the server's interfaces, service or not, any of which children may connect from. They may include a prefix length, which is ignored.
+	IPAddresses []string
 }
 
 const DefaultCoalesceMaskLenV4 = 24
@@ -196,6 +198,22 @@ func MakeIPAllowDotConfig(
 					}
 				}
 			}
+
+			for _, addr := range server.IPAddresses {
+				ip := hostIP(addr)
+				if ip == nil {
+					log.Errorln("MakeIPAllowDotConfig server '" + string(serverName) + "' interface address '" + addr + "' is not an IP address or CIDR - skipping!")
+					continue
+				}
+				if ip.Equal(hostIP(server.IPAddress)) || ip.Equal(hostIP(server.IP6Address)) {
+					continue // already added
+				}
+				if ip4 := ip.To4(); ip4 != nil {
+					ips = append(ips, util.IPToCIDR(ip4))
+				} else {
+					ip6s = append(ip6s, util.IPToCIDR(ip))
+				}
+			}
 		}
 
 		cidrs := util.CoalesceCIDRs(ips, coalesceNumberV4, coalesceMaskLenV4)
@@ -252,3 +270,11 @@ func MakeIPAllowDotConfig(
 	}
 	return text
 }
+
+// hostIP returns the IP of the given IP address or CIDR, or nil if it is neither.
+func hostIP(addr string) net.IP {
+	if ip, _, err := net.ParseCIDR(addr); err == nil {
+		return ip
+	}
+	return net.ParseIP(addr)
+}
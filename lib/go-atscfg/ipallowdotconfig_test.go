@@ -68,6 +68,10 @@ func TestMakeIPAllowDotConfig(t *testing.T) {
 		"child8": IPAllowServer{
 			IP6Address: "2001:DB8:2::5/64",
 		},
+		"child9": IPAllowServer{
+			IPAddress:   "192.168.2.200",
+			IPAddresses: []string{"192.168.2.200/24", "10.10.10.1/24", "2001:DB8:4::1/64"},
+		},
 	}
 
 	expecteds := []string{
@@ -82,6 +86,8 @@ func TestMakeIPAllowDotConfig(t *testing.T) {
 		"192.168.2.99",
 		"2001:db8:1::-2001:db8:1:0:ffff:ffff:ffff:ffff",
 		"2001:db8:2::-2001:db8:2:ffff:ffff:ffff:ffff:ffff",
+		"10.10.10.1",
+		"2001:db8:4::1",
 	}
 
 	txt := MakeIPAllowDotConfig(serverName, serverType, toToolName, toURL, params, childServers)
@@ -154,6 +160,10 @@ func TestMakeIPAllowDotConfigEdge(t *testing.T) {
 		"child8": IPAllowServer{
 			IP6Address: "2001:DB8:2::5/64",
 		},
+		"child9": IPAllowServer{
+			IPAddress:   "192.168.2.200",
+			IPAddresses: []string{"192.168.2.200/24", "10.10.10.1/24", "2001:DB8:4::1/64"},
+		},
 	}
 
 	expecteds := []string{
@@ -67,6 +67,13 @@ type ParentConfigDS struct {
 	QStringHandling string
 
 	RequiredCapabilities map[ServerCapability]struct{}
+
+	// Topology is the name of the Delivery Service's Topology, if any. Delivery Services with a Topology use TopologyPlacement and TopologyParents, rather than the server's parent Cache Groups.
+	Topology string
+	// TopologyPlacement is where the server's Cache Group is in the Delivery Service's Topology.
+	TopologyPlacement TopologyPlacement
+	// TopologyParents are the server's parents for the Delivery Service, from MakeTopologyParentInfo.
+	TopologyParents []ParentInfo
 }
 
 type ParentConfigDSTopLevel struct {
@@ -176,7 +183,7 @@ func MakeParentDotConfig(
 	nameVersionStr := GetNameVersionStringFromToolNameAndURL(toToolName, toURL)
 	hdr := HeaderCommentWithTOVersionStr(serverInfo.HostName, nameVersionStr)
 
-	textArr := []string{}
+	textArr, parentConfigDSes := makeTopologyParentLines(parentConfigDSes, serverParams, atsMajorVer)
 	text := ""
 	// TODO put these in separate functions. No if-statement should be this long.
 	if serverInfo.IsTopLevelCache() {
@@ -340,6 +347,72 @@ func MakeParentDotConfig(
 	return text
 }
 
+// makeTopologyParentLines returns the parent.config lines of the given Delivery Services which have Topologies, and the Delivery Services without Topologies.
+// Delivery Services without Topologies which have the same origin as one with a Topology are omitted, because only one line per origin is used.
+func makeTopologyParentLines(dses []ParentConfigDSTopLevel, serverParams map[string]string, atsMajorVer int) ([]string, []ParentConfigDSTopLevel) {
+	lines := []string{}
+	topologyOrigins := map[string]tc.DeliveryServiceName{}
+	for _, ds := range dses {
+		if ds.Topology == "" || !ds.TopologyPlacement.InTopology {
+			continue
+		}
+		if existingDS, ok := topologyOrigins[ds.OriginFQDN]; ok {
+			log.Errorln("parent.config generation: duplicate origin! services '" + string(ds.Name) + "' and '" + string(existingDS) + "' share origin '" + ds.OriginFQDN + "': skipping '" + string(ds.Name) + "'!")
+			continue
+		}
+		orgURI, err := url.Parse(ds.OriginFQDN)
+		if err != nil || ds.OriginFQDN == "" {
+			log.Errorln("Malformed ds '" + string(ds.Name) + "' origin  URI: '" + ds.OriginFQDN + "', skipping!")
+			continue
+		}
+		if orgURI.Port() == "" {
+			if orgURI.Scheme == "http" {
+				orgURI.Host += ":80"
+			} else if orgURI.Scheme == "https" {
+				orgURI.Host += ":443"
+			}
+		}
+		topologyOrigins[ds.OriginFQDN] = ds.Name
+
+		if dsType := tc.DSType(ds.Type); ds.TopologyPlacement.IsLastTier || dsType == tc.DSTypeHTTPNoCache || dsType == tc.DSTypeHTTPLive || dsType == tc.DSTypeDNSLive {
+			if ds.TopologyPlacement.IsLastTier && ds.MultiSiteOrigin {
+				log.Warnln("parent.config generation: delivery service '" + string(ds.Name) + "' uses multi-site origin, which is not supported with topology '" + ds.Topology + "': going direct to the origin")
+			}
+			lines = append(lines, `dest_domain=`+orgURI.Hostname()+` port=`+orgURI.Port()+` go_direct=true`+"\n")
+			continue
+		}
+
+		if len(ds.TopologyParents) == 0 {
+			log.Warnln("parent.config generation: delivery service '" + string(ds.Name) + "' topology '" + ds.Topology + "' parent cachegroups have no parent servers")
+		}
+		parentQStr := serverParams[ParentConfigParamQStringHandling]
+		if parentQStr == "" {
+			parentQStr = ds.QStringHandling
+		}
+		if parentQStr == "" {
+			parentQStr = "ignore"
+			if ds.QStringIgnore == tc.QStringIgnoreUseInCacheKeyAndPassUp {
+				parentQStr = "consider"
+			}
+		}
+		parents, secondaryParents := getParentStrs(ds, ds.TopologyParents, atsMajorVer)
+		lines = append(lines, `dest_domain=`+orgURI.Hostname()+` port=`+orgURI.Port()+` `+parents+secondaryParents+` round_robin=consistent_hash go_direct=false qstring=`+parentQStr+"\n")
+	}
+
+	otherDSes := []ParentConfigDSTopLevel{}
+	for _, ds := range dses {
+		if ds.Topology != "" {
+			continue
+		}
+		if topologyDS, ok := topologyOrigins[ds.OriginFQDN]; ok {
+			log.Errorln("parent.config generation: duplicate origin! services '" + string(ds.Name) + "' and '" + string(topologyDS) + "' share origin '" + ds.OriginFQDN + "': skipping '" + string(ds.Name) + "'!")
+			continue
+		}
+		otherDSes = append(otherDSes, ds)
+	}
+	return lines, otherDSes
+}
+
 // getParentStrs returns the parents= and secondary_parents= strings for ATS parent.config lines.
 func getParentStrs(ds ParentConfigDSTopLevel, parentInfos []ParentInfo, atsMajorVer int) (string, string) {
 	parentInfo := []string{}
@@ -324,3 +324,97 @@ func TestMakeParentDotConfigMSOSecondaryParent(t *testing.T) {
 		t.Errorf("expected secondary parent 'my-parent-1.my-parent-1-domain', actual: '%v'", txt)
 	}
 }
+
+func TestMakeParentDotConfigTopology(t *testing.T) {
+	atsMajorVer := 7
+	serverName := "myserver"
+	toolName := "myToolName"
+	toURL := "https://myto.example.net"
+
+	parentConfigDSes := []ParentConfigDSTopLevel{
+		ParentConfigDSTopLevel{
+			ParentConfigDS: ParentConfigDS{
+				Name:              "ds-topology",
+				QStringIgnore:     tc.QStringIgnoreDrop,
+				OriginFQDN:        "http://ds-topology.example.net",
+				Type:              tc.DSTypeHTTP,
+				Topology:          "mytopology",
+				TopologyPlacement: TopologyPlacement{InTopology: true, IsFirstTier: true, PrimaryParent: "mid0", SecondaryParent: "mid1"},
+				TopologyParents: []ParentInfo{
+					ParentInfo{Host: "mid-0", Port: 80, Domain: "example.net", Weight: "1", Rank: 1, PrimaryParent: true},
+					ParentInfo{Host: "mid-1", Port: 80, Domain: "example.net", Weight: "1", Rank: 1, SecondaryParent: true},
+				},
+			},
+		},
+		ParentConfigDSTopLevel{
+			ParentConfigDS: ParentConfigDS{
+				Name:              "ds-topology-last-tier",
+				OriginFQDN:        "http://ds-topology-last-tier.example.net",
+				Type:              tc.DSTypeHTTP,
+				Topology:          "flattopology",
+				TopologyPlacement: TopologyPlacement{InTopology: true, IsFirstTier: true, IsLastTier: true},
+			},
+		},
+		ParentConfigDSTopLevel{
+			ParentConfigDS: ParentConfigDS{
+				Name:              "ds-topology-elsewhere",
+				OriginFQDN:        "http://ds-topology-elsewhere.example.net",
+				Type:              tc.DSTypeHTTP,
+				Topology:          "othertopology",
+				TopologyPlacement: TopologyPlacement{},
+			},
+		},
+		ParentConfigDSTopLevel{
+			ParentConfigDS: ParentConfigDS{
+				Name:       "ds-same-origin",
+				OriginFQDN: "http://ds-topology.example.net",
+				Type:       tc.DSTypeHTTP,
+			},
+		},
+	}
+
+	serverInfo := &ServerInfo{
+		CacheGroupID:       42,
+		CDN:                "myCDN",
+		CDNID:              43,
+		DomainName:         "serverdomain.example.net",
+		HostName:           "myserver",
+		ID:                 44,
+		IP:                 "192.168.2.1",
+		ParentCacheGroupID: 45,
+		ProfileID:          46,
+		ProfileName:        "MyProfileName",
+		Port:               80,
+		Type:               "EDGE",
+	}
+
+	txt := MakeParentDotConfig(serverInfo, atsMajorVer, toolName, toURL, parentConfigDSes, map[string]string{}, map[OriginHost][]ParentInfo{})
+
+	testComment(t, txt, serverName, toolName, toURL)
+
+	lines := strings.Split(txt, "\n")
+	topologyLine := ""
+	for _, line := range lines {
+		if strings.HasPrefix(line, "dest_domain=ds-topology.example.net ") {
+			if topologyLine != "" {
+				t.Errorf("expected one line for origin ds-topology.example.net, actual: '%v'", txt)
+			}
+			topologyLine = line
+		}
+	}
+	if !strings.Contains(topologyLine, `parent="mid-0.example.net:80|1;"`) {
+		t.Errorf("expected topology primary parent 'mid-0', actual: '%v'", topologyLine)
+	}
+	if !strings.Contains(topologyLine, `secondary_parent="mid-1.example.net:80|1;"`) {
+		t.Errorf("expected topology secondary parent 'mid-1', actual: '%v'", topologyLine)
+	}
+	if !strings.Contains(topologyLine, "go_direct=false") {
+		t.Errorf("expected topology first tier to not go direct, actual: '%v'", topologyLine)
+	}
+	if !strings.Contains(txt, "dest_domain=ds-topology-last-tier.example.net port=80 go_direct=true") {
+		t.Errorf("expected topology last tier to go direct, actual: '%v'", txt)
+	}
+	if strings.Contains(txt, "ds-topology-elsewhere") {
+		t.Errorf("expected no line for topology not containing the server's cachegroup, actual: '%v'", txt)
+	}
+}
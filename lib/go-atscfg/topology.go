@@ -0,0 +1,101 @@
+package atscfg
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+// TopologyPlacement is where a Cache Group is in a Topology.
+type TopologyPlacement struct {
+	// InTopology is whether the Cache Group is in the Topology at all. If it isn't, the other fields are empty.
+	InTopology bool
+	// IsFirstTier is whether the Cache Group is in the first tier of the Topology, i.e. clients are routed to it.
+	IsFirstTier bool
+	// IsLastTier is whether the Cache Group has no parents in the Topology, i.e. it requests content from the origin.
+	IsLastTier bool
+	// PrimaryParent is the name of the primary parent Cache Group, if any.
+	PrimaryParent string
+	// SecondaryParent is the name of the secondary parent Cache Group, if any.
+	SecondaryParent string
+}
+
+// GetTopologyPlacement returns where the given Cache Group is in the given Topology.
+func GetTopologyPlacement(topology tc.Topology, cachegroup string) TopologyPlacement {
+	index, ok := topology.NodeIndex(cachegroup)
+	if !ok {
+		return TopologyPlacement{}
+	}
+	node := topology.Nodes[index]
+	placement := TopologyPlacement{
+		InTopology:  true,
+		IsFirstTier: !topology.IsParent(index),
+		IsLastTier:  len(node.Parents) == 0,
+	}
+	if len(node.Parents) > 0 {
+		placement.PrimaryParent = topology.Nodes[node.Parents[0]].Cachegroup
+	}
+	if len(node.Parents) > 1 {
+		placement.SecondaryParent = topology.Nodes[node.Parents[1]].Cachegroup
+	}
+	return placement
+}
+
+// MakeTopologyParentInfo returns the parents of a server for a Delivery Service with a Topology, from the servers of its primary and secondary parent Cache Groups.
+// Servers in other Cache Groups, and those whose profiles say they are not parents, are ignored. A missing parent Cache Group should be given as an ID no Cache Group has, such as -1.
+func MakeTopologyParentInfo(
+	primaryParentCacheGroupID int,
+	secondaryParentCacheGroupID int,
+	profileCaches map[ProfileID]ProfileCache,
+	parentServers []CGServer,
+) []ParentInfo {
+	parentInfos := []ParentInfo{}
+	for _, row := range parentServers {
+		isPrimary := row.CacheGroupID == primaryParentCacheGroupID
+		isSecondary := row.CacheGroupID == secondaryParentCacheGroupID
+		if !isPrimary && !isSecondary {
+			continue
+		}
+		profile, ok := profileCaches[row.ProfileID]
+		if !ok {
+			profile = DefaultProfileCache()
+		}
+		if profile.NotAParent {
+			continue
+		}
+		parentInf := ParentInfo{
+			Host:            row.ServerHost,
+			Port:            profile.Port,
+			Domain:          row.Domain,
+			Weight:          profile.Weight,
+			UseIP:           profile.UseIP,
+			Rank:            profile.Rank,
+			IP:              row.ServerIP,
+			PrimaryParent:   isPrimary,
+			SecondaryParent: isSecondary,
+			Capabilities:    row.Capabilities,
+		}
+		if parentInf.Port < 1 {
+			parentInf.Port = row.ServerPort
+		}
+		parentInfos = append(parentInfos, parentInf)
+	}
+	return parentInfos
+}
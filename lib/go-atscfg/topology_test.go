@@ -0,0 +1,75 @@
+package atscfg
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+func TestGetTopologyPlacement(t *testing.T) {
+	topology := tc.Topology{
+		Name: "mytopology",
+		Nodes: []tc.TopologyNode{
+			{Cachegroup: "edge", Parents: []int{1, 2}},
+			{Cachegroup: "mid0", Parents: []int{3}},
+			{Cachegroup: "mid1", Parents: []int{3}},
+			{Cachegroup: "mid2"},
+		},
+	}
+
+	expected := map[string]TopologyPlacement{
+		"edge":  {InTopology: true, IsFirstTier: true, PrimaryParent: "mid0", SecondaryParent: "mid1"},
+		"mid0":  {InTopology: true, PrimaryParent: "mid2"},
+		"mid1":  {InTopology: true, PrimaryParent: "mid2"},
+		"mid2":  {InTopology: true, IsLastTier: true},
+		"other": {},
+	}
+	for cachegroup, expectedPlacement := range expected {
+		if actual := GetTopologyPlacement(topology, cachegroup); actual != expectedPlacement {
+			t.Errorf("GetTopologyPlacement(%s) expected: %+v, actual: %+v", cachegroup, expectedPlacement, actual)
+		}
+	}
+}
+
+func TestMakeTopologyParentInfo(t *testing.T) {
+	profileCaches := map[ProfileID]ProfileCache{
+		1: DefaultProfileCache(),
+		2: ProfileCache{NotAParent: true},
+	}
+	servers := []CGServer{
+		{ServerHost: "primary", ServerPort: 80, CacheGroupID: 10, ProfileID: 1},
+		{ServerHost: "secondary", ServerPort: 81, CacheGroupID: 11, ProfileID: 1},
+		{ServerHost: "notaparent", ServerPort: 80, CacheGroupID: 10, ProfileID: 2},
+		{ServerHost: "elsewhere", ServerPort: 80, CacheGroupID: 12, ProfileID: 1},
+	}
+
+	parents := MakeTopologyParentInfo(10, 11, profileCaches, servers)
+	if len(parents) != 2 {
+		t.Fatalf("expected 2 parents, actual: %+v", parents)
+	}
+	if parents[0].Host != "primary" || !parents[0].PrimaryParent || parents[0].SecondaryParent || parents[0].Port != 80 {
+		t.Errorf("expected primary parent 'primary' on port 80, actual: %+v", parents[0])
+	}
+	if parents[1].Host != "secondary" || parents[1].PrimaryParent || !parents[1].SecondaryParent || parents[1].Port != 81 {
+		t.Errorf("expected secondary parent 'secondary' on port 81, actual: %+v", parents[1])
+	}
+}
@@ -20,6 +20,8 @@ package rfc
  */
 
 import (
+	"crypto/sha1"
+	"encoding/hex"
 	"net/http"
 	"strings"
 
@@ -35,6 +37,12 @@ const (
 	AcceptEncoding         = "Accept-Encoding"          // RFC7231§5.3.4
 	ContentDisposition     = "Content-Disposition"      // RFC6266
 	ApplicationOctetStream = "application/octet-stream" // RFC2046§4.5.2
+	ETagHeader             = "ETag"                     // RFC7232§2.3
+	LastModified           = "Last-Modified"            // RFC7232§2.2
+	IfNoneMatch            = "If-None-Match"            // RFC7232§3.2
+	IfModifiedSince        = "If-Modified-Since"        // RFC7232§3.3
+	IfMatch                = "If-Match"                 // RFC7232§3.1
+	IfUnmodifiedSince      = "If-Unmodified-Since"      // RFC7232§3.4
 )
 
 // AcceptsGzip returns whether r accepts gzip encoding, per RFC7231§5.3.4.
@@ -51,3 +59,45 @@ func AcceptsGzip(r *http.Request) bool {
 	}
 	return false
 }
+
+// ETag returns a strong entity tag for the given representation, suitable for the ETag header per RFC7232§2.3.
+func ETag(bts []byte) string {
+	sum := sha1.Sum(bts)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+// ETagMatches returns whether the given If-None-Match header value matches the entity tag etag, using the weak comparison function of RFC7232§2.3.2 as required by RFC7232§3.2.
+func ETagMatches(ifNoneMatch string, etag string) bool {
+	ifNoneMatch = strings.TrimSpace(ifNoneMatch)
+	if ifNoneMatch == "" || etag == "" {
+		return false
+	}
+	if ifNoneMatch == "*" {
+		return true
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(tag), "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// ETagMatchesStrong returns whether the given If-Match header value matches the entity tag etag, using the strong comparison function of RFC7232§2.3.2 as required by RFC7232§3.1.
+// Weak entity tags never match. The caller must check the representation exists before treating a "*" match as success.
+func ETagMatchesStrong(ifMatch string, etag string) bool {
+	ifMatch = strings.TrimSpace(ifMatch)
+	if ifMatch == "" || etag == "" || strings.HasPrefix(etag, "W/") {
+		return false
+	}
+	if ifMatch == "*" {
+		return true
+	}
+	for _, tag := range strings.Split(ifMatch, ",") {
+		if strings.TrimSpace(tag) == etag {
+			return true
+		}
+	}
+	return false
+}
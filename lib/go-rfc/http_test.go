@@ -0,0 +1,85 @@
+package rfc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"testing"
+)
+
+func TestETag(t *testing.T) {
+	a := ETag([]byte(`{"response":[]}`))
+	if a != ETag([]byte(`{"response":[]}`)) {
+		t.Errorf("expected identical bodies to have identical ETags")
+	}
+	if a == ETag([]byte(`{"response":[{}]}`)) {
+		t.Errorf("expected different bodies to have different ETags")
+	}
+	if len(a) < 3 || a[0] != '"' || a[len(a)-1] != '"' {
+		t.Errorf("expected quoted ETag, actual %s", a)
+	}
+}
+
+func TestETagMatches(t *testing.T) {
+	etag := `"abc"`
+	tests := []struct {
+		header   string
+		expected bool
+	}{
+		{``, false},
+		{`*`, true},
+		{`"abc"`, true},
+		{`W/"abc"`, true},
+		{`"xyz", "abc"`, true},
+		{`"xyz",W/"abc"`, true},
+		{`"xyz"`, false},
+		{`abc`, false},
+	}
+	for _, test := range tests {
+		if actual := ETagMatches(test.header, etag); actual != test.expected {
+			t.Errorf("ETagMatches(%q, %q) expected %v actual %v", test.header, etag, test.expected, actual)
+		}
+	}
+	if ETagMatches(`"abc"`, "") {
+		t.Errorf("expected an empty ETag to never match")
+	}
+}
+
+func TestETagMatchesStrong(t *testing.T) {
+	etag := `"abc"`
+	tests := []struct {
+		header   string
+		expected bool
+	}{
+		{``, false},
+		{`*`, true},
+		{`"abc"`, true},
+		{`W/"abc"`, false},
+		{`"xyz", "abc"`, true},
+		{`"xyz"`, false},
+	}
+	for _, test := range tests {
+		if actual := ETagMatchesStrong(test.header, etag); actual != test.expected {
+			t.Errorf("ETagMatchesStrong(%q, %q) expected %v actual %v", test.header, etag, test.expected, actual)
+		}
+	}
+	if ETagMatchesStrong(`*`, `W/"abc"`) {
+		t.Errorf("expected a weak ETag to never match")
+	}
+}
@@ -0,0 +1,220 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/apache/trafficcontrol/lib/go-util"
+)
+
+// CDNExportVersion is the version of the CDN export document format produced by Traffic Ops. It is
+// incremented whenever the format changes in a way that older versions of Traffic Ops could not
+// import.
+const CDNExportVersion = 1
+
+// CDNExport is a document describing the configuration of a CDN, as exported from the
+// /cdns/{{ID}}/export endpoint and imported by the /cdns/import endpoint.
+//
+// Objects in the document refer to each other - and to objects which are not part of the
+// document, such as Tenants, Types, and servers - by name rather than by ID, so that the document
+// can be imported into a different Traffic Ops.
+type CDNExport struct {
+	Version          int                        `json:"version"`
+	CDN              CDNExportCDN               `json:"cdn"`
+	CacheGroups      []CDNExportCacheGroup      `json:"cacheGroups"`
+	Profiles         []CDNExportProfile         `json:"profiles"`
+	DeliveryServices []CDNExportDeliveryService `json:"deliveryServices"`
+	SteeringTargets  []CDNExportSteeringTarget  `json:"steeringTargets"`
+	Federations      []CDNExportFederation      `json:"federations"`
+}
+
+// CDNExportCDN is the CDN itself in a CDNExport.
+type CDNExportCDN struct {
+	Name          string `json:"name"`
+	DomainName    string `json:"domainName"`
+	DNSSECEnabled bool   `json:"dnssecEnabled"`
+}
+
+// CDNExportCacheGroup is a Cache Group in a CDNExport. The Cache Groups of a CDN are those which
+// contain its servers, along with their parents and fallbacks.
+type CDNExportCacheGroup struct {
+	Name                string               `json:"name"`
+	ShortName           string               `json:"shortName"`
+	Type                string               `json:"type"`
+	Latitude            *float64             `json:"latitude"`
+	Longitude           *float64             `json:"longitude"`
+	ParentName          *string              `json:"parentCachegroupName"`
+	SecondaryParentName *string              `json:"secondaryParentCachegroupName"`
+	FallbackToClosest   bool                 `json:"fallbackToClosest"`
+	LocalizationMethods []LocalizationMethod `json:"localizationMethods"`
+	Fallbacks           []string             `json:"fallbacks"`
+}
+
+// CDNExportProfile is a Profile of the CDN, with its Parameters, in a CDNExport. Secure Parameters
+// are never exported.
+type CDNExportProfile struct {
+	Name            string                                 `json:"name"`
+	Description     string                                 `json:"description"`
+	Type            string                                 `json:"type"`
+	RoutingDisabled bool                                   `json:"routingDisabled"`
+	Parameters      []ProfileExportImportParameterNullable `json:"parameters"`
+}
+
+// CDNExportDeliveryService is a Delivery Service of the CDN in a CDNExport.
+//
+// Its CDN, Profile, Tenant, and Type are given by name; the corresponding IDs, and other fields
+// which are specific to a Traffic Ops instance or derived from other fields, are always null when
+// exported and ignored when imported. Its MatchList is the complete set of its regular expressions,
+// and Servers are the host names of the servers assigned to it.
+type CDNExportDeliveryService struct {
+	DeliveryServiceNullable
+	Servers []string `json:"servers"`
+}
+
+// CDNExportSteeringTarget is a target of a steering Delivery Service of the CDN in a CDNExport.
+type CDNExportSteeringTarget struct {
+	DeliveryService string `json:"deliveryService"`
+	Target          string `json:"target"`
+	Type            string `json:"type"`
+	Value           int    `json:"value"`
+}
+
+// CDNExportFederation is a Federation of a Delivery Service of the CDN in a CDNExport. The users
+// assigned to Federations are not exported.
+type CDNExportFederation struct {
+	CName           string                        `json:"cname"`
+	TTL             int                           `json:"ttl"`
+	Description     *string                       `json:"description"`
+	DeliveryService string                        `json:"deliveryService"`
+	Resolvers       []CDNExportFederationResolver `json:"resolvers"`
+}
+
+// CDNExportFederationResolver is a resolver of a Federation in a CDNExport.
+type CDNExportFederationResolver struct {
+	IPAddress string `json:"ipAddress"`
+	Type      string `json:"type"`
+}
+
+// Validate checks that the CDNExport is of a version which can be imported, and that all of the
+// names it uses to identify objects are present and unique. It does not check that objects it
+// refers to exist.
+func (e CDNExport) Validate() error {
+	errs := []error{}
+	if e.Version < 1 || e.Version > CDNExportVersion {
+		errs = append(errs, fmt.Errorf("version: unsupported version %d, must be between 1 and %d", e.Version, CDNExportVersion))
+	}
+	if e.CDN.Name == "" {
+		errs = append(errs, errors.New("cdn: name cannot be blank"))
+	}
+	if e.CDN.DomainName == "" {
+		errs = append(errs, errors.New("cdn: domainName cannot be blank"))
+	}
+
+	names := map[string]struct{}{}
+	checkName := func(objType string, i int, name string) {
+		if name == "" {
+			errs = append(errs, fmt.Errorf("%s #%d: name cannot be blank", objType, i))
+			return
+		}
+		key := objType + "/" + name
+		if _, ok := names[key]; ok {
+			errs = append(errs, fmt.Errorf("%s '%s': appears more than once", objType, name))
+		}
+		names[key] = struct{}{}
+	}
+	for i, cg := range e.CacheGroups {
+		checkName("cacheGroup", i, cg.Name)
+	}
+	for i, profile := range e.Profiles {
+		checkName("profile", i, profile.Name)
+	}
+	for i, ds := range e.DeliveryServices {
+		xmlID := ""
+		if ds.XMLID != nil {
+			xmlID = *ds.XMLID
+		}
+		checkName("deliveryService", i, xmlID)
+	}
+	for i, st := range e.SteeringTargets {
+		if st.DeliveryService == "" || st.Target == "" {
+			errs = append(errs, fmt.Errorf("steeringTarget #%d: deliveryService and target cannot be blank", i))
+			continue
+		}
+		checkName("steeringTarget", i, st.Name())
+	}
+	for i, fed := range e.Federations {
+		if fed.CName == "" || fed.DeliveryService == "" {
+			errs = append(errs, fmt.Errorf("federation #%d: cname and deliveryService cannot be blank", i))
+			continue
+		}
+		checkName("federation", i, fed.Name())
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return util.JoinErrs(errs)
+}
+
+// Name returns the name by which the CDNExportSteeringTarget is identified in a CDNImportReport.
+func (st CDNExportSteeringTarget) Name() string {
+	return st.DeliveryService + " -> " + st.Target
+}
+
+// Name returns the name by which the CDNExportFederation is identified in a CDNImportReport.
+func (fed CDNExportFederation) Name() string {
+	return fed.CName + " (" + fed.DeliveryService + ")"
+}
+
+// These are the actions which may be reported for an object in a CDNImportReport.
+const (
+	CDNImportActionCreate = "create"
+	CDNImportActionUpdate = "update"
+	CDNImportActionSkip   = "skip"
+	CDNImportActionError  = "error"
+)
+
+// CDNImportResult is the result of importing one object of a CDNExport.
+type CDNImportResult struct {
+	// Type is the type of the object - "cdn", "cacheGroup", "profile", "deliveryService",
+	// "steeringTarget", or "federation".
+	Type string `json:"type"`
+	Name string `json:"name"`
+
+	// Action is one of the CDNImportAction constants. Objects which already exist and are
+	// identical to those in the document are skipped.
+	Action  string `json:"action"`
+	Message string `json:"message,omitempty"`
+}
+
+// CDNImportReport is the result of importing a CDNExport, with the result for each of its objects,
+// in the order in which they were imported.
+type CDNImportReport struct {
+	DryRun  bool              `json:"dryRun"`
+	Results []CDNImportResult `json:"results"`
+}
+
+// CDNImportResponse is the type of a response from Traffic Ops to a POST request to the
+// /cdns/import endpoint.
+type CDNImportResponse struct {
+	Response CDNImportReport `json:"response"`
+	Alerts
+}
@@ -71,3 +71,8 @@ func (a AlertLevel) String() string {
 // CachegroupCoordinateNamePrefix is a string that all cache group coordinate
 // names are prefixed with.
 const CachegroupCoordinateNamePrefix = "from_cachegroup_"
+
+// CorrelationIDHeader is the HTTP header in which Traffic Ops returns the correlation ID of each
+// request, which identifies it in Traffic Ops's logs and change log and in the requests it makes
+// to other services. Clients may send their own correlation ID in the same header.
+const CorrelationIDHeader = "X-Correlation-Id"
@@ -91,6 +91,7 @@ type CRConfigTrafficOpsServer struct {
 	HashId           *string               `json:"hashId,omitempty"`
 	HttpsPort        *int                  `json:"httpsPort"`
 	InterfaceName    *string               `json:"interfaceName,omitempty"`
+	Interfaces       []ServerInterfaceInfo `json:"interfaces,omitempty"`
 	Ip               *string               `json:"ip,omitempty"`
 	Ip6              *string               `json:"ip6,omitempty"`
 	LocationId       *string               `json:"locationId,omitempty"`
@@ -27,12 +27,16 @@ import (
 type CRStates struct {
 	Caches          map[CacheName]IsAvailable                       `json:"caches"`
 	DeliveryService map[DeliveryServiceName]CRStatesDeliveryService `json:"deliveryServices"`
+	// Routers is the availability of Traffic Routers, as probed by this Traffic Monitor. It's omitted if Traffic Routers aren't probed.
+	Routers map[TrafficRouterName]IsAvailable `json:"routers,omitempty"`
 }
 
 // CRStatesDeliveryService contains data about the availability of a particular delivery service, and which caches in that delivery service have been marked as unavailable.
 type CRStatesDeliveryService struct {
 	DisabledLocations []CacheGroupName `json:"disabledLocations"`
 	IsAvailable       bool             `json:"isAvailable"`
+	// OverloadedLocations are the cachegroups disabled for this delivery service alone, because its traffic in them exceeded a delivery service threshold, keyed by cachegroup with the reason as the value. Overloaded locations are also in DisabledLocations.
+	OverloadedLocations map[CacheGroupName]string `json:"overloadedLocations,omitempty"`
 }
 
 // IsAvailable contains whether the given cache or delivery service is available. It is designed for JSON serialization, namely in the Traffic Monitor 1.0 API.
@@ -59,6 +63,9 @@ func (a CRStates) Copy() CRStates {
 	for k, v := range a.DeliveryService {
 		b.DeliveryService[k] = v
 	}
+	if a.Routers != nil {
+		b.Routers = a.CopyRouters()
+	}
 	return b
 }
 
@@ -80,6 +87,15 @@ func (a CRStates) CopyCaches() map[CacheName]IsAvailable {
 	return b
 }
 
+// CopyRouters creates a deep copy of the Traffic Router availability data. It does not mutate, and is thus safe for multiple goroutines.
+func (a CRStates) CopyRouters() map[TrafficRouterName]IsAvailable {
+	b := map[TrafficRouterName]IsAvailable{}
+	for k, v := range a.Routers {
+		b[k] = v
+	}
+	return b
+}
+
 // CRStatesMarshall serializes the given CRStates into bytes.
 func CRStatesMarshall(states CRStates) ([]byte, error) {
 	return json.Marshal(states)
@@ -167,6 +167,9 @@ type DeliveryServiceNullable struct {
 	DeliveryServiceNullableV14
 	EcsEnabled          bool `json:"ecsEnabled" db:"ecs_enabled"`
 	RangeSliceBlockSize *int `json:"rangeSliceBlockSize" db:"range_slice_block_size"`
+	// Topology is the name of the Topology whose Cache Groups serve the Delivery Service, in place
+	// of explicitly assigned servers and the Cache Groups' own parents. It may be nil.
+	Topology *string `json:"topology" db:"topology"`
 }
 
 type DeliveryServiceNullableV14 struct {
@@ -346,6 +349,9 @@ func (ds *DeliveryServiceNullable) Sanitize() {
 	if ds.MaxOriginConnections == nil || *ds.MaxOriginConnections < 0 {
 		ds.MaxOriginConnections = util.IntPtr(0)
 	}
+	if ds.Topology != nil && strings.TrimSpace(*ds.Topology) == "" {
+		ds.Topology = nil
+	}
 	if ds.DeepCachingType == nil {
 		s := DeepCachingType("")
 		ds.DeepCachingType = &s
@@ -417,6 +423,14 @@ func (ds *DeliveryServiceNullable) validateTypeFields(tx *sql.Tx) error {
 			validation.By(requiredIfMatchesTypeName([]string{DNSRegexType, HTTPRegexType}, typeName))),
 		"rangeRequestHandling": validation.Validate(ds.RangeRequestHandling,
 			validation.By(requiredIfMatchesTypeName([]string{DNSRegexType, HTTPRegexType}, typeName))),
+		"topology": validation.Validate(ds,
+			validation.By(func(dsi interface{}) error {
+				ds := dsi.(*DeliveryServiceNullable)
+				if ds.Topology == nil || DSType(typeName).IsHTTP() || DSType(typeName).IsDNS() {
+					return nil
+				}
+				return fmt.Errorf("topology not allowed for '%s' deliveryservice type", typeName)
+			})),
 	}
 	toErrs := tovalidate.ToErrors(errs)
 	if len(toErrs) > 0 {
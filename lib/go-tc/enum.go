@@ -43,6 +43,9 @@ type CDNName string
 // TrafficMonitorName is the hostname of a Traffic Monitor peer.
 type TrafficMonitorName string
 
+// TrafficRouterName is the hostname of a Traffic Router.
+type TrafficRouterName string
+
 // CacheName is the hostname of a CDN cache.
 type CacheName string
 
@@ -77,6 +80,8 @@ const MidTypePrefix = "MID"
 const OriginTypeName = "ORG"
 
 const CacheGroupOriginTypeName = "ORG_LOC"
+const CacheGroupEdgeTypeName = "EDGE_LOC"
+const CacheGroupMidTypeName = "MID_LOC"
 
 const GlobalProfileName = "GLOBAL"
 
@@ -88,6 +93,10 @@ func (t TrafficMonitorName) String() string {
 	return string(t)
 }
 
+func (t TrafficRouterName) String() string {
+	return string(t)
+}
+
 func (d DeliveryServiceName) String() string {
 	return string(d)
 }
@@ -0,0 +1,144 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// These are the types of Events emitted by Traffic Ops which do not simply correspond to an object
+// being created, updated, or deleted.
+//
+// Objects created, updated, or deleted through the generic API handlers emit Events of the type
+// "{object type}.created", "{object type}.updated", or "{object type}.deleted", respectively, as
+// built by EventTypeFor.
+const (
+	EventTypeDeliveryServiceCreated = "ds.created"
+	EventTypeDeliveryServiceUpdated = "ds.updated"
+	EventTypeDeliveryServiceDeleted = "ds.deleted"
+	EventTypeServerStatusChanged    = "server.status_changed"
+	EventTypeSnapshotTaken          = "cdn.snapshot"
+	EventTypeUpdatesQueued          = "updates.queued"
+	EventTypeUpdatesCleared         = "updates.cleared"
+	EventTypeInvalidationJobCreated = "job.created"
+	EventTypeServercheckTriggered   = "servercheck.rule_triggered"
+)
+
+// These are the actions used to build Event types from object types with EventTypeFor.
+const (
+	EventActionCreated = "created"
+	EventActionUpdated = "updated"
+	EventActionDeleted = "deleted"
+)
+
+// EventTypeWildcard, as a pattern in a Webhook's EventTypes, matches every Event type.
+const EventTypeWildcard = "*"
+
+// EventTypeFor returns the Event type for the given action having been taken on an object of the
+// given type.
+func EventTypeFor(objType string, action string) string {
+	return strings.Replace(objType, " ", "_", -1) + "." + strings.ToLower(action)
+}
+
+// EventTypeMatches returns whether or not the Event type t is matched by pattern, which is either
+// an exact Event type, EventTypeWildcard, or an object type followed by ".*" - e.g. "ds.*" - which
+// matches any Event type with that prefix.
+func EventTypeMatches(pattern string, t string) bool {
+	if pattern == EventTypeWildcard || pattern == t {
+		return true
+	}
+	if strings.HasSuffix(pattern, ".*") {
+		return strings.HasPrefix(t, strings.TrimSuffix(pattern, "*"))
+	}
+	return false
+}
+
+// Event is a single change made in Traffic Ops, as delivered to Webhooks and the event stream.
+type Event struct {
+	ID   uint64 `json:"id"`
+	Type string `json:"type"`
+	Time Time   `json:"time"`
+
+	// User is the username of the user who made the change.
+	User string `json:"user"`
+
+	// Data is the changed object, as it would be returned by the API. For deletions, it is the
+	// key(s) identifying the deleted object.
+	Data json.RawMessage `json:"data"`
+}
+
+// Webhook is a subscription to Traffic Ops Events, which are delivered by POSTing them to URL.
+//
+// Each delivery is signed with Secret: the X-Trafficops-Signature header contains "sha256=" followed
+// by the hex-encoded HMAC-SHA256 of the request body, keyed by Secret.
+type Webhook struct {
+	ID  *int    `json:"id" db:"id"`
+	URL *string `json:"url" db:"url"`
+
+	// Secret is required when creating a Webhook, but when updating one it may be omitted to keep
+	// the existing Secret. It is never returned by Traffic Ops.
+	Secret *string `json:"secret,omitempty" db:"secret"`
+
+	// EventTypes are the patterns of the Event types which are delivered, as understood by
+	// EventTypeMatches.
+	EventTypes  []string   `json:"eventTypes" db:"event_types"`
+	Active      *bool      `json:"active" db:"active"`
+	LastUpdated *TimeNoMod `json:"lastUpdated" db:"last_updated"`
+}
+
+// WebhooksResponse is the type of a response from Traffic Ops to a GET request to the /webhooks
+// endpoint.
+type WebhooksResponse struct {
+	Response []Webhook `json:"response"`
+}
+
+// Validate checks that the Webhook is well-formed, returning an error describing any and all
+// problematic fields.
+func (w *Webhook) Validate() error {
+	errs := validation.Errors{
+		"url":        validation.Validate(w.URL, validation.Required),
+		"eventTypes": validation.Validate(w.EventTypes, validation.Required),
+	}
+	if w.URL != nil && *w.URL != "" {
+		if u, err := url.Parse(*w.URL); err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			errs["url"] = errors.New("must be an absolute http(s) URL")
+		}
+	}
+	for _, t := range w.EventTypes {
+		if t == "" || strings.ContainsAny(t, " \t\n") || (strings.Contains(t, "*") && t != EventTypeWildcard && !strings.HasSuffix(t, ".*")) {
+			errs["eventTypes"] = errors.New("'" + t + "' is not a valid event type pattern")
+			break
+		}
+	}
+	for k, v := range errs {
+		if v == nil {
+			delete(errs, k)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
@@ -0,0 +1,50 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"testing"
+)
+
+func TestEventTypeMatches(t *testing.T) {
+	type testCase struct {
+		pattern  string
+		t        string
+		expected bool
+	}
+	testCases := []testCase{
+		{EventTypeWildcard, EventTypeSnapshotTaken, true},
+		{EventTypeSnapshotTaken, EventTypeSnapshotTaken, true},
+		{"ds.*", EventTypeDeliveryServiceCreated, true},
+		{"ds.*", EventTypeFor("ds", EventActionDeleted), true},
+		{"ds.*", "dsr.created", false},
+		{"ds.created", EventTypeDeliveryServiceUpdated, false},
+		{"server.*", EventTypeServerStatusChanged, true},
+	}
+	for _, c := range testCases {
+		if actual := EventTypeMatches(c.pattern, c.t); actual != c.expected {
+			t.Errorf("EventTypeMatches('%s', '%s') expected: %v, actual: %v", c.pattern, c.t, c.expected, actual)
+		}
+	}
+
+	if actual := EventTypeFor("server capability", EventActionCreated); actual != "server_capability.created" {
+		t.Errorf("EventTypeFor expected: server_capability.created, actual: %s", actual)
+	}
+}
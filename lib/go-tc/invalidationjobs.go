@@ -24,11 +24,13 @@ import "fmt"
 import "regexp"
 import "database/sql"
 import "math"
+import "net/url"
 import "strconv"
 import "strings"
 import "time"
 
 import "github.com/apache/trafficcontrol/lib/go-log"
+import "github.com/apache/trafficcontrol/lib/go-util"
 
 import "github.com/go-ozzo/ozzo-validation"
 import "github.com/go-ozzo/ozzo-validation/is"
@@ -365,3 +367,195 @@ func (job *UserInvalidationJobInput) Validate(tx *sql.Tx) error {
 	}
 	return nil
 }
+
+// These are the possible values of the State of an InvalidationJobStatus.
+const (
+	// InvalidationJobStatePending means the job has not yet come into effect.
+	InvalidationJobStatePending = "PENDING"
+	// InvalidationJobStateInProgress means the job is in effect, but some cache servers have yet to
+	// apply the revalidation.
+	InvalidationJobStateInProgress = "IN_PROGRESS"
+	// InvalidationJobStateComplete means the job is in effect, and every cache server that should
+	// apply the revalidation has done so.
+	InvalidationJobStateComplete = "COMPLETE"
+	// InvalidationJobStateExpired means the job's TTL has passed.
+	InvalidationJobStateExpired = "EXPIRED"
+)
+
+// MaxBulkInvalidationJobs is the largest number of content invalidation jobs that may be created
+// by a single InvalidationJobBulkInput.
+const MaxBulkInvalidationJobs = 1000
+
+// InvalidationJobBulkInput represents user input intending to create many content invalidation
+// jobs at once.
+//
+// Every one of Regexes is applied to every one of DeliveryServices. Each of URLs is a full URL of
+// some content on the origin of a Delivery Service; Traffic Ops determines the Delivery Service
+// from the Primary Origin that matches the URL's scheme and authority, and invalidates exactly
+// that path (and query string, if any). If DeliveryServices is not empty, URLs may only match the
+// origins of those Delivery Services.
+type InvalidationJobBulkInput struct {
+
+	// DeliveryServices is a list of Delivery Service identifiers, each of which follows the same
+	// rules as InvalidationJobInput.DeliveryService.
+	DeliveryServices []interface{} `json:"deliveryServices"`
+	Regexes          []string      `json:"regexes"`
+	URLs             []string      `json:"urls"`
+
+	// StartTime and TTL have the same meaning and constraints as those of InvalidationJobInput,
+	// and are shared by every created job.
+	StartTime *Time        `json:"startTime"`
+	TTL       *interface{} `json:"ttl"`
+}
+
+// Validate checks that the InvalidationJobBulkInput is well-formed. It does not check that the
+// identified Delivery Services exist or that the URLs match any origin, because that requires
+// database access; each job created from it is expected to be validated separately as an
+// InvalidationJobInput.
+//
+// This returns an error describing any and all problematic fields encountered during validation.
+func (job *InvalidationJobBulkInput) Validate() error {
+	errs := []string{}
+
+	if len(job.Regexes) == 0 && len(job.URLs) == 0 {
+		errs = append(errs, "at least one of 'regexes' or 'urls' is required")
+	}
+	if len(job.Regexes) > 0 && len(job.DeliveryServices) == 0 {
+		errs = append(errs, "deliveryServices: cannot be blank when 'regexes' are given")
+	}
+	if n := len(job.Regexes)*len(job.DeliveryServices) + len(job.URLs); n > MaxBulkInvalidationJobs {
+		errs = append(errs, fmt.Sprintf("cannot create more than %d jobs at once (requested %d)", MaxBulkInvalidationJobs, n))
+	}
+
+	for _, ds := range job.DeliveryServices {
+		switch ds.(type) {
+		case float64, string:
+		default:
+			errs = append(errs, fmt.Sprintf("deliveryServices: '%v' is not a valid Delivery Service identifier", ds))
+		}
+	}
+
+	for _, r := range job.Regexes {
+		if !strings.HasPrefix(r, `\/`) && !strings.HasPrefix(r, "/") {
+			errs = append(errs, fmt.Sprintf(`regexes: '%s' must start with '/' (or '\/')`, r))
+		} else if _, err := regexp.Compile(r); err != nil {
+			errs = append(errs, fmt.Sprintf("regexes: '%s' is not a valid Regular Expression: %v", r, err))
+		}
+	}
+
+	for _, u := range job.URLs {
+		parsed, err := url.Parse(u)
+		if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+			errs = append(errs, fmt.Sprintf("urls: '%s' is not an absolute http(s) URL", u))
+		}
+	}
+
+	if job.StartTime == nil {
+		errs = append(errs, "startTime: cannot be blank")
+	} else if job.StartTime.Time.Before(time.Now()) {
+		errs = append(errs, "startTime: must be in the future")
+	}
+
+	if job.TTL == nil {
+		errs = append(errs, "ttl: cannot be blank")
+	} else if _, err := (&InvalidationJobInput{TTL: job.TTL}).TTLHours(); err != nil {
+		errs = append(errs, "ttl: must be a number of hours, or a duration string e.g. '48h'")
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// URLRegex returns the regular expression matching exactly the path and query string of the given
+// URL, suitable for use as the Regex of an InvalidationJobInput, as well as the URL's origin (its
+// scheme and authority, without a trailing '/').
+func URLRegex(u string) (string, string, error) {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return "", "", err
+	}
+	if parsed.Host == "" || parsed.Scheme == "" {
+		return "", "", errors.New("not an absolute URL")
+	}
+	path := parsed.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if parsed.RawQuery != "" {
+		path += "?" + parsed.RawQuery
+	}
+	return regexp.QuoteMeta(path), parsed.Scheme + "://" + parsed.Host, nil
+}
+
+// InvalidationJobStatus represents how far a content invalidation job has propagated to the cache
+// servers that must apply it.
+type InvalidationJobStatus struct {
+	InvalidationJob
+
+	// State is one of InvalidationJobStatePending, InvalidationJobStateInProgress,
+	// InvalidationJobStateComplete, or InvalidationJobStateExpired.
+	State string `json:"state"`
+
+	// AssignedCaches is the number of cache servers which must apply the revalidation.
+	AssignedCaches int `json:"assignedCaches"`
+
+	// AppliedCaches is the number of those cache servers which have no revalidation pending.
+	AppliedCaches int `json:"appliedCaches"`
+
+	// PendingCaches are the host names of the cache servers which still have a revalidation
+	// pending.
+	PendingCaches []string `json:"pendingCaches"`
+}
+
+// InvalidationJobSchedule is a deferred or recurring content invalidation job. When it comes due,
+// Traffic Ops creates an InvalidationJob from it and queues revalidation on the affected cache
+// servers.
+type InvalidationJobSchedule struct {
+	ID              *uint64 `json:"id" db:"id"`
+	AssetURL        *string `json:"assetUrl" db:"asset_url"`
+	CreatedBy       *string `json:"createdBy" db:"created_by"`
+	DeliveryService *string `json:"deliveryService" db:"deliveryservice"`
+
+	// Cron is the standard five-field cron expression describing when the job recurs. If it is
+	// nil, the schedule creates exactly one job, at NextRun, and is then removed.
+	Cron        *string    `json:"cron" db:"cron"`
+	LastRun     *Time      `json:"lastRun" db:"last_run"`
+	LastUpdated *TimeNoMod `json:"lastUpdated" db:"last_updated"`
+	NextRun     *Time      `json:"nextRun" db:"next_run"`
+	Parameters  *string    `json:"parameters" db:"parameters"`
+}
+
+// InvalidationJobScheduleInput represents user input intending to create an
+// InvalidationJobSchedule.
+//
+// The embedded InvalidationJobInput has the same meaning as it does when creating a single job,
+// except that StartTime is the time of the first run. StartTime may be omitted if Cron is given, in
+// which case the first run is the next time matched by Cron.
+type InvalidationJobScheduleInput struct {
+	InvalidationJobInput
+	Cron *string `json:"cron"`
+}
+
+// Validate validates that the user input is correct, given a transaction connected to the Traffic
+// Ops database. If Cron is given but StartTime is not, this sets StartTime to the next time
+// matched by Cron.
+//
+// This returns an error describing any and all problematic fields encountered during validation.
+func (job *InvalidationJobScheduleInput) Validate(tx *sql.Tx) error {
+	if job.Cron != nil {
+		sched, err := util.ParseCron(*job.Cron)
+		if err != nil {
+			return errors.New("cron: " + err.Error())
+		}
+		if job.StartTime == nil {
+			next := sched.Next(time.Now())
+			if next.IsZero() {
+				return errors.New("cron: never matches any time")
+			}
+			job.StartTime = &Time{Time: next, Valid: true}
+		}
+	}
+	return job.InvalidationJobInput.Validate(tx)
+}
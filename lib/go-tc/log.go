@@ -32,6 +32,9 @@ type Log struct {
 	Message     *string `json:"message"`
 	TicketNum   *int    `json:"ticketNum"`
 	User        *string `json:"user"`
+	// CorrelationID is the correlation ID of the Traffic Ops request which made the change, if
+	// it's known.
+	CorrelationID *string `json:"correlationId"`
 }
 
 // NewLogCountResp is the response returned when the total number of new changes
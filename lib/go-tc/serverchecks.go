@@ -220,3 +220,137 @@ type ServerCheckColumns struct {
 	BE *int `db:"be"`
 	BF *int `db:"bf"`
 }
+
+// ServercheckResult is a single result of a server check, as kept in its history.
+type ServercheckResult struct {
+	// Name is the short name of the check extension which reported the result.
+	Name  string `json:"name" db:"check_name"`
+	Value int    `json:"value" db:"value"`
+	Time  Time   `json:"time" db:"created"`
+}
+
+// ServercheckHistoryResponse is the type of a response from Traffic Ops to a GET request to the
+// /servers/{{ID}}/checks endpoint.
+type ServercheckHistoryResponse struct {
+	Response []ServercheckResult `json:"response"`
+}
+
+// These are the comparisons a ServercheckRule may make between a check result and its Threshold.
+const (
+	ServercheckComparisonLess         = "<"
+	ServercheckComparisonLessEqual    = "<="
+	ServercheckComparisonEqual        = "="
+	ServercheckComparisonNotEqual     = "!="
+	ServercheckComparisonGreaterEqual = ">="
+	ServercheckComparisonGreater      = ">"
+)
+
+// ServercheckRule is an action taken when the results of a server check match a condition, e.g.
+// "ORT failed 3 times in a row".
+//
+// A rule is triggered by the result which makes Consecutive results in a row match, and not again
+// until a result does not match.
+type ServercheckRule struct {
+	ID *int `json:"id" db:"id"`
+
+	// CheckName is the short name of the check extension whose results are matched.
+	CheckName *string `json:"checkName" db:"check_name"`
+
+	// A result matches if it compares to Threshold by Comparison, which is one of the
+	// ServercheckComparison constants - e.g. "value < 1".
+	Comparison *string `json:"comparison" db:"comparison"`
+	Threshold  *int    `json:"threshold" db:"threshold"`
+
+	// Consecutive is how many results in a row must match to trigger the rule.
+	Consecutive *int `json:"consecutive" db:"consecutive"`
+
+	// Status, if not nil, is the name of the Status the server is set to when the rule is triggered.
+	Status *string `json:"status" db:"status"`
+
+	// Alert is whether or not the rule being triggered is reported, as a warning to the reporting
+	// check extension and as an Event.
+	Alert *bool `json:"alert" db:"alert"`
+
+	Description *string    `json:"description" db:"description"`
+	LastUpdated *TimeNoMod `json:"lastUpdated" db:"last_updated"`
+}
+
+// ServercheckRulesResponse is the type of a response from Traffic Ops to a GET request to the
+// /servercheck/rules endpoint.
+type ServercheckRulesResponse struct {
+	Response []ServercheckRule `json:"response"`
+}
+
+// Validate checks that the ServercheckRule is well-formed, and that its check extension and Status
+// exist.
+func (r *ServercheckRule) Validate(tx *sql.Tx) error {
+	errs := []string{}
+	if r.CheckName == nil || *r.CheckName == "" {
+		errs = append(errs, "checkName is required")
+	} else {
+		exists := false
+		if err := tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM to_extension WHERE servercheck_short_name = $1)`, *r.CheckName).Scan(&exists); err != nil {
+			return errors.New("checking for check extension: " + err.Error())
+		}
+		if !exists {
+			errs = append(errs, "no check extension with short name '"+*r.CheckName+"'")
+		}
+	}
+	if r.Comparison == nil {
+		errs = append(errs, "comparison is required")
+	} else {
+		switch *r.Comparison {
+		case ServercheckComparisonLess, ServercheckComparisonLessEqual, ServercheckComparisonEqual, ServercheckComparisonNotEqual, ServercheckComparisonGreaterEqual, ServercheckComparisonGreater:
+		default:
+			errs = append(errs, "comparison must be one of <, <=, =, !=, >=, >")
+		}
+	}
+	if r.Threshold == nil {
+		errs = append(errs, "threshold is required")
+	}
+	if r.Consecutive == nil {
+		r.Consecutive = util.IntPtr(1)
+	} else if *r.Consecutive < 1 {
+		errs = append(errs, "consecutive must be at least 1")
+	}
+	if r.Alert == nil {
+		r.Alert = util.BoolPtr(false)
+	}
+	if r.Status != nil {
+		exists := false
+		if err := tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM status WHERE name = $1)`, *r.Status).Scan(&exists); err != nil {
+			return errors.New("checking for status: " + err.Error())
+		}
+		if !exists {
+			errs = append(errs, "no status named '"+*r.Status+"'")
+		}
+	} else if !*r.Alert {
+		errs = append(errs, "a rule must set a status, alert, or both")
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// Matches returns whether or not the given check result matches the rule's condition.
+func (r ServercheckRule) Matches(value int) bool {
+	if r.Comparison == nil || r.Threshold == nil {
+		return false
+	}
+	switch *r.Comparison {
+	case ServercheckComparisonLess:
+		return value < *r.Threshold
+	case ServercheckComparisonLessEqual:
+		return value <= *r.Threshold
+	case ServercheckComparisonEqual:
+		return value == *r.Threshold
+	case ServercheckComparisonNotEqual:
+		return value != *r.Threshold
+	case ServercheckComparisonGreaterEqual:
+		return value >= *r.Threshold
+	case ServercheckComparisonGreater:
+		return value > *r.Threshold
+	}
+	return false
+}
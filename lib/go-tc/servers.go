@@ -1,6 +1,7 @@
 package tc
 
 import (
+	"net"
 	"time"
 
 	"github.com/apache/trafficcontrol/lib/go-util"
@@ -50,54 +51,55 @@ type ServersV1DetailResponse struct {
 }
 
 type Server struct {
-	Cachegroup       string              `json:"cachegroup" db:"cachegroup"`
-	CachegroupID     int                 `json:"cachegroupId" db:"cachegroup_id"`
-	CDNID            int                 `json:"cdnId" db:"cdn_id"`
-	CDNName          string              `json:"cdnName" db:"cdn_name"`
-	DeliveryServices map[string][]string `json:"deliveryServices,omitempty"`
-	DomainName       string              `json:"domainName" db:"domain_name"`
-	FQDN             *string             `json:"fqdn,omitempty"`
-	FqdnTime         time.Time           `json:"-"`
-	GUID             string              `json:"guid" db:"guid"`
-	HostName         string              `json:"hostName" db:"host_name"`
-	HTTPSPort        int                 `json:"httpsPort" db:"https_port"`
-	ID               int                 `json:"id" db:"id"`
-	ILOIPAddress     string              `json:"iloIpAddress" db:"ilo_ip_address"`
-	ILOIPGateway     string              `json:"iloIpGateway" db:"ilo_ip_gateway"`
-	ILOIPNetmask     string              `json:"iloIpNetmask" db:"ilo_ip_netmask"`
-	ILOPassword      string              `json:"iloPassword" db:"ilo_password"`
-	ILOUsername      string              `json:"iloUsername" db:"ilo_username"`
-	InterfaceMtu     int                 `json:"interfaceMtu" db:"interface_mtu"`
-	InterfaceName    string              `json:"interfaceName" db:"interface_name"`
-	IP6Address       string              `json:"ip6Address" db:"ip6_address"`
-	IP6IsService     bool                `json:"ip6IsService" db:"ip6_address_is_service"`
-	IP6Gateway       string              `json:"ip6Gateway" db:"ip6_gateway"`
-	IPAddress        string              `json:"ipAddress" db:"ip_address"`
-	IPIsService      bool                `json:"ipIsService" db:"ip_address_is_service"`
-	IPGateway        string              `json:"ipGateway" db:"ip_gateway"`
-	IPNetmask        string              `json:"ipNetmask" db:"ip_netmask"`
-	LastUpdated      TimeNoMod           `json:"lastUpdated" db:"last_updated"`
-	MgmtIPAddress    string              `json:"mgmtIpAddress" db:"mgmt_ip_address"`
-	MgmtIPGateway    string              `json:"mgmtIpGateway" db:"mgmt_ip_gateway"`
-	MgmtIPNetmask    string              `json:"mgmtIpNetmask" db:"mgmt_ip_netmask"`
-	OfflineReason    string              `json:"offlineReason" db:"offline_reason"`
-	PhysLocation     string              `json:"physLocation" db:"phys_location"`
-	PhysLocationID   int                 `json:"physLocationId" db:"phys_location_id"`
-	Profile          string              `json:"profile" db:"profile"`
-	ProfileDesc      string              `json:"profileDesc" db:"profile_desc"`
-	ProfileID        int                 `json:"profileId" db:"profile_id"`
-	Rack             string              `json:"rack" db:"rack"`
-	RevalPending     bool                `json:"revalPending" db:"reval_pending"`
-	RouterHostName   string              `json:"routerHostName" db:"router_host_name"`
-	RouterPortName   string              `json:"routerPortName" db:"router_port_name"`
-	Status           string              `json:"status" db:"status"`
-	StatusID         int                 `json:"statusId" db:"status_id"`
-	TCPPort          int                 `json:"tcpPort" db:"tcp_port"`
-	Type             string              `json:"type" db:"server_type"`
-	TypeID           int                 `json:"typeId" db:"server_type_id"`
-	UpdPending       bool                `json:"updPending" db:"upd_pending"`
-	XMPPID           string              `json:"xmppId" db:"xmpp_id"`
-	XMPPPasswd       string              `json:"xmppPasswd" db:"xmpp_passwd"`
+	Cachegroup       string                `json:"cachegroup" db:"cachegroup"`
+	CachegroupID     int                   `json:"cachegroupId" db:"cachegroup_id"`
+	CDNID            int                   `json:"cdnId" db:"cdn_id"`
+	CDNName          string                `json:"cdnName" db:"cdn_name"`
+	DeliveryServices map[string][]string   `json:"deliveryServices,omitempty"`
+	DomainName       string                `json:"domainName" db:"domain_name"`
+	FQDN             *string               `json:"fqdn,omitempty"`
+	FqdnTime         time.Time             `json:"-"`
+	GUID             string                `json:"guid" db:"guid"`
+	HostName         string                `json:"hostName" db:"host_name"`
+	HTTPSPort        int                   `json:"httpsPort" db:"https_port"`
+	ID               int                   `json:"id" db:"id"`
+	ILOIPAddress     string                `json:"iloIpAddress" db:"ilo_ip_address"`
+	ILOIPGateway     string                `json:"iloIpGateway" db:"ilo_ip_gateway"`
+	ILOIPNetmask     string                `json:"iloIpNetmask" db:"ilo_ip_netmask"`
+	ILOPassword      string                `json:"iloPassword" db:"ilo_password"`
+	ILOUsername      string                `json:"iloUsername" db:"ilo_username"`
+	InterfaceMtu     int                   `json:"interfaceMtu" db:"interface_mtu"`
+	InterfaceName    string                `json:"interfaceName" db:"interface_name"`
+	Interfaces       []ServerInterfaceInfo `json:"interfaces,omitempty" db:"-"`
+	IP6Address       string                `json:"ip6Address" db:"ip6_address"`
+	IP6IsService     bool                  `json:"ip6IsService" db:"ip6_address_is_service"`
+	IP6Gateway       string                `json:"ip6Gateway" db:"ip6_gateway"`
+	IPAddress        string                `json:"ipAddress" db:"ip_address"`
+	IPIsService      bool                  `json:"ipIsService" db:"ip_address_is_service"`
+	IPGateway        string                `json:"ipGateway" db:"ip_gateway"`
+	IPNetmask        string                `json:"ipNetmask" db:"ip_netmask"`
+	LastUpdated      TimeNoMod             `json:"lastUpdated" db:"last_updated"`
+	MgmtIPAddress    string                `json:"mgmtIpAddress" db:"mgmt_ip_address"`
+	MgmtIPGateway    string                `json:"mgmtIpGateway" db:"mgmt_ip_gateway"`
+	MgmtIPNetmask    string                `json:"mgmtIpNetmask" db:"mgmt_ip_netmask"`
+	OfflineReason    string                `json:"offlineReason" db:"offline_reason"`
+	PhysLocation     string                `json:"physLocation" db:"phys_location"`
+	PhysLocationID   int                   `json:"physLocationId" db:"phys_location_id"`
+	Profile          string                `json:"profile" db:"profile"`
+	ProfileDesc      string                `json:"profileDesc" db:"profile_desc"`
+	ProfileID        int                   `json:"profileId" db:"profile_id"`
+	Rack             string                `json:"rack" db:"rack"`
+	RevalPending     bool                  `json:"revalPending" db:"reval_pending"`
+	RouterHostName   string                `json:"routerHostName" db:"router_host_name"`
+	RouterPortName   string                `json:"routerPortName" db:"router_port_name"`
+	Status           string                `json:"status" db:"status"`
+	StatusID         int                   `json:"statusId" db:"status_id"`
+	TCPPort          int                   `json:"tcpPort" db:"tcp_port"`
+	Type             string                `json:"type" db:"server_type"`
+	TypeID           int                   `json:"typeId" db:"server_type_id"`
+	UpdPending       bool                  `json:"updPending" db:"upd_pending"`
+	XMPPID           string                `json:"xmppId" db:"xmpp_id"`
+	XMPPPasswd       string                `json:"xmppPasswd" db:"xmpp_passwd"`
 }
 
 type ServerV1 struct {
@@ -198,12 +200,85 @@ type ServerNullableV11 struct {
 	XMPPPasswd       *string              `json:"xmppPasswd" db:"xmpp_passwd"`
 }
 
-type ServerNullable struct {
+// ServerNullableV2 is a server as of API version 2.0, which has exactly one interface.
+type ServerNullableV2 struct {
 	ServerNullableV11
 	IPIsService  *bool `json:"ipIsService" db:"ip_address_is_service"`
 	IP6IsService *bool `json:"ip6IsService" db:"ip6_address_is_service"`
 }
 
+// ServerNullable is a server as of the latest API version, which may have any number of network
+// interfaces. The legacy interface and address fields reflect its service interface.
+type ServerNullable struct {
+	ServerNullableV2
+	Interfaces []ServerInterfaceInfo `json:"interfaces,omitempty" db:"-"`
+}
+
+// ServerIPAddress is one of the IP addresses of a server's network interface. The Address may
+// include a prefix length, e.g. "192.0.2.1/24".
+type ServerIPAddress struct {
+	Address        string  `json:"address" db:"address"`
+	Gateway        *string `json:"gateway" db:"gateway"`
+	ServiceAddress bool    `json:"serviceAddress" db:"service_address"`
+}
+
+// IP returns the address without its prefix length, or nil if the Address is not a valid IP
+// address or CIDR.
+func (a ServerIPAddress) IP() net.IP {
+	if ip, _, err := net.ParseCIDR(a.Address); err == nil {
+		return ip
+	}
+	return net.ParseIP(a.Address)
+}
+
+// ServerInterfaceInfo is a network interface of a server. The MaxBandwidth is in kilobits per
+// second; a nil MaxBandwidth means the interface is not limited. Only interfaces with Monitor set
+// are polled and checked against their MaxBandwidth by Traffic Monitor.
+type ServerInterfaceInfo struct {
+	IPAddresses  []ServerIPAddress `json:"ipAddresses" db:"-"`
+	MaxBandwidth *uint64           `json:"maxBandwidth" db:"max_bandwidth"`
+	Monitor      bool              `json:"monitor" db:"monitor"`
+	MTU          *uint64           `json:"mtu" db:"mtu"`
+	Name         string            `json:"name" db:"name"`
+}
+
+// ServiceAddresses returns the IPv4 and IPv6 service addresses of the interface, either of which
+// may be nil.
+func (i ServerInterfaceInfo) ServiceAddresses() (*ServerIPAddress, *ServerIPAddress) {
+	ipv4 := (*ServerIPAddress)(nil)
+	ipv6 := (*ServerIPAddress)(nil)
+	for idx, addr := range i.IPAddresses {
+		if !addr.ServiceAddress {
+			continue
+		}
+		ip := addr.IP()
+		if ip == nil {
+			continue
+		}
+		if ip.To4() != nil {
+			if ipv4 == nil {
+				ipv4 = &i.IPAddresses[idx]
+			}
+		} else if ipv6 == nil {
+			ipv6 = &i.IPAddresses[idx]
+		}
+	}
+	return ipv4, ipv6
+}
+
+// GetServiceInterface returns the first of the interfaces which has a service address, and
+// whether one was found.
+func GetServiceInterface(interfaces []ServerInterfaceInfo) (ServerInterfaceInfo, bool) {
+	for _, iface := range interfaces {
+		for _, addr := range iface.IPAddresses {
+			if addr.ServiceAddress {
+				return iface, true
+			}
+		}
+	}
+	return ServerInterfaceInfo{}, false
+}
+
 type ServerUpdateStatus struct {
 	HostName           string `json:"host_name"`
 	UpdatePending      bool   `json:"upd_pending"`
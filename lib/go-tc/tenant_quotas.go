@@ -0,0 +1,102 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"strings"
+)
+
+// TenantQuota is the set of limits on the resources that may be used by a Tenant together with all
+// of its descendants. A nil limit means the resource is not limited by this Tenant's quota, though it
+// may still be limited by the quota of one of the Tenant's ancestors.
+type TenantQuota struct {
+	TenantID   *int    `json:"tenantId" db:"tenant"`
+	TenantName *string `json:"tenantName"`
+
+	// MaxDeliveryServices limits the number of Delivery Services.
+	MaxDeliveryServices *int `json:"maxDeliveryServices" db:"max_delivery_services"`
+	// MaxEdgeServers limits the number of assignments of edge-tier cache servers to Delivery Services.
+	MaxEdgeServers *int `json:"maxEdgeServers" db:"max_edge_servers"`
+	// MaxInvalidationJobsPerDay limits the number of Invalidation Jobs created in any 24 hours.
+	MaxInvalidationJobsPerDay *int `json:"maxInvalidationJobsPerDay" db:"max_invalidation_jobs_per_day"`
+	// MaxRegexesPerDeliveryService limits the number of regular expressions of each Delivery Service.
+	MaxRegexesPerDeliveryService *int `json:"maxRegexesPerDeliveryService" db:"max_regexes_per_ds"`
+
+	LastUpdated *TimeNoMod `json:"lastUpdated" db:"last_updated"`
+}
+
+// Validate returns an error if any of the quota's limits is negative.
+func (q TenantQuota) Validate() error {
+	errs := []string{}
+	limits := []struct {
+		name  string
+		limit *int
+	}{
+		{"maxDeliveryServices", q.MaxDeliveryServices},
+		{"maxEdgeServers", q.MaxEdgeServers},
+		{"maxInvalidationJobsPerDay", q.MaxInvalidationJobsPerDay},
+		{"maxRegexesPerDeliveryService", q.MaxRegexesPerDeliveryService},
+	}
+	for _, l := range limits {
+		if l.limit != nil && *l.limit < 0 {
+			errs = append(errs, l.name+" cannot be negative")
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// TenantQuotaResponse is the type of a response from Traffic Ops to a request for, or to set, the
+// quota of a Tenant.
+type TenantQuotaResponse struct {
+	Response TenantQuota `json:"response"`
+	Alerts
+}
+
+// QuotaUsage is how much of a resource is used, and the limit on it set by a Tenant's own quota, if
+// any.
+type QuotaUsage struct {
+	Used  int  `json:"used"`
+	Limit *int `json:"limit"`
+}
+
+// TenantUsage is the use of the resources limited by Tenant quotas by a Tenant together with all of
+// its descendants.
+type TenantUsage struct {
+	TenantID   int    `json:"tenantId"`
+	TenantName string `json:"tenantName"`
+
+	DeliveryServices       QuotaUsage `json:"deliveryServices"`
+	EdgeServers            QuotaUsage `json:"edgeServers"`
+	InvalidationJobsPerDay QuotaUsage `json:"invalidationJobsPerDay"`
+	// The usage of RegexesPerDeliveryService is the greatest number of regular expressions of any
+	// one Delivery Service.
+	RegexesPerDeliveryService QuotaUsage `json:"regexesPerDeliveryService"`
+}
+
+// TenantUsageResponse is the type of a response from Traffic Ops to a GET request to the
+// /tenants/usage endpoint.
+type TenantUsageResponse struct {
+	Response []TenantUsage `json:"response"`
+	Alerts
+}
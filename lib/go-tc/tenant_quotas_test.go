@@ -0,0 +1,45 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"testing"
+)
+
+func TestTenantQuotaValidate(t *testing.T) {
+	zero := 0
+	ten := 10
+	negative := -1
+
+	if err := (TenantQuota{}).Validate(); err != nil {
+		t.Errorf("expected empty quota to be valid, actual: %v", err)
+	}
+	if err := (TenantQuota{MaxDeliveryServices: &zero, MaxEdgeServers: &ten}).Validate(); err != nil {
+		t.Errorf("expected non-negative quota to be valid, actual: %v", err)
+	}
+
+	err := (TenantQuota{MaxEdgeServers: &negative, MaxRegexesPerDeliveryService: &negative}).Validate()
+	expected := "maxEdgeServers cannot be negative, maxRegexesPerDeliveryService cannot be negative"
+	if err == nil {
+		t.Errorf("expected negative quota to be invalid, actual: valid")
+	} else if err.Error() != expected {
+		t.Errorf("expected error '%s', actual: '%s'", expected, err.Error())
+	}
+}
@@ -0,0 +1,196 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// TopologyMaxParents is the greatest number of parents a Topology node may have: a primary and a
+// secondary.
+const TopologyMaxParents = 2
+
+// Topology is a named, directed acyclic graph of Cache Groups, through which the content of the
+// Delivery Services that use it flows from their origins to clients.
+//
+// A node with no parents requests content from the origin. A node which is no other node's parent
+// is in the first tier, and its cache servers are the ones clients are routed to.
+type Topology struct {
+	Name        string         `json:"name" db:"name"`
+	Description string         `json:"description" db:"description"`
+	Nodes       []TopologyNode `json:"nodes"`
+	LastUpdated *TimeNoMod     `json:"lastUpdated" db:"last_updated"`
+}
+
+// TopologyNode is a Cache Group in a Topology. Parents are the indices, in the Topology's Nodes, of
+// the node's primary parent and optional secondary parent, in that order.
+type TopologyNode struct {
+	Cachegroup string `json:"cachegroup"`
+	Parents    []int  `json:"parents"`
+}
+
+// TopologiesResponse is the type of a response from Traffic Ops to a request for Topologies.
+type TopologiesResponse struct {
+	Response []Topology `json:"response"`
+	Alerts
+}
+
+// TopologyResponse is the type of a response from Traffic Ops to a request to create or update a
+// Topology.
+type TopologyResponse struct {
+	Response Topology `json:"response"`
+	Alerts
+}
+
+// Validate returns an error if the Topology is not a well-formed graph: it must have a name and at
+// least one node, each node must have a unique Cache Group and at most TopologyMaxParents distinct
+// parents other than itself, and there must be no cycles.
+//
+// Whether the Cache Groups exist, and are of types that may be used in a Topology, can only be
+// checked against Traffic Ops' data.
+func (t Topology) Validate() error {
+	errs := []string{}
+	if strings.TrimSpace(t.Name) == "" {
+		errs = append(errs, "name cannot be blank")
+	}
+	if len(t.Nodes) == 0 {
+		errs = append(errs, "nodes cannot be empty")
+	}
+
+	cachegroups := map[string]int{}
+	for i, node := range t.Nodes {
+		if node.Cachegroup == "" {
+			errs = append(errs, fmt.Sprintf("node %d: cachegroup cannot be blank", i))
+		} else if j, ok := cachegroups[node.Cachegroup]; ok {
+			errs = append(errs, fmt.Sprintf("nodes %d and %d: cachegroup '%s' cannot be in a topology more than once", j, i, node.Cachegroup))
+		} else {
+			cachegroups[node.Cachegroup] = i
+		}
+
+		if len(node.Parents) > TopologyMaxParents {
+			errs = append(errs, fmt.Sprintf("node %d: cannot have more than %d parents", i, TopologyMaxParents))
+		}
+		if len(node.Parents) == 2 && node.Parents[0] == node.Parents[1] {
+			errs = append(errs, fmt.Sprintf("node %d: primary and secondary parents cannot be the same", i))
+		}
+		for _, parent := range node.Parents {
+			if parent < 0 || parent >= len(t.Nodes) {
+				errs = append(errs, fmt.Sprintf("node %d: parent %d does not exist", i, parent))
+			} else if parent == i {
+				errs = append(errs, fmt.Sprintf("node %d: cannot be its own parent", i))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, ", "))
+	}
+
+	if cycle := t.findCycle(); len(cycle) > 0 {
+		names := make([]string, 0, len(cycle))
+		for _, i := range cycle {
+			names = append(names, t.Nodes[i].Cachegroup)
+		}
+		return errors.New("topology cannot have cycles: " + strings.Join(names, " -> "))
+	}
+	return nil
+}
+
+// findCycle returns the indices of the nodes in a cycle of parents, with the first node repeated
+// at the end, or nil if there are none. The parents of every node must be valid indices.
+func (t Topology) findCycle() []int {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(t.Nodes))
+	path := []int{}
+
+	var visit func(int) []int
+	visit = func(i int) []int {
+		state[i] = visiting
+		path = append(path, i)
+		for _, parent := range t.Nodes[i].Parents {
+			switch state[parent] {
+			case visiting:
+				for j, node := range path {
+					if node == parent {
+						return append(append([]int{}, path[j:]...), parent)
+					}
+				}
+			case unvisited:
+				if cycle := visit(parent); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[i] = visited
+		return nil
+	}
+
+	for i := range t.Nodes {
+		if state[i] != unvisited {
+			continue
+		}
+		if cycle := visit(i); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// NodeIndex returns the index in the Topology's Nodes of the node with the given Cache Group, and
+// whether there is one.
+func (t Topology) NodeIndex(cachegroup string) (int, bool) {
+	for i, node := range t.Nodes {
+		if node.Cachegroup == cachegroup {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// IsParent returns whether the node at the given index of the Topology's Nodes is the parent of any
+// other node.
+func (t Topology) IsParent(index int) bool {
+	for _, node := range t.Nodes {
+		for _, parent := range node.Parents {
+			if parent == index {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// FirstTierCachegroups returns the Cache Groups of the Topology's nodes which are not the parent of
+// any other node, in the order of the nodes.
+func (t Topology) FirstTierCachegroups() []string {
+	cachegroups := []string{}
+	for i, node := range t.Nodes {
+		if !t.IsParent(i) {
+			cachegroups = append(cachegroups, node.Cachegroup)
+		}
+	}
+	return cachegroups
+}
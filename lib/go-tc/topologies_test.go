@@ -0,0 +1,85 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTopologyValidate(t *testing.T) {
+	valid := Topology{
+		Name: "mso",
+		Nodes: []TopologyNode{
+			{Cachegroup: "edge1", Parents: []int{2, 3}},
+			{Cachegroup: "edge2", Parents: []int{3}},
+			{Cachegroup: "mid1"},
+			{Cachegroup: "mid2", Parents: []int{2}},
+		},
+	}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("expected valid topology, actual: %v", err)
+	}
+
+	cases := []struct {
+		name     string
+		topology Topology
+		err      string
+	}{
+		{"no name", Topology{Nodes: []TopologyNode{{Cachegroup: "edge"}}}, "name cannot be blank"},
+		{"no nodes", Topology{Name: "empty"}, "nodes cannot be empty"},
+		{"duplicate cachegroup", Topology{Name: "dup", Nodes: []TopologyNode{{Cachegroup: "edge"}, {Cachegroup: "edge"}}}, "more than once"},
+		{"missing parent", Topology{Name: "missing", Nodes: []TopologyNode{{Cachegroup: "edge", Parents: []int{1}}}}, "parent 1 does not exist"},
+		{"self parent", Topology{Name: "self", Nodes: []TopologyNode{{Cachegroup: "edge", Parents: []int{0}}}}, "its own parent"},
+		{"same parents", Topology{Name: "same", Nodes: []TopologyNode{{Cachegroup: "edge", Parents: []int{1, 1}}, {Cachegroup: "mid"}}}, "cannot be the same"},
+		{"too many parents", Topology{Name: "many", Nodes: []TopologyNode{{Cachegroup: "edge", Parents: []int{1, 2, 3}}, {Cachegroup: "a"}, {Cachegroup: "b"}, {Cachegroup: "c"}}}, "more than 2 parents"},
+		{"cycle", Topology{Name: "cycle", Nodes: []TopologyNode{{Cachegroup: "edge", Parents: []int{1}}, {Cachegroup: "mid1", Parents: []int{2}}, {Cachegroup: "mid2", Parents: []int{1}}}}, "cycles: mid1 -> mid2 -> mid1"},
+	}
+	for _, c := range cases {
+		err := c.topology.Validate()
+		if err == nil {
+			t.Errorf("%s: expected error containing '%s', actual: valid", c.name, c.err)
+		} else if !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%s: expected error containing '%s', actual: %v", c.name, c.err, err)
+		}
+	}
+}
+
+func TestTopologyFirstTierCachegroups(t *testing.T) {
+	topology := Topology{
+		Name: "tiers",
+		Nodes: []TopologyNode{
+			{Cachegroup: "mid", Parents: nil},
+			{Cachegroup: "edge1", Parents: []int{0}},
+			{Cachegroup: "edge2", Parents: []int{0}},
+		},
+	}
+	expected := []string{"edge1", "edge2"}
+	if actual := topology.FirstTierCachegroups(); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected first tier %v, actual: %v", expected, actual)
+	}
+	if i, ok := topology.NodeIndex("edge2"); !ok || i != 2 {
+		t.Errorf("expected node index of edge2 to be 2, actual: %d %v", i, ok)
+	}
+	if _, ok := topology.NodeIndex("nonexistent"); ok {
+		t.Error("expected no node index of nonexistent cachegroup")
+	}
+}
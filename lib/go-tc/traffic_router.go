@@ -132,19 +132,20 @@ type StaticDNS struct {
 
 // TrafficServer ...
 type TrafficServer struct {
-	Profile          string              `json:"profile"`
-	IP               string              `json:"ip"`
-	ServerStatus     string              `json:"status"`
-	CacheGroup       string              `json:"cacheGroup"`
-	IP6              string              `json:"ip6"`
-	Port             int                 `json:"port"`
-	HTTPSPort        int                 `json:"httpsPort,omitempty"`
-	HostName         string              `json:"hostName"`
-	FQDN             string              `json:"fqdn"`
-	InterfaceName    string              `json:"interfaceName"`
-	Type             string              `json:"type"`
-	HashID           string              `json:"hashId"`
-	DeliveryServices []tsdeliveryService `json:"deliveryServices,omitempty"` // the deliveryServices key does not exist on mids
+	Profile          string                `json:"profile"`
+	IP               string                `json:"ip"`
+	ServerStatus     string                `json:"status"`
+	CacheGroup       string                `json:"cacheGroup"`
+	IP6              string                `json:"ip6"`
+	Port             int                   `json:"port"`
+	HTTPSPort        int                   `json:"httpsPort,omitempty"`
+	HostName         string                `json:"hostName"`
+	FQDN             string                `json:"fqdn"`
+	InterfaceName    string                `json:"interfaceName"`
+	Interfaces       []ServerInterfaceInfo `json:"interfaces,omitempty"`
+	Type             string                `json:"type"`
+	HashID           string                `json:"hashId"`
+	DeliveryServices []tsdeliveryService   `json:"deliveryServices,omitempty"` // the deliveryServices key does not exist on mids
 }
 
 type tsdeliveryService struct {
@@ -0,0 +1,206 @@
+package util
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSchedule is a parsed, standard five-field cron expression:
+//
+//   minute hour day-of-month month day-of-week
+//
+// Each field may be '*', a number, a range 'a-b', any of those followed by a step '/n', or a
+// comma-separated list of any of those. Day-of-week is 0-6, where 0 is Sunday (7 is also accepted
+// as Sunday). As in the traditional cron, if both day-of-month and day-of-week are restricted
+// (i.e. neither is '*'), a time matches when either of them matches.
+//
+// The macros @hourly, @daily (or @midnight), @weekly, @monthly and @yearly (or @annually) are also
+// accepted.
+type CronSchedule struct {
+	minute     uint64
+	hour       uint64
+	dayOfMonth uint64
+	month      uint64
+	dayOfWeek  uint64
+	domStar    bool
+	dowStar    bool
+}
+
+// cronMaxYears is how far into the future Next will search before giving up. Any valid expression
+// matches at least once every four years (Feb 29), so this is only reached by expressions which can
+// never match, like "0 0 31 2 *".
+const cronMaxYears = 5
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+type cronFieldBounds struct {
+	name string
+	min  uint
+	max  uint
+}
+
+var cronFields = []cronFieldBounds{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+// ParseCron parses a cron expression, returning an error if it is malformed.
+func ParseCron(expr string) (CronSchedule, error) {
+	expr = strings.TrimSpace(expr)
+	if macro, ok := cronMacros[strings.ToLower(expr)]; ok {
+		expr = macro
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != len(cronFields) {
+		return CronSchedule{}, fmt.Errorf("expected %d space-separated fields, got %d", len(cronFields), len(fields))
+	}
+
+	bits := make([]uint64, len(fields))
+	for i, field := range fields {
+		b, err := parseCronField(field, cronFields[i])
+		if err != nil {
+			return CronSchedule{}, fmt.Errorf("%s: %v", cronFields[i].name, err)
+		}
+		bits[i] = b
+	}
+
+	// Sunday may be written as 0 or 7
+	if bits[4]&(1<<7) != 0 {
+		bits[4] = (bits[4] &^ (1 << 7)) | 1
+	}
+
+	return CronSchedule{
+		minute:     bits[0],
+		hour:       bits[1],
+		dayOfMonth: bits[2],
+		month:      bits[3],
+		dayOfWeek:  bits[4],
+		domStar:    strings.HasPrefix(fields[2], "*"),
+		dowStar:    strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+func parseCronField(field string, bounds cronFieldBounds) (uint64, error) {
+	bits := uint64(0)
+	for _, part := range strings.Split(field, ",") {
+		if part == "" {
+			return 0, errors.New("empty list element")
+		}
+
+		rng := part
+		step := uint64(1)
+		if i := strings.Index(part, "/"); i >= 0 {
+			rng = part[:i]
+			s, err := strconv.ParseUint(part[i+1:], 10, 8)
+			if err != nil || s == 0 {
+				return 0, fmt.Errorf("invalid step '%s'", part[i+1:])
+			}
+			step = s
+		}
+
+		low, high := uint64(bounds.min), uint64(bounds.max)
+		if rng != "*" {
+			var err error
+			if i := strings.Index(rng, "-"); i >= 0 {
+				if low, err = strconv.ParseUint(rng[:i], 10, 8); err != nil {
+					return 0, fmt.Errorf("invalid range start '%s'", rng[:i])
+				}
+				if high, err = strconv.ParseUint(rng[i+1:], 10, 8); err != nil {
+					return 0, fmt.Errorf("invalid range end '%s'", rng[i+1:])
+				}
+			} else {
+				if low, err = strconv.ParseUint(rng, 10, 8); err != nil {
+					return 0, fmt.Errorf("invalid value '%s'", rng)
+				}
+				high = low
+				if step != 1 {
+					high = uint64(bounds.max)
+				}
+			}
+		}
+
+		if low < uint64(bounds.min) || high > uint64(bounds.max) {
+			return 0, fmt.Errorf("'%s' is out of range %d-%d", rng, bounds.min, bounds.max)
+		}
+		if low > high {
+			return 0, fmt.Errorf("range '%s' ends before it starts", rng)
+		}
+
+		for v := low; v <= high; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// Next returns the first time strictly after t, truncated to the minute, at which the schedule
+// matches. The returned time is in the same location as t.
+//
+// If the schedule can never match (e.g. "0 0 30 2 *"), the zero time.Time is returned.
+func (s CronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(cronMaxYears, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s CronSchedule) matchesDay(t time.Time) bool {
+	dom := s.dayOfMonth&(1<<uint(t.Day())) != 0
+	dow := s.dayOfWeek&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
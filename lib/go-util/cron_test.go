@@ -0,0 +1,83 @@
+package util
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronErrors(t *testing.T) {
+	bad := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"1,,2 * * * *",
+		"@fortnightly",
+	}
+	for _, expr := range bad {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) expected: error, actual: nil", expr)
+		}
+	}
+}
+
+func TestCronScheduleNext(t *testing.T) {
+	base := time.Date(2020, time.March, 31, 10, 17, 42, 0, time.UTC) // a Tuesday
+
+	type testCase struct {
+		expr     string
+		expected time.Time
+	}
+	testCases := []testCase{
+		{"* * * * *", time.Date(2020, time.March, 31, 10, 18, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2020, time.March, 31, 10, 30, 0, 0, time.UTC)},
+		{"17 * * * *", time.Date(2020, time.March, 31, 11, 17, 0, 0, time.UTC)},
+		{"0 3 * * *", time.Date(2020, time.April, 1, 3, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2020, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2020, time.March, 31, 11, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2020, time.April, 5, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2020, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		{"30 2 * * 1-5", time.Date(2020, time.April, 1, 2, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2020, time.April, 5, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 12 15 * 5", time.Date(2020, time.April, 3, 12, 0, 0, 0, time.UTC)}, // dom OR dow
+		{"0 0 31 2 *", time.Time{}},
+	}
+
+	for _, tc := range testCases {
+		s, err := ParseCron(tc.expr)
+		if err != nil {
+			t.Errorf("ParseCron(%q) unexpected error: %v", tc.expr, err)
+			continue
+		}
+		if actual := s.Next(base); !actual.Equal(tc.expected) {
+			t.Errorf("ParseCron(%q).Next(%v) expected: %v, actual: %v", tc.expr, base, tc.expected, actual)
+		}
+	}
+}
@@ -22,6 +22,8 @@ package cache
 import (
 	"fmt"
 	"io"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/apache/trafficcontrol/lib/go-log"
@@ -89,6 +91,10 @@ func (result *Result) HasStat(stat string) bool {
 	if _, ok := result.Astats.Ats[stat]; ok {
 		return true
 	}
+	if iface, ok := InterfaceOfStat(stat); ok {
+		_, ok = result.Vitals.Interfaces[iface]
+		return ok
+	}
 	return false
 }
 
@@ -99,6 +105,72 @@ type Vitals struct {
 	BytesIn    int64
 	KbpsOut    int64
 	MaxKbpsOut int64
+	Interfaces map[string]InterfaceVitals
+}
+
+// InterfaceVitals is the vitals data of one network interface of a cache.
+type InterfaceVitals struct {
+	BytesOut int64
+	BytesIn  int64
+	KbpsOut  int64
+}
+
+// InterfaceStatPrefix prefixes the names of the stats computed for each network interface of a cache, which are named "interfaces.<interface>.<stat>".
+const InterfaceStatPrefix = "interfaces."
+
+// InterfaceWildcard may be used in place of an interface name in an interface stat threshold, e.g. "interfaces.*.bandwidth", to apply the threshold to every monitored interface.
+const InterfaceWildcard = "*"
+
+// InterfaceStats returns the stats computed for each network interface the cache reported, keyed by stat name. Interfaces with a max bandwidth in Traffic Ops also have their max and available bandwidth, in Kbps.
+func InterfaceStats(info ResultInfo, serverInfo tc.TrafficServer) map[string]interface{} {
+	stats := map[string]interface{}{}
+	for name, vitals := range info.Vitals.Interfaces {
+		prefix := InterfaceStatPrefix + name + "."
+		stats[prefix+"bandwidth"] = vitals.KbpsOut
+		stats[prefix+"bytesIn"] = vitals.BytesIn
+		stats[prefix+"bytesOut"] = vitals.BytesOut
+	}
+	for _, iface := range serverInfo.Interfaces {
+		vitals, ok := info.Vitals.Interfaces[iface.Name]
+		if !ok || iface.MaxBandwidth == nil {
+			continue
+		}
+		prefix := InterfaceStatPrefix + iface.Name + "."
+		stats[prefix+"maxBandwidth"] = int64(*iface.MaxBandwidth)
+		stats[prefix+"availableBandwidth"] = int64(*iface.MaxBandwidth) - vitals.KbpsOut
+	}
+	return stats
+}
+
+// MonitoredInterfaces returns the sorted names of the interfaces the cache reported, which are monitored in Traffic Ops. If Traffic Ops has no interfaces for the cache, all reported interfaces are returned.
+func MonitoredInterfaces(info ResultInfo, serverInfo tc.TrafficServer) []string {
+	names := []string{}
+	if len(serverInfo.Interfaces) == 0 {
+		for name := range info.Vitals.Interfaces {
+			names = append(names, name)
+		}
+	} else {
+		for _, iface := range serverInfo.Interfaces {
+			if _, ok := info.Vitals.Interfaces[iface.Name]; ok && iface.Monitor {
+				names = append(names, iface.Name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// InterfaceOfStat returns the interface of the given interface stat name, and false if the stat isn't an interface stat.
+func InterfaceOfStat(stat string) (string, bool) {
+	if !strings.HasPrefix(stat, InterfaceStatPrefix) {
+		return "", false
+	}
+	stat = stat[len(InterfaceStatPrefix):]
+	lastDot := strings.LastIndex(stat, ".")
+	if lastDot < 1 {
+		return "", false
+	}
+	return stat[:lastDot], true
 }
 
 // Stat is a generic stat, including the untyped value and the time the stat was taken.
@@ -22,6 +22,7 @@ package config
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"strings"
 	"time"
@@ -93,6 +94,56 @@ func (t *PollingProtocol) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// PeerCombiningStrategy is how the availability of a cache is combined from this monitor's and its peers' states.
+type PeerCombiningStrategy string
+
+const (
+	// PeerCombiningOptimistic marks a cache available if this monitor or any available peer does.
+	PeerCombiningOptimistic = PeerCombiningStrategy("optimistic")
+	// PeerCombiningMajority marks a cache available if more monitors mark it available than unavailable.
+	PeerCombiningMajority = PeerCombiningStrategy("majority")
+	// PeerCombiningWeighted is PeerCombiningMajority, with each peer's vote weighted by how recently it was polled.
+	PeerCombiningWeighted = PeerCombiningStrategy("weighted")
+	// PeerCombiningLocation is PeerCombiningMajority, with the votes of monitors in the cache's cachegroup weighted by the peer_location_weight.
+	PeerCombiningLocation = PeerCombiningStrategy("location")
+	// InvalidPeerCombiningStrategy is returned for unknown strategies.
+	InvalidPeerCombiningStrategy = PeerCombiningStrategy("invalid_peer_combining_strategy")
+)
+
+// String returns a string representation of this PeerCombiningStrategy.
+func (s PeerCombiningStrategy) String() string {
+	return string(s)
+}
+
+// PeerCombiningStrategyFromString returns a PeerCombiningStrategy based on the string input.
+func PeerCombiningStrategyFromString(s string) PeerCombiningStrategy {
+	switch s = strings.ToLower(s); s {
+	case PeerCombiningOptimistic.String():
+		return PeerCombiningOptimistic
+	case PeerCombiningMajority.String():
+		return PeerCombiningMajority
+	case PeerCombiningWeighted.String():
+		return PeerCombiningWeighted
+	case PeerCombiningLocation.String():
+		return PeerCombiningLocation
+	default:
+		return InvalidPeerCombiningStrategy
+	}
+}
+
+// UnmarshalJSON implements the json.Unmarshaller interface
+func (s *PeerCombiningStrategy) UnmarshalJSON(b []byte) error {
+	var str string
+	if err := json.Unmarshal(b, &str); err != nil {
+		return err
+	}
+	*s = PeerCombiningStrategyFromString(str)
+	if *s == InvalidPeerCombiningStrategy {
+		return errors.New("parsed invalid PeerCombiningStrategy: " + str)
+	}
+	return nil
+}
+
 // Config is the configuration for the application. It includes myriad data, such as polling intervals and log locations.
 type Config struct {
 	CacheHealthPollingInterval   time.Duration   `json:"-"`
@@ -125,6 +176,14 @@ type Config struct {
 	TrafficOpsDiskRetryMax       uint64          `json:"-"`
 	CachePollingProtocol         PollingProtocol `json:"cache_polling_protocol"`
 	PeerPollingProtocol          PollingProtocol `json:"peer_polling_protocol"`
+	// PeerCombiningStrategy is how cache availability is combined with peers. If it's not optimistic, the peer_optimistic setting is ignored.
+	PeerCombiningStrategy PeerCombiningStrategy `json:"peer_combining_strategy"`
+	// PeerLocationWeight is the weight of the votes of monitors in a cache's cachegroup, with the location strategy. Other monitors' votes have a weight of 1.
+	PeerLocationWeight float64 `json:"peer_location_weight"`
+	// RouterProbeInterval is how often each Traffic Router is probed with DNS and HTTP requests. If it's 0, Traffic Routers aren't probed.
+	RouterProbeInterval time.Duration `json:"-"`
+	// RouterProbeDeliveryService is the XMLID of the delivery service whose name Traffic Routers are probed for. If it's empty, the first delivery service by name with a routing name is used.
+	RouterProbeDeliveryService string `json:"router_probe_delivery_service"`
 }
 
 func (c Config) ErrorLog() log.LogLocation   { return log.LogLocation(c.LogLocationError) }
@@ -165,6 +224,10 @@ var DefaultConfig = Config{
 	TrafficOpsDiskRetryMax:       2,
 	CachePollingProtocol:         Both,
 	PeerPollingProtocol:          Both,
+	PeerCombiningStrategy:        PeerCombiningOptimistic,
+	PeerLocationWeight:           2,
+	RouterProbeInterval:          10 * time.Second,
+	RouterProbeDeliveryService:   "",
 }
 
 // MarshalJSON marshals custom millisecond durations. Aliasing inspired by http://choly.ca/post/go-json-marshalling/
@@ -184,6 +247,7 @@ func (c *Config) MarshalJSON() ([]byte, error) {
 		StatBufferIntervalMs           uint64 `json:"stat_buffer_interval_ms"`
 		ServeReadTimeoutMs             uint64 `json:"serve_read_timeout_ms"`
 		ServeWriteTimeoutMs            uint64 `json:"serve_write_timeout_ms"`
+		RouterProbeIntervalMs          uint64 `json:"router_probe_interval_ms"`
 		*Alias
 	}{
 		CacheHealthPollingIntervalMs:   uint64(c.CacheHealthPollingInterval / time.Millisecond),
@@ -196,6 +260,7 @@ func (c *Config) MarshalJSON() ([]byte, error) {
 		HealthFlushIntervalMs:          uint64(c.HealthFlushInterval / time.Millisecond),
 		StatFlushIntervalMs:            uint64(c.StatFlushInterval / time.Millisecond),
 		StatBufferIntervalMs:           uint64(c.StatBufferInterval / time.Millisecond),
+		RouterProbeIntervalMs:          uint64(c.RouterProbeInterval / time.Millisecond),
 		Alias:                          (*Alias)(c),
 	})
 }
@@ -221,6 +286,7 @@ func (c *Config) UnmarshalJSON(data []byte) error {
 		TrafficOpsDiskRetryMax         *uint64 `json:"traffic_ops_disk_retry_max"`
 		CRConfigBackupFile             *string `json:"crconfig_backup_file"`
 		TMConfigBackupFile             *string `json:"tmconfig_backup_file"`
+		RouterProbeIntervalMs          *uint64 `json:"router_probe_interval_ms"`
 		*Alias
 	}{
 		Alias: (*Alias)(c),
@@ -281,6 +347,12 @@ func (c *Config) UnmarshalJSON(data []byte) error {
 	if aux.TMConfigBackupFile != nil {
 		c.TMConfigBackupFile = *aux.TMConfigBackupFile
 	}
+	if aux.RouterProbeIntervalMs != nil {
+		c.RouterProbeInterval = time.Duration(*aux.RouterProbeIntervalMs) * time.Millisecond
+	}
+	if c.PeerLocationWeight <= 0 {
+		return fmt.Errorf("peer_location_weight must be greater than 0, got %v", c.PeerLocationWeight)
+	}
 	return nil
 }
 
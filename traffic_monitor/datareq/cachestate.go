@@ -58,6 +58,8 @@ type CacheStatus struct {
 	IPv4Available          *bool    `json:"ipv4_available,omitempty"`
 	IPv6Available          *bool    `json:"ipv6_available,omitempty"`
 	CombinedAvailable      *bool    `json:"combined_available,omitempty"`
+	// OverloadedDeliveryServices are the delivery services this cache's cachegroup is disabled for, because their traffic exceeded a delivery service threshold, with the reason for each.
+	OverloadedDeliveryServices map[tc.DeliveryServiceName]string `json:"overloaded_delivery_services,omitempty"`
 }
 
 func srvAPICacheStates(
@@ -73,7 +75,11 @@ func srvAPICacheStates(
 	monitorConfig threadsafe.TrafficMonitorConfigMap,
 ) ([]byte, error) {
 	json := jsoniter.ConfigFastest
-	return json.Marshal(createCacheStatuses(toData.Get().ServerTypes, statInfoHistory.Get(), statResultHistory, healthHistory.Get(), lastHealthDurations.Get(), localStates.Get().Caches, lastStats.Get(), localCacheStatus, statMaxKbpses, monitorConfig.Get().TrafficServer))
+	toDataVal := toData.Get()
+	states := localStates.Get()
+	statii := createCacheStatuses(toDataVal.ServerTypes, statInfoHistory.Get(), statResultHistory, healthHistory.Get(), lastHealthDurations.Get(), states.Caches, lastStats.Get(), localCacheStatus, statMaxKbpses, monitorConfig.Get().TrafficServer)
+	addOverloadedDeliveryServices(statii, states.DeliveryService, toDataVal.ServerDeliveryServices, toDataVal.ServerCachegroups)
+	return json.Marshal(statii)
 }
 
 func createCacheStatuses(
@@ -183,6 +189,27 @@ func createCacheStatuses(
 	return statii
 }
 
+// addOverloadedDeliveryServices adds the delivery services overloaded in each cache's cachegroup, and the reasons, to the cache statuses.
+func addOverloadedDeliveryServices(statii map[tc.CacheName]CacheStatus, dsStates map[tc.DeliveryServiceName]tc.CRStatesDeliveryService, serverDeliveryServices map[tc.CacheName][]tc.DeliveryServiceName, serverCachegroups map[tc.CacheName]tc.CacheGroupName) {
+	for cacheName, status := range statii {
+		cg, ok := serverCachegroups[cacheName]
+		if !ok {
+			continue
+		}
+		for _, dsName := range serverDeliveryServices[cacheName] {
+			why, ok := dsStates[dsName].OverloadedLocations[cg]
+			if !ok {
+				continue
+			}
+			if status.OverloadedDeliveryServices == nil {
+				status.OverloadedDeliveryServices = map[tc.DeliveryServiceName]string{}
+			}
+			status.OverloadedDeliveryServices[dsName] = why
+		}
+		statii[cacheName] = status
+	}
+}
+
 //cacheStatusAndPoller returns the the reason why a cache is unavailable (or that is available), the poller, and 3 booleans in order:
 // IPv4 availability, IPv6 availability and Processed availability which is what the monitor reports based on the PollingProtocol chosen (ipv4only,ipv6only or both)
 func cacheStatusAndPoller(server tc.CacheName, serverInfo tc.TrafficServer, localCacheStatus cache.AvailableStatuses) (string, string, bool, bool, bool) {
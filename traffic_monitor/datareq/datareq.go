@@ -33,6 +33,7 @@ import (
 	"github.com/apache/trafficcontrol/traffic_monitor/config"
 	"github.com/apache/trafficcontrol/traffic_monitor/health"
 	"github.com/apache/trafficcontrol/traffic_monitor/peer"
+	"github.com/apache/trafficcontrol/traffic_monitor/router"
 	"github.com/apache/trafficcontrol/traffic_monitor/threadsafe"
 	"github.com/apache/trafficcontrol/traffic_monitor/todata"
 	"github.com/apache/trafficcontrol/traffic_monitor/towrap"
@@ -62,6 +63,8 @@ func MakeDispatchMap(
 	lastStats threadsafe.LastStats,
 	unpolledCaches threadsafe.UnpolledCaches,
 	monitorConfig threadsafe.TrafficMonitorConfigMap,
+	routerStats router.StatsThreadsafe,
+	cfg config.Config,
 ) map[string]http.HandlerFunc {
 
 	// wrap composes all universal wrapper functions. Right now, it's only the UnpolledCheck, but there may be others later. For example, security headers.
@@ -87,7 +90,10 @@ func MakeDispatchMap(
 			return srvEventLog(events)
 		}, ContentTypeJSON)),
 		"/publish/PeerStates": wrap(WrapParams(func(params url.Values, path string) ([]byte, int) {
-			return srvPeerStates(params, errorCount, path, toData, peerStates)
+			return srvPeerStates(params, errorCount, path, toData, peerStates, monitorConfig, cfg)
+		}, ContentTypeJSON)),
+		"/publish/RouterStats": wrap(WrapParams(func(params url.Values, path string) ([]byte, int) {
+			return srvRouterStats(params, errorCount, path, routerStats)
 		}, ContentTypeJSON)),
 		"/publish/Stats": wrap(WrapErr(errorCount, func() ([]byte, error) {
 			return srvStats(staticAppData, healthPollInterval, lastHealthDurations, fetchCount, healthIteration, errorCount, peerStates)
@@ -25,6 +25,7 @@ import (
 	"time"
 
 	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_monitor/config"
 	"github.com/apache/trafficcontrol/traffic_monitor/peer"
 	"github.com/apache/trafficcontrol/traffic_monitor/srvhttp"
 	"github.com/apache/trafficcontrol/traffic_monitor/threadsafe"
@@ -36,7 +37,9 @@ import (
 // APIPeerStates contains the data to be returned for an API call to get the peer states of a Traffic Monitor. This contains common API data returned by most endpoints, and a map of peers, to caches' states.
 type APIPeerStates struct {
 	srvhttp.CommonAPIData
-	Peers map[tc.TrafficMonitorName]map[tc.CacheName][]CacheState `json:"peers"`
+	// CombiningStrategy is how this monitor combines its peers' cache states.
+	CombiningStrategy config.PeerCombiningStrategy                            `json:"combiningStrategy"`
+	Peers             map[tc.TrafficMonitorName]map[tc.CacheName][]CacheState `json:"peers"`
 }
 
 // CacheState represents the available state of a cache.
@@ -44,23 +47,28 @@ type CacheState struct {
 	Value         bool `json:"value"`
 	Ipv4Available bool `json:"ipv4Available"`
 	Ipv6Available bool `json:"ipv6Available"`
+	// Weight is the weight of the peer's vote on the cache. It's omitted with the optimistic combining strategy, which doesn't weigh votes.
+	Weight *float64 `json:"weight,omitempty"`
 }
 
-func srvPeerStates(params url.Values, errorCount threadsafe.Uint, path string, toData todata.TODataThreadsafe, peerStates peer.CRStatesPeersThreadsafe) ([]byte, int) {
-	filter, err := NewPeerStateFilter(path, params, toData.Get().ServerTypes)
+func srvPeerStates(params url.Values, errorCount threadsafe.Uint, path string, toData todata.TODataThreadsafe, peerStates peer.CRStatesPeersThreadsafe, monitorConfig threadsafe.TrafficMonitorConfigMap, cfg config.Config) ([]byte, int) {
+	toDataVal := toData.Get()
+	filter, err := NewPeerStateFilter(path, params, toDataVal.ServerTypes)
 	if err != nil {
 		HandleErr(errorCount, path, err)
 		return []byte(err.Error()), http.StatusBadRequest
 	}
+	consensus := peer.NewConsensus(cfg, peerStates, monitorConfig.Get().TrafficMonitor)
 	json := jsoniter.ConfigFastest
-	bytes, err := json.Marshal(createAPIPeerStates(peerStates.GetCrstates(), peerStates.GetPeersOnline(), filter, params))
+	bytes, err := json.Marshal(createAPIPeerStates(peerStates.GetCrstates(), peerStates.GetPeersOnline(), peerStates.GetQueryTimes(), consensus, toDataVal.ServerCachegroups, filter, params))
 	return WrapErrCode(errorCount, path, bytes, err)
 }
 
-func createAPIPeerStates(peerStates map[tc.TrafficMonitorName]tc.CRStates, peersOnline map[tc.TrafficMonitorName]bool, filter *PeerStateFilter, params url.Values) APIPeerStates {
+func createAPIPeerStates(peerStates map[tc.TrafficMonitorName]tc.CRStates, peersOnline map[tc.TrafficMonitorName]bool, peerTimes map[tc.TrafficMonitorName]time.Time, consensus peer.Consensus, serverCachegroups map[tc.CacheName]tc.CacheGroupName, filter *PeerStateFilter, params url.Values) APIPeerStates {
 	apiPeerStates := APIPeerStates{
-		CommonAPIData: srvhttp.GetCommonAPIData(params, time.Now()),
-		Peers:         map[tc.TrafficMonitorName]map[tc.CacheName][]CacheState{},
+		CommonAPIData:     srvhttp.GetCommonAPIData(params, time.Now()),
+		CombiningStrategy: consensus.Strategy,
+		Peers:             map[tc.TrafficMonitorName]map[tc.CacheName][]CacheState{},
 	}
 
 	for peer, state := range peerStates {
@@ -78,7 +86,12 @@ func createAPIPeerStates(peerStates map[tc.TrafficMonitorName]tc.CRStates, peers
 			if !filter.UseCache(cache) {
 				continue
 			}
-			peerState[cache] = []CacheState{CacheState{Value: available.IsAvailable, Ipv4Available: available.Ipv4Available, Ipv6Available: available.Ipv6Available}}
+			cacheState := CacheState{Value: available.IsAvailable, Ipv4Available: available.Ipv4Available, Ipv6Available: available.Ipv6Available}
+			if consensus.Strategy != config.PeerCombiningOptimistic {
+				weight := consensus.Weight(peer, peerTimes[peer], serverCachegroups[cache])
+				cacheState.Weight = &weight
+			}
+			peerState[cache] = []CacheState{cacheState}
 		}
 		apiPeerStates.Peers[peer] = peerState
 	}
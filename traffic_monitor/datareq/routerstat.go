@@ -0,0 +1,47 @@
+package datareq
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_monitor/router"
+	"github.com/apache/trafficcontrol/traffic_monitor/srvhttp"
+	"github.com/apache/trafficcontrol/traffic_monitor/threadsafe"
+
+	"github.com/json-iterator/go"
+)
+
+// APIRouterStats contains the data to be returned for an API call to get the Traffic Router probe stats of a Traffic Monitor.
+type APIRouterStats struct {
+	srvhttp.CommonAPIData
+	Routers map[tc.TrafficRouterName]router.Stats `json:"routers"`
+}
+
+func srvRouterStats(params url.Values, errorCount threadsafe.Uint, path string, routerStats router.StatsThreadsafe) ([]byte, int) {
+	json := jsoniter.ConfigFastest
+	bytes, err := json.Marshal(APIRouterStats{
+		CommonAPIData: srvhttp.GetCommonAPIData(params, time.Now()),
+		Routers:       routerStats.Get(),
+	})
+	return WrapErrCode(errorCount, path, bytes, err)
+}
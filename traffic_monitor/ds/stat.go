@@ -377,13 +377,14 @@ func addCachePerSecStats(lastStats *dsdata.LastStats, cacheName tc.CacheName, pr
 //
 // Note this mutates both dsStats and lastStats, adding the per-second stats to them.
 //
-func addPerSecStats(precomputed map[tc.CacheName]cache.PrecomputedData, dsStats *dsdata.Stats, lastStats *dsdata.LastStats, serverCachegroups map[tc.CacheName]tc.CacheGroupName, serverTypes map[tc.CacheName]tc.CacheType, mc tc.TrafficMonitorConfigMap, events health.ThreadsafeEvents, states peer.CRStatesThreadsafe) {
+func addPerSecStats(precomputed map[tc.CacheName]cache.PrecomputedData, dsStats *dsdata.Stats, lastStats *dsdata.LastStats, serverCachegroups map[tc.CacheName]tc.CacheGroupName, serverTypes map[tc.CacheName]tc.CacheType, mc tc.TrafficMonitorConfigMap, events health.ThreadsafeEvents, cacheStates map[tc.CacheName]tc.IsAvailable, states peer.CRStatesThreadsafe, now time.Time) {
 	for dsName, stat := range dsStats.DeliveryService {
 		addDSPerSecStats(lastStats, dsStats, dsName, stat, serverCachegroups, serverTypes, mc, events, precomputed, states)
 	}
 	for cacheName, precomputedData := range precomputed {
 		addCachePerSecStats(lastStats, cacheName, precomputedData)
 	}
+	addOverloadedLocations(dsStats, lastStats, serverCachegroups, mc, cacheStates, states, now)
 }
 
 // CreateStats aggregates and creates statistics from given precomputed stat history. It returns the created stats, information about these stats necessary for the next calculation, and any error.
@@ -452,7 +453,7 @@ func CreateStats(precomputed map[tc.CacheName]cache.PrecomputedData, toData toda
 		}
 	}
 
-	addPerSecStats(precomputed, dsStats, lastStats, toData.ServerCachegroups, toData.ServerTypes, mc, events, states)
+	addPerSecStats(precomputed, dsStats, lastStats, toData.ServerCachegroups, toData.ServerTypes, mc, events, crStates.Caches, states, now)
 	log.Infof("CreateStats took %v\n", time.Since(start))
 	dsStats.Time = time.Now()
 	return dsStats, nil
@@ -468,6 +469,56 @@ func getDSErr(dsName tc.DeliveryServiceName, dsStats dsdata.StatCacheStats, moni
 	return nil
 }
 
+// OverloadedLocationHoldTime is how long a location stays overloaded by a delivery service after the delivery service last exceeded its threshold there.
+// Once Traffic Router sheds the delivery service from the location, its traffic there falls, so without this the location would be re-enabled on the next poll, and flap.
+const OverloadedLocationHoldTime = 5 * time.Minute
+
+// addOverloadedLocations sets the overloaded locations of each delivery service in the local states, and adds them to its disabled locations. It must be called after the per-second stats of the delivery services and caches are calculated.
+// Locations which are no longer overloaded are re-enabled when the delivery service's disabled locations are next calculated from cache health.
+func addOverloadedLocations(dsStats *dsdata.Stats, lastStats *dsdata.LastStats, serverCachegroups map[tc.CacheName]tc.CacheGroupName, mc tc.TrafficMonitorConfigMap, cacheStates map[tc.CacheName]tc.IsAvailable, states peer.CRStatesThreadsafe, now time.Time) {
+	cacheKbps := make(map[tc.CacheName]float64, len(lastStats.Caches))
+	for cacheName, lastStat := range lastStats.Caches {
+		cacheKbps[cacheName] = lastStat.Bytes.PerSec / BytesPerKilobit
+	}
+	for dsName, stat := range dsStats.DeliveryService {
+		lastStat, ok := lastStats.DeliveryServices[dsName]
+		if !ok {
+			continue
+		}
+		dsKbps := make(map[tc.CacheName]float64, len(stat.Caches))
+		for cacheName, cacheStats := range stat.Caches {
+			dsKbps[cacheName] = cacheStats.Kbps.Value
+		}
+		dsState, _ := states.GetDeliveryService(dsName)
+		exceeded := health.EvalDeliveryServiceLocations(dsName, dsKbps, cacheKbps, cacheStates, serverCachegroups, &mc, dsState.OverloadedLocations)
+		dsState.OverloadedLocations = holdOverloadedLocations(lastStat, exceeded, now)
+		dsState.DisabledLocations = health.AddOverloadedLocations(dsState.DisabledLocations, dsState.OverloadedLocations)
+		states.SetDeliveryService(dsName, dsState)
+	}
+}
+
+// holdOverloadedLocations records the locations where the delivery service exceeded its threshold at now in lastStat, and returns the locations where it did so within the last OverloadedLocationHoldTime, with the reason for each.
+func holdOverloadedLocations(lastStat *dsdata.LastDSStat, exceeded map[tc.CacheGroupName]string, now time.Time) map[tc.CacheGroupName]string {
+	for cg, why := range exceeded {
+		if lastStat.OverloadedLocations == nil {
+			lastStat.OverloadedLocations = map[tc.CacheGroupName]dsdata.LocationOverload{}
+		}
+		lastStat.OverloadedLocations[cg] = dsdata.LocationOverload{Why: why, Exceeded: now}
+	}
+	overloaded := map[tc.CacheGroupName]string(nil)
+	for cg, overload := range lastStat.OverloadedLocations {
+		if now.Sub(overload.Exceeded) >= OverloadedLocationHoldTime {
+			delete(lastStat.OverloadedLocations, cg)
+			continue
+		}
+		if overloaded == nil {
+			overloaded = map[tc.CacheGroupName]string{}
+		}
+		overloaded[cg] = overload.Why
+	}
+	return overloaded
+}
+
 func SumDSAstats(ds *dsdata.StatCacheStats, cacheStat *cache.AStat) {
 	ds.OutBytes.Value += int64(cacheStat.OutBytes)
 	ds.InBytes.Value += float64(cacheStat.InBytes)
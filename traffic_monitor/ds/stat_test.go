@@ -405,3 +405,26 @@ func TestAddLastStatsToStatCacheStatsNilVals(t *testing.T) {
 	addLastStatsToStatCacheStats(&dsdata.StatCacheStats{}, nil)
 	addLastStatsToStatCacheStats(nil, &dsdata.LastStatsData{})
 }
+
+func TestHoldOverloadedLocations(t *testing.T) {
+	start := time.Now()
+	lastStat := newLastDSStat()
+
+	// shedding the delivery service from the location makes its traffic there fall, so it's only exceeded once
+	overloaded := holdOverloadedLocations(lastStat, map[tc.CacheGroupName]string{"cg0": "overloaded"}, start)
+	if overloaded["cg0"] != "overloaded" {
+		t.Fatalf("holdOverloadedLocations expected: cg0 overloaded, actual: %+v", overloaded)
+	}
+	for elapsed := time.Duration(0); elapsed < OverloadedLocationHoldTime; elapsed += 10 * time.Second {
+		overloaded = holdOverloadedLocations(lastStat.Copy(), nil, start.Add(elapsed))
+		if _, ok := overloaded["cg0"]; !ok {
+			t.Fatalf("holdOverloadedLocations %v after last exceeded expected: cg0 overloaded, actual: %+v", elapsed, overloaded)
+		}
+	}
+	if overloaded := holdOverloadedLocations(lastStat, nil, start.Add(OverloadedLocationHoldTime)); overloaded != nil {
+		t.Errorf("holdOverloadedLocations after the hold time expected: nil, actual: %+v", overloaded)
+	}
+	if len(lastStat.OverloadedLocations) != 0 {
+		t.Errorf("holdOverloadedLocations after the hold time expected: no overloads kept, actual: %+v", lastStat.OverloadedLocations)
+	}
+}
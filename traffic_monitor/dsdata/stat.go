@@ -373,6 +373,14 @@ type LastDSStat struct {
 	Type        map[tc.CacheType]*LastStatsData
 	Total       LastStatsData
 	Available   bool
+	// OverloadedLocations are the cachegroups the delivery service overloaded, which are kept overloaded until a while after they last exceeded their thresholds.
+	OverloadedLocations map[tc.CacheGroupName]LocationOverload
+}
+
+// LocationOverload is the last time a delivery service exceeded its threshold in a location, and why.
+type LocationOverload struct {
+	Why      string
+	Exceeded time.Time
 }
 
 // Copy performs a deep copy of this LastDSStat object.
@@ -384,6 +392,12 @@ func (a LastDSStat) Copy() *LastDSStat {
 		Total:       a.Total,
 		Available:   a.Available,
 	}
+	if a.OverloadedLocations != nil {
+		b.OverloadedLocations = make(map[tc.CacheGroupName]LocationOverload, len(a.OverloadedLocations))
+		for k, v := range a.OverloadedLocations {
+			b.OverloadedLocations[k] = v
+		}
+	}
 	for k, v := range a.CacheGroups {
 		b.CacheGroups[k] = v
 	}
@@ -21,6 +21,7 @@ package health
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -56,6 +57,8 @@ func GetVitals(newResult *cache.Result, prevResult *cache.Result, mc *tc.Traffic
 		return
 	}
 
+	getInterfaceVitals(newResult, prevResult)
+
 	// proc.net.dev -- need to compare to prevSample
 	// value looks like
 	// "bond0:8495786321839 31960528603    0    0    0     0          0   2349716 143283576747316 101104535041    0    0    0     0       0          0"
@@ -148,12 +151,33 @@ func EvalCache(result cache.ResultInfo, resultStats *threadsafe.ResultStatValHis
 	}
 
 	computedStats := cache.ComputedStats()
+	interfaceStats := cache.InterfaceStats(result, serverInfo)
 
 	for stat, threshold := range serverProfile.Parameters.Thresholds {
+		if strings.HasPrefix(stat, DeliveryServiceThresholdPrefix) {
+			continue // delivery service thresholds don't make the cache unavailable; see EvalDeliveryServiceLocations
+		}
+		if iface, ok := cache.InterfaceOfStat(stat); ok && iface == cache.InterfaceWildcard {
+			ifaceStatName := stat[len(cache.InterfaceStatPrefix+cache.InterfaceWildcard):]
+			for _, name := range cache.MonitoredInterfaces(result, serverInfo) {
+				ifaceStat := cache.InterfaceStatPrefix + name + ifaceStatName
+				ifaceStatNum, ok := util.ToNumeric(interfaceStats[ifaceStat])
+				if !ok {
+					continue
+				}
+				if !inThreshold(threshold, ifaceStatNum) {
+					return false, result.UsingIPv4, eventDesc(status, exceedsThresholdMsg(ifaceStat, threshold, ifaceStatNum)), ifaceStat
+				}
+			}
+			continue
+		}
+
 		resultStat := interface{}(nil)
 		if computedStatF, ok := computedStats[stat]; ok {
 			dummyCombinedstate := tc.IsAvailable{} // the only stats which use combinedState are things like isAvailable, which don't make sense to ever be thresholds.
 			resultStat = computedStatF(result, serverInfo, serverProfile, dummyCombinedstate)
+		} else if interfaceStat, ok := interfaceStats[stat]; ok {
+			resultStat = interfaceStat
 		} else {
 			if resultStats == nil {
 				continue
@@ -176,6 +200,21 @@ func EvalCache(result cache.ResultInfo, resultStats *threadsafe.ResultStatValHis
 		}
 	}
 
+	// Each monitored interface with a max bandwidth in Traffic Ops must be within it, regardless of the profile thresholds.
+	for _, iface := range serverInfo.Interfaces {
+		if !iface.Monitor || iface.MaxBandwidth == nil {
+			continue
+		}
+		vitals, ok := result.Vitals.Interfaces[iface.Name]
+		if !ok {
+			continue
+		}
+		if vitals.KbpsOut > int64(*iface.MaxBandwidth) {
+			stat := cache.InterfaceStatPrefix + iface.Name + ".bandwidth"
+			return false, result.UsingIPv4, eventDesc(status, fmt.Sprintf("%s too high (%d > %d max bandwidth)", stat, vitals.KbpsOut, *iface.MaxBandwidth)), stat
+		}
+	}
+
 	return avail, result.UsingIPv4, eventDescVal, eventMsg
 }
 
@@ -271,6 +310,42 @@ func CalcAvailability(results []cache.Result, pollerName string, statResultHisto
 	localCacheStatusThreadsafe.Set(localCacheStatuses)
 }
 
+// getInterfaceVitals sets the vitals of each network interface in the result's proc.net.dev, which has one line per interface.
+// Lines which can't be parsed are skipped, because the cache's total vitals are still usable.
+func getInterfaceVitals(newResult *cache.Result, prevResult *cache.Result) {
+	newResult.Vitals.Interfaces = map[string]cache.InterfaceVitals{}
+	for _, line := range strings.Split(newResult.Astats.System.ProcNetDev, "\n") {
+		colon := strings.Index(line, ":")
+		if colon < 0 {
+			continue
+		}
+		name := strings.TrimSpace(line[:colon])
+		numbers := strings.Fields(line[colon+1:])
+		if name == "" || len(numbers) < 9 {
+			log.Warnf("cache %s interface line '%s' unknown proc.net.dev format", newResult.ID, line)
+			continue
+		}
+		bytesIn, err := strconv.ParseInt(numbers[0], 10, 64)
+		if err != nil {
+			log.Warnf("cache %s interface %s converting BytesIn from procnetdev: %v", newResult.ID, name, err)
+			continue
+		}
+		bytesOut, err := strconv.ParseInt(numbers[8], 10, 64)
+		if err != nil {
+			log.Warnf("cache %s interface %s converting BytesOut from procnetdev: %v", newResult.ID, name, err)
+			continue
+		}
+		vitals := cache.InterfaceVitals{BytesIn: bytesIn, BytesOut: bytesOut}
+		if prevResult != nil {
+			if prev, ok := prevResult.Vitals.Interfaces[name]; ok && prev.BytesOut != 0 {
+				elapsedTimeInSecs := float64(newResult.Time.UnixNano()-prevResult.Time.UnixNano()) / 1000000000
+				vitals.KbpsOut = int64(float64((vitals.BytesOut-prev.BytesOut)*8/1000) / elapsedTimeInSecs)
+			}
+		}
+		newResult.Vitals.Interfaces[name] = vitals
+	}
+}
+
 func setErr(newResult *cache.Result, err error) {
 	newResult.Error = err
 	newResult.Available = false
@@ -326,7 +401,8 @@ func calculateDeliveryServiceState(deliveryServiceServers map[tc.DeliveryService
 			log.Infof("CRConfig does not have delivery service %s, but traffic monitor poller does; skipping\n", deliveryServiceName)
 			continue
 		}
-		deliveryServiceState.DisabledLocations = getDisabledLocations(deliveryServiceName, toData.DeliveryServiceServers[deliveryServiceName], cacheStates, toData.ServerCachegroups)
+		disabledLocations := getDisabledLocations(deliveryServiceName, toData.DeliveryServiceServers[deliveryServiceName], cacheStates, toData.ServerCachegroups)
+		deliveryServiceState.DisabledLocations = AddOverloadedLocations(disabledLocations, deliveryServiceState.OverloadedLocations)
 		states.SetDeliveryService(deliveryServiceName, deliveryServiceState)
 	}
 }
@@ -344,6 +420,115 @@ func getDisabledLocations(deliveryService tc.DeliveryServiceName, deliveryServic
 	return disabledLocations
 }
 
+// AddOverloadedLocations returns the disabled locations with each overloaded location not already in them appended.
+func AddOverloadedLocations(disabledLocations []tc.CacheGroupName, overloadedLocations map[tc.CacheGroupName]string) []tc.CacheGroupName {
+	if len(overloadedLocations) == 0 {
+		return disabledLocations
+	}
+	disabled := map[tc.CacheGroupName]struct{}{}
+	for _, cg := range disabledLocations {
+		disabled[cg] = struct{}{}
+	}
+	overloaded := []tc.CacheGroupName{}
+	for cg := range overloadedLocations {
+		if _, ok := disabled[cg]; !ok {
+			overloaded = append(overloaded, cg)
+		}
+	}
+	sort.Slice(overloaded, func(i, j int) bool { return overloaded[i] < overloaded[j] })
+	locations := make([]tc.CacheGroupName, 0, len(disabledLocations)+len(overloaded))
+	locations = append(locations, disabledLocations...)
+	return append(locations, overloaded...)
+}
+
+// DeliveryServiceThresholdPrefix prefixes cache profile thresholds on the traffic of a single delivery service, which are named "deliveryservice.<xml_id>.kbpsPercent".
+const DeliveryServiceThresholdPrefix = "deliveryservice."
+
+// DeliveryServiceRecoveryRatio is how far below its threshold a delivery service's share of an overloaded location's kbps must fall for the location to no longer be overloaded. Thresholds which are maximums are multiplied by it, so a share hovering around the threshold doesn't flap the location between overloaded and not.
+const DeliveryServiceRecoveryRatio = 0.8
+
+// DeliveryServiceKbpsPercentThreshold returns the name of the cache profile threshold on the given delivery service's percentage of the kbps of the caches in a cachegroup.
+func DeliveryServiceKbpsPercentThreshold(dsName tc.DeliveryServiceName) string {
+	return DeliveryServiceThresholdPrefix + string(dsName) + ".kbpsPercent"
+}
+
+// EvalDeliveryServiceLocations returns the cachegroups overloaded by the given delivery service, with the reason each is overloaded. A cachegroup is overloaded when the delivery service's share of the kbps of the available caches in it, whose profiles have a threshold for the delivery service, exceeds every one of those thresholds. This lets a single delivery service be shed from a cachegroup, without marking its caches unavailable for every other delivery service.
+//
+// The dsKbps is the delivery service's kbps on each cache, and cacheKbps is each cache's total kbps. The overloaded locations are those which were overloaded when last evaluated, which stay overloaded until the share is within their thresholds multiplied by DeliveryServiceRecoveryRatio.
+// Returns nil if no cachegroup is overloaded.
+func EvalDeliveryServiceLocations(dsName tc.DeliveryServiceName, dsKbps map[tc.CacheName]float64, cacheKbps map[tc.CacheName]float64, cacheStates map[tc.CacheName]tc.IsAvailable, serverCachegroups map[tc.CacheName]tc.CacheGroupName, mc *tc.TrafficMonitorConfigMap, overloadedLocations map[tc.CacheGroupName]string) map[tc.CacheGroupName]string {
+	type location struct {
+		dsKbps     float64
+		kbps       float64
+		thresholds []tc.HealthThreshold
+	}
+	stat := DeliveryServiceKbpsPercentThreshold(dsName)
+	locations := map[tc.CacheGroupName]*location{}
+	for cacheName, kbps := range cacheKbps {
+		if !cacheStates[cacheName].IsAvailable {
+			continue
+		}
+		serverInfo, ok := mc.TrafficServer[string(cacheName)]
+		if !ok {
+			continue
+		}
+		threshold, ok := mc.Profile[serverInfo.Profile].Parameters.Thresholds[stat]
+		if !ok {
+			continue
+		}
+		cg, ok := serverCachegroups[cacheName]
+		if !ok {
+			continue
+		}
+		loc, ok := locations[cg]
+		if !ok {
+			loc = &location{}
+			locations[cg] = loc
+		}
+		loc.dsKbps += dsKbps[cacheName]
+		loc.kbps += kbps
+		loc.thresholds = append(loc.thresholds, threshold)
+	}
+
+	overloaded := map[tc.CacheGroupName]string(nil)
+	for cg, loc := range locations {
+		if loc.kbps <= 0 {
+			continue
+		}
+		percent := 100 * loc.dsKbps / loc.kbps
+		_, wasOverloaded := overloadedLocations[cg]
+		// the reason is the largest threshold exceeded, so it doesn't depend on the order of the caches
+		sort.Slice(loc.thresholds, func(i, j int) bool { return loc.thresholds[i].Val < loc.thresholds[j].Val })
+		why := ""
+		for _, threshold := range loc.thresholds {
+			if wasOverloaded {
+				threshold = recoveryThreshold(threshold)
+			}
+			if inThreshold(threshold, percent) {
+				why = ""
+				break
+			}
+			why = exceedsThresholdMsg(stat, threshold, percent)
+		}
+		if why == "" {
+			continue
+		}
+		if overloaded == nil {
+			overloaded = map[tc.CacheGroupName]string{}
+		}
+		overloaded[cg] = why + " in the cachegroup"
+	}
+	return overloaded
+}
+
+// recoveryThreshold returns the threshold an overloaded location must be within to no longer be overloaded. Only maximums are lowered; other thresholds are unchanged.
+func recoveryThreshold(threshold tc.HealthThreshold) tc.HealthThreshold {
+	if threshold.Comparator == "<" || threshold.Comparator == "<=" {
+		threshold.Val *= DeliveryServiceRecoveryRatio
+	}
+	return threshold
+}
+
 func getDeliveryServiceCacheAvailability(cacheStates map[tc.CacheName]tc.IsAvailable, deliveryServiceServers []tc.CacheName) map[tc.CacheName]tc.IsAvailable {
 	dsCacheStates := map[tc.CacheName]tc.IsAvailable{}
 	for _, server := range deliveryServiceServers {
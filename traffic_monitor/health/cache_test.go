@@ -152,3 +152,260 @@ func TestCalcAvailabilityThresholds(t *testing.T) {
 		t.Fatalf("localCacheStatus.Why expected 'availableBandwidthInKbps too low' actual %v", localCacheStatus.Why)
 	}
 }
+
+func TestEvalCacheInterfaceMaxBandwidth(t *testing.T) {
+	prevResult := cache.Result{
+		ID:        "myCacheName",
+		Time:      time.Now().Add(time.Second * -1),
+		UsingIPv4: true,
+		Astats: cache.Astats{
+			Ats: map[string]interface{}{},
+			System: cache.AstatsSystem{
+				ProcNetDev:  "bond0: 1000 10    0    0    0     0          0   0 1000 10    0    0    0     0       0          0\nbond1: 1000 10    0    0    0     0          0   0 1000 10    0    0    0     0       0          0",
+				ProcLoadavg: "0.10 0.05 0.05 1/1000 30000",
+			},
+		},
+		Available: true,
+	}
+	GetVitals(&prevResult, nil, nil)
+
+	result := prevResult
+	result.Time = prevResult.Time.Add(time.Second)
+	// bond0 sends 1000 Kbps, bond1 sends 8000 Kbps
+	result.Astats.System.ProcNetDev = "bond0: 1000 10    0    0    0     0          0   0 126000 10    0    0    0     0       0          0\nbond1: 1000 10    0    0    0     0          0   0 1001000 10    0    0    0     0       0          0"
+	GetVitals(&result, &prevResult, nil)
+
+	if kbps := result.Vitals.Interfaces["bond0"].KbpsOut; kbps != 1000 {
+		t.Errorf("bond0 kbps expected: 1000, actual: %v", kbps)
+	}
+	if kbps := result.Vitals.Interfaces["bond1"].KbpsOut; kbps != 8000 {
+		t.Errorf("bond1 kbps expected: 8000, actual: %v", kbps)
+	}
+
+	maxBandwidth := uint64(5000)
+	mc := tc.TrafficMonitorConfigMap{
+		TrafficServer: map[string]tc.TrafficServer{
+			string(result.ID): {
+				ServerStatus: string(tc.CacheStatusReported),
+				Profile:      "myProfileName",
+				Interfaces: []tc.ServerInterfaceInfo{
+					{Name: "bond0", Monitor: true, MaxBandwidth: &maxBandwidth},
+					{Name: "bond1", Monitor: true, MaxBandwidth: &maxBandwidth},
+				},
+			},
+		},
+		Profile: map[string]tc.TMProfile{"myProfileName": {Name: "myProfileName"}},
+	}
+
+	avail, _, why, stat := EvalCache(cache.ToInfo(result), nil, &mc)
+	if avail {
+		t.Errorf("EvalCache with bond1 over its max bandwidth expected: unavailable, actual: available")
+	}
+	if stat != "interfaces.bond1.bandwidth" {
+		t.Errorf("EvalCache unavailable stat expected: interfaces.bond1.bandwidth, actual: %v", stat)
+	}
+	if !strings.Contains(why, "interfaces.bond1.bandwidth too high") {
+		t.Errorf("EvalCache why expected: 'interfaces.bond1.bandwidth too high', actual: %v", why)
+	}
+	if !result.HasStat(stat) {
+		t.Errorf("Result.HasStat(%v) expected: true, actual: false", stat)
+	}
+
+	// interfaces which aren't monitored aren't checked
+	mc.TrafficServer[string(result.ID)].Interfaces[1].Monitor = false
+	if avail, _, why, _ := EvalCache(cache.ToInfo(result), nil, &mc); !avail {
+		t.Errorf("EvalCache with unmonitored interface over its max bandwidth expected: available, actual: unavailable because %v", why)
+	}
+}
+
+func TestEvalCacheInterfaceWildcardThreshold(t *testing.T) {
+	prevResult := cache.Result{
+		ID:        "myCacheName",
+		Time:      time.Now().Add(time.Second * -1),
+		UsingIPv4: true,
+		Astats: cache.Astats{
+			Ats: map[string]interface{}{},
+			System: cache.AstatsSystem{
+				ProcNetDev:  "bond0: 1000 10    0    0    0     0          0   0 1000 10    0    0    0     0       0          0\nbond1: 1000 10    0    0    0     0          0   0 1000 10    0    0    0     0       0          0",
+				ProcLoadavg: "0.10 0.05 0.05 1/1000 30000",
+			},
+		},
+		Available: true,
+	}
+	GetVitals(&prevResult, nil, nil)
+
+	result := prevResult
+	result.Time = prevResult.Time.Add(time.Second)
+	// bond0 sends 1000 Kbps, bond1 sends 8000 Kbps
+	result.Astats.System.ProcNetDev = "bond0: 1000 10    0    0    0     0          0   0 126000 10    0    0    0     0       0          0\nbond1: 1000 10    0    0    0     0          0   0 1001000 10    0    0    0     0       0          0"
+	GetVitals(&result, &prevResult, nil)
+
+	mc := tc.TrafficMonitorConfigMap{
+		TrafficServer: map[string]tc.TrafficServer{
+			string(result.ID): {
+				ServerStatus: string(tc.CacheStatusReported),
+				Profile:      "myProfileName",
+				Interfaces: []tc.ServerInterfaceInfo{
+					{Name: "bond0", Monitor: true},
+					{Name: "bond1", Monitor: true},
+				},
+			},
+		},
+		Profile: map[string]tc.TMProfile{
+			"myProfileName": {
+				Name: "myProfileName",
+				Parameters: tc.TMParameters{
+					Thresholds: map[string]tc.HealthThreshold{
+						"interfaces.*.bandwidth":       {Val: 5000, Comparator: "<"},
+						"deliveryservice.myDS.kbps":    {Val: 1, Comparator: "<"},
+						"interfaces.*.nonexistentStat": {Val: 1, Comparator: "<"},
+					},
+				},
+			},
+		},
+	}
+
+	avail, _, why, stat := EvalCache(cache.ToInfo(result), nil, &mc)
+	if avail {
+		t.Errorf("EvalCache with bond1 over the wildcard interface threshold expected: unavailable, actual: available")
+	}
+	if stat != "interfaces.bond1.bandwidth" {
+		t.Errorf("EvalCache unavailable stat expected: interfaces.bond1.bandwidth, actual: %v", stat)
+	}
+	if !strings.Contains(why, "interfaces.bond1.bandwidth too high") {
+		t.Errorf("EvalCache why expected: 'interfaces.bond1.bandwidth too high', actual: %v", why)
+	}
+
+	// interfaces which aren't monitored aren't checked, and delivery service thresholds never make the cache unavailable
+	mc.TrafficServer[string(result.ID)].Interfaces[1].Monitor = false
+	if avail, _, why, _ := EvalCache(cache.ToInfo(result), nil, &mc); !avail {
+		t.Errorf("EvalCache with unmonitored interface over the wildcard threshold expected: available, actual: unavailable because %v", why)
+	}
+}
+
+func TestEvalDeliveryServiceLocations(t *testing.T) {
+	const ds = tc.DeliveryServiceName("myDS")
+	mc := tc.TrafficMonitorConfigMap{
+		TrafficServer: map[string]tc.TrafficServer{
+			"edge0": {Profile: "capped"},
+			"edge1": {Profile: "capped"},
+			"edge2": {Profile: "capped"},
+			"edge3": {Profile: "uncapped"},
+			"edge4": {Profile: "capped"},
+		},
+		Profile: map[string]tc.TMProfile{
+			"capped": {Parameters: tc.TMParameters{Thresholds: map[string]tc.HealthThreshold{
+				DeliveryServiceKbpsPercentThreshold(ds): {Val: 25, Comparator: "<"},
+			}}},
+			"uncapped": {Parameters: tc.TMParameters{Thresholds: map[string]tc.HealthThreshold{}}},
+		},
+	}
+	serverCachegroups := map[tc.CacheName]tc.CacheGroupName{
+		"edge0": "cgOverloaded",
+		"edge1": "cgOverloaded",
+		"edge2": "cgPartial",
+		"edge3": "cgPartial",
+		"edge4": "cgPartial",
+	}
+	cacheStates := map[tc.CacheName]tc.IsAvailable{
+		"edge0": {IsAvailable: true},
+		"edge1": {IsAvailable: true},
+		"edge2": {IsAvailable: true},
+		"edge3": {IsAvailable: true},
+		"edge4": {IsAvailable: true},
+	}
+	cacheKbps := map[tc.CacheName]float64{
+		"edge0": 4000,
+		"edge1": 4000,
+		"edge2": 4000,
+		"edge3": 4000,
+		"edge4": 4000,
+	}
+	dsKbps := map[tc.CacheName]float64{
+		"edge0": 3000, // a high kbps, but a low share, isn't enough alone
+		"edge1": 0,
+		"edge2": 1200,
+		"edge3": 4000, // no threshold, so never counts
+		"edge4": 0,
+	}
+
+	overloaded := EvalDeliveryServiceLocations(ds, dsKbps, cacheKbps, cacheStates, serverCachegroups, &mc, nil)
+	if len(overloaded) != 1 {
+		t.Fatalf("EvalDeliveryServiceLocations expected: 1 overloaded location, actual: %+v", overloaded)
+	}
+	why, ok := overloaded["cgOverloaded"]
+	if !ok {
+		t.Fatalf("EvalDeliveryServiceLocations expected: cgOverloaded overloaded, actual: %+v", overloaded)
+	}
+	if expected := "deliveryservice.myDS.kbpsPercent too high (37.50 > 25.00)"; !strings.Contains(why, expected) {
+		t.Errorf("EvalDeliveryServiceLocations reason expected: to contain '%v', actual: '%v'", expected, why)
+	}
+
+	// unavailable caches don't count towards a cachegroup's kbps
+	cacheStates["edge4"] = tc.IsAvailable{IsAvailable: false}
+	overloaded = EvalDeliveryServiceLocations(ds, dsKbps, cacheKbps, cacheStates, serverCachegroups, &mc, nil)
+	if _, ok := overloaded["cgPartial"]; !ok {
+		t.Errorf("EvalDeliveryServiceLocations with the only capped cache without the delivery service unavailable expected: cgPartial overloaded, actual: %+v", overloaded)
+	}
+
+	if overloaded := EvalDeliveryServiceLocations("otherDS", dsKbps, cacheKbps, cacheStates, serverCachegroups, &mc, nil); overloaded != nil {
+		t.Errorf("EvalDeliveryServiceLocations for a delivery service with no thresholds expected: nil, actual: %+v", overloaded)
+	}
+}
+
+func TestEvalDeliveryServiceLocationsRecovery(t *testing.T) {
+	const ds = tc.DeliveryServiceName("myDS")
+	mc := tc.TrafficMonitorConfigMap{
+		TrafficServer: map[string]tc.TrafficServer{"edge0": {Profile: "capped"}},
+		Profile: map[string]tc.TMProfile{
+			"capped": {Parameters: tc.TMParameters{Thresholds: map[string]tc.HealthThreshold{
+				DeliveryServiceKbpsPercentThreshold(ds): {Val: 50, Comparator: "<"},
+			}}},
+		},
+	}
+	serverCachegroups := map[tc.CacheName]tc.CacheGroupName{"edge0": "cg0"}
+	cacheStates := map[tc.CacheName]tc.IsAvailable{"edge0": {IsAvailable: true}}
+	cacheKbps := map[tc.CacheName]float64{"edge0": 1000}
+
+	// the share hovers around the threshold, but only overloads the location once, and only recovers once it's below the threshold times the recovery ratio
+	overloaded := map[tc.CacheGroupName]string(nil)
+	for i, test := range []struct {
+		dsKbps     float64
+		overloaded bool
+	}{
+		{dsKbps: 490, overloaded: false},
+		{dsKbps: 510, overloaded: true},
+		{dsKbps: 490, overloaded: true},
+		{dsKbps: 420, overloaded: true},
+		{dsKbps: 510, overloaded: true},
+		{dsKbps: 390, overloaded: false},
+		{dsKbps: 490, overloaded: false},
+	} {
+		overloaded = EvalDeliveryServiceLocations(ds, map[tc.CacheName]float64{"edge0": test.dsKbps}, cacheKbps, cacheStates, serverCachegroups, &mc, overloaded)
+		if _, ok := overloaded["cg0"]; ok != test.overloaded {
+			t.Errorf("EvalDeliveryServiceLocations poll %d with %v%% of the kbps expected: overloaded %v, actual: %v", i, test.dsKbps/10, test.overloaded, ok)
+		}
+	}
+}
+
+func TestAddOverloadedLocations(t *testing.T) {
+	disabled := []tc.CacheGroupName{"cg0"}
+	if locations := AddOverloadedLocations(disabled, nil); len(locations) != 1 || locations[0] != "cg0" {
+		t.Errorf("AddOverloadedLocations with no overloaded locations expected: [cg0], actual: %v", locations)
+	}
+
+	locations := AddOverloadedLocations(disabled, map[tc.CacheGroupName]string{"cg0": "overloaded", "cg2": "overloaded", "cg1": "overloaded"})
+	expected := []tc.CacheGroupName{"cg0", "cg1", "cg2"}
+	if len(locations) != len(expected) {
+		t.Fatalf("AddOverloadedLocations expected: %v, actual: %v", expected, locations)
+	}
+	for i, cg := range expected {
+		if locations[i] != cg {
+			t.Errorf("AddOverloadedLocations expected: %v, actual: %v", expected, locations)
+			break
+		}
+	}
+	if len(disabled) != 1 {
+		t.Errorf("AddOverloadedLocations expected: not to modify the disabled locations, actual: %v", disabled)
+	}
+}
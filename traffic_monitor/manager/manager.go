@@ -35,6 +35,7 @@ import (
 	"github.com/apache/trafficcontrol/traffic_monitor/health"
 	"github.com/apache/trafficcontrol/traffic_monitor/peer"
 	"github.com/apache/trafficcontrol/traffic_monitor/poller"
+	"github.com/apache/trafficcontrol/traffic_monitor/router"
 	"github.com/apache/trafficcontrol/traffic_monitor/threadsafe"
 	"github.com/apache/trafficcontrol/traffic_monitor/todata"
 	"github.com/apache/trafficcontrol/traffic_monitor/towrap"
@@ -66,6 +67,17 @@ func Start(opsConfigFile string, cfg config.Config, appData config.StaticAppData
 	go cacheStatPoller.Poll()
 	go peerPoller.Poll()
 
+	// Traffic Router probing is optional; with a nil subscriber, the monitor config manager doesn't send router probe URLs.
+	routerStats := router.NewStatsThreadsafe(cfg.MaxHealthHistory)
+	var routerHandler router.Handler
+	var routerURLSubscriber chan<- poller.CachePollerConfig
+	if cfg.RouterProbeInterval > 0 {
+		routerHandler = router.NewHandler()
+		routerPoller := poller.NewCache(cfg.RouterProbeInterval, false, routerHandler, cfg, appData, cfg.CachePollingProtocol)
+		routerURLSubscriber = routerPoller.ConfigChannel
+		go routerPoller.Poll()
+	}
+
 	events := health.NewThreadsafeEvents(cfg.MaxEvents)
 
 	cachesChanged := make(chan struct{})
@@ -78,6 +90,8 @@ func Start(opsConfigFile string, cfg config.Config, appData config.StaticAppData
 		cacheStatPoller.ConfigChannel,
 		cacheHealthPoller.ConfigChannel,
 		peerPoller.ConfigChannel,
+		routerURLSubscriber,
+		routerStats,
 		monitorConfigPoller.IntervalChan,
 		cachesChanged,
 		cfg,
@@ -86,7 +100,7 @@ func Start(opsConfigFile string, cfg config.Config, appData config.StaticAppData
 		toData,
 	)
 
-	combinedStates, combineStateFunc := StartStateCombiner(events, peerStates, localStates, toData)
+	combinedStates, combineStateFunc := StartStateCombiner(events, peerStates, localStates, toData, monitorConfig, cfg, appData)
 
 	StartPeerManager(
 		peerHandler.ResultChannel,
@@ -95,6 +109,16 @@ func Start(opsConfigFile string, cfg config.Config, appData config.StaticAppData
 		combineStateFunc,
 	)
 
+	if cfg.RouterProbeInterval > 0 {
+		StartRouterManager(
+			routerHandler.ResultChannel,
+			routerStats,
+			localStates,
+			events,
+			combineStateFunc,
+		)
+	}
+
 	statInfoHistory, statResultHistory, statMaxKbpses, _, lastKbpsStats, dsStats, unpolledCaches, localCacheStatus := StartStatHistoryManager(
 		cacheStatHandler.ResultChan(),
 		localStates,
@@ -146,6 +170,7 @@ func Start(opsConfigFile string, cfg config.Config, appData config.StaticAppData
 		localCacheStatus,
 		unpolledCaches,
 		monitorConfig,
+		routerStats,
 		cfg,
 	)
 
@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -33,6 +34,7 @@ import (
 	"github.com/apache/trafficcontrol/traffic_monitor/config"
 	"github.com/apache/trafficcontrol/traffic_monitor/peer"
 	"github.com/apache/trafficcontrol/traffic_monitor/poller"
+	"github.com/apache/trafficcontrol/traffic_monitor/router"
 	"github.com/apache/trafficcontrol/traffic_monitor/threadsafe"
 	"github.com/apache/trafficcontrol/traffic_monitor/todata"
 	"github.com/apache/trafficcontrol/traffic_monitor/towrap"
@@ -125,6 +127,8 @@ func StartMonitorConfigManager(
 	statURLSubscriber chan<- poller.CachePollerConfig,
 	healthURLSubscriber chan<- poller.CachePollerConfig,
 	peerURLSubscriber chan<- poller.CachePollerConfig,
+	routerURLSubscriber chan<- poller.CachePollerConfig,
+	routerStats router.StatsThreadsafe,
 	toIntervalSubscriber chan<- time.Duration,
 	cachesChangeSubscriber chan<- struct{},
 	cfg config.Config,
@@ -140,6 +144,8 @@ func StartMonitorConfigManager(
 		statURLSubscriber,
 		healthURLSubscriber,
 		peerURLSubscriber,
+		routerURLSubscriber,
+		routerStats,
 		toIntervalSubscriber,
 		cachesChangeSubscriber,
 		cfg,
@@ -195,6 +201,8 @@ func monitorConfigListen(
 	statURLSubscriber chan<- poller.CachePollerConfig,
 	healthURLSubscriber chan<- poller.CachePollerConfig,
 	peerURLSubscriber chan<- poller.CachePollerConfig,
+	routerURLSubscriber chan<- poller.CachePollerConfig,
+	routerStats router.StatsThreadsafe,
 	toIntervalSubscriber chan<- time.Duration,
 	cachesChangeSubscriber chan<- struct{},
 	cfg config.Config,
@@ -302,6 +310,9 @@ func monitorConfigListen(
 		statURLSubscriber <- poller.CachePollerConfig{Urls: statURLs, PollingProtocol: cfg.CachePollingProtocol, Interval: intervals.Stat, NoKeepAlive: intervals.StatNoKeepAlive}
 		healthURLSubscriber <- poller.CachePollerConfig{Urls: healthURLs, PollingProtocol: cfg.CachePollingProtocol, Interval: intervals.Health, NoKeepAlive: intervals.HealthNoKeepAlive}
 		peerURLSubscriber <- poller.CachePollerConfig{Urls: peerURLs, PollingProtocol: cfg.PeerPollingProtocol, Interval: intervals.Peer, NoKeepAlive: intervals.PeerNoKeepAlive}
+		if routerURLSubscriber != nil {
+			routerURLSubscriber <- poller.CachePollerConfig{Urls: createRouterProbeURLs(toData.Get(), cfg), PollingProtocol: cfg.CachePollingProtocol, Interval: cfg.RouterProbeInterval}
+		}
 		toIntervalSubscriber <- intervals.TO
 		peerStates.SetTimeout((intervals.Peer + cfg.HTTPTimeout) * 2)
 		peerStates.SetPeers(peerSet)
@@ -313,6 +324,18 @@ func monitorConfigListen(
 			}
 		}
 
+		routerSet := map[tc.TrafficRouterName]struct{}{}
+		for routerName := range toData.Get().Routers {
+			routerSet[routerName] = struct{}{}
+		}
+		for routerName := range localStates.GetRouters() {
+			if _, exists := routerSet[routerName]; !exists {
+				log.Warnf("Removing %s from localStates", routerName)
+				localStates.DeleteRouter(routerName)
+			}
+		}
+		routerStats.Prune(routerSet)
+
 		if len(healthURLs) == 0 {
 			log.Errorf("No REPORTED caches exist in Traffic Ops, nothing to poll.")
 		}
@@ -334,6 +357,53 @@ func monitorConfigListen(
 	}
 }
 
+// createRouterProbeURLs returns the probe configs for every ONLINE or REPORTED Traffic Router in the CRConfig.
+// Every router is probed with the same delivery service FQDN; if no delivery service with an FQDN exists, nothing is probed.
+func createRouterProbeURLs(toData todata.TOData, cfg config.Config) map[string]poller.PollConfig {
+	urls := map[string]poller.PollConfig{}
+	fqdn := routerProbeFQDN(toData, cfg.RouterProbeDeliveryService)
+	if fqdn == "" {
+		log.Warnln("no delivery service FQDN to probe Traffic Routers with, not probing Traffic Routers")
+		return urls
+	}
+	for name, rt := range toData.Routers {
+		if rt.ServerStatus == nil || rt.IP == nil || rt.Port == nil {
+			log.Warnf("Traffic Router '%s' missing status, IP, or port, not probing", name)
+			continue
+		}
+		if status := tc.CacheStatusFromString(string(*rt.ServerStatus)); status != tc.CacheStatusOnline && status != tc.CacheStatusReported {
+			continue
+		}
+		url4 := fmt.Sprintf("http://%s:%d/", *rt.IP, *rt.Port)
+		url6 := ""
+		if rt.IP6 != nil && *rt.IP6 != "" {
+			url6 = fmt.Sprintf("http://[%s]:%d/", ipv6CIDRStrToAddr(*rt.IP6), *rt.Port)
+		}
+		urls[string(name)] = poller.PollConfig{URL: url4, URLv6: url6, Host: fqdn, Timeout: cfg.HTTPTimeout, PollType: poller.PollerTypeRouter}
+	}
+	return urls
+}
+
+// routerProbeFQDN returns the FQDN of the configured probe delivery service, or of the first delivery service by name if none is configured.
+func routerProbeFQDN(toData todata.TOData, probeDS string) string {
+	if probeDS != "" {
+		fqdn, ok := toData.DeliveryServiceFQDNs[tc.DeliveryServiceName(probeDS)]
+		if !ok {
+			log.Warnf("router_probe_delivery_service '%s' not found in CRConfig", probeDS)
+		}
+		return fqdn
+	}
+	dsNames := make([]string, 0, len(toData.DeliveryServiceFQDNs))
+	for ds := range toData.DeliveryServiceFQDNs {
+		dsNames = append(dsNames, string(ds))
+	}
+	if len(dsNames) == 0 {
+		return ""
+	}
+	sort.Strings(dsNames)
+	return toData.DeliveryServiceFQDNs[tc.DeliveryServiceName(dsNames[0])]
+}
+
 // createServerHealthPollURLs takes the template pollingURLStr, and replaces variables with data from srv, and returns the polling URL for srv.
 func createServerHealthPollURLs(pollingURLStr string, srv tc.TrafficServer) (string, string) {
 	pollingURL4Str := ""
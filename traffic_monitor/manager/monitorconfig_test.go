@@ -24,6 +24,9 @@ import (
 	"testing"
 
 	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_monitor/config"
+	"github.com/apache/trafficcontrol/traffic_monitor/poller"
+	"github.com/apache/trafficcontrol/traffic_monitor/todata"
 )
 
 func TestCreateServerHealthPollURL(t *testing.T) {
@@ -133,3 +136,46 @@ func TestCreateServerStatPollURL(t *testing.T) {
 		t.Errorf("for IPv6 expected createServerStatPollURL '" + expectedV6 + "' actual: '" + actualV6 + "'")
 	}
 }
+
+func TestCreateRouterProbeURLs(t *testing.T) {
+	online := tc.CRConfigRouterStatus(tc.CacheStatusOnline)
+	offline := tc.CRConfigRouterStatus(tc.CacheStatusOffline)
+	ip := "192.0.2.1"
+	ip6 := "2001:db8::1/64"
+	port := 80
+
+	toData := todata.New()
+	toData.Routers = map[tc.TrafficRouterName]tc.CRConfigRouter{
+		"tr-online":  {ServerStatus: &online, IP: &ip, IP6: &ip6, Port: &port},
+		"tr-offline": {ServerStatus: &offline, IP: &ip, Port: &port},
+	}
+	toData.DeliveryServiceFQDNs = map[tc.DeliveryServiceName]string{
+		"ds-b": "cdn.ds-b.example.net",
+		"ds-a": "cdn.ds-a.example.net",
+	}
+
+	urls := createRouterProbeURLs(*toData, config.Config{})
+	if len(urls) != 1 {
+		t.Fatalf("expected 1 router probe URL, actual: %+v", urls)
+	}
+	probe := urls["tr-online"]
+	if probe.URL != "http://192.0.2.1:80/" || probe.URLv6 != "http://[2001:db8::1]:80/" {
+		t.Errorf("expected router probe URLs 'http://192.0.2.1:80/' and 'http://[2001:db8::1]:80/', actual: '%v' and '%v'", probe.URL, probe.URLv6)
+	}
+	if probe.Host != "cdn.ds-a.example.net" {
+		t.Errorf("expected router probe of first delivery service FQDN 'cdn.ds-a.example.net', actual: '%v'", probe.Host)
+	}
+	if probe.PollType != poller.PollerTypeRouter {
+		t.Errorf("expected router probe poll type '%v', actual: '%v'", poller.PollerTypeRouter, probe.PollType)
+	}
+
+	urls = createRouterProbeURLs(*toData, config.Config{RouterProbeDeliveryService: "ds-b"})
+	if host := urls["tr-online"].Host; host != "cdn.ds-b.example.net" {
+		t.Errorf("expected router probe of configured delivery service FQDN 'cdn.ds-b.example.net', actual: '%v'", host)
+	}
+
+	urls = createRouterProbeURLs(*toData, config.Config{RouterProbeDeliveryService: "nonexistent"})
+	if len(urls) != 0 {
+		t.Errorf("expected no router probe URLs for a nonexistent delivery service, actual: %+v", urls)
+	}
+}
@@ -37,6 +37,7 @@ import (
 	"github.com/apache/trafficcontrol/traffic_monitor/handler"
 	"github.com/apache/trafficcontrol/traffic_monitor/health"
 	"github.com/apache/trafficcontrol/traffic_monitor/peer"
+	"github.com/apache/trafficcontrol/traffic_monitor/router"
 	"github.com/apache/trafficcontrol/traffic_monitor/srvhttp"
 	"github.com/apache/trafficcontrol/traffic_monitor/threadsafe"
 	"github.com/apache/trafficcontrol/traffic_monitor/todata"
@@ -73,6 +74,7 @@ func StartOpsConfigManager(
 	localCacheStatus threadsafe.CacheAvailableStatus,
 	unpolledCaches threadsafe.UnpolledCaches,
 	monitorConfig threadsafe.TrafficMonitorConfigMap,
+	routerStats router.StatsThreadsafe,
 	cfg config.Config,
 ) (threadsafe.OpsConfig, error) {
 
@@ -130,6 +132,8 @@ func StartOpsConfigManager(
 			lastStats,
 			unpolledCaches,
 			monitorConfig,
+			routerStats,
+			cfg,
 		)
 
 		// If the HTTPS Listener is defined in the traffic_ops.cfg file then it creates the HTTPS endpoint and the corresponding HTTP endpoint as a redirect
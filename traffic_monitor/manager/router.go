@@ -0,0 +1,61 @@
+package manager
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"github.com/apache/trafficcontrol/traffic_monitor/health"
+	"github.com/apache/trafficcontrol/traffic_monitor/peer"
+	"github.com/apache/trafficcontrol/traffic_monitor/router"
+)
+
+// StartRouterManager listens for Traffic Router probe results, and sets the Traffic Routers' probe data and availability.
+func StartRouterManager(
+	routerChan <-chan router.Result,
+	routerStats router.StatsThreadsafe,
+	localStates peer.CRStatesThreadsafe,
+	events health.ThreadsafeEvents,
+	combineState func(),
+) {
+	go func() {
+		for result := range routerChan {
+			old, available, existed := routerStats.Add(result.ID, result.Probe)
+			localStates.SetRouter(result.ID, available)
+			if !existed || old.IsAvailable != available.IsAvailable {
+				events.Add(health.Event{Time: health.Time(result.Probe.Time), Description: routerEventDesc(result.Probe), Name: result.ID.String(), Hostname: result.ID.String(), Type: "TRAFFIC_ROUTER", Available: available.IsAvailable, IPv4Available: available.Ipv4Available, IPv6Available: available.Ipv6Available})
+			}
+			combineState()
+			result.PollFinished <- result.PollID
+		}
+	}()
+}
+
+func routerEventDesc(probe router.Probe) string {
+	if probe.Available() {
+		return "Traffic Router answered DNS and HTTP for " + probe.FQDN
+	}
+	desc := "Traffic Router probe for " + probe.FQDN + " failed:"
+	if probe.DNSError != "" {
+		desc += " DNS: " + probe.DNSError
+	}
+	if probe.HTTPError != "" {
+		desc += " HTTP: " + probe.HTTPError
+	}
+	return desc
+}
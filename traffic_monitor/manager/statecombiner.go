@@ -27,13 +27,15 @@ import (
 
 	"github.com/apache/trafficcontrol/lib/go-log"
 	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_monitor/config"
 	"github.com/apache/trafficcontrol/traffic_monitor/health"
 	"github.com/apache/trafficcontrol/traffic_monitor/peer"
+	"github.com/apache/trafficcontrol/traffic_monitor/threadsafe"
 	"github.com/apache/trafficcontrol/traffic_monitor/todata"
 )
 
 // StartStateCombiner starts the State Combiner goroutine, and returns the threadsafe CombinedStates, and a func to signal to combine states.
-func StartStateCombiner(events health.ThreadsafeEvents, peerStates peer.CRStatesPeersThreadsafe, localStates peer.CRStatesThreadsafe, toData todata.TODataThreadsafe) (peer.CRStatesThreadsafe, func()) {
+func StartStateCombiner(events health.ThreadsafeEvents, peerStates peer.CRStatesPeersThreadsafe, localStates peer.CRStatesThreadsafe, toData todata.TODataThreadsafe, monitorConfig threadsafe.TrafficMonitorConfigMap, cfg config.Config, staticAppData config.StaticAppData) (peer.CRStatesThreadsafe, func()) {
 	combinedStates := peer.NewCRStatesThreadsafe()
 
 	// the chan buffer just reduces the number of goroutines on our infinite buffer hack in combineState(), no real writer will block, since combineState() writes in a goroutine.
@@ -58,7 +60,8 @@ func StartStateCombiner(events health.ThreadsafeEvents, peerStates peer.CRStates
 		overrideMap := map[tc.CacheName]bool{}
 		for range combineStateChan {
 			drain(combineStateChan)
-			combineCrStates(events, true, peerStates, localStates.Get(), combinedStates, overrideMap, toData.Get())
+			consensus := peer.NewConsensus(cfg, peerStates, monitorConfig.Get().TrafficMonitor)
+			combineCrStates(events, true, consensus, tc.TrafficMonitorName(staticAppData.Hostname), peerStates, localStates.Get(), combinedStates, overrideMap, toData.Get())
 		}
 	}()
 
@@ -131,6 +134,55 @@ func combineCacheState(cacheName tc.CacheName, localCacheState tc.IsAvailable, e
 	combinedStates.AddCache(cacheName, tc.IsAvailable{IsAvailable: available, Ipv4Available: ipv4Available, Ipv6Available: ipv6Available})
 }
 
+// combineCacheStateByVote sets the combined state of the given cache to the tally of the votes of this monitor and its available peers, weighed by the consensus. Peers which don't have the cache abstain.
+func combineCacheStateByVote(
+	cacheName tc.CacheName,
+	localCacheState tc.IsAvailable,
+	events health.ThreadsafeEvents,
+	consensus peer.Consensus,
+	localName tc.TrafficMonitorName,
+	availablePeerStates map[tc.TrafficMonitorName]tc.CRStates,
+	peerTimes map[tc.TrafficMonitorName]time.Time,
+	combinedStates peer.CRStatesThreadsafe,
+	overrideMap map[tc.CacheName]bool,
+	toData todata.TOData,
+) {
+	cacheGroup := toData.ServerCachegroups[cacheName]
+	local := peer.Vote{State: localCacheState, Weight: consensus.Weight(localName, consensus.Now, cacheGroup)}
+	votes := make([]peer.Vote, 0, len(availablePeerStates))
+	for peerName, peerCrStates := range availablePeerStates {
+		peerCacheState, ok := peerCrStates.Caches[cacheName]
+		if !ok {
+			continue
+		}
+		votes = append(votes, peer.Vote{State: peerCacheState, Weight: consensus.Weight(peerName, peerTimes[peerName], cacheGroup)})
+	}
+	combined := peer.Tally(local, votes)
+
+	overrideCondition := ""
+	override := overrideMap[cacheName]
+	if combined.IsAvailable != localCacheState.IsAvailable && !override {
+		overrideCondition = fmt.Sprintf("detected; %s by %s peer consensus of %d monitors", availabilityStr(combined.IsAvailable), consensus.Strategy, len(votes)+1)
+		overrideMap[cacheName] = true
+	} else if combined.IsAvailable == localCacheState.IsAvailable && override {
+		overrideCondition = "cleared; peer consensus agrees with local state"
+		overrideMap[cacheName] = false
+	}
+
+	if overrideCondition != "" {
+		events.Add(health.Event{Time: health.Time(time.Now()), Description: fmt.Sprintf("Health protocol override condition %s", overrideCondition), Name: cacheName.String(), Hostname: cacheName.String(), Type: toData.ServerTypes[cacheName].String(), Available: combined.IsAvailable, IPv4Available: combined.Ipv4Available, IPv6Available: combined.Ipv6Available})
+	}
+
+	combinedStates.AddCache(cacheName, combined)
+}
+
+func availabilityStr(available bool) string {
+	if available {
+		return health.AvailableStr
+	}
+	return health.UnavailableStr
+}
+
 func combineDSState(
 	deliveryServiceName tc.DeliveryServiceName,
 	localDeliveryService tc.CRStatesDeliveryService,
@@ -159,9 +211,27 @@ func combineDSState(
 		}
 		deliveryService.DisabledLocations = intersection(deliveryService.DisabledLocations, peerDeliveryService.DisabledLocations)
 	}
+	deliveryService.OverloadedLocations = combineOverloadedLocations(deliveryService.DisabledLocations, localDeliveryService.OverloadedLocations)
 	combinedStates.SetDeliveryService(deliveryServiceName, deliveryService)
 }
 
+// combineOverloadedLocations returns the local overloaded locations which are still disabled after combining with peers, so a location's overload reason is only published while the location is disabled.
+func combineOverloadedLocations(disabledLocations []tc.CacheGroupName, localOverloadedLocations map[tc.CacheGroupName]string) map[tc.CacheGroupName]string {
+	if len(localOverloadedLocations) == 0 {
+		return nil
+	}
+	overloaded := map[tc.CacheGroupName]string{}
+	for _, cg := range disabledLocations {
+		if why, ok := localOverloadedLocations[cg]; ok {
+			overloaded[cg] = why
+		}
+	}
+	if len(overloaded) == 0 {
+		return nil
+	}
+	return overloaded
+}
+
 // pruneCombinedDSState deletes delivery services in combined states which have been removed from localStates and peerStates
 func pruneCombinedDSState(combinedStates peer.CRStatesThreadsafe, localStates tc.CRStates, peerStates peer.CRStatesPeersThreadsafe) {
 	combinedCRStates := combinedStates.Get()
@@ -197,9 +267,22 @@ func pruneCombinedCaches(combinedStates peer.CRStatesThreadsafe, localStates tc.
 	}
 }
 
-func combineCrStates(events health.ThreadsafeEvents, peerOptimistic bool, peerStates peer.CRStatesPeersThreadsafe, localStates tc.CRStates, combinedStates peer.CRStatesThreadsafe, overrideMap map[tc.CacheName]bool, toData todata.TOData) {
-	for cacheName, localCacheState := range localStates.Caches { // localStates gets pruned when servers are disabled, it's the source of truth
-		combineCacheState(cacheName, localCacheState, events, peerOptimistic, peerStates, localStates, combinedStates, overrideMap, toData)
+func combineCrStates(events health.ThreadsafeEvents, peerOptimistic bool, consensus peer.Consensus, localName tc.TrafficMonitorName, peerStates peer.CRStatesPeersThreadsafe, localStates tc.CRStates, combinedStates peer.CRStatesThreadsafe, overrideMap map[tc.CacheName]bool, toData todata.TOData) {
+	if consensus.Strategy == config.PeerCombiningOptimistic {
+		for cacheName, localCacheState := range localStates.Caches { // localStates gets pruned when servers are disabled, it's the source of truth
+			combineCacheState(cacheName, localCacheState, events, peerOptimistic, peerStates, localStates, combinedStates, overrideMap, toData)
+		}
+	} else {
+		availablePeerStates := map[tc.TrafficMonitorName]tc.CRStates{}
+		for peerName, peerCrStates := range peerStates.GetCrstates() {
+			if peerStates.GetPeerAvailability(peerName) {
+				availablePeerStates[peerName] = peerCrStates
+			}
+		}
+		peerTimes := peerStates.GetQueryTimes()
+		for cacheName, localCacheState := range localStates.Caches {
+			combineCacheStateByVote(cacheName, localCacheState, events, consensus, localName, availablePeerStates, peerTimes, combinedStates, overrideMap, toData)
+		}
 	}
 
 	for deliveryServiceName, localDeliveryService := range localStates.DeliveryService {
@@ -208,6 +291,9 @@ func combineCrStates(events health.ThreadsafeEvents, peerOptimistic bool, peerSt
 
 	pruneCombinedDSState(combinedStates, localStates, peerStates)
 	pruneCombinedCaches(combinedStates, localStates)
+
+	// Traffic Routers are only probed locally, so there's nothing to combine.
+	combinedStates.SetRouters(localStates.Routers)
 }
 
 // CacheNameSlice is a slice of cache names, which fulfills the `sort.Interface` interface.
@@ -0,0 +1,108 @@
+package peer
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_monitor/config"
+)
+
+// Consensus weighs the votes of this monitor and its peers on the availability of caches, according to the configured peer combining strategy.
+type Consensus struct {
+	Strategy       config.PeerCombiningStrategy
+	LocationWeight float64
+	// Timeout is the time since a peer was last polled after which it's unavailable. Votes are weighted by the time remaining, with the weighted strategy.
+	Timeout time.Duration
+	// Locations are the cachegroups of this monitor and its peers.
+	Locations map[tc.TrafficMonitorName]tc.CacheGroupName
+	Now       time.Time
+}
+
+// NewConsensus returns a Consensus for the given config, peers, and Traffic Monitors from the monitoring config.
+func NewConsensus(cfg config.Config, peerStates CRStatesPeersThreadsafe, monitors map[string]tc.TrafficMonitor) Consensus {
+	locations := make(map[tc.TrafficMonitorName]tc.CacheGroupName, len(monitors))
+	for name, monitor := range monitors {
+		locations[tc.TrafficMonitorName(name)] = tc.CacheGroupName(monitor.Location)
+	}
+	return Consensus{
+		Strategy:       cfg.PeerCombiningStrategy,
+		LocationWeight: cfg.PeerLocationWeight,
+		Timeout:        peerStates.GetTimeout(),
+		Locations:      locations,
+		Now:            time.Now(),
+	}
+}
+
+// Weight returns the weight of the given monitor's vote on a cache in the given cachegroup, where the monitor was last polled at the given time. This monitor's own vote should use the current time.
+// With the optimistic strategy, votes aren't weighed, and the weight is always 1.
+func (c Consensus) Weight(monitor tc.TrafficMonitorName, polled time.Time, cacheGroup tc.CacheGroupName) float64 {
+	switch c.Strategy {
+	case config.PeerCombiningWeighted:
+		if c.Timeout <= 0 {
+			return 1
+		}
+		weight := 1 - float64(c.Now.Sub(polled))/float64(c.Timeout)
+		if weight < 0 {
+			return 0
+		}
+		if weight > 1 {
+			return 1
+		}
+		return weight
+	case config.PeerCombiningLocation:
+		if location, ok := c.Locations[monitor]; ok && location != "" && location == cacheGroup {
+			return c.LocationWeight
+		}
+		return 1
+	default:
+		return 1
+	}
+}
+
+// Vote is a monitor's vote on the availability of a cache.
+type Vote struct {
+	State  tc.IsAvailable
+	Weight float64
+}
+
+// Tally returns the availability of a cache which the weight of the votes for is greater than the weight of the votes against. Ties are broken by the local state, which should not also be in the votes.
+func Tally(local Vote, votes []Vote) tc.IsAvailable {
+	tally := func(get func(tc.IsAvailable) bool) bool {
+		weight := 0.0 // the weight for, minus the weight against
+		for _, vote := range append([]Vote{local}, votes...) {
+			if get(vote.State) {
+				weight += vote.Weight
+			} else {
+				weight -= vote.Weight
+			}
+		}
+		if weight == 0 {
+			return get(local.State)
+		}
+		return weight > 0
+	}
+	return tc.IsAvailable{
+		IsAvailable:   tally(func(a tc.IsAvailable) bool { return a.IsAvailable }),
+		Ipv4Available: tally(func(a tc.IsAvailable) bool { return a.Ipv4Available }),
+		Ipv6Available: tally(func(a tc.IsAvailable) bool { return a.Ipv6Available }),
+	}
+}
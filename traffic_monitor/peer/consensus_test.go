@@ -0,0 +1,100 @@
+package peer
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_monitor/config"
+)
+
+func TestConsensusWeight(t *testing.T) {
+	now := time.Now()
+	consensus := Consensus{
+		Strategy:       config.PeerCombiningWeighted,
+		LocationWeight: 3,
+		Timeout:        10 * time.Second,
+		Locations:      map[tc.TrafficMonitorName]tc.CacheGroupName{"tm0": "cg0", "tm1": "cg1"},
+		Now:            now,
+	}
+
+	if weight := consensus.Weight("tm0", now, "cg0"); weight != 1 {
+		t.Errorf("weighted Weight of a vote polled now expected: 1, actual: %v", weight)
+	}
+	if weight := consensus.Weight("tm0", now.Add(-5*time.Second), "cg0"); weight != 0.5 {
+		t.Errorf("weighted Weight of a vote polled half the timeout ago expected: 0.5, actual: %v", weight)
+	}
+	if weight := consensus.Weight("tm0", now.Add(-time.Minute), "cg0"); weight != 0 {
+		t.Errorf("weighted Weight of a vote polled before the timeout expected: 0, actual: %v", weight)
+	}
+
+	consensus.Strategy = config.PeerCombiningLocation
+	if weight := consensus.Weight("tm0", now.Add(-time.Minute), "cg0"); weight != 3 {
+		t.Errorf("location Weight of a vote from the cache's cachegroup expected: 3, actual: %v", weight)
+	}
+	if weight := consensus.Weight("tm1", now, "cg0"); weight != 1 {
+		t.Errorf("location Weight of a vote from another cachegroup expected: 1, actual: %v", weight)
+	}
+	if weight := consensus.Weight("tm2", now, ""); weight != 1 {
+		t.Errorf("location Weight of a vote from an unknown monitor expected: 1, actual: %v", weight)
+	}
+
+	consensus.Strategy = config.PeerCombiningMajority
+	if weight := consensus.Weight("tm0", now.Add(-time.Minute), "cg0"); weight != 1 {
+		t.Errorf("majority Weight expected: 1, actual: %v", weight)
+	}
+}
+
+func TestTally(t *testing.T) {
+	available := tc.IsAvailable{IsAvailable: true, Ipv4Available: true, Ipv6Available: true}
+	unavailable := tc.IsAvailable{}
+
+	// a majority overrules the local state
+	combined := Tally(Vote{State: available, Weight: 1}, []Vote{{State: unavailable, Weight: 1}, {State: unavailable, Weight: 1}})
+	if combined.IsAvailable || combined.Ipv4Available || combined.Ipv6Available {
+		t.Errorf("Tally of 1 available and 2 unavailable votes expected: unavailable, actual: %+v", combined)
+	}
+
+	// ties are broken by the local state
+	combined = Tally(Vote{State: unavailable, Weight: 1}, []Vote{{State: available, Weight: 1}})
+	if combined.IsAvailable {
+		t.Errorf("Tally of a tie with the local state unavailable expected: unavailable, actual: %+v", combined)
+	}
+
+	// weights outweigh numbers
+	combined = Tally(Vote{State: available, Weight: 3}, []Vote{{State: unavailable, Weight: 1}, {State: unavailable, Weight: 1}})
+	if !combined.IsAvailable {
+		t.Errorf("Tally of an available vote weighing 3 and 2 unavailable votes weighing 1 expected: available, actual: %+v", combined)
+	}
+
+	// each protocol is tallied separately
+	ipv4Only := tc.IsAvailable{IsAvailable: true, Ipv4Available: true}
+	combined = Tally(Vote{State: ipv4Only, Weight: 1}, []Vote{{State: available, Weight: 1}, {State: ipv4Only, Weight: 1}})
+	if !combined.IsAvailable || !combined.Ipv4Available || combined.Ipv6Available {
+		t.Errorf("Tally of 2 IPv4-only and 1 dual-stack votes expected: available over IPv4 only, actual: %+v", combined)
+	}
+
+	// with no peers, the local state is used
+	if combined = Tally(Vote{State: available, Weight: 1}, nil); combined != available {
+		t.Errorf("Tally with no peers expected: %+v, actual: %+v", available, combined)
+	}
+}
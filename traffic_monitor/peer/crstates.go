@@ -114,6 +114,37 @@ func (t *CRStatesThreadsafe) DeleteDeliveryService(name tc.DeliveryServiceName)
 	t.m.Unlock()
 }
 
+// GetRouters returns the Traffic Router availability data. This does not mutate, and is thus safe for multiple goroutines to call.
+func (t *CRStatesThreadsafe) GetRouters() map[tc.TrafficRouterName]tc.IsAvailable {
+	t.m.RLock()
+	defer t.m.RUnlock()
+	return t.crStates.CopyRouters()
+}
+
+// SetRouter sets the availability data for the given Traffic Router.
+func (t *CRStatesThreadsafe) SetRouter(name tc.TrafficRouterName, available tc.IsAvailable) {
+	t.m.Lock()
+	if t.crStates.Routers == nil {
+		t.crStates.Routers = map[tc.TrafficRouterName]tc.IsAvailable{}
+	}
+	t.crStates.Routers[name] = available
+	t.m.Unlock()
+}
+
+// SetRouters replaces the availability data of all Traffic Routers. A nil map removes the Traffic Routers from the CRStates.
+func (t *CRStatesThreadsafe) SetRouters(routers map[tc.TrafficRouterName]tc.IsAvailable) {
+	t.m.Lock()
+	t.crStates.Routers = routers
+	t.m.Unlock()
+}
+
+// DeleteRouter deletes the given Traffic Router from the internal data.
+func (t *CRStatesThreadsafe) DeleteRouter(name tc.TrafficRouterName) {
+	t.m.Lock()
+	delete(t.crStates.Routers, name)
+	t.m.Unlock()
+}
+
 // CRStatesPeersThreadsafe provides safe access for multiple goroutines to read a map of Traffic Monitor peers to their returned Crstates, with a single goroutine writer.
 // This could be made lock-free, if the performance was necessary
 type CRStatesPeersThreadsafe struct {
@@ -149,6 +180,13 @@ func (t *CRStatesPeersThreadsafe) SetTimeout(timeout time.Duration) {
 	*t.timeout = timeout
 }
 
+// GetTimeout returns the time since a peer was last polled, after which it's considered unavailable.
+func (t *CRStatesPeersThreadsafe) GetTimeout() time.Duration {
+	t.m.RLock()
+	defer t.m.RUnlock()
+	return *t.timeout
+}
+
 func (t *CRStatesPeersThreadsafe) SetPeers(newPeers map[tc.TrafficMonitorName]struct{}) {
 	t.m.Lock()
 	defer t.m.Unlock()
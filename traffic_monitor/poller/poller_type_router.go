@@ -0,0 +1,79 @@
+package poller
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/apache/trafficcontrol/traffic_monitor/config"
+	"github.com/apache/trafficcontrol/traffic_monitor/router"
+
+	"github.com/json-iterator/go"
+)
+
+// PollerTypeRouter probes Traffic Routers with a DNS query and an HTTP request for the poll host, which should be a delivery service FQDN. It returns the JSON of the router.Probe, and never an error, since failures are part of the probe.
+const PollerTypeRouter = "router"
+
+func init() {
+	AddPollerType(PollerTypeRouter, routerGlobalInit, routerInit, routerPoll)
+}
+
+// RouterPollCtx is the context of a Traffic Router prober.
+type RouterPollCtx struct {
+	Client    *http.Client
+	Timeout   time.Duration
+	UserAgent string
+}
+
+func routerGlobalInit(cfg config.Config, appData config.StaticAppData) interface{} {
+	return &RouterPollCtx{Timeout: cfg.HTTPTimeout, UserAgent: appData.UserAgent}
+}
+
+func routerInit(cfg PollerConfig, globalCtxI interface{}) interface{} {
+	gctx := (globalCtxI).(*RouterPollCtx)
+	timeout := gctx.Timeout
+	if cfg.Timeout != 0 {
+		timeout = cfg.Timeout
+	}
+	return &RouterPollCtx{
+		Client: &http.Client{
+			Timeout:   timeout,
+			Transport: &http.Transport{DisableKeepAlives: cfg.NoKeepAlive},
+			// Traffic Router redirects HTTP delivery service requests, which is a success.
+			CheckRedirect: func(req *http.Request, via []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		Timeout:   timeout,
+		UserAgent: gctx.UserAgent,
+	}
+}
+
+func routerPoll(ctxI interface{}, url string, host string, pollID uint64) ([]byte, time.Time, time.Duration, error) {
+	ctx := (ctxI).(*RouterPollCtx)
+	start := time.Now()
+	probe := router.DoProbe(ctx.Client, ctx.Timeout, url, host, ctx.UserAgent)
+	json := jsoniter.ConfigFastest
+	bts, err := json.Marshal(probe)
+	if err != nil {
+		return nil, time.Now(), time.Since(start), errors.New("marshalling router probe: " + err.Error())
+	}
+	return bts, time.Now(), time.Since(start), nil
+}
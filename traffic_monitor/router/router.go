@@ -0,0 +1,155 @@
+package router
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+
+	"github.com/json-iterator/go"
+)
+
+// DNSPort is the port Traffic Routers are probed with DNS queries on.
+const DNSPort = 53
+
+// Probe is the result of probing a Traffic Router with a DNS query and an HTTP request for a delivery service's name.
+type Probe struct {
+	Time          time.Time `json:"time"`
+	UsingIPv4     bool      `json:"usingIPv4"`
+	FQDN          string    `json:"fqdn"`
+	DNSLatencyMs  float64   `json:"dnsLatencyMs"`
+	DNSAddresses  []string  `json:"dnsAddresses,omitempty"`
+	DNSError      string    `json:"dnsError,omitempty"`
+	HTTPLatencyMs float64   `json:"httpLatencyMs"`
+	HTTPStatus    int       `json:"httpStatus,omitempty"`
+	HTTPError     string    `json:"httpError,omitempty"`
+}
+
+// Available returns whether the Traffic Router answered both the DNS query and the HTTP request.
+func (p Probe) Available() bool {
+	return p.DNSError == "" && p.HTTPError == ""
+}
+
+// DoProbe queries the Traffic Router at the given URL for the given FQDN, over DNS on DNSPort of the URL's host, and over HTTP at the URL. The HTTP client should not follow redirects, since Traffic Router answers HTTP delivery service requests with one.
+// A DNS query which returns no addresses, or an HTTP response with a server error status, is a failure.
+func DoProbe(client *http.Client, timeout time.Duration, probeURL string, fqdn string, userAgent string) Probe {
+	probe := Probe{Time: time.Now(), FQDN: fqdn}
+	u, err := url.Parse(probeURL)
+	if err != nil {
+		probe.DNSError = "parsing URL: " + err.Error()
+		probe.HTTPError = probe.DNSError
+		return probe
+	}
+	ip := net.ParseIP(u.Hostname())
+	probe.UsingIPv4 = ip == nil || ip.To4() != nil
+
+	dnsAddr := net.JoinHostPort(u.Hostname(), strconv.Itoa(DNSPort))
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			dialer := net.Dialer{}
+			return dialer.DialContext(ctx, network, dnsAddr)
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	start := time.Now()
+	addrs, err := resolver.LookupHost(ctx, fqdn)
+	probe.DNSLatencyMs = msSince(start)
+	if err != nil {
+		probe.DNSError = err.Error()
+	} else if len(addrs) == 0 {
+		probe.DNSError = "no addresses returned"
+	} else {
+		probe.DNSAddresses = addrs
+	}
+
+	req, err := http.NewRequest(http.MethodGet, probeURL, nil)
+	if err != nil {
+		probe.HTTPError = "creating HTTP request: " + err.Error()
+		return probe
+	}
+	req.Host = fqdn
+	req.Header.Set("User-Agent", userAgent)
+	start = time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		probe.HTTPLatencyMs = msSince(start)
+		probe.HTTPError = err.Error()
+		return probe
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+	probe.HTTPLatencyMs = msSince(start)
+	probe.HTTPStatus = resp.StatusCode
+	if resp.StatusCode >= http.StatusInternalServerError {
+		probe.HTTPError = "bad HTTP status: " + strconv.Itoa(resp.StatusCode)
+	}
+	return probe
+}
+
+func msSince(t time.Time) float64 {
+	return float64(time.Since(t)) / float64(time.Millisecond)
+}
+
+// Handler handles Traffic Router probes, parsing the data and passing a result object to the ResultChannel. This fulfills the common `Handler` interface.
+type Handler struct {
+	ResultChannel chan Result
+}
+
+// NewHandler returns a new Traffic Router probe Handler.
+func NewHandler() Handler {
+	return Handler{ResultChannel: make(chan Result)}
+}
+
+// Result contains the data from probing a Traffic Router.
+type Result struct {
+	ID           tc.TrafficRouterName
+	Probe        Probe
+	PollID       uint64
+	PollFinished chan<- uint64
+}
+
+// Handle handles a probe of a Traffic Router, parsing the data and forwarding it to the ResultChannel.
+func (handler Handler) Handle(id string, r io.Reader, format string, reqTime time.Duration, reqEnd time.Time, err error, pollID uint64, usingIPv4 bool, pollFinished chan<- uint64) {
+	result := Result{
+		ID:           tc.TrafficRouterName(id),
+		Probe:        Probe{Time: reqEnd, UsingIPv4: usingIPv4},
+		PollID:       pollID,
+		PollFinished: pollFinished,
+	}
+	if err == nil && r != nil {
+		json := jsoniter.ConfigFastest
+		err = json.NewDecoder(r).Decode(&result.Probe)
+	}
+	if err != nil {
+		result.Probe.DNSError = err.Error()
+		result.Probe.HTTPError = err.Error()
+	}
+	handler.ResultChannel <- result
+}
@@ -0,0 +1,106 @@
+package router
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"sync"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+// Stats is the probe data of a Traffic Router.
+type Stats struct {
+	tc.IsAvailable
+	Probes       uint64 `json:"probes"`
+	DNSFailures  uint64 `json:"dnsFailures"`
+	HTTPFailures uint64 `json:"httpFailures"`
+	// History is the most recent probes, newest first.
+	History []Probe `json:"history"`
+}
+
+// StatsThreadsafe provides safe access for multiple goroutines to read the probe data of each Traffic Router, with a single goroutine writer.
+type StatsThreadsafe struct {
+	stats      map[tc.TrafficRouterName]Stats
+	maxHistory int
+	m          *sync.RWMutex
+}
+
+// NewStatsThreadsafe returns a new StatsThreadsafe, which keeps the given number of probes of each Traffic Router.
+func NewStatsThreadsafe(maxHistory uint64) StatsThreadsafe {
+	if maxHistory < 1 {
+		maxHistory = 1
+	}
+	return StatsThreadsafe{stats: map[tc.TrafficRouterName]Stats{}, maxHistory: int(maxHistory), m: &sync.RWMutex{}}
+}
+
+// Get returns a copy of the probe data of every Traffic Router.
+func (s StatsThreadsafe) Get() map[tc.TrafficRouterName]Stats {
+	s.m.RLock()
+	defer s.m.RUnlock()
+	stats := make(map[tc.TrafficRouterName]Stats, len(s.stats))
+	for name, stat := range s.stats {
+		stat.History = append([]Probe(nil), stat.History...)
+		stats[name] = stat
+	}
+	return stats
+}
+
+// Add adds the given probe of the given Traffic Router, and returns its availability before and after the probe, and whether it had been probed before. The IPv4 or IPv6 availability is set from the probe, and the Traffic Router is available if either is.
+func (s StatsThreadsafe) Add(name tc.TrafficRouterName, probe Probe) (tc.IsAvailable, tc.IsAvailable, bool) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	stat, existed := s.stats[name]
+	old := stat.IsAvailable
+
+	stat.Probes++
+	if probe.DNSError != "" {
+		stat.DNSFailures++
+	}
+	if probe.HTTPError != "" {
+		stat.HTTPFailures++
+	}
+	if probe.UsingIPv4 {
+		stat.Ipv4Available = probe.Available()
+	} else {
+		stat.Ipv6Available = probe.Available()
+	}
+	stat.IsAvailable.IsAvailable = stat.Ipv4Available || stat.Ipv6Available
+
+	history := make([]Probe, 0, s.maxHistory)
+	history = append(history, probe)
+	for i := 0; i < len(stat.History) && len(history) < s.maxHistory; i++ {
+		history = append(history, stat.History[i])
+	}
+	stat.History = history
+
+	s.stats[name] = stat
+	return old, stat.IsAvailable, existed
+}
+
+// Prune deletes the probe data of Traffic Routers which aren't in the given set.
+func (s StatsThreadsafe) Prune(routers map[tc.TrafficRouterName]struct{}) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	for name := range s.stats {
+		if _, ok := routers[name]; !ok {
+			delete(s.stats, name)
+		}
+	}
+}
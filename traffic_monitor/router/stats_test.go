@@ -0,0 +1,79 @@
+package router
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+func TestStatsAdd(t *testing.T) {
+	stats := NewStatsThreadsafe(2)
+	name := tc.TrafficRouterName("tr0")
+
+	_, avail, existed := stats.Add(name, Probe{Time: time.Now(), UsingIPv4: true})
+	if existed {
+		t.Errorf("expected first probe to not exist, actual: existed")
+	}
+	if !avail.IsAvailable || !avail.Ipv4Available || avail.Ipv6Available {
+		t.Errorf("expected available on IPv4 only, actual: %+v", avail)
+	}
+
+	old, avail, existed := stats.Add(name, Probe{Time: time.Now(), UsingIPv4: false, DNSError: "timeout"})
+	if !existed || !old.IsAvailable {
+		t.Errorf("expected second probe to exist and previously be available, actual: existed %v old %+v", existed, old)
+	}
+	if !avail.IsAvailable || avail.Ipv6Available {
+		t.Errorf("expected available on IPv4 but not IPv6, actual: %+v", avail)
+	}
+
+	_, avail, _ = stats.Add(name, Probe{Time: time.Now(), UsingIPv4: true, HTTPError: "503 Service Unavailable"})
+	if avail.IsAvailable {
+		t.Errorf("expected unavailable after both IPv4 and IPv6 failed, actual: %+v", avail)
+	}
+
+	stat := stats.Get()[name]
+	if stat.Probes != 3 || stat.DNSFailures != 1 || stat.HTTPFailures != 1 {
+		t.Errorf("expected 3 probes with 1 DNS and 1 HTTP failure, actual: %+v", stat)
+	}
+	if len(stat.History) != 2 {
+		t.Fatalf("expected history capped at 2, actual: %v", len(stat.History))
+	}
+	if stat.History[0].HTTPError == "" || stat.History[1].DNSError == "" {
+		t.Errorf("expected history newest first, actual: %+v", stat.History)
+	}
+}
+
+func TestStatsPrune(t *testing.T) {
+	stats := NewStatsThreadsafe(1)
+	stats.Add("tr0", Probe{UsingIPv4: true})
+	stats.Add("tr1", Probe{UsingIPv4: true})
+	stats.Prune(map[tc.TrafficRouterName]struct{}{"tr1": {}})
+
+	got := stats.Get()
+	if _, ok := got["tr0"]; ok {
+		t.Errorf("expected tr0 pruned, actual: exists")
+	}
+	if _, ok := got["tr1"]; !ok {
+		t.Errorf("expected tr1 not pruned, actual: missing")
+	}
+}
@@ -245,6 +245,13 @@ func StatsMarshall(statResultHistory ResultStatHistory, statInfo cache.ResultInf
 				}
 				stats.Caches[id][stat] = append(stats.Caches[id][stat], cache.ResultStatVal{Val: statValF(resultInfo, serverInfo, serverProfile, combinedStatesCache), Time: t, Span: 1}) // combinedState will default to unavailable
 			}
+
+			for stat, val := range cache.InterfaceStats(resultInfo, serverInfo) {
+				if !filter.UseStat(stat) {
+					continue
+				}
+				stats.Caches[id][stat] = append(stats.Caches[id][stat], cache.ResultStatVal{Val: val, Time: t, Span: 1})
+			}
 		}
 	}
 
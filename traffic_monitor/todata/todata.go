@@ -74,6 +74,9 @@ type TOData struct {
 	DeliveryServiceTypes   map[tc.DeliveryServiceName]tc.DSTypeCategory
 	DeliveryServiceRegexes Regexes
 	ServerCachegroups      map[tc.CacheName]tc.CacheGroupName
+	Routers                map[tc.TrafficRouterName]tc.CRConfigRouter
+	// DeliveryServiceFQDNs are the names Traffic Router answers for each delivery service: its routing name, prepended to its first domain.
+	DeliveryServiceFQDNs map[tc.DeliveryServiceName]string
 }
 
 // New returns a new empty TOData object, initializing pointer members.
@@ -85,6 +88,8 @@ func New() *TOData {
 		DeliveryServiceTypes:   map[tc.DeliveryServiceName]tc.DSTypeCategory{},
 		DeliveryServiceRegexes: NewRegexes(),
 		ServerCachegroups:      map[tc.CacheName]tc.CacheGroupName{},
+		Routers:                map[tc.TrafficRouterName]tc.CRConfigRouter{},
+		DeliveryServiceFQDNs:   map[tc.DeliveryServiceName]string{},
 	}
 }
 
@@ -122,8 +127,11 @@ type CRConfig struct {
 		CacheGroup       string                              `json:"cacheGroup"`
 		Type             string                              `json:"type"`
 	} `json:"contentServers"`
+	ContentRouters   map[tc.TrafficRouterName]tc.CRConfigRouter `json:"contentRouters"`
 	DeliveryServices map[tc.DeliveryServiceName]struct {
-		Matchsets []struct {
+		Domains     []string `json:"domains"`
+		RoutingName *string  `json:"routingName"`
+		Matchsets   []struct {
 			Protocol  string `json:"protocol"`
 			MatchList []struct {
 				Regex string `json:"regex"`
@@ -182,10 +190,28 @@ func (d TODataThreadsafe) Update(to towrap.ITrafficOpsSession, cdn string) error
 		return fmt.Errorf("Error getting server types from Traffic Ops: %v\n", err)
 	}
 
+	newTOData.Routers = crConfig.ContentRouters
+	if newTOData.Routers == nil {
+		newTOData.Routers = map[tc.TrafficRouterName]tc.CRConfigRouter{}
+	}
+	newTOData.DeliveryServiceFQDNs = getDeliveryServiceFQDNs(crConfig)
+
 	d.set(newTOData)
 	return nil
 }
 
+// getDeliveryServiceFQDNs returns the name Traffic Router answers for each delivery service with a routing name and a domain.
+func getDeliveryServiceFQDNs(crc CRConfig) map[tc.DeliveryServiceName]string {
+	fqdns := map[tc.DeliveryServiceName]string{}
+	for ds, dsData := range crc.DeliveryServices {
+		if dsData.RoutingName == nil || *dsData.RoutingName == "" || len(dsData.Domains) == 0 {
+			continue
+		}
+		fqdns[ds] = *dsData.RoutingName + "." + dsData.Domains[0]
+	}
+	return fqdns
+}
+
 // getDeliveryServiceServers gets the servers on each delivery services, for the given CDN, from Traffic Ops.
 func getDeliveryServiceServers(crc CRConfig) (map[tc.DeliveryServiceName][]tc.CacheName, map[tc.CacheName][]tc.DeliveryServiceName, error) {
 	dsServers := map[tc.DeliveryServiceName][]tc.CacheName{}
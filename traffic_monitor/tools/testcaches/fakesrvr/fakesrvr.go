@@ -24,15 +24,19 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/apache/trafficcontrol/traffic_monitor/tools/testcaches/fakesrvrdata"
+	"github.com/apache/trafficcontrol/traffic_monitor/tools/testcaches/fault"
+	"github.com/apache/trafficcontrol/traffic_monitor/tools/testcaches/topology"
 )
 
-func News(portStart int, numPorts int, remaps []string) ([]*http.Server, error) {
+// News starts a fake server for each cache, on consecutive ports from portStart.
+func News(portStart int, caches []topology.Cache, faults *fault.Injector) ([]*http.Server, error) {
 	servers := []*http.Server{}
-	for i := 0; i < numPorts; i++ {
+	for i, cache := range caches {
 		port := portStart + i
-		server, err := New(port, remaps)
+		server, err := New(port, cache, faults)
 		if err != nil {
 			// TODO stop all servers already created?
 			return nil, errors.New("making server on port " + strconv.Itoa(port) + ": " + err.Error())
@@ -42,19 +46,37 @@ func News(portStart int, numPorts int, remaps []string) ([]*http.Server, error)
 	return servers, nil
 }
 
-func New(port int, remaps []string) (*http.Server, error) {
-	serverData, remapIncrements := newData(remaps)
-	fakeServerThs, err := fakesrvrdata.Run(serverData, remapIncrements)
+// New starts a fake server for the cache on the given port, with the faults injected into the cache.
+func New(port int, cache topology.Cache, faults *fault.Injector) (*http.Server, error) {
+	serverData, remapIncrements := newData(cache.InterfaceName, cache.Remaps())
+	fakeServerThs, err := fakesrvrdata.Run(serverData, remapIncrements, faultIncrements(cache, faults))
 	if err != nil {
 		return nil, errors.New("running FakeServer: " + err.Error())
 	}
-	fmt.Println("Starting Serving on port " + strconv.Itoa(port)) // debug
-	srvr := Serve(port, fakeServerThs)
-	fmt.Println("Serving on port " + strconv.Itoa(port)) // debug
+	fmt.Println("Starting Serving " + cache.HostName + " on port " + strconv.Itoa(port)) // debug
+	srvr := Serve(port, cache.HostName, fakeServerThs, faults)
+	fmt.Println("Serving " + cache.HostName + " on port " + strconv.Itoa(port)) // debug
 	return srvr, nil
 }
 
-func newData(remaps []string) (fakesrvrdata.FakeServerData, map[string]fakesrvrdata.BytesPerSec) {
+// faultIncrements returns a func returning the increments of the faults currently active on the cache.
+func faultIncrements(cache topology.Cache, faults *fault.Injector) func() fakesrvrdata.Increments {
+	return func() fakesrvrdata.Increments {
+		effects := faults.Effects(cache.HostName, time.Now())
+		increments := fakesrvrdata.Increments{SndBytes: effects.Kbps * 1000 / 8}
+		for ds, rate := range effects.DS5xx {
+			for _, remap := range cache.DeliveryServices[ds] {
+				if increments.Remaps == nil {
+					increments.Remaps = map[string]fakesrvrdata.FakeRemap{}
+				}
+				increments.Remaps[remap] = fakesrvrdata.FakeRemap{Status5xx: rate}
+			}
+		}
+		return increments
+	}
+}
+
+func newData(interfaceName string, remaps []string) (fakesrvrdata.FakeServerData, map[string]fakesrvrdata.BytesPerSec) {
 	serverDataRemap := map[string]fakesrvrdata.FakeRemap{}
 	for _, remap := range remaps {
 		serverDataRemap[remap] = fakesrvrdata.FakeRemap{}
@@ -65,9 +87,9 @@ func newData(remaps []string) (fakesrvrdata.FakeServerData, map[string]fakesrvrd
 			Remaps: serverDataRemap,
 		},
 		System: fakesrvrdata.FakeSystem{
-			Name:       "bond0",
+			Name:       interfaceName,
 			Speed:      20000,
-			ProcNetDev: fakesrvrdata.FakeProcNetDev{Interface: "bond0"},
+			ProcNetDev: fakesrvrdata.FakeProcNetDev{Interface: interfaceName},
 			ProcLoadAvg: fakesrvrdata.FakeProcLoadAvg{
 				CPU1m:        4.52,
 				CPU5m:        4.64,
@@ -27,19 +27,65 @@ import (
 	"time"
 
 	"github.com/apache/trafficcontrol/traffic_monitor/tools/testcaches/fakesrvrdata"
+	"github.com/apache/trafficcontrol/traffic_monitor/tools/testcaches/fault"
 )
 
 // TODO config?
 const readTimeout = time.Second * 10
 const writeTimeout = time.Second * 10
 
+// timeoutPollInterval is how often a request held by a timeout fault checks whether the fault has ended.
+const timeoutPollInterval = time.Millisecond * 100
+
 func reqIsApplicationSystem(r *http.Request) bool {
 	return r.URL.Query().Get("application") == "system"
 }
 
-func astatsHandler(fakeSrvrDataThs fakesrvrdata.Ths) http.HandlerFunc {
+// waitForTimeout holds the request until no timeout fault is active on the host, or the client gives up. Returns the effects active when it stopped waiting, and false if the client gave up.
+func waitForTimeout(r *http.Request, host string, faults *fault.Injector) (fault.Effects, bool) {
+	ticker := time.NewTicker(timeoutPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return fault.Effects{}, false
+		case <-ticker.C:
+		}
+		if effects := faults.Effects(host, time.Now()); !effects.Timeout {
+			return effects, true
+		}
+	}
+}
+
+// applyEffects modifies the server data per the stat-altering fault effects.
+func applyEffects(srvr *fakesrvrdata.FakeServerData, effects fault.Effects) {
+	if effects.LoadAvg != nil {
+		srvr.System.ProcLoadAvg.CPU1m = *effects.LoadAvg
+	}
+	if effects.InterfaceDown {
+		srvr.System.ProcNetDev = fakesrvrdata.FakeProcNetDev{}
+	}
+}
+
+func astatsHandler(host string, fakeSrvrDataThs fakesrvrdata.Ths, faults *fault.Injector) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		srvr := (*fakesrvrdata.FakeServerData)(fakeSrvrDataThs.Get())
+		effects := faults.Effects(host, time.Now())
+		if effects.Timeout {
+			ok := false
+			if effects, ok = waitForTimeout(r, host, faults); !ok {
+				return
+			}
+		}
+		if effects.Delay > 0 {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(effects.Delay):
+			}
+		}
+
+		srvr := *(*fakesrvrdata.FakeServerData)(fakeSrvrDataThs.Get()) // copy, so effects don't modify the shared data
+		applyEffects(&srvr, effects)
 		// TODO cast to System, if query string `application=system`
 		b := []byte{}
 		err := error(nil)
@@ -52,13 +98,17 @@ func astatsHandler(fakeSrvrDataThs fakesrvrdata.Ths) http.HandlerFunc {
 		if err != nil {
 			w.Write([]byte(`{"error": "marshalling: ` + err.Error() + `"}`)) // TODO escape error for JSON
 		}
+		if effects.MalformedJSON {
+			b = b[:len(b)/2]
+		}
 		w.Write(b)
 	}
 }
 
-func Serve(port int, fakeSrvrData fakesrvrdata.Ths) *http.Server {
+// Serve serves the stats of the fake server with the given host name on the given port, with the faults injected into it.
+func Serve(port int, host string, fakeSrvrData fakesrvrdata.Ths, faults *fault.Injector) *http.Server {
 	mux := http.NewServeMux()
-	mux.HandleFunc("/_astats", astatsHandler(fakeSrvrData))
+	mux.HandleFunc("/_astats", astatsHandler(host, fakeSrvrData, faults))
 	server := &http.Server{
 		Addr:           ":" + strconv.Itoa(port),
 		Handler:        mux,
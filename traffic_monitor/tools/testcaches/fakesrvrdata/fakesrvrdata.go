@@ -167,6 +167,9 @@ type FakeProcNetDev struct {
 }
 
 func (p FakeProcNetDev) MarshalJSON() ([]byte, error) {
+	if p.Interface == "" {
+		return []byte(`""`), nil // no stats at all, as for an interface which doesn't exist
+	}
 	return []byte("\"" + p.Interface + ": " +
 		strconv.FormatUint(p.RcvBytes, 10) + " " +
 		strconv.FormatUint(p.RcvPackets, 10) + " " +
@@ -63,29 +63,39 @@ func runValidate(s *FakeServerData, remapIncrements map[string]BytesPerSec) erro
 	return nil
 }
 
+// Increments are increments per second added on top of a running FakeServerData's regular remap increments, e.g. by injected faults.
+type Increments struct {
+	// SndBytes is added to the interface's sent bytes.
+	SndBytes uint64
+	// Remaps is added to each remap's stats. Remaps not in the FakeServerData are ignored.
+	Remaps map[string]FakeRemap
+}
+
 // Run takes a FakeServerData and a config, and starts running it, incrementing stats per the config. Returns a Threadsafe accessor to the running FakeServerData pointer, whose value may be accessed, but MUST NOT be modified.
+// If extraIncrements is not nil, it's called every tick, and the Increments it returns are added in addition to the remapIncrements.
 // TODO add increments for Rcv,SndPackets, ProcLoadAvg variance, ConfigReloads
-func Run(s FakeServerData, remapIncrements map[string]BytesPerSec) (Ths, error) {
+func Run(s FakeServerData, remapIncrements map[string]BytesPerSec, extraIncrements func() Increments) (Ths, error) {
 	// TODO seed rand? Param?
 	if err := runValidate(&s, remapIncrements); err != nil {
 		return Ths{}, errors.New("invalid configuration: " + err.Error())
 	}
 	ths := NewThs()
 	ths.Set(&s)
-	go run(ths, remapIncrements)
+	go run(ths, remapIncrements, extraIncrements)
 	return ths, nil
 }
 
-// run starts a goroutine incrementing the FakeServerData's values according to the remapIncrements. Never returns.
-func run(srvrThs Ths, remapIncrements map[string]BytesPerSec) {
+// run starts a goroutine incrementing the FakeServerData's values according to the remapIncrements and extraIncrements. Never returns.
+func run(srvrThs Ths, remapIncrements map[string]BytesPerSec, extraIncrements func() Increments) {
 	tickSecs := uint64(1) // adjustable for performance (i.e. a higher number is less CPU work)
 	for {
 		time.Sleep(time.Second * time.Duration(tickSecs))
-		srvr := srvrThs.Get()
+		// copy, rather than modify, the current data, which may be concurrently read
+		srvr := *srvrThs.Get()
 		newRemaps := copyRemaps(srvr.ATS.Remaps)
 		for remap, increments := range remapIncrements {
 			srvrRemap := newRemaps[remap]
-			if increments.Min.InBytes != increments.Min.InBytes {
+			if increments.Min.InBytes != increments.Max.InBytes {
 				i := uint64(rand.Int63n(int64((increments.Max.InBytes-increments.Min.InBytes)*tickSecs))) + (increments.Min.InBytes * tickSecs)
 				srvrRemap.InBytes += i
 				srvr.System.ProcNetDev.RcvBytes += i
@@ -109,7 +119,28 @@ func run(srvrThs Ths, remapIncrements map[string]BytesPerSec) {
 			}
 			newRemaps[remap] = srvrRemap
 		}
+		if extraIncrements != nil {
+			addIncrements(&srvr, newRemaps, extraIncrements(), tickSecs)
+		}
 		srvr.ATS.Remaps = newRemaps
-		srvrThs.Set(srvr)
+		srvrThs.Set(&srvr)
+	}
+}
+
+// addIncrements adds the extra increments for tickSecs seconds to the server's interface stats and the given remaps.
+func addIncrements(srvr *FakeServerData, remaps map[string]FakeRemap, extra Increments, tickSecs uint64) {
+	srvr.System.ProcNetDev.SndBytes += extra.SndBytes * tickSecs
+	for remap, increments := range extra.Remaps {
+		srvrRemap, ok := remaps[remap]
+		if !ok {
+			continue
+		}
+		srvrRemap.InBytes += increments.InBytes * tickSecs
+		srvrRemap.OutBytes += increments.OutBytes * tickSecs
+		srvrRemap.Status2xx += increments.Status2xx * tickSecs
+		srvrRemap.Status3xx += increments.Status3xx * tickSecs
+		srvrRemap.Status4xx += increments.Status4xx * tickSecs
+		srvrRemap.Status5xx += increments.Status5xx * tickSecs
+		remaps[remap] = srvrRemap
 	}
 }
@@ -0,0 +1,295 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+// Package fault schedules faults on fake caches, such as timeouts, slow responses, load and bandwidth spikes, downed interfaces, malformed JSON, and Delivery Service 5xx bursts.
+//
+// Faults may be scheduled from a scenario file when testcaches starts, or added and removed while it runs, via the Handler control API.
+package fault
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Type is the kind of a fault.
+type Type string
+
+const (
+	// TypeTimeout makes the cache never respond, until the fault ends or the client gives up.
+	TypeTimeout = Type("timeout")
+	// TypeSlow makes the cache wait Delay before responding.
+	TypeSlow = Type("slow")
+	// TypeLoadAvg makes the cache report a one-minute load average of LoadAvg.
+	TypeLoadAvg = Type("loadavg")
+	// TypeBandwidth makes the cache's interface send an extra Kbps kilobits per second.
+	TypeBandwidth = Type("bandwidth")
+	// TypeInterfaceDown makes the cache report no stats for its interface.
+	TypeInterfaceDown = Type("interfaceDown")
+	// TypeMalformedJSON makes the cache serve truncated JSON.
+	TypeMalformedJSON = Type("malformedJSON")
+	// TypeDS5xx makes every remap of DeliveryService on the cache serve an extra Rate 5xx responses per second.
+	TypeDS5xx = Type("ds5xx")
+)
+
+// Duration is a time.Duration which is encoded in JSON as a Go duration string, e.g. "1m30s".
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	s := ""
+	if err := json.Unmarshal(b, &s); err != nil {
+		return errors.New("duration must be a string, e.g. \"30s\": " + err.Error())
+	}
+	dur, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(dur)
+	return nil
+}
+
+// Fault is a single fault, to be injected into some or all caches for some time.
+type Fault struct {
+	// ID identifies the fault once it's scheduled. It's ignored in scenario files and control API requests.
+	ID   int  `json:"id"`
+	Type Type `json:"type"`
+	// Servers is the host names of the caches the fault applies to. If it's empty, the fault applies to all caches.
+	Servers []string `json:"servers,omitempty"`
+	// Start is how long after the fault is scheduled it begins.
+	Start Duration `json:"start"`
+	// Duration is how long the fault lasts. If it's 0, the fault lasts until it's removed.
+	Duration Duration `json:"duration"`
+
+	Delay           Duration `json:"delay,omitempty"`
+	LoadAvg         float64  `json:"loadavg,omitempty"`
+	Kbps            uint64   `json:"kbps,omitempty"`
+	DeliveryService string   `json:"deliveryService,omitempty"`
+	Rate            uint64   `json:"rate,omitempty"`
+}
+
+// Validate returns an error if the fault is missing the parameters its Type requires.
+func (f Fault) Validate() error {
+	if f.Start < 0 || f.Duration < 0 {
+		return errors.New("start and duration must not be negative")
+	}
+	switch f.Type {
+	case TypeTimeout, TypeInterfaceDown, TypeMalformedJSON:
+		return nil
+	case TypeSlow:
+		if f.Delay <= 0 {
+			return errors.New("slow faults require a positive delay")
+		}
+	case TypeLoadAvg:
+		if f.LoadAvg <= 0 {
+			return errors.New("loadavg faults require a positive loadavg")
+		}
+	case TypeBandwidth:
+		if f.Kbps == 0 {
+			return errors.New("bandwidth faults require a positive kbps")
+		}
+	case TypeDS5xx:
+		if f.DeliveryService == "" || f.Rate == 0 {
+			return errors.New("ds5xx faults require a deliveryService and a positive rate")
+		}
+	default:
+		return errors.New("unknown fault type '" + string(f.Type) + "'")
+	}
+	return nil
+}
+
+// appliesTo returns whether the fault applies to the cache with the given host name.
+func (f Fault) appliesTo(host string) bool {
+	if len(f.Servers) == 0 {
+		return true
+	}
+	for _, server := range f.Servers {
+		if server == host {
+			return true
+		}
+	}
+	return false
+}
+
+// Scenario is a list of faults, scheduled relative to when testcaches starts.
+type Scenario struct {
+	Faults []Fault `json:"faults"`
+}
+
+// LoadScenario loads and validates the JSON scenario file at the given path.
+func LoadScenario(path string) (Scenario, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Scenario{}, errors.New("reading scenario: " + err.Error())
+	}
+	sc := Scenario{}
+	if err := json.Unmarshal(b, &sc); err != nil {
+		return Scenario{}, errors.New("decoding scenario: " + err.Error())
+	}
+	for i, f := range sc.Faults {
+		if err := f.Validate(); err != nil {
+			return Scenario{}, errors.New("scenario fault " + strconv.Itoa(i) + ": " + err.Error())
+		}
+	}
+	return sc, nil
+}
+
+// Effects is the combined effect of all faults active on a cache at some instant.
+type Effects struct {
+	Timeout bool
+	// Delay is the longest delay of all active slow faults.
+	Delay time.Duration
+	// LoadAvg is the highest load average of all active loadavg faults, or nil if there are none.
+	LoadAvg *float64
+	// Kbps is the sum of all active bandwidth faults.
+	Kbps          uint64
+	InterfaceDown bool
+	MalformedJSON bool
+	// DS5xx is the sum of the rates of all active ds5xx faults, keyed on Delivery Service.
+	DS5xx map[string]uint64
+}
+
+// Status is a scheduled fault, and when it's active.
+type Status struct {
+	Fault
+	StartTime time.Time `json:"startTime"`
+	// EndTime is when the fault ends, or nil if it lasts until it's removed.
+	EndTime *time.Time `json:"endTime,omitempty"`
+	Active  bool       `json:"active"`
+}
+
+func (s Status) activeAt(t time.Time) bool {
+	return !t.Before(s.StartTime) && (s.EndTime == nil || t.Before(*s.EndTime))
+}
+
+// Injector holds the scheduled faults of all caches. It is safe for concurrent use.
+type Injector struct {
+	m      sync.RWMutex
+	faults []Status
+	lastID int
+}
+
+// NewInjector returns an Injector with no faults.
+func NewInjector() *Injector {
+	return &Injector{}
+}
+
+// Schedule validates the fault, and schedules it relative to the given time. Returns the scheduled fault, with its assigned ID.
+func (inj *Injector) Schedule(f Fault, from time.Time) (Status, error) {
+	if err := f.Validate(); err != nil {
+		return Status{}, err
+	}
+	inj.m.Lock()
+	defer inj.m.Unlock()
+	inj.lastID++
+	f.ID = inj.lastID
+	st := Status{Fault: f, StartTime: from.Add(time.Duration(f.Start))}
+	if f.Duration > 0 {
+		end := st.StartTime.Add(time.Duration(f.Duration))
+		st.EndTime = &end
+	}
+	inj.faults = append(inj.faults, st)
+	st.Active = st.activeAt(from)
+	return st, nil
+}
+
+// ScheduleScenario schedules all the faults in the scenario, relative to the given time.
+func (inj *Injector) ScheduleScenario(sc Scenario, from time.Time) error {
+	for i, f := range sc.Faults {
+		if _, err := inj.Schedule(f, from); err != nil {
+			return errors.New("scenario fault " + strconv.Itoa(i) + ": " + err.Error())
+		}
+	}
+	return nil
+}
+
+// Remove removes the fault with the given ID. Returns whether it existed.
+func (inj *Injector) Remove(id int) bool {
+	inj.m.Lock()
+	defer inj.m.Unlock()
+	for i, st := range inj.faults {
+		if st.ID == id {
+			inj.faults = append(inj.faults[:i], inj.faults[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Clear removes all faults.
+func (inj *Injector) Clear() {
+	inj.m.Lock()
+	defer inj.m.Unlock()
+	inj.faults = nil
+}
+
+// List returns all scheduled faults, including those which have ended, and whether each is active at the given time.
+func (inj *Injector) List(now time.Time) []Status {
+	inj.m.RLock()
+	defer inj.m.RUnlock()
+	statuses := make([]Status, 0, len(inj.faults))
+	for _, st := range inj.faults {
+		st.Active = st.activeAt(now)
+		statuses = append(statuses, st)
+	}
+	return statuses
+}
+
+// Effects returns the combined effects of all faults active on the given cache at the given time.
+func (inj *Injector) Effects(host string, now time.Time) Effects {
+	inj.m.RLock()
+	defer inj.m.RUnlock()
+	ef := Effects{}
+	for _, st := range inj.faults {
+		if !st.activeAt(now) || !st.appliesTo(host) {
+			continue
+		}
+		switch st.Type {
+		case TypeTimeout:
+			ef.Timeout = true
+		case TypeSlow:
+			if delay := time.Duration(st.Delay); delay > ef.Delay {
+				ef.Delay = delay
+			}
+		case TypeLoadAvg:
+			if ef.LoadAvg == nil || st.LoadAvg > *ef.LoadAvg {
+				loadAvg := st.LoadAvg
+				ef.LoadAvg = &loadAvg
+			}
+		case TypeBandwidth:
+			ef.Kbps += st.Kbps
+		case TypeInterfaceDown:
+			ef.InterfaceDown = true
+		case TypeMalformedJSON:
+			ef.MalformedJSON = true
+		case TypeDS5xx:
+			if ef.DS5xx == nil {
+				ef.DS5xx = map[string]uint64{}
+			}
+			ef.DS5xx[st.DeliveryService] += st.Rate
+		}
+	}
+	return ef
+}
@@ -0,0 +1,178 @@
+package fault
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInjectorEffects(t *testing.T) {
+	start := time.Now()
+	inj := NewInjector()
+	faults := []Fault{
+		{Type: TypeSlow, Delay: Duration(time.Second), Duration: Duration(time.Minute)},
+		{Type: TypeSlow, Delay: Duration(3 * time.Second), Start: Duration(time.Minute)},
+		{Type: TypeLoadAvg, LoadAvg: 20, Servers: []string{"edge0"}},
+		{Type: TypeLoadAvg, LoadAvg: 50, Servers: []string{"edge0"}, Duration: Duration(time.Minute)},
+		{Type: TypeBandwidth, Kbps: 1000},
+		{Type: TypeBandwidth, Kbps: 500, Servers: []string{"edge1"}},
+		{Type: TypeDS5xx, DeliveryService: "ds0", Rate: 10, Servers: []string{"edge1"}},
+	}
+	for _, f := range faults {
+		if _, err := inj.Schedule(f, start); err != nil {
+			t.Fatalf("scheduling %+v: %v", f, err)
+		}
+	}
+
+	ef := inj.Effects("edge0", start)
+	if ef.Delay != time.Second {
+		t.Errorf("expected edge0 delay 1s before the second slow fault starts, actual %v", ef.Delay)
+	}
+	if ef.LoadAvg == nil || *ef.LoadAvg != 50 {
+		t.Errorf("expected edge0 to have the highest loadavg 50, actual %v", ef.LoadAvg)
+	}
+	if ef.Kbps != 1000 {
+		t.Errorf("expected edge0 kbps 1000, actual %v", ef.Kbps)
+	}
+	if len(ef.DS5xx) != 0 {
+		t.Errorf("expected edge0 to have no ds5xx, actual %v", ef.DS5xx)
+	}
+
+	ef = inj.Effects("edge0", start.Add(2*time.Minute))
+	if ef.Delay != 3*time.Second {
+		t.Errorf("expected edge0 delay 3s after the first slow fault ends, actual %v", ef.Delay)
+	}
+	if ef.LoadAvg == nil || *ef.LoadAvg != 20 {
+		t.Errorf("expected edge0 loadavg 20 after the higher one ends, actual %v", ef.LoadAvg)
+	}
+
+	ef = inj.Effects("edge1", start)
+	if ef.LoadAvg != nil {
+		t.Errorf("expected edge1 to have no loadavg, actual %v", *ef.LoadAvg)
+	}
+	if ef.Kbps != 1500 {
+		t.Errorf("expected edge1 kbps to be the sum 1500, actual %v", ef.Kbps)
+	}
+	if ef.DS5xx["ds0"] != 10 {
+		t.Errorf("expected edge1 ds0 5xx rate 10, actual %v", ef.DS5xx)
+	}
+
+	statuses := inj.List(start.Add(2 * time.Minute))
+	if len(statuses) != len(faults) {
+		t.Fatalf("expected %v faults listed, actual %v", len(faults), len(statuses))
+	}
+	if statuses[0].ID != 1 || statuses[0].Active || !statuses[1].Active {
+		t.Errorf("expected the first fault to have id 1 and have ended, and the second to be active, actual %+v %+v", statuses[0], statuses[1])
+	}
+
+	if !inj.Remove(statuses[4].ID) {
+		t.Errorf("expected removing fault %v to succeed", statuses[4].ID)
+	}
+	if inj.Remove(statuses[4].ID) {
+		t.Errorf("expected removing fault %v twice to fail", statuses[4].ID)
+	}
+	if ef = inj.Effects("edge0", start); ef.Kbps != 0 {
+		t.Errorf("expected edge0 kbps 0 after removing the bandwidth fault, actual %v", ef.Kbps)
+	}
+	inj.Clear()
+	if statuses = inj.List(start); len(statuses) != 0 {
+		t.Errorf("expected no faults after clearing, actual %v", len(statuses))
+	}
+}
+
+func TestLoadScenario(t *testing.T) {
+	dir, err := ioutil.TempDir("", "testcaches-fault")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "scenario.json")
+	valid := `{"faults": [
+		{"type": "timeout", "servers": ["edge0"], "start": "30s", "duration": "1m"},
+		{"type": "ds5xx", "deliveryService": "ds0", "rate": 5, "start": "0s", "duration": "0s"}
+	]}`
+	if err := ioutil.WriteFile(path, []byte(valid), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sc, err := LoadScenario(path)
+	if err != nil {
+		t.Fatalf("loading valid scenario: %v", err)
+	}
+	if len(sc.Faults) != 2 || sc.Faults[0].Start != Duration(30*time.Second) || sc.Faults[0].Duration != Duration(time.Minute) {
+		t.Errorf("expected the scenario's faults to be decoded, actual %+v", sc.Faults)
+	}
+
+	invalid := map[string]string{
+		"unknown type":    `{"faults": [{"type": "meltdown"}]}`,
+		"missing param":   `{"faults": [{"type": "ds5xx", "rate": 5}]}`,
+		"number duration": `{"faults": [{"type": "timeout", "start": 30}]}`,
+	}
+	for name, scenario := range invalid {
+		if err := ioutil.WriteFile(path, []byte(scenario), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadScenario(path); err == nil {
+			t.Errorf("expected loading scenario with %v to fail", name)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	inj := NewInjector()
+	h := Handler(inj)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/faults", strings.NewReader(`{"type": "interfaceDown", "servers": ["edge0"]}`)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected POST to return %v, actual %v: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	if ef := inj.Effects("edge0", time.Now()); !ef.InterfaceDown {
+		t.Errorf("expected a POSTed fault to be active immediately")
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/faults", strings.NewReader(`{"type": "slow"}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected POSTing an invalid fault to return %v, actual %v", http.StatusBadRequest, w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/faults/1", nil))
+	if w.Code != http.StatusNoContent {
+		t.Errorf("expected DELETE to return %v, actual %v", http.StatusNoContent, w.Code)
+	}
+	if ef := inj.Effects("edge0", time.Now()); ef.InterfaceDown {
+		t.Errorf("expected a DELETEd fault to be inactive")
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/faults/1", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected DELETEing a nonexistent fault to return %v, actual %v", http.StatusNotFound, w.Code)
+	}
+}
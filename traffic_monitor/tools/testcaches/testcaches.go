@@ -20,26 +20,60 @@ package main
  */
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
+	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/apache/trafficcontrol/traffic_monitor/tools/testcaches/fakesrvr"
+	"github.com/apache/trafficcontrol/traffic_monitor/tools/testcaches/fault"
+	"github.com/apache/trafficcontrol/traffic_monitor/tools/testcaches/topology"
 )
 
-func makeFakeRemaps(n int) []string {
-	remaps := []string{}
-	for i := 0; i < n; i++ {
-		remaps = append(remaps, "num"+strconv.Itoa(i)+".example.net")
+// ServedCache is a fake cache, and the port it's served on.
+type ServedCache struct {
+	topology.Cache
+	Port int `json:"port"`
+}
+
+// serveControl serves the fault control API, and the list of served caches at /servers, on the given port.
+func serveControl(port int, portStart int, caches []topology.Cache, faults *fault.Injector) {
+	served := make([]ServedCache, 0, len(caches))
+	for i, cache := range caches {
+		served = append(served, ServedCache{Cache: cache, Port: portStart + i})
+	}
+	servedJSON, err := json.MarshalIndent(served, "", "  ")
+	if err != nil {
+		fmt.Println("Error marshalling servers: " + err.Error())
+		return
 	}
-	return remaps
+
+	faultHandler := fault.Handler(faults)
+	mux := http.NewServeMux()
+	mux.Handle("/faults", faultHandler)
+	mux.Handle("/faults/", faultHandler)
+	mux.HandleFunc("/servers", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(servedJSON)
+	})
+	go func() {
+		fmt.Println("Serving control API on port " + strconv.Itoa(port))
+		if err := http.ListenAndServe(":"+strconv.Itoa(port), mux); err != nil {
+			fmt.Println("Error serving control API on port " + strconv.Itoa(port) + ": " + err.Error())
+		}
+	}()
 }
 
 func main() {
 	portStart := flag.Int("portStart", 40000, "Starting port in range")
 	numPorts := flag.Int("numPorts", 1000, "Number of ports to serve")
 	numRemaps := flag.Int("numRemaps", 1000, "Number of remaps to serve")
+	crConfigPath := flag.String("crConfig", "", "CRConfig (Snapshot) file to serve the caches of, instead of numPorts generic caches")
+	monitoringConfigPath := flag.String("monitoringConfig", "", "Traffic Monitor configuration file to serve the caches of, instead of numPorts generic caches")
+	scenarioPath := flag.String("scenario", "", "Scenario file of faults to inject")
+	controlPort := flag.Int("controlPort", 0, "Port to serve the fault control API on; 0 to not serve it")
 	flag.Parse()
 	if *portStart < 0 || *portStart > 65535 {
 		fmt.Println("portStart must be 0-65535")
@@ -50,10 +84,49 @@ func main() {
 	} else if *numRemaps < 0 {
 		fmt.Println("numRemaps must be > 0")
 		return
+	} else if *crConfigPath != "" && *monitoringConfigPath != "" {
+		fmt.Println("only one of crConfig and monitoringConfig may be given")
+		return
+	} else if *controlPort < 0 || *controlPort > 65535 {
+		fmt.Println("controlPort must be 0-65535")
+		return
+	}
+
+	caches := []topology.Cache(nil)
+	err := error(nil)
+	switch {
+	case *crConfigPath != "":
+		caches, err = topology.LoadCRConfig(*crConfigPath)
+	case *monitoringConfigPath != "":
+		caches, err = topology.LoadMonitoringConfig(*monitoringConfigPath)
+	default:
+		caches = topology.Generic(*numPorts, *numRemaps)
+	}
+	if err != nil {
+		fmt.Println("Error loading caches: " + err.Error())
+		return
+	} else if *portStart+len(caches) > 65535 {
+		fmt.Println("portStart plus the number of caches must be < 65535")
+		return
+	}
+
+	faults := fault.NewInjector()
+	if *scenarioPath != "" {
+		scenario, err := fault.LoadScenario(*scenarioPath)
+		if err != nil {
+			fmt.Println("Error loading scenario: " + err.Error())
+			return
+		}
+		if err := faults.ScheduleScenario(scenario, time.Now()); err != nil {
+			fmt.Println("Error scheduling scenario: " + err.Error())
+			return
+		}
+	}
+	if *controlPort != 0 {
+		serveControl(*controlPort, *portStart, caches, faults)
 	}
 
-	remaps := makeFakeRemaps(*numRemaps)
-	_, err := fakesrvr.News(*portStart, *numPorts, remaps)
+	_, err = fakesrvr.News(*portStart, caches, faults)
 	if err != nil {
 		fmt.Println("Error making FakeServers: " + err.Error())
 		return
@@ -0,0 +1,171 @@
+package topology
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"sort"
+	"strconv"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+// DefaultInterfaceName is the interface name of caches whose configuration doesn't specify one.
+const DefaultInterfaceName = "bond0"
+
+// Cache is the shape of a single fake cache: the host it pretends to be, the interface whose stats it serves, and the remaps it serves stats for.
+type Cache struct {
+	HostName      string `json:"hostName"`
+	InterfaceName string `json:"interfaceName"`
+	// DeliveryServices is the remap names of each Delivery Service assigned to the cache, keyed on the Delivery Service XMLID.
+	DeliveryServices map[string][]string `json:"deliveryServices"`
+}
+
+// Remaps returns all of the cache's remap names, sorted.
+func (c Cache) Remaps() []string {
+	remaps := []string{}
+	for _, dsRemaps := range c.DeliveryServices {
+		remaps = append(remaps, dsRemaps...)
+	}
+	sort.Strings(remaps)
+	return remaps
+}
+
+// Generic returns numCaches caches named "testcache0", "testcache1", etc., each with numRemaps Delivery Services named "num0", "num1", etc., each of which has the single remap "num0.example.net", "num1.example.net", etc.
+func Generic(numCaches int, numRemaps int) []Cache {
+	dses := make(map[string][]string, numRemaps)
+	for i := 0; i < numRemaps; i++ {
+		ds := "num" + strconv.Itoa(i)
+		dses[ds] = []string{ds + ".example.net"}
+	}
+	caches := make([]Cache, 0, numCaches)
+	for i := 0; i < numCaches; i++ {
+		caches = append(caches, Cache{
+			HostName:         "testcache" + strconv.Itoa(i),
+			InterfaceName:    DefaultInterfaceName,
+			DeliveryServices: dses,
+		})
+	}
+	return caches
+}
+
+// LoadCRConfig returns the caches of the CRConfig (Snapshot) at the given path, sorted by host name.
+// The file may be either a bare CRConfig, or a Traffic Ops API response wrapping one.
+func LoadCRConfig(path string) ([]Cache, error) {
+	crc := tc.CRConfig{}
+	if err := loadJSON(path, &crc); err != nil {
+		return nil, err
+	}
+	caches := []Cache{}
+	for name, srv := range crc.ContentServers {
+		if srv.ServerType == nil || !isCache(*srv.ServerType) {
+			continue
+		}
+		ifaceName := ""
+		if srv.InterfaceName != nil {
+			ifaceName = *srv.InterfaceName
+		}
+		caches = append(caches, Cache{
+			HostName:         name,
+			InterfaceName:    interfaceName(ifaceName, srv.Interfaces),
+			DeliveryServices: copyDeliveryServices(srv.DeliveryServices),
+		})
+	}
+	return sortCaches(caches), nil
+}
+
+// LoadMonitoringConfig returns the caches of the Traffic Monitor configuration at the given path, sorted by host name.
+// The file may be either a bare TrafficMonitorConfig, or a Traffic Ops API response wrapping one.
+func LoadMonitoringConfig(path string) ([]Cache, error) {
+	cfg := tc.TrafficMonitorConfig{}
+	if err := loadJSON(path, &cfg); err != nil {
+		return nil, err
+	}
+	caches := []Cache{}
+	for _, srv := range cfg.TrafficServers {
+		if !isCache(srv.Type) {
+			continue
+		}
+		dses := map[string][]string{}
+		for _, ds := range srv.DeliveryServices {
+			dses[ds.Xmlid] = append([]string{}, ds.Remaps...)
+		}
+		caches = append(caches, Cache{
+			HostName:         srv.HostName,
+			InterfaceName:    interfaceName(srv.InterfaceName, srv.Interfaces),
+			DeliveryServices: dses,
+		})
+	}
+	return sortCaches(caches), nil
+}
+
+// loadJSON decodes the JSON file at path into obj, unwrapping it from a Traffic Ops API "response" if necessary.
+func loadJSON(path string, obj interface{}) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.New("reading '" + path + "': " + err.Error())
+	}
+	wrapper := struct {
+		Response json.RawMessage `json:"response"`
+	}{}
+	if err := json.Unmarshal(b, &wrapper); err != nil {
+		return errors.New("decoding '" + path + "': " + err.Error())
+	}
+	if len(wrapper.Response) > 0 {
+		b = wrapper.Response
+	}
+	if err := json.Unmarshal(b, obj); err != nil {
+		return errors.New("decoding '" + path + "': " + err.Error())
+	}
+	return nil
+}
+
+// isCache returns whether the server type is one whose stats Traffic Monitor polls.
+func isCache(serverType string) bool {
+	return tc.CacheTypeFromString(serverType) != tc.CacheTypeInvalid
+}
+
+// interfaceName returns the name of the interface Traffic Monitor polls: the legacy interface name if it's set, else the first monitored interface, else DefaultInterfaceName.
+func interfaceName(legacyName string, interfaces []tc.ServerInterfaceInfo) string {
+	if legacyName != "" {
+		return legacyName
+	}
+	for _, iface := range interfaces {
+		if iface.Monitor && iface.Name != "" {
+			return iface.Name
+		}
+	}
+	return DefaultInterfaceName
+}
+
+func copyDeliveryServices(dses map[string][]string) map[string][]string {
+	cp := make(map[string][]string, len(dses))
+	for ds, remaps := range dses {
+		cp[ds] = append([]string{}, remaps...)
+	}
+	return cp
+}
+
+func sortCaches(caches []Cache) []Cache {
+	sort.Slice(caches, func(i, j int) bool { return caches[i].HostName < caches[j].HostName })
+	return caches
+}
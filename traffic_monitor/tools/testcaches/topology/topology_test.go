@@ -0,0 +1,91 @@
+package topology
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeTemp(t *testing.T, contents string) string {
+	f, err := ioutil.TempFile("", "testcaches-topology")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(contents); err != nil {
+		t.Fatal(err)
+	}
+	return f.Name()
+}
+
+func TestLoadCRConfig(t *testing.T) {
+	path := writeTemp(t, `{"response": {"contentServers": {
+		"edge1": {"type": "EDGE", "interfaceName": "eth0", "deliveryServices": {"ds0": ["edge1.ds0.cdn.example.net"]}},
+		"edge0": {"type": "EDGE", "interfaces": [{"name": "lo", "monitor": false}, {"name": "eth1", "monitor": true}], "deliveryServices": {"ds0": ["edge0.ds0.cdn.example.net"], "ds1": ["edge0.ds1.cdn.example.net", "ds1.example.org"]}},
+		"mid0": {"type": "MID_LOC"},
+		"tr0": {"type": "CCR"}
+	}}}`)
+	defer os.Remove(path)
+
+	caches, err := LoadCRConfig(path)
+	if err != nil {
+		t.Fatalf("loading CRConfig: %v", err)
+	}
+	expected := []Cache{
+		{HostName: "edge0", InterfaceName: "eth1", DeliveryServices: map[string][]string{"ds0": {"edge0.ds0.cdn.example.net"}, "ds1": {"edge0.ds1.cdn.example.net", "ds1.example.org"}}},
+		{HostName: "edge1", InterfaceName: "eth0", DeliveryServices: map[string][]string{"ds0": {"edge1.ds0.cdn.example.net"}}},
+		{HostName: "mid0", InterfaceName: DefaultInterfaceName, DeliveryServices: map[string][]string{}},
+	}
+	if !reflect.DeepEqual(caches, expected) {
+		t.Errorf("expected caches %+v, actual %+v", expected, caches)
+	}
+	if remaps := caches[0].Remaps(); !reflect.DeepEqual(remaps, []string{"ds1.example.org", "edge0.ds0.cdn.example.net", "edge0.ds1.cdn.example.net"}) {
+		t.Errorf("expected edge0 remaps sorted, actual %v", remaps)
+	}
+}
+
+func TestLoadMonitoringConfig(t *testing.T) {
+	path := writeTemp(t, `{"trafficServers": [
+		{"hostName": "mid0", "type": "MID", "interfaceName": "bond1"},
+		{"hostName": "edge0", "type": "EDGE", "interfaceName": "eth0", "deliveryServices": [{"xmlId": "ds0", "remaps": ["edge0.ds0.cdn.example.net"]}]},
+		{"hostName": "tm0", "type": "RASCAL"}
+	]}`)
+	defer os.Remove(path)
+
+	caches, err := LoadMonitoringConfig(path)
+	if err != nil {
+		t.Fatalf("loading monitoring config: %v", err)
+	}
+	expected := []Cache{
+		{HostName: "edge0", InterfaceName: "eth0", DeliveryServices: map[string][]string{"ds0": {"edge0.ds0.cdn.example.net"}}},
+		{HostName: "mid0", InterfaceName: "bond1", DeliveryServices: map[string][]string{}},
+	}
+	if !reflect.DeepEqual(caches, expected) {
+		t.Errorf("expected caches %+v, actual %+v", expected, caches)
+	}
+
+	if _, err := LoadMonitoringConfig(filepath.Join(os.TempDir(), "testcaches-nonexistent.json")); err == nil {
+		t.Errorf("expected loading a nonexistent file to fail")
+	}
+}
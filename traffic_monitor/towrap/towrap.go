@@ -460,6 +460,7 @@ func CreateMonitorConfig(crConfig tc.CRConfig, mc *tc.TrafficMonitorConfigMap) (
 		} else {
 			log.Warnf("Creating monitor config: CRConfig server %s missing InterfaceName field\n", name)
 		}
+		s.Interfaces = srv.Interfaces
 		if srv.ServerType != nil {
 			s.Type = *srv.ServerType
 		} else {
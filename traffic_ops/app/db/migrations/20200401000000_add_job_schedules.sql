@@ -0,0 +1,33 @@
+/*
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+	    http://www.apache.org/licenses/LICENSE-2.0
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS job_schedule (
+    id bigserial PRIMARY KEY,
+    deliveryservice bigint NOT NULL REFERENCES deliveryservice(id) ON DELETE CASCADE,
+    regex text NOT NULL,
+    ttl_hours bigint NOT NULL,
+    cron text,
+    next_run timestamp with time zone NOT NULL,
+    last_run timestamp with time zone,
+    job_user bigint NOT NULL REFERENCES tm_user(id),
+    last_updated timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT job_schedule_ttl_positive CHECK (ttl_hours > 0)
+);
+CREATE INDEX IF NOT EXISTS job_schedule_next_run_idx ON job_schedule (next_run);
+DROP TRIGGER IF EXISTS on_update_current_timestamp ON job_schedule;
+CREATE TRIGGER on_update_current_timestamp BEFORE UPDATE ON job_schedule FOR EACH ROW EXECUTE PROCEDURE on_update_current_timestamp_last_updated();
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS job_schedule;
@@ -0,0 +1,51 @@
+/*
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+	    http://www.apache.org/licenses/LICENSE-2.0
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS event (
+    id bigserial PRIMARY KEY,
+    event_type text NOT NULL,
+    username text NOT NULL,
+    data json NOT NULL,
+    created timestamp with time zone NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS event_created_idx ON event (created);
+
+CREATE TABLE IF NOT EXISTS webhook (
+    id bigserial PRIMARY KEY,
+    url text NOT NULL,
+    secret text NOT NULL,
+    event_types text[] NOT NULL,
+    active boolean NOT NULL DEFAULT TRUE,
+    last_updated timestamp with time zone NOT NULL DEFAULT now()
+);
+DROP TRIGGER IF EXISTS on_update_current_timestamp ON webhook;
+CREATE TRIGGER on_update_current_timestamp BEFORE UPDATE ON webhook FOR EACH ROW EXECUTE PROCEDURE on_update_current_timestamp_last_updated();
+
+CREATE TABLE IF NOT EXISTS webhook_delivery (
+    id bigserial PRIMARY KEY,
+    webhook bigint NOT NULL REFERENCES webhook(id) ON DELETE CASCADE,
+    event bigint NOT NULL REFERENCES event(id) ON DELETE CASCADE,
+    attempts integer NOT NULL DEFAULT 0,
+    next_attempt timestamp with time zone NOT NULL DEFAULT now(),
+    last_error text,
+    delivered timestamp with time zone,
+    failed boolean NOT NULL DEFAULT FALSE
+);
+CREATE INDEX IF NOT EXISTS webhook_delivery_pending_idx ON webhook_delivery (next_attempt) WHERE delivered IS NULL AND NOT failed;
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS webhook_delivery;
+DROP TABLE IF EXISTS webhook;
+DROP TABLE IF EXISTS event;
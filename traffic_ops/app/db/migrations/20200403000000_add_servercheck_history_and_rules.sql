@@ -0,0 +1,46 @@
+/*
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+	    http://www.apache.org/licenses/LICENSE-2.0
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS servercheck_result (
+    id bigserial PRIMARY KEY,
+    server bigint NOT NULL REFERENCES server(id) ON DELETE CASCADE,
+    check_name text NOT NULL,
+    value bigint NOT NULL,
+    created timestamp with time zone NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS servercheck_result_server_check_created_idx ON servercheck_result (server, check_name, created DESC);
+
+CREATE TABLE IF NOT EXISTS servercheck_rule (
+    id bigserial PRIMARY KEY,
+    check_name text NOT NULL,
+    comparison text NOT NULL,
+    threshold bigint NOT NULL,
+    consecutive bigint NOT NULL DEFAULT 1,
+    status bigint REFERENCES status(id) ON DELETE RESTRICT,
+    alert boolean NOT NULL DEFAULT false,
+    description text,
+    last_updated timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT servercheck_rule_comparison_valid CHECK (comparison IN ('<', '<=', '=', '!=', '>=', '>')),
+    CONSTRAINT servercheck_rule_consecutive_positive CHECK (consecutive > 0),
+    CONSTRAINT servercheck_rule_has_action CHECK (status IS NOT NULL OR alert)
+);
+CREATE INDEX IF NOT EXISTS servercheck_rule_check_name_idx ON servercheck_rule (check_name);
+DROP TRIGGER IF EXISTS on_update_current_timestamp ON servercheck_rule;
+CREATE TRIGGER on_update_current_timestamp BEFORE UPDATE ON servercheck_rule FOR EACH ROW EXECUTE PROCEDURE on_update_current_timestamp_last_updated();
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS servercheck_rule;
+DROP TABLE IF EXISTS servercheck_result;
@@ -0,0 +1,33 @@
+/*
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+	    http://www.apache.org/licenses/LICENSE-2.0
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS tenant_quota (
+    tenant bigint PRIMARY KEY REFERENCES tenant(id) ON DELETE CASCADE,
+    max_delivery_services bigint CHECK (max_delivery_services >= 0),
+    max_edge_servers bigint CHECK (max_edge_servers >= 0),
+    max_invalidation_jobs_per_day bigint CHECK (max_invalidation_jobs_per_day >= 0),
+    max_regexes_per_ds bigint CHECK (max_regexes_per_ds >= 0),
+    last_updated timestamp with time zone NOT NULL DEFAULT now()
+);
+DROP TRIGGER IF EXISTS on_update_current_timestamp ON tenant_quota;
+CREATE TRIGGER on_update_current_timestamp BEFORE UPDATE ON tenant_quota FOR EACH ROW EXECUTE PROCEDURE on_update_current_timestamp_last_updated();
+
+-- Counting a Tenant's Invalidation Jobs of the last day looks them up by Delivery Service and time.
+CREATE INDEX IF NOT EXISTS job_deliveryservice_entered_time_idx ON job (job_deliveryservice, entered_time);
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP INDEX IF EXISTS job_deliveryservice_entered_time_idx;
+DROP TABLE IF EXISTS tenant_quota;
@@ -0,0 +1,57 @@
+/*
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+	    http://www.apache.org/licenses/LICENSE-2.0
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS topology (
+    name text PRIMARY KEY CHECK (name <> ''),
+    description text NOT NULL DEFAULT '',
+    last_updated timestamp with time zone NOT NULL DEFAULT now()
+);
+DROP TRIGGER IF EXISTS on_update_current_timestamp ON topology;
+CREATE TRIGGER on_update_current_timestamp BEFORE UPDATE ON topology FOR EACH ROW EXECUTE PROCEDURE on_update_current_timestamp_last_updated();
+
+-- A Cache Group may appear in any number of Topologies, but only once in each.
+CREATE TABLE IF NOT EXISTS topology_cachegroup (
+    id bigserial PRIMARY KEY,
+    topology text NOT NULL REFERENCES topology (name) ON UPDATE CASCADE ON DELETE CASCADE,
+    cachegroup text NOT NULL REFERENCES cachegroup (name) ON UPDATE CASCADE ON DELETE RESTRICT,
+    last_updated timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT topology_cachegroup_unique UNIQUE (topology, cachegroup)
+);
+DROP TRIGGER IF EXISTS on_update_current_timestamp ON topology_cachegroup;
+CREATE TRIGGER on_update_current_timestamp BEFORE UPDATE ON topology_cachegroup FOR EACH ROW EXECUTE PROCEDURE on_update_current_timestamp_last_updated();
+
+-- Each node has at most a primary (rank 1) and a secondary (rank 2) parent.
+CREATE TABLE IF NOT EXISTS topology_cachegroup_parents (
+    child bigint NOT NULL REFERENCES topology_cachegroup (id) ON DELETE CASCADE,
+    parent bigint NOT NULL REFERENCES topology_cachegroup (id) ON DELETE CASCADE,
+    rank integer NOT NULL CHECK (rank = 1 OR rank = 2),
+    last_updated timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT topology_cachegroup_parents_rank_unique UNIQUE (child, rank),
+    CONSTRAINT topology_cachegroup_parents_unique UNIQUE (child, parent),
+    CONSTRAINT topology_cachegroup_parents_not_self CHECK (child <> parent)
+);
+DROP TRIGGER IF EXISTS on_update_current_timestamp ON topology_cachegroup_parents;
+CREATE TRIGGER on_update_current_timestamp BEFORE UPDATE ON topology_cachegroup_parents FOR EACH ROW EXECUTE PROCEDURE on_update_current_timestamp_last_updated();
+
+ALTER TABLE deliveryservice ADD COLUMN IF NOT EXISTS topology text REFERENCES topology (name) ON UPDATE CASCADE ON DELETE RESTRICT;
+CREATE INDEX IF NOT EXISTS deliveryservice_topology_idx ON deliveryservice (topology);
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP INDEX IF EXISTS deliveryservice_topology_idx;
+ALTER TABLE deliveryservice DROP COLUMN IF EXISTS topology;
+DROP TABLE IF EXISTS topology_cachegroup_parents;
+DROP TABLE IF EXISTS topology_cachegroup;
+DROP TABLE IF EXISTS topology;
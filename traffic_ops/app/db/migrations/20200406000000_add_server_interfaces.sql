@@ -0,0 +1,80 @@
+/*
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+	    http://www.apache.org/licenses/LICENSE-2.0
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+
+
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS interface (
+    server bigint NOT NULL REFERENCES server (id) ON DELETE CASCADE,
+    name text NOT NULL CHECK (name <> ''),
+    max_bandwidth bigint DEFAULT NULL CHECK (max_bandwidth IS NULL OR max_bandwidth >= 0),
+    monitor boolean NOT NULL DEFAULT FALSE,
+    mtu bigint DEFAULT 1500 CHECK (mtu IS NULL OR mtu > 0),
+    last_updated timestamp with time zone NOT NULL DEFAULT now(),
+    PRIMARY KEY (server, name)
+);
+DROP TRIGGER IF EXISTS on_update_current_timestamp ON interface;
+CREATE TRIGGER on_update_current_timestamp BEFORE UPDATE ON interface FOR EACH ROW EXECUTE PROCEDURE on_update_current_timestamp_last_updated();
+
+CREATE TABLE IF NOT EXISTS ip_address (
+    address inet NOT NULL,
+    gateway inet CHECK (gateway IS NULL OR (family(gateway) = 4 AND masklen(gateway) = 32) OR (family(gateway) = 6 AND masklen(gateway) = 128)),
+    interface text NOT NULL,
+    server bigint NOT NULL,
+    service_address boolean NOT NULL DEFAULT FALSE,
+    last_updated timestamp with time zone NOT NULL DEFAULT now(),
+    PRIMARY KEY (address, interface, server),
+    FOREIGN KEY (server, interface) REFERENCES interface (server, name) ON UPDATE CASCADE ON DELETE CASCADE
+);
+DROP TRIGGER IF EXISTS on_update_current_timestamp ON ip_address;
+CREATE TRIGGER on_update_current_timestamp BEFORE UPDATE ON ip_address FOR EACH ROW EXECUTE PROCEDURE on_update_current_timestamp_last_updated();
+
+-- Every existing server gets one monitored interface holding its legacy addresses. Servers without
+-- an interface name get 'eth0', because interfaces must have names.
+INSERT INTO interface (server, name, mtu, monitor)
+SELECT s.id, COALESCE(NULLIF(s.interface_name, ''), 'eth0'), s.interface_mtu, TRUE
+FROM server AS s
+ON CONFLICT DO NOTHING;
+
+-- The legacy IPv4 netmask is converted to a prefix length by counting its set bits. A missing or
+-- malformed netmask, which can't be cast, makes the address a single host.
+INSERT INTO ip_address (address, gateway, interface, server, service_address)
+SELECT
+    set_masklen(s.ip_address::inet, CASE
+        WHEN s.ip_netmask ~ '^((25[0-5]|2[0-4][0-9]|1?[0-9]?[0-9])\.){3}(25[0-5]|2[0-4][0-9]|1?[0-9]?[0-9])$'
+            THEN length(replace(((s.ip_netmask::inet - '0.0.0.0'::inet)::bit(32))::text, '0', ''))
+        ELSE 32
+    END),
+    NULLIF(s.ip_gateway, '')::inet,
+    COALESCE(NULLIF(s.interface_name, ''), 'eth0'),
+    s.id,
+    s.ip_address_is_service
+FROM server AS s
+WHERE COALESCE(s.ip_address, '') <> ''
+ON CONFLICT DO NOTHING;
+
+INSERT INTO ip_address (address, gateway, interface, server, service_address)
+SELECT
+    s.ip6_address::inet,
+    NULLIF(s.ip6_gateway, '')::inet,
+    COALESCE(NULLIF(s.interface_name, ''), 'eth0'),
+    s.id,
+    s.ip6_address_is_service
+FROM server AS s
+WHERE COALESCE(s.ip6_address, '') <> ''
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS ip_address;
+DROP TABLE IF EXISTS interface;
@@ -0,0 +1,74 @@
+/*
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+	    http://www.apache.org/licenses/LICENSE-2.0
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS last_deleted (
+  table_name text NOT NULL PRIMARY KEY,
+  last_updated timestamp with time zone NOT NULL DEFAULT now()
+);
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION on_delete_current_timestamp_last_updated()
+  RETURNS trigger
+AS $$
+BEGIN
+  INSERT INTO last_deleted (table_name, last_updated) VALUES (TG_TABLE_NAME, now())
+  ON CONFLICT (table_name) DO UPDATE SET last_updated = now();
+  RETURN NULL;
+END;
+$$
+LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER on_delete_current_timestamp AFTER DELETE ON cachegroup FOR EACH STATEMENT EXECUTE PROCEDURE on_delete_current_timestamp_last_updated();
+CREATE TRIGGER on_delete_current_timestamp AFTER DELETE ON cdn FOR EACH STATEMENT EXECUTE PROCEDURE on_delete_current_timestamp_last_updated();
+CREATE TRIGGER on_delete_current_timestamp AFTER DELETE ON deliveryservice FOR EACH STATEMENT EXECUTE PROCEDURE on_delete_current_timestamp_last_updated();
+CREATE TRIGGER on_delete_current_timestamp AFTER DELETE ON deliveryservice_regex FOR EACH STATEMENT EXECUTE PROCEDURE on_delete_current_timestamp_last_updated();
+CREATE TRIGGER on_delete_current_timestamp AFTER DELETE ON deliveryservice_server FOR EACH STATEMENT EXECUTE PROCEDURE on_delete_current_timestamp_last_updated();
+CREATE TRIGGER on_delete_current_timestamp AFTER DELETE ON interface FOR EACH STATEMENT EXECUTE PROCEDURE on_delete_current_timestamp_last_updated();
+CREATE TRIGGER on_delete_current_timestamp AFTER DELETE ON ip_address FOR EACH STATEMENT EXECUTE PROCEDURE on_delete_current_timestamp_last_updated();
+CREATE TRIGGER on_delete_current_timestamp AFTER DELETE ON origin FOR EACH STATEMENT EXECUTE PROCEDURE on_delete_current_timestamp_last_updated();
+CREATE TRIGGER on_delete_current_timestamp AFTER DELETE ON parameter FOR EACH STATEMENT EXECUTE PROCEDURE on_delete_current_timestamp_last_updated();
+CREATE TRIGGER on_delete_current_timestamp AFTER DELETE ON phys_location FOR EACH STATEMENT EXECUTE PROCEDURE on_delete_current_timestamp_last_updated();
+CREATE TRIGGER on_delete_current_timestamp AFTER DELETE ON profile FOR EACH STATEMENT EXECUTE PROCEDURE on_delete_current_timestamp_last_updated();
+CREATE TRIGGER on_delete_current_timestamp AFTER DELETE ON profile_parameter FOR EACH STATEMENT EXECUTE PROCEDURE on_delete_current_timestamp_last_updated();
+CREATE TRIGGER on_delete_current_timestamp AFTER DELETE ON regex FOR EACH STATEMENT EXECUTE PROCEDURE on_delete_current_timestamp_last_updated();
+CREATE TRIGGER on_delete_current_timestamp AFTER DELETE ON server FOR EACH STATEMENT EXECUTE PROCEDURE on_delete_current_timestamp_last_updated();
+CREATE TRIGGER on_delete_current_timestamp AFTER DELETE ON status FOR EACH STATEMENT EXECUTE PROCEDURE on_delete_current_timestamp_last_updated();
+CREATE TRIGGER on_delete_current_timestamp AFTER DELETE ON tenant FOR EACH STATEMENT EXECUTE PROCEDURE on_delete_current_timestamp_last_updated();
+CREATE TRIGGER on_delete_current_timestamp AFTER DELETE ON tm_user FOR EACH STATEMENT EXECUTE PROCEDURE on_delete_current_timestamp_last_updated();
+CREATE TRIGGER on_delete_current_timestamp AFTER DELETE ON type FOR EACH STATEMENT EXECUTE PROCEDURE on_delete_current_timestamp_last_updated();
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TRIGGER IF EXISTS on_delete_current_timestamp ON cachegroup;
+DROP TRIGGER IF EXISTS on_delete_current_timestamp ON cdn;
+DROP TRIGGER IF EXISTS on_delete_current_timestamp ON deliveryservice;
+DROP TRIGGER IF EXISTS on_delete_current_timestamp ON deliveryservice_regex;
+DROP TRIGGER IF EXISTS on_delete_current_timestamp ON deliveryservice_server;
+DROP TRIGGER IF EXISTS on_delete_current_timestamp ON interface;
+DROP TRIGGER IF EXISTS on_delete_current_timestamp ON ip_address;
+DROP TRIGGER IF EXISTS on_delete_current_timestamp ON origin;
+DROP TRIGGER IF EXISTS on_delete_current_timestamp ON parameter;
+DROP TRIGGER IF EXISTS on_delete_current_timestamp ON phys_location;
+DROP TRIGGER IF EXISTS on_delete_current_timestamp ON profile;
+DROP TRIGGER IF EXISTS on_delete_current_timestamp ON profile_parameter;
+DROP TRIGGER IF EXISTS on_delete_current_timestamp ON regex;
+DROP TRIGGER IF EXISTS on_delete_current_timestamp ON server;
+DROP TRIGGER IF EXISTS on_delete_current_timestamp ON status;
+DROP TRIGGER IF EXISTS on_delete_current_timestamp ON tenant;
+DROP TRIGGER IF EXISTS on_delete_current_timestamp ON tm_user;
+DROP TRIGGER IF EXISTS on_delete_current_timestamp ON type;
+DROP FUNCTION IF EXISTS on_delete_current_timestamp_last_updated();
+DROP TABLE IF EXISTS last_deleted;
@@ -0,0 +1,56 @@
+/*
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+	    http://www.apache.org/licenses/LICENSE-2.0
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- Conditional GET requests read the latest last_updated time of each of these tables, which is only
+-- a quick lookup with an index on it.
+CREATE INDEX IF NOT EXISTS cachegroup_last_updated_idx ON cachegroup (last_updated);
+CREATE INDEX IF NOT EXISTS cdn_last_updated_idx ON cdn (last_updated);
+CREATE INDEX IF NOT EXISTS deliveryservice_last_updated_idx ON deliveryservice (last_updated);
+CREATE INDEX IF NOT EXISTS deliveryservice_regex_last_updated_idx ON deliveryservice_regex (last_updated);
+CREATE INDEX IF NOT EXISTS deliveryservice_server_last_updated_idx ON deliveryservice_server (last_updated);
+CREATE INDEX IF NOT EXISTS interface_last_updated_idx ON interface (last_updated);
+CREATE INDEX IF NOT EXISTS ip_address_last_updated_idx ON ip_address (last_updated);
+CREATE INDEX IF NOT EXISTS origin_last_updated_idx ON origin (last_updated);
+CREATE INDEX IF NOT EXISTS parameter_last_updated_idx ON parameter (last_updated);
+CREATE INDEX IF NOT EXISTS phys_location_last_updated_idx ON phys_location (last_updated);
+CREATE INDEX IF NOT EXISTS profile_last_updated_idx ON profile (last_updated);
+CREATE INDEX IF NOT EXISTS profile_parameter_last_updated_idx ON profile_parameter (last_updated);
+CREATE INDEX IF NOT EXISTS regex_last_updated_idx ON regex (last_updated);
+CREATE INDEX IF NOT EXISTS server_last_updated_idx ON server (last_updated);
+CREATE INDEX IF NOT EXISTS status_last_updated_idx ON status (last_updated);
+CREATE INDEX IF NOT EXISTS tenant_last_updated_idx ON tenant (last_updated);
+CREATE INDEX IF NOT EXISTS tm_user_last_updated_idx ON tm_user (last_updated);
+CREATE INDEX IF NOT EXISTS type_last_updated_idx ON type (last_updated);
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP INDEX IF EXISTS cachegroup_last_updated_idx;
+DROP INDEX IF EXISTS cdn_last_updated_idx;
+DROP INDEX IF EXISTS deliveryservice_last_updated_idx;
+DROP INDEX IF EXISTS deliveryservice_regex_last_updated_idx;
+DROP INDEX IF EXISTS deliveryservice_server_last_updated_idx;
+DROP INDEX IF EXISTS interface_last_updated_idx;
+DROP INDEX IF EXISTS ip_address_last_updated_idx;
+DROP INDEX IF EXISTS origin_last_updated_idx;
+DROP INDEX IF EXISTS parameter_last_updated_idx;
+DROP INDEX IF EXISTS phys_location_last_updated_idx;
+DROP INDEX IF EXISTS profile_last_updated_idx;
+DROP INDEX IF EXISTS profile_parameter_last_updated_idx;
+DROP INDEX IF EXISTS regex_last_updated_idx;
+DROP INDEX IF EXISTS server_last_updated_idx;
+DROP INDEX IF EXISTS status_last_updated_idx;
+DROP INDEX IF EXISTS tenant_last_updated_idx;
+DROP INDEX IF EXISTS tm_user_last_updated_idx;
+DROP INDEX IF EXISTS type_last_updated_idx;
@@ -117,6 +117,20 @@ func (to *Session) GetASNByASN(asn int) ([]tc.ASN, ReqInf, error) {
 	return data.Response, reqInf, nil
 }
 
+// DeleteASNByID deletes the ASN with the given ID.
+func (to *Session) DeleteASNByID(id int) (tc.Alerts, ReqInf, error) {
+	route := fmt.Sprintf("%s/%d", API_ASNS, id)
+	resp, remoteAddr, err := to.request(http.MethodDelete, route, nil)
+	reqInf := ReqInf{CacheHitStatus: CacheHitStatusMiss, RemoteAddr: remoteAddr}
+	if err != nil {
+		return tc.Alerts{}, reqInf, err
+	}
+	defer resp.Body.Close()
+	var alerts tc.Alerts
+	err = json.NewDecoder(resp.Body).Decode(&alerts)
+	return alerts, reqInf, nil
+}
+
 // DELETE an ASN by asn number
 func (to *Session) DeleteASNByASN(asn int) (tc.Alerts, ReqInf, error) {
 	route := fmt.Sprintf("%s/asn/%d", API_ASNS, asn)
@@ -0,0 +1,60 @@
+package client
+
+/*
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+// ExportCDN returns the configuration of the CDN with the given ID, including all of the Delivery
+// Services in it which are visible to the user.
+func (to *Session) ExportCDN(id int) (tc.CDNExport, ReqInf, error) {
+	doc := tc.CDNExport{}
+	reqInf, err := get(to, fmt.Sprintf("%s/%d/export", API_CDNS, id), &doc)
+	return doc, reqInf, err
+}
+
+// ImportCDN creates or updates the CDN described by doc, as exported by ExportCDN, and everything
+// in it. If dryRun is true, nothing is changed, but the returned report says what would have been.
+// If any object can't be imported, nothing is changed, and an error is returned along with the
+// report saying why.
+func (to *Session) ImportCDN(doc tc.CDNExport, dryRun bool) (tc.CDNImportReport, tc.Alerts, ReqInf, error) {
+	reqInf := ReqInf{CacheHitStatus: CacheHitStatusMiss}
+	reqBody, err := json.Marshal(doc)
+	if err != nil {
+		return tc.CDNImportReport{}, tc.Alerts{}, reqInf, err
+	}
+	// The report is returned even if the import fails, so the response is read whatever its status.
+	resp, remoteAddr, err := to.RawRequest(http.MethodPost, API_CDNS+"/import?dryRun="+strconv.FormatBool(dryRun), reqBody)
+	reqInf.RemoteAddr = remoteAddr
+	if err != nil {
+		return tc.CDNImportReport{}, tc.Alerts{}, reqInf, err
+	}
+	defer resp.Body.Close()
+
+	data := tc.CDNImportResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return tc.CDNImportReport{}, tc.Alerts{}, reqInf, fmt.Errorf("%s - decoding CDN import response: %v", resp.Status, err)
+	}
+	if resp.StatusCode >= 300 {
+		return data.Response, data.Alerts, reqInf, fmt.Errorf("%s - CDN import failed: %+v", resp.Status, data.Alerts.Alerts)
+	}
+	return data.Response, data.Alerts, reqInf, nil
+}
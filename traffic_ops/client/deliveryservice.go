@@ -19,6 +19,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"strconv"
 
 	"github.com/apache/trafficcontrol/lib/go-tc"
@@ -160,11 +161,17 @@ func (to *Session) GetDeliveryServicesNullable() ([]tc.DeliveryServiceNullable,
 // GetDeliveryServicesByCDNID returns the (tenant-visible) Delivery Services within the CDN identified
 // by the integral, unique identifier 'cdnID'.
 func (to *Session) GetDeliveryServicesByCDNID(cdnID int) ([]tc.DeliveryServiceNullable, ReqInf, error) {
+	return to.GetDeliveryServicesByCDNIDWithHdr(cdnID, nil)
+}
+
+// GetDeliveryServicesByCDNIDWithHdr is like GetDeliveryServicesByCDNID, but also sends the given headers, such as If-None-Match.
+// If Traffic Ops returns a 304 Not Modified, the returned Delivery Services are nil and the ReqInf StatusCode is 304.
+func (to *Session) GetDeliveryServicesByCDNIDWithHdr(cdnID int, header http.Header) ([]tc.DeliveryServiceNullable, ReqInf, error) {
 	data := struct {
 		Response []tc.DeliveryServiceNullable `json:"response"`
 	}{}
-	reqInf, err := get(to, API_DELIVERY_SERVICES+"?cdn="+strconv.Itoa(cdnID), &data)
-	if err != nil {
+	reqInf, err := getWithHdr(to, API_DELIVERY_SERVICES+"?cdn="+strconv.Itoa(cdnID), header, &data)
+	if err != nil || reqInf.StatusCode == http.StatusNotModified {
 		return nil, reqInf, err
 	}
 	return data.Response, reqInf, nil
@@ -332,28 +339,40 @@ func (to *Session) UpdateDeliveryService(id string, ds *tc.DeliveryService) (*tc
 // UpdateDeliveryServiceNullable updates the DeliveryService matching the ID it's
 // passed with the DeliveryService it is passed.
 func (to *Session) UpdateDeliveryServiceNullable(id string, ds *tc.DeliveryServiceNullable) (*tc.UpdateDeliveryServiceResponse, error) {
+	resp, _, err := to.UpdateDeliveryServiceNullableWithHdr(id, ds, nil)
+	return resp, err
+}
+
+// UpdateDeliveryServiceNullableWithHdr is like UpdateDeliveryServiceNullable, but also sends the given headers, such as If-Match or If-Unmodified-Since.
+// If the Delivery Service was changed since the given ETag or time, Traffic Ops returns an error, and the ReqInf StatusCode is 412.
+func (to *Session) UpdateDeliveryServiceNullableWithHdr(id string, ds *tc.DeliveryServiceNullable, header http.Header) (*tc.UpdateDeliveryServiceResponse, ReqInf, error) {
 	var data tc.UpdateDeliveryServiceResponse
 	jsonReq, err := json.Marshal(ds)
 	if err != nil {
-		return nil, err
+		return nil, ReqInf{CacheHitStatus: CacheHitStatusMiss}, err
 	}
-	_, err = put(to, fmt.Sprintf(API_DELIVERY_SERVICE_ID, id), jsonReq, &data)
+	reqInf, err := putWithHdr(to, fmt.Sprintf(API_DELIVERY_SERVICE_ID, id), jsonReq, header, &data)
 	if err != nil {
-		return nil, err
+		return nil, reqInf, err
 	}
-
-	return &data, nil
+	return &data, reqInf, nil
 }
 
 // DeleteDeliveryService deletes the DeliveryService matching the ID it's passed.
 func (to *Session) DeleteDeliveryService(id string) (*tc.DeleteDeliveryServiceResponse, error) {
+	resp, _, err := to.DeleteDeliveryServiceWithHdr(id, nil)
+	return resp, err
+}
+
+// DeleteDeliveryServiceWithHdr is like DeleteDeliveryService, but also sends the given headers, such as If-Match or If-Unmodified-Since.
+// If the Delivery Service was changed since the given ETag or time, Traffic Ops returns an error, and the ReqInf StatusCode is 412.
+func (to *Session) DeleteDeliveryServiceWithHdr(id string, header http.Header) (*tc.DeleteDeliveryServiceResponse, ReqInf, error) {
 	var data tc.DeleteDeliveryServiceResponse
-	_, err := del(to, fmt.Sprintf(API_DELIVERY_SERVICE_ID, id), &data)
+	reqInf, err := delWithHdr(to, fmt.Sprintf(API_DELIVERY_SERVICE_ID, id), header, &data)
 	if err != nil {
-		return nil, err
+		return nil, reqInf, err
 	}
-
-	return &data, nil
+	return &data, reqInf, nil
 }
 
 // GetDeliveryServiceHealth gets the 'health' of the Delivery Service identified by the
@@ -16,3 +16,5 @@
 package client
 
 const apiBase = "/api/2.0"
+
+const apiBaseV21 = "/api/2.1"
@@ -162,7 +162,7 @@ func (to *Session) GetInvalidationJobs(ds *interface{}, user *interface{}) ([]tc
 	}
 
 	resp, remoteAddr, err := to.request(http.MethodGet, path, nil)
-	reqInf := ReqInf{CacheHitStatusMiss, remoteAddr}
+	reqInf := ReqInf{CacheHitStatus: CacheHitStatusMiss, RemoteAddr: remoteAddr}
 	if err != nil {
 		return nil, reqInf, err
 	}
@@ -174,3 +174,98 @@ func (to *Session) GetInvalidationJobs(ds *interface{}, user *interface{}) ([]tc
 	err = json.NewDecoder(resp.Body).Decode(&data)
 	return data.Response, reqInf, err
 }
+
+// CreateInvalidationJobs creates many Content Invalidation Jobs at once, returning the created
+// jobs. Either all of the requested jobs are created, or none of them are.
+func (to *Session) CreateInvalidationJobs(jobs tc.InvalidationJobBulkInput) ([]tc.InvalidationJob, tc.Alerts, ReqInf, error) {
+	remoteAddr := (net.Addr)(nil)
+	reqBody, err := json.Marshal(jobs)
+	reqInf := ReqInf{CacheHitStatus: CacheHitStatusMiss, RemoteAddr: remoteAddr}
+	if err != nil {
+		return nil, tc.Alerts{}, reqInf, err
+	}
+	resp, remoteAddr, err := to.request(http.MethodPost, apiBase+`/jobs/bulk`, reqBody)
+	reqInf.RemoteAddr = remoteAddr
+	if err != nil {
+		return nil, tc.Alerts{}, reqInf, err
+	}
+	defer resp.Body.Close()
+
+	data := struct {
+		tc.Alerts
+		Response []tc.InvalidationJob `json:"response"`
+	}{}
+	err = json.NewDecoder(resp.Body).Decode(&data)
+	return data.Response, data.Alerts, reqInf, err
+}
+
+// GetInvalidationJobStatus returns how far the Content Invalidation Job identified by id has
+// propagated to the cache servers that must apply it.
+func (to *Session) GetInvalidationJobStatus(id uint64) (tc.InvalidationJobStatus, ReqInf, error) {
+	resp, remoteAddr, err := to.request(http.MethodGet, apiBase+"/jobs/"+strconv.FormatUint(id, 10)+"/status", nil)
+	reqInf := ReqInf{CacheHitStatus: CacheHitStatusMiss, RemoteAddr: remoteAddr}
+	if err != nil {
+		return tc.InvalidationJobStatus{}, reqInf, err
+	}
+	defer resp.Body.Close()
+
+	data := struct {
+		Response tc.InvalidationJobStatus `json:"response"`
+	}{}
+	err = json.NewDecoder(resp.Body).Decode(&data)
+	return data.Response, reqInf, err
+}
+
+// CreateInvalidationJobSchedule creates a deferred or recurring Content Invalidation Job.
+func (to *Session) CreateInvalidationJobSchedule(job tc.InvalidationJobScheduleInput) (tc.InvalidationJobSchedule, tc.Alerts, ReqInf, error) {
+	remoteAddr := (net.Addr)(nil)
+	reqBody, err := json.Marshal(job)
+	reqInf := ReqInf{CacheHitStatus: CacheHitStatusMiss, RemoteAddr: remoteAddr}
+	if err != nil {
+		return tc.InvalidationJobSchedule{}, tc.Alerts{}, reqInf, err
+	}
+	resp, remoteAddr, err := to.request(http.MethodPost, apiBase+`/jobs/schedules`, reqBody)
+	reqInf.RemoteAddr = remoteAddr
+	if err != nil {
+		return tc.InvalidationJobSchedule{}, tc.Alerts{}, reqInf, err
+	}
+	defer resp.Body.Close()
+
+	data := struct {
+		tc.Alerts
+		Response tc.InvalidationJobSchedule `json:"response"`
+	}{}
+	err = json.NewDecoder(resp.Body).Decode(&data)
+	return data.Response, data.Alerts, reqInf, err
+}
+
+// GetInvalidationJobSchedules returns all of the deferred and recurring Content Invalidation Jobs
+// visible to your Tenant.
+func (to *Session) GetInvalidationJobSchedules() ([]tc.InvalidationJobSchedule, ReqInf, error) {
+	resp, remoteAddr, err := to.request(http.MethodGet, apiBase+`/jobs/schedules`, nil)
+	reqInf := ReqInf{CacheHitStatus: CacheHitStatusMiss, RemoteAddr: remoteAddr}
+	if err != nil {
+		return nil, reqInf, err
+	}
+	defer resp.Body.Close()
+
+	data := struct {
+		Response []tc.InvalidationJobSchedule `json:"response"`
+	}{}
+	err = json.NewDecoder(resp.Body).Decode(&data)
+	return data.Response, reqInf, err
+}
+
+// DeleteInvalidationJobSchedule deletes the deferred or recurring Content Invalidation Job
+// identified by id. Jobs it has already created are unaffected.
+func (to *Session) DeleteInvalidationJobSchedule(id uint64) (tc.Alerts, ReqInf, error) {
+	resp, remoteAddr, err := to.request(http.MethodDelete, apiBase+"/jobs/schedules?id="+strconv.FormatUint(id, 10), nil)
+	reqInf := ReqInf{CacheHitStatus: CacheHitStatusMiss, RemoteAddr: remoteAddr}
+	if err != nil {
+		return tc.Alerts{}, reqInf, err
+	}
+	defer resp.Body.Close()
+	var alerts tc.Alerts
+	err = json.NewDecoder(resp.Body).Decode(&alerts)
+	return alerts, reqInf, err
+}
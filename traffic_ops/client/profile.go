@@ -97,19 +97,18 @@ func (to *Session) UpdateProfileByID(id int, pl tc.Profile) (tc.Alerts, ReqInf,
 
 // GetParametersByProfileName gets all of the Parameters assigned to the Profile named 'profileName'.
 func (to *Session) GetParametersByProfileName(profileName string) ([]tc.Parameter, ReqInf, error) {
-	url := fmt.Sprintf(API_PROFILES_NAME_PARAMETERS, profileName)
-	resp, remoteAddr, err := to.request(http.MethodGet, url, nil)
-	reqInf := ReqInf{CacheHitStatus: CacheHitStatusMiss, RemoteAddr: remoteAddr}
-	if err != nil {
-		return nil, reqInf, err
-	}
-	defer resp.Body.Close()
+	return to.GetParametersByProfileNameWithHdr(profileName, nil)
+}
 
-	var data tc.ParametersResponse
-	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+// GetParametersByProfileNameWithHdr is like GetParametersByProfileName, but also sends the given headers, such as If-None-Match.
+// If Traffic Ops returns a 304 Not Modified, the returned Parameters are nil and the ReqInf StatusCode is 304.
+func (to *Session) GetParametersByProfileNameWithHdr(profileName string, header http.Header) ([]tc.Parameter, ReqInf, error) {
+	url := fmt.Sprintf(API_PROFILES_NAME_PARAMETERS, profileName)
+	data := tc.ParametersResponse{}
+	reqInf, err := getWithHdr(to, url, header, &data)
+	if err != nil || reqInf.StatusCode == http.StatusNotModified {
 		return nil, reqInf, err
 	}
-
 	return data.Response, reqInf, nil
 }
 
@@ -148,18 +147,19 @@ func (to *Session) GetProfileByID(id int) ([]tc.Profile, ReqInf, error) {
 
 // GetProfileByName GETs a Profile by the Profile name.
 func (to *Session) GetProfileByName(name string) ([]tc.Profile, ReqInf, error) {
+	return to.GetProfileByNameWithHdr(name, nil)
+}
+
+// GetProfileByNameWithHdr is like GetProfileByName, but also sends the given headers, such as If-None-Match.
+// If Traffic Ops returns a 304 Not Modified, the returned Profiles are nil and the ReqInf StatusCode is 304.
+func (to *Session) GetProfileByNameWithHdr(name string, header http.Header) ([]tc.Profile, ReqInf, error) {
 	URI := fmt.Sprintf("%s?name=%s", API_PROFILES, url.QueryEscape(name))
-	resp, remoteAddr, err := to.request(http.MethodGet, URI, nil)
-	reqInf := ReqInf{CacheHitStatus: CacheHitStatusMiss, RemoteAddr: remoteAddr}
-	if err != nil {
+	data := tc.ProfilesResponse{}
+	reqInf, err := getWithHdr(to, URI, header, &data)
+	if err != nil || reqInf.StatusCode == http.StatusNotModified {
 		return nil, reqInf, err
 	}
-	defer resp.Body.Close()
-
-	var data tc.ProfilesResponse
-	err = json.NewDecoder(resp.Body).Decode(&data)
-
-	return data.Response, reqInf, err
+	return data.Response, reqInf, nil
 }
 
 // GetProfileByParameter GETs a Profile by the Profile "param".
@@ -38,102 +38,110 @@ func (to *Session) CreateServer(server tc.Server) (tc.Alerts, ReqInf, error) {
 	var remoteAddr net.Addr
 	reqInf := ReqInf{CacheHitStatus: CacheHitStatusMiss, RemoteAddr: remoteAddr}
 
+	if err := to.setServerIDs(&server); err != nil {
+		return tc.Alerts{}, ReqInf{}, err
+	}
+	reqBody, err := json.Marshal(server)
+	if err != nil {
+		return tc.Alerts{}, reqInf, err
+	}
+
+	resp, remoteAddr, err := to.request(http.MethodPost, API_SERVERS, reqBody)
+	if err != nil {
+		return tc.Alerts{}, reqInf, err
+	}
+	defer resp.Body.Close()
+	var alerts tc.Alerts
+	err = json.NewDecoder(resp.Body).Decode(&alerts)
+	return alerts, reqInf, nil
+}
+
+// setServerIDs sets the IDs of the server's Cache Group, CDN, Physical Location, Profile, Status
+// and Type from their names, for any which are given by name only.
+func (to *Session) setServerIDs(server *tc.Server) error {
 	if server.CachegroupID == 0 && server.Cachegroup != "" {
 		cg, _, err := to.GetCacheGroupNullableByName(server.Cachegroup)
 		if err != nil {
-			return tc.Alerts{}, ReqInf{}, errors.New("no cachegroup named " + server.Cachegroup + ":" + err.Error())
+			return errors.New("no cachegroup named " + server.Cachegroup + ":" + err.Error())
 		}
 		if len(cg) == 0 {
-			return tc.Alerts{}, ReqInf{}, errors.New("no cachegroup named " + server.Cachegroup)
+			return errors.New("no cachegroup named " + server.Cachegroup)
 		}
 		if cg[0].ID == nil {
-			return tc.Alerts{}, ReqInf{}, errors.New("Cachegroup named " + server.Cachegroup + " has a nil ID")
+			return errors.New("Cachegroup named " + server.Cachegroup + " has a nil ID")
 		}
 		server.CachegroupID = *cg[0].ID
 	}
 	if server.CDNID == 0 && server.CDNName != "" {
 		c, _, err := to.GetCDNByName(server.CDNName)
 		if err != nil {
-			return tc.Alerts{}, ReqInf{}, errors.New("no CDN named " + server.CDNName + ":" + err.Error())
+			return errors.New("no CDN named " + server.CDNName + ":" + err.Error())
 		}
 		if len(c) == 0 {
-			return tc.Alerts{}, ReqInf{}, errors.New("no CDN named " + server.CDNName)
+			return errors.New("no CDN named " + server.CDNName)
 		}
 		server.CDNID = c[0].ID
 	}
 	if server.PhysLocationID == 0 && server.PhysLocation != "" {
 		ph, _, err := to.GetPhysLocationByName(server.PhysLocation)
 		if err != nil {
-			return tc.Alerts{}, ReqInf{}, errors.New("no physlocation named " + server.PhysLocation + ":" + err.Error())
+			return errors.New("no physlocation named " + server.PhysLocation + ":" + err.Error())
 		}
 		if len(ph) == 0 {
-			return tc.Alerts{}, ReqInf{}, errors.New("no physlocation named " + server.PhysLocation)
+			return errors.New("no physlocation named " + server.PhysLocation)
 		}
 		server.PhysLocationID = ph[0].ID
 	}
 	if server.ProfileID == 0 && server.Profile != "" {
 		pr, _, err := to.GetProfileByName(server.Profile)
 		if err != nil {
-			return tc.Alerts{}, ReqInf{}, errors.New("no profile named " + server.Profile + ":" + err.Error())
+			return errors.New("no profile named " + server.Profile + ":" + err.Error())
 		}
 		if len(pr) == 0 {
-			return tc.Alerts{}, ReqInf{}, errors.New("no profile named " + server.Profile)
+			return errors.New("no profile named " + server.Profile)
 		}
 		server.ProfileID = pr[0].ID
 	}
 	if server.StatusID == 0 && server.Status != "" {
 		st, _, err := to.GetStatusByName(server.Status)
 		if err != nil {
-			return tc.Alerts{}, ReqInf{}, errors.New("no status named " + server.Status + ":" + err.Error())
+			return errors.New("no status named " + server.Status + ":" + err.Error())
 		}
 		if len(st) == 0 {
-			return tc.Alerts{}, ReqInf{}, errors.New("no status named " + server.Status)
+			return errors.New("no status named " + server.Status)
 		}
 		server.StatusID = st[0].ID
 	}
 	if server.TypeID == 0 && server.Type != "" {
 		ty, _, err := to.GetTypeByName(server.Type)
 		if err != nil {
-			return tc.Alerts{}, ReqInf{}, errors.New("no type named " + server.Type + ":" + err.Error())
+			return errors.New("no type named " + server.Type + ":" + err.Error())
 		}
 		if len(ty) == 0 {
-			return tc.Alerts{}, ReqInf{}, errors.New("no type named " + server.Type)
+			return errors.New("no type named " + server.Type)
 		}
 		server.TypeID = ty[0].ID
 	}
-	reqBody, err := json.Marshal(server)
-	if err != nil {
-		return tc.Alerts{}, reqInf, err
-	}
-
-	resp, remoteAddr, err := to.request(http.MethodPost, API_SERVERS, reqBody)
-	if err != nil {
-		return tc.Alerts{}, reqInf, err
-	}
-	defer resp.Body.Close()
-	var alerts tc.Alerts
-	err = json.NewDecoder(resp.Body).Decode(&alerts)
-	return alerts, reqInf, nil
+	return nil
 }
 
 // UpdateServerByID updates a Server by ID.
 func (to *Session) UpdateServerByID(id int, server tc.Server) (tc.Alerts, ReqInf, error) {
+	return to.UpdateServerByIDWithHdr(id, server, nil)
+}
 
-	var remoteAddr net.Addr
+// UpdateServerByIDWithHdr is like UpdateServerByID, but also sends the given headers, such as If-Match or If-Unmodified-Since.
+// If the Server was changed since the given ETag or time, Traffic Ops returns an error, and the ReqInf StatusCode is 412.
+func (to *Session) UpdateServerByIDWithHdr(id int, server tc.Server, header http.Header) (tc.Alerts, ReqInf, error) {
+	reqInf := ReqInf{CacheHitStatus: CacheHitStatusMiss}
 	reqBody, err := json.Marshal(server)
-	reqInf := ReqInf{CacheHitStatus: CacheHitStatusMiss, RemoteAddr: remoteAddr}
 	if err != nil {
 		return tc.Alerts{}, reqInf, err
 	}
 	route := fmt.Sprintf("%s/%d", API_SERVERS, id)
-	resp, remoteAddr, err := to.request(http.MethodPut, route, reqBody)
-	if err != nil {
-		return tc.Alerts{}, reqInf, err
-	}
-	defer resp.Body.Close()
-	var alerts tc.Alerts
-	err = json.NewDecoder(resp.Body).Decode(&alerts)
-	return alerts, reqInf, nil
+	alerts := tc.Alerts{}
+	reqInf, err = putWithHdr(to, route, reqBody, header, &alerts)
+	return alerts, reqInf, err
 }
 
 // GetServers returns a list of Servers.
@@ -188,16 +196,16 @@ func (to *Session) GetServerByHostName(hostName string) ([]tc.Server, ReqInf, er
 
 // DeleteServerByID DELETEs a Server by ID.
 func (to *Session) DeleteServerByID(id int) (tc.Alerts, ReqInf, error) {
+	return to.DeleteServerByIDWithHdr(id, nil)
+}
+
+// DeleteServerByIDWithHdr is like DeleteServerByID, but also sends the given headers, such as If-Match or If-Unmodified-Since.
+// If the Server was changed since the given ETag or time, Traffic Ops returns an error, and the ReqInf StatusCode is 412.
+func (to *Session) DeleteServerByIDWithHdr(id int, header http.Header) (tc.Alerts, ReqInf, error) {
 	route := fmt.Sprintf("%s/%d", API_SERVERS, id)
-	resp, remoteAddr, err := to.request(http.MethodDelete, route, nil)
-	reqInf := ReqInf{CacheHitStatus: CacheHitStatusMiss, RemoteAddr: remoteAddr}
-	if err != nil {
-		return tc.Alerts{}, reqInf, err
-	}
-	defer resp.Body.Close()
-	var alerts tc.Alerts
-	err = json.NewDecoder(resp.Body).Decode(&alerts)
-	return alerts, reqInf, nil
+	alerts := tc.Alerts{}
+	reqInf, err := delWithHdr(to, route, header, &alerts)
+	return alerts, reqInf, err
 }
 
 // GetServersByType returns all servers that match the given query parameter filters, NOT
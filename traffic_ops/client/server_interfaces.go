@@ -0,0 +1,93 @@
+package client
+
+/*
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+// API_SERVERS_WITH_INTERFACES is the servers endpoint of the API version in which servers have a
+// list of network interfaces.
+const API_SERVERS_WITH_INTERFACES = apiBaseV21 + "/servers"
+
+// GetServersWithInterfaces returns the servers matching the given query parameters, which may be
+// nil, including their network interfaces.
+func (to *Session) GetServersWithInterfaces(params url.Values) ([]tc.Server, ReqInf, error) {
+	return to.GetServersWithInterfacesWithHdr(params, nil)
+}
+
+// GetServersWithInterfacesWithHdr is like GetServersWithInterfaces, but also sends the given headers, such as If-None-Match.
+// If Traffic Ops returns a 304 Not Modified, the returned servers are nil and the ReqInf StatusCode is 304.
+func (to *Session) GetServersWithInterfacesWithHdr(params url.Values, header http.Header) ([]tc.Server, ReqInf, error) {
+	route := API_SERVERS_WITH_INTERFACES
+	if len(params) > 0 {
+		route += "?" + params.Encode()
+	}
+	data := tc.ServersResponse{}
+	reqInf, err := getWithHdr(to, route, header, &data)
+	if err != nil || reqInf.StatusCode == http.StatusNotModified {
+		return nil, reqInf, err
+	}
+	return data.Response, reqInf, nil
+}
+
+// CreateServerWithInterfaces creates a server with the given network interfaces. The legacy
+// interface and address fields are ignored, because Traffic Ops sets them from the service
+// interface.
+func (to *Session) CreateServerWithInterfaces(server tc.Server) (tc.Alerts, ReqInf, error) {
+	reqInf := ReqInf{CacheHitStatus: CacheHitStatusMiss}
+	if err := to.setServerIDs(&server); err != nil {
+		return tc.Alerts{}, reqInf, err
+	}
+	reqBody, err := json.Marshal(server)
+	if err != nil {
+		return tc.Alerts{}, reqInf, err
+	}
+	resp, remoteAddr, err := to.request(http.MethodPost, API_SERVERS_WITH_INTERFACES, reqBody)
+	reqInf.RemoteAddr = remoteAddr
+	if err != nil {
+		return tc.Alerts{}, reqInf, err
+	}
+	defer resp.Body.Close()
+
+	alerts := tc.Alerts{}
+	err = json.NewDecoder(resp.Body).Decode(&alerts)
+	return alerts, reqInf, err
+}
+
+// UpdateServerWithInterfacesByID replaces the server with the given ID, including all its network
+// interfaces.
+func (to *Session) UpdateServerWithInterfacesByID(id int, server tc.Server) (tc.Alerts, ReqInf, error) {
+	return to.UpdateServerWithInterfacesByIDWithHdr(id, server, nil)
+}
+
+// UpdateServerWithInterfacesByIDWithHdr is like UpdateServerWithInterfacesByID, but also sends the given headers, such as If-Match or If-Unmodified-Since.
+// If the Server was changed since the given ETag or time, Traffic Ops returns an error, and the ReqInf StatusCode is 412.
+func (to *Session) UpdateServerWithInterfacesByIDWithHdr(id int, server tc.Server, header http.Header) (tc.Alerts, ReqInf, error) {
+	reqInf := ReqInf{CacheHitStatus: CacheHitStatusMiss}
+	reqBody, err := json.Marshal(server)
+	if err != nil {
+		return tc.Alerts{}, reqInf, err
+	}
+	route := fmt.Sprintf("%s/%d", API_SERVERS_WITH_INTERFACES, id)
+	alerts := tc.Alerts{}
+	reqInf, err = putWithHdr(to, route, reqBody, header, &alerts)
+	return alerts, reqInf, err
+}
@@ -25,6 +25,12 @@ import (
 
 // UpdateServerStatus updates a server's status and returns the response.
 func (to *Session) UpdateServerStatus(serverID int, req tc.ServerPutStatus) (*tc.Alerts, ReqInf, error) {
+	return to.UpdateServerStatusWithHdr(serverID, req, nil)
+}
+
+// UpdateServerStatusWithHdr is like UpdateServerStatus, but also sends the given headers, such as If-Match or If-Unmodified-Since.
+// If the server was changed since the given ETag or time, Traffic Ops returns an error, and the ReqInf StatusCode is 412.
+func (to *Session) UpdateServerStatusWithHdr(serverID int, req tc.ServerPutStatus, header http.Header) (*tc.Alerts, ReqInf, error) {
 	reqInf := ReqInf{CacheHitStatus: CacheHitStatusMiss}
 
 	reqBody, err := json.Marshal(req)
@@ -33,18 +39,11 @@ func (to *Session) UpdateServerStatus(serverID int, req tc.ServerPutStatus) (*tc
 	}
 
 	path := fmt.Sprintf("%s/servers/%d/status", apiBase, serverID)
-	resp, remoteAddr, err := to.request(http.MethodPut, path, reqBody)
-	reqInf.RemoteAddr = remoteAddr
-	if err != nil {
-		return nil, reqInf, err
-	}
-	defer resp.Body.Close()
-
 	alerts := tc.Alerts{}
-	if err = json.NewDecoder(resp.Body).Decode(&alerts); err != nil {
+	reqInf, err = putWithHdr(to, path, reqBody, header, &alerts)
+	if err != nil {
 		return nil, reqInf, err
 	}
-
 	return &alerts, reqInf, nil
 }
 
@@ -17,7 +17,9 @@ package client
 
 import (
 	"encoding/json"
+	"fmt"
 	"net"
+	"net/url"
 
 	"github.com/apache/trafficcontrol/lib/go-tc"
 )
@@ -51,3 +53,59 @@ func (to *Session) GetServersChecks() ([]tc.GenericServerCheck, tc.Alerts, ReqIn
 	reqInf, err := get(to, API_SERVERCHECK, &response)
 	return response.Response, response.Alerts, reqInf, err
 }
+
+// GetServerCheckHistory fetches the retained check results of the server with the given ID, most
+// recent first. If checkName is not empty, only the results of that check are returned.
+func (to *Session) GetServerCheckHistory(serverID int, checkName string) ([]tc.ServercheckResult, ReqInf, error) {
+	uri := fmt.Sprintf("%s/%d/checks", API_SERVERS, serverID)
+	if checkName != "" {
+		uri += "?name=" + url.QueryEscape(checkName)
+	}
+	resp := tc.ServercheckHistoryResponse{}
+	reqInf, err := get(to, uri, &resp)
+	return resp.Response, reqInf, err
+}
+
+// GetServerCheckRules fetches all servercheck rules.
+func (to *Session) GetServerCheckRules() ([]tc.ServercheckRule, ReqInf, error) {
+	resp := tc.ServercheckRulesResponse{}
+	reqInf, err := get(to, API_SERVERCHECK+"/rules", &resp)
+	return resp.Response, reqInf, err
+}
+
+// GetServerCheckRulesByCheckName fetches the servercheck rules for the check extension with the
+// given short name.
+func (to *Session) GetServerCheckRulesByCheckName(checkName string) ([]tc.ServercheckRule, ReqInf, error) {
+	resp := tc.ServercheckRulesResponse{}
+	reqInf, err := get(to, API_SERVERCHECK+"/rules?checkName="+url.QueryEscape(checkName), &resp)
+	return resp.Response, reqInf, err
+}
+
+// CreateServerCheckRule creates the given servercheck rule.
+func (to *Session) CreateServerCheckRule(rule tc.ServercheckRule) (tc.Alerts, ReqInf, error) {
+	alerts := tc.Alerts{}
+	reqBody, err := json.Marshal(rule)
+	if err != nil {
+		return alerts, ReqInf{CacheHitStatus: CacheHitStatusMiss}, err
+	}
+	reqInf, err := post(to, API_SERVERCHECK+"/rules", reqBody, &alerts)
+	return alerts, reqInf, err
+}
+
+// UpdateServerCheckRuleByID replaces the servercheck rule with the given ID.
+func (to *Session) UpdateServerCheckRuleByID(id int, rule tc.ServercheckRule) (tc.Alerts, ReqInf, error) {
+	alerts := tc.Alerts{}
+	reqBody, err := json.Marshal(rule)
+	if err != nil {
+		return alerts, ReqInf{CacheHitStatus: CacheHitStatusMiss}, err
+	}
+	reqInf, err := put(to, fmt.Sprintf("%s/rules/%d", API_SERVERCHECK, id), reqBody, &alerts)
+	return alerts, reqInf, err
+}
+
+// DeleteServerCheckRuleByID deletes the servercheck rule with the given ID.
+func (to *Session) DeleteServerCheckRuleByID(id int) (tc.Alerts, ReqInf, error) {
+	alerts := tc.Alerts{}
+	reqInf, err := del(to, fmt.Sprintf("%s/rules/%d", API_SERVERCHECK, id), &alerts)
+	return alerts, reqInf, err
+}
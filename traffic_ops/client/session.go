@@ -18,6 +18,7 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"errors"
@@ -27,12 +28,14 @@ import (
 	"net/http"
 	"net/http/cookiejar"
 	"net/http/httptrace"
+	"net/url"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	tc "github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
 
 	"golang.org/x/net/publicsuffix"
 )
@@ -47,6 +50,9 @@ type Session struct {
 	cacheMutex   *sync.RWMutex
 	useCache     bool
 	UserAgentStr string
+	// Retry is how idempotent requests are retried, if they fail with a network error or a status which means Traffic Ops may succeed later. The zero value never retries.
+	Retry RetryOptions
+	ctx   context.Context
 }
 
 func NewSession(user, password, url, userAgent string, client *http.Client, useCache bool) *Session {
@@ -64,19 +70,84 @@ func NewSession(user, password, url, userAgent string, client *http.Client, useC
 
 const DefaultTimeout = time.Second * time.Duration(30)
 
-// HTTPError is returned on Update Session failure.
+// RetryOptions configures retrying requests to Traffic Ops.
+//
+// Only idempotent requests - GET, HEAD, OPTIONS, PUT and DELETE - are retried, and only if they fail with a network error, or a 429 Too Many Requests, 502 Bad Gateway, 503 Service Unavailable or 504 Gateway Timeout. Retries wait the longer of an exponential backoff and the response's Retry-After, and stop when the Session's Context is done.
+type RetryOptions struct {
+	// MaxRetries is the most times a request is retried after the first attempt. 0 never retries.
+	MaxRetries int
+	// MinBackoff is how long to wait before the first retry. If 0, DefaultRetryMinBackoff is used.
+	MinBackoff time.Duration
+	// MaxBackoff is the longest to wait between retries. If 0, DefaultRetryMaxBackoff is used.
+	MaxBackoff time.Duration
+	// Factor is how much the wait grows with each retry. If 0, util.DefaultFactor is used.
+	Factor float64
+}
+
+const DefaultRetryMinBackoff = time.Second
+const DefaultRetryMaxBackoff = time.Second * time.Duration(30)
+
+// DefaultRetryOptions is a reasonable RetryOptions for applications which want retries.
+var DefaultRetryOptions = RetryOptions{MaxRetries: 3}
+
+// newBackoff returns the backoff to wait between retries of a single request.
+func (opts RetryOptions) newBackoff() util.Backoff {
+	min := opts.MinBackoff
+	if min == 0 {
+		min = DefaultRetryMinBackoff
+	}
+	max := opts.MaxBackoff
+	if max == 0 {
+		max = DefaultRetryMaxBackoff
+	}
+	factor := opts.Factor
+	if factor == 0 {
+		factor = util.DefaultFactor
+	}
+	backoff, err := util.NewBackoff(min, max, factor)
+	if err != nil {
+		return util.NewConstantBackoff(min) // invalid options, e.g. a max less than the min
+	}
+	return backoff
+}
+
+// HTTPError is returned when Traffic Ops responds to a request with an error status code.
+// Callers can check the status code and alerts with a type assertion, for example:
+//     if httpErr, ok := err.(*client.HTTPError); ok && httpErr.HTTPStatusCode == http.StatusNotFound {
 type HTTPError struct {
 	HTTPStatusCode int
 	HTTPStatus     string
 	URL            string
 	Body           string
+	// Alerts are the alerts in the response Body, if any.
+	Alerts tc.Alerts
+	// CorrelationID is the correlation ID of the request, which identifies it in Traffic Ops's
+	// logs, if Traffic Ops returned one.
+	CorrelationID string
 }
 
+// ErrNotImplementedMsg is the error message of a 501 Not Implemented HTTPError.
+const ErrNotImplementedMsg = "Traffic Ops Server returned 'Not Implemented', this client is probably newer than Traffic Ops, and you probably need to either upgrade Traffic Ops, or use a client whose version matches your Traffic Ops version."
+
 // Error implements the error interface for our customer error type.
 func (e *HTTPError) Error() string {
+	if e.HTTPStatusCode == http.StatusNotImplemented {
+		return ErrNotImplementedMsg
+	}
 	return fmt.Sprintf("%s[%d] - Error requesting Traffic Ops %s %s", e.HTTPStatus, e.HTTPStatusCode, e.URL, e.Body)
 }
 
+// ErrorAlerts returns the text of the error-level alerts Traffic Ops responded with, if any.
+func (e *HTTPError) ErrorAlerts() []string {
+	errs := []string{}
+	for _, alert := range e.Alerts.Alerts {
+		if alert.Level == tc.ErrorLevel.String() {
+			errs = append(errs, alert.Text)
+		}
+	}
+	return errs
+}
+
 // CacheEntry ...
 type CacheEntry struct {
 	Entered    int64
@@ -307,54 +378,162 @@ func NewNoAuthSession(toURL string, insecure bool, userAgent string, useCache bo
 	}, useCache)
 }
 
-// ErrUnlessOk returns nil and an error if the given Response's status code is anything but 200 OK. This includes reading the Response.Body and Closing it. Otherwise, the given response and error are returned unchanged.
+// ErrUnlessOk returns nil and an *HTTPError if the given Response's status code is anything but 200 OK. This includes reading the Response.Body and Closing it. Otherwise, the given response and error are returned unchanged.
 func (to *Session) ErrUnlessOK(resp *http.Response, remoteAddr net.Addr, err error, path string) (*http.Response, net.Addr, error) {
 	if err != nil {
 		return resp, remoteAddr, err
 	}
-	if resp.StatusCode < 300 {
+	if resp.StatusCode < 300 || resp.StatusCode == http.StatusNotModified {
 		return resp, remoteAddr, err
 	}
 
 	defer resp.Body.Close()
 
+	httpErr := &HTTPError{
+		HTTPStatusCode: resp.StatusCode,
+		HTTPStatus:     resp.Status,
+		URL:            to.getURL(path),
+		CorrelationID:  resp.Header.Get(tc.CorrelationIDHeader),
+	}
 	if resp.StatusCode == http.StatusNotImplemented {
-		return nil, remoteAddr, errors.New("Traffic Ops Server returned 'Not Implemented', this client is probably newer than Traffic Ops, and you probably need to either upgrade Traffic Ops, or use a client whose version matches your Traffic Ops version.")
+		return nil, remoteAddr, httpErr
 	}
 
 	body, readErr := ioutil.ReadAll(resp.Body)
 	if readErr != nil {
 		return nil, remoteAddr, readErr
 	}
-	return nil, remoteAddr, errors.New(resp.Status + "[" + strconv.Itoa(resp.StatusCode) + "] - Error requesting Traffic Ops " + to.getURL(path) + " " + string(body))
+	httpErr.Body = string(body)
+	json.Unmarshal(body, &httpErr.Alerts) // not all errors have alerts, e.g. from a proxy
+	return nil, remoteAddr, httpErr
 }
 
 func (to *Session) getURL(path string) string { return to.URL + path }
 
-// request performs the HTTP request to Traffic Ops, trying to refresh the cookie if an Unauthorized or Forbidden code is received. It only tries once. If the login fails, the original Unauthorized/Forbidden response is returned. If the login succeeds and the subsequent re-request fails, the re-request's response is returned even if it's another Unauthorized/Forbidden.
+// WithContext returns a shallow copy of the Session, whose requests use the given Context, which must not be nil.
+// This makes a context-taking variant of every Session method, for example to.WithContext(ctx).GetServers(). Requests are canceled, and retries stopped, when the Context is done.
+// The copy shares its HTTP client, login cookie and cache with the original.
+func (to *Session) WithContext(ctx context.Context) *Session {
+	if ctx == nil {
+		panic("nil context")
+	}
+	to2 := *to
+	to2.ctx = ctx
+	return &to2
+}
+
+// Context returns the Session's Context, which is the background Context unless the Session was made with WithContext.
+func (to *Session) Context() context.Context {
+	if to.ctx != nil {
+		return to.ctx
+	}
+	return context.Background()
+}
+
+// request performs the HTTP request to Traffic Ops, retrying it per the Session's Retry options, and trying to refresh the cookie if an Unauthorized or Forbidden code is received. It only tries to refresh the cookie once per attempt. If the login fails, the original Unauthorized/Forbidden response is returned. If the login succeeds and the subsequent re-request fails, the re-request's response is returned even if it's another Unauthorized/Forbidden.
 // Returns the response, the remote address of the Traffic Ops instance used, and any error.
 // The returned net.Addr is guaranteed to be either nil or valid, even if the returned error is not nil. Callers are encouraged to check and use the net.Addr if an error is returned, and use the remote address in their own error messages. This violates the Go idiom that a non-nil error implies all other values are undefined, but it's more straightforward than alternatives like typecasting.
 func (to *Session) request(method, path string, body []byte) (*http.Response, net.Addr, error) {
-	r, remoteAddr, err := to.RawRequest(method, path, body)
+	resp, reqInf, err := to.requestWithHdr(method, path, body, nil)
+	return resp, reqInf.RemoteAddr, err
+}
+
+// requestWithHdr is like request, but also sends the given headers, which may be nil.
+// The returned ReqInf has the status code and headers of the response, even if the returned error is not nil, so callers can tell why a request failed, for example with a 412 Precondition Failed.
+func (to *Session) requestWithHdr(method, path string, body []byte, header http.Header) (*http.Response, ReqInf, error) {
+	backoff := util.Backoff(nil)
+	for retry := 0; ; retry++ {
+		r, remoteAddr, err := to.RawRequestWithHdr(method, path, body, header)
+		if err == nil && (r.StatusCode == http.StatusUnauthorized || r.StatusCode == http.StatusForbidden) && to.UserName != "" {
+			if _, lerr := to.login(); lerr == nil {
+				r.Body.Close()
+				// use the second request, even if it's another Unauthorized or Forbidden.
+				r, remoteAddr, err = to.RawRequestWithHdr(method, path, body, header)
+			}
+			// if re-logging-in fails, use the original request's response
+		}
+
+		if retry < to.Retry.MaxRetries && retryable(method, r, err) && to.Context().Err() == nil {
+			if backoff == nil {
+				backoff = to.Retry.newBackoff()
+			}
+			wait := backoff.BackoffDuration()
+			if r != nil {
+				if retryAfter := parseRetryAfter(r.Header.Get("Retry-After")); retryAfter > wait {
+					wait = retryAfter
+				}
+				ioutil.ReadAll(r.Body) // read the body, so the connection can be reused
+				r.Body.Close()
+			}
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+				continue
+			case <-to.Context().Done():
+				timer.Stop()
+				return nil, newReqInf(nil, remoteAddr), to.Context().Err()
+			}
+		}
+
+		reqInf := newReqInf(r, remoteAddr)
+		r, remoteAddr, err = to.ErrUnlessOK(r, remoteAddr, err, path)
+		return r, reqInf, err
+	}
+}
+
+// retryable returns whether a request with the given method, which got the given response and error, should be retried.
+func retryable(method string, resp *http.Response, err error) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+	default:
+		return false
+	}
 	if err != nil {
-		return r, remoteAddr, err
+		// http.Client.Do only returns *url.Errors, so any other error is from building the request, which would fail again.
+		urlErr, ok := err.(*url.Error)
+		return ok && urlErr.Op != "parse"
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter returns the duration of a Retry-After header, which may be a number of seconds or an HTTP date, or 0 if it's empty or invalid.
+func parseRetryAfter(retryAfter string) time.Duration {
+	if retryAfter == "" {
+		return 0
 	}
-	if r.StatusCode != http.StatusUnauthorized && r.StatusCode != http.StatusForbidden {
-		return to.ErrUnlessOK(r, remoteAddr, err, path)
+	if seconds, err := strconv.Atoi(retryAfter); err == nil {
+		return time.Duration(seconds) * time.Second
 	}
-	if _, lerr := to.login(); lerr != nil {
-		return to.ErrUnlessOK(r, remoteAddr, err, path) // if re-logging-in fails, return the original request's response
+	if t, err := http.ParseTime(retryAfter); err == nil {
+		return time.Until(t)
 	}
+	return 0
+}
 
-	// return second request, even if it's another Unauthorized or Forbidden.
-	r, remoteAddr, err = to.RawRequest(method, path, body)
-	return to.ErrUnlessOK(r, remoteAddr, err, path)
+// newReqInf returns the ReqInf of the given response, which may be nil.
+func newReqInf(resp *http.Response, remoteAddr net.Addr) ReqInf {
+	reqInf := ReqInf{CacheHitStatus: CacheHitStatusMiss, RemoteAddr: remoteAddr}
+	if resp != nil {
+		reqInf.StatusCode = resp.StatusCode
+		reqInf.RespHeaders = resp.Header
+	}
+	return reqInf
 }
 
 // RawRequest performs the actual HTTP request to Traffic Ops, simply, without trying to refresh the cookie if an Unauthorized code is returned.
 // Returns the response, the remote address of the Traffic Ops instance used, and any error.
 // The returned net.Addr is guaranteed to be either nil or valid, even if the returned error is not nil. Callers are encouraged to check and use the net.Addr if an error is returned, and use the remote address in their own error messages. This violates the Go idiom that a non-nil error implies all other values are undefined, but it's more straightforward than alternatives like typecasting.
 func (to *Session) RawRequest(method, path string, body []byte) (*http.Response, net.Addr, error) {
+	return to.RawRequestWithHdr(method, path, body, nil)
+}
+
+// RawRequestWithHdr is like RawRequest, but also sends the given headers, which may be nil.
+// This is most useful for conditional requests, with headers like If-None-Match and If-Modified-Since.
+func (to *Session) RawRequestWithHdr(method, path string, body []byte, header http.Header) (*http.Response, net.Addr, error) {
 	url := to.getURL(path)
 
 	var req *http.Request
@@ -379,8 +558,13 @@ func (to *Session) RawRequest(method, path string, body []byte) (*http.Response,
 			remoteAddr = connInfo.Conn.RemoteAddr()
 		},
 	}
-	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+	req = req.WithContext(httptrace.WithClientTrace(to.Context(), trace))
 
+	for name, vals := range header {
+		for _, val := range vals {
+			req.Header.Add(name, val)
+		}
+	}
 	req.Header.Set("User-Agent", to.UserAgentStr)
 
 	resp, err := to.Client.Do(req)
@@ -394,6 +578,10 @@ func (to *Session) RawRequest(method, path string, body []byte) (*http.Response,
 type ReqInf struct {
 	CacheHitStatus CacheHitStatus
 	RemoteAddr     net.Addr
+	// StatusCode is the HTTP status code of the response, if any. Callers making conditional requests should check it for 304 Not Modified, in which case no data is returned.
+	StatusCode int
+	// RespHeaders are the headers of the response, if any, such as ETag and Last-Modified.
+	RespHeaders http.Header
 }
 
 type CacheHitStatus string
@@ -0,0 +1,166 @@
+package client
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+// testRetryOptions retries quickly, so tests don't wait.
+var testRetryOptions = RetryOptions{MaxRetries: 2, MinBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+
+func TestRequestRetries(t *testing.T) {
+	tests := []struct {
+		name             string
+		method           string
+		statuses         []int
+		expectedRequests int32
+		expectErr        bool
+	}{
+		{"succeeds after unavailable", http.MethodGet, []int{http.StatusServiceUnavailable, http.StatusOK}, 2, false},
+		{"succeeds after rate limit", http.MethodPut, []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusOK}, 3, false},
+		{"stops after max retries", http.MethodDelete, []int{http.StatusGatewayTimeout, http.StatusGatewayTimeout, http.StatusGatewayTimeout, http.StatusOK}, 3, true},
+		{"never retries post", http.MethodPost, []int{http.StatusServiceUnavailable, http.StatusOK}, 1, true},
+		{"never retries client errors", http.MethodGet, []int{http.StatusNotFound, http.StatusOK}, 1, true},
+	}
+
+	for _, test := range tests {
+		requests := int32(0)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			i := atomic.AddInt32(&requests, 1) - 1
+			w.WriteHeader(test.statuses[i])
+			w.Write([]byte(`{"response":[]}`))
+		}))
+
+		to := NewSession("", "", srv.URL, "test", srv.Client(), false)
+		to.Retry = testRetryOptions
+		resp, _, err := to.request(test.method, "/", nil)
+		if test.expectErr && err == nil {
+			t.Errorf("request %s expected error, actual nil", test.name)
+		} else if !test.expectErr && err != nil {
+			t.Errorf("request %s expected no error, actual %v", test.name, err)
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		if requests != test.expectedRequests {
+			t.Errorf("request %s expected %d requests, actual %d", test.name, test.expectedRequests, requests)
+		}
+		srv.Close()
+	}
+}
+
+func TestRequestContextCanceled(t *testing.T) {
+	requests := int32(0)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	to := NewSession("", "", srv.URL, "test", srv.Client(), false)
+	to.Retry = testRetryOptions
+	start := time.Now()
+	_, _, err := to.WithContext(ctx).request(http.MethodGet, "/", nil)
+	if err != context.DeadlineExceeded {
+		t.Errorf("request with canceled context expected error %v, actual %v", context.DeadlineExceeded, err)
+	}
+	if time.Since(start) > 10*time.Second {
+		t.Errorf("request with canceled context expected to stop waiting for Retry-After")
+	}
+	if requests != 1 {
+		t.Errorf("request with canceled context expected 1 request, actual %d", requests)
+	}
+	if to.Context() != context.Background() {
+		t.Errorf("WithContext expected to leave the original Session's Context unchanged")
+	}
+}
+
+func TestRequestRelogin(t *testing.T) {
+	logins := int32(0)
+	loggedIn := int32(0)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == apiBase+"/user/login" {
+			atomic.AddInt32(&logins, 1)
+			atomic.StoreInt32(&loggedIn, 1)
+			json.NewEncoder(w).Encode(tc.CreateAlerts(tc.SuccessLevel, "Successfully logged in."))
+			return
+		}
+		if atomic.LoadInt32(&loggedIn) == 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"response":[]}`))
+	}))
+	defer srv.Close()
+
+	to, _, err := LoginWithAgent(srv.URL, "user", "pass", true, "test", false, DefaultTimeout)
+	if err != nil {
+		t.Fatalf("logging in: %v", err)
+	}
+	atomic.StoreInt32(&loggedIn, 0) // the login expires
+
+	resp, _, err := to.request(http.MethodGet, apiBase+"/cdns", nil)
+	if err != nil {
+		t.Fatalf("request with expired login expected no error, actual %v", err)
+	}
+	resp.Body.Close()
+	if logins != 2 {
+		t.Errorf("request with expired login expected to log in again, actual logins %d", logins)
+	}
+}
+
+func TestHTTPError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(tc.CreateAlerts(tc.ErrorLevel, "name cannot be blank"))
+	}))
+	defer srv.Close()
+
+	to := NewSession("", "", srv.URL, "test", srv.Client(), false)
+	resp := tc.Alerts{}
+	reqInf, err := post(to, "/things", []byte(`{}`), &resp)
+	httpErr, ok := err.(*HTTPError)
+	if !ok {
+		t.Fatalf("post with bad request expected *HTTPError, actual %T %v", err, err)
+	}
+	if httpErr.HTTPStatusCode != http.StatusBadRequest || reqInf.StatusCode != http.StatusBadRequest {
+		t.Errorf("post with bad request expected status %d, actual error %d request info %d", http.StatusBadRequest, httpErr.HTTPStatusCode, reqInf.StatusCode)
+	}
+	if alerts := httpErr.ErrorAlerts(); len(alerts) != 1 || alerts[0] != "name cannot be blank" {
+		t.Errorf("post with bad request expected alert 'name cannot be blank', actual %v", alerts)
+	}
+	if !strings.HasPrefix(err.Error(), "400 Bad Request[400] - Error requesting Traffic Ops "+srv.URL+"/things") {
+		t.Errorf("post with bad request expected the existing error message, actual %v", err)
+	}
+}
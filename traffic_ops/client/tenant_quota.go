@@ -0,0 +1,66 @@
+package client
+
+/*
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+// API_TENANTS_USAGE is the API path on which Tenants' resource usage is reported.
+const API_TENANTS_USAGE = API_TENANTS + "/usage"
+
+// GetTenantQuota returns the quota of the Tenant with the given ID. Limits which are not set are
+// nil.
+func (to *Session) GetTenantQuota(tenantID int) (tc.TenantQuota, ReqInf, error) {
+	data := tc.TenantQuotaResponse{}
+	reqInf, err := get(to, fmt.Sprintf(API_TENANT_ID+"/quota", tenantID), &data)
+	return data.Response, reqInf, err
+}
+
+// UpdateTenantQuota replaces the quota of the Tenant with the given ID.
+func (to *Session) UpdateTenantQuota(tenantID int, quota tc.TenantQuota) (tc.TenantQuotaResponse, ReqInf, error) {
+	reqBody, err := json.Marshal(quota)
+	if err != nil {
+		return tc.TenantQuotaResponse{}, ReqInf{CacheHitStatus: CacheHitStatusMiss}, err
+	}
+	data := tc.TenantQuotaResponse{}
+	reqInf, err := put(to, fmt.Sprintf(API_TENANT_ID+"/quota", tenantID), reqBody, &data)
+	return data, reqInf, err
+}
+
+// DeleteTenantQuota removes the quota of the Tenant with the given ID, leaving it unlimited.
+func (to *Session) DeleteTenantQuota(tenantID int) (tc.Alerts, ReqInf, error) {
+	alerts := tc.Alerts{}
+	reqInf, err := del(to, fmt.Sprintf(API_TENANT_ID+"/quota", tenantID), &alerts)
+	return alerts, reqInf, err
+}
+
+// GetTenantUsage returns the resource usage of every Tenant visible to the user.
+func (to *Session) GetTenantUsage() ([]tc.TenantUsage, ReqInf, error) {
+	data := tc.TenantUsageResponse{}
+	reqInf, err := get(to, API_TENANTS_USAGE, &data)
+	return data.Response, reqInf, err
+}
+
+// GetTenantUsageByID returns the resource usage of the Tenant with the given ID.
+func (to *Session) GetTenantUsageByID(tenantID int) ([]tc.TenantUsage, ReqInf, error) {
+	data := tc.TenantUsageResponse{}
+	reqInf, err := get(to, API_TENANTS_USAGE+"?id="+strconv.Itoa(tenantID), &data)
+	return data.Response, reqInf, err
+}
@@ -0,0 +1,69 @@
+package client
+
+/*
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"encoding/json"
+	"net/url"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+// API_TOPOLOGIES is the API path on which Topologies are managed.
+const API_TOPOLOGIES = apiBase + "/topologies"
+
+// GetTopologies returns all Topologies.
+func (to *Session) GetTopologies() ([]tc.Topology, ReqInf, error) {
+	data := tc.TopologiesResponse{}
+	reqInf, err := get(to, API_TOPOLOGIES, &data)
+	return data.Response, reqInf, err
+}
+
+// GetTopologyByName returns the Topology with the given name, in a slice which is empty if there is
+// no such Topology.
+func (to *Session) GetTopologyByName(name string) ([]tc.Topology, ReqInf, error) {
+	data := tc.TopologiesResponse{}
+	reqInf, err := get(to, API_TOPOLOGIES+"?name="+url.QueryEscape(name), &data)
+	return data.Response, reqInf, err
+}
+
+// CreateTopology creates the given Topology.
+func (to *Session) CreateTopology(topology tc.Topology) (tc.TopologyResponse, ReqInf, error) {
+	reqBody, err := json.Marshal(topology)
+	if err != nil {
+		return tc.TopologyResponse{}, ReqInf{CacheHitStatus: CacheHitStatusMiss}, err
+	}
+	data := tc.TopologyResponse{}
+	reqInf, err := post(to, API_TOPOLOGIES, reqBody, &data)
+	return data, reqInf, err
+}
+
+// UpdateTopology replaces the Topology with the given name. The Topology may be renamed.
+func (to *Session) UpdateTopology(name string, topology tc.Topology) (tc.TopologyResponse, ReqInf, error) {
+	reqBody, err := json.Marshal(topology)
+	if err != nil {
+		return tc.TopologyResponse{}, ReqInf{CacheHitStatus: CacheHitStatusMiss}, err
+	}
+	data := tc.TopologyResponse{}
+	reqInf, err := put(to, API_TOPOLOGIES+"?name="+url.QueryEscape(name), reqBody, &data)
+	return data, reqInf, err
+}
+
+// DeleteTopology deletes the Topology with the given name.
+func (to *Session) DeleteTopology(name string) (tc.Alerts, ReqInf, error) {
+	alerts := tc.Alerts{}
+	reqInf, err := del(to, API_TOPOLOGIES+"?name="+url.QueryEscape(name), &alerts)
+	return alerts, reqInf, err
+}
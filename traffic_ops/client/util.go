@@ -19,12 +19,17 @@ import (
 	"encoding/json"
 	"errors"
 	"io/ioutil"
+	"net/http"
 )
 
 func get(to *Session, endpoint string, respStruct interface{}) (ReqInf, error) {
 	return makeReq(to, "GET", endpoint, nil, respStruct)
 }
 
+func getWithHdr(to *Session, endpoint string, header http.Header, respStruct interface{}) (ReqInf, error) {
+	return makeReqWithHdr(to, "GET", endpoint, nil, header, respStruct)
+}
+
 func post(to *Session, endpoint string, body []byte, respStruct interface{}) (ReqInf, error) {
 	return makeReq(to, "POST", endpoint, body, respStruct)
 }
@@ -37,14 +42,31 @@ func del(to *Session, endpoint string, respStruct interface{}) (ReqInf, error) {
 	return makeReq(to, "DELETE", endpoint, nil, respStruct)
 }
 
+func putWithHdr(to *Session, endpoint string, body []byte, header http.Header, respStruct interface{}) (ReqInf, error) {
+	return makeReqWithHdr(to, "PUT", endpoint, body, header, respStruct)
+}
+
+func delWithHdr(to *Session, endpoint string, header http.Header, respStruct interface{}) (ReqInf, error) {
+	return makeReqWithHdr(to, "DELETE", endpoint, nil, header, respStruct)
+}
+
 func makeReq(to *Session, method, endpoint string, body []byte, respStruct interface{}) (ReqInf, error) {
-	resp, remoteAddr, err := to.request(method, endpoint, body) // TODO change to getBytesWithTTL
-	reqInf := ReqInf{RemoteAddr: remoteAddr, CacheHitStatus: CacheHitStatusMiss}
+	return makeReqWithHdr(to, method, endpoint, body, nil, respStruct)
+}
+
+// makeReqWithHdr is like makeReq, but also sends the given headers, which may be nil.
+// If the response is a 304 Not Modified, respStruct is left untouched, and the returned ReqInf has the StatusCode.
+func makeReqWithHdr(to *Session, method, endpoint string, body []byte, header http.Header, respStruct interface{}) (ReqInf, error) {
+	resp, reqInf, err := to.requestWithHdr(method, endpoint, body, header) // TODO change to getBytesWithTTL
 	if err != nil {
 		return reqInf, err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified {
+		return reqInf, nil
+	}
+
 	bts, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return reqInf, errors.New("reading body: " + err.Error())
@@ -0,0 +1,79 @@
+package client
+
+/*
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+const (
+	API_WEBHOOKS = apiBase + "/webhooks"
+)
+
+// GetWebhooks returns all Webhooks. Their secrets are never returned.
+func (to *Session) GetWebhooks() ([]tc.Webhook, ReqInf, error) {
+	data := tc.WebhooksResponse{}
+	reqInf, err := get(to, API_WEBHOOKS, &data)
+	return data.Response, reqInf, err
+}
+
+// GetWebhookByID returns the Webhook with the given ID, if it exists.
+func (to *Session) GetWebhookByID(id int) ([]tc.Webhook, ReqInf, error) {
+	data := tc.WebhooksResponse{}
+	reqInf, err := get(to, fmt.Sprintf("%s?id=%d", API_WEBHOOKS, id), &data)
+	return data.Response, reqInf, err
+}
+
+// GetWebhookByURL returns the Webhook(s) which deliver Events to the given URL.
+func (to *Session) GetWebhookByURL(u string) ([]tc.Webhook, ReqInf, error) {
+	data := tc.WebhooksResponse{}
+	reqInf, err := get(to, API_WEBHOOKS+"?url="+url.QueryEscape(u), &data)
+	return data.Response, reqInf, err
+}
+
+// CreateWebhook creates the given Webhook, which must have a Secret.
+func (to *Session) CreateWebhook(wh tc.Webhook) (tc.Alerts, ReqInf, error) {
+	alerts := tc.Alerts{}
+	reqBody, err := json.Marshal(wh)
+	if err != nil {
+		return alerts, ReqInf{CacheHitStatus: CacheHitStatusMiss}, err
+	}
+	reqInf, err := post(to, API_WEBHOOKS, reqBody, &alerts)
+	return alerts, reqInf, err
+}
+
+// UpdateWebhookByID replaces the Webhook with the given ID with wh. If wh has no Secret, the
+// existing Secret is kept.
+func (to *Session) UpdateWebhookByID(id int, wh tc.Webhook) (tc.Alerts, ReqInf, error) {
+	alerts := tc.Alerts{}
+	reqBody, err := json.Marshal(wh)
+	if err != nil {
+		return alerts, ReqInf{CacheHitStatus: CacheHitStatusMiss}, err
+	}
+	reqInf, err := put(to, fmt.Sprintf("%s/%d", API_WEBHOOKS, id), reqBody, &alerts)
+	return alerts, reqInf, err
+}
+
+// DeleteWebhookByID deletes the Webhook with the given ID. Any of its pending deliveries are
+// abandoned.
+func (to *Session) DeleteWebhookByID(id int) (tc.Alerts, ReqInf, error) {
+	alerts := tc.Alerts{}
+	reqInf, err := del(to, fmt.Sprintf("%s/%d", API_WEBHOOKS, id), &alerts)
+	return alerts, reqInf, err
+}
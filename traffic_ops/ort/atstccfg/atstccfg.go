@@ -4,17 +4,17 @@
 //
 // Usage:
 //
-// 	atstccfg [-u TO_URL] [-U TO_USER] [-P TO_PASSWORD] [-n] [-r N] [-e ERROR_LOCATION] [-w WARNING_LOCATION] [-i INFO_LOCATION] [-g] [-s] [-t TIMEOUT] [-a MAX_AGE] [-l] [-v] [-h]
+// 	atstccfg [-u TO_URL] [-U TO_USER] [-P TO_PASSWORD] [-n] [-r N] [-e ERROR_LOCATION] [-w WARNING_LOCATION] [-i INFO_LOCATION] [-g] [-s] [-t TIMEOUT] [--cache-dir CACHE_DIR] [--no-cache] [-l] [-v] [-h]
 //
 // The available options are:
 //
-// 	-a, --cache-file-max-age-seconds                                Sets the maximum age - in seconds - a cached response can be in order to be considered "fresh" - older files will be re-generated and cached. Default: 60
+// 	--cache-dir CACHE_DIR                                           The directory to cache Traffic Ops data in, to make conditional requests for it. Default: /tmp/atstccfg_cache
 // 	-e ERROR_LOCATION, --log-location-error ERROR_LOCATION          The file location to which to log errors. Respects the special string constants of github.com/apache/trafficcontrol/lib/go-log. Default: 'stderr'
 // 	-g, --print-generated-files                                     If given, the names of files generated (and not proxied to Traffic Ops) will be printed to stdout, then atstccfg will exit.
 // 	-h, --help                                                      Print usage information and exit.
 // 	-i INFO_LOCATION, --log-location-info INFO_LOCATION             The file location to which to log information messages. Respects the special string constants of github.com/apache/trafficcontrol/lib/go-log. Default: 'stderr'
 // 	-l, --list-plugins                                              List the loaded plugins and then exit.
-// 	-n, --no-cache                                                  If given, existing cache files will not be used. Cache files will still be created, existing ones just won't be used.
+// 	--no-cache                                                      If given, Traffic Ops data will not be cached, and all of it will be requested unconditionally.
 // 	-P TO_PASSWORD                                                  Authenticate using this password - if not given, atstccfg will attempt to use the value of the TO_PASS environment variable
 // 	-r N, --num-retries N                                           The number of times to retry getting a file if it fails. Default: 5
 // 	-s, --traffic-ops-insecure                                      If given, SSL certificate errors will be ignored when communicating with Traffic Ops. NOT RECOMMENDED FOR PRODUCTION ENVIRONMENTS.
@@ -24,7 +24,7 @@
 // 	-v, --version                                                   Print version information and exit.
 // 	-w WARNING_LOCATION, --log-location-warning WARNING_LOCATION    The file location to which to log warnings. Respects the special string constants of github.com/apache/trafficcontrol/lib/go-log. Default: 'stderr'
 //
-// atstccfg caches generated files in /tmp/atstccfg_cache/ for re-use.
+// atstccfg caches Traffic Ops data, with the ETag and Last-Modified time Traffic Ops returned for it, in /tmp/atstccfg_cache/. The next run asks Traffic Ops whether the data changed with If-None-Match and If-Modified-Since, and uses the cached copy if Traffic Ops responds Not Modified.
 
 package main
 
@@ -83,6 +83,11 @@ func main() {
 	}
 
 	toClientNew, err := toreqnew.New(toClient.Cookies(cfg.TOURL), cfg.TOURL, cfg.TOUser, cfg.TOPass, cfg.TOInsecure, cfg.TOTimeout, config.UserAgent)
+	if err != nil {
+		log.Errorln(err)
+		os.Exit(config.ExitCodeErrGeneric)
+	}
+	toClientNew.CacheDir = cfg.CacheDir
 
 	tccfg := config.TCCfg{Cfg: cfg, TOClient: toClient, TOClientNew: toClientNew}
 
@@ -47,7 +47,11 @@ func GetTOData(cfg config.TCCfg) (*config.TOData, error) {
 	serversF := func() error {
 		defer func(start time.Time) { log.Infof("serversF took %v\n", time.Since(start)) }(time.Now())
 		// TODO TOAPI add /servers?cdn=1 query param
-		servers, err := cfg.TOClient.GetServers()
+		servers, unsupported, err := cfg.TOClientNew.GetServers()
+		if err == nil && unsupported {
+			log.Warnln("Traffic Ops older than ORT, does not support server interfaces, getting servers without them!")
+			servers, err = cfg.TOClient.GetServers()
+		}
 		if err != nil {
 			return errors.New("getting servers: " + err.Error())
 		}
@@ -149,7 +153,11 @@ func GetTOData(cfg config.TCCfg) (*config.TOData, error) {
 		}
 		serverParamsF := func() error {
 			defer func(start time.Time) { log.Infof("serverParamsF took %v\n", time.Since(start)) }(time.Now())
-			params, err := cfg.TOClient.GetServerProfileParameters(server.Profile)
+			params, unsupported, err := cfg.TOClientNew.GetServerProfileParameters(server.Profile)
+			if err == nil && unsupported {
+				log.Warnln("Traffic Ops older than ORT, falling back to previous API Profile Parameters!")
+				params, err = cfg.TOClient.GetServerProfileParameters(server.Profile)
+			}
 			if err != nil {
 				return errors.New("getting server profile '" + server.Profile + "' parameters: " + err.Error())
 			} else if len(params) == 0 {
@@ -169,7 +177,11 @@ func GetTOData(cfg config.TCCfg) (*config.TOData, error) {
 		}
 		profileF := func() error {
 			defer func(start time.Time) { log.Infof("profileF took %v\n", time.Since(start)) }(time.Now())
-			profile, err := cfg.TOClient.GetProfileByName(server.Profile)
+			profile, unsupported, err := cfg.TOClientNew.GetProfileByName(server.Profile)
+			if err == nil && unsupported {
+				log.Warnln("Traffic Ops older than ORT, falling back to previous API Profiles!")
+				profile, err = cfg.TOClient.GetProfileByName(server.Profile)
+			}
 			if err != nil {
 				return errors.New("getting profile '" + server.Profile + "': " + err.Error())
 			}
@@ -279,10 +291,24 @@ func GetTOData(cfg config.TCCfg) (*config.TOData, error) {
 		return nil
 	}
 
+	topologiesF := func() error {
+		defer func(start time.Time) { log.Infof("topologiesF took %v\n", time.Since(start)) }(time.Now())
+		topologies, unsupported, err := cfg.TOClientNew.GetTopologies()
+		if err == nil && unsupported {
+			log.Warnln("Traffic Ops older than ORT, does not support Topologies, assuming there are none!")
+			topologies = []tc.Topology{}
+		}
+		if err != nil {
+			return errors.New("getting topologies: " + err.Error())
+		}
+		toData.Topologies = topologies
+		return nil
+	}
+
 	fs := []func() error{dssF, serversF, cgF, globalParamsF, scopeParamsF, jobsF}
 	if !cfg.RevalOnly {
 		// skip data not needed for reval, if we're reval-only
-		fs = append([]func() error{dsrF, cacheKeyParamsF, parentConfigParamsF, capsF, dsCapsF}, fs...)
+		fs = append([]func() error{dsrF, cacheKeyParamsF, parentConfigParamsF, capsF, dsCapsF, topologiesF}, fs...)
 	}
 	errs := runParallel(fs)
 	return toData, util.JoinErrs(errs)
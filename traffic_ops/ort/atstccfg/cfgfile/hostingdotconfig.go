@@ -90,8 +90,13 @@ func GetConfigFileServerHostingDotConfig(toData *config.TOData) (string, string,
 				continue
 			}
 
-			// mids: include all DSes with at least one server assigned
-			if len(dsServerMap[*ds.ID]) == 0 {
+			if placement, hasTopology := GetTopologyPlacement(toData, ds); hasTopology {
+				// mids: include DSes whose Topologies contain this mid's cachegroup after the first tier
+				if !placement.InTopology || placement.IsFirstTier {
+					continue
+				}
+			} else if len(dsServerMap[*ds.ID]) == 0 {
+				// mids: include all DSes with at least one server assigned
 				continue
 			}
 		} else {
@@ -99,13 +104,20 @@ func GetConfigFileServerHostingDotConfig(toData *config.TOData) (string, string,
 				continue
 			}
 
-			// edges: only include DSes assigned to this edge
-			if dsServerMap[*ds.ID] == nil {
-				continue
-			}
-
-			if _, ok := dsServerMap[*ds.ID][toData.Server.ID]; !ok {
-				continue
+			if placement, hasTopology := GetTopologyPlacement(toData, ds); hasTopology {
+				// edges: include DSes whose Topologies have this edge's cachegroup in the first tier
+				if !placement.IsFirstTier {
+					continue
+				}
+			} else {
+				// edges: only include DSes assigned to this edge
+				if dsServerMap[*ds.ID] == nil {
+					continue
+				}
+
+				if _, ok := dsServerMap[*ds.ID][toData.Server.ID]; !ok {
+					continue
+				}
 			}
 		}
 
@@ -55,7 +55,13 @@ func GetConfigFileServerIPAllowDotConfig(toData *config.TOData) (string, string,
 	for _, sv := range toData.Servers {
 		_, ok := childCGs[sv.Cachegroup]
 		if ok || (strings.HasPrefix(toData.Server.Type, tc.MidTypePrefix) && string(sv.Type) == tc.MonitorTypeName) {
-			childServers[tc.CacheName(sv.HostName)] = atscfg.IPAllowServer{IPAddress: sv.IPAddress, IP6Address: sv.IP6Address}
+			ipAllowServer := atscfg.IPAllowServer{IPAddress: sv.IPAddress, IP6Address: sv.IP6Address}
+			for _, iface := range sv.Interfaces {
+				for _, addr := range iface.IPAddresses {
+					ipAllowServer.IPAddresses = append(ipAllowServer.IPAddresses, addr.Address)
+				}
+			}
+			childServers[tc.CacheName(sv.HostName)] = ipAllowServer
 		}
 	}
 
@@ -142,6 +142,25 @@ func GetConfigFileServerParentDotConfig(toData *config.TOData) (string, string,
 		}
 	}
 
+	// Delivery Services with Topologies use the server's parents in the Topology, rather than its Cache Group's parents, so those are parents too.
+	dsTopologyPlacements := map[int]atscfg.TopologyPlacement{} // map[dsID]placement, of DSes whose Topologies contain this server's cachegroup
+	for _, ds := range toData.DeliveryServices {
+		if ds.ID == nil {
+			continue
+		}
+		placement, _ := GetTopologyPlacement(toData, ds)
+		if !placement.InTopology {
+			continue
+		}
+		dsTopologyPlacements[*ds.ID] = placement
+		if placement.PrimaryParent != "" {
+			parentCacheGroups[placement.PrimaryParent] = struct{}{}
+		}
+		if placement.SecondaryParent != "" {
+			parentCacheGroups[placement.SecondaryParent] = struct{}{}
+		}
+	}
+
 	cgServers := map[int]tc.Server{} // map[serverID]server
 	for _, sv := range toData.Servers {
 		if sv.CDNName != toData.Server.CDNName {
@@ -312,7 +331,12 @@ func GetConfigFileServerParentDotConfig(toData *config.TOData) (string, string,
 			continue // TODO warn?
 		}
 
-		if !serverInfo.IsTopLevelCache() {
+		topologyPlacement, inTopology := dsTopologyPlacements[*tcDS.ID]
+		if tcDS.Topology != nil && *tcDS.Topology != "" {
+			if !inTopology {
+				continue // skip DSes whose Topologies don't contain this server's cachegroup. Server assignments are ignored for DSes with Topologies.
+			}
+		} else if !serverInfo.IsTopLevelCache() {
 			if _, ok := parentServerDSes[toData.Server.ID][*tcDS.ID]; !ok {
 				continue // skip DSes not assigned to this server.
 			}
@@ -389,6 +413,11 @@ func GetConfigFileServerParentDotConfig(toData *config.TOData) (string, string,
 
 		ds.RequiredCapabilities = toData.DSRequiredCapabilities[*tcDS.ID]
 
+		if inTopology {
+			ds.Topology = *tcDS.Topology
+			ds.TopologyPlacement = topologyPlacement
+		}
+
 		parentConfigDSes = append(parentConfigDSes, ds)
 	}
 
@@ -408,6 +437,7 @@ func GetConfigFileServerParentDotConfig(toData *config.TOData) (string, string,
 
 	originServers := map[atscfg.OriginHost][]atscfg.CGServer{}  // "deliveryServices" in Perl
 	profileCaches := map[atscfg.ProfileID]atscfg.ProfileCache{} // map[profileID]ProfileCache
+	parentCGServers := []atscfg.CGServer{}                      // all non-origin parents, for Topologies
 
 	for _, cgServer := range cgServers {
 		realCGServer := atscfg.CGServer{
@@ -441,6 +471,7 @@ func GetConfigFileServerParentDotConfig(toData *config.TOData) (string, string,
 			}
 		} else {
 			originServers[atscfg.DeliveryServicesAllParentsKey] = append(originServers[atscfg.DeliveryServicesAllParentsKey], realCGServer)
+			parentCGServers = append(parentCGServers, realCGServer)
 		}
 
 		if _, profileCachesHasProfile := profileCaches[realCGServer.ProfileID]; !profileCachesHasProfile {
@@ -455,6 +486,21 @@ func GetConfigFileServerParentDotConfig(toData *config.TOData) (string, string,
 
 	parentInfos := atscfg.MakeParentInfo(&serverInfo, serverCDNDomain, profileCaches, originServers)
 
+	for i, ds := range parentConfigDSes {
+		if ds.Topology == "" || ds.TopologyPlacement.IsLastTier {
+			continue
+		}
+		primaryParentCGID := -1
+		if cg, ok := cgMap[ds.TopologyPlacement.PrimaryParent]; ok && cg.ID != nil {
+			primaryParentCGID = *cg.ID
+		}
+		secondaryParentCGID := -1
+		if cg, ok := cgMap[ds.TopologyPlacement.SecondaryParent]; ok && cg.ID != nil {
+			secondaryParentCGID = *cg.ID
+		}
+		parentConfigDSes[i].TopologyParents = atscfg.MakeTopologyParentInfo(primaryParentCGID, secondaryParentCGID, profileCaches, parentCGServers)
+	}
+
 	return atscfg.MakeParentDotConfig(&serverInfo, atsMajorVer, toData.TOToolName, toData.TOURL, parentConfigDSes, serverParams, parentInfos), atscfg.ContentTypeParentDotConfig, nil
 }
 
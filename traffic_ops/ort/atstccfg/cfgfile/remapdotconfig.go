@@ -96,7 +96,12 @@ func GetConfigFileServerRemapDotConfig(toData *config.TOData) (string, string, e
 		if ds.Active == nil {
 			continue // TODO log?
 		}
-		if _, ok := dssMap[*ds.ID]; !ok {
+		if placement, hasTopology := GetTopologyPlacement(toData, ds); hasTopology {
+			// edges get DSes whose Topologies have their cachegroup in the first tier, mids get the rest of the DSes whose Topologies contain their cachegroup.
+			if !placement.InTopology || placement.IsFirstTier == isMid {
+				continue
+			}
+		} else if _, ok := dssMap[*ds.ID]; !ok {
 			continue
 		}
 		if !useInactive && !*ds.Active {
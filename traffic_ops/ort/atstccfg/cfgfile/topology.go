@@ -0,0 +1,41 @@
+package cfgfile
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"github.com/apache/trafficcontrol/lib/go-atscfg"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/ort/atstccfg/config"
+)
+
+// GetTopologyPlacement returns where the server's Cache Group is in the Topology of the given Delivery Service, and whether the Delivery Service has a Topology.
+// If the Delivery Service's Topology doesn't exist, the server is not in it.
+// Delivery Services with Topologies are on the servers in their Topologies, regardless of the servers assigned to them.
+func GetTopologyPlacement(toData *config.TOData, ds tc.DeliveryServiceNullable) (atscfg.TopologyPlacement, bool) {
+	if ds.Topology == nil || *ds.Topology == "" {
+		return atscfg.TopologyPlacement{}, false
+	}
+	for _, topology := range toData.Topologies {
+		if topology.Name == *ds.Topology {
+			return atscfg.GetTopologyPlacement(topology, toData.Server.Cachegroup), true
+		}
+	}
+	return atscfg.TopologyPlacement{}, true
+}
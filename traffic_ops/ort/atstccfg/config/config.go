@@ -40,6 +40,9 @@ const AppName = "atstccfg"
 const Version = "0.2"
 const UserAgent = AppName + "/" + Version
 
+// DefaultCacheDir is the default directory atstccfg caches Traffic Ops data in.
+const DefaultCacheDir = "/tmp/atstccfg_cache"
+
 const ExitCodeSuccess = 0
 const ExitCodeErrGeneric = 1
 const ExitCodeNotFound = 104
@@ -49,6 +52,7 @@ var ErrNotFound = errors.New("not found")
 var ErrBadRequest = errors.New("bad request")
 
 type Cfg struct {
+	CacheDir        string
 	CacheHostName   string
 	GetData         string
 	ListPlugins     bool
@@ -97,6 +101,8 @@ func GetCfg() (Cfg, error) {
 	setQueueStatusPtr := flag.StringP("set-queue-status", "q", "", "POSTs to Traffic Ops setting the queue status of the server. Must be 'true' or 'false'. Requires --set-reval-status also be set")
 	setRevalStatusPtr := flag.StringP("set-reval-status", "a", "", "POSTs to Traffic Ops setting the revaliate status of the server. Must be 'true' or 'false'. Requires --set-queue-status also be set")
 	revalOnlyPtr := flag.BoolP("revalidate-only", "y", false, "Whether to exclude files not named 'regex_revalidate.config'")
+	cacheDirPtr := flag.String("cache-dir", DefaultCacheDir, "The directory to cache Traffic Ops data in, to make conditional requests for it.")
+	noCachePtr := flag.Bool("no-cache", false, "Whether to not cache Traffic Ops data, and request all of it unconditionally.")
 
 	flag.Parse()
 
@@ -125,6 +131,10 @@ func GetCfg() (Cfg, error) {
 	setQueueStatus := *setQueueStatusPtr
 	setRevalStatus := *setRevalStatusPtr
 	revalOnly := *revalOnlyPtr
+	cacheDir := *cacheDirPtr
+	if *noCachePtr {
+		cacheDir = ""
+	}
 
 	urlSourceStr := "argument" // for error messages
 	if toURL == "" {
@@ -175,6 +185,7 @@ func GetCfg() (Cfg, error) {
 		SetRevalStatus:  setRevalStatus,
 		SetQueueStatus:  setQueueStatus,
 		RevalOnly:       revalOnly,
+		CacheDir:        cacheDir,
 	}
 	if err := log.InitCfg(cfg); err != nil {
 		return Cfg{}, errors.New("Initializing loggers: " + err.Error() + "\n")
@@ -269,4 +280,7 @@ type TOData struct {
 
 	// SSLKeys must be all the ssl keys for the server's cdn.
 	SSLKeys []tc.CDNSSLKeys
+
+	// Topologies must be all the Topologies in Traffic Ops. May be empty if Traffic Ops doesn't support Topologies.
+	Topologies []tc.Topology
 }
@@ -0,0 +1,151 @@
+package toreqnew
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-rfc"
+	toclient "github.com/apache/trafficcontrol/traffic_ops/client"
+)
+
+// CacheEntry is a Traffic Ops object cached on disk, along with the validators Traffic Ops returned with it.
+type CacheEntry struct {
+	ETag         string          `json:"etag"`
+	LastModified string          `json:"lastModified"`
+	Obj          json.RawMessage `json:"obj"`
+}
+
+// Header returns the conditional request headers to ask Traffic Ops whether the entry is still current.
+func (e CacheEntry) Header() http.Header {
+	hdr := http.Header{}
+	if e.ETag != "" {
+		hdr.Set(rfc.IfNoneMatch, e.ETag)
+	}
+	if e.LastModified != "" {
+		hdr.Set(rfc.IfModifiedSince, e.LastModified)
+	}
+	return hdr
+}
+
+// cachePath returns the file path of the cache entry for objName in dir.
+func cachePath(dir string, objName string) string {
+	return filepath.Join(dir, url.PathEscape(objName)+".json")
+}
+
+// ReadCache returns the cache entry for objName in dir, and whether one existed.
+// Unreadable or corrupt entries are logged and treated as missing.
+func ReadCache(dir string, objName string) (CacheEntry, bool) {
+	bts, err := ioutil.ReadFile(cachePath(dir, objName))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Warnln("reading cache file for '" + objName + "', ignoring: " + err.Error())
+		}
+		return CacheEntry{}, false
+	}
+	entry := CacheEntry{}
+	if err := json.Unmarshal(bts, &entry); err != nil {
+		log.Warnln("parsing cache file for '" + objName + "', ignoring: " + err.Error())
+		return CacheEntry{}, false
+	}
+	return entry, true
+}
+
+// WriteCache writes the cache entry for objName to dir, creating dir if necessary.
+// The file is written to a temporary file and renamed, so a concurrent reader never sees a partial entry.
+func WriteCache(dir string, objName string, entry CacheEntry) error {
+	bts, err := json.Marshal(entry)
+	if err != nil {
+		return errors.New("marshalling cache entry: " + err.Error())
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return errors.New("creating cache directory: " + err.Error())
+	}
+	tmp, err := ioutil.TempFile(dir, ".tmp-")
+	if err != nil {
+		return errors.New("creating temporary cache file: " + err.Error())
+	}
+	if _, err := tmp.Write(bts); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return errors.New("writing temporary cache file: " + err.Error())
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return errors.New("closing temporary cache file: " + err.Error())
+	}
+	if err := os.Rename(tmp.Name(), cachePath(dir, objName)); err != nil {
+		os.Remove(tmp.Name())
+		return errors.New("renaming temporary cache file: " + err.Error())
+	}
+	return nil
+}
+
+// getCached calls get with the conditional request headers of any cached copy of objName, which are nil if there is none or the cache is disabled.
+// The get func must store the object it fetches in obj, unless Traffic Ops returns a 304 Not Modified, in which case getCached stores the cached copy in obj.
+// Successfully fetched objects with validators are cached for the next request. Failing to write the cache is logged, but is not an error.
+func (cl *TOClient) getCached(objName string, obj interface{}, get func(hdr http.Header) (toclient.ReqInf, error)) error {
+	entry, cached := CacheEntry{}, false
+	hdr := http.Header(nil)
+	if cl.CacheDir != "" {
+		if entry, cached = ReadCache(cl.CacheDir, objName); cached {
+			hdr = entry.Header()
+		}
+	}
+
+	reqInf, err := get(hdr)
+	if err != nil {
+		return err
+	}
+
+	if reqInf.StatusCode == http.StatusNotModified {
+		if !cached {
+			return errors.New("Traffic Ops returned Not Modified for '" + objName + "', but it was not cached")
+		}
+		if err := json.Unmarshal(entry.Obj, obj); err != nil {
+			return errors.New("Traffic Ops returned Not Modified for '" + objName + "', but the cached copy was corrupt: " + err.Error())
+		}
+		log.Infoln("Traffic Ops returned Not Modified for '" + objName + "', using cached copy")
+		return nil
+	}
+
+	if cl.CacheDir == "" || reqInf.StatusCode != http.StatusOK {
+		return nil
+	}
+	newEntry := CacheEntry{ETag: reqInf.RespHeaders.Get(rfc.ETagHeader), LastModified: reqInf.RespHeaders.Get(rfc.LastModified)}
+	if newEntry.ETag == "" && newEntry.LastModified == "" {
+		return nil // Traffic Ops is too old to answer conditional requests, caching would be useless
+	}
+	if newEntry.Obj, err = json.Marshal(obj); err != nil {
+		log.Warnln("marshalling '" + objName + "' for the cache, not caching: " + err.Error())
+		return nil
+	}
+	if err := WriteCache(cl.CacheDir, objName, newEntry); err != nil {
+		log.Warnln("caching '" + objName + "': " + err.Error())
+	}
+	return nil
+}
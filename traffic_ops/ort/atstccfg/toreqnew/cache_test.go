@@ -0,0 +1,123 @@
+package toreqnew
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-rfc"
+	toclient "github.com/apache/trafficcontrol/traffic_ops/client"
+)
+
+func TestReadWriteCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "atstccfg-cache-test")
+	if err != nil {
+		t.Fatalf("creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if _, ok := ReadCache(dir, "profile_foo/bar"); ok {
+		t.Errorf("ReadCache on empty dir expected not ok, actual ok")
+	}
+
+	entry := CacheEntry{ETag: `"abc"`, LastModified: "Tue, 07 Apr 2020 12:00:00 GMT", Obj: []byte(`["foo"]`)}
+	if err := WriteCache(dir, "profile_foo/bar", entry); err != nil {
+		t.Fatalf("WriteCache expected nil error, actual %v", err)
+	}
+	actual, ok := ReadCache(dir, "profile_foo/bar")
+	if !ok {
+		t.Fatalf("ReadCache after write expected ok, actual not ok")
+	}
+	if !reflect.DeepEqual(entry, actual) {
+		t.Errorf("ReadCache expected %+v, actual %+v", entry, actual)
+	}
+
+	hdr := actual.Header()
+	if hdr.Get(rfc.IfNoneMatch) != entry.ETag {
+		t.Errorf("Header expected If-None-Match '%s', actual '%s'", entry.ETag, hdr.Get(rfc.IfNoneMatch))
+	}
+	if hdr.Get(rfc.IfModifiedSince) != entry.LastModified {
+		t.Errorf("Header expected If-Modified-Since '%s', actual '%s'", entry.LastModified, hdr.Get(rfc.IfModifiedSince))
+	}
+}
+
+func TestGetCached(t *testing.T) {
+	dir, err := ioutil.TempDir("", "atstccfg-cache-test")
+	if err != nil {
+		t.Fatalf("creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	cl := &TOClient{CacheDir: dir}
+	respHdr := http.Header{}
+	respHdr.Set(rfc.ETagHeader, `"abc"`)
+
+	// first request is unconditional, and caches the object
+	obj := []string{}
+	err = cl.getCached("strs", &obj, func(hdr http.Header) (toclient.ReqInf, error) {
+		if hdr != nil {
+			t.Errorf("getCached with nothing cached expected no conditional headers, actual %+v", hdr)
+		}
+		obj = []string{"foo", "bar"}
+		return toclient.ReqInf{StatusCode: http.StatusOK, RespHeaders: respHdr}, nil
+	})
+	if err != nil {
+		t.Fatalf("getCached expected nil error, actual %v", err)
+	}
+
+	// second request is conditional, and uses the cached object when not modified
+	obj = []string{}
+	err = cl.getCached("strs", &obj, func(hdr http.Header) (toclient.ReqInf, error) {
+		if hdr.Get(rfc.IfNoneMatch) != `"abc"` {
+			t.Errorf("getCached with a cached object expected If-None-Match '\"abc\"', actual '%s'", hdr.Get(rfc.IfNoneMatch))
+		}
+		return toclient.ReqInf{StatusCode: http.StatusNotModified, RespHeaders: respHdr}, nil
+	})
+	if err != nil {
+		t.Fatalf("getCached expected nil error, actual %v", err)
+	}
+	if expected := []string{"foo", "bar"}; !reflect.DeepEqual(expected, obj) {
+		t.Errorf("getCached Not Modified expected cached %+v, actual %+v", expected, obj)
+	}
+
+	// with caching disabled, requests are never conditional
+	cl.CacheDir = ""
+	err = cl.getCached("strs", &obj, func(hdr http.Header) (toclient.ReqInf, error) {
+		if hdr != nil {
+			t.Errorf("getCached with caching disabled expected no conditional headers, actual %+v", hdr)
+		}
+		return toclient.ReqInf{StatusCode: http.StatusOK, RespHeaders: respHdr}, nil
+	})
+	if err != nil {
+		t.Fatalf("getCached expected nil error, actual %v", err)
+	}
+
+	// a Not Modified for an object which isn't cached is an error
+	err = cl.getCached("strs", &obj, func(hdr http.Header) (toclient.ReqInf, error) {
+		return toclient.ReqInf{StatusCode: http.StatusNotModified}, nil
+	})
+	if err == nil {
+		t.Errorf("getCached Not Modified without a cached object expected error, actual nil")
+	}
+}
@@ -29,6 +29,7 @@ package toreqnew
 
 import (
 	"errors"
+	"net/http"
 	"net/http/cookiejar"
 	"net/url"
 	"strconv"
@@ -46,6 +47,8 @@ import (
 type TOClient struct {
 	C          *toclient.Session
 	NumRetries int
+	// CacheDir is the directory to cache Traffic Ops objects in, to make conditional requests for them. If empty, nothing is cached.
+	CacheDir string
 }
 
 // New returns a TOClient with the given credentials.
@@ -74,24 +77,160 @@ func New(cookies string, url *url.URL, user string, pass string, insecure bool,
 func (cl *TOClient) GetCDNDeliveryServices(cdnID int) ([]tc.DeliveryServiceNullable, bool, error) {
 	deliveryServices := []tc.DeliveryServiceNullable{}
 	unsupported := false
-	err := torequtil.GetRetry(cl.NumRetries, "cdn_"+strconv.Itoa(cdnID)+"_deliveryservices", &deliveryServices, func(obj interface{}) error {
-		toDSes, reqInf, err := cl.C.GetDeliveryServicesByCDNID(cdnID)
+	objName := "cdn_" + strconv.Itoa(cdnID) + "_deliveryservices"
+	err := torequtil.GetRetry(cl.NumRetries, objName, &deliveryServices, func(obj interface{}) error {
+		return cl.getCached(objName, obj, func(hdr http.Header) (toclient.ReqInf, error) {
+			toDSes, reqInf, err := cl.C.GetDeliveryServicesByCDNIDWithHdr(cdnID, hdr)
+			if err != nil {
+				if errStr := strings.ToLower(err.Error()); strings.Contains(errStr, "not found") || strings.Contains(errStr, "not impl") {
+					unsupported = true
+					return reqInf, nil
+				}
+				return reqInf, errors.New("getting delivery services from Traffic Ops '" + torequtil.MaybeIPStr(reqInf.RemoteAddr) + "': " + err.Error())
+			}
+			if reqInf.StatusCode != http.StatusNotModified {
+				dses := obj.(*[]tc.DeliveryServiceNullable)
+				*dses = toDSes
+			}
+			return reqInf, nil
+		})
+	})
+	if unsupported {
+		return nil, true, nil
+	}
+	if err != nil {
+		return nil, false, errors.New("getting delivery services: " + err.Error())
+	}
+	return deliveryServices, false, nil
+}
+
+// GetTopologies returns the topologies, whether this client's version is unsupported by the server, and any error.
+// Note if the server returns a 404 or 503, this returns false and a nil error.
+// Users should check the "not supported" bool, and treat Traffic Ops as having no Topologies if it's set.
+func (cl *TOClient) GetTopologies() ([]tc.Topology, bool, error) {
+	topologies := []tc.Topology{}
+	unsupported := false
+	err := torequtil.GetRetry(cl.NumRetries, "topologies", &topologies, func(obj interface{}) error {
+		toTopologies, reqInf, err := cl.C.GetTopologies()
 		if err != nil {
 			if errStr := strings.ToLower(err.Error()); strings.Contains(errStr, "not found") || strings.Contains(errStr, "not impl") {
 				unsupported = true
 				return nil
 			}
-			return errors.New("getting delivery services from Traffic Ops '" + torequtil.MaybeIPStr(reqInf.RemoteAddr) + "': " + err.Error())
+			return errors.New("getting topologies from Traffic Ops '" + torequtil.MaybeIPStr(reqInf.RemoteAddr) + "': " + err.Error())
 		}
-		dses := obj.(*[]tc.DeliveryServiceNullable)
-		*dses = toDSes
+		topologies := obj.(*[]tc.Topology)
+		*topologies = toTopologies
 		return nil
 	})
 	if unsupported {
 		return nil, true, nil
 	}
 	if err != nil {
-		return nil, false, errors.New("getting delivery services: " + err.Error())
+		return nil, false, errors.New("getting topologies: " + err.Error())
 	}
-	return deliveryServices, false, nil
+	return topologies, false, nil
+}
+
+// GetServers returns the servers with their network interfaces, whether this client's version is unsupported by the server, and any error.
+// Note if the server returns a 404 or 503, this returns false and a nil error.
+// Users should check the "not supported" bool, and use the vendored TOClient if it's set, which gets servers without interfaces.
+func (cl *TOClient) GetServers() ([]tc.Server, bool, error) {
+	servers := []tc.Server{}
+	unsupported := false
+	objName := "servers_interfaces"
+	err := torequtil.GetRetry(cl.NumRetries, objName, &servers, func(obj interface{}) error {
+		return cl.getCached(objName, obj, func(hdr http.Header) (toclient.ReqInf, error) {
+			toServers, reqInf, err := cl.C.GetServersWithInterfacesWithHdr(nil, hdr)
+			if err != nil {
+				if errStr := strings.ToLower(err.Error()); strings.Contains(errStr, "not found") || strings.Contains(errStr, "not impl") {
+					unsupported = true
+					return reqInf, nil
+				}
+				return reqInf, errors.New("getting servers from Traffic Ops '" + torequtil.MaybeIPStr(reqInf.RemoteAddr) + "': " + err.Error())
+			}
+			if reqInf.StatusCode != http.StatusNotModified {
+				servers := obj.(*[]tc.Server)
+				*servers = toServers
+			}
+			return reqInf, nil
+		})
+	})
+	if unsupported {
+		return nil, true, nil
+	}
+	if err != nil {
+		return nil, false, errors.New("getting servers: " + err.Error())
+	}
+	return servers, false, nil
+}
+
+// GetServerProfileParameters returns the Parameters on the given Profile, whether this client's version is unsupported by the server, and any error.
+// Note if the server returns a 404 or 503, this returns false and a nil error.
+// Users should check the "not supported" bool, and use the vendored TOClient if it's set.
+func (cl *TOClient) GetServerProfileParameters(profileName string) ([]tc.Parameter, bool, error) {
+	serverProfileParameters := []tc.Parameter{}
+	unsupported := false
+	objName := "profile_" + profileName + "_parameters"
+	err := torequtil.GetRetry(cl.NumRetries, objName, &serverProfileParameters, func(obj interface{}) error {
+		return cl.getCached(objName, obj, func(hdr http.Header) (toclient.ReqInf, error) {
+			toParams, reqInf, err := cl.C.GetParametersByProfileNameWithHdr(profileName, hdr)
+			if err != nil {
+				if errStr := strings.ToLower(err.Error()); strings.Contains(errStr, "not found") || strings.Contains(errStr, "not impl") {
+					unsupported = true
+					return reqInf, nil
+				}
+				return reqInf, errors.New("getting server profile '" + profileName + "' parameters from Traffic Ops '" + torequtil.MaybeIPStr(reqInf.RemoteAddr) + "': " + err.Error())
+			}
+			if reqInf.StatusCode != http.StatusNotModified {
+				params := obj.(*[]tc.Parameter)
+				*params = toParams
+			}
+			return reqInf, nil
+		})
+	})
+	if unsupported {
+		return nil, true, nil
+	}
+	if err != nil {
+		return nil, false, errors.New("getting server profile '" + profileName + "' parameters: " + err.Error())
+	}
+	return serverProfileParameters, false, nil
+}
+
+// GetProfileByName returns the Profile with the given name, whether this client's version is unsupported by the server, and any error.
+// Note if the server returns a 404 or 503, this returns false and a nil error.
+// Users should check the "not supported" bool, and use the vendored TOClient if it's set.
+func (cl *TOClient) GetProfileByName(profileName string) (tc.Profile, bool, error) {
+	profile := tc.Profile{}
+	unsupported := false
+	objName := "profile_" + profileName
+	err := torequtil.GetRetry(cl.NumRetries, objName, &profile, func(obj interface{}) error {
+		return cl.getCached(objName, obj, func(hdr http.Header) (toclient.ReqInf, error) {
+			toProfiles, reqInf, err := cl.C.GetProfileByNameWithHdr(profileName, hdr)
+			if err != nil {
+				if errStr := strings.ToLower(err.Error()); strings.Contains(errStr, "not found") || strings.Contains(errStr, "not impl") {
+					unsupported = true
+					return reqInf, nil
+				}
+				return reqInf, errors.New("getting profile '" + profileName + "' from Traffic Ops '" + torequtil.MaybeIPStr(reqInf.RemoteAddr) + "': " + err.Error())
+			}
+			if reqInf.StatusCode == http.StatusNotModified {
+				return reqInf, nil
+			}
+			if len(toProfiles) != 1 {
+				return reqInf, errors.New("getting profile '" + profileName + "'from Traffic Ops '" + torequtil.MaybeIPStr(reqInf.RemoteAddr) + "': expected 1 Profile, got " + strconv.Itoa(len(toProfiles)))
+			}
+			profile := obj.(*tc.Profile)
+			*profile = toProfiles[0]
+			return reqInf, nil
+		})
+	})
+	if unsupported {
+		return tc.Profile{}, true, nil
+	}
+	if err != nil {
+		return tc.Profile{}, false, errors.New("getting profile '" + profileName + "': " + err.Error())
+	}
+	return profile, false, nil
 }
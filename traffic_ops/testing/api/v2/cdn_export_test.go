@@ -0,0 +1,131 @@
+package v2
+
+/*
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+import (
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+func TestCDNExportImport(t *testing.T) {
+	WithObjs(t, []TCObj{CDNs, Types, Tenants, Parameters, Profiles, Statuses, Divisions, Regions, PhysLocations, CacheGroups, Servers, DeliveryServices}, func() {
+		doc := ExportTestCDN(t)
+		ImportUnchangedTestCDN(t, doc)
+		DryRunImportTestCDN(t, doc)
+		ImportInvalidTestCDN(t, doc)
+	})
+}
+
+func ExportTestCDN(t *testing.T) tc.CDNExport {
+	cdn := testData.CDNs[0]
+	cdns, _, err := TOSession.GetCDNByName(cdn.Name)
+	if err != nil {
+		t.Fatalf("cannot GET CDN by name: %v", err)
+	}
+	if len(cdns) != 1 {
+		t.Fatalf("expected exactly one CDN named %s, actual: %d", cdn.Name, len(cdns))
+	}
+
+	doc, _, err := TOSession.ExportCDN(cdns[0].ID)
+	if err != nil {
+		t.Fatalf("cannot export CDN: %v", err)
+	}
+	if doc.Version != tc.CDNExportVersion {
+		t.Errorf("expected export version %d, actual: %d", tc.CDNExportVersion, doc.Version)
+	}
+	if doc.CDN.Name != cdn.Name || doc.CDN.DomainName != cdn.DomainName {
+		t.Errorf("expected exported CDN %s with domain %s, actual: %s with domain %s", cdn.Name, cdn.DomainName, doc.CDN.Name, doc.CDN.DomainName)
+	}
+	if len(doc.Profiles) == 0 {
+		t.Error("expected exported CDN to have profiles, actual: none")
+	}
+	for _, ds := range doc.DeliveryServices {
+		if ds.ID != nil {
+			t.Errorf("expected exported delivery service %s to have no ID, actual: %d", *ds.XMLID, *ds.ID)
+		}
+		if ds.CDNName == nil || *ds.CDNName != cdn.Name {
+			t.Errorf("expected exported delivery service %s to be in CDN %s", *ds.XMLID, cdn.Name)
+		}
+	}
+	return doc
+}
+
+func ImportUnchangedTestCDN(t *testing.T, doc tc.CDNExport) {
+	report, _, _, err := TOSession.ImportCDN(doc, false)
+	if err != nil {
+		t.Fatalf("cannot import CDN: %v", err)
+	}
+	if report.DryRun {
+		t.Error("expected import not to be a dry run")
+	}
+	for _, result := range report.Results {
+		if result.Type == "cdn" || result.Type == "profile" || result.Type == "cacheGroup" {
+			if result.Action != tc.CDNImportActionSkip {
+				t.Errorf("expected importing an unchanged export to skip %s %s, actual: %s %s", result.Type, result.Name, result.Action, result.Message)
+			}
+		} else if result.Action == tc.CDNImportActionError {
+			t.Errorf("importing %s %s: %s", result.Type, result.Name, result.Message)
+		}
+	}
+}
+
+func DryRunImportTestCDN(t *testing.T, doc tc.CDNExport) {
+	doc.CDN.DomainName = "changed." + doc.CDN.DomainName
+	report, _, _, err := TOSession.ImportCDN(doc, true)
+	if err != nil {
+		t.Fatalf("cannot dry run CDN import: %v", err)
+	}
+	if !report.DryRun {
+		t.Error("expected import to be a dry run")
+	}
+	if len(report.Results) == 0 || report.Results[0].Type != "cdn" || report.Results[0].Action != tc.CDNImportActionUpdate {
+		t.Errorf("expected dry run to update the CDN first, actual: %+v", report.Results)
+	}
+
+	cdns, _, err := TOSession.GetCDNByName(doc.CDN.Name)
+	if err != nil {
+		t.Fatalf("cannot GET CDN by name: %v", err)
+	}
+	if len(cdns) != 1 || cdns[0].DomainName == doc.CDN.DomainName {
+		t.Errorf("expected dry run not to change the CDN, actual: %+v", cdns)
+	}
+}
+
+func ImportInvalidTestCDN(t *testing.T, doc tc.CDNExport) {
+	doc.CDN.DomainName = "changed." + doc.CDN.DomainName
+	doc.Profiles = append(doc.Profiles, tc.CDNExportProfile{Name: "importNoSuchType", Type: "NO_SUCH_TYPE"})
+	report, _, _, err := TOSession.ImportCDN(doc, false)
+	if err == nil {
+		t.Error("expected an error importing a profile with an invalid type, actual: nil")
+	}
+	failed := false
+	for _, result := range report.Results {
+		if result.Name == "importNoSuchType" && result.Action == tc.CDNImportActionError {
+			failed = true
+		}
+	}
+	if !failed {
+		t.Errorf("expected importing a profile with an invalid type to fail, actual: %+v", report.Results)
+	}
+
+	cdns, _, err := TOSession.GetCDNByName(doc.CDN.Name)
+	if err != nil {
+		t.Fatalf("cannot GET CDN by name: %v", err)
+	}
+	if len(cdns) != 1 || cdns[0].DomainName == doc.CDN.DomainName {
+		t.Errorf("expected a failed import not to change the CDN, actual: %+v", cdns)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
 )
 
 func TestJobs(t *testing.T) {
@@ -29,6 +30,10 @@ func TestJobs(t *testing.T) {
 		CreateTestInvalidationJobs(t)
 		GetTestJobs(t)
 		GetTestInvalidationJobs(t)
+		CreateTestBulkInvalidationJobs(t)
+		GetTestInvalidationJobStatus(t)
+		CreateTestInvalidationJobSchedules(t)
+		DeleteTestInvalidationJobSchedules(t)
 	})
 }
 
@@ -168,3 +173,128 @@ func GetTestInvalidationJobs(t *testing.T) {
 		}
 	}
 }
+
+func CreateTestBulkInvalidationJobs(t *testing.T) {
+	if len(testData.InvalidationJobs) < 1 {
+		t.Fatal("need at least one test invalidation job to test bulk creation")
+	}
+	testJob := testData.InvalidationJobs[0]
+
+	bulk := tc.InvalidationJobBulkInput{
+		DeliveryServices: []interface{}{*testJob.DeliveryService},
+		Regexes:          []string{`/bulk/one/.*`, `/bulk/two/.*`},
+		StartTime:        &tc.Time{Time: time.Now().Add(time.Minute).UTC(), Valid: true},
+		TTL:              testJob.TTL,
+	}
+	jobs, _, _, err := TOSession.CreateInvalidationJobs(bulk)
+	if err != nil {
+		t.Fatalf("could not CREATE bulk jobs: %v", err)
+	}
+	if len(jobs) != len(bulk.Regexes) {
+		t.Fatalf("bulk job creation expected: %d jobs, actual: %d", len(bulk.Regexes), len(jobs))
+	}
+
+	bulk.Regexes = []string{`/bulk/three/.*`, `bulk/invalid/.*`}
+	if _, _, _, err := TOSession.CreateInvalidationJobs(bulk); err == nil {
+		t.Error("expected an error creating bulk jobs with an invalid regex, actual: nil")
+	}
+
+	toJobs, _, err := TOSession.GetInvalidationJobs(testJob.DeliveryService, nil)
+	if err != nil {
+		t.Fatalf("error getting jobs: %v", err)
+	}
+	for _, job := range toJobs {
+		if job.AssetURL != nil && strings.HasSuffix(*job.AssetURL, `/bulk/three/.*`) {
+			t.Error("expected failed bulk job creation to create no jobs, but found one")
+		}
+	}
+}
+
+func GetTestInvalidationJobStatus(t *testing.T) {
+	jobs, _, err := TOSession.GetInvalidationJobs(nil, nil)
+	if err != nil {
+		t.Fatalf("error getting invalidation jobs: %v", err)
+	}
+	if len(jobs) < 1 {
+		t.Fatal("expected at least one invalidation job to exist")
+	}
+
+	status, _, err := TOSession.GetInvalidationJobStatus(*jobs[0].ID)
+	if err != nil {
+		t.Fatalf("error getting invalidation job status: %v", err)
+	}
+	if status.ID == nil || *status.ID != *jobs[0].ID {
+		t.Errorf("invalidation job status ID expected: %d, actual: %v", *jobs[0].ID, status.ID)
+	}
+	if status.AppliedCaches+len(status.PendingCaches) != status.AssignedCaches {
+		t.Errorf("invalidation job status applied (%d) + pending (%d) caches expected to equal assigned (%d)", status.AppliedCaches, len(status.PendingCaches), status.AssignedCaches)
+	}
+	switch status.State {
+	case tc.InvalidationJobStatePending, tc.InvalidationJobStateInProgress, tc.InvalidationJobStateComplete, tc.InvalidationJobStateExpired:
+	default:
+		t.Errorf("invalidation job status unknown state '%s'", status.State)
+	}
+}
+
+func CreateTestInvalidationJobSchedules(t *testing.T) {
+	if len(testData.InvalidationJobs) < 1 {
+		t.Fatal("need at least one test invalidation job to test job schedules")
+	}
+	testJob := testData.InvalidationJobs[0]
+
+	schedule := tc.InvalidationJobScheduleInput{
+		InvalidationJobInput: tc.InvalidationJobInput{
+			DeliveryService: testJob.DeliveryService,
+			Regex:           testJob.Regex,
+			TTL:             testJob.TTL,
+		},
+		Cron: util.StrPtr("0 3 * * *"),
+	}
+	created, _, _, err := TOSession.CreateInvalidationJobSchedule(schedule)
+	if err != nil {
+		t.Fatalf("could not CREATE job schedule: %v", err)
+	}
+	if created.NextRun == nil || created.NextRun.Hour() != 3 || created.NextRun.Minute() != 0 {
+		t.Errorf("job schedule next run expected: 03:00, actual: %v", created.NextRun)
+	}
+
+	schedule.Cron = util.StrPtr("0 3 * *")
+	if _, _, _, err := TOSession.CreateInvalidationJobSchedule(schedule); err == nil {
+		t.Error("expected an error creating a job schedule with an invalid cron expression, actual: nil")
+	}
+
+	schedules, _, err := TOSession.GetInvalidationJobSchedules()
+	if err != nil {
+		t.Fatalf("error getting job schedules: %v", err)
+	}
+	found := false
+	for _, s := range schedules {
+		if s.ID != nil && created.ID != nil && *s.ID == *created.ID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Errorf("expected job schedule %+v to exist, but it didn't", created)
+	}
+}
+
+func DeleteTestInvalidationJobSchedules(t *testing.T) {
+	schedules, _, err := TOSession.GetInvalidationJobSchedules()
+	if err != nil {
+		t.Fatalf("error getting job schedules: %v", err)
+	}
+	for _, s := range schedules {
+		if _, _, err := TOSession.DeleteInvalidationJobSchedule(*s.ID); err != nil {
+			t.Errorf("could not DELETE job schedule #%d: %v", *s.ID, err)
+		}
+	}
+
+	schedules, _, err = TOSession.GetInvalidationJobSchedules()
+	if err != nil {
+		t.Fatalf("error getting job schedules: %v", err)
+	}
+	if len(schedules) != 0 {
+		t.Errorf("expected all job schedules to be deleted, but %d remain", len(schedules))
+	}
+}
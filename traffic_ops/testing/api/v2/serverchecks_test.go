@@ -28,6 +28,8 @@ func TestServerChecks(t *testing.T) {
 		CreateTestInvalidServerChecks(t)
 		UpdateTestServerChecks(t)
 		GetTestServerChecks(t)
+		GetTestServerCheckHistory(t)
+		ServerCheckRulesTest(t)
 	})
 }
 
@@ -138,6 +140,99 @@ func GetTestServerChecks(t *testing.T) {
 	}
 }
 
+func GetTestServerCheckHistory(t *testing.T) {
+	servers, _, err := TOSession.GetServerByHostName(*testData.Serverchecks[0].HostName)
+	if err != nil || len(servers) != 1 {
+		t.Fatalf("could not GET server %s: %v", *testData.Serverchecks[0].HostName, err)
+	}
+	history, _, err := TOSession.GetServerCheckHistory(servers[0].ID, "ORT")
+	if err != nil {
+		t.Fatalf("could not GET servercheck history: %v", err)
+	}
+	// ORT was reported once when created and once when updated
+	if len(history) != 2 {
+		t.Fatalf("expected 2 ORT results in history, got %d", len(history))
+	}
+	if history[0].Value != 12 || history[1].Value != 13 {
+		t.Errorf("expected ORT history to be [12 13] (most recent first), got [%d %d]", history[0].Value, history[1].Value)
+	}
+}
+
+func ServerCheckRulesTest(t *testing.T) {
+	servers, _, err := TOSession.GetServerByHostName(*testData.Serverchecks[0].HostName)
+	if err != nil || len(servers) != 1 {
+		t.Fatalf("could not GET server %s: %v", *testData.Serverchecks[0].HostName, err)
+	}
+
+	rule := tc.ServercheckRule{
+		CheckName:   util.StrPtr("ORT"),
+		Comparison:  util.StrPtr(tc.ServercheckComparisonGreaterEqual),
+		Threshold:   util.IntPtr(100),
+		Consecutive: util.IntPtr(2),
+		Status:      util.StrPtr("ADMIN_DOWN"),
+		Alert:       util.BoolPtr(true),
+	}
+	if _, _, err := TOSession.CreateServerCheckRule(rule); err != nil {
+		t.Fatalf("could not CREATE servercheck rule: %v", err)
+	}
+	rules, _, err := TOSession.GetServerCheckRulesByCheckName("ORT")
+	if err != nil || len(rules) != 1 {
+		t.Fatalf("expected 1 servercheck rule for ORT, got %d: %v", len(rules), err)
+	}
+	ruleID := *rules[0].ID
+
+	invalid := rule
+	invalid.CheckName = util.StrPtr("BOGUS")
+	if _, _, err := TOSession.CreateServerCheckRule(invalid); err == nil {
+		t.Error("expected to receive error creating a servercheck rule for a nonexistent check")
+	}
+
+	SwitchSession(toReqTimeout, Config.TrafficOps.URL, Config.TrafficOps.Users.Admin, Config.TrafficOps.UserPassword, Config.TrafficOps.Users.Extension, Config.TrafficOps.UserPassword)
+	check := tc.ServercheckRequestNullable{
+		Name:     util.StrPtr("ORT"),
+		Value:    util.IntPtr(100),
+		HostName: testData.Serverchecks[0].HostName,
+	}
+	warned := []bool{}
+	for i := 0; i < 3; i++ {
+		resp, _, err := TOSession.InsertServerCheckStatus(check)
+		if err != nil {
+			t.Fatalf("could not update servercheck: %v", err)
+		}
+		warning := false
+		for _, alert := range resp.Alerts {
+			if alert.Level == tc.WarnLevel.String() {
+				warning = true
+			}
+		}
+		warned = append(warned, warning)
+	}
+	SwitchSession(toReqTimeout, Config.TrafficOps.URL, Config.TrafficOps.Users.Extension, Config.TrafficOps.UserPassword, Config.TrafficOps.Users.Admin, Config.TrafficOps.UserPassword)
+
+	// the rule should only trigger on the second result in a row, not the first or third
+	if warned[0] || !warned[1] || warned[2] {
+		t.Errorf("expected a warning for the second matching result only, got %v", warned)
+	}
+
+	servers, _, err = TOSession.GetServerByHostName(*testData.Serverchecks[0].HostName)
+	if err != nil || len(servers) != 1 {
+		t.Fatalf("could not GET server %s: %v", *testData.Serverchecks[0].HostName, err)
+	}
+	if servers[0].Status != "ADMIN_DOWN" {
+		t.Errorf("expected servercheck rule to set status to ADMIN_DOWN, got %s", servers[0].Status)
+	}
+
+	if _, _, err := TOSession.DeleteServerCheckRuleByID(ruleID); err != nil {
+		t.Errorf("could not DELETE servercheck rule: %v", err)
+	}
+	rules, _, err = TOSession.GetServerCheckRulesByCheckName("ORT")
+	if err != nil {
+		t.Errorf("could not GET servercheck rules: %v", err)
+	} else if len(rules) != 0 {
+		t.Errorf("expected servercheck rule to be deleted, but %d remain", len(rules))
+	}
+}
+
 // Need to define no-op function as TCObj interface expects a delete function
 // There is no delete path for serverchecks
 func DeleteTestServerChecks(t *testing.T) {
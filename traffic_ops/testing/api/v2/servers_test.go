@@ -16,6 +16,7 @@ package v2
 */
 
 import (
+	"net/url"
 	"testing"
 
 	"github.com/apache/trafficcontrol/lib/go-tc"
@@ -25,6 +26,7 @@ func TestServers(t *testing.T) {
 	WithObjs(t, []TCObj{CDNs, Types, Tenants, Users, Parameters, Profiles, Statuses, Divisions, Regions, PhysLocations, CacheGroups, DeliveryServices, Servers}, func() {
 		UpdateTestServers(t)
 		GetTestServers(t)
+		UpdateTestServersWithInterfaces(t)
 	})
 }
 
@@ -123,6 +125,75 @@ func UpdateTestServers(t *testing.T) {
 
 }
 
+func UpdateTestServersWithInterfaces(t *testing.T) {
+	hostName := testData.Servers[len(testData.Servers)-1].HostName
+	params := url.Values{}
+	params.Set("hostName", hostName)
+	resp, _, err := TOSession.GetServersWithInterfaces(params)
+	if err != nil {
+		t.Fatalf("cannot GET Server with interfaces by hostname: %v - %v", hostName, err)
+	}
+	if len(resp) != 1 {
+		t.Fatalf("GET Server with interfaces by hostname %v expected: 1 server, actual: %v", hostName, len(resp))
+	}
+	remoteServer := resp[0]
+
+	// servers created through the previous API version have one interface holding their legacy addresses
+	serviceInterface, ok := tc.GetServiceInterface(remoteServer.Interfaces)
+	if !ok {
+		t.Fatalf("server %v expected: a service interface, actual: %+v", hostName, remoteServer.Interfaces)
+	}
+	if serviceInterface.Name != remoteServer.InterfaceName {
+		t.Errorf("server %v service interface expected: %v, actual: %v", hostName, remoteServer.InterfaceName, serviceInterface.Name)
+	}
+
+	maxBandwidth := uint64(40000000)
+	mtu := uint64(9000)
+	gateway := "198.51.100.254"
+	remoteServer.Interfaces = []tc.ServerInterfaceInfo{
+		{
+			Name:         "bond2",
+			MaxBandwidth: &maxBandwidth,
+			Monitor:      true,
+			MTU:          &mtu,
+			IPAddresses: []tc.ServerIPAddress{
+				{Address: "198.51.100.10/24", Gateway: &gateway, ServiceAddress: true},
+				{Address: "198.51.100.11/24"},
+			},
+		},
+		serviceInterface,
+	}
+	for i := range remoteServer.Interfaces[1].IPAddresses {
+		remoteServer.Interfaces[1].IPAddresses[i].ServiceAddress = false
+	}
+	if alerts, _, err := TOSession.UpdateServerWithInterfacesByID(remoteServer.ID, remoteServer); err != nil {
+		t.Fatalf("cannot UPDATE Server with interfaces: %v - %v", err, alerts)
+	}
+
+	resp, _, err = TOSession.GetServersWithInterfaces(params)
+	if err != nil || len(resp) != 1 {
+		t.Fatalf("cannot GET Server with interfaces by hostname: %v - %v", hostName, err)
+	}
+	if len(resp[0].Interfaces) != 2 {
+		t.Errorf("server %v interfaces expected: 2, actual: %+v", hostName, resp[0].Interfaces)
+	}
+
+	// the previous API version sees the service interface
+	legacy, _, err := TOSession.GetServerByID(remoteServer.ID)
+	if err != nil || len(legacy) != 1 {
+		t.Fatalf("cannot GET Server by ID: %v - %v", remoteServer.ID, err)
+	}
+	if legacy[0].InterfaceName != "bond2" || legacy[0].IPAddress != "198.51.100.10" || legacy[0].IPNetmask != "255.255.255.0" || legacy[0].IPGateway != gateway || legacy[0].InterfaceMtu != int(mtu) {
+		t.Errorf("legacy server fields expected: bond2 198.51.100.10/255.255.255.0 via %v mtu %v, actual: %v %v/%v via %v mtu %v", gateway, mtu, legacy[0].InterfaceName, legacy[0].IPAddress, legacy[0].IPNetmask, legacy[0].IPGateway, legacy[0].InterfaceMtu)
+	}
+
+	// service addresses must all be on one interface
+	remoteServer.Interfaces[1].IPAddresses[0].ServiceAddress = true
+	if _, _, err := TOSession.UpdateServerWithInterfacesByID(remoteServer.ID, remoteServer); err == nil {
+		t.Errorf("expected an error updating a server with service addresses on two interfaces, actual: nil")
+	}
+}
+
 func DeleteTestServers(t *testing.T) {
 
 	for _, server := range testData.Servers {
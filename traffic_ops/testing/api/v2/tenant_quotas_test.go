@@ -0,0 +1,140 @@
+package v2
+
+/*
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+import (
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
+)
+
+func TestTenantQuotas(t *testing.T) {
+	WithObjs(t, []TCObj{CDNs, Types, Tenants, Parameters, Profiles, Statuses, Divisions, Regions, PhysLocations, CacheGroups, Servers, DeliveryServices}, func() {
+		tenant := GetTestQuotaTenant(t)
+		UpdateTestTenantQuota(t, tenant)
+		CreateTestDeliveryServiceOverQuota(t, tenant)
+		GetTestTenantUsage(t, tenant)
+		UpdateTestTenantQuotaInvalid(t, tenant)
+		DeleteTestTenantQuota(t, tenant)
+	})
+}
+
+func GetTestQuotaTenant(t *testing.T) tc.Tenant {
+	tenant, _, err := TOSession.TenantByName(testData.DeliveryServices[0].Tenant)
+	if err != nil {
+		t.Fatalf("cannot GET tenant by name: %v", err)
+	}
+	quota, _, err := TOSession.GetTenantQuota(tenant.ID)
+	if err != nil {
+		t.Fatalf("cannot GET tenant quota: %v", err)
+	}
+	if quota.MaxDeliveryServices != nil || quota.MaxEdgeServers != nil || quota.MaxInvalidationJobsPerDay != nil || quota.MaxRegexesPerDeliveryService != nil {
+		t.Errorf("expected tenant %s to have no quota, actual: %+v", tenant.Name, quota)
+	}
+	return *tenant
+}
+
+func UpdateTestTenantQuota(t *testing.T, tenant tc.Tenant) {
+	usages, _, err := TOSession.GetTenantUsageByID(tenant.ID)
+	if err != nil {
+		t.Fatalf("cannot GET tenant usage: %v", err)
+	}
+	if len(usages) != 1 {
+		t.Fatalf("expected usage of exactly one tenant, actual: %d", len(usages))
+	}
+
+	// Limit the tenant to the delivery services it already has.
+	quota := tc.TenantQuota{MaxDeliveryServices: util.IntPtr(usages[0].DeliveryServices.Used)}
+	if _, _, err := TOSession.UpdateTenantQuota(tenant.ID, quota); err != nil {
+		t.Fatalf("cannot PUT tenant quota: %v", err)
+	}
+	actual, _, err := TOSession.GetTenantQuota(tenant.ID)
+	if err != nil {
+		t.Fatalf("cannot GET tenant quota: %v", err)
+	}
+	if actual.MaxDeliveryServices == nil || *actual.MaxDeliveryServices != *quota.MaxDeliveryServices {
+		t.Errorf("expected tenant quota to limit delivery services to %d, actual: %+v", *quota.MaxDeliveryServices, actual)
+	}
+	if actual.MaxEdgeServers != nil {
+		t.Errorf("expected tenant quota not to limit edge servers, actual: %d", *actual.MaxEdgeServers)
+	}
+}
+
+func CreateTestDeliveryServiceOverQuota(t *testing.T, tenant tc.Tenant) {
+	ds := testData.DeliveryServices[0]
+	ds.XMLID = "over-quota"
+	ds.TenantID = tenant.ID
+	if _, err := TOSession.CreateDeliveryService(&ds); err == nil {
+		t.Error("expected creating a delivery service over the tenant's quota to fail, actual: success")
+	}
+	if dses, _, err := TOSession.GetDeliveryServiceByXMLIDNullable(ds.XMLID); err != nil {
+		t.Errorf("cannot GET delivery service by xml id: %v", err)
+	} else if len(dses) != 0 {
+		t.Error("expected delivery service over the tenant's quota not to be created")
+	}
+}
+
+func GetTestTenantUsage(t *testing.T, tenant tc.Tenant) {
+	usages, _, err := TOSession.GetTenantUsage()
+	if err != nil {
+		t.Fatalf("cannot GET tenant usage: %v", err)
+	}
+	found := false
+	for _, usage := range usages {
+		if usage.TenantID != tenant.ID {
+			continue
+		}
+		found = true
+		if usage.DeliveryServices.Limit == nil || *usage.DeliveryServices.Limit != usage.DeliveryServices.Used {
+			t.Errorf("expected tenant %s to be at its delivery service limit, actual: %+v", tenant.Name, usage.DeliveryServices)
+		}
+		if usage.EdgeServers.Limit != nil {
+			t.Errorf("expected tenant %s to have no edge server limit, actual: %d", tenant.Name, *usage.EdgeServers.Limit)
+		}
+	}
+	if !found {
+		t.Errorf("expected usage of tenant %s, actual: none", tenant.Name)
+	}
+}
+
+func UpdateTestTenantQuotaInvalid(t *testing.T, tenant tc.Tenant) {
+	if _, _, err := TOSession.UpdateTenantQuota(tenant.ID, tc.TenantQuota{MaxEdgeServers: util.IntPtr(-1)}); err == nil {
+		t.Error("expected setting a negative tenant quota to fail, actual: success")
+	}
+	root, _, err := TOSession.TenantByName("root")
+	if err != nil {
+		t.Fatalf("cannot GET tenant by name: %v", err)
+	}
+	if _, _, err := TOSession.UpdateTenantQuota(root.ID, tc.TenantQuota{MaxEdgeServers: util.IntPtr(1)}); err == nil {
+		t.Error("expected setting a quota on the root tenant to fail, actual: success")
+	}
+}
+
+func DeleteTestTenantQuota(t *testing.T, tenant tc.Tenant) {
+	if _, _, err := TOSession.DeleteTenantQuota(tenant.ID); err != nil {
+		t.Fatalf("cannot DELETE tenant quota: %v", err)
+	}
+	quota, _, err := TOSession.GetTenantQuota(tenant.ID)
+	if err != nil {
+		t.Fatalf("cannot GET tenant quota: %v", err)
+	}
+	if quota.MaxDeliveryServices != nil {
+		t.Errorf("expected deleted tenant quota not to limit delivery services, actual: %d", *quota.MaxDeliveryServices)
+	}
+	if _, _, err := TOSession.DeleteTenantQuota(tenant.ID); err == nil {
+		t.Error("expected deleting a missing tenant quota to fail, actual: success")
+	}
+}
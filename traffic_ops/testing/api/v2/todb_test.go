@@ -308,9 +308,15 @@ func Teardown(db *sql.DB) error {
 	DELETE FROM server_server_capability;
 	DELETE FROM server_server_capability;
 	DELETE FROM server_capability;
+	DELETE FROM servercheck_rule;
+	DELETE FROM servercheck_result;
 	DELETE FROM to_extension;
 	DELETE FROM staticdnsentry;
+	DELETE FROM tenant_quota;
+	DELETE FROM job_schedule;
 	DELETE FROM job;
+	DELETE FROM webhook;
+	DELETE FROM event;
 	DELETE FROM job_agent;
 	DELETE FROM job_status;
 	DELETE FROM log;
@@ -324,7 +330,12 @@ func Teardown(db *sql.DB) error {
 	DELETE FROM regex;
 	DELETE FROM deliveryservice_server;
 	DELETE FROM deliveryservice;
+	DELETE FROM topology_cachegroup_parents;
+	DELETE FROM topology_cachegroup;
+	DELETE FROM topology;
 	DELETE FROM origin;
+	DELETE FROM ip_address;
+	DELETE FROM interface;
 	DELETE FROM server;
 	DELETE FROM phys_location;
 	DELETE FROM region;
@@ -0,0 +1,184 @@
+package v2
+
+/*
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+import (
+	"strconv"
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
+)
+
+func TestTopologies(t *testing.T) {
+	WithObjs(t, []TCObj{CDNs, Types, Tenants, Parameters, Profiles, Statuses, Divisions, Regions, PhysLocations, CacheGroups, Servers, Topologies, DeliveryServices}, func() {
+		GetTestTopologies(t)
+		UpdateTestTopologies(t)
+		CreateTestTopologiesInvalid(t)
+		UpdateTestDeliveryServiceTopology(t)
+	})
+}
+
+func CreateTestTopologies(t *testing.T) {
+	for _, topology := range testData.Topologies {
+		if _, _, err := TOSession.CreateTopology(topology); err != nil {
+			t.Errorf("could not CREATE topology %s: %v", topology.Name, err)
+		}
+	}
+}
+
+func GetTestTopologies(t *testing.T) {
+	for _, expected := range testData.Topologies {
+		topologies, _, err := TOSession.GetTopologyByName(expected.Name)
+		if err != nil {
+			t.Errorf("cannot GET topology %s: %v", expected.Name, err)
+			continue
+		}
+		if len(topologies) != 1 {
+			t.Errorf("expected exactly one topology named %s, actual: %d", expected.Name, len(topologies))
+			continue
+		}
+		actual := topologies[0]
+		if len(actual.Nodes) != len(expected.Nodes) {
+			t.Errorf("expected topology %s to have %d nodes, actual: %d", expected.Name, len(expected.Nodes), len(actual.Nodes))
+			continue
+		}
+		for i, node := range expected.Nodes {
+			if actual.Nodes[i].Cachegroup != node.Cachegroup {
+				t.Errorf("expected topology %s node %d to be cachegroup %s, actual: %s", expected.Name, i, node.Cachegroup, actual.Nodes[i].Cachegroup)
+			}
+			if len(actual.Nodes[i].Parents) != len(node.Parents) {
+				t.Errorf("expected topology %s node %d to have parents %v, actual: %v", expected.Name, i, node.Parents, actual.Nodes[i].Parents)
+				continue
+			}
+			for j, parent := range node.Parents {
+				if actual.Nodes[i].Parents[j] != parent {
+					t.Errorf("expected topology %s node %d to have parents %v, actual: %v", expected.Name, i, node.Parents, actual.Nodes[i].Parents)
+					break
+				}
+			}
+		}
+	}
+}
+
+func UpdateTestTopologies(t *testing.T) {
+	topology := testData.Topologies[len(testData.Topologies)-1]
+	originalName := topology.Name
+	topology.Name = originalName + "-renamed"
+	topology.Description = "renamed"
+	topology.Nodes = append(topology.Nodes, tc.TopologyNode{Cachegroup: "cachegroup3", Parents: []int{}})
+	if _, _, err := TOSession.UpdateTopology(originalName, topology); err != nil {
+		t.Fatalf("cannot UPDATE topology %s: %v", originalName, err)
+	}
+	topologies, _, err := TOSession.GetTopologyByName(topology.Name)
+	if err != nil {
+		t.Fatalf("cannot GET topology %s: %v", topology.Name, err)
+	}
+	if len(topologies) != 1 {
+		t.Fatalf("expected exactly one topology named %s, actual: %d", topology.Name, len(topologies))
+	}
+	if topologies[0].Description != topology.Description || len(topologies[0].Nodes) != len(topology.Nodes) {
+		t.Errorf("expected updated topology %+v, actual: %+v", topology, topologies[0])
+	}
+	if topologies, _, err := TOSession.GetTopologyByName(originalName); err != nil {
+		t.Errorf("cannot GET topology %s: %v", originalName, err)
+	} else if len(topologies) != 0 {
+		t.Errorf("expected renamed topology %s to be gone, actual: %+v", originalName, topologies)
+	}
+
+	// restore the original, so it can be deleted
+	if _, _, err := TOSession.UpdateTopology(topology.Name, testData.Topologies[len(testData.Topologies)-1]); err != nil {
+		t.Errorf("cannot UPDATE topology %s: %v", topology.Name, err)
+	}
+}
+
+func CreateTestTopologiesInvalid(t *testing.T) {
+	invalid := map[string]tc.Topology{
+		"edge parent": tc.Topology{Name: "invalid", Nodes: []tc.TopologyNode{
+			{Cachegroup: "cachegroup1", Parents: []int{1}},
+			{Cachegroup: "cachegroup2"},
+		}},
+		"mid first tier": tc.Topology{Name: "invalid", Nodes: []tc.TopologyNode{
+			{Cachegroup: "parentCachegroup"},
+		}},
+		"origin cachegroup": tc.Topology{Name: "invalid", Nodes: []tc.TopologyNode{
+			{Cachegroup: "cachegroup1", Parents: []int{1}},
+			{Cachegroup: "originCachegroup"},
+		}},
+		"cycle": tc.Topology{Name: "invalid", Nodes: []tc.TopologyNode{
+			{Cachegroup: "cachegroup1", Parents: []int{1}},
+			{Cachegroup: "parentCachegroup", Parents: []int{2}},
+			{Cachegroup: "secondaryCachegroup", Parents: []int{1}},
+		}},
+		"missing cachegroup": tc.Topology{Name: "invalid", Nodes: []tc.TopologyNode{
+			{Cachegroup: "no-such-cachegroup"},
+		}},
+	}
+	for name, topology := range invalid {
+		if _, _, err := TOSession.CreateTopology(topology); err == nil {
+			t.Errorf("expected creating a topology with %s to fail, actual: success", name)
+		}
+	}
+}
+
+func UpdateTestDeliveryServiceTopology(t *testing.T) {
+	topology := testData.Topologies[0].Name
+	dses, _, err := TOSession.GetDeliveryServiceByXMLIDNullable(testData.DeliveryServices[0].XMLID)
+	if err != nil {
+		t.Fatalf("cannot GET delivery service by xml id: %v", err)
+	}
+	if len(dses) != 1 {
+		t.Fatalf("expected exactly one delivery service with xml id %s, actual: %d", testData.DeliveryServices[0].XMLID, len(dses))
+	}
+	ds := dses[0]
+	id := strconv.Itoa(*ds.ID)
+
+	ds.Topology = util.StrPtr(topology)
+	if _, err := TOSession.UpdateDeliveryServiceNullable(id, &ds); err != nil {
+		t.Fatalf("cannot UPDATE delivery service topology: %v", err)
+	}
+	if dses, _, err := TOSession.GetDeliveryServiceByXMLIDNullable(*ds.XMLID); err != nil {
+		t.Errorf("cannot GET delivery service by xml id: %v", err)
+	} else if len(dses) != 1 || dses[0].Topology == nil || *dses[0].Topology != topology {
+		t.Errorf("expected delivery service to have topology %s, actual: %+v", topology, dses)
+	}
+
+	if _, _, err := TOSession.DeleteTopology(topology); err == nil {
+		t.Error("expected deleting a topology used by a delivery service to fail, actual: success")
+	}
+
+	ds.Topology = util.StrPtr("no-such-topology")
+	if _, err := TOSession.UpdateDeliveryServiceNullable(id, &ds); err == nil {
+		t.Error("expected updating a delivery service to a nonexistent topology to fail, actual: success")
+	}
+
+	ds.Topology = nil
+	if _, err := TOSession.UpdateDeliveryServiceNullable(id, &ds); err != nil {
+		t.Errorf("cannot UPDATE delivery service topology: %v", err)
+	}
+}
+
+func DeleteTestTopologies(t *testing.T) {
+	for _, topology := range testData.Topologies {
+		if _, _, err := TOSession.DeleteTopology(topology.Name); err != nil {
+			t.Errorf("cannot DELETE topology %s: %v", topology.Name, err)
+		}
+		if topologies, _, err := TOSession.GetTopologyByName(topology.Name); err != nil {
+			t.Errorf("cannot GET topology %s: %v", topology.Name, err)
+		} else if len(topologies) != 0 {
+			t.Errorf("expected topology %s to be deleted, actual: %+v", topology.Name, topologies)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package v2
+
+/*
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
+)
+
+const testWebhookURL = "https://webhook.example.net/trafficops"
+
+func TestWebhooks(t *testing.T) {
+	CreateTestWebhooks(t)
+	GetTestWebhooks(t)
+	UpdateTestWebhooks(t)
+	DeleteTestWebhooks(t)
+}
+
+func CreateTestWebhooks(t *testing.T) {
+	wh := tc.Webhook{
+		URL:        util.StrPtr(testWebhookURL),
+		EventTypes: []string{"ds.*", tc.EventTypeSnapshotTaken},
+	}
+	if _, _, err := TOSession.CreateWebhook(wh); err == nil {
+		t.Error("expected an error creating a webhook with no secret, actual: nil")
+	}
+
+	wh.Secret = util.StrPtr("shhh")
+	if _, _, err := TOSession.CreateWebhook(wh); err != nil {
+		t.Fatalf("cannot create webhook: %v", err)
+	}
+
+	wh.URL = util.StrPtr("not a url")
+	if _, _, err := TOSession.CreateWebhook(wh); err == nil {
+		t.Error("expected an error creating a webhook with an invalid URL, actual: nil")
+	}
+}
+
+func getTestWebhook(t *testing.T) tc.Webhook {
+	whs, _, err := TOSession.GetWebhookByURL(testWebhookURL)
+	if err != nil {
+		t.Fatalf("cannot get webhook by URL: %v", err)
+	}
+	if len(whs) != 1 {
+		t.Fatalf("expected exactly one webhook with URL %s, actual: %d", testWebhookURL, len(whs))
+	}
+	if whs[0].ID == nil {
+		t.Fatal("webhook returned with no id")
+	}
+	return whs[0]
+}
+
+func GetTestWebhooks(t *testing.T) {
+	wh := getTestWebhook(t)
+	if wh.Secret != nil {
+		t.Error("expected webhook secret to never be returned, actual: returned")
+	}
+	if wh.Active == nil || !*wh.Active {
+		t.Error("expected webhook to be active by default, actual: inactive")
+	}
+	if len(wh.EventTypes) != 2 {
+		t.Errorf("expected 2 webhook event types, actual: %v", wh.EventTypes)
+	}
+}
+
+func UpdateTestWebhooks(t *testing.T) {
+	wh := getTestWebhook(t)
+	wh.Active = util.BoolPtr(false)
+	wh.EventTypes = []string{tc.EventTypeWildcard}
+	if _, _, err := TOSession.UpdateWebhookByID(*wh.ID, wh); err != nil {
+		t.Fatalf("cannot update webhook without changing its secret: %v", err)
+	}
+
+	wh = getTestWebhook(t)
+	if wh.Active == nil || *wh.Active {
+		t.Error("expected updated webhook to be inactive, actual: active")
+	}
+	if len(wh.EventTypes) != 1 || wh.EventTypes[0] != tc.EventTypeWildcard {
+		t.Errorf("expected updated webhook event types [*], actual: %v", wh.EventTypes)
+	}
+}
+
+func DeleteTestWebhooks(t *testing.T) {
+	wh := getTestWebhook(t)
+	if _, _, err := TOSession.DeleteWebhookByID(*wh.ID); err != nil {
+		t.Fatalf("cannot delete webhook: %v", err)
+	}
+	whs, _, err := TOSession.GetWebhookByID(*wh.ID)
+	if err != nil {
+		t.Fatalf("cannot get webhook by id: %v", err)
+	}
+	if len(whs) != 0 {
+		t.Errorf("expected webhook to be deleted, actual: %+v", whs)
+	}
+}
@@ -45,6 +45,7 @@ import (
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/config"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/tocookie"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/trace"
 
 	influx "github.com/influxdata/influxdb/client/v2"
 	"github.com/jmoiron/sqlx"
@@ -53,10 +54,11 @@ import (
 
 // Common context.Context value keys.
 const (
-	DBContextKey      = "db"
-	ConfigContextKey  = "context"
-	ReqIDContextKey   = "reqid"
-	APIRespWrittenKey = "respwritten"
+	DBContextKey            = "db"
+	ConfigContextKey        = "context"
+	ReqIDContextKey         = "reqid"
+	CorrelationIDContextKey = "correlationid"
+	APIRespWrittenKey       = "respwritten"
 )
 
 const influxServersQuery = `
@@ -94,6 +96,9 @@ func WriteRespRaw(w http.ResponseWriter, r *http.Request, v interface{}) {
 		tc.GetHandleErrorsFunc(w, r)(http.StatusInternalServerError, errors.New(http.StatusText(http.StatusInternalServerError)))
 		return
 	}
+	if writeConditional(w, r, bts) {
+		return
+	}
 	w.Header().Set(rfc.ContentType, rfc.ApplicationJSON)
 	w.Write(append(bts, '\n'))
 }
@@ -114,6 +119,9 @@ func WriteRespVals(w http.ResponseWriter, r *http.Request, v interface{}, vals m
 		tc.GetHandleErrorsFunc(w, r)(http.StatusInternalServerError, errors.New(http.StatusText(http.StatusInternalServerError)))
 		return
 	}
+	if writeConditional(w, r, respBts) {
+		return
+	}
 	w.Header().Set("Content-Type", "application/json")
 	w.Write(append(respBts, '\n'))
 }
@@ -175,13 +183,24 @@ func HandleDeprecatedErr(w http.ResponseWriter, r *http.Request, tx *sql.Tx, sta
 // LogErr handles the logging of errors and setting up possibly nil errors without actually writing anything to a
 // http.ResponseWriter, unlike handleSimpleErr. It returns the userErr which will be initialized to the
 // http.StatusText of errCode if it was passed as nil - otherwise left alone.
+//
+// The correlation ID of the request is logged with the sysErr, and added to the userErr of server
+// errors, so users can tell operators which logged error they got.
 func LogErr(r *http.Request, errCode int, userErr error, sysErr error) error {
+	correlationID := GetCorrelationID(r.Context())
 	if sysErr != nil {
-		log.Errorf(r.RemoteAddr + " " + sysErr.Error())
+		if correlationID != "" {
+			log.Errorln(r.RemoteAddr + " (correlation ID " + correlationID + ") " + sysErr.Error())
+		} else {
+			log.Errorln(r.RemoteAddr + " " + sysErr.Error())
+		}
 	}
 	if userErr == nil {
 		userErr = errors.New(http.StatusText(errCode))
 	}
+	if errCode >= http.StatusInternalServerError && correlationID != "" {
+		userErr = errors.New(userErr.Error() + " (correlation ID " + correlationID + ")")
+	}
 	log.Debugln(userErr.Error())
 	*r = *r.WithContext(context.WithValue(r.Context(), tc.StatusKey, errCode))
 	return userErr
@@ -418,13 +437,14 @@ func Parse(r io.Reader, tx *sql.Tx, v ParseValidator) error {
 }
 
 type APIInfo struct {
-	Params    map[string]string
-	IntParams map[string]int
-	User      *auth.CurrentUser
-	ReqID     uint64
-	Version   *Version
-	Tx        *sqlx.Tx
-	Config    *config.Config
+	Params        map[string]string
+	IntParams     map[string]int
+	User          *auth.CurrentUser
+	ReqID         uint64
+	CorrelationID string
+	Version       *Version
+	Tx            *sqlx.Tx
+	Config        *config.Config
 }
 
 // NewInfo get and returns the context info needed by handlers. It also returns any user error, any system error, and the status code which should be returned to the client if an error occurred.
@@ -469,6 +489,7 @@ func NewInfo(r *http.Request, requiredParams []string, intParamNames []string) (
 	if err != nil {
 		return &APIInfo{Tx: &sqlx.Tx{}}, errors.New("getting reqID: " + err.Error()), nil, http.StatusInternalServerError
 	}
+	correlationID := GetCorrelationID(r.Context())
 	version := getRequestedAPIVersion(r.URL.Path)
 
 	user, err := auth.GetCurrentUser(r.Context())
@@ -484,14 +505,22 @@ func NewInfo(r *http.Request, requiredParams []string, intParamNames []string) (
 	if err != nil {
 		return &APIInfo{Tx: &sqlx.Tx{}}, userErr, errors.New("could not begin transaction: " + err.Error()), http.StatusInternalServerError
 	}
+	if correlationID != "" {
+		if err := setTxCorrelationID(tx.Tx, correlationID); err != nil {
+			tx.Rollback()
+			return &APIInfo{Tx: &sqlx.Tx{}}, nil, errors.New("setting transaction correlation ID: " + err.Error()), http.StatusInternalServerError
+		}
+	}
+	trace.BindTx(r.Context(), tx.Tx)
 	return &APIInfo{
-		Config:    cfg,
-		ReqID:     reqID,
-		Version:   version,
-		Params:    params,
-		IntParams: intParams,
-		User:      user,
-		Tx:        tx,
+		Config:        cfg,
+		ReqID:         reqID,
+		CorrelationID: correlationID,
+		Version:       version,
+		Params:        params,
+		IntParams:     intParams,
+		User:          user,
+		Tx:            tx,
 	}, nil, nil, http.StatusOK
 }
 
@@ -502,6 +531,7 @@ func (inf *APIInfo) Close() {
 	if err := inf.Tx.Tx.Commit(); err != nil && err != sql.ErrTxDone {
 		log.Errorln("committing transaction: " + err.Error())
 	}
+	trace.UnbindTx(inf.Tx.Tx)
 }
 
 // SendMail is a convenience method used to call SendMail using an APIInfo structure's configuration.
@@ -585,11 +615,17 @@ func (inf *APIInfo) CreateInfluxClient() (*influx.Client, error) {
 		host = fmt.Sprintf(host, "", fqdn, 8086)
 	}
 
+	// The Influx client can't send other headers, but InfluxDB logs the User-Agent of each request.
+	userAgent := fmt.Sprintf("TrafficOps/%s (Go)", inf.Config.Version)
+	if inf.CorrelationID != "" {
+		userAgent = fmt.Sprintf("TrafficOps/%s (Go; correlation ID %s)", inf.Config.Version, inf.CorrelationID)
+	}
+
 	config := influx.HTTPConfig{
 		Addr:      host,
 		Username:  inf.Config.ConfigInflux.User,
 		Password:  inf.Config.ConfigInflux.Password,
-		UserAgent: fmt.Sprintf("TrafficOps/%s (Go)", inf.Config.Version),
+		UserAgent: userAgent,
 		Timeout:   time.Duration(float64(inf.Config.ReadTimeout)/2.1) * time.Second,
 	}
 
@@ -598,9 +634,34 @@ func (inf *APIInfo) CreateInfluxClient() (*influx.Client, error) {
 	if client == nil {
 		return nil, fmt.Errorf("Failed to create influx client (client was nil): %v", e)
 	}
+	if ctx := trace.TxContext(inf.Tx.Tx); trace.FromContext(ctx) != nil {
+		client = tracedInfluxClient{Client: client, ctx: ctx}
+	}
 	return &client, e
 }
 
+// tracedInfluxClient is an InfluxDB client which traces its queries as children of the span of ctx.
+type tracedInfluxClient struct {
+	influx.Client
+	ctx context.Context
+}
+
+// Query implements influx.Client.
+func (c tracedInfluxClient) Query(q influx.Query) (*influx.Response, error) {
+	_, span := trace.StartChildSpan(c.ctx, "InfluxDB query", trace.KindClient)
+	span.SetAttribute("db.system", "influxdb")
+	span.SetAttribute("db.name", q.Database)
+	span.SetAttribute("db.statement", q.Command)
+	resp, err := c.Client.Query(q)
+	if err != nil {
+		span.SetError(err)
+	} else if resp != nil {
+		span.SetError(resp.Error())
+	}
+	span.End()
+	return resp, err
+}
+
 // APIInfoImpl implements APIInfo via the APIInfoer interface
 type APIInfoImpl struct {
 	ReqInfo *APIInfo
@@ -50,6 +50,13 @@ const (
 	Deleted   = "Deleted"
 )
 
+// insertChangeLogQuery inserts a change log row, with the correlation ID of the request whose
+// transaction it's in, if any.
+const insertChangeLogQuery = `
+INSERT INTO log (level, message, tm_user, correlation_id)
+VALUES ($1, $2, $3, NULLIF(current_setting('` + CorrelationIDSetting + `', true), ''))
+`
+
 func CreateChangeLog(level string, action string, i Identifier, user *auth.CurrentUser, tx *sql.Tx) error {
 	t, ok := i.(ChangeLogger)
 	if !ok {
@@ -80,14 +87,14 @@ func CreateChangeLogBuildMsg(level string, action string, user *auth.CurrentUser
 }
 
 func CreateChangeLogRawErr(level string, msg string, user *auth.CurrentUser, tx *sql.Tx) error {
-	if _, err := tx.Exec(`INSERT INTO log (level, message, tm_user) VALUES ($1, $2, $3)`, level, msg, user.ID); err != nil {
+	if _, err := tx.Exec(insertChangeLogQuery, level, msg, user.ID); err != nil {
 		return errors.New("Inserting change log level '" + level + "' message '" + msg + "' user '" + user.UserName + "': " + err.Error())
 	}
 	return nil
 }
 
 func CreateChangeLogRawTx(level string, msg string, user *auth.CurrentUser, tx *sql.Tx) {
-	if _, err := tx.Exec(`INSERT INTO log (level, message, tm_user) VALUES ($1, $2, $3)`, level, msg, user.ID); err != nil {
+	if _, err := tx.Exec(insertChangeLogQuery, level, msg, user.ID); err != nil {
 		log.Errorln("Inserting change log level '" + level + "' message '" + msg + "' user '" + user.UserName + "': " + err.Error())
 	}
 }
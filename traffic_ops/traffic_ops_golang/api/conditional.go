@@ -0,0 +1,249 @@
+package api
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-rfc"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+
+	"github.com/lib/pq"
+)
+
+// LastModifiedTable is the table recording when rows were last deleted from other tables, which have no row left to carry a last_updated time.
+const LastModifiedTable = "last_deleted"
+
+// GetLastModified returns the latest time any row in any of the given tables was created, updated, or deleted, and the database's current time.
+// If none of the tables have ever had a row, the returned last modified time is zero.
+func GetLastModified(tx *sql.Tx, tables []string) (time.Time, time.Time, error) {
+	if len(tables) == 0 {
+		return time.Time{}, time.Time{}, errors.New("getting last modified time: no tables")
+	}
+	qry := `SELECT GREATEST(`
+	for _, table := range tables {
+		qry += `(SELECT MAX(last_updated) FROM ` + pq.QuoteIdentifier(table) + `), `
+	}
+	qry += `(SELECT MAX(last_updated) FROM ` + LastModifiedTable + ` WHERE table_name = ANY($1))), clock_timestamp()`
+
+	lastModified := pq.NullTime{}
+	now := time.Time{}
+	if err := tx.QueryRow(qry, pq.Array(tables)).Scan(&lastModified, &now); err != nil {
+		return time.Time{}, time.Time{}, errors.New("getting last modified time for " + strings.Join(tables, ",") + ": " + err.Error())
+	}
+	if !lastModified.Valid {
+		return time.Time{}, now, nil
+	}
+	return lastModified.Time, now, nil
+}
+
+// SetLastModified sets the Last-Modified header on w to the last time any row in any of the given tables was modified.
+//
+// This should be called before the data is read, so a modification made while the request is served makes the header older than the data, and not newer.
+//
+// HTTP dates have a resolution of a second, so if the last modification was less than a second ago, no header is set, because a later modification within the same second would be indistinguishable.
+func SetLastModified(w http.ResponseWriter, tx *sql.Tx, tables []string) error {
+	lastModified, now, err := GetLastModified(tx, tables)
+	if err != nil {
+		return err
+	}
+	if lastModified.IsZero() || now.Sub(lastModified) < time.Second {
+		return nil
+	}
+	w.Header().Set(rfc.LastModified, lastModified.UTC().Format(http.TimeFormat))
+	return nil
+}
+
+// writeConditional sets the ETag of the response body bts on w, and if the request r's preconditions show the client already has the representation, writes a 304 Not Modified and returns true.
+// Only GET and HEAD requests are conditional; for any other method, this does nothing and returns false.
+func writeConditional(w http.ResponseWriter, r *http.Request, bts []byte) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	w.Header().Set(rfc.ETagHeader, rfc.ETag(bts))
+	if !notModified(r, w.Header()) {
+		return false
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// notModified returns whether the request's If-None-Match or If-Modified-Since header shows the client's copy is current, per RFC7232§6.
+// The If-Modified-Since header is ignored if the request has an If-None-Match header.
+func notModified(r *http.Request, respHdr http.Header) bool {
+	if ifNoneMatch := r.Header.Get(rfc.IfNoneMatch); ifNoneMatch != "" {
+		return rfc.ETagMatches(ifNoneMatch, respHdr.Get(rfc.ETagHeader))
+	}
+	ifModifiedSinceStr := r.Header.Get(rfc.IfModifiedSince)
+	lastModifiedStr := respHdr.Get(rfc.LastModified)
+	if ifModifiedSinceStr == "" || lastModifiedStr == "" {
+		return false
+	}
+	ifModifiedSince, err := http.ParseTime(ifModifiedSinceStr)
+	if err != nil {
+		return false // RFC7232§3.3 requires ignoring invalid dates
+	}
+	lastModified, err := http.ParseTime(lastModifiedStr)
+	if err != nil {
+		return false
+	}
+	return !lastModified.After(ifModifiedSince)
+}
+
+// CheckPreconditions checks the If-Match and If-Unmodified-Since headers of a request to change or delete an object, per RFC7232§3.1 and RFC7232§3.4.
+// If the object was changed since the given ETag or time, it returns a 412 Precondition Failed user error.
+//
+// The object is read by calling Read on reader, a new object of the type being changed, with only the given params, which must identify the object. So the ETag If-Match is compared to is the ETag of a GET request with those query parameters.
+// If-Unmodified-Since is compared to the lastUpdated time of the object. Per RFC7232§3.4, it is ignored if the request has an If-Match header, or an invalid date.
+//
+// If the request has neither header, nothing is read, and no error is returned. Otherwise, this locks the object until the transaction ends, so two conditional requests can't both succeed; callers must make their change in the same transaction.
+func CheckPreconditions(r *http.Request, inf *APIInfo, reader Reader, params map[string]string) (error, error, int) {
+	ifMatch := r.Header.Get(rfc.IfMatch)
+	ifUnmodifiedSinceStr := r.Header.Get(rfc.IfUnmodifiedSince)
+	if ifMatch == "" && ifUnmodifiedSinceStr == "" {
+		return nil, nil, http.StatusOK
+	}
+
+	if _, err := inf.Tx.Tx.Exec(`SELECT pg_advisory_xact_lock(hashtext($1))`, preconditionLockKey(reader, params)); err != nil {
+		return nil, errors.New("locking object for precondition check: " + err.Error()), http.StatusInternalServerError
+	}
+
+	readInf := *inf
+	readInf.Params = params
+	reader.SetInfo(&readInf)
+	current, userErr, sysErr, errCode := reader.Read()
+	if userErr != nil || sysErr != nil {
+		return userErr, sysErr, errCode
+	}
+
+	if ifMatch != "" {
+		if len(current) == 0 {
+			return errors.New("If-Match precondition failed: the object does not exist"), nil, http.StatusPreconditionFailed
+		}
+		bts, err := json.Marshal(struct {
+			Response interface{} `json:"response"`
+		}{current})
+		if err != nil {
+			return nil, errors.New("marshalling object for precondition check: " + err.Error()), http.StatusInternalServerError
+		}
+		if !rfc.ETagMatchesStrong(ifMatch, rfc.ETag(bts)) {
+			return errors.New("If-Match precondition failed: the object was changed"), nil, http.StatusPreconditionFailed
+		}
+		return nil, nil, http.StatusOK
+	}
+
+	ifUnmodifiedSince, err := http.ParseTime(ifUnmodifiedSinceStr)
+	if err != nil {
+		return nil, nil, http.StatusOK // RFC7232§3.4 requires ignoring invalid dates
+	}
+	lastUpdated, ok := latestLastUpdated(current)
+	if !ok {
+		return nil, nil, http.StatusOK // the object doesn't exist, or has no modification time, so there's no modification to have happened
+	}
+	if lastUpdated.Truncate(time.Second).After(ifUnmodifiedSince) {
+		return errors.New("If-Unmodified-Since precondition failed: the object was changed at " + lastUpdated.UTC().Format(http.TimeFormat)), nil, http.StatusPreconditionFailed
+	}
+	return nil, nil, http.StatusOK
+}
+
+// preconditionLockKey returns the key of the transaction lock CheckPreconditions takes on the object of type reader with the given params.
+func preconditionLockKey(reader Reader, params map[string]string) string {
+	keys := make([]string, 0, len(params))
+	for key, val := range params {
+		keys = append(keys, key+"="+val)
+	}
+	sort.Strings(keys)
+	return fmt.Sprintf("%T", reader) + "?" + strings.Join(keys, "&")
+}
+
+// latestLastUpdated returns the latest LastUpdated field of the given objects, and whether any of them had one.
+func latestLastUpdated(objs []interface{}) (time.Time, bool) {
+	latest := time.Time{}
+	found := false
+	for _, obj := range objs {
+		val := reflect.Indirect(reflect.ValueOf(obj))
+		if val.Kind() != reflect.Struct {
+			continue
+		}
+		field := val.FieldByName("LastUpdated")
+		if !field.IsValid() || !field.CanInterface() {
+			continue
+		}
+		lastUpdated := time.Time{}
+		switch fieldVal := field.Interface().(type) {
+		case tc.TimeNoMod:
+			lastUpdated = fieldVal.Time
+		case *tc.TimeNoMod:
+			if fieldVal == nil {
+				continue
+			}
+			lastUpdated = fieldVal.Time
+		case tc.Time:
+			lastUpdated = fieldVal.Time
+		case *tc.Time:
+			if fieldVal == nil {
+				continue
+			}
+			lastUpdated = fieldVal.Time
+		default:
+			continue
+		}
+		if !found || lastUpdated.After(latest) {
+			latest = lastUpdated
+			found = true
+		}
+	}
+	return latest, found
+}
+
+// checkPreconditions calls CheckPreconditions for the object of the given type with the given identifier, for the shared handlers.
+// The object is identified by its key fields, or if deleteKeyOptions is true, by the delete key options in the request.
+// Types which can't be read can't have their preconditions checked, so requests to change them with preconditions fail.
+func checkPreconditions(r *http.Request, inf *APIInfo, objectType reflect.Type, obj Identifier, deleteKeyOptions bool) (error, error, int) {
+	reader, ok := reflect.New(objectType).Interface().(Reader)
+	if !ok {
+		if r.Header.Get(rfc.IfMatch) != "" || r.Header.Get(rfc.IfUnmodifiedSince) != "" {
+			return errors.New("If-Match and If-Unmodified-Since are not supported for " + obj.GetType()), nil, http.StatusPreconditionFailed
+		}
+		return nil, nil, http.StatusOK
+	}
+
+	params := map[string]string{}
+	if deleteKeyOptions {
+		for key := range obj.(HasDeleteKeyOptions).DeleteKeyOptions() {
+			if val := inf.Params[key]; val != "" {
+				params[key] = val
+			}
+		}
+	} else {
+		for _, kf := range obj.GetKeyFieldsInfo() {
+			params[kf.Field] = inf.Params[kf.Field]
+		}
+	}
+	return CheckPreconditions(r, inf, reader, params)
+}
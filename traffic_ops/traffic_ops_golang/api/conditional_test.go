@@ -0,0 +1,246 @@
+package api
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-rfc"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/jmoiron/sqlx"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+type lastModifiedTester struct {
+	tester
+}
+
+func (i *lastModifiedTester) LastModifiedTables() []string {
+	return []string{"tester"}
+}
+
+type lastUpdatedTester struct {
+	ID          int
+	LastUpdated *tc.TimeNoMod
+}
+
+// lastUpdatedReader is a Reader whose object was last updated at the given time.
+type lastUpdatedReader struct {
+	APIInfoImpl
+	lastUpdated time.Time
+}
+
+func (rd *lastUpdatedReader) Read() ([]interface{}, error, error, int) {
+	return []interface{}{lastUpdatedTester{ID: 1, LastUpdated: &tc.TimeNoMod{Time: rd.lastUpdated}}}, nil, nil, http.StatusOK
+}
+
+func TestNotModified(t *testing.T) {
+	lastModified := time.Date(2020, 4, 7, 12, 0, 0, 0, time.UTC)
+	respHdr := http.Header{}
+	respHdr.Set(rfc.ETagHeader, `"abc"`)
+	respHdr.Set(rfc.LastModified, lastModified.Format(http.TimeFormat))
+
+	tests := []struct {
+		name     string
+		reqHdr   map[string]string
+		expected bool
+	}{
+		{"unconditional", map[string]string{}, false},
+		{"matching etag", map[string]string{rfc.IfNoneMatch: `"abc"`}, true},
+		{"mismatched etag", map[string]string{rfc.IfNoneMatch: `"xyz"`}, false},
+		{"same time", map[string]string{rfc.IfModifiedSince: lastModified.Format(http.TimeFormat)}, true},
+		{"later time", map[string]string{rfc.IfModifiedSince: lastModified.Add(time.Hour).Format(http.TimeFormat)}, true},
+		{"earlier time", map[string]string{rfc.IfModifiedSince: lastModified.Add(-time.Second).Format(http.TimeFormat)}, false},
+		{"invalid time", map[string]string{rfc.IfModifiedSince: "yesterday"}, false},
+		{"mismatched etag takes precedence over time", map[string]string{rfc.IfNoneMatch: `"xyz"`, rfc.IfModifiedSince: lastModified.Format(http.TimeFormat)}, false},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		for k, v := range test.reqHdr {
+			r.Header.Set(k, v)
+		}
+		if actual := notModified(r, respHdr); actual != test.expected {
+			t.Errorf("notModified %s expected %v actual %v", test.name, test.expected, actual)
+		}
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(rfc.IfModifiedSince, lastModified.Format(http.TimeFormat))
+	if notModified(r, http.Header{}) {
+		t.Errorf("notModified with no Last-Modified header expected false actual true")
+	}
+}
+
+func TestWriteRespConditional(t *testing.T) {
+	w := httptest.NewRecorder()
+	WriteResp(w, httptest.NewRequest(http.MethodGet, "/", nil), "foo")
+	etag := w.Header().Get(rfc.ETagHeader)
+	if w.Code != http.StatusOK || etag == "" {
+		t.Fatalf("WriteResp expected 200 with an ETag, actual %d ETag '%s'", w.Code, etag)
+	}
+
+	w = httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(rfc.IfNoneMatch, etag)
+	WriteResp(w, r, "foo")
+	if w.Code != http.StatusNotModified {
+		t.Errorf("WriteResp with matching If-None-Match expected 304, actual %d", w.Code)
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("WriteResp with matching If-None-Match expected no body, actual '%s'", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(rfc.IfNoneMatch, etag)
+	WriteResp(w, r, "bar")
+	if w.Code != http.StatusOK {
+		t.Errorf("WriteResp with changed body expected 200, actual %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodPost, "/", nil)
+	r.Header.Set(rfc.IfNoneMatch, etag)
+	WriteResp(w, r, "foo")
+	if w.Code != http.StatusOK || w.Header().Get(rfc.ETagHeader) != "" {
+		t.Errorf("WriteResp POST expected 200 with no ETag, actual %d ETag '%s'", w.Code, w.Header().Get(rfc.ETagHeader))
+	}
+}
+
+func TestReadHandlerLastModified(t *testing.T) {
+	lastModified := time.Date(2020, 4, 7, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                 string
+		now                  time.Time
+		ifModifiedSince      time.Time
+		expectedCode         int
+		expectedLastModified string
+	}{
+		{"unmodified", lastModified.Add(time.Hour), lastModified, http.StatusNotModified, lastModified.Format(http.TimeFormat)},
+		{"modified", lastModified.Add(time.Hour), lastModified.Add(-time.Hour), http.StatusOK, lastModified.Format(http.TimeFormat)},
+		{"modified within the second", lastModified.Add(time.Millisecond), lastModified, http.StatusOK, ""},
+	}
+
+	for _, test := range tests {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		db := sqlx.NewDb(mockDB, "sqlmock")
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set(rfc.IfModifiedSince, test.ifModifiedSince.Format(http.TimeFormat))
+
+		ctx := r.Context()
+		ctx = context.WithValue(ctx, auth.CurrentUserKey,
+			auth.CurrentUser{UserName: "username", ID: 1, PrivLevel: auth.PrivLevelAdmin})
+		ctx = context.WithValue(ctx, PathParamsKey, map[string]string{})
+		ctx = context.WithValue(ctx, DBContextKey, db)
+		ctx = context.WithValue(ctx, ConfigContextKey, &cfg)
+		ctx = context.WithValue(ctx, ReqIDContextKey, uint64(0))
+		r = r.WithContext(ctx)
+
+		mock.ExpectBegin()
+		rows := sqlmock.NewRows([]string{"greatest", "clock_timestamp"}).AddRow(lastModified, test.now)
+		mock.ExpectQuery("SELECT GREATEST").WillReturnRows(rows)
+		mock.ExpectCommit()
+
+		ReadHandler(&lastModifiedTester{})(w, r)
+
+		if w.Code != test.expectedCode {
+			t.Errorf("ReadHandler %s expected code %d, actual %d", test.name, test.expectedCode, w.Code)
+		}
+		if actual := w.Header().Get(rfc.LastModified); actual != test.expectedLastModified {
+			t.Errorf("ReadHandler %s expected Last-Modified '%s', actual '%s'", test.name, test.expectedLastModified, actual)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("ReadHandler %s expected all queries to be made: %v", test.name, err)
+		}
+		db.Close()
+	}
+}
+
+func TestCheckPreconditions(t *testing.T) {
+	lastUpdated := time.Date(2020, 4, 7, 12, 0, 0, 500000000, time.UTC)
+	currentETag := rfc.ETag([]byte(`{"response":[{"ID":1}]}`))
+
+	tests := []struct {
+		name         string
+		reader       Reader
+		reqHdr       map[string]string
+		expectedCode int
+	}{
+		{"unconditional", &tester{}, map[string]string{}, http.StatusOK},
+		{"matching etag", &tester{}, map[string]string{rfc.IfMatch: currentETag}, http.StatusOK},
+		{"any etag", &tester{}, map[string]string{rfc.IfMatch: "*"}, http.StatusOK},
+		{"changed etag", &tester{}, map[string]string{rfc.IfMatch: `"abc"`}, http.StatusPreconditionFailed},
+		{"weak etag", &tester{}, map[string]string{rfc.IfMatch: "W/" + currentETag}, http.StatusPreconditionFailed},
+		{"unmodified", &lastUpdatedReader{lastUpdated: lastUpdated}, map[string]string{rfc.IfUnmodifiedSince: lastUpdated.Format(http.TimeFormat)}, http.StatusOK},
+		{"modified", &lastUpdatedReader{lastUpdated: lastUpdated}, map[string]string{rfc.IfUnmodifiedSince: lastUpdated.Add(-time.Second).Format(http.TimeFormat)}, http.StatusPreconditionFailed},
+		{"invalid time", &lastUpdatedReader{lastUpdated: lastUpdated}, map[string]string{rfc.IfUnmodifiedSince: "yesterday"}, http.StatusOK},
+		{"etag takes precedence over time", &tester{}, map[string]string{rfc.IfMatch: currentETag, rfc.IfUnmodifiedSince: lastUpdated.Add(-time.Hour).Format(http.TimeFormat)}, http.StatusOK},
+	}
+
+	for _, test := range tests {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		db := sqlx.NewDb(mockDB, "sqlmock")
+
+		mock.ExpectBegin()
+		if len(test.reqHdr) > 0 {
+			mock.ExpectExec("pg_advisory_xact_lock").WithArgs(fmt.Sprintf("%T?id=1", test.reader)).WillReturnResult(sqlmock.NewResult(0, 0))
+		}
+		tx, err := db.Beginx()
+		if err != nil {
+			t.Fatalf("beginning transaction: %v", err)
+		}
+		inf := &APIInfo{Tx: tx, Params: map[string]string{"id": "1", "foo": "bar"}}
+
+		r := httptest.NewRequest(http.MethodPut, "/", nil)
+		for k, v := range test.reqHdr {
+			r.Header.Set(k, v)
+		}
+
+		userErr, sysErr, errCode := CheckPreconditions(r, inf, test.reader, map[string]string{"id": "1"})
+		if sysErr != nil {
+			t.Errorf("CheckPreconditions %s expected no system error, actual %v", test.name, sysErr)
+		}
+		if test.expectedCode == http.StatusOK && userErr != nil {
+			t.Errorf("CheckPreconditions %s expected no user error, actual %v", test.name, userErr)
+		} else if test.expectedCode != http.StatusOK && (userErr == nil || errCode != test.expectedCode) {
+			t.Errorf("CheckPreconditions %s expected code %d, actual %d (user error %v)", test.name, test.expectedCode, errCode, userErr)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("CheckPreconditions %s expected all queries to be made: %v", test.name, err)
+		}
+		db.Close()
+	}
+}
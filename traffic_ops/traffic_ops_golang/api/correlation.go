@@ -0,0 +1,121 @@
+package api
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+// MaxCorrelationIDLen is the length of the longest correlation ID Traffic Ops accepts from a
+// client. It's short enough for the ID to fit in the PostgreSQL application_name of the request's
+// transaction.
+const MaxCorrelationIDLen = 48
+
+// CorrelationIDSetting is the PostgreSQL setting which holds the correlation ID of the request in
+// the request's transaction, from which it's stored on change log rows.
+const CorrelationIDSetting = "traffic_ops.correlation_id"
+
+// NewCorrelationID returns a new, random correlation ID.
+func NewCorrelationID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		log.Errorln("generating correlation ID: " + err.Error())
+	}
+	return hex.EncodeToString(b)
+}
+
+// IsValidCorrelationID returns whether a correlation ID sent by a client may be used. It must be
+// no longer than MaxCorrelationIDLen, and only contain letters, digits, '-', '_', '.' and ':', so
+// it can't be used to forge log lines or headers.
+func IsValidCorrelationID(id string) bool {
+	if id == "" || len(id) > MaxCorrelationIDLen {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.' || c == ':') {
+			return false
+		}
+	}
+	return true
+}
+
+// RequestCorrelationID returns the correlation ID the client sent in the request, if it's valid,
+// or else a new one.
+func RequestCorrelationID(r *http.Request) string {
+	if id := r.Header.Get(tc.CorrelationIDHeader); IsValidCorrelationID(id) {
+		return id
+	}
+	return NewCorrelationID()
+}
+
+// GetCorrelationID returns the correlation ID of the request with the given context, or an empty
+// string if it has none.
+func GetCorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(CorrelationIDContextKey).(string)
+	return id
+}
+
+// setTxCorrelationID sets the correlation ID of the request in its transaction, both in
+// CorrelationIDSetting and in the application_name, which PostgreSQL can include in its logs.
+func setTxCorrelationID(tx *sql.Tx, correlationID string) error {
+	_, err := tx.Exec(`SELECT set_config('application_name', $1, true), set_config('`+CorrelationIDSetting+`', $2, true)`, "traffic_ops "+correlationID, correlationID)
+	return err
+}
+
+// CorrelatedClient returns a copy of the client which sends the correlation ID in the
+// tc.CorrelationIDHeader of every request, so that services Traffic Ops calls while handling a
+// request can log its correlation ID. If the correlation ID is empty, the client is returned
+// unchanged.
+func CorrelatedClient(client *http.Client, correlationID string) *http.Client {
+	if correlationID == "" {
+		return client
+	}
+	transport := client.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	correlated := *client
+	correlated.Transport = correlationIDTransport{id: correlationID, transport: transport}
+	return &correlated
+}
+
+type correlationIDTransport struct {
+	id        string
+	transport http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper. It sends a copy of the request with the correlation ID
+// header, because a RoundTripper mustn't modify the request it's given.
+func (t correlationIDTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	correlated := *r
+	correlated.Header = make(http.Header, len(r.Header)+1)
+	for name, vals := range r.Header {
+		correlated.Header[name] = vals
+	}
+	correlated.Header.Set(tc.CorrelationIDHeader, t.id)
+	return t.transport.RoundTrip(&correlated)
+}
@@ -0,0 +1,110 @@
+package api
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+func TestIsValidCorrelationID(t *testing.T) {
+	tests := map[string]bool{
+		"":                                       false,
+		"0b6b1d1c9e7e4f0a8c3d2b1a0f9e8d7c":       true,
+		"3f2a3c9e-1b7d-4c58-9a6e-0d4f5e6a7b8c":   true,
+		"portal:1234.5_6":                        true,
+		"has space":                              false,
+		"new\nline":                              false,
+		"quote\"":                                false,
+		strings.Repeat("a", MaxCorrelationIDLen): true,
+		strings.Repeat("a", MaxCorrelationIDLen+1): false,
+	}
+	for id, expected := range tests {
+		if actual := IsValidCorrelationID(id); actual != expected {
+			t.Errorf("IsValidCorrelationID(%q) expected: %v, actual: %v", id, expected, actual)
+		}
+	}
+	if id := NewCorrelationID(); !IsValidCorrelationID(id) {
+		t.Errorf("expected NewCorrelationID to return a valid correlation ID, actual: %q", id)
+	}
+	if NewCorrelationID() == NewCorrelationID() {
+		t.Errorf("expected NewCorrelationID to return different correlation IDs")
+	}
+}
+
+func TestRequestCorrelationID(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/api/2.0/cdns", nil)
+	r.Header.Set(tc.CorrelationIDHeader, "client-id")
+	if actual := RequestCorrelationID(r); actual != "client-id" {
+		t.Errorf("expected the client's correlation ID client-id, actual: %s", actual)
+	}
+	r.Header.Set(tc.CorrelationIDHeader, "forged\n127.0.0.1 - admin")
+	if actual := RequestCorrelationID(r); actual == r.Header.Get(tc.CorrelationIDHeader) || !IsValidCorrelationID(actual) {
+		t.Errorf("expected an invalid correlation ID to be replaced, actual: %q", actual)
+	}
+}
+
+func TestCorrelatedClient(t *testing.T) {
+	received := ""
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get(tc.CorrelationIDHeader)
+	}))
+	defer srv.Close()
+
+	client := &http.Client{}
+	if CorrelatedClient(client, "") != client {
+		t.Errorf("expected the client unchanged without a correlation ID")
+	}
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	resp, err := CorrelatedClient(client, "abc").Do(req)
+	if err != nil {
+		t.Fatalf("expected no error, actual: %v", err)
+	}
+	resp.Body.Close()
+	if received != "abc" {
+		t.Errorf("expected the correlation ID abc to be sent, actual: %q", received)
+	}
+	if req.Header.Get(tc.CorrelationIDHeader) != "" {
+		t.Errorf("expected the request not to be modified, actual header: %q", req.Header.Get(tc.CorrelationIDHeader))
+	}
+	if client.Transport != nil {
+		t.Errorf("expected the original client not to be modified")
+	}
+}
+
+func TestLogErrCorrelationID(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/api/2.0/cdns", nil)
+	r = r.WithContext(context.WithValue(r.Context(), CorrelationIDContextKey, "abc"))
+
+	userErr := LogErr(r, http.StatusInternalServerError, nil, errors.New("database is down"))
+	if expected := "Internal Server Error (correlation ID abc)"; userErr.Error() != expected {
+		t.Errorf("expected server error %s, actual: %s", expected, userErr.Error())
+	}
+	userErr = LogErr(r, http.StatusBadRequest, errors.New("bad name"), nil)
+	if expected := "bad name"; userErr.Error() != expected {
+		t.Errorf("expected user error %s, actual: %s", expected, userErr.Error())
+	}
+}
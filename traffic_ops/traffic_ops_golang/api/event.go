@@ -0,0 +1,77 @@
+package api
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+)
+
+// createEventQuery inserts an Event, and a pending delivery of it to each active Webhook with an
+// event type pattern that matches it - see tc.EventTypeMatches.
+const createEventQuery = `
+WITH e AS (
+	INSERT INTO event (event_type, username, data)
+	VALUES ($1, $2, $3)
+	RETURNING id
+)
+INSERT INTO webhook_delivery (webhook, event)
+SELECT webhook.id, e.id
+FROM webhook, e
+WHERE webhook.active
+AND EXISTS (
+	SELECT 1
+	FROM unnest(webhook.event_types) AS pattern
+	WHERE pattern = '` + tc.EventTypeWildcard + `'
+	OR pattern = $1
+	OR (right(pattern, 2) = '.*' AND left($1, length(pattern) - 1) = left(pattern, length(pattern) - 1))
+)
+`
+
+// CreateEvent records an Event of the given type, made by the given user, with the given data
+// serialized as JSON. The Event is delivered to the event stream and any matching Webhooks if and
+// only if tx is committed.
+func CreateEvent(tx *sql.Tx, eventType string, user *auth.CurrentUser, data interface{}) error {
+	bts, err := json.Marshal(data)
+	if err != nil {
+		return errors.New("marshalling event data: " + err.Error())
+	}
+	if _, err := tx.Exec(createEventQuery, eventType, user.UserName, string(bts)); err != nil {
+		return errors.New("inserting event: " + err.Error())
+	}
+	return nil
+}
+
+// CreateObjectEvent records the Event for the given action - one of the tc.EventAction constants -
+// having been taken on the given object by the given user. For deletions, the Event data is the
+// object's keys, otherwise it is the object itself, or its EventData if it implements HasEventData.
+func CreateObjectEvent(tx *sql.Tx, action string, i Identifier, user *auth.CurrentUser) error {
+	data := interface{}(i)
+	if action == tc.EventActionDeleted {
+		data, _ = i.GetKeys()
+	} else if ed, ok := i.(HasEventData); ok {
+		data = ed.EventData()
+	}
+	return CreateEvent(tx, tc.EventTypeFor(i.GetType(), action), user, data)
+}
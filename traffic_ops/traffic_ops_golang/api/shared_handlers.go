@@ -152,6 +152,13 @@ func ReadHandler(reader Reader) http.HandlerFunc {
 		obj := reflect.New(objectType).Interface().(Reader)
 		obj.SetInfo(inf)
 
+		if lm, ok := obj.(HasLastModifiedTables); ok {
+			if err := SetLastModified(w, inf.Tx.Tx, lm.LastModifiedTables()); err != nil {
+				HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, err)
+				return
+			}
+		}
+
 		results, userErr, sysErr, errCode := obj.Read()
 		if userErr != nil || sysErr != nil {
 			HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
@@ -272,6 +279,11 @@ func UpdateHandler(updater Updater) http.HandlerFunc {
 			}
 		}
 
+		if userErr, sysErr, errCode := checkPreconditions(r, inf, objectType, obj, false); userErr != nil || sysErr != nil {
+			HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+			return
+		}
+
 		userErr, sysErr, errCode = obj.Update()
 		if userErr != nil || sysErr != nil {
 			HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
@@ -282,6 +294,10 @@ func UpdateHandler(updater Updater) http.HandlerFunc {
 			HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, tc.DBError, errors.New("inserting changelog: "+err.Error()))
 			return
 		}
+		if err := CreateObjectEvent(inf.Tx.Tx, tc.EventActionUpdated, obj, inf.User); err != nil {
+			HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("creating event: "+err.Error()))
+			return
+		}
 		WriteRespAlertObj(w, r, tc.SuccessLevel, obj.GetType()+" was updated.", obj)
 	}
 }
@@ -347,6 +363,11 @@ func DeleteHandler(deleter Deleter) http.HandlerFunc {
 			}
 		}
 
+		if userErr, sysErr, errCode := checkPreconditions(r, inf, objectType, obj, deleteKeyOptionExists); userErr != nil || sysErr != nil {
+			HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+			return
+		}
+
 		if deleteKeyOptionExists {
 			obj := reflect.New(objectType).Interface().(OptionsDeleter)
 			obj.SetInfo(inf)
@@ -364,6 +385,10 @@ func DeleteHandler(deleter Deleter) http.HandlerFunc {
 			HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("inserting changelog: "+err.Error()))
 			return
 		}
+		if err := CreateObjectEvent(inf.Tx.Tx, tc.EventActionDeleted, obj, inf.User); err != nil {
+			HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("creating event: "+err.Error()))
+			return
+		}
 		WriteRespAlert(w, r, tc.SuccessLevel, obj.GetType()+" was deleted.")
 	}
 }
@@ -429,6 +454,11 @@ func DeprecatedDeleteHandler(deleter Deleter, alternative *string) http.HandlerF
 			}
 		}
 
+		if userErr, sysErr, errCode := checkPreconditions(r, inf, objectType, obj, deleteKeyOptionExists); userErr != nil || sysErr != nil {
+			HandleDeprecatedErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr, alternative)
+			return
+		}
+
 		if deleteKeyOptionExists {
 			obj := reflect.New(objectType).Interface().(OptionsDeleter)
 			obj.SetInfo(inf)
@@ -447,6 +477,10 @@ func DeprecatedDeleteHandler(deleter Deleter, alternative *string) http.HandlerF
 			HandleDeprecatedErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("inserting changelog: "+err.Error()), alternative)
 			return
 		}
+		if err := CreateObjectEvent(inf.Tx.Tx, tc.EventActionDeleted, obj, inf.User); err != nil {
+			HandleDeprecatedErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("creating event: "+err.Error()), alternative)
+			return
+		}
 		alerts := CreateDeprecationAlerts(alternative)
 		alerts.AddNewAlert(tc.SuccessLevel, obj.GetType()+" was deleted.")
 
@@ -522,6 +556,10 @@ func CreateHandler(creator Creator) http.HandlerFunc {
 					HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, tc.DBError, errors.New("inserting changelog: "+err.Error()))
 					return
 				}
+				if err = CreateObjectEvent(inf.Tx.Tx, tc.EventActionCreated, objElem, inf.User); err != nil {
+					HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("creating event: "+err.Error()))
+					return
+				}
 			}
 			if len(objSlice) == 0 {
 				WriteRespAlert(w, r, tc.SuccessLevel, "No objects were provided in request.")
@@ -560,6 +598,10 @@ func CreateHandler(creator Creator) http.HandlerFunc {
 				HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, tc.DBError, errors.New("inserting changelog: "+err.Error()))
 				return
 			}
+			if err = CreateObjectEvent(inf.Tx.Tx, tc.EventActionCreated, obj, inf.User); err != nil {
+				HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("creating event: "+err.Error()))
+				return
+			}
 			WriteRespAlertObj(w, r, tc.SuccessLevel, obj.GetType()+" was created.", obj)
 		}
 	}
@@ -106,6 +106,19 @@ type HasDeleteKeyOptions interface {
 	DeleteKeyOptions() map[string]dbhelpers.WhereColumnInfo
 }
 
+// HasLastModifiedTables is an optional interface for Readers, which allows ReadHandler to set a Last-Modified header and answer If-Modified-Since requests.
+// LastModifiedTables must return every table the read selects from; each must have a last_updated column with an index, and an on_delete_current_timestamp trigger.
+type HasLastModifiedTables interface {
+	LastModifiedTables() []string
+}
+
+// HasEventData is an optional interface for objects using the shared handlers, which allows them to
+// replace the data of their Events - for instance to remove passwords, which must not be stored with
+// the Event, sent to Webhooks or streamed.
+type HasEventData interface {
+	EventData() interface{}
+}
+
 // APIInfoer is an interface that guarantees the existance of a variable through its setters and getters.
 // Every CRUD operation uses this login session context
 type APIInfoer interface {
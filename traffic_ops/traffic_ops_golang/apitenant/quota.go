@@ -0,0 +1,208 @@
+package apitenant
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
+)
+
+const upsertQuotaQuery = `
+INSERT INTO tenant_quota (tenant, max_delivery_services, max_edge_servers, max_invalidation_jobs_per_day, max_regexes_per_ds)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (tenant) DO UPDATE SET
+	max_delivery_services = EXCLUDED.max_delivery_services,
+	max_edge_servers = EXCLUDED.max_edge_servers,
+	max_invalidation_jobs_per_day = EXCLUDED.max_invalidation_jobs_per_day,
+	max_regexes_per_ds = EXCLUDED.max_regexes_per_ds
+RETURNING last_updated
+`
+
+// GetQuota is the handler for GET requests to /tenants/{id}/quota. A Tenant without a quota has
+// one with no limits.
+func GetQuota(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"id"}, []string{"id"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	id := inf.IntParams["id"]
+	name, userErr, sysErr, errCode := checkQuotaTenant(inf, id, false)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	quota, ok, err := tenant.GetQuota(inf.Tx.Tx, id)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, err)
+		return
+	}
+	if !ok {
+		quota = tc.TenantQuota{TenantID: &id, TenantName: &name}
+	}
+	api.WriteResp(w, r, quota)
+}
+
+// UpdateQuota is the handler for PUT requests to /tenants/{id}/quota, which sets the Tenant's quota.
+// Users may only set the quotas of Tenants below their own, so the root Tenant can't have a quota.
+//
+// Lowering a limit below the current usage is allowed; it only prevents the resource from being
+// added to until usage is back within the limit.
+func UpdateQuota(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"id"}, []string{"id"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	quota := tc.TenantQuota{}
+	if err := json.NewDecoder(r.Body).Decode(&quota); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, errors.New("malformed JSON: "+err.Error()), nil)
+		return
+	}
+	if err := quota.Validate(); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, err, nil)
+		return
+	}
+
+	id := inf.IntParams["id"]
+	name, userErr, sysErr, errCode := checkQuotaTenant(inf, id, true)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	quota.TenantID = &id
+	quota.TenantName = &name
+	if err := inf.Tx.Tx.QueryRow(upsertQuotaQuery, id, quota.MaxDeliveryServices, quota.MaxEdgeServers, quota.MaxInvalidationJobsPerDay, quota.MaxRegexesPerDeliveryService).Scan(&quota.LastUpdated); err != nil {
+		userErr, sysErr, errCode = api.ParseDBError(err)
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+
+	api.CreateChangeLogRawTx(api.ApiChange, "TENANT: "+name+", ID: "+inf.Params["id"]+", ACTION: Set quota", inf.User, inf.Tx.Tx)
+	api.WriteRespAlertObj(w, r, tc.SuccessLevel, "Tenant quota was updated.", quota)
+}
+
+// DeleteQuota is the handler for DELETE requests to /tenants/{id}/quota, which removes all of the
+// Tenant's limits.
+func DeleteQuota(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"id"}, []string{"id"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	name, userErr, sysErr, errCode := checkQuotaTenant(inf, inf.IntParams["id"], true)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	result, err := inf.Tx.Tx.Exec(`DELETE FROM tenant_quota WHERE tenant = $1`, inf.IntParams["id"])
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("deleting tenant quota: "+err.Error()))
+		return
+	}
+	if rows, err := result.RowsAffected(); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("deleting tenant quota: getting rows affected: "+err.Error()))
+		return
+	} else if rows == 0 {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("tenant "+name+" has no quota"), nil)
+		return
+	}
+
+	api.CreateChangeLogRawTx(api.ApiChange, "TENANT: "+name+", ID: "+inf.Params["id"]+", ACTION: Deleted quota", inf.User, inf.Tx.Tx)
+	api.WriteRespAlert(w, r, tc.SuccessLevel, "Tenant quota was deleted.")
+}
+
+// GetUsage is the handler for GET requests to /tenants/usage, which reports the usage of each
+// Tenant visible to the user - or only the one with the ID given in the "id" query parameter -
+// against its quota.
+func GetUsage(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, nil, []string{"id"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	tenantIDs, err := tenant.GetUserTenantIDListTx(inf.Tx.Tx, inf.User.TenantID)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting user tenants: "+err.Error()))
+		return
+	}
+
+	usages := []tc.TenantUsage{}
+	for _, tenantID := range tenantIDs {
+		if id, ok := inf.IntParams["id"]; ok && id != tenantID {
+			continue
+		}
+		usage, err := tenant.GetUsage(inf.Tx.Tx, tenantID)
+		if err != nil {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, err)
+			return
+		}
+		usages = append(usages, usage)
+	}
+	sort.Slice(usages, func(i, j int) bool { return usages[i].TenantName < usages[j].TenantName })
+	api.WriteResp(w, r, usages)
+}
+
+// checkQuotaTenant checks that the Tenant with the given ID exists and that the user may see - or,
+// if modify is true, set - its quota, and returns its name.
+func checkQuotaTenant(inf *api.APIInfo, id int, modify bool) (string, error, error, int) {
+	name := ""
+	parentID := (*int)(nil)
+	if err := inf.Tx.Tx.QueryRow(`SELECT name, parent_id FROM tenant WHERE id = $1`, id).Scan(&name, &parentID); err != nil {
+		if err == sql.ErrNoRows {
+			return "", errors.New("no tenant with id " + inf.Params["id"]), nil, http.StatusNotFound
+		}
+		return "", nil, errors.New("getting tenant: " + err.Error()), http.StatusInternalServerError
+	}
+
+	authorized, err := tenant.IsResourceAuthorizedToUserTx(id, inf.User, inf.Tx.Tx)
+	if err != nil {
+		return "", nil, errors.New("checking tenant: " + err.Error()), http.StatusInternalServerError
+	}
+	if !authorized {
+		return "", errors.New("not authorized on this tenant"), nil, http.StatusForbidden
+	}
+	if !modify {
+		return name, nil, nil, http.StatusOK
+	}
+
+	if parentID == nil {
+		return "", errors.New("the root tenant cannot have a quota"), nil, http.StatusBadRequest
+	}
+	if id == inf.User.TenantID {
+		return "", errors.New("users cannot change the quota of their own tenant"), nil, http.StatusForbidden
+	}
+	return name, nil, nil, http.StatusOK
+}
@@ -102,6 +102,11 @@ func postDSes(tx *sql.Tx, user *auth.CurrentUser, cgID int64, dsIDs []int64) (tc
 	if err := insertCachegroupDSes(tx, cgID, dsIDs); err != nil {
 		return tc.CacheGroupPostDSResp{}, nil, errors.New("inserting cachegroup delivery services: " + err.Error()), http.StatusInternalServerError
 	}
+	for _, dsID := range dsIDs {
+		if userErr, sysErr, errCode := tenant.CheckDeliveryServiceQuota(tx, int(dsID), tenant.QuotaEdgeServers); userErr != nil || sysErr != nil {
+			return tc.CacheGroupPostDSResp{}, userErr, sysErr, errCode
+		}
+	}
 
 	if err := updateParams(tx, dsIDs); err != nil {
 		return tc.CacheGroupPostDSResp{}, nil, errors.New("updating delivery service parameters: " + err.Error()), http.StatusInternalServerError
@@ -81,13 +81,23 @@ func QueueUpdates(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	api.WriteResp(w, r, QueueUpdatesResp{
+	resp := QueueUpdatesResp{
 		CacheGroupName: cgName,
 		Action:         reqObj.Action,
 		ServerNames:    updatedCaches,
 		CDN:            *reqObj.CDN,
 		CacheGroupID:   cgID,
-	})
+	}
+	eventType := tc.EventTypeUpdatesCleared
+	if queue {
+		eventType = tc.EventTypeUpdatesQueued
+	}
+	if err := api.CreateEvent(inf.Tx.Tx, eventType, inf.User, resp); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("creating event: "+err.Error()))
+		return
+	}
+
+	api.WriteResp(w, r, resp)
 	api.CreateChangeLogRawTx(api.ApiChange, "CACHEGROUP: "+string(cgName)+", ID: "+strconv.FormatInt(cgID, 10)+", ACTION: "+strings.Title(reqObj.Action)+"d CacheGroup server updates to the "+string(*reqObj.CDN)+" CDN", inf.User, inf.Tx.Tx)
 }
 
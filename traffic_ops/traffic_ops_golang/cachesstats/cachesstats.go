@@ -41,18 +41,18 @@ func Get(w http.ResponseWriter, r *http.Request) {
 	}
 	defer inf.Close()
 
-	api.RespWriter(w, r, inf.Tx.Tx)(getCachesStats(inf.Tx.Tx))
+	api.RespWriter(w, r, inf.Tx.Tx)(getCachesStats(inf.Tx.Tx, inf.CorrelationID))
 }
 
 const MonitorOnlineStatus = "ONLINE"
 
-func getCachesStats(tx *sql.Tx) ([]CacheData, error) {
+func getCachesStats(tx *sql.Tx, correlationID string) ([]CacheData, error) {
 	monitors, err := getCDNMonitorFQDNs(tx)
 	if err != nil {
 		return nil, errors.New("getting monitors: " + err.Error())
 	}
 
-	client, err := monitorhlp.GetClient(tx)
+	client, err := monitorhlp.GetClient(tx, correlationID)
 	if err != nil {
 		return nil, errors.New("getting monitor client: " + err.Error())
 	}
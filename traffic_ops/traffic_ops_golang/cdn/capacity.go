@@ -34,6 +34,7 @@ import (
 	"github.com/apache/trafficcontrol/lib/go-tc"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/trace"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/util/monitorhlp"
 )
 
@@ -45,20 +46,20 @@ func GetCapacity(w http.ResponseWriter, r *http.Request) {
 	}
 	defer inf.Close()
 
-	api.RespWriter(w, r, inf.Tx.Tx)(getCapacity(inf.Tx.Tx))
+	api.RespWriter(w, r, inf.Tx.Tx)(getCapacity(inf.Tx.Tx, inf.CorrelationID))
 }
 
 const MonitorProxyParameter = "tm.traffic_mon_fwd_proxy"
 const MonitorRequestTimeout = time.Second * 10
 const MonitorOnlineStatus = "ONLINE"
 
-func getCapacity(tx *sql.Tx) (CapacityResp, error) {
+func getCapacity(tx *sql.Tx, correlationID string) (CapacityResp, error) {
 	monitors, err := getCDNMonitorFQDNs(tx)
 	if err != nil {
 		return CapacityResp{}, errors.New("getting monitors: " + err.Error())
 	}
 
-	return getMonitorsCapacity(tx, monitors)
+	return getMonitorsCapacity(tx, monitors, correlationID)
 }
 
 type CapacityResp struct {
@@ -76,7 +77,7 @@ type CapData struct {
 	Capacity    float64
 }
 
-func getMonitorsCapacity(tx *sql.Tx, monitors map[tc.CDNName][]string) (CapacityResp, error) {
+func getMonitorsCapacity(tx *sql.Tx, monitors map[tc.CDNName][]string, correlationID string) (CapacityResp, error) {
 	monitorForwardProxy, monitorForwardProxyExists, err := dbhelpers.GetGlobalParam(tx, MonitorProxyParameter)
 	if err != nil {
 		return CapacityResp{}, errors.New("getting global monitor proxy parameter: " + err.Error())
@@ -96,6 +97,7 @@ func getMonitorsCapacity(tx *sql.Tx, monitors map[tc.CDNName][]string) (Capacity
 		}
 		client = &http.Client{Timeout: MonitorRequestTimeout, Transport: clientTransport}
 	}
+	client = api.CorrelatedClient(trace.Client(trace.TxContext(tx), client), correlationID)
 
 	thresholds, err := getEdgeProfileHealthThresholdBandwidth(tx)
 	if err != nil {
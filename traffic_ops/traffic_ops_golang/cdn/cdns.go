@@ -80,6 +80,11 @@ func (cdn TOCDN) GetType() string {
 	return "cdn"
 }
 
+// LastModifiedTables implements api.HasLastModifiedTables.
+func (cdn TOCDN) LastModifiedTables() []string {
+	return []string{"cdn"}
+}
+
 func (cdn *TOCDN) SetKeys(keys map[string]interface{}) {
 	i, _ := keys["id"].(int) //this utilizes the non panicking type assertion, if the thrown away ok variable is false i will be the zero of the type, 0 here.
 	cdn.ID = &i
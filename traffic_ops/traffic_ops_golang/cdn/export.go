@@ -0,0 +1,602 @@
+package cdn
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/apache/trafficcontrol/lib/go-rfc"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/deliveryservice"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"gopkg.in/yaml.v2"
+)
+
+// These are the formats in which a CDN may be exported and imported.
+const (
+	ExportFormatJSON = "json"
+	ExportFormatYAML = "yaml"
+)
+
+// ApplicationYAML is the media type of CDN exports in the YAML format. The "application/x-yaml" and
+// "text/yaml" types are also accepted on import.
+const ApplicationYAML = "application/yaml"
+
+// The Cache Groups of a CDN are those containing its servers, and - recursively - their parents,
+// secondary parents, and fallbacks.
+const cdnCacheGroupNamesQuery = `
+WITH RECURSIVE cgs(id) AS (
+	SELECT server.cachegroup
+	FROM server
+	WHERE server.cdn_id = $1
+	UNION
+	SELECT related.id
+	FROM cgs
+	JOIN cachegroup ON cachegroup.id = cgs.id
+	JOIN LATERAL (
+		SELECT cachegroup.parent_cachegroup_id AS id
+		UNION
+		SELECT cachegroup.secondary_parent_cachegroup_id
+		UNION
+		SELECT cachegroup_fallbacks.backup_cg
+		FROM cachegroup_fallbacks
+		WHERE cachegroup_fallbacks.primary_cg = cachegroup.id
+	) AS related ON related.id IS NOT NULL
+)
+SELECT cachegroup.name
+FROM cachegroup
+WHERE cachegroup.id IN (SELECT id FROM cgs)
+`
+
+const readCacheGroupsQuery = `
+SELECT cachegroup.id,
+       cachegroup.name,
+       cachegroup.short_name,
+       type.name,
+       coordinate.latitude,
+       coordinate.longitude,
+       parent.name,
+       secondary_parent.name,
+       cachegroup.fallback_to_closest,
+       (SELECT COALESCE(array_agg(CAST(method AS text) ORDER BY method), '{}') FROM cachegroup_localization_method WHERE cachegroup = cachegroup.id),
+       (SELECT COALESCE(array_agg(backup.name ORDER BY cachegroup_fallbacks.set_order), '{}') FROM cachegroup_fallbacks JOIN cachegroup AS backup ON backup.id = cachegroup_fallbacks.backup_cg WHERE cachegroup_fallbacks.primary_cg = cachegroup.id)
+FROM cachegroup
+JOIN type ON cachegroup.type = type.id
+LEFT JOIN coordinate ON cachegroup.coordinate = coordinate.id
+LEFT JOIN cachegroup AS parent ON cachegroup.parent_cachegroup_id = parent.id
+LEFT JOIN cachegroup AS secondary_parent ON cachegroup.secondary_parent_cachegroup_id = secondary_parent.id
+WHERE cachegroup.name = ANY($1)
+`
+
+const readProfilesQuery = `
+SELECT profile.id,
+       profile.name,
+       COALESCE(profile.description, ''),
+       profile.type,
+       profile.routing_disabled,
+       cdn.name
+FROM profile
+JOIN cdn ON profile.cdn = cdn.id
+WHERE profile.name = ANY($1)
+`
+
+// Secure Parameters are never exported.
+const readProfileParametersQuery = `
+SELECT profile_parameter.profile,
+       parameter.name,
+       parameter.config_file,
+       parameter.value
+FROM profile_parameter
+JOIN parameter ON profile_parameter.parameter = parameter.id
+WHERE profile_parameter.profile = ANY($1)
+AND NOT parameter.secure
+`
+
+const readDSServersQuery = `
+SELECT deliveryservice_server.deliveryservice,
+       server.host_name
+FROM deliveryservice_server
+JOIN server ON deliveryservice_server.server = server.id
+WHERE deliveryservice_server.deliveryservice = ANY($1)
+`
+
+const readSteeringTargetsQuery = `
+SELECT ds.xml_id,
+       target.xml_id,
+       type.name,
+       steering_target.value
+FROM steering_target
+JOIN deliveryservice AS ds ON steering_target.deliveryservice = ds.id
+JOIN deliveryservice AS target ON steering_target.target = target.id
+JOIN type ON steering_target.type = type.id
+WHERE ds.xml_id = ANY($1)
+`
+
+const readFederationsQuery = `
+SELECT federation.id,
+       federation.cname,
+       federation.ttl,
+       federation.description,
+       deliveryservice.xml_id
+FROM federation
+JOIN federation_deliveryservice ON federation_deliveryservice.federation = federation.id
+JOIN deliveryservice ON federation_deliveryservice.deliveryservice = deliveryservice.id
+WHERE deliveryservice.xml_id = ANY($1)
+`
+
+const readFederationResolversQuery = `
+SELECT federation_federation_resolver.federation,
+       federation_resolver.ip_address,
+       type.name
+FROM federation_federation_resolver
+JOIN federation_resolver ON federation_federation_resolver.federation_resolver = federation_resolver.id
+JOIN type ON federation_resolver.type = type.id
+WHERE federation_federation_resolver.federation = ANY($1)
+`
+
+// Export is the handler for GET requests to /cdns/{id}/export, which responds with the
+// configuration of the CDN as a tc.CDNExport document, in JSON or - if the "format" query
+// parameter is "yaml" - YAML.
+//
+// Only the Delivery Services which are visible to the user's Tenant are exported.
+func Export(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"id"}, []string{"id"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	format := ExportFormatJSON
+	if f, ok := inf.Params["format"]; ok {
+		format = f
+	}
+	if format != ExportFormatJSON && format != ExportFormatYAML {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, errors.New("format must be '"+ExportFormatJSON+"' or '"+ExportFormatYAML+"'"), nil)
+		return
+	}
+
+	cdnName, ok, err := dbhelpers.GetCDNNameFromID(inf.Tx.Tx, int64(inf.IntParams["id"]))
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting CDN name: "+err.Error()))
+		return
+	} else if !ok {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("cdn not found"), nil)
+		return
+	}
+
+	doc, err := export(inf.Tx, inf.User, string(cdnName))
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("exporting CDN: "+err.Error()))
+		return
+	}
+
+	w.Header().Set(rfc.ContentDisposition, fmt.Sprintf("attachment; filename=\"%s.%s\"", cdnName, format))
+	if format == ExportFormatJSON {
+		api.WriteRespRaw(w, r, doc)
+		return
+	}
+	bts, err := marshalYAML(doc)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("marshalling CDN export: "+err.Error()))
+		return
+	}
+	w.Header().Set(rfc.ContentType, ApplicationYAML)
+	w.Write(bts)
+}
+
+// export returns the configuration of the CDN with the given name, which must exist.
+func export(tx *sqlx.Tx, user *auth.CurrentUser, cdnName string) (tc.CDNExport, error) {
+	doc := tc.CDNExport{Version: tc.CDNExportVersion}
+	cdnID := 0
+	if err := tx.QueryRow(`SELECT id, name, domain_name, dnssec_enabled FROM cdn WHERE name = $1`, cdnName).Scan(&cdnID, &doc.CDN.Name, &doc.CDN.DomainName, &doc.CDN.DNSSECEnabled); err != nil {
+		return doc, errors.New("getting CDN: " + err.Error())
+	}
+
+	cgNames, err := queryNames(tx.Tx, cdnCacheGroupNamesQuery, cdnID)
+	if err != nil {
+		return doc, errors.New("getting cache group names: " + err.Error())
+	}
+	cgs, err := readCacheGroups(tx.Tx, cgNames)
+	if err != nil {
+		return doc, errors.New("reading cache groups: " + err.Error())
+	}
+	doc.CacheGroups = []tc.CDNExportCacheGroup{}
+	for _, name := range cgNames {
+		doc.CacheGroups = append(doc.CacheGroups, cgs[name].CDNExportCacheGroup)
+	}
+
+	profileNames, err := queryNames(tx.Tx, `SELECT name FROM profile WHERE cdn = $1`, cdnID)
+	if err != nil {
+		return doc, errors.New("getting profile names: " + err.Error())
+	}
+	profiles, err := readProfiles(tx.Tx, profileNames)
+	if err != nil {
+		return doc, errors.New("reading profiles: " + err.Error())
+	}
+	doc.Profiles = []tc.CDNExportProfile{}
+	for _, name := range profileNames {
+		doc.Profiles = append(doc.Profiles, profiles[name].CDNExportProfile)
+	}
+
+	tenantIDs, err := tenant.GetUserTenantIDListTx(tx.Tx, user.TenantID)
+	if err != nil {
+		return doc, errors.New("getting user tenants: " + err.Error())
+	}
+	where, queryValues := dbhelpers.AddTenancyCheck("\nWHERE cdn.name = :cdn", map[string]interface{}{"cdn": cdnName}, "ds.tenant_id", tenantIDs)
+	dses, err := readDeliveryServices(tx, where, queryValues)
+	if err != nil {
+		return doc, errors.New("reading delivery services: " + err.Error())
+	}
+	doc.DeliveryServices = []tc.CDNExportDeliveryService{}
+	xmlIDs := []string{}
+	for _, ds := range dses {
+		doc.DeliveryServices = append(doc.DeliveryServices, ds.CDNExportDeliveryService)
+		xmlIDs = append(xmlIDs, *ds.XMLID)
+	}
+
+	targets, err := readSteeringTargets(tx.Tx, xmlIDs)
+	if err != nil {
+		return doc, errors.New("reading steering targets: " + err.Error())
+	}
+	doc.SteeringTargets = []tc.CDNExportSteeringTarget{}
+	for _, st := range targets {
+		doc.SteeringTargets = append(doc.SteeringTargets, st)
+	}
+	sort.Slice(doc.SteeringTargets, func(i, j int) bool { return doc.SteeringTargets[i].Name() < doc.SteeringTargets[j].Name() })
+
+	feds, err := readFederations(tx.Tx, xmlIDs)
+	if err != nil {
+		return doc, errors.New("reading federations: " + err.Error())
+	}
+	doc.Federations = []tc.CDNExportFederation{}
+	for _, fed := range feds {
+		doc.Federations = append(doc.Federations, fed.CDNExportFederation)
+	}
+	sort.Slice(doc.Federations, func(i, j int) bool { return doc.Federations[i].Name() < doc.Federations[j].Name() })
+	return doc, nil
+}
+
+// queryNames returns the names selected by the given query, in order.
+func queryNames(tx *sql.Tx, query string, args ...interface{}) ([]string, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	names := []string{}
+	for rows.Next() {
+		name := ""
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+type cacheGroup struct {
+	tc.CDNExportCacheGroup
+	id int
+}
+
+// readCacheGroups returns the Cache Groups with the given names which exist, by name.
+func readCacheGroups(tx *sql.Tx, names []string) (map[string]cacheGroup, error) {
+	rows, err := tx.Query(readCacheGroupsQuery, pq.Array(names))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cgs := map[string]cacheGroup{}
+	for rows.Next() {
+		cg := cacheGroup{}
+		methods := []string{}
+		if err := rows.Scan(&cg.id, &cg.Name, &cg.ShortName, &cg.Type, &cg.Latitude, &cg.Longitude, &cg.ParentName, &cg.SecondaryParentName, &cg.FallbackToClosest, pq.Array(&methods), pq.Array(&cg.Fallbacks)); err != nil {
+			return nil, err
+		}
+		cg.LocalizationMethods = []tc.LocalizationMethod{}
+		for _, method := range methods {
+			cg.LocalizationMethods = append(cg.LocalizationMethods, tc.LocalizationMethod(method))
+		}
+		cgs[cg.Name] = cg
+	}
+	return cgs, rows.Err()
+}
+
+type profile struct {
+	tc.CDNExportProfile
+	id  int
+	cdn string
+}
+
+// readProfiles returns the Profiles with the given names which exist, by name.
+func readProfiles(tx *sql.Tx, names []string) (map[string]profile, error) {
+	rows, err := tx.Query(readProfilesQuery, pq.Array(names))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	profiles := map[string]profile{}
+	ids := []int{}
+	for rows.Next() {
+		p := profile{}
+		if err := rows.Scan(&p.id, &p.Name, &p.Description, &p.Type, &p.RoutingDisabled, &p.cdn); err != nil {
+			return nil, err
+		}
+		p.Parameters = []tc.ProfileExportImportParameterNullable{}
+		profiles[p.Name] = p
+		ids = append(ids, p.id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	params := map[int][]tc.ProfileExportImportParameterNullable{}
+	rows, err = tx.Query(readProfileParametersQuery, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		id := 0
+		param := tc.ProfileExportImportParameterNullable{}
+		if err := rows.Scan(&id, &param.Name, &param.ConfigFile, &param.Value); err != nil {
+			return nil, err
+		}
+		params[id] = append(params[id], param)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for name, p := range profiles {
+		p.Parameters = append(p.Parameters, params[p.id]...)
+		sortParameters(p.Parameters)
+		profiles[name] = p
+	}
+	return profiles, nil
+}
+
+type deliveryService struct {
+	tc.CDNExportDeliveryService
+	id  int
+	cdn string
+}
+
+// readDeliveryServices returns the Delivery Services matching the given WHERE clause of the
+// deliveryservice package's select query, ordered by XMLID, with their servers.
+func readDeliveryServices(tx *sqlx.Tx, where string, queryValues map[string]interface{}) ([]deliveryService, error) {
+	dses, userErr, sysErr, _ := deliveryservice.GetDeliveryServices(deliveryservice.GetDSSelectQuery()+where+"\nORDER BY ds.xml_id", queryValues, tx)
+	if userErr != nil || sysErr != nil {
+		return nil, util.JoinErrs([]error{userErr, sysErr})
+	}
+
+	exported := []deliveryService{}
+	ids := []int{}
+	for _, ds := range dses {
+		exported = append(exported, deliveryService{CDNExportDeliveryService: tc.CDNExportDeliveryService{DeliveryServiceNullable: ds}, id: *ds.ID, cdn: *ds.CDNName})
+		ids = append(ids, *ds.ID)
+	}
+
+	rows, err := tx.Query(readDSServersQuery, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	servers := map[int][]string{}
+	for rows.Next() {
+		id := 0
+		hostName := ""
+		if err := rows.Scan(&id, &hostName); err != nil {
+			return nil, err
+		}
+		servers[id] = append(servers[id], hostName)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i, ds := range exported {
+		ds.Servers = servers[ds.id]
+		normalizeDeliveryService(&ds.CDNExportDeliveryService)
+		exported[i] = ds
+	}
+	return exported, nil
+}
+
+// readSteeringTargets returns the targets of the Delivery Services with the given XMLIDs, by name.
+func readSteeringTargets(tx *sql.Tx, xmlIDs []string) (map[string]tc.CDNExportSteeringTarget, error) {
+	rows, err := tx.Query(readSteeringTargetsQuery, pq.Array(xmlIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	targets := map[string]tc.CDNExportSteeringTarget{}
+	for rows.Next() {
+		st := tc.CDNExportSteeringTarget{}
+		if err := rows.Scan(&st.DeliveryService, &st.Target, &st.Type, &st.Value); err != nil {
+			return nil, err
+		}
+		targets[st.Name()] = st
+	}
+	return targets, rows.Err()
+}
+
+type federation struct {
+	tc.CDNExportFederation
+	id int
+}
+
+// readFederations returns the Federations of the Delivery Services with the given XMLIDs, with
+// their resolvers, by name.
+func readFederations(tx *sql.Tx, xmlIDs []string) (map[string]federation, error) {
+	rows, err := tx.Query(readFederationsQuery, pq.Array(xmlIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	feds := map[string]federation{}
+	ids := []int{}
+	for rows.Next() {
+		fed := federation{}
+		if err := rows.Scan(&fed.id, &fed.CName, &fed.TTL, &fed.Description, &fed.DeliveryService); err != nil {
+			return nil, err
+		}
+		fed.Resolvers = []tc.CDNExportFederationResolver{}
+		feds[fed.Name()] = fed
+		ids = append(ids, fed.id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	resolvers := map[int][]tc.CDNExportFederationResolver{}
+	rows, err = tx.Query(readFederationResolversQuery, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		id := 0
+		resolver := tc.CDNExportFederationResolver{}
+		if err := rows.Scan(&id, &resolver.IPAddress, &resolver.Type); err != nil {
+			return nil, err
+		}
+		resolvers[id] = append(resolvers[id], resolver)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for name, fed := range feds {
+		fed.Resolvers = append(fed.Resolvers, resolvers[fed.id]...)
+		sortResolvers(fed.Resolvers)
+		feds[name] = fed
+	}
+	return feds, nil
+}
+
+// normalizeDeliveryService clears the fields of ds which are not exported, and sorts its lists, so
+// that exported Delivery Services may be compared.
+func normalizeDeliveryService(ds *tc.CDNExportDeliveryService) {
+	ds.ID = nil
+	ds.CDNID = nil
+	ds.ProfileID = nil
+	ds.ProfileDesc = nil
+	ds.TenantID = nil
+	ds.TypeID = nil
+	ds.LastUpdated = nil
+	ds.SSLKeyVersion = nil
+	ds.ExampleURLs = nil
+	ds.Signed = ds.SigningAlgorithm != nil && *ds.SigningAlgorithm == tc.SigningAlgorithmURLSig
+
+	if ds.MatchList == nil {
+		ds.MatchList = &[]tc.DeliveryServiceMatch{}
+	}
+	matchList := *ds.MatchList
+	sort.Slice(matchList, func(i, j int) bool {
+		if matchList[i].SetNumber != matchList[j].SetNumber {
+			return matchList[i].SetNumber < matchList[j].SetNumber
+		}
+		if matchList[i].Type != matchList[j].Type {
+			return matchList[i].Type < matchList[j].Type
+		}
+		return matchList[i].Pattern < matchList[j].Pattern
+	})
+	if ds.ConsistentHashQueryParams == nil {
+		ds.ConsistentHashQueryParams = []string{}
+	}
+	sort.Strings(ds.ConsistentHashQueryParams)
+	if ds.Servers == nil {
+		ds.Servers = []string{}
+	}
+	sort.Strings(ds.Servers)
+}
+
+// normalizeCacheGroup replaces the nil lists of cg with empty ones, so that exported Cache Groups
+// may be compared.
+func normalizeCacheGroup(cg *tc.CDNExportCacheGroup) {
+	if cg.LocalizationMethods == nil {
+		cg.LocalizationMethods = []tc.LocalizationMethod{}
+	}
+	sort.Slice(cg.LocalizationMethods, func(i, j int) bool { return cg.LocalizationMethods[i] < cg.LocalizationMethods[j] })
+	if cg.Fallbacks == nil {
+		cg.Fallbacks = []string{}
+	}
+}
+
+func sortParameters(params []tc.ProfileExportImportParameterNullable) {
+	str := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+	sort.Slice(params, func(i, j int) bool {
+		if str(params[i].ConfigFile) != str(params[j].ConfigFile) {
+			return str(params[i].ConfigFile) < str(params[j].ConfigFile)
+		}
+		if str(params[i].Name) != str(params[j].Name) {
+			return str(params[i].Name) < str(params[j].Name)
+		}
+		return str(params[i].Value) < str(params[j].Value)
+	})
+}
+
+func sortResolvers(resolvers []tc.CDNExportFederationResolver) {
+	sort.Slice(resolvers, func(i, j int) bool {
+		if resolvers[i].IPAddress != resolvers[j].IPAddress {
+			return resolvers[i].IPAddress < resolvers[j].IPAddress
+		}
+		return resolvers[i].Type < resolvers[j].Type
+	})
+}
+
+// marshalYAML marshals the CDN export as YAML, with the same field names as its JSON.
+func marshalYAML(doc tc.CDNExport) ([]byte, error) {
+	bts, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	obj := yaml.MapSlice{}
+	if err := yaml.Unmarshal(bts, &obj); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(obj)
+}
@@ -38,7 +38,7 @@ func GetHealth(w http.ResponseWriter, r *http.Request) {
 	}
 	defer inf.Close()
 
-	health, err := getHealth(inf.Tx.Tx)
+	health, err := getHealth(inf.Tx.Tx, inf.CorrelationID)
 	if err != nil {
 		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting cdn health: "+err.Error()))
 		return
@@ -55,7 +55,7 @@ func GetNameHealth(w http.ResponseWriter, r *http.Request) {
 	}
 	defer inf.Close()
 
-	health, err := getNameHealth(inf.Tx.Tx, tc.CDNName(inf.Params["name"]))
+	health, err := getNameHealth(inf.Tx.Tx, tc.CDNName(inf.Params["name"]), inf.CorrelationID)
 	if err != nil {
 		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting cdn name health: "+err.Error()))
 		return
@@ -64,15 +64,15 @@ func GetNameHealth(w http.ResponseWriter, r *http.Request) {
 	api.WriteResp(w, r, health)
 }
 
-func getHealth(tx *sql.Tx) (tc.HealthData, error) {
+func getHealth(tx *sql.Tx, correlationID string) (tc.HealthData, error) {
 	monitors, err := monitorhlp.GetURLs(tx)
 	if err != nil {
 		return tc.HealthData{}, errors.New("getting monitors: " + err.Error())
 	}
-	return getMonitorsHealth(tx, monitors)
+	return getMonitorsHealth(tx, monitors, correlationID)
 }
 
-func getNameHealth(tx *sql.Tx, name tc.CDNName) (tc.HealthData, error) {
+func getNameHealth(tx *sql.Tx, name tc.CDNName, correlationID string) (tc.HealthData, error) {
 	monitors, err := monitorhlp.GetURLs(tx)
 	if err != nil {
 		return tc.HealthData{}, errors.New("getting monitors: " + err.Error())
@@ -82,11 +82,11 @@ func getNameHealth(tx *sql.Tx, name tc.CDNName) (tc.HealthData, error) {
 	if ok {
 		monitors = map[tc.CDNName]string{name: monitor}
 	}
-	return getMonitorsHealth(tx, monitors)
+	return getMonitorsHealth(tx, monitors, correlationID)
 }
 
-func getMonitorsHealth(tx *sql.Tx, monitors map[tc.CDNName]string) (tc.HealthData, error) {
-	client, err := monitorhlp.GetClient(tx)
+func getMonitorsHealth(tx *sql.Tx, monitors map[tc.CDNName]string, correlationID string) (tc.HealthData, error) {
+	client, err := monitorhlp.GetClient(tx, correlationID)
 	if err != nil {
 		return tc.HealthData{}, errors.New("getting monitor client: " + err.Error())
 	}
@@ -0,0 +1,774 @@
+package cdn
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/apache/trafficcontrol/lib/go-rfc"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/cachegroup"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/cdnfederation"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/deliveryservice"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
+
+	"github.com/lib/pq"
+	"gopkg.in/yaml.v2"
+)
+
+// Parameters are matched by name, config file and value; secure Parameters are never imported, and
+// their assignments to imported Profiles are kept.
+const getParameterIDQuery = `
+SELECT id
+FROM parameter
+WHERE name = $1
+AND config_file IS NOT DISTINCT FROM $2
+AND value = $3
+AND NOT secure
+LIMIT 1
+`
+
+const replaceProfileParametersQuery = `
+DELETE FROM profile_parameter
+WHERE profile = $1
+AND NOT (parameter = ANY($2))
+AND parameter IN (SELECT id FROM parameter WHERE NOT secure)
+`
+
+const insertProfileParametersQuery = `
+INSERT INTO profile_parameter (profile, parameter)
+SELECT $1, unnest($2::bigint[])
+ON CONFLICT DO NOTHING
+`
+
+// Deleting the regexes cascades to their deliveryservice_regex rows.
+const deleteDSRegexesQuery = `
+DELETE FROM regex
+WHERE id IN (SELECT regex FROM deliveryservice_regex WHERE deliveryservice = $1)
+`
+
+// Import is the handler for POST requests to /cdns/import, which creates or updates the CDN
+// described by the tc.CDNExport document in the request body - in JSON or, if the Content-Type is a
+// YAML media type, YAML - and everything in it, resolving references between objects by name.
+//
+// If the "dryRun" query parameter is true, nothing is changed, but the response reports what would
+// have been. Nothing is changed if any object can't be imported, either.
+func Import(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, nil, nil)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	dryRun := false
+	if v, ok := inf.Params["dryRun"]; ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, errors.New("dryRun must be a boolean"), nil)
+			return
+		}
+		dryRun = b
+	}
+
+	doc, err := decodeExport(r.Header.Get(rfc.ContentType), r.Body)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, errors.New("malformed CDN export: "+err.Error()), nil)
+		return
+	}
+	if err := doc.Validate(); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, err, nil)
+		return
+	}
+	if doc.CacheGroups, err = sortCacheGroups(doc.CacheGroups); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, err, nil)
+		return
+	}
+
+	imp := &importer{inf: inf, tx: inf.Tx.Tx, dryRun: dryRun, cdnName: doc.CDN.Name, results: []tc.CDNImportResult{}}
+	if err := imp.run(doc); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("importing CDN: "+err.Error()))
+		return
+	}
+	report := tc.CDNImportReport{DryRun: dryRun, Results: imp.results}
+
+	if imp.failed {
+		inf.Tx.Tx.Rollback()
+		api.WriteAlertsObj(w, r, http.StatusBadRequest, tc.CreateAlerts(tc.ErrorLevel, "CDN import failed for one or more objects; no changes were made."), report)
+		return
+	}
+	if dryRun {
+		inf.Tx.Tx.Rollback()
+		api.WriteAlertsObj(w, r, http.StatusOK, tc.CreateAlerts(tc.InfoLevel, "CDN import dry run; no changes were made."), report)
+		return
+	}
+	api.CreateChangeLogRawTx(api.ApiChange, "CDN: "+doc.CDN.Name+", ACTION: Imported CDN configuration", inf.User, inf.Tx.Tx)
+	for _, write := range imp.vaultWrites {
+		if errCode, userErr, sysErr := write(); userErr != nil || sysErr != nil {
+			api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+			return
+		}
+	}
+	api.WriteAlertsObj(w, r, http.StatusOK, tc.CreateAlerts(tc.SuccessLevel, "CDN import was successful."), report)
+}
+
+// decodeExport decodes a CDN export from body, which is YAML if contentType is a YAML media type,
+// and otherwise JSON.
+func decodeExport(contentType string, body io.Reader) (tc.CDNExport, error) {
+	doc := tc.CDNExport{}
+	bts, err := ioutil.ReadAll(body)
+	if err != nil {
+		return doc, errors.New("reading body: " + err.Error())
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case ApplicationYAML, "application/x-yaml", "text/yaml":
+		obj := interface{}(nil)
+		if err := yaml.Unmarshal(bts, &obj); err != nil {
+			return doc, err
+		}
+		if bts, err = json.Marshal(jsonCompatible(obj)); err != nil {
+			return doc, err
+		}
+	}
+	err = json.Unmarshal(bts, &doc)
+	return doc, err
+}
+
+// jsonCompatible returns obj, as unmarshalled from YAML, with its maps' keys converted to strings,
+// so that it can be marshalled as JSON.
+func jsonCompatible(obj interface{}) interface{} {
+	switch v := obj.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			m[fmt.Sprint(key)] = jsonCompatible(val)
+		}
+		return m
+	case []interface{}:
+		for i, val := range v {
+			v[i] = jsonCompatible(val)
+		}
+	}
+	return obj
+}
+
+// sortCacheGroups returns the given Cache Groups ordered such that each comes after any of its
+// parents and fallbacks which are also given, or an error if any is its own ancestor or fallback.
+func sortCacheGroups(cgs []tc.CDNExportCacheGroup) ([]tc.CDNExportCacheGroup, error) {
+	byName := map[string]tc.CDNExportCacheGroup{}
+	for _, cg := range cgs {
+		byName[cg.Name] = cg
+	}
+
+	const visiting, visited = 1, 2
+	states := map[string]int{}
+	sorted := make([]tc.CDNExportCacheGroup, 0, len(cgs))
+	visit := (func(string) error)(nil)
+	visit = func(name string) error {
+		cg, ok := byName[name]
+		if !ok || states[name] == visited {
+			return nil
+		}
+		if states[name] == visiting {
+			return errors.New("cache group " + name + " is its own parent or fallback")
+		}
+		states[name] = visiting
+		deps := append([]string{}, cg.Fallbacks...)
+		if cg.ParentName != nil {
+			deps = append(deps, *cg.ParentName)
+		}
+		if cg.SecondaryParentName != nil {
+			deps = append(deps, *cg.SecondaryParentName)
+		}
+		for _, dep := range deps {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		states[name] = visited
+		sorted = append(sorted, cg)
+		return nil
+	}
+	for _, cg := range cgs {
+		if err := visit(cg.Name); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
+
+// importer imports the objects of a CDN export one at a time, each in its own savepoint, so that
+// an object which can't be imported is reported without preventing the rest from being checked.
+// Writes to Traffic Vault, which can't be rolled back, are kept in vaultWrites, to be made only once
+// every object has been imported.
+type importer struct {
+	inf         *api.APIInfo
+	tx          *sql.Tx
+	dryRun      bool
+	cdnID       int
+	cdnName     string
+	results     []tc.CDNImportResult
+	failed      bool
+	vaultWrites []deliveryservice.TrafficVaultWrite
+}
+
+// importFunc imports a single object, returning the action taken - one of the tc.CDNImportAction
+// constants - and an optional message about it.
+type importFunc func() (action string, msg string, userErr error, sysErr error)
+
+func (imp *importer) run(doc tc.CDNExport) error {
+	if err := imp.apply("cdn", doc.CDN.Name, func() (string, string, error, error) { return imp.importCDN(doc.CDN) }); err != nil {
+		return err
+	}
+	if imp.failed {
+		return nil // everything else belongs to the CDN
+	}
+	for _, cg := range doc.CacheGroups {
+		cg := cg
+		if err := imp.apply("cacheGroup", cg.Name, func() (string, string, error, error) { return imp.importCacheGroup(cg) }); err != nil {
+			return err
+		}
+	}
+	for _, p := range doc.Profiles {
+		p := p
+		if err := imp.apply("profile", p.Name, func() (string, string, error, error) { return imp.importProfile(p) }); err != nil {
+			return err
+		}
+	}
+	for _, ds := range doc.DeliveryServices {
+		ds := ds
+		if err := imp.apply("deliveryService", *ds.XMLID, func() (string, string, error, error) { return imp.importDeliveryService(ds) }); err != nil {
+			return err
+		}
+	}
+	for _, st := range doc.SteeringTargets {
+		st := st
+		if err := imp.apply("steeringTarget", st.Name(), func() (string, string, error, error) { return imp.importSteeringTarget(st) }); err != nil {
+			return err
+		}
+	}
+	for _, fed := range doc.Federations {
+		fed := fed
+		if err := imp.apply("federation", fed.Name(), func() (string, string, error, error) { return imp.importFederation(fed) }); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// apply imports an object with f, and records the result. A user error undoes whatever f did and
+// is reported; a system error is returned, and aborts the import.
+func (imp *importer) apply(objType string, name string, f importFunc) error {
+	if _, err := imp.tx.Exec(`SAVEPOINT cdn_import`); err != nil {
+		return errors.New("creating savepoint: " + err.Error())
+	}
+	action, msg, userErr, sysErr := f()
+	if sysErr != nil {
+		return fmt.Errorf("%s %s: %v", objType, name, sysErr)
+	}
+	if userErr != nil {
+		if _, err := imp.tx.Exec(`ROLLBACK TO SAVEPOINT cdn_import`); err != nil {
+			return errors.New("rolling back to savepoint: " + err.Error())
+		}
+		imp.failed = true
+		imp.results = append(imp.results, tc.CDNImportResult{Type: objType, Name: name, Action: tc.CDNImportActionError, Message: userErr.Error()})
+		return nil
+	}
+	if _, err := imp.tx.Exec(`RELEASE SAVEPOINT cdn_import`); err != nil {
+		return errors.New("releasing savepoint: " + err.Error())
+	}
+	if action != tc.CDNImportActionSkip {
+		api.CreateChangeLogRawTx(api.ApiChange, "CDN: "+imp.cdnName+", ACTION: Import "+action+"d "+objType+" "+name, imp.inf.User, imp.tx)
+	}
+	imp.results = append(imp.results, tc.CDNImportResult{Type: objType, Name: name, Action: action, Message: msg})
+	return nil
+}
+
+// writeErr converts an error from writing to the database into a user or system error.
+func writeErr(err error) (string, string, error, error) {
+	userErr, sysErr, _ := api.ParseDBError(err)
+	return "", "", userErr, sysErr
+}
+
+// getTypeID returns the ID of the Type with the given name used in the given table.
+func getTypeID(tx *sql.Tx, name string, useInTable string) (int, bool, error) {
+	id := 0
+	if err := tx.QueryRow(`SELECT id FROM type WHERE name = $1 AND use_in_table = $2`, name, useInTable).Scan(&id); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	return id, true, nil
+}
+
+// getDSID returns the ID of the Delivery Service with the given XMLID, or a user error if it
+// doesn't exist or the user isn't authorized on its Tenant.
+func (imp *importer) getDSID(xmlID string) (int, error, error) {
+	id := 0
+	if err := imp.tx.QueryRow(`SELECT id FROM deliveryservice WHERE xml_id = $1`, xmlID).Scan(&id); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, errors.New("no delivery service named " + xmlID), nil
+		}
+		return 0, nil, errors.New("getting delivery service id: " + err.Error())
+	}
+	userErr, sysErr, _ := tenant.CheckID(imp.tx, imp.inf.User, id)
+	return id, userErr, sysErr
+}
+
+func (imp *importer) importCDN(c tc.CDNExportCDN) (string, string, error, error) {
+	obj := &TOCDN{APIInfoImpl: api.APIInfoImpl{ReqInfo: imp.inf}, CDNNullable: tc.CDNNullable{Name: &c.Name, DomainName: &c.DomainName, DNSSECEnabled: &c.DNSSECEnabled}}
+	if err := obj.Validate(); err != nil {
+		return "", "", err, nil
+	}
+
+	existing := tc.CDNExportCDN{}
+	err := imp.tx.QueryRow(`SELECT id, name, domain_name, dnssec_enabled FROM cdn WHERE name = $1`, c.Name).Scan(&imp.cdnID, &existing.Name, &existing.DomainName, &existing.DNSSECEnabled)
+	if err == sql.ErrNoRows {
+		if err := imp.tx.QueryRow(`INSERT INTO cdn (name, domain_name, dnssec_enabled) VALUES ($1, $2, $3) RETURNING id`, c.Name, c.DomainName, c.DNSSECEnabled).Scan(&imp.cdnID); err != nil {
+			return writeErr(err)
+		}
+		obj.ID = &imp.cdnID
+		if err := api.CreateObjectEvent(imp.tx, tc.EventActionCreated, obj, imp.inf.User); err != nil {
+			return "", "", nil, err
+		}
+		return tc.CDNImportActionCreate, "", nil, nil
+	} else if err != nil {
+		return "", "", nil, errors.New("getting cdn: " + err.Error())
+	}
+	if existing == c {
+		return tc.CDNImportActionSkip, "", nil, nil
+	}
+
+	if _, err := imp.tx.Exec(`UPDATE cdn SET domain_name = $1, dnssec_enabled = $2 WHERE id = $3`, c.DomainName, c.DNSSECEnabled, imp.cdnID); err != nil {
+		return writeErr(err)
+	}
+	obj.ID = &imp.cdnID
+	if err := api.CreateObjectEvent(imp.tx, tc.EventActionUpdated, obj, imp.inf.User); err != nil {
+		return "", "", nil, err
+	}
+	return tc.CDNImportActionUpdate, "", nil, nil
+}
+
+func (imp *importer) importCacheGroup(cg tc.CDNExportCacheGroup) (string, string, error, error) {
+	normalizeCacheGroup(&cg)
+	existing, err := readCacheGroups(imp.tx, []string{cg.Name})
+	if err != nil {
+		return "", "", nil, errors.New("reading cache group: " + err.Error())
+	}
+	old, exists := existing[cg.Name]
+	if exists && reflect.DeepEqual(old.CDNExportCacheGroup, cg) {
+		return tc.CDNImportActionSkip, "", nil, nil
+	}
+
+	typeID, ok, err := getTypeID(imp.tx, cg.Type, "cachegroup")
+	if err != nil {
+		return "", "", nil, errors.New("getting type id: " + err.Error())
+	} else if !ok {
+		return "", "", errors.New("no cache group type named " + cg.Type), nil
+	}
+	parentIDs := []*int{}
+	for _, parent := range []*string{cg.ParentName, cg.SecondaryParentName} {
+		if parent == nil {
+			parentIDs = append(parentIDs, nil)
+			continue
+		}
+		parents, err := readCacheGroups(imp.tx, []string{*parent})
+		if err != nil {
+			return "", "", nil, errors.New("reading parent cache group: " + err.Error())
+		}
+		p, ok := parents[*parent]
+		if !ok {
+			return "", "", errors.New("no cache group named " + *parent), nil
+		}
+		parentIDs = append(parentIDs, &p.id)
+	}
+
+	obj := &cachegroup.TOCacheGroup{
+		APIInfoImpl: api.APIInfoImpl{ReqInfo: imp.inf},
+		CacheGroupNullable: tc.CacheGroupNullable{
+			Name:                        &cg.Name,
+			ShortName:                   &cg.ShortName,
+			Latitude:                    cg.Latitude,
+			Longitude:                   cg.Longitude,
+			ParentCachegroupID:          parentIDs[0],
+			SecondaryParentCachegroupID: parentIDs[1],
+			FallbackToClosest:           &cg.FallbackToClosest,
+			LocalizationMethods:         &cg.LocalizationMethods,
+			TypeID:                      &typeID,
+			Fallbacks:                   &cg.Fallbacks,
+		},
+	}
+	if err := obj.Validate(); err != nil {
+		return "", "", err, nil
+	}
+
+	action, eventAction := tc.CDNImportActionCreate, tc.EventActionCreated
+	userErr, sysErr := error(nil), error(nil)
+	if exists {
+		action, eventAction = tc.CDNImportActionUpdate, tc.EventActionUpdated
+		obj.ID = &old.id
+		userErr, sysErr, _ = obj.Update()
+	} else {
+		userErr, sysErr, _ = obj.Create()
+	}
+	if userErr != nil || sysErr != nil {
+		return "", "", userErr, sysErr
+	}
+	if err := api.CreateObjectEvent(imp.tx, eventAction, obj, imp.inf.User); err != nil {
+		return "", "", nil, err
+	}
+	return action, "", nil, nil
+}
+
+func (imp *importer) importProfile(p tc.CDNExportProfile) (string, string, error, error) {
+	if p.Parameters == nil {
+		p.Parameters = []tc.ProfileExportImportParameterNullable{}
+	}
+	for _, param := range p.Parameters {
+		if param.Name == nil || *param.Name == "" || param.Value == nil {
+			return "", "", errors.New("parameters must have a name and value"), nil
+		}
+	}
+	sortParameters(p.Parameters)
+
+	existing, err := readProfiles(imp.tx, []string{p.Name})
+	if err != nil {
+		return "", "", nil, errors.New("reading profile: " + err.Error())
+	}
+	old, exists := existing[p.Name]
+	if exists && old.cdn != imp.cdnName {
+		return "", "", errors.New("profile " + p.Name + " belongs to CDN " + old.cdn), nil
+	}
+	if exists && reflect.DeepEqual(old.CDNExportProfile, p) {
+		return tc.CDNImportActionSkip, "", nil, nil
+	}
+
+	validType := false
+	if err := imp.tx.QueryRow(`SELECT $1 = ANY(CAST(enum_range(NULL::profile_type) AS text[]))`, p.Type).Scan(&validType); err != nil {
+		return "", "", nil, errors.New("checking profile type: " + err.Error())
+	} else if !validType {
+		return "", "", errors.New("invalid profile type " + p.Type), nil
+	}
+
+	action, eventAction := tc.CDNImportActionCreate, tc.EventActionCreated
+	id := old.id
+	if exists {
+		action, eventAction = tc.CDNImportActionUpdate, tc.EventActionUpdated
+		if _, err := imp.tx.Exec(`UPDATE profile SET description = $1, type = $2, routing_disabled = $3 WHERE id = $4`, p.Description, p.Type, p.RoutingDisabled, id); err != nil {
+			return writeErr(err)
+		}
+	} else if err := imp.tx.QueryRow(`INSERT INTO profile (name, description, cdn, type, routing_disabled) VALUES ($1, $2, $3, $4, $5) RETURNING id`, p.Name, p.Description, imp.cdnID, p.Type, p.RoutingDisabled).Scan(&id); err != nil {
+		return writeErr(err)
+	}
+
+	paramIDs := []int64{}
+	for _, param := range p.Parameters {
+		paramID := int64(0)
+		if err := imp.tx.QueryRow(getParameterIDQuery, *param.Name, param.ConfigFile, *param.Value).Scan(&paramID); err == sql.ErrNoRows {
+			if err := imp.tx.QueryRow(`INSERT INTO parameter (name, config_file, value) VALUES ($1, $2, $3) RETURNING id`, *param.Name, param.ConfigFile, *param.Value).Scan(&paramID); err != nil {
+				return writeErr(err)
+			}
+		} else if err != nil {
+			return "", "", nil, errors.New("getting parameter id: " + err.Error())
+		}
+		paramIDs = append(paramIDs, paramID)
+	}
+	if _, err := imp.tx.Exec(replaceProfileParametersQuery, id, pq.Array(paramIDs)); err != nil {
+		return "", "", nil, errors.New("removing profile parameters: " + err.Error())
+	}
+	if _, err := imp.tx.Exec(insertProfileParametersQuery, id, pq.Array(paramIDs)); err != nil {
+		return "", "", nil, errors.New("assigning profile parameters: " + err.Error())
+	}
+
+	if err := api.CreateEvent(imp.tx, tc.EventTypeFor("profile", eventAction), imp.inf.User, p); err != nil {
+		return "", "", nil, err
+	}
+	return action, "", nil, nil
+}
+
+func (imp *importer) importDeliveryService(d tc.CDNExportDeliveryService) (string, string, error, error) {
+	xmlID := *d.XMLID
+	existing, err := readDeliveryServices(imp.inf.Tx, "\nWHERE ds.xml_id = :xml_id", map[string]interface{}{"xml_id": xmlID})
+	if err != nil {
+		return "", "", nil, errors.New("reading delivery service: " + err.Error())
+	}
+	old := (*deliveryService)(nil)
+	if len(existing) > 0 {
+		old = &existing[0]
+		if old.cdn != imp.cdnName {
+			return "", "", errors.New("delivery service " + xmlID + " belongs to CDN " + old.cdn), nil
+		}
+	}
+
+	serverIDs := []int64{}
+	servers := []string{}
+	rows, err := imp.tx.Query(`SELECT id, host_name FROM server WHERE cdn_id = $1 AND host_name = ANY($2)`, imp.cdnID, pq.Array(d.Servers))
+	if err != nil {
+		return "", "", nil, errors.New("getting servers: " + err.Error())
+	}
+	defer rows.Close()
+	for rows.Next() {
+		id := int64(0)
+		hostName := ""
+		if err := rows.Scan(&id, &hostName); err != nil {
+			return "", "", nil, errors.New("scanning servers: " + err.Error())
+		}
+		serverIDs = append(serverIDs, id)
+		servers = append(servers, hostName)
+	}
+	if err := rows.Err(); err != nil {
+		return "", "", nil, errors.New("scanning servers: " + err.Error())
+	}
+	rows.Close()
+	msg := ""
+	if missing := missingNames(d.Servers, servers); len(missing) > 0 {
+		msg = "servers not in CDN " + imp.cdnName + " were not assigned: " + strings.Join(missing, ", ")
+	}
+
+	replaceMatchList := d.MatchList != nil && len(*d.MatchList) > 0
+	d.Servers = servers
+	d.CDNName = &imp.cdnName
+	normalizeDeliveryService(&d)
+	if old != nil {
+		if !replaceMatchList {
+			d.MatchList = old.MatchList
+		}
+		if reflect.DeepEqual(old.CDNExportDeliveryService, d) {
+			return tc.CDNImportActionSkip, msg, nil, nil
+		}
+	}
+
+	ds := d.DeliveryServiceNullable
+	ds.CDNID = &imp.cdnID
+	if ds.Type == nil {
+		return "", "", errors.New("type: cannot be blank"), nil
+	}
+	typeID, ok, err := getTypeID(imp.tx, ds.Type.String(), "deliveryservice")
+	if err != nil {
+		return "", "", nil, errors.New("getting type id: " + err.Error())
+	} else if !ok {
+		return "", "", errors.New("no delivery service type named " + ds.Type.String()), nil
+	}
+	ds.TypeID = &typeID
+	if ds.ProfileName != nil {
+		profileID := 0
+		if err := imp.tx.QueryRow(`SELECT id FROM profile WHERE name = $1 AND cdn = $2`, *ds.ProfileName, imp.cdnID).Scan(&profileID); err == sql.ErrNoRows {
+			return "", "", errors.New("no profile named " + *ds.ProfileName + " in CDN " + imp.cdnName), nil
+		} else if err != nil {
+			return "", "", nil, errors.New("getting profile id: " + err.Error())
+		}
+		ds.ProfileID = &profileID
+	}
+	tenantID := imp.inf.User.TenantID
+	if ds.Tenant != nil {
+		if err := imp.tx.QueryRow(`SELECT id FROM tenant WHERE name = $1`, *ds.Tenant).Scan(&tenantID); err == sql.ErrNoRows {
+			return "", "", errors.New("no tenant named " + *ds.Tenant), nil
+		} else if err != nil {
+			return "", "", nil, errors.New("getting tenant id: " + err.Error())
+		}
+	}
+	ds.TenantID = &tenantID
+
+	action := tc.CDNImportActionCreate
+	res, userErr, sysErr := (*tc.DeliveryServiceNullable)(nil), error(nil), error(nil)
+	if old != nil {
+		action = tc.CDNImportActionUpdate
+		ds.ID = &old.id
+		res, _, userErr, sysErr = deliveryservice.Update(imp.inf, ds, &imp.vaultWrites)
+	} else {
+		res, _, userErr, sysErr = deliveryservice.Create(imp.inf, ds, &imp.vaultWrites)
+	}
+	if userErr != nil || sysErr != nil {
+		return "", "", userErr, sysErr
+	}
+	dsID := *res.ID
+
+	if replaceMatchList {
+		if _, err := imp.tx.Exec(deleteDSRegexesQuery, dsID); err != nil {
+			return "", "", nil, errors.New("deleting regexes: " + err.Error())
+		}
+		for _, match := range *d.MatchList {
+			regexTypeID, ok, err := getTypeID(imp.tx, string(match.Type), "regex")
+			if err != nil {
+				return "", "", nil, errors.New("getting regex type id: " + err.Error())
+			} else if !ok {
+				return "", "", errors.New("no regex type named " + string(match.Type)), nil
+			}
+			regexID := 0
+			if err := imp.tx.QueryRow(`INSERT INTO regex (type, pattern) VALUES ($1, $2) RETURNING id`, regexTypeID, match.Pattern).Scan(&regexID); err != nil {
+				return writeErr(err)
+			}
+			if _, err := imp.tx.Exec(`INSERT INTO deliveryservice_regex (deliveryservice, regex, set_number) VALUES ($1, $2, $3)`, dsID, regexID, match.SetNumber); err != nil {
+				return writeErr(err)
+			}
+		}
+	}
+
+	if _, err := imp.tx.Exec(`DELETE FROM deliveryservice_server WHERE deliveryservice = $1`, dsID); err != nil {
+		return "", "", nil, errors.New("removing servers: " + err.Error())
+	}
+	if _, err := imp.tx.Exec(`INSERT INTO deliveryservice_server (deliveryservice, server) SELECT $1, unnest($2::bigint[])`, dsID, pq.Array(serverIDs)); err != nil {
+		return "", "", nil, errors.New("assigning servers: " + err.Error())
+	}
+	for _, resource := range []tenant.QuotaResource{tenant.QuotaRegexesPerDeliveryService, tenant.QuotaEdgeServers} {
+		if userErr, sysErr, _ := tenant.CheckDeliveryServiceQuota(imp.tx, dsID, resource); userErr != nil || sysErr != nil {
+			return "", "", userErr, sysErr
+		}
+	}
+	return action, msg, nil, nil
+}
+
+// missingNames returns the names in want which are not in have, sorted.
+func missingNames(want []string, have []string) []string {
+	found := map[string]struct{}{}
+	for _, name := range have {
+		found[name] = struct{}{}
+	}
+	missing := []string{}
+	for _, name := range want {
+		if _, ok := found[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+func (imp *importer) importSteeringTarget(st tc.CDNExportSteeringTarget) (string, string, error, error) {
+	dsID, userErr, sysErr := imp.getDSID(st.DeliveryService)
+	if userErr != nil || sysErr != nil {
+		return "", "", userErr, sysErr
+	}
+	targetID, userErr, sysErr := imp.getDSID(st.Target)
+	if userErr != nil || sysErr != nil {
+		return "", "", userErr, sysErr
+	}
+	typeID, ok, err := getTypeID(imp.tx, st.Type, "steering_target")
+	if err != nil {
+		return "", "", nil, errors.New("getting type id: " + err.Error())
+	} else if !ok {
+		return "", "", errors.New("no steering target type named " + st.Type), nil
+	}
+
+	oldTypeID, oldValue := 0, 0
+	err = imp.tx.QueryRow(`SELECT type, value FROM steering_target WHERE deliveryservice = $1 AND target = $2`, dsID, targetID).Scan(&oldTypeID, &oldValue)
+	if err == sql.ErrNoRows {
+		if _, err := imp.tx.Exec(`INSERT INTO steering_target (deliveryservice, target, type, value) VALUES ($1, $2, $3, $4)`, dsID, targetID, typeID, st.Value); err != nil {
+			return writeErr(err)
+		}
+		return tc.CDNImportActionCreate, "", nil, nil
+	} else if err != nil {
+		return "", "", nil, errors.New("getting steering target: " + err.Error())
+	}
+	if oldTypeID == typeID && oldValue == st.Value {
+		return tc.CDNImportActionSkip, "", nil, nil
+	}
+	if _, err := imp.tx.Exec(`UPDATE steering_target SET type = $3, value = $4 WHERE deliveryservice = $1 AND target = $2`, dsID, targetID, typeID, st.Value); err != nil {
+		return writeErr(err)
+	}
+	return tc.CDNImportActionUpdate, "", nil, nil
+}
+
+func (imp *importer) importFederation(fed tc.CDNExportFederation) (string, string, error, error) {
+	dsID, userErr, sysErr := imp.getDSID(fed.DeliveryService)
+	if userErr != nil || sysErr != nil {
+		return "", "", userErr, sysErr
+	}
+	obj := cdnfederation.TOCDNFederation{CDNFederation: tc.CDNFederation{CName: &fed.CName, TTL: &fed.TTL}}
+	if err := obj.Validate(); err != nil {
+		return "", "", err, nil
+	}
+	if fed.Resolvers == nil {
+		fed.Resolvers = []tc.CDNExportFederationResolver{}
+	}
+	sortResolvers(fed.Resolvers)
+
+	existing, err := readFederations(imp.tx, []string{fed.DeliveryService})
+	if err != nil {
+		return "", "", nil, errors.New("reading federation: " + err.Error())
+	}
+	old, exists := existing[fed.Name()]
+	if exists && reflect.DeepEqual(old.CDNExportFederation, fed) {
+		return tc.CDNImportActionSkip, "", nil, nil
+	}
+
+	resolverIDs := []int64{}
+	for _, resolver := range fed.Resolvers {
+		resolver := resolver
+		typeID, ok, err := getTypeID(imp.tx, resolver.Type, "federation")
+		if err != nil {
+			return "", "", nil, errors.New("getting type id: " + err.Error())
+		} else if !ok {
+			return "", "", errors.New("no federation resolver type named " + resolver.Type), nil
+		}
+		utypeID := uint(typeID)
+		if err := (&tc.FederationResolver{IPAddress: &resolver.IPAddress, TypeID: &utypeID}).Validate(imp.tx); err != nil {
+			return "", "", fmt.Errorf("resolver %s: %v", resolver.IPAddress, err), nil
+		}
+		resolverID := int64(0)
+		if err := imp.tx.QueryRow(`SELECT id FROM federation_resolver WHERE ip_address = $1 AND type = $2 LIMIT 1`, resolver.IPAddress, typeID).Scan(&resolverID); err == sql.ErrNoRows {
+			if err := imp.tx.QueryRow(`INSERT INTO federation_resolver (ip_address, type) VALUES ($1, $2) RETURNING id`, resolver.IPAddress, typeID).Scan(&resolverID); err != nil {
+				return writeErr(err)
+			}
+		} else if err != nil {
+			return "", "", nil, errors.New("getting federation resolver id: " + err.Error())
+		}
+		resolverIDs = append(resolverIDs, resolverID)
+	}
+
+	action := tc.CDNImportActionCreate
+	id := old.id
+	if exists {
+		action = tc.CDNImportActionUpdate
+		if _, err := imp.tx.Exec(`UPDATE federation SET ttl = $1, description = $2 WHERE id = $3`, fed.TTL, fed.Description, id); err != nil {
+			return writeErr(err)
+		}
+	} else {
+		if err := imp.tx.QueryRow(`INSERT INTO federation (cname, ttl, description) VALUES ($1, $2, $3) RETURNING id`, fed.CName, fed.TTL, fed.Description).Scan(&id); err != nil {
+			return writeErr(err)
+		}
+		if _, err := imp.tx.Exec(`INSERT INTO federation_deliveryservice (federation, deliveryservice) VALUES ($1, $2)`, id, dsID); err != nil {
+			return writeErr(err)
+		}
+	}
+
+	if _, err := imp.tx.Exec(`DELETE FROM federation_federation_resolver WHERE federation = $1`, id); err != nil {
+		return "", "", nil, errors.New("removing federation resolvers: " + err.Error())
+	}
+	if _, err := imp.tx.Exec(`INSERT INTO federation_federation_resolver (federation, federation_resolver) SELECT $1, unnest($2::bigint[])`, id, pq.Array(resolverIDs)); err != nil {
+		return "", "", nil, errors.New("assigning federation resolvers: " + err.Error())
+	}
+	return action, "", nil, nil
+}
@@ -0,0 +1,139 @@
+package cdn
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-rfc"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
+)
+
+func TestSortCacheGroups(t *testing.T) {
+	cgs := []tc.CDNExportCacheGroup{
+		{Name: "edge", ParentName: util.StrPtr("mid"), SecondaryParentName: util.StrPtr("mid2"), Fallbacks: []string{"edge2"}},
+		{Name: "mid2"},
+		{Name: "edge2", ParentName: util.StrPtr("mid")},
+		{Name: "mid", ParentName: util.StrPtr("not-in-export")},
+	}
+	sorted, err := sortCacheGroups(cgs)
+	if err != nil {
+		t.Fatalf("expected no error, actual: %v", err)
+	}
+	if len(sorted) != len(cgs) {
+		t.Fatalf("expected %d cache groups, actual: %d", len(cgs), len(sorted))
+	}
+	positions := map[string]int{}
+	for i, cg := range sorted {
+		positions[cg.Name] = i
+	}
+	for _, dep := range [][2]string{{"mid", "edge"}, {"mid2", "edge"}, {"edge2", "edge"}, {"mid", "edge2"}} {
+		if positions[dep[0]] > positions[dep[1]] {
+			t.Errorf("expected %s before %s, actual: %v", dep[0], dep[1], positions)
+		}
+	}
+
+	cgs = append(cgs, tc.CDNExportCacheGroup{Name: "loop", ParentName: util.StrPtr("loop2")}, tc.CDNExportCacheGroup{Name: "loop2", Fallbacks: []string{"loop"}})
+	if _, err := sortCacheGroups(cgs); err == nil {
+		t.Error("expected an error sorting cache groups with a cycle, actual: nil")
+	}
+}
+
+func TestDecodeExport(t *testing.T) {
+	expected := tc.CDNExport{
+		Version: tc.CDNExportVersion,
+		CDN:     tc.CDNExportCDN{Name: "cdn1", DomainName: "cdn1.example.net"},
+		Profiles: []tc.CDNExportProfile{
+			{
+				Name: "EDGE1",
+				Type: "ATS_PROFILE",
+				Parameters: []tc.ProfileExportImportParameterNullable{
+					{Name: util.StrPtr("location"), ConfigFile: util.StrPtr("records.config"), Value: util.StrPtr("/etc/trafficserver")},
+				},
+			},
+		},
+		Federations: []tc.CDNExportFederation{
+			{CName: "the.cname.com.", TTL: 48, DeliveryService: "ds1", Resolvers: []tc.CDNExportFederationResolver{{IPAddress: "192.0.2.0/24", Type: "RESOLVE4"}}},
+		},
+	}
+
+	bts, err := marshalYAML(expected)
+	if err != nil {
+		t.Fatalf("marshalling YAML: %v", err)
+	}
+	if !strings.Contains(string(bts), "domainName: cdn1.example.net") {
+		t.Errorf("expected YAML to use the JSON field names, actual: %s", bts)
+	}
+	for _, contentType := range []string{ApplicationYAML, "text/yaml; charset=utf-8"} {
+		actual, err := decodeExport(contentType, strings.NewReader(string(bts)))
+		if err != nil {
+			t.Errorf("decoding %s: %v", contentType, err)
+		} else if !reflect.DeepEqual(expected, actual) {
+			t.Errorf("decoding %s: expected %+v, actual: %+v", contentType, expected, actual)
+		}
+	}
+
+	actual, err := decodeExport(rfc.ApplicationJSON, strings.NewReader(`{"version": 1, "cdn": {"name": "cdn1", "domainName": "cdn1.example.net"}}`))
+	if err != nil {
+		t.Fatalf("decoding JSON: %v", err)
+	}
+	if actual.CDN.Name != "cdn1" || actual.CDN.DomainName != "cdn1.example.net" {
+		t.Errorf("expected CDN cdn1 with domain cdn1.example.net, actual: %+v", actual.CDN)
+	}
+	if _, err := decodeExport(ApplicationYAML, strings.NewReader("version: [")); err == nil {
+		t.Error("expected an error decoding malformed YAML, actual: nil")
+	}
+}
+
+func TestNormalizeDeliveryService(t *testing.T) {
+	ds := tc.CDNExportDeliveryService{Servers: []string{"edge2", "edge1"}}
+	ds.ID = util.IntPtr(42)
+	ds.SigningAlgorithm = util.StrPtr(tc.SigningAlgorithmURLSig)
+	ds.MatchList = &[]tc.DeliveryServiceMatch{
+		{Type: "PATH_REGEXP", SetNumber: 1, Pattern: "/foo"},
+		{Type: "HOST_REGEXP", SetNumber: 0, Pattern: `.*\.ds1\..*`},
+	}
+	normalizeDeliveryService(&ds)
+
+	if ds.ID != nil {
+		t.Errorf("expected no ID, actual: %d", *ds.ID)
+	}
+	if !ds.Signed {
+		t.Error("expected a url_sig delivery service to be signed")
+	}
+	if ds.ConsistentHashQueryParams == nil {
+		t.Error("expected consistent hash query params to be empty, actual: nil")
+	}
+	if !reflect.DeepEqual(ds.Servers, []string{"edge1", "edge2"}) {
+		t.Errorf("expected sorted servers, actual: %v", ds.Servers)
+	}
+	if (*ds.MatchList)[0].Type != "HOST_REGEXP" {
+		t.Errorf("expected match list sorted by set number, actual: %+v", *ds.MatchList)
+	}
+}
+
+func TestMissingNames(t *testing.T) {
+	if actual := missingNames([]string{"c", "a", "b"}, []string{"b"}); !reflect.DeepEqual(actual, []string{"a", "c"}) {
+		t.Errorf("expected [a c], actual: %v", actual)
+	}
+}
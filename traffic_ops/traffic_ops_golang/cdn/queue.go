@@ -26,6 +26,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/apache/trafficcontrol/lib/go-tc"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
 )
@@ -69,7 +70,16 @@ func Queue(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	api.CreateChangeLogRawTx(api.ApiChange, "CDN: "+string(cdnName)+", ID: "+strconv.Itoa(inf.IntParams["id"])+", ACTION: CDN server updates "+reqObj.Action+"d", inf.User, inf.Tx.Tx)
-	api.WriteResp(w, r, QueueResp{Action: reqObj.Action, CDNID: int64(inf.IntParams["id"])})
+	resp := QueueResp{Action: reqObj.Action, CDNID: int64(inf.IntParams["id"])}
+	eventType := tc.EventTypeUpdatesCleared
+	if reqObj.Action == "queue" {
+		eventType = tc.EventTypeUpdatesQueued
+	}
+	if err := api.CreateEvent(inf.Tx.Tx, eventType, inf.User, resp); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("creating event: "+err.Error()))
+		return
+	}
+	api.WriteResp(w, r, resp)
 }
 
 func queueUpdates(tx *sql.Tx, cdnID int64, queue bool) error {
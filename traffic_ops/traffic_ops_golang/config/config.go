@@ -27,6 +27,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/apache/trafficcontrol/lib/go-log"
@@ -43,8 +44,9 @@ type Config struct {
 	KeyPath                string   `json:"-"`
 	ConfigHypnotoad        `json:"hypnotoad"`
 	ConfigTrafficOpsGolang `json:"traffic_ops_golang"`
-	ConfigTO               *ConfigTO   `json:"to"`
-	SMTP                   *ConfigSMTP `json:"smtp"`
+	ConfigTO               *ConfigTO      `json:"to"`
+	SMTP                   *ConfigSMTP    `json:"smtp"`
+	Tracing                *ConfigTracing `json:"tracing"`
 	ConfigPortal           `json:"portal"`
 	ConfigLetsEncrypt      `json:"lets_encrypt"`
 	DB                     ConfigDatabase `json:"db"`
@@ -108,6 +110,48 @@ type ConfigTrafficOpsGolang struct {
 	// CRConfigEmulateOldPath is whether to emulate the legacy CRConfig request path when generating a new CRConfig. This primarily exists in the event a tool relies on the legacy path '/tools/write_crconfig'.
 	// Deprecated: will be removed in the next major version.
 	CRConfigEmulateOldPath bool `json:"crconfig_emulate_old_path"`
+	// InvalidationJobScheduleIntervalSeconds is how often to check for deferred and recurring content invalidation jobs which have come due.
+	// This defaults to 60. If it is negative, scheduled jobs are never run by this instance of Traffic Ops.
+	InvalidationJobScheduleIntervalSeconds int `json:"invalidation_job_schedule_interval_seconds"`
+	// EventWebhookMaxAttempts is how many times delivery of an event to a webhook is attempted before it is given up on.
+	// This defaults to 5. If it is negative, events are never delivered to webhooks by this instance of Traffic Ops, though old events are still pruned.
+	EventWebhookMaxAttempts int `json:"event_webhook_max_attempts"`
+	// EventRetentionHours is how long events are kept, for clients of the event stream to catch up on missed events. Events are kept longer if they are still being delivered to webhooks.
+	// This defaults to 24.
+	EventRetentionHours int `json:"event_retention_hours"`
+	// ServercheckHistoryRetentionDays is how long the results reported by check extensions are kept in each server's check history.
+	// This defaults to 30.
+	ServercheckHistoryRetentionDays int `json:"servercheck_history_retention_days"`
+	// GraphQLMaxDepth is the maximum depth of the fields of a GraphQL query, the fields of the query type being depth 1.
+	// This defaults to 10.
+	GraphQLMaxDepth int `json:"graphql_max_depth"`
+	// GraphQLMaxCost is the maximum cost of a GraphQL query: the number of fields it selects, where the fields of lists of objects are counted once for each object they're estimated to have.
+	// This defaults to 10000.
+	GraphQLMaxCost int `json:"graphql_max_cost"`
+	// RateLimit is the limits of the rate of requests of each user. By default, requests aren't limited.
+	RateLimit ConfigRateLimit `json:"rate_limit"`
+}
+
+// ConfigRateLimit contains the limits of the rate of requests each user may make to Traffic Ops. Requests to routes which don't require authentication are limited by client IP address instead.
+// A request must be within both the limit of the route it's to, if it has one, and the user's overall limit, which is the limit of the user, or else of their role, or else the default limit, if there is one. Each user has their own buckets of requests, whether their limits are their own or their role's.
+type ConfigRateLimit struct {
+	Default     *RateLimit           `json:"default"`
+	Routes      map[int]RateLimit    `json:"routes"`
+	Users       map[string]RateLimit `json:"users"`
+	Roles       map[string]RateLimit `json:"roles"`
+	ExemptUsers []string             `json:"exempt_users"`
+	ExemptRoles []string             `json:"exempt_roles"`
+}
+
+// Enabled returns whether any rate limits are configured.
+func (c ConfigRateLimit) Enabled() bool {
+	return c.Default != nil || len(c.Routes) > 0 || len(c.Users) > 0 || len(c.Roles) > 0
+}
+
+// RateLimit is a token bucket limit of the rate of requests: up to Burst requests may be made at once, after which requests may be made at Rate per second.
+type RateLimit struct {
+	Rate  float64 `json:"rate"`
+	Burst int     `json:"burst"`
 }
 
 // RoutingBlacklist contains the list of route IDs that will be handled by TO-Perl, a list of route IDs that are disabled,
@@ -143,6 +187,16 @@ type ConfigSMTP struct {
 	User     string `json:"user"`
 }
 
+// ConfigTracing contains configuration information for exporting OpenTelemetry traces of requests to a collector.
+type ConfigTracing struct {
+	Enabled        bool              `json:"enabled"`
+	Endpoint       string            `json:"endpoint"`
+	Headers        map[string]string `json:"headers"`
+	SampleRatio    *float64          `json:"sample_ratio"`
+	TimeoutSeconds int               `json:"timeout_seconds"`
+	Insecure       bool              `json:"insecure"`
+}
+
 // ConfigLetsEncrypt contains configuration information for integration with the Let's Encrypt certificate authority.
 type ConfigLetsEncrypt struct {
 	Email                     string `json:"user_email,omitempty"`
@@ -194,6 +248,15 @@ func NewFakeConfig() Config {
 
 const DefaultLDAPTimeoutSecs = 60
 const DefaultDBQueryTimeoutSecs = 20
+const DefaultInvalidationJobScheduleIntervalSecs = 60
+const DefaultEventWebhookMaxAttempts = 5
+const DefaultEventRetentionHours = 24
+const DefaultServercheckHistoryRetentionDays = 30
+const DefaultGraphQLMaxDepth = 10
+const DefaultGraphQLMaxCost = 10000
+const DefaultTracingEndpoint = "http://localhost:4318"
+const DefaultTracingSampleRatio = 1.0
+const DefaultTracingTimeoutSecs = 10
 
 // ErrorLog - critical messages
 func (c Config) ErrorLog() log.LogLocation {
@@ -371,6 +434,35 @@ func ParseConfig(cfg Config) (Config, error) {
 	if cfg.DBQueryTimeoutSeconds == 0 {
 		cfg.DBQueryTimeoutSeconds = DefaultDBQueryTimeoutSecs
 	}
+	if cfg.InvalidationJobScheduleIntervalSeconds == 0 {
+		cfg.InvalidationJobScheduleIntervalSeconds = DefaultInvalidationJobScheduleIntervalSecs
+	}
+	if cfg.EventWebhookMaxAttempts == 0 {
+		cfg.EventWebhookMaxAttempts = DefaultEventWebhookMaxAttempts
+	}
+	if cfg.EventRetentionHours <= 0 {
+		cfg.EventRetentionHours = DefaultEventRetentionHours
+	}
+	if cfg.ServercheckHistoryRetentionDays <= 0 {
+		cfg.ServercheckHistoryRetentionDays = DefaultServercheckHistoryRetentionDays
+	}
+	if cfg.GraphQLMaxDepth <= 0 {
+		cfg.GraphQLMaxDepth = DefaultGraphQLMaxDepth
+	}
+	if cfg.GraphQLMaxCost <= 0 {
+		cfg.GraphQLMaxCost = DefaultGraphQLMaxCost
+	}
+	if cfg.Tracing != nil && cfg.Tracing.Enabled {
+		if cfg.Tracing.Endpoint == "" {
+			cfg.Tracing.Endpoint = DefaultTracingEndpoint
+		}
+		if cfg.Tracing.SampleRatio == nil {
+			cfg.Tracing.SampleRatio = util.FloatPtr(DefaultTracingSampleRatio)
+		}
+		if cfg.Tracing.TimeoutSeconds <= 0 {
+			cfg.Tracing.TimeoutSeconds = DefaultTracingTimeoutSecs
+		}
+	}
 
 	invalidTOURLStr := ""
 	var err error
@@ -419,9 +511,42 @@ func ParseConfig(cfg Config) (Config, error) {
 		return Config{}, err
 	}
 
+	if err := ValidateRateLimit(cfg.RateLimit); err != nil {
+		return Config{}, err
+	}
+
+	if cfg.Tracing != nil && cfg.Tracing.Enabled {
+		if ratio := *cfg.Tracing.SampleRatio; ratio < 0 || ratio > 1 {
+			return Config{}, fmt.Errorf("tracing.sample_ratio must be between 0 and 1, was %v", ratio)
+		}
+	}
+
 	return cfg, nil
 }
 
+// ValidateRateLimit returns an error if any of the rate limits would never allow a request.
+func ValidateRateLimit(rateLimit ConfigRateLimit) error {
+	limits := map[string]RateLimit{}
+	if rateLimit.Default != nil {
+		limits["default"] = *rateLimit.Default
+	}
+	for id, limit := range rateLimit.Routes {
+		limits["route "+strconv.Itoa(id)] = limit
+	}
+	for name, limit := range rateLimit.Users {
+		limits["user '"+name+"'"] = limit
+	}
+	for name, limit := range rateLimit.Roles {
+		limits["role '"+name+"'"] = limit
+	}
+	for name, limit := range limits {
+		if limit.Rate <= 0 || limit.Burst < 1 {
+			return fmt.Errorf("rate_limit of %s must have a rate greater than 0 and a burst of at least 1", name)
+		}
+	}
+	return nil
+}
+
 func ValidateRoutingBlacklist(blacklist RoutingBlacklist) error {
 	seenPerlIDs := make(map[int]struct{}, len(blacklist.PerlRoutes))
 	for _, id := range blacklist.PerlRoutes {
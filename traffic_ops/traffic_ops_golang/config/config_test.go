@@ -339,3 +339,50 @@ func TestValidateRoutingBlacklist(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateRateLimit(t *testing.T) {
+	type testCase struct {
+		Input     ConfigRateLimit
+		ExpectErr bool
+	}
+	testCases := []testCase{
+		{
+			Input:     ConfigRateLimit{},
+			ExpectErr: false,
+		},
+		{
+			Input: ConfigRateLimit{
+				Default: &RateLimit{Rate: 10, Burst: 50},
+				Routes:  map[int]RateLimit{1: {Rate: 0.5, Burst: 1}},
+				Users:   map[string]RateLimit{"script": {Rate: 1, Burst: 5}},
+				Roles:   map[string]RateLimit{"admin": {Rate: 100, Burst: 100}},
+			},
+			ExpectErr: false,
+		},
+		{
+			Input: ConfigRateLimit{
+				Default: &RateLimit{Rate: 0, Burst: 50},
+			},
+			ExpectErr: true,
+		},
+		{
+			Input: ConfigRateLimit{
+				Routes: map[int]RateLimit{1: {Rate: 1}},
+			},
+			ExpectErr: true,
+		},
+		{
+			Input: ConfigRateLimit{
+				Roles: map[string]RateLimit{"admin": {Rate: -1, Burst: 1}},
+			},
+			ExpectErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		if err := ValidateRateLimit(tc.Input); err != nil && !tc.ExpectErr {
+			t.Errorf("Expected: no error, actual: %v", err)
+		} else if err == nil && tc.ExpectErr {
+			t.Errorf("Expected: non-nil error, actual: nil")
+		}
+	}
+}
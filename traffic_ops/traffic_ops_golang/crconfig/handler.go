@@ -210,6 +210,10 @@ func snapshotHandler(w http.ResponseWriter, r *http.Request, deprecated bool) {
 	}
 
 	api.CreateChangeLogRawTx(api.ApiChange, "CDN: "+cdn+", ID: "+strconv.Itoa(inf.IntParams["id"])+", ACTION: Snapshot of CRConfig and Monitor", inf.User, inf.Tx.Tx)
+	if err := api.CreateEvent(inf.Tx.Tx, tc.EventTypeSnapshotTaken, inf.User, map[string]string{"cdn": cdn}); err != nil {
+		api.HandleErrOptionalDeprecation(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("creating event: "+err.Error()), deprecated, &alt)
+		return
+	}
 	if deprecated {
 		api.WriteAlertsObj(w, r, http.StatusOK, api.CreateDeprecationAlerts(&alt), "SUCCESS")
 		return
@@ -257,6 +261,10 @@ func SnapshotOldGUIHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	api.CreateChangeLogRawTx(api.ApiChange, "Snapshot of CRConfig performed for "+cdn, inf.User, inf.Tx.Tx)
+	if err := api.CreateEvent(inf.Tx.Tx, tc.EventTypeSnapshotTaken, inf.User, map[string]string{"cdn": cdn}); err != nil {
+		writePerlHTMLErr(w, r, inf.Tx.Tx, errors.New(r.RemoteAddr+" creating event: "+err.Error()), err)
+		return
+	}
 	http.Redirect(w, r, "/tools/flash_and_close/"+url.PathEscape("Successfully wrote the CRConfig.json!"), http.StatusFound)
 }
 
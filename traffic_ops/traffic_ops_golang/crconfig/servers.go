@@ -52,6 +52,11 @@ func makeCRConfigServers(cdn string, tx *sql.Tx, cdnDomain string) (
 		return nil, nil, nil, errors.New("getting server deliveryservices: " + err.Error())
 	}
 
+	serverInterfaces, err := getServerInterfaces(cdn, tx)
+	if err != nil {
+		return nil, nil, nil, errors.New("getting server interfaces: " + err.Error())
+	}
+
 	servers := map[string]tc.CRConfigTrafficOpsServer{}
 	routers := map[string]tc.CRConfigRouter{}
 	monitors := map[string]tc.CRConfigMonitor{}
@@ -86,6 +91,7 @@ func makeCRConfigServers(cdn string, tx *sql.Tx, cdnDomain string) (
 			if s.RoutingDisabled == 0 {
 				s.CRConfigTrafficOpsServer.DeliveryServices = serverDSes[tc.CacheName(host)]
 			}
+			s.CRConfigTrafficOpsServer.Interfaces = serverInterfaces[host]
 			servers[host] = s.CRConfigTrafficOpsServer
 		}
 	}
@@ -215,6 +221,73 @@ and (st.name = 'REPORTED' or st.name = 'ONLINE' or st.name = 'ADMIN_DOWN')
 	return servers, nil
 }
 
+// getServerInterfaces returns the network interfaces of each server in the CDN, keyed by host name.
+func getServerInterfaces(cdn string, tx *sql.Tx) (map[string][]tc.ServerInterfaceInfo, error) {
+	q := `
+select s.host_name,
+       i.name,
+       i.max_bandwidth,
+       i.monitor,
+       i.mtu,
+       ip.address,
+       ip.gateway,
+       ip.service_address
+from interface as i
+inner join server as s on s.id = i.server
+left join ip_address as ip on ip.server = i.server and ip.interface = i.name
+where s.cdn_id = (select id from cdn where name = $1)
+order by s.host_name, i.name, ip.address
+`
+	rows, err := tx.Query(q, cdn)
+	if err != nil {
+		return nil, errors.New("querying server interfaces: " + err.Error())
+	}
+	defer rows.Close()
+
+	interfaces := map[string][]tc.ServerInterfaceInfo{}
+	for rows.Next() {
+		host := ""
+		name := ""
+		maxBandwidth := sql.NullInt64{}
+		monitor := false
+		mtu := sql.NullInt64{}
+		address := sql.NullString{}
+		gateway := sql.NullString{}
+		serviceAddress := sql.NullBool{}
+		if err := rows.Scan(&host, &name, &maxBandwidth, &monitor, &mtu, &address, &gateway, &serviceAddress); err != nil {
+			return nil, errors.New("scanning server interfaces: " + err.Error())
+		}
+		ifaces := interfaces[host]
+		if len(ifaces) == 0 || ifaces[len(ifaces)-1].Name != name {
+			iface := tc.ServerInterfaceInfo{Name: name, Monitor: monitor, IPAddresses: []tc.ServerIPAddress{}}
+			if maxBandwidth.Valid {
+				v := uint64(maxBandwidth.Int64)
+				iface.MaxBandwidth = &v
+			}
+			if mtu.Valid {
+				v := uint64(mtu.Int64)
+				iface.MTU = &v
+			}
+			ifaces = append(ifaces, iface)
+		}
+		if address.Valid {
+			addr := tc.ServerIPAddress{Address: address.String, ServiceAddress: serviceAddress.Bool}
+			if gateway.Valid {
+				addr.Gateway = &gateway.String
+			}
+			ifaces[len(ifaces)-1].IPAddresses = append(ifaces[len(ifaces)-1].IPAddresses, addr)
+		}
+		interfaces[host] = ifaces
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.New("iterating server interface rows: " + err.Error())
+	}
+	return interfaces, nil
+}
+
+// getServerDSNames returns the Delivery Services of each server. Delivery Services with a Topology
+// are on the edge servers of the CDN in the Topology's first tier Cache Groups, regardless of the
+// servers assigned to them.
 func getServerDSNames(cdn string, tx *sql.Tx) (map[tc.CacheName][]tc.DeliveryServiceName, error) {
 	q := `
 select s.host_name, ds.xml_id
@@ -227,6 +300,24 @@ inner join status as st ON st.id = s.status
 where ds.cdn_id = (select id from cdn where name = $1)
 and ds.active = true` +
 		fmt.Sprintf(" and dt.name != '%s' ", tc.DSTypeAnyMap) + `
+and ds.topology is null
+and p.routing_disabled = false
+and (st.name = 'REPORTED' or st.name = 'ONLINE' or st.name = 'ADMIN_DOWN')
+union
+select s.host_name, ds.xml_id
+from deliveryservice as ds
+inner join topology_cachegroup as tc on tc.topology = ds.topology
+inner join cachegroup as cg on cg.name = tc.cachegroup
+inner join server as s on s.cachegroup = cg.id and s.cdn_id = ds.cdn_id
+inner join type as t on t.id = s.type
+inner join type as dt on dt.id = ds.type
+inner join profile as p on p.id = s.profile
+inner join status as st ON st.id = s.status
+where ds.cdn_id = (select id from cdn where name = $1)
+and ds.active = true` +
+		fmt.Sprintf(" and dt.name != '%s' ", tc.DSTypeAnyMap) + `
+and t.name like '` + tc.EdgeTypePrefix + `%'
+and not exists (select 1 from topology_cachegroup_parents as tcp where tcp.parent = tc.id)
 and p.routing_disabled = false
 and (st.name = 'REPORTED' or st.name = 'ONLINE' or st.name = 'ADMIN_DOWN')
 `
@@ -270,6 +270,60 @@ func TestGetAllServersNonService(t *testing.T) {
 	}
 }
 
+func TestGetServerInterfaces(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	cdn := "mycdn"
+	maxBandwidth := uint64(10000000)
+	mtu := uint64(9000)
+	expected := map[string][]tc.ServerInterfaceInfo{
+		"cache0": []tc.ServerInterfaceInfo{
+			{
+				Name:         "bond0",
+				MaxBandwidth: &maxBandwidth,
+				Monitor:      true,
+				MTU:          &mtu,
+				IPAddresses: []tc.ServerIPAddress{
+					{Address: "192.0.2.1/24", Gateway: util.StrPtr("192.0.2.254"), ServiceAddress: true},
+					{Address: "2001:db8::1/64", ServiceAddress: true},
+				},
+			},
+			{
+				Name:        "eth1",
+				IPAddresses: []tc.ServerIPAddress{},
+			},
+		},
+	}
+
+	mock.ExpectBegin()
+	rows := sqlmock.NewRows([]string{"host_name", "name", "max_bandwidth", "monitor", "mtu", "address", "gateway", "service_address"})
+	rows = rows.AddRow("cache0", "bond0", int64(maxBandwidth), true, int64(mtu), "192.0.2.1/24", "192.0.2.254", true)
+	rows = rows.AddRow("cache0", "bond0", int64(maxBandwidth), true, int64(mtu), "2001:db8::1/64", nil, true)
+	rows = rows.AddRow("cache0", "eth1", nil, false, nil, nil, nil, nil)
+	mock.ExpectQuery("select").WithArgs(cdn).WillReturnRows(rows)
+	mock.ExpectCommit()
+
+	dbCtx, cancel := context.WithTimeout(context.TODO(), time.Duration(10)*time.Second)
+	defer cancel()
+	tx, err := db.BeginTx(dbCtx, nil)
+	if err != nil {
+		t.Fatalf("creating transaction: %v", err)
+	}
+	defer tx.Commit()
+
+	actual, err := getServerInterfaces(cdn, tx)
+	if err != nil {
+		t.Fatalf("getServerInterfaces expected: nil error, actual: %v", err)
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("getServerInterfaces expected: %+v, actual: %+v", expected, actual)
+	}
+}
+
 func ExpectedGetServerDSNames() map[tc.CacheName][]tc.DeliveryServiceName {
 	return map[tc.CacheName][]tc.DeliveryServiceName{
 		"cache0": []tc.DeliveryServiceName{"ds0", "ds1"},
@@ -61,7 +61,7 @@ func GetCapacity(w http.ResponseWriter, r *http.Request) {
 		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, nil, nil)
 	}
 
-	capacity, err := getCapacity(inf.Tx.Tx, ds, cdn)
+	capacity, err := getCapacity(inf.Tx.Tx, ds, cdn, inf.CorrelationID)
 	if err != nil {
 		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting delivery service capacity: "+err.Error()))
 		return
@@ -84,12 +84,12 @@ type CapData struct {
 	Capacity    float64
 }
 
-func getCapacity(tx *sql.Tx, ds tc.DeliveryServiceName, cdn tc.CDNName) (CapacityResp, error) {
+func getCapacity(tx *sql.Tx, ds tc.DeliveryServiceName, cdn tc.CDNName, correlationID string) (CapacityResp, error) {
 	monitors, err := monitorhlp.GetURLs(tx)
 	if err != nil {
 		return CapacityResp{}, errors.New("getting monitor URLs: " + err.Error())
 	}
-	client, err := monitorhlp.GetClient(tx)
+	client, err := monitorhlp.GetClient(tx, correlationID)
 	if err != nil {
 		return CapacityResp{}, errors.New("getting monitor client: " + err.Error())
 	}
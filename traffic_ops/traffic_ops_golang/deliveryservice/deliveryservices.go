@@ -86,6 +86,13 @@ func (ds *TODeliveryService) GetType() string {
 	return "ds"
 }
 
+// LastModifiedTables implements api.HasLastModifiedTables.
+// The consistent hash query parameters have no last_updated column, but are only changed along with their delivery service.
+// Users are included because a user's tenant determines which delivery services they can read.
+func (ds *TODeliveryService) LastModifiedTables() []string {
+	return []string{"deliveryservice", "type", "cdn", "profile", "tenant", "origin", "regex", "deliveryservice_regex", "tm_user"}
+}
+
 // IsTenantAuthorized checks that the user is authorized for both the delivery service's existing tenant, and the new tenant they're changing it to (if different).
 func (ds *TODeliveryService) IsTenantAuthorized(user *auth.CurrentUser) (bool, error) {
 	return isTenantAuthorized(ds.ReqInfo, &ds.DeliveryServiceNullable)
@@ -175,6 +182,9 @@ func CreateV15(w http.ResponseWriter, r *http.Request) {
 		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, errors.New("decoding: "+err.Error()), nil)
 		return
 	}
+	if inf.Version == nil || inf.Version.Major < 2 {
+		ds.Topology = nil // Topologies were added in API 2.0
+	}
 
 	res, status, userErr, sysErr := createV15(w, r, inf, ds)
 	if userErr != nil || sysErr != nil {
@@ -211,8 +221,26 @@ func createV14(w http.ResponseWriter, r *http.Request, inf *api.APIInfo, reqDS t
 	return nil, status, userErr, sysErr
 }
 
-// create creates the given ds in the database, and returns the DS with its id and other fields created on insert set. On error, the HTTP status code, user error, and system error are returned. The status code SHOULD NOT be used, if both errors are nil.
 func createV15(w http.ResponseWriter, r *http.Request, inf *api.APIInfo, reqDS tc.DeliveryServiceNullableV15) (*tc.DeliveryServiceNullableV15, int, error, error) {
+	return create(inf, reqDS, nil)
+}
+
+// TrafficVaultWrite makes a write to Traffic Vault for a Delivery Service created or updated in a transaction. Traffic Vault writes can't be rolled back with the transaction, so they must be made after everything else in it which could fail, just before it's committed. It returns the HTTP status code, user error, and system error, as Create and Update do.
+type TrafficVaultWrite func() (int, error, error)
+
+// Create creates the given Delivery Service as the user of inf, as a POST request to /deliveryservices does, except that its Traffic Vault writes - generating its DNSSEC keys - are appended to vaultWrites, rather than made.
+func Create(inf *api.APIInfo, ds tc.DeliveryServiceNullable, vaultWrites *[]TrafficVaultWrite) (*tc.DeliveryServiceNullable, int, error, error) {
+	res, status, userErr, sysErr := create(inf, tc.DeliveryServiceNullableV15(ds), vaultWrites)
+	if res != nil {
+		created := tc.DeliveryServiceNullable(*res)
+		return &created, status, userErr, sysErr
+	}
+	return nil, status, userErr, sysErr
+}
+
+// create creates the given ds in the database, and returns the DS with its id and other fields created on insert set. On error, the HTTP status code, user error, and system error are returned. The status code SHOULD NOT be used, if both errors are nil.
+// If vaultWrites is nil, Traffic Vault is written to immediately, otherwise the writes are appended to it.
+func create(inf *api.APIInfo, reqDS tc.DeliveryServiceNullableV15, vaultWrites *[]TrafficVaultWrite) (*tc.DeliveryServiceNullableV15, int, error, error) {
 	ds := tc.DeliveryServiceNullable(reqDS)
 	user := inf.User
 	tx := inf.Tx.Tx
@@ -288,7 +316,8 @@ func createV15(w http.ResponseWriter, r *http.Request, inf *api.APIInfo, reqDS t
 		&ds.TypeID,
 		&ds.XMLID,
 		&ds.EcsEnabled,
-		&ds.RangeSliceBlockSize)
+		&ds.RangeSliceBlockSize,
+		&ds.Topology)
 
 	if err != nil {
 		usrErr, sysErr, code := api.ParseDBError(err)
@@ -324,6 +353,12 @@ func createV15(w http.ResponseWriter, r *http.Request, inf *api.APIInfo, reqDS t
 	}
 	ds.Type = &dsType
 
+	if ds.TenantID != nil {
+		if userErr, sysErr, errCode := tenant.CheckQuota(tx, *ds.TenantID, tenant.QuotaDeliveryServices); userErr != nil || sysErr != nil {
+			return nil, errCode, userErr, sysErr
+		}
+	}
+
 	if err := createDefaultRegex(tx, *ds.ID, *ds.XMLID); err != nil {
 		return nil, http.StatusInternalServerError, nil, errors.New("creating default regex: " + err.Error())
 	}
@@ -356,7 +391,9 @@ func createV15(w http.ResponseWriter, r *http.Request, inf *api.APIInfo, reqDS t
 		return nil, http.StatusInternalServerError, nil, errors.New("ensuring ds parameters:: " + err.Error())
 	}
 
-	if dnssecEnabled {
+	if dnssecEnabled && vaultWrites != nil {
+		*vaultWrites = append(*vaultWrites, putNewDSDNSSecKeys(tx, cfg, ds))
+	} else if dnssecEnabled {
 		if userErr, sysErr, statusCode := PutDNSSecKeys(tx, cfg, *ds.XMLID, cdnName, ds.ExampleURLs); userErr != nil || sysErr != nil {
 			return nil, statusCode, userErr, sysErr
 		}
@@ -372,9 +409,29 @@ func createV15(w http.ResponseWriter, r *http.Request, inf *api.APIInfo, reqDS t
 	}
 
 	dsLatest := tc.DeliveryServiceNullableV15(ds)
+	if err := api.CreateEvent(tx, tc.EventTypeDeliveryServiceCreated, user, dsLatest); err != nil {
+		return nil, http.StatusInternalServerError, nil, errors.New("creating event: " + err.Error())
+	}
 	return &dsLatest, http.StatusOK, nil, nil
 }
 
+// putNewDSDNSSecKeys returns the Traffic Vault write which generates the DNSSEC keys of the newly created ds, for the match lists and CDN it has when the write is made.
+func putNewDSDNSSecKeys(tx *sql.Tx, cfg *config.Config, ds tc.DeliveryServiceNullable) TrafficVaultWrite {
+	return func() (int, error, error) {
+		cdnName, cdnDomain, _, err := getCDNNameDomainDNSSecEnabled(*ds.ID, tx)
+		if err != nil {
+			return http.StatusInternalServerError, nil, errors.New("creating DS " + *ds.XMLID + ": getting CDN info: " + err.Error())
+		}
+		matchlists, err := GetDeliveryServicesMatchLists([]string{*ds.XMLID}, tx)
+		if err != nil {
+			return http.StatusInternalServerError, nil, errors.New("creating DS " + *ds.XMLID + ": reading matchlists: " + err.Error())
+		}
+		exampleURLs := MakeExampleURLs(ds.Protocol, *ds.Type, *ds.RoutingName, matchlists[*ds.XMLID], cdnDomain)
+		userErr, sysErr, statusCode := PutDNSSecKeys(tx, cfg, *ds.XMLID, cdnName, exampleURLs)
+		return statusCode, userErr, sysErr
+	}
+}
+
 func createDefaultRegex(tx *sql.Tx, dsID int, xmlID string) error {
 	regexStr := `.*\.` + xmlID + `\..*`
 	regexID := 0
@@ -458,6 +515,11 @@ func UpdateV12(w http.ResponseWriter, r *http.Request) {
 	}
 	ds.ID = &id
 
+	if userErr, sysErr, errCode := checkPreconditions(r, inf, id); userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+
 	res, status, userErr, sysErr := updateV12(w, r, inf, &ds)
 	if userErr != nil || sysErr != nil {
 		api.HandleErr(w, r, inf.Tx.Tx, status, userErr, sysErr)
@@ -483,6 +545,11 @@ func UpdateV13(w http.ResponseWriter, r *http.Request) {
 	}
 	ds.ID = &id
 
+	if userErr, sysErr, errCode := checkPreconditions(r, inf, id); userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+
 	res, status, userErr, sysErr := updateV13(w, r, inf, &ds)
 	if userErr != nil || sysErr != nil {
 		api.HandleErr(w, r, inf.Tx.Tx, status, userErr, sysErr)
@@ -508,6 +575,11 @@ func UpdateV14(w http.ResponseWriter, r *http.Request) {
 	}
 	ds.ID = &id
 
+	if userErr, sysErr, errCode := checkPreconditions(r, inf, id); userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+
 	res, status, userErr, sysErr := updateV14(w, r, inf, &ds)
 	if userErr != nil || sysErr != nil {
 		api.HandleErr(w, r, inf.Tx.Tx, status, userErr, sysErr)
@@ -532,6 +604,18 @@ func UpdateV15(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	ds.ID = &id
+	if inf.Version == nil || inf.Version.Major < 2 {
+		// Topologies were added in API 2.0, so keep the existing one, which older clients don't know about.
+		if err := inf.Tx.Tx.QueryRow(`SELECT topology FROM deliveryservice WHERE id = $1`, id).Scan(&ds.Topology); err != nil && err != sql.ErrNoRows {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting existing delivery service topology: "+err.Error()))
+			return
+		}
+	}
+
+	if userErr, sysErr, errCode := checkPreconditions(r, inf, id); userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
 
 	res, status, userErr, sysErr := updateV15(w, r, inf, &ds)
 	if userErr != nil || sysErr != nil {
@@ -541,6 +625,11 @@ func UpdateV15(w http.ResponseWriter, r *http.Request) {
 	api.WriteRespAlertObj(w, r, tc.SuccessLevel, "Deliveryservice update was successful.", []tc.DeliveryServiceNullableV15{*res})
 }
 
+// checkPreconditions checks the request's If-Match and If-Unmodified-Since headers against the Delivery Service with the given ID.
+func checkPreconditions(r *http.Request, inf *api.APIInfo, id int) (error, error, int) {
+	return api.CheckPreconditions(r, inf, &TODeliveryService{}, map[string]string{"id": strconv.Itoa(id)})
+}
+
 func updateV12(w http.ResponseWriter, r *http.Request, inf *api.APIInfo, reqDS *tc.DeliveryServiceNullableV12) (*tc.DeliveryServiceNullableV12, int, error, error) {
 	dsV13 := tc.DeliveryServiceNullableV13{DeliveryServiceNullableV12: *reqDS}
 	// query the DB for existing 1.3 fields in order to "upgrade" this 1.2 request into a 1.3 request
@@ -615,7 +704,8 @@ func updateV14(w http.ResponseWriter, r *http.Request, inf *api.APIInfo, reqDS *
 	query := `
 SELECT
   ds.ecs_enabled,
-  ds.range_slice_block_size
+  ds.range_slice_block_size,
+  ds.topology
 FROM
   deliveryservice ds
 WHERE
@@ -623,6 +713,7 @@ WHERE
 	if err := inf.Tx.Tx.QueryRow(query, *reqDS.ID).Scan(
 		&dsV15.EcsEnabled,
 		&dsV15.RangeSliceBlockSize,
+		&dsV15.Topology,
 	); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, http.StatusNotFound, fmt.Errorf("delivery service ID %d not found", *dsV15.ID), nil
@@ -637,6 +728,22 @@ WHERE
 }
 
 func updateV15(w http.ResponseWriter, r *http.Request, inf *api.APIInfo, reqDS *tc.DeliveryServiceNullableV15) (*tc.DeliveryServiceNullableV15, int, error, error) {
+	return update(inf, reqDS, nil)
+}
+
+// Update updates the given Delivery Service, which must have its ID set, as the user of inf, as a PUT request to /deliveryservices/{{ID}} does, except that its Traffic Vault writes - updating the host name of its SSL keys - are appended to vaultWrites, rather than made.
+func Update(inf *api.APIInfo, ds tc.DeliveryServiceNullable, vaultWrites *[]TrafficVaultWrite) (*tc.DeliveryServiceNullable, int, error, error) {
+	reqDS := tc.DeliveryServiceNullableV15(ds)
+	res, status, userErr, sysErr := update(inf, &reqDS, vaultWrites)
+	if res != nil {
+		updated := tc.DeliveryServiceNullable(*res)
+		return &updated, status, userErr, sysErr
+	}
+	return nil, status, userErr, sysErr
+}
+
+// update updates the given ds in the database. If vaultWrites is nil, Traffic Vault is written to immediately, otherwise the writes are appended to it.
+func update(inf *api.APIInfo, reqDS *tc.DeliveryServiceNullableV15, vaultWrites *[]TrafficVaultWrite) (*tc.DeliveryServiceNullableV15, int, error, error) {
 	converted := tc.DeliveryServiceNullable(*reqDS)
 	ds := &converted
 	tx := inf.Tx.Tx
@@ -668,6 +775,16 @@ func updateV15(w http.ResponseWriter, r *http.Request, inf *api.APIInfo, reqDS *
 		return nil, http.StatusInternalServerError, nil, errors.New("getting delivery service type during update: " + err.Error())
 	}
 
+	// oldTenantID will be used to determine if the quotas of the new tenant need checking.
+	oldTenant, err := tenant.GetDeliveryServiceTenantInfoID(tx, *ds.ID)
+	if err != nil {
+		if oldTenant == nil {
+			return nil, http.StatusInternalServerError, nil, errors.New("getting existing delivery service tenant: " + err.Error())
+		}
+		return nil, http.StatusNotFound, errors.New("delivery service '" + *ds.XMLID + "' not found"), nil
+	}
+	oldTenantID := oldTenant.TenantID
+
 	// oldHostName will be used to determine if SSL Keys need updating - this will be empty if the DS doesn't have SSL keys, because DS types without SSL keys may not have regexes, and thus will fail to get a host name.
 	oldHostName := ""
 	if dsType.HasSSLKeys() {
@@ -738,6 +855,7 @@ func updateV15(w http.ResponseWriter, r *http.Request, inf *api.APIInfo, reqDS *
 		&ds.MaxOriginConnections,
 		&ds.EcsEnabled,
 		&ds.RangeSliceBlockSize,
+		&ds.Topology,
 		&ds.ID)
 
 	if err != nil {
@@ -778,6 +896,14 @@ func updateV15(w http.ResponseWriter, r *http.Request, inf *api.APIInfo, reqDS *
 	}
 	ds.Type = &newDSType
 
+	if ds.TenantID != nil && (oldTenantID == nil || *oldTenantID != *ds.TenantID) {
+		for _, resource := range []tenant.QuotaResource{tenant.QuotaDeliveryServices, tenant.QuotaEdgeServers} {
+			if userErr, sysErr, errCode := tenant.CheckDeliveryServiceQuota(tx, *ds.ID, resource); userErr != nil || sysErr != nil {
+				return nil, errCode, userErr, sysErr
+			}
+		}
+	}
+
 	cdnDomain, err := getCDNDomain(*ds.ID, tx) // need to get the domain again, in case it changed.
 	if err != nil {
 		return nil, http.StatusInternalServerError, nil, errors.New("getting CDN domain after update: " + err.Error())
@@ -802,7 +928,9 @@ func updateV15(w http.ResponseWriter, r *http.Request, inf *api.APIInfo, reqDS *
 		}
 	}
 
-	if newDSType.HasSSLKeys() && oldHostName != newHostName {
+	if vaultWrites != nil && newDSType.HasSSLKeys() {
+		*vaultWrites = append(*vaultWrites, updateChangedSSLKeys(tx, cfg, *ds, oldHostName))
+	} else if newDSType.HasSSLKeys() && oldHostName != newHostName {
 		if err := updateSSLKeys(ds, newHostName, tx, cfg); err != nil {
 			return nil, http.StatusInternalServerError, nil, errors.New("updating delivery service " + *ds.XMLID + ": updating SSL keys: " + err.Error())
 		}
@@ -837,6 +965,9 @@ func updateV15(w http.ResponseWriter, r *http.Request, inf *api.APIInfo, reqDS *
 		return nil, http.StatusInternalServerError, nil, errors.New("writing change log entry: " + err.Error())
 	}
 	dsLatest := tc.DeliveryServiceNullableV15(*ds)
+	if err := api.CreateEvent(tx, tc.EventTypeDeliveryServiceUpdated, user, dsLatest); err != nil {
+		return nil, http.StatusInternalServerError, nil, errors.New("creating event: " + err.Error())
+	}
 	return &dsLatest, http.StatusOK, nil, nil
 }
 
@@ -1136,6 +1267,7 @@ func GetDeliveryServices(query string, queryValues map[string]interface{}, tx *s
 			&ds.TenantID,
 			&ds.Tenant,
 			&ds.TRRequestHeaders,
+			&ds.Topology,
 			&ds.TRResponseHeaders,
 			&ds.Type,
 			&ds.TypeID,
@@ -1190,6 +1322,23 @@ func GetDeliveryServices(query string, queryValues map[string]interface{}, tx *s
 	return dses, nil, nil, http.StatusOK
 }
 
+// updateChangedSSLKeys returns the Traffic Vault write which updates the SSL keys of the updated ds, if its host name when the write is made is no longer oldHostName.
+func updateChangedSSLKeys(tx *sql.Tx, cfg *config.Config, ds tc.DeliveryServiceNullable, oldHostName string) TrafficVaultWrite {
+	return func() (int, error, error) {
+		hostName, err := getOldHostName(*ds.ID, tx)
+		if err != nil {
+			return http.StatusInternalServerError, nil, errors.New("updating delivery service " + *ds.XMLID + ": getting hostname: " + err.Error())
+		}
+		if hostName == oldHostName {
+			return http.StatusOK, nil, nil
+		}
+		if err := updateSSLKeys(&ds, hostName, tx, cfg); err != nil {
+			return http.StatusInternalServerError, nil, errors.New("updating delivery service " + *ds.XMLID + ": updating SSL keys: " + err.Error())
+		}
+		return http.StatusOK, nil, nil
+	}
+}
+
 func updateSSLKeys(ds *tc.DeliveryServiceNullable, hostName string, tx *sql.Tx, cfg *config.Config) error {
 	if ds.XMLID == nil {
 		return errors.New("delivery services has no XMLID!")
@@ -1656,6 +1805,7 @@ ds.ssl_key_version,
 ds.tenant_id,
 tenant.name,
 ds.tr_request_headers,
+ds.topology,
 ds.tr_response_headers,
 type.name,
 ds.type as type_id,
@@ -1726,8 +1876,9 @@ anonymous_blocking_enabled=$50,
 consistent_hash_regex=$51,
 max_origin_connections=$52,
 ecs_enabled=$53,
-range_slice_block_size=$54
-WHERE id=$55
+range_slice_block_size=$54,
+topology=$55
+WHERE id=$56
 RETURNING last_updated
 `
 }
@@ -1788,9 +1939,10 @@ tr_response_headers,
 type,
 xml_id,
 ecs_enabled,
-range_slice_block_size
+range_slice_block_size,
+topology
 )
-VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22,$23,$24,$25,$26,$27,$28,$29,$30,$31,$32,$33,$34,$35,$36,$37,$38,$39,$40,$41,$42,$43,$44,$45,$46,$47,$48,$49,$50,$51,$52,$53,$54)
+VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22,$23,$24,$25,$26,$27,$28,$29,$30,$31,$32,$33,$34,$35,$36,$37,$38,$39,$40,$41,$42,$43,$44,$45,$46,$47,$48,$49,$50,$51,$52,$53,$54,$55)
 RETURNING id, last_updated
 `
 }
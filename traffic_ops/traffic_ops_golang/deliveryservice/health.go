@@ -57,7 +57,7 @@ func GetHealth(w http.ResponseWriter, r *http.Request) {
 		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, nil, nil)
 	}
 
-	health, err := getHealth(inf.Tx.Tx, ds, cdn)
+	health, err := getHealth(inf.Tx.Tx, ds, cdn, inf.CorrelationID)
 	if err != nil {
 		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting delivery service health: "+err.Error()))
 		return
@@ -66,7 +66,7 @@ func GetHealth(w http.ResponseWriter, r *http.Request) {
 	api.WriteResp(w, r, health)
 }
 
-func getHealth(tx *sql.Tx, ds tc.DeliveryServiceName, cdn tc.CDNName) (tc.HealthData, error) {
+func getHealth(tx *sql.Tx, ds tc.DeliveryServiceName, cdn tc.CDNName, correlationID string) (tc.HealthData, error) {
 	monitors, err := monitorhlp.GetURLs(tx)
 	if err != nil {
 		return tc.HealthData{}, errors.New("getting monitors: " + err.Error())
@@ -75,11 +75,11 @@ func getHealth(tx *sql.Tx, ds tc.DeliveryServiceName, cdn tc.CDNName) (tc.Health
 	if !ok {
 		return tc.HealthData{}, nil // TODO emulates old Perl behavior; change to return error?
 	}
-	return getMonitorHealth(tx, ds, monitor)
+	return getMonitorHealth(tx, ds, monitor, correlationID)
 }
 
-func getMonitorHealth(tx *sql.Tx, ds tc.DeliveryServiceName, monitorFQDN string) (tc.HealthData, error) {
-	client, err := monitorhlp.GetClient(tx)
+func getMonitorHealth(tx *sql.Tx, ds tc.DeliveryServiceName, monitorFQDN string, correlationID string) (tc.HealthData, error) {
+	client, err := monitorhlp.GetClient(tx, correlationID)
 	if err != nil {
 		return tc.HealthData{}, errors.New("getting monitor client: " + err.Error())
 	}
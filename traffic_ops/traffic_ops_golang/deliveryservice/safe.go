@@ -65,6 +65,11 @@ func UpdateSafe(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if userErr, sysErr, errCode := checkPreconditions(r, inf, dsID); userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, tx, errCode, userErr, sysErr)
+		return
+	}
+
 	if ok, err := updateDSSafe(tx, dsID, dsr); err != nil {
 		api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, fmt.Errorf("Updating Delivery Service (safe): %s", err))
 		return
@@ -0,0 +1,254 @@
+package event
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// These are the headers sent with each delivery of an Event to a Webhook.
+const (
+	EventTypeHeader  = "X-Trafficops-Event"
+	EventIDHeader    = "X-Trafficops-Event-Id"
+	DeliveryIDHeader = "X-Trafficops-Delivery"
+	SignatureHeader  = "X-Trafficops-Signature"
+)
+
+// DeliveryTimeout is how long a Webhook has to respond to a delivery before it is considered failed.
+const DeliveryTimeout = 10 * time.Second
+
+// MinRetryDelay and MaxRetryDelay bound how long a failed delivery waits before it is retried. The
+// delay doubles with each failed attempt.
+const (
+	MinRetryDelay = 10 * time.Second
+	MaxRetryDelay = time.Hour
+)
+
+// deliveryLease is how long a delivery claimed by a dispatcher is hidden from other dispatchers. It
+// must be longer than DeliveryTimeout, so that a delivery is never sent twice at once; if the
+// dispatcher dies before recording the result, the delivery is retried once the lease expires.
+const deliveryLease = time.Minute
+
+// Deliveries are claimed and counted as attempted in their own short transaction, so that no locks
+// are held while they are sent. At most 100 are claimed at a time; any more that are due are
+// claimed on the next tick.
+const claimDeliveriesQuery = `
+UPDATE webhook_delivery
+SET attempts = webhook_delivery.attempts + 1,
+    next_attempt = now() + $1 * interval '1 second'
+FROM webhook, event
+WHERE webhook_delivery.webhook = webhook.id
+AND webhook_delivery.event = event.id
+AND webhook_delivery.id IN (
+	SELECT id
+	FROM webhook_delivery
+	WHERE delivered IS NULL
+	AND NOT failed
+	AND next_attempt <= now()
+	ORDER BY next_attempt
+	LIMIT 100
+	FOR UPDATE SKIP LOCKED
+)
+RETURNING webhook_delivery.id,
+          webhook_delivery.attempts,
+          webhook.url,
+          webhook.secret,
+          event.id,
+          event.event_type,
+          event.username,
+          event.data,
+          event.created
+`
+
+const deliveredQuery = `
+UPDATE webhook_delivery
+SET delivered = now(),
+    last_error = NULL
+WHERE id = $1
+`
+
+const deliveryFailedQuery = `
+UPDATE webhook_delivery
+SET last_error = $2,
+    next_attempt = now() + $3 * interval '1 second',
+    failed = attempts >= $4
+WHERE id = $1
+`
+
+// Events are kept until they are older than the retention period and all of their deliveries are
+// finished, one way or the other. Deleting an Event deletes its deliveries.
+const pruneEventsQuery = `
+DELETE FROM event
+WHERE created < now() - $1 * interval '1 second'
+AND NOT EXISTS (
+	SELECT 1
+	FROM webhook_delivery
+	WHERE webhook_delivery.event = event.id
+	AND webhook_delivery.delivered IS NULL
+	AND NOT webhook_delivery.failed
+)
+`
+
+type delivery struct {
+	id       int64
+	attempts int
+	url      string
+	secret   string
+	event    tc.Event
+}
+
+// RunPruner removes Events older than retention once a minute, except those still being delivered
+// to Webhooks. It never returns, and is meant to be run in its own goroutine, whether or not this
+// Traffic Ops runs the dispatcher.
+func RunPruner(db *sqlx.DB, retention time.Duration) {
+	for range time.Tick(time.Minute) {
+		if _, err := db.Exec(pruneEventsQuery, retention.Seconds()); err != nil {
+			log.Errorln("pruning events: " + err.Error())
+		}
+	}
+}
+
+// RunDispatcher delivers Events to the Webhooks subscribed to them, retrying failed deliveries
+// with exponential backoff until maxAttempts have been made. It never returns, and is meant to be
+// run in its own goroutine.
+//
+// Any number of Traffic Ops instances may run the dispatcher at once; each delivery is claimed by
+// only one of them at a time.
+func RunDispatcher(db *sqlx.DB, maxAttempts int) {
+	client := &http.Client{Timeout: DeliveryTimeout}
+	for range time.Tick(time.Second) {
+		deliveries, err := claimDeliveries(db)
+		if err != nil {
+			log.Errorln("claiming webhook deliveries: " + err.Error())
+			continue
+		}
+		wg := sync.WaitGroup{}
+		for _, d := range deliveries {
+			wg.Add(1)
+			go func(d delivery) {
+				defer wg.Done()
+				finishDelivery(db, d, deliver(client, d), maxAttempts)
+			}(d)
+		}
+		wg.Wait()
+	}
+}
+
+func claimDeliveries(db *sqlx.DB) ([]delivery, error) {
+	rows, err := db.Query(claimDeliveriesQuery, deliveryLease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []delivery{}
+	for rows.Next() {
+		d := delivery{}
+		data := []byte(nil)
+		created := time.Time{}
+		if err := rows.Scan(&d.id, &d.attempts, &d.url, &d.secret, &d.event.ID, &d.event.Type, &d.event.User, &data, &created); err != nil {
+			return nil, err
+		}
+		d.event.Data = json.RawMessage(data)
+		d.event.Time = tc.Time{Time: created, Valid: true}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+// deliver POSTs the delivery's Event to its Webhook, returning an error if the Webhook could not be
+// reached or did not respond with a 2xx status.
+func deliver(client *http.Client, d delivery) error {
+	body, err := json.Marshal(d.event)
+	if err != nil {
+		return errors.New("marshalling event: " + err.Error())
+	}
+	req, err := http.NewRequest(http.MethodPost, d.url, bytes.NewReader(body))
+	if err != nil {
+		return errors.New("creating request: " + err.Error())
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventTypeHeader, d.event.Type)
+	req.Header.Set(EventIDHeader, strconv.FormatUint(d.event.ID, 10))
+	req.Header.Set(DeliveryIDHeader, strconv.FormatInt(d.id, 10))
+	req.Header.Set(SignatureHeader, Sign(d.secret, body))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func finishDelivery(db *sqlx.DB, d delivery, err error, maxAttempts int) {
+	if err == nil {
+		if _, err := db.Exec(deliveredQuery, d.id); err != nil {
+			log.Errorf("recording webhook delivery #%d as delivered: %v\n", d.id, err)
+		}
+		return
+	}
+	log.Warnf("delivering event #%d to webhook %s (attempt %d of %d): %v\n", d.event.ID, d.url, d.attempts, maxAttempts, err)
+	if _, err := db.Exec(deliveryFailedQuery, d.id, err.Error(), RetryDelay(d.attempts).Seconds(), maxAttempts); err != nil {
+		log.Errorf("recording webhook delivery #%d as failed: %v\n", d.id, err)
+	}
+}
+
+// Sign returns the value of the SignatureHeader for a delivery of the given body to a Webhook with
+// the given secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// RetryDelay returns how long to wait before retrying a delivery which has failed the given number
+// of attempts.
+func RetryDelay(attempts int) time.Duration {
+	delay := MinRetryDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= MaxRetryDelay {
+			return MaxRetryDelay
+		}
+	}
+	return delay
+}
@@ -0,0 +1,168 @@
+package event
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+
+	"github.com/jmoiron/sqlx"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"id":1,"type":"cdn.snapshot"}`)
+	sig := Sign("secret", body)
+	if !strings.HasPrefix(sig, "sha256=") {
+		t.Fatalf("Sign expected: 'sha256=' prefix, actual: %s", sig)
+	}
+	mac, err := hex.DecodeString(strings.TrimPrefix(sig, "sha256="))
+	if err != nil {
+		t.Fatalf("Sign expected: hex-encoded MAC, actual: %s", sig)
+	}
+	expected := hmac.New(sha256.New, []byte("secret"))
+	expected.Write(body)
+	if !hmac.Equal(mac, expected.Sum(nil)) {
+		t.Errorf("Sign MAC does not match the HMAC-SHA256 of the body")
+	}
+	if Sign("other", body) == sig {
+		t.Errorf("Sign expected: different signatures for different secrets, actual: same")
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	expected := map[int]time.Duration{
+		1:  MinRetryDelay,
+		2:  2 * MinRetryDelay,
+		3:  4 * MinRetryDelay,
+		10: MaxRetryDelay,
+		50: MaxRetryDelay,
+	}
+	for attempts, delay := range expected {
+		if actual := RetryDelay(attempts); actual != delay {
+			t.Errorf("RetryDelay(%d) expected: %v, actual: %v", attempts, delay, actual)
+		}
+	}
+}
+
+func TestWriteEvent(t *testing.T) {
+	e := tc.Event{ID: 42, Type: tc.EventTypeSnapshotTaken, User: "admin", Data: json.RawMessage(`{"cdn":"foo"}`)}
+	buf := &bytes.Buffer{}
+	if err := writeEvent(buf, e); err != nil {
+		t.Fatalf("writeEvent unexpected error: %v", err)
+	}
+	lines := strings.Split(buf.String(), "\n")
+	if len(lines) != 5 || lines[0] != "id: 42" || lines[1] != "event: cdn.snapshot" || !strings.HasPrefix(lines[2], "data: ") || lines[3] != "" {
+		t.Fatalf("writeEvent expected: id, event, and data lines followed by a blank line, actual: %q", buf.String())
+	}
+	actual := tc.Event{}
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(lines[2], "data: ")), &actual); err != nil {
+		t.Fatalf("writeEvent data expected: JSON event, actual: %s", lines[2])
+	}
+	if actual.ID != e.ID || actual.Type != e.Type || string(actual.Data) != string(e.Data) {
+		t.Errorf("writeEvent data expected: %+v, actual: %+v", e, actual)
+	}
+
+	if !matchesAny(nil, tc.EventTypeSnapshotTaken) {
+		t.Errorf("matchesAny with no patterns expected: true, actual: false")
+	}
+	if matchesAny([]string{"ds.*", "server.*"}, tc.EventTypeSnapshotTaken) {
+		t.Errorf("matchesAny with non-matching patterns expected: false, actual: true")
+	}
+}
+
+func TestEventCursorGaps(t *testing.T) {
+	now := time.Now()
+	c := newEventCursor(5)
+
+	// 8 commits before 6 and 7
+	c.read([]tc.Event{{ID: 8}}, now)
+	if c.last != 8 {
+		t.Errorf("expected last 8, actual: %d", c.last)
+	}
+	if len(c.gaps) != 2 {
+		t.Fatalf("expected gaps 6 and 7, actual: %v", c.gapIDs())
+	}
+
+	// 7 turns up on a later poll, along with new events
+	c.read([]tc.Event{{ID: 7}, {ID: 9}}, now.Add(time.Second))
+	if c.last != 9 {
+		t.Errorf("expected last 9, actual: %d", c.last)
+	}
+	if ids := c.gapIDs(); len(ids) != 1 || ids[0] != 6 {
+		t.Errorf("expected gaps [6], actual: %v", ids)
+	}
+
+	// 6 never turns up
+	c.read(nil, now.Add(gapTimeout+time.Second))
+	if len(c.gaps) != 0 {
+		t.Errorf("expected gaps to be forgotten after %v, actual: %v", gapTimeout, c.gapIDs())
+	}
+}
+
+func TestSendMissedEventsPages(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+
+	first := make([]tc.Event, readEventsLimit)
+	for i := range first {
+		first[i] = tc.Event{ID: uint64(i + 1), Type: "cdn.snapshot"}
+	}
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "event_type", "username", "data", "created"})
+	rows.AddRow(readEventsLimit+1, "cdn.snapshot", "admin", []byte(`{}`), now)
+	rows.AddRow(readEventsLimit+2, "cdn.snapshot", "admin", []byte(`{}`), now)
+	mock.ExpectQuery("SELECT").WithArgs(readEventsLimit, sqlmock.AnyArg(), readEventsLimit).WillReturnRows(rows)
+
+	sent := []uint64{}
+	send := func(e tc.Event) error {
+		sent = append(sent, e.ID)
+		return nil
+	}
+	flushes := 0
+	ids, err := sendMissedEvents(db, first, send, func() { flushes++ })
+	if err != nil {
+		t.Fatalf("sendMissedEvents expected: no error, actual: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expected the page after a full page to be read, actual: %v", err)
+	}
+	if len(sent) != readEventsLimit+2 || sent[len(sent)-1] != readEventsLimit+2 {
+		t.Errorf("expected %d events sent ending with %d, actual: %d ending with %v", readEventsLimit+2, readEventsLimit+2, len(sent), sent[len(sent)-1])
+	}
+	if len(ids) != len(sent) {
+		t.Errorf("expected %d missed event IDs, actual: %d", len(sent), len(ids))
+	}
+	if flushes != 2 {
+		t.Errorf("expected a flush after each page, actual: %d flushes", flushes)
+	}
+}
@@ -0,0 +1,343 @@
+package event
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// KeepAliveInterval is how often a comment is sent to event stream clients when there are no
+// Events, so that idle connections are not closed by proxies.
+const KeepAliveInterval = 15 * time.Second
+
+// pollInterval is how often the Hub checks for new Events.
+const pollInterval = time.Second
+
+// subscriberBuffer is how many Events may be waiting to be sent to a stream client. Clients which
+// fall further behind than this are disconnected, and may reconnect with Last-Event-ID to resume.
+const subscriberBuffer = 100
+
+// gapTimeout is how long the Hub keeps looking for an Event it skipped over. Event IDs are assigned
+// when Events are inserted, but Events can only be read once their transactions commit, so a poll
+// can read an Event before one with a lower ID whose transaction is still open. The IDs skipped
+// over are read again on each poll until their Events turn up, or until this long has passed, after
+// which their transactions are assumed to have rolled back.
+const gapTimeout = 10 * time.Minute
+
+// maxGaps is the most skipped over IDs the Hub keeps looking for at once.
+const maxGaps = 1000
+
+// readEventsLimit is the most Events read at a time. The Hub reads any more on its next poll, and
+// the missed Events of a reconnecting client are read a page of this many at a time.
+const readEventsLimit = 1000
+
+const readEventsQuery = `
+SELECT id, event_type, username, data, created
+FROM event
+WHERE id > $1 OR id = ANY($2)
+ORDER BY id
+LIMIT $3
+`
+
+// Hub polls for new Events and broadcasts them to the event stream clients of this Traffic Ops.
+// Polling only starts once the first client subscribes.
+type Hub struct {
+	db          *sqlx.DB
+	start       sync.Once
+	m           sync.Mutex
+	subscribers map[chan tc.Event]struct{}
+}
+
+// NewHub returns a Hub which reads Events from db.
+func NewHub(db *sqlx.DB) *Hub {
+	return &Hub{db: db, subscribers: map[chan tc.Event]struct{}{}}
+}
+
+// subscribe returns a channel on which all Events created from now on are sent. The channel is
+// closed if the subscriber falls too far behind.
+func (h *Hub) subscribe() chan tc.Event {
+	h.start.Do(func() { go h.run() })
+	ch := make(chan tc.Event, subscriberBuffer)
+	h.m.Lock()
+	h.subscribers[ch] = struct{}{}
+	h.m.Unlock()
+	return ch
+}
+
+func (h *Hub) unsubscribe(ch chan tc.Event) {
+	h.m.Lock()
+	defer h.m.Unlock()
+	if _, ok := h.subscribers[ch]; ok {
+		delete(h.subscribers, ch)
+		close(ch)
+	}
+}
+
+func (h *Hub) broadcast(e tc.Event) {
+	h.m.Lock()
+	defer h.m.Unlock()
+	for ch := range h.subscribers {
+		select {
+		case ch <- e:
+		default:
+			delete(h.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+func (h *Hub) run() {
+	c := newEventCursor(0)
+	if err := h.db.QueryRow(`SELECT COALESCE(MAX(id), 0) FROM event`).Scan(&c.last); err != nil {
+		log.Errorln("event stream getting latest event: " + err.Error())
+	}
+	for range time.Tick(pollInterval) {
+		events, err := readEvents(h.db, c.last, c.gapIDs())
+		if err != nil {
+			log.Errorln("event stream reading events: " + err.Error())
+			continue
+		}
+		c.read(events, time.Now())
+		for _, e := range events {
+			h.broadcast(e)
+		}
+	}
+}
+
+// eventCursor is the position of the Hub in the Events: the highest ID it has read, and the lower
+// IDs it skipped over, which it is still looking for, with when it skipped them.
+type eventCursor struct {
+	last uint64
+	gaps map[uint64]time.Time
+}
+
+func newEventCursor(last uint64) *eventCursor {
+	return &eventCursor{last: last, gaps: map[uint64]time.Time{}}
+}
+
+// gapIDs returns the skipped over IDs to read again.
+func (c *eventCursor) gapIDs() []int64 {
+	ids := make([]int64, 0, len(c.gaps))
+	for id := range c.gaps {
+		ids = append(ids, int64(id))
+	}
+	return ids
+}
+
+// read moves the cursor past the events, which were read at now in order of ID, and forgets the
+// IDs skipped over more than gapTimeout ago.
+func (c *eventCursor) read(events []tc.Event, now time.Time) {
+	for _, e := range events {
+		if _, ok := c.gaps[e.ID]; ok {
+			delete(c.gaps, e.ID)
+			continue
+		}
+		for id := c.last + 1; id < e.ID && len(c.gaps) < maxGaps; id++ {
+			c.gaps[id] = now
+		}
+		if e.ID > c.last {
+			c.last = e.ID
+		}
+	}
+	for id, skipped := range c.gaps {
+		if now.Sub(skipped) > gapTimeout {
+			delete(c.gaps, id)
+		}
+	}
+}
+
+// readEvents returns the Events after the one with the ID after, and those with the IDs in gaps,
+// in order, up to readEventsLimit of them.
+func readEvents(db *sqlx.DB, after uint64, gaps []int64) ([]tc.Event, error) {
+	rows, err := db.Query(readEventsQuery, after, pq.Array(gaps), readEventsLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []tc.Event{}
+	for rows.Next() {
+		e := tc.Event{}
+		data := []byte(nil)
+		created := time.Time{}
+		if err := rows.Scan(&e.ID, &e.Type, &e.User, &data, &created); err != nil {
+			return nil, err
+		}
+		e.Data = json.RawMessage(data)
+		e.Time = tc.Time{Time: created, Valid: true}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// StreamHandler returns the handler for GET requests to /events/stream, which sends Events as
+// Server-Sent Events as they are created, for as long as the client stays connected.
+//
+// The optional "types" query parameter is a comma-delimited list of Event type patterns, as
+// understood by tc.EventTypeMatches; only matching Events are sent. A client which sends the
+// Last-Event-ID header is first sent the Events it missed which have not yet been pruned.
+func StreamHandler(hub *Hub) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			api.HandleErr(w, r, nil, http.StatusInternalServerError, nil, errors.New("event stream: response writer does not support flushing"))
+			return
+		}
+
+		patterns := []string{}
+		if types := r.URL.Query().Get("types"); types != "" {
+			for _, t := range strings.Split(types, ",") {
+				if t = strings.TrimSpace(t); t != "" {
+					patterns = append(patterns, t)
+				}
+			}
+		}
+		last := uint64(0)
+		if id := r.Header.Get("Last-Event-ID"); id != "" {
+			i, err := strconv.ParseUint(id, 10, 64)
+			if err != nil {
+				api.HandleErr(w, r, nil, http.StatusBadRequest, errors.New("Last-Event-ID must be an event id"), nil)
+				return
+			}
+			last = i
+		}
+
+		// Subscribe before reading the missed Events, so none are lost in between; any read twice
+		// are skipped by ID.
+		ch := hub.subscribe()
+		defer hub.unsubscribe(ch)
+
+		missed := []tc.Event{}
+		if last > 0 {
+			events, err := readEvents(hub.db, last, nil)
+			if err != nil {
+				api.HandleErr(w, r, nil, http.StatusInternalServerError, nil, errors.New("event stream reading missed events: "+err.Error()))
+				return
+			}
+			missed = events
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+
+		send := func(e tc.Event) error {
+			if !matchesAny(patterns, e.Type) {
+				return nil
+			}
+			return writeEvent(w, e)
+		}
+
+		// The Hub sends each Event once, but may send one with a lower ID than those it already
+		// sent, if its transaction committed late, so only those also read as missed are skipped.
+		missedIDs, err := sendMissedEvents(hub.db, missed, send, flusher.Flush)
+		if err != nil {
+			log.Errorf("event stream client %s: %v\n", r.RemoteAddr, err)
+			return
+		}
+
+		keepAlive := time.NewTicker(KeepAliveInterval)
+		defer keepAlive.Stop()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case e, ok := <-ch:
+				if !ok {
+					log.Infof("event stream client %s fell behind, disconnecting\n", r.RemoteAddr)
+					return
+				}
+				if _, ok := missedIDs[e.ID]; ok {
+					continue
+				}
+				if err := send(e); err != nil {
+					return
+				}
+			case <-keepAlive.C:
+				if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil {
+					return
+				}
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// sendMissedEvents sends the missed Events, the first page read for a reconnecting client, and
+// then reads and sends the pages after it until one isn't full, flushing after each page. It
+// returns the IDs of all the Events read.
+func sendMissedEvents(db *sqlx.DB, missed []tc.Event, send func(tc.Event) error, flush func()) (map[uint64]struct{}, error) {
+	ids := map[uint64]struct{}{}
+	for {
+		for _, e := range missed {
+			ids[e.ID] = struct{}{}
+			if err := send(e); err != nil {
+				return nil, errors.New("sending missed event: " + err.Error())
+			}
+		}
+		flush()
+		if len(missed) < readEventsLimit {
+			return ids, nil
+		}
+		events, err := readEvents(db, missed[len(missed)-1].ID, nil)
+		if err != nil {
+			return nil, errors.New("reading missed events: " + err.Error())
+		}
+		missed = events
+	}
+}
+
+func matchesAny(patterns []string, t string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if tc.EventTypeMatches(p, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeEvent writes e in the Server-Sent Events format, with its ID, its type as the event name,
+// and the entire Event as the data.
+func writeEvent(w io.Writer, e tc.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return errors.New("marshalling event: " + err.Error())
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+	return err
+}
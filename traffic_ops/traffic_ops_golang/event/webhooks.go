@@ -0,0 +1,214 @@
+package event
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+
+	"github.com/lib/pq"
+)
+
+// readWebhooksQuery never selects the secret, which is never returned by Traffic Ops.
+const readWebhooksQuery = `
+SELECT id,
+       url,
+       event_types,
+       active,
+       last_updated
+FROM webhook
+`
+
+const insertWebhookQuery = `
+INSERT INTO webhook (url, secret, event_types, active)
+VALUES ($1, $2, $3, $4)
+RETURNING id, last_updated
+`
+
+// updateWebhookQuery keeps the existing secret if $2 is NULL.
+const updateWebhookQuery = `
+UPDATE webhook SET
+	url=$1,
+	secret=COALESCE($2, secret),
+	event_types=$3,
+	active=$4
+WHERE id=$5
+RETURNING last_updated
+`
+
+const deleteWebhookQuery = `
+DELETE FROM webhook
+WHERE id=$1
+RETURNING url
+`
+
+// GetWebhooks is the handler for GET requests to /webhooks.
+func GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, nil, nil)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	queryParamsToSQLCols := map[string]dbhelpers.WhereColumnInfo{
+		"id":  dbhelpers.WhereColumnInfo{"id", api.IsInt},
+		"url": dbhelpers.WhereColumnInfo{"url", nil},
+	}
+	where, orderBy, pagination, queryValues, errs := dbhelpers.BuildWhereAndOrderByAndPagination(inf.Params, queryParamsToSQLCols)
+	if len(errs) > 0 {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, util.JoinErrs(errs), nil)
+		return
+	}
+
+	rows, err := inf.Tx.NamedQuery(readWebhooksQuery+where+orderBy+pagination, queryValues)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("querying webhooks: %v", err))
+		return
+	}
+	defer rows.Close()
+
+	webhooks := []tc.Webhook{}
+	for rows.Next() {
+		wh := tc.Webhook{}
+		if err := rows.Scan(&wh.ID, &wh.URL, pq.Array(&wh.EventTypes), &wh.Active, &wh.LastUpdated); err != nil {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("scanning webhooks: %v", err))
+			return
+		}
+		webhooks = append(webhooks, wh)
+	}
+	if err := rows.Err(); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("scanning webhooks: %v", err))
+		return
+	}
+	api.WriteResp(w, r, webhooks)
+}
+
+// CreateWebhook is the handler for POST requests to /webhooks.
+func CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, nil, nil)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	wh := tc.Webhook{}
+	if err := json.NewDecoder(r.Body).Decode(&wh); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, errors.New("malformed JSON: "+err.Error()), nil)
+		return
+	}
+	if err := wh.Validate(); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, err, nil)
+		return
+	}
+	if wh.Secret == nil || *wh.Secret == "" {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, errors.New("secret: cannot be blank."), nil)
+		return
+	}
+	if wh.Active == nil {
+		wh.Active = util.BoolPtr(true)
+	}
+
+	if err := inf.Tx.Tx.QueryRow(insertWebhookQuery, *wh.URL, *wh.Secret, pq.Array(wh.EventTypes), *wh.Active).Scan(&wh.ID, &wh.LastUpdated); err != nil {
+		userErr, sysErr, errCode = api.ParseDBError(err)
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	wh.Secret = nil
+
+	api.CreateChangeLogRawTx(api.ApiChange, api.Created+" webhook: #"+strconv.Itoa(*wh.ID)+" to "+*wh.URL, inf.User, inf.Tx.Tx)
+	api.WriteRespAlertObj(w, r, tc.SuccessLevel, "Webhook was created.", wh)
+}
+
+// UpdateWebhook is the handler for PUT requests to /webhooks/{id}.
+func UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"id"}, []string{"id"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	wh := tc.Webhook{}
+	if err := json.NewDecoder(r.Body).Decode(&wh); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, errors.New("malformed JSON: "+err.Error()), nil)
+		return
+	}
+	if err := wh.Validate(); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, err, nil)
+		return
+	}
+	if wh.Secret != nil && *wh.Secret == "" {
+		wh.Secret = nil
+	}
+	if wh.Active == nil {
+		wh.Active = util.BoolPtr(true)
+	}
+	id := inf.IntParams["id"]
+	wh.ID = &id
+
+	if err := inf.Tx.Tx.QueryRow(updateWebhookQuery, *wh.URL, wh.Secret, pq.Array(wh.EventTypes), *wh.Active, id).Scan(&wh.LastUpdated); err != nil {
+		if err == sql.ErrNoRows {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("no webhook with id "+inf.Params["id"]), nil)
+			return
+		}
+		userErr, sysErr, errCode = api.ParseDBError(err)
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	wh.Secret = nil
+
+	api.CreateChangeLogRawTx(api.ApiChange, api.Updated+" webhook: #"+inf.Params["id"]+" to "+*wh.URL, inf.User, inf.Tx.Tx)
+	api.WriteRespAlertObj(w, r, tc.SuccessLevel, "Webhook was updated.", wh)
+}
+
+// DeleteWebhook is the handler for DELETE requests to /webhooks/{id}. Any of its pending deliveries
+// are abandoned.
+func DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"id"}, []string{"id"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	url := ""
+	if err := inf.Tx.Tx.QueryRow(deleteWebhookQuery, inf.IntParams["id"]).Scan(&url); err != nil {
+		if err == sql.ErrNoRows {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("no webhook with id "+inf.Params["id"]), nil)
+			return
+		}
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("deleting webhook: %v", err))
+		return
+	}
+
+	api.CreateChangeLogRawTx(api.ApiChange, api.Deleted+" webhook: #"+inf.Params["id"]+" to "+url, inf.User, inf.Tx.Tx)
+	api.WriteRespAlert(w, r, tc.SuccessLevel, "Webhook was deleted.")
+}
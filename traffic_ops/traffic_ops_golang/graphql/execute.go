@@ -0,0 +1,991 @@
+package graphql
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+)
+
+// InternalErrorMessage is the message of the error of a field whose resolver failed with an error
+// other than a *UserError. The error itself is logged, rather than returned to the client.
+const InternalErrorMessage = "internal error resolving field"
+
+// Request is a GraphQL request, as POSTed by clients.
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Response is the result of executing a query.
+type Response struct {
+	Data   interface{} `json:"data"`
+	Errors []*Error    `json:"errors,omitempty"`
+}
+
+// Error is an error in a query or executing it, as it's reported in the response.
+type Error struct {
+	Message   string        `json:"message"`
+	Locations []location    `json:"locations,omitempty"`
+	Path      []interface{} `json:"path,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// UserError is an error resolving a field which is caused by the request, such as an invalid
+// argument or an object the user isn't authorized to read, and whose message the client is given.
+type UserError struct {
+	Message string
+}
+
+func (e *UserError) Error() string {
+	return e.Message
+}
+
+// RequestError is the errors in a request which make it impossible to execute: syntax errors,
+// invalid queries and variables, and queries which exceed the Limits.
+type RequestError struct {
+	Errors []*Error
+}
+
+func (e *RequestError) Error() string {
+	msgs := []string{}
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Message)
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Limits protects a schema from expensive queries. Limits which are 0 or less are unlimited.
+type Limits struct {
+	// MaxDepth is the maximum depth of the fields of a query, the fields of the query type being
+	// depth 1.
+	MaxDepth int
+	// MaxCost is the maximum cost of a query: the number of fields it selects, where the fields of
+	// the values of lists are counted once for each value it's estimated to have (see
+	// Field.ListSize).
+	MaxCost int
+}
+
+// Execute executes the query of the request. It returns a *RequestError if the request is invalid
+// or exceeds the limits, or the error returned by the schema's Authorize; otherwise, it returns the
+// response, including any errors resolving its fields.
+func (s *Schema) Execute(ctx context.Context, req Request, limits Limits) (*Response, error) {
+	doc, err := parse(req.Query)
+	if err != nil {
+		return nil, requestError(err)
+	}
+	op, err := doc.operation(req.OperationName)
+	if err != nil {
+		return nil, requestError(err)
+	}
+
+	v := &validator{schema: s, doc: doc, op: op, objects: map[*Object]bool{}, fragments: map[string]bool{}, usedVars: map[string]bool{}}
+	v.validate()
+	if len(v.errs) > 0 {
+		return nil, &RequestError{Errors: v.errs}
+	}
+	vars, errs := v.coerceVariables(req.Variables)
+	if len(errs) > 0 {
+		return nil, &RequestError{Errors: errs}
+	}
+
+	e := &executor{schema: s, doc: doc, vars: vars, ctx: ctx}
+	depth, cost := e.measure(s.Query, op.selections, 1, limits)
+	if limits.MaxDepth > 0 && depth > limits.MaxDepth {
+		return nil, requestError(fmt.Errorf("query exceeds the maximum depth of %d", limits.MaxDepth))
+	}
+	if limits.MaxCost > 0 && cost > limits.MaxCost {
+		return nil, requestError(fmt.Errorf("query exceeds the maximum cost of %d", limits.MaxCost))
+	}
+
+	if s.Authorize != nil {
+		for _, t := range v.objectList {
+			if err := s.Authorize(ctx, t); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	data, ok := e.executeSelections(s.Query, nil, op.selections, nil)
+	resp := &Response{Errors: e.errs}
+	if ok {
+		resp.Data = data
+	}
+	return resp, nil
+}
+
+func requestError(err error) *RequestError {
+	gqlErr := &Error{Message: err.Error()}
+	if se, ok := err.(*syntaxError); ok {
+		gqlErr.Locations = []location{se.loc}
+	}
+	return &RequestError{Errors: []*Error{gqlErr}}
+}
+
+// operation returns the operation of the document with the given name, or its only operation if
+// name is empty.
+func (doc *document) operation(name string) (*operation, error) {
+	if name == "" {
+		if len(doc.operations) > 1 {
+			return nil, fmt.Errorf("operationName is required to choose between %d operations", len(doc.operations))
+		}
+		return doc.operations[0], nil
+	}
+	for _, op := range doc.operations {
+		if op.name == name {
+			return op, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown operation '%s'", name)
+}
+
+// validator validates an operation against a schema, and collects the object types it selects.
+type validator struct {
+	schema *Schema
+	doc    *document
+	op     *operation
+	errs   []*Error
+	// objects is the object types the operation selects, and objectList those types in the order
+	// they're first selected.
+	objects    map[*Object]bool
+	objectList []*Object
+	// fragments is the fragments which have been validated.
+	fragments map[string]bool
+	usedVars  map[string]bool
+}
+
+func (v *validator) errorf(loc location, format string, args ...interface{}) {
+	v.errs = append(v.errs, &Error{Message: fmt.Sprintf(format, args...), Locations: []location{loc}})
+}
+
+func (v *validator) validate() {
+	names := map[string]bool{}
+	for _, op := range v.doc.operations {
+		if op.name == "" && len(v.doc.operations) > 1 {
+			v.errorf(op.loc, "an anonymous operation must be the only operation in a document")
+		}
+		if op.name != "" && names[op.name] {
+			v.errorf(op.loc, "there can only be one operation named '%s'", op.name)
+		}
+		names[op.name] = true
+	}
+	if v.op.typ != "query" {
+		v.errorf(v.op.loc, "%s operations are not supported", v.op.typ)
+		return
+	}
+	v.directives(v.op.directives, false)
+
+	vars := map[string]bool{}
+	for _, def := range v.op.variables {
+		if vars[def.name] {
+			v.errorf(def.loc, "there can only be one variable named '$%s'", def.name)
+		}
+		vars[def.name] = true
+		t := v.inputType(def.typ)
+		if t == nil {
+			v.errorf(def.loc, "variable '$%s' has unknown type '%s'", def.name, def.typ)
+			continue
+		}
+		if def.defaultValue != nil {
+			if _, ok := valueFromAST(def.defaultValue, t, nil); !ok {
+				v.errorf(def.loc, "variable '$%s' has an invalid default value", def.name)
+			}
+		}
+	}
+
+	v.selections(v.schema.Query, v.op.selections, nil)
+
+	for _, def := range v.op.variables {
+		if !v.usedVars[def.name] {
+			v.errorf(def.loc, "variable '$%s' is never used", def.name)
+		}
+	}
+	for name, f := range v.doc.fragments {
+		if !v.fragments[name] {
+			v.errorf(f.loc, "fragment '%s' is never used", name)
+		}
+	}
+}
+
+// inputType returns the type of a variable definition, or nil if it's not a type of the schema's
+// arguments.
+func (v *validator) inputType(ref *typeRef) Type {
+	t := Type(nil)
+	if ref.elem != nil {
+		if elem := v.inputType(ref.elem); elem != nil {
+			t = &List{Of: elem}
+		}
+	} else {
+		t = v.schema.scalar(ref.name)
+	}
+	if t != nil && ref.nonNull {
+		t = &NonNull{Of: t}
+	}
+	return t
+}
+
+// scalar returns the scalar type with the given name, if any argument of the schema or a built-in
+// scalar has it.
+func (s *Schema) scalar(name string) *Scalar {
+	for _, t := range []*Scalar{Int, Float, String, Boolean, ID} {
+		if t.Name == name {
+			return t
+		}
+	}
+	seen := map[*Object]bool{}
+	var find func(o *Object) *Scalar
+	find = func(o *Object) *Scalar {
+		if seen[o] {
+			return nil
+		}
+		seen[o] = true
+		for _, f := range o.Fields {
+			for _, a := range f.Args {
+				if t, ok := namedType(a.Type).(*Scalar); ok && t.Name == name {
+					return t
+				}
+			}
+			if ft, ok := namedType(f.Type).(*Object); ok {
+				if t := find(ft); t != nil {
+					return t
+				}
+			}
+		}
+		return nil
+	}
+	return find(s.Query)
+}
+
+// selections validates a selection set of the object type. fragmentPath is the fragments being
+// validated, to detect cycles.
+func (v *validator) selections(t *Object, sels []selection, fragmentPath []string) {
+	if !v.objects[t] {
+		v.objects[t] = true
+		v.objectList = append(v.objectList, t)
+	}
+	v.checkMerge(sels, map[string]*field{}, map[string]bool{})
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *field:
+			v.field(t, sel, fragmentPath)
+		case *inlineFragment:
+			v.directives(sel.directives, true)
+			if sel.typeCondition != "" && sel.typeCondition != t.Name {
+				v.errorf(sel.loc, "fragment on '%s' can never be spread on type '%s'", sel.typeCondition, t.Name)
+				continue
+			}
+			v.selections(t, sel.selections, fragmentPath)
+		case *fragmentSpread:
+			v.directives(sel.directives, true)
+			f, ok := v.doc.fragments[sel.name]
+			if !ok {
+				v.errorf(sel.loc, "unknown fragment '%s'", sel.name)
+				continue
+			}
+			for _, name := range fragmentPath {
+				if name == sel.name {
+					v.errorf(sel.loc, "fragment '%s' spreads itself", sel.name)
+					return
+				}
+			}
+			if f.typeCondition != t.Name {
+				v.errorf(sel.loc, "fragment '%s' on '%s' can never be spread on type '%s'", sel.name, f.typeCondition, t.Name)
+				continue
+			}
+			if v.fragments[sel.name] {
+				continue
+			}
+			v.fragments[sel.name] = true
+			v.directives(f.directives, false)
+			v.selections(t, f.selections, append(fragmentPath, sel.name))
+		}
+	}
+}
+
+// checkMerge reports fields of the selection set with the same response key which can't be merged,
+// because they're different fields or have different arguments.
+func (v *validator) checkMerge(sels []selection, keys map[string]*field, spread map[string]bool) {
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *field:
+			other, ok := keys[sel.responseKey()]
+			if !ok {
+				keys[sel.responseKey()] = sel
+				continue
+			}
+			if other.name != sel.name || argsString(other.args) != argsString(sel.args) {
+				v.errorf(sel.loc, "fields '%s' conflict because they are different fields or have different arguments", sel.responseKey())
+			}
+		case *inlineFragment:
+			v.checkMerge(sel.selections, keys, spread)
+		case *fragmentSpread:
+			if f, ok := v.doc.fragments[sel.name]; ok && !spread[sel.name] {
+				spread[sel.name] = true
+				v.checkMerge(f.selections, keys, spread)
+			}
+		}
+	}
+}
+
+func argsString(args []*argument) string {
+	parts := []string{}
+	for _, arg := range args {
+		parts = append(parts, arg.name+":"+fmt.Sprintf("%#v", arg.val))
+	}
+	return strings.Join(parts, ",")
+}
+
+func (v *validator) field(t *Object, f *field, fragmentPath []string) {
+	v.directives(f.directives, true)
+	if f.name == "__typename" {
+		if len(f.args) > 0 || f.selections != nil {
+			v.errorf(f.loc, "field '__typename' takes no arguments or selections")
+		}
+		return
+	}
+	def := t.Field(f.name)
+	if def == nil {
+		v.errorf(f.loc, "cannot query field '%s' on type '%s'", f.name, t.Name)
+		return
+	}
+	v.arguments(def.Args, f.args, f.loc, "field '"+f.name+"'")
+
+	obj, isObject := namedType(def.Type).(*Object)
+	switch {
+	case isObject && f.selections == nil:
+		v.errorf(f.loc, "field '%s' of type '%s' must have a selection of subfields", f.name, def.Type)
+	case !isObject && f.selections != nil:
+		v.errorf(f.loc, "field '%s' of type '%s' must not have a selection of subfields", f.name, def.Type)
+	case isObject:
+		v.selections(obj, f.selections, fragmentPath)
+	}
+}
+
+func (v *validator) arguments(defs []*Argument, args []*argument, loc location, of string) {
+	given := map[string]bool{}
+	for _, arg := range args {
+		if given[arg.name] {
+			v.errorf(arg.loc, "there can only be one argument named '%s'", arg.name)
+			continue
+		}
+		given[arg.name] = true
+		def := (*Argument)(nil)
+		for _, d := range defs {
+			if d.Name == arg.name {
+				def = d
+			}
+		}
+		if def == nil {
+			v.errorf(arg.loc, "unknown argument '%s' of %s", arg.name, of)
+			continue
+		}
+		v.argumentValue(arg.val, def.Type, arg.loc, arg.name)
+	}
+	for _, def := range defs {
+		if _, nonNull := def.Type.(*NonNull); nonNull && def.DefaultValue == nil && !given[def.Name] {
+			v.errorf(loc, "argument '%s' of type '%s' is required by %s", def.Name, def.Type, of)
+		}
+	}
+}
+
+// argumentValue validates the value of an argument of the given type, which may be or contain
+// variables.
+func (v *validator) argumentValue(val value, t Type, loc location, name string) {
+	switch val := val.(type) {
+	case variable:
+		v.usedVars[string(val)] = true
+		for _, def := range v.op.variables {
+			if def.name != string(val) {
+				continue
+			}
+			varType := v.inputType(def.typ)
+			if varType != nil && !assignable(varType, def.defaultValue != nil, t) {
+				v.errorf(loc, "variable '$%s' of type '%s' can't be used for argument '%s' of type '%s'", def.name, def.typ, name, t)
+			}
+			return
+		}
+		v.errorf(loc, "variable '$%s' is not defined", val)
+		return
+	case listValue:
+		inner := t
+		if nn, ok := t.(*NonNull); ok {
+			inner = nn.Of
+		}
+		if list, ok := inner.(*List); ok {
+			for _, item := range val {
+				v.argumentValue(item, list.Of, loc, name)
+			}
+			return
+		}
+	}
+	if !containsVariable(val) {
+		if _, ok := valueFromAST(val, t, nil); !ok {
+			v.errorf(loc, "argument '%s' has an invalid value for type '%s'", name, t)
+		}
+	}
+}
+
+func containsVariable(val value) bool {
+	switch val := val.(type) {
+	case variable:
+		return true
+	case listValue:
+		for _, item := range val {
+			if containsVariable(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// assignable returns whether a variable of type varType can be used where a value of type t is
+// expected.
+func assignable(varType Type, hasDefault bool, t Type) bool {
+	if nn, ok := t.(*NonNull); ok {
+		varNN, ok := varType.(*NonNull)
+		if !ok {
+			return hasDefault && assignable(varType, false, nn.Of)
+		}
+		return assignable(varNN.Of, false, nn.Of)
+	}
+	if varNN, ok := varType.(*NonNull); ok {
+		return assignable(varNN.Of, false, t)
+	}
+	if list, ok := t.(*List); ok {
+		varList, ok := varType.(*List)
+		return ok && assignable(varList.Of, false, list.Of)
+	}
+	if _, ok := varType.(*List); ok {
+		return false
+	}
+	return varType.String() == t.String()
+}
+
+// directives validates the directives of an operation, field or fragment; only @skip and @include
+// are supported, and only on fields and fragment spreads.
+func (v *validator) directives(directives []*directive, allowed bool) {
+	for _, d := range directives {
+		if d.name != "skip" && d.name != "include" {
+			v.errorf(d.loc, "unknown directive '@%s'", d.name)
+			continue
+		}
+		if !allowed {
+			v.errorf(d.loc, "directive '@%s' may not be used here", d.name)
+			continue
+		}
+		v.arguments(ifArgs, d.args, d.loc, "directive '@"+d.name+"'")
+	}
+}
+
+// ifArgs is the arguments of the @skip and @include directives.
+var ifArgs = []*Argument{{Name: "if", Type: &NonNull{Of: Boolean}}}
+
+// coerceVariables returns the values of the operation's variables, given the values in the request.
+func (v *validator) coerceVariables(provided map[string]interface{}) (map[string]interface{}, []*Error) {
+	vars := map[string]interface{}{}
+	errs := []*Error{}
+	for _, def := range v.op.variables {
+		t := v.inputType(def.typ)
+		val, ok := provided[def.name]
+		if !ok {
+			if def.defaultValue != nil {
+				vars[def.name], _ = valueFromAST(def.defaultValue, t, nil)
+				continue
+			}
+			if _, nonNull := t.(*NonNull); nonNull {
+				errs = append(errs, &Error{Message: fmt.Sprintf("variable '$%s' of required type '%s' was not provided", def.name, def.typ), Locations: []location{def.loc}})
+			}
+			continue
+		}
+		coerced, ok := coerceInput(val, t)
+		if !ok {
+			errs = append(errs, &Error{Message: fmt.Sprintf("variable '$%s' has an invalid value for type '%s'", def.name, def.typ), Locations: []location{def.loc}})
+			continue
+		}
+		vars[def.name] = coerced
+	}
+	return vars, errs
+}
+
+// coerceInput returns the value of the given type of a JSON value.
+func coerceInput(val interface{}, t Type) (interface{}, bool) {
+	if nn, ok := t.(*NonNull); ok {
+		if val == nil {
+			return nil, false
+		}
+		return coerceInput(val, nn.Of)
+	}
+	if val == nil {
+		return nil, true
+	}
+	switch t := t.(type) {
+	case *List:
+		items, ok := val.([]interface{})
+		if !ok {
+			item, ok := coerceInput(val, t.Of)
+			return []interface{}{item}, ok
+		}
+		list := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			coerced, ok := coerceInput(item, t.Of)
+			if !ok {
+				return nil, false
+			}
+			list = append(list, coerced)
+		}
+		return list, true
+	case *Scalar:
+		return t.Parse(val)
+	}
+	return nil, false
+}
+
+// valueFromAST returns the value of the given type of a literal, or of a variable in vars. A
+// variable which isn't in vars is null.
+func valueFromAST(val value, t Type, vars map[string]interface{}) (interface{}, bool) {
+	if name, ok := val.(variable); ok {
+		v := vars[string(name)]
+		if _, nonNull := t.(*NonNull); nonNull && v == nil {
+			return nil, false
+		}
+		return v, true
+	}
+	if nn, ok := t.(*NonNull); ok {
+		if _, isNull := val.(nullValue); isNull {
+			return nil, false
+		}
+		return valueFromAST(val, nn.Of, vars)
+	}
+	if _, isNull := val.(nullValue); isNull {
+		return nil, true
+	}
+	switch t := t.(type) {
+	case *List:
+		items, ok := val.(listValue)
+		if !ok {
+			item, ok := valueFromAST(val, t.Of, vars)
+			return []interface{}{item}, ok
+		}
+		list := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			v, ok := valueFromAST(item, t.Of, vars)
+			if !ok {
+				return nil, false
+			}
+			list = append(list, v)
+		}
+		return list, true
+	case *Scalar:
+		switch val := val.(type) {
+		case intValue:
+			i, err := strconv.Atoi(string(val))
+			if err != nil {
+				return nil, false
+			}
+			return t.Parse(i)
+		case floatValue:
+			f, err := strconv.ParseFloat(string(val), 64)
+			if err != nil || t == Int {
+				return nil, false
+			}
+			return t.Parse(f)
+		case stringValue:
+			return t.Parse(string(val))
+		case boolValue:
+			return t.Parse(bool(val))
+		}
+	}
+	return nil, false
+}
+
+// executor executes an operation.
+type executor struct {
+	schema *Schema
+	doc    *document
+	vars   map[string]interface{}
+	ctx    context.Context
+	errs   []*Error
+	// measured is the number of fields measured.
+	measured int
+}
+
+// fieldGroup is the fields of a selection set with the same response key, which are executed as
+// one.
+type fieldGroup struct {
+	key    string
+	fields []*field
+}
+
+// collectFields returns the fields of the selection set which aren't skipped, grouped by their
+// response keys in the order they're first selected.
+func (e *executor) collectFields(sels []selection, groups []*fieldGroup, visited map[string]bool) []*fieldGroup {
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *field:
+			if !e.included(sel.directives) {
+				continue
+			}
+			found := false
+			for _, g := range groups {
+				if g.key == sel.responseKey() {
+					g.fields = append(g.fields, sel)
+					found = true
+					break
+				}
+			}
+			if !found {
+				groups = append(groups, &fieldGroup{key: sel.responseKey(), fields: []*field{sel}})
+			}
+		case *inlineFragment:
+			if e.included(sel.directives) {
+				groups = e.collectFields(sel.selections, groups, visited)
+			}
+		case *fragmentSpread:
+			if visited[sel.name] || !e.included(sel.directives) {
+				continue
+			}
+			visited[sel.name] = true
+			groups = e.collectFields(e.doc.fragments[sel.name].selections, groups, visited)
+		}
+	}
+	return groups
+}
+
+// included returns whether a field or fragment with the directives is included, by @skip or
+// @include.
+func (e *executor) included(directives []*directive) bool {
+	for _, d := range directives {
+		cond := false
+		for _, arg := range d.args {
+			v, _ := valueFromAST(arg.val, Boolean, e.vars)
+			cond, _ = v.(bool)
+		}
+		if d.name == "skip" && cond || d.name == "include" && !cond {
+			return false
+		}
+	}
+	return true
+}
+
+// measure returns the depth and cost of the selection set of the object type, whose fields are at
+// the given level; see Limits. Fragments can make the depth and cost of a query exponential in its
+// size, so it stops measuring once either exceeds the limits.
+func (e *executor) measure(t *Object, sels []selection, level int, limits Limits) (int, int) {
+	maxDepth, cost := 0, 0
+	for _, g := range e.collectFields(sels, nil, map[string]bool{}) {
+		// every field costs at least 1, so the number measured is never more than the cost
+		e.measured++
+		if limits.MaxCost > 0 && e.measured > limits.MaxCost {
+			return maxDepth, math.MaxInt32
+		}
+		f := g.fields[0]
+		def := t.Field(f.name)
+		depth, fieldCost := 1, 1
+		if obj, ok := namedType(typeOf(def)).(*Object); ok {
+			if limits.MaxDepth > 0 && level >= limits.MaxDepth {
+				return limits.MaxDepth - level + 2, cost
+			}
+			subSels := []selection{}
+			for _, f := range g.fields {
+				subSels = append(subSels, f.selections...)
+			}
+			subDepth, subCost := e.measure(obj, subSels, level+1, limits)
+			depth += subDepth
+			fieldCost = saturatingAdd(1, saturatingMul(e.listSize(def, f), subCost))
+		}
+		if depth > maxDepth {
+			maxDepth = depth
+		}
+		cost = saturatingAdd(cost, fieldCost)
+	}
+	return maxDepth, cost
+}
+
+func typeOf(def *Field) Type {
+	if def == nil {
+		return String // __typename
+	}
+	return def.Type
+}
+
+// listSize returns the number of values the field is estimated to have: its "limit" argument, if it
+// has one and it's given, or its ListSize.
+func (e *executor) listSize(def *Field, f *field) int {
+	if argDef := def.Arg("limit"); argDef != nil {
+		for _, arg := range f.args {
+			if arg.name == "limit" {
+				if limit, ok := valueFromAST(arg.val, argDef.Type, e.vars); ok {
+					if limit, ok := limit.(int); ok && limit > 0 {
+						return limit
+					}
+				}
+			}
+		}
+	}
+	if def.ListSize > 1 {
+		return def.ListSize
+	}
+	return 1
+}
+
+func saturatingAdd(a int, b int) int {
+	if a > math.MaxInt32-b {
+		return math.MaxInt32
+	}
+	return a + b
+}
+
+func saturatingMul(a int, b int) int {
+	if b != 0 && a > math.MaxInt32/b {
+		return math.MaxInt32
+	}
+	return a * b
+}
+
+// executeSelections executes the selection set of the object type on the source value. It returns
+// false if the object is null, because a non-null field of it is.
+func (e *executor) executeSelections(t *Object, source interface{}, sels []selection, path []interface{}) (*orderedMap, bool) {
+	result := &orderedMap{vals: map[string]interface{}{}}
+	for _, g := range e.collectFields(sels, nil, map[string]bool{}) {
+		fieldPath := appendPath(path, g.key)
+		f := g.fields[0]
+		if f.name == "__typename" {
+			result.set(g.key, t.Name)
+			continue
+		}
+		def := t.Field(f.name)
+		val, ok := e.executeField(def, source, g.fields, fieldPath)
+		if !ok {
+			return nil, false
+		}
+		result.set(g.key, val)
+	}
+	return result, true
+}
+
+// executeField resolves and completes the value of the field of the source object.
+func (e *executor) executeField(def *Field, source interface{}, fields []*field, path []interface{}) (interface{}, bool) {
+	args := map[string]interface{}{}
+	for _, argDef := range def.Args {
+		given := false
+		for _, arg := range fields[0].args {
+			if arg.name != argDef.Name {
+				continue
+			}
+			if name, isVar := arg.val.(variable); isVar {
+				if _, ok := e.vars[string(name)]; !ok {
+					break
+				}
+			}
+			val, ok := valueFromAST(arg.val, argDef.Type, e.vars)
+			if !ok {
+				e.addError(fmt.Sprintf("argument '%s' has an invalid value for type '%s'", arg.name, argDef.Type), fields[0], path)
+				return nullFor(def.Type)
+			}
+			args[arg.name] = val
+			given = true
+		}
+		if !given && argDef.DefaultValue != nil {
+			args[argDef.Name] = argDef.DefaultValue
+		}
+	}
+
+	val := interface{}(nil)
+	err := error(nil)
+	if def.Resolve != nil {
+		val, err = def.Resolve(ResolveParams{Context: e.ctx, Source: source, Args: args})
+	} else {
+		val = defaultResolve(source, def.Name)
+	}
+	if err != nil {
+		if userErr, ok := err.(*UserError); ok {
+			e.addError(userErr.Message, fields[0], path)
+		} else {
+			log.Errorf("graphql resolving field '%s': %v\n", def.Name, err)
+			e.addError(InternalErrorMessage, fields[0], path)
+		}
+		return nullFor(def.Type)
+	}
+	return e.complete(def.Type, fields, val, path)
+}
+
+// nullFor returns the value of a field of the type whose value is null because of an error: null,
+// or, if the field is non-null, false, to make its parent null.
+func nullFor(t Type) (interface{}, bool) {
+	_, nonNull := t.(*NonNull)
+	return nil, !nonNull
+}
+
+func (e *executor) addError(msg string, f *field, path []interface{}) {
+	e.errs = append(e.errs, &Error{Message: msg, Locations: []location{f.loc}, Path: path})
+}
+
+// complete returns the result of the value of the fields of the given type. It returns false if the
+// value is null, and must not be, in which case its parent is made null.
+func (e *executor) complete(t Type, fields []*field, val interface{}, path []interface{}) (interface{}, bool) {
+	if nn, ok := t.(*NonNull); ok {
+		result, ok := e.completeNullable(nn.Of, fields, val, path)
+		if !ok {
+			return nil, false
+		}
+		if result == nil {
+			e.addError("cannot return null for non-nullable field", fields[0], path)
+			return nil, false
+		}
+		return result, true
+	}
+	result, ok := e.completeNullable(t, fields, val, path)
+	if !ok {
+		return nil, true
+	}
+	return result, true
+}
+
+func (e *executor) completeNullable(t Type, fields []*field, val interface{}, path []interface{}) (interface{}, bool) {
+	rv := reflect.ValueOf(val)
+	for rv.IsValid() && rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, true
+		}
+		if rv.Elem().Kind() == reflect.Struct {
+			break
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() || (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Map) && rv.IsNil() {
+		return nil, true
+	}
+
+	switch t := t.(type) {
+	case *List:
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			e.addError("expected a list", fields[0], path)
+			return nil, false
+		}
+		list := make([]interface{}, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			item, ok := e.complete(t.Of, fields, rv.Index(i).Interface(), appendPath(path, i))
+			if !ok {
+				return nil, false
+			}
+			list = append(list, item)
+		}
+		return list, true
+	case *Scalar:
+		result, ok := t.Serialize(rv.Interface())
+		if !ok {
+			e.addError(fmt.Sprintf("invalid value of type '%s'", t.Name), fields[0], path)
+			return nil, false
+		}
+		return result, true
+	case *Object:
+		sels := []selection{}
+		for _, f := range fields {
+			sels = append(sels, f.selections...)
+		}
+		result, ok := e.executeSelections(t, rv.Interface(), sels, path)
+		if !ok {
+			return nil, false
+		}
+		return result, true
+	}
+	return nil, false
+}
+
+// defaultResolve returns the value named name of the source: its value with that key if it's a map,
+// or its field with that JSON name if it's a struct or a pointer to one.
+func defaultResolve(source interface{}, name string) interface{} {
+	if m, ok := source.(map[string]interface{}); ok {
+		return m[name]
+	}
+	rv := reflect.ValueOf(source)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		if strings.Split(rt.Field(i).Tag.Get("json"), ",")[0] == name {
+			return rv.Field(i).Interface()
+		}
+	}
+	return nil
+}
+
+func appendPath(path []interface{}, key interface{}) []interface{} {
+	p := make([]interface{}, len(path), len(path)+1)
+	copy(p, path)
+	return append(p, key)
+}
+
+// orderedMap is a JSON object whose keys are marshalled in the order they were set, as GraphQL
+// requires of results.
+type orderedMap struct {
+	keys []string
+	vals map[string]interface{}
+}
+
+func (m *orderedMap) set(key string, val interface{}) {
+	if _, ok := m.vals[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.vals[key] = val
+}
+
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	buf := bytes.Buffer{}
+	buf.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(m.vals[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,311 @@
+package graphql
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type testPet struct {
+	Name  string   `json:"name"`
+	Age   *int     `json:"age"`
+	Tags  []string `json:"tags"`
+	Owner string   `json:"-"`
+}
+
+type testOwner struct {
+	Name string `json:"name"`
+}
+
+var testOwnerType = &Object{Name: "Owner", Fields: []*Field{{Name: "name", Type: nonNull(String)}}}
+
+var testPetType = &Object{Name: "Pet"}
+
+var testPets = []*testPet{
+	{Name: "Rex", Age: intPtr(3), Tags: []string{"dog"}, Owner: "ann"},
+	{Name: "Tom", Tags: []string{}, Owner: "bob"},
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func init() {
+	testPetType.Fields = []*Field{
+		{Name: "name", Type: nonNull(String)},
+		{Name: "age", Type: Int},
+		{Name: "tags", Type: listOf(String)},
+		{Name: "owner", Type: testOwnerType, Resolve: func(p ResolveParams) (interface{}, error) {
+			return &testOwner{Name: p.Source.(*testPet).Owner}, nil
+		}},
+		{Name: "friends", Type: listOf(testPetType), ListSize: 10, Resolve: func(p ResolveParams) (interface{}, error) {
+			return testPets, nil
+		}},
+		{Name: "broken", Type: String, Resolve: func(p ResolveParams) (interface{}, error) {
+			return nil, &UserError{Message: "broken"}
+		}},
+		{Name: "brokenNonNull", Type: nonNull(String), Resolve: func(p ResolveParams) (interface{}, error) {
+			return nil, &UserError{Message: "broken"}
+		}},
+		{Name: "failing", Type: String, Resolve: func(p ResolveParams) (interface{}, error) {
+			return nil, errors.New("querying pets: pq: relation \"pet\" does not exist")
+		}},
+	}
+}
+
+func testSchema() *Schema {
+	query := &Object{Name: "Query", Fields: []*Field{
+		{Name: "pets", Type: listOf(testPetType), ListSize: 100,
+			Args: []*Argument{{Name: "name", Type: String}, {Name: "limit", Type: Int}},
+			Resolve: func(p ResolveParams) (interface{}, error) {
+				pets := []*testPet{}
+				for _, pet := range testPets {
+					if name, ok := p.Args["name"]; !ok || name == pet.Name {
+						pets = append(pets, pet)
+					}
+				}
+				return pets, nil
+			}},
+		{Name: "pet", Type: testPetType,
+			Args: []*Argument{{Name: "name", Type: nonNull(String)}},
+			Resolve: func(p ResolveParams) (interface{}, error) {
+				for _, pet := range testPets {
+					if pet.Name == p.Args["name"] {
+						return pet, nil
+					}
+				}
+				return nil, nil
+			}},
+		{Name: "echo", Type: String,
+			Args: []*Argument{{Name: "s", Type: String, DefaultValue: "default"}},
+			Resolve: func(p ResolveParams) (interface{}, error) {
+				return p.Args["s"], nil
+			}},
+	}}
+	return &Schema{Query: query}
+}
+
+func execute(t *testing.T, s *Schema, query string, vars map[string]interface{}, limits Limits) (string, error) {
+	resp, err := s.Execute(context.Background(), Request{Query: query, Variables: vars}, limits)
+	if err != nil {
+		return "", err
+	}
+	bts, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatalf("marshalling response: %v", err)
+	}
+	return string(bts), nil
+}
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		vars     map[string]interface{}
+		expected string
+	}{
+		{
+			name:     "fields in order, with aliases",
+			query:    `{ pets { tags n: name age } }`,
+			expected: `{"data":{"pets":[{"tags":["dog"],"n":"Rex","age":3},{"tags":[],"n":"Tom","age":null}]}}`,
+		},
+		{
+			name: "fragments and __typename",
+			query: `query Pets { pets(name: "Rex") { ...petFields ... on Pet { owner { __typename name } } } }
+			fragment petFields on Pet { __typename name }`,
+			expected: `{"data":{"pets":[{"__typename":"Pet","name":"Rex","owner":{"__typename":"Owner","name":"ann"}}]}}`,
+		},
+		{
+			name:     "variables and directives",
+			query:    `query ($name: String!, $withAge: Boolean = false) { pet(name: $name) { name age @include(if: $withAge) tags @skip(if: true) } }`,
+			vars:     map[string]interface{}{"name": "Tom"},
+			expected: `{"data":{"pet":{"name":"Tom"}}}`,
+		},
+		{
+			name:     "default argument values",
+			query:    `{ a: echo b: echo(s: "given") c: echo(s: null) }`,
+			expected: `{"data":{"a":"default","b":"given","c":null}}`,
+		},
+		{
+			name:     "errors resolving nullable fields",
+			query:    `{ pet(name: "Rex") { name broken } }`,
+			expected: `{"data":{"pet":{"name":"Rex","broken":null}},"errors":[{"message":"broken","locations":[{"line":1,"column":27}],"path":["pet","broken"]}]}`,
+		},
+		{
+			name:     "errors resolving non-null fields make their parents null",
+			query:    `{ pet(name: "Rex") { name brokenNonNull } }`,
+			expected: `{"data":{"pet":null},"errors":[{"message":"broken","locations":[{"line":1,"column":27}],"path":["pet","brokenNonNull"]}]}`,
+		},
+		{
+			name:     "errors in non-null list items make the list's parent null",
+			query:    "{\n  pets { brokenNonNull }\n}",
+			expected: `{"data":null,"errors":[{"message":"broken","locations":[{"line":2,"column":10}],"path":["pets",0,"brokenNonNull"]}]}`,
+		},
+		{
+			name:     "system errors resolving fields are not returned",
+			query:    `{ pet(name: "Rex") { name failing } }`,
+			expected: `{"data":{"pet":{"name":"Rex","failing":null}},"errors":[{"message":"` + InternalErrorMessage + `","locations":[{"line":1,"column":27}],"path":["pet","failing"]}]}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := execute(t, testSchema(), test.query, test.vars, Limits{})
+			if err != nil {
+				t.Fatalf("expected no error, actual: %v", err)
+			}
+			if actual != test.expected {
+				t.Errorf("expected response %s, actual: %s", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestExecuteInvalidRequests(t *testing.T) {
+	tests := map[string]struct {
+		query    string
+		vars     map[string]interface{}
+		expected string
+	}{
+		"syntax error":               {`{ pets { name }`, nil, "syntax error at 1:16: expected a name, found end of document"},
+		"unknown field":              {`{ pets { colour } }`, nil, "cannot query field 'colour' on type 'Pet'"},
+		"missing subselection":       {`{ pets }`, nil, "field 'pets' of type '[Pet!]!' must have a selection of subfields"},
+		"leaf subselection":          {`{ pets { name { x } } }`, nil, "must not have a selection of subfields"},
+		"unknown argument":           {`{ pets(colour: "red") { name } }`, nil, "unknown argument 'colour' of field 'pets'"},
+		"missing required argument":  {`{ pet { name } }`, nil, "argument 'name' of type 'String!' is required by field 'pet'"},
+		"wrongly typed argument":     {`{ pets(limit: "ten") { name } }`, nil, "argument 'limit' has an invalid value for type 'Int'"},
+		"undefined variable":         {`{ pets(name: $name) { name } }`, nil, "variable '$name' is not defined"},
+		"unused variable":            {`query ($name: String) { pets { name } }`, nil, "variable '$name' is never used"},
+		"incompatible variable":      {`query ($name: String) { pet(name: $name) { name } }`, nil, "variable '$name' of type 'String' can't be used for argument 'name' of type 'String!'"},
+		"missing variable":           {`query ($name: String!) { pet(name: $name) { name } }`, nil, "variable '$name' of required type 'String!' was not provided"},
+		"wrongly typed variable":     {`query ($limit: Int) { pets(limit: $limit) { name } }`, map[string]interface{}{"limit": 1.5}, "variable '$limit' has an invalid value for type 'Int'"},
+		"unknown fragment":           {`{ pets { ...missing } }`, nil, "unknown fragment 'missing'"},
+		"unused fragment":            {`{ pets { name } } fragment f on Pet { name }`, nil, "fragment 'f' is never used"},
+		"fragment cycle":             {`{ pets { ...a } } fragment a on Pet { friends { ...b } } fragment b on Pet { friends { ...a } }`, nil, "spreads itself"},
+		"fragment on the wrong type": {`{ pets { ...f } } fragment f on Owner { name }`, nil, "fragment 'f' on 'Owner' can never be spread on type 'Pet'"},
+		"conflicting fields":         {`{ pets { name: age name } }`, nil, "fields 'name' conflict"},
+		"unknown directive":          {`{ pets { name @deprecated } }`, nil, "unknown directive '@deprecated'"},
+		"mutation":                   {`mutation { pets { name } }`, nil, "mutation operations are not supported"},
+		"ambiguous anonymous query":  {`{ pets { name } } query Other { pets { name } }`, nil, "operationName is required"},
+		"introspection":              {`{ __schema { types { name } } }`, nil, "cannot query field '__schema' on type 'Query'"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := execute(t, testSchema(), test.query, test.vars, Limits{})
+			if _, ok := err.(*RequestError); !ok {
+				t.Fatalf("expected a *RequestError, actual: %v", err)
+			}
+			if !strings.Contains(err.Error(), test.expected) {
+				t.Errorf("expected error containing '%s', actual: %v", test.expected, err)
+			}
+		})
+	}
+}
+
+func TestExecuteLimits(t *testing.T) {
+	tests := map[string]struct {
+		query    string
+		limits   Limits
+		exceeded bool
+	}{
+		"within depth":       {`{ pets { friends { name } } }`, Limits{MaxDepth: 3}, false},
+		"exceeds depth":      {`{ pets { friends { friends { name } } } }`, Limits{MaxDepth: 3}, true},
+		"within cost":        {`{ pets { name friends { name } } }`, Limits{MaxCost: 1201}, false},
+		"exceeds cost":       {`{ pets { name friends { name } } }`, Limits{MaxCost: 1200}, true},
+		"limit reduces cost": {`{ pets(limit: 2) { name friends { name } } }`, Limits{MaxCost: 25}, false},
+		"unlimited":          {`{ pets { friends { friends { friends { name } } } } }`, Limits{}, false},
+		"fragments measured": {`{ pets { ...f } } fragment f on Pet { friends { name } }`, Limits{MaxCost: 1000}, true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := execute(t, testSchema(), test.query, nil, test.limits)
+			if test.exceeded && err == nil {
+				t.Errorf("expected the query to exceed the limits, actual: no error")
+			}
+			if !test.exceeded && err != nil {
+				t.Errorf("expected no error, actual: %v", err)
+			}
+		})
+	}
+
+	// a query whose fragments double its size at each level would take forever to measure fully
+	query := `{ pets { ...f0 } }`
+	for i := 0; i < 40; i++ {
+		query += " fragment f" + strconv.Itoa(i) + " on Pet { a: friends { ...f" + strconv.Itoa(i+1) + " } b: friends { ...f" + strconv.Itoa(i+1) + " } }"
+	}
+	query += " fragment f40 on Pet { name }"
+	if _, err := execute(t, testSchema(), query, nil, Limits{MaxDepth: 100, MaxCost: 10000}); err == nil || !strings.Contains(err.Error(), "maximum cost") {
+		t.Errorf("expected an exponential query to exceed the maximum cost, actual: %v", err)
+	}
+}
+
+func TestExecuteAuthorize(t *testing.T) {
+	s := testSchema()
+	authorized := []string{}
+	s.Authorize = func(ctx context.Context, o *Object) error {
+		authorized = append(authorized, o.Name)
+		if o.Name == "Owner" {
+			return errors.New("forbidden")
+		}
+		return nil
+	}
+	if _, err := execute(t, s, `{ pets { name } }`, nil, Limits{}); err != nil {
+		t.Errorf("expected no error querying authorized types, actual: %v", err)
+	}
+	if _, err := execute(t, s, `{ pets { owner { name } } }`, nil, Limits{}); err == nil || err.Error() != "forbidden" {
+		t.Errorf("expected the Authorize error querying an unauthorized type, actual: %v", err)
+	}
+	expected := "Query,Pet,Query,Pet,Owner"
+	if actual := strings.Join(authorized, ","); actual != expected {
+		t.Errorf("expected types authorized %s, actual: %s", expected, actual)
+	}
+}
+
+func TestParseStrings(t *testing.T) {
+	doc, err := parse("{ echo(s: \"a\\n\\u00e9\\\"\") b: echo(s: \"\"\"\n    block\n      indented\n    \"\"\") }")
+	if err != nil {
+		t.Fatalf("expected no error, actual: %v", err)
+	}
+	sels := doc.operations[0].selections
+	if actual := sels[0].(*field).args[0].val; actual != stringValue("a\né\"") {
+		t.Errorf("expected string value %q, actual: %q", "a\né\"", actual)
+	}
+	if actual := sels[1].(*field).args[0].val; actual != stringValue("block\n  indented") {
+		t.Errorf("expected block string value %q, actual: %q", "block\n  indented", actual)
+	}
+}
+
+func TestSDL(t *testing.T) {
+	sdl := testSchema().SDL()
+	for _, expected := range []string{
+		"schema {\n  query: Query\n}\n",
+		"type Query {\n  pets(name: String, limit: Int): [Pet!]!\n",
+		"type Pet {\n  name: String!\n",
+		"type Owner {\n  name: String!\n}\n",
+	} {
+		if !strings.Contains(sdl, expected) {
+			t.Errorf("expected SDL to contain %q, actual:\n%s", expected, sdl)
+		}
+	}
+}
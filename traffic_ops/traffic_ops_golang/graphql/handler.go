@@ -0,0 +1,128 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+// Package graphql is the Traffic Ops GraphQL API, which queries servers, Delivery Services, Cache Groups, Profiles, Parameters and CDNs, and the relationships between them, in one request.
+// It implements the query subset of GraphQL (https://spec.graphql.org): operations, variables, fragments and the @skip and @include directives, but not mutations, subscriptions or introspection; the schema is served in the GraphQL schema definition language instead.
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"mime"
+	"net/http"
+
+	"github.com/apache/trafficcontrol/lib/go-rfc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+)
+
+// ContentTypeGraphQL is the content type of a POSTed query which isn't in a JSON request.
+const ContentTypeGraphQL = "application/graphql"
+
+// authorize returns an error if the user isn't authorized to query the object type, because their
+// priv level is below that of the route which reads the same objects.
+func authorize(ctx context.Context, t *Object) error {
+	if t == queryType {
+		return nil
+	}
+	l := loaderFrom(ctx)
+	level, ok := l.privLevels[t.Name]
+	if !ok || l.user.PrivLevel < level {
+		return errors.New("forbidden: insufficient privileges to query " + t.Name)
+	}
+	return nil
+}
+
+// Handler returns the handler of GraphQL queries, which are GETted with the query string
+// parameters query, operationName and variables, or POSTed as JSON, or as a query alone with the
+// content type application/graphql.
+//
+// privLevels is the priv level a user must have to query each object type, by name.
+func Handler(privLevels map[string]int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		inf, userErr, sysErr, errCode := api.NewInfo(r, nil, nil)
+		if userErr != nil || sysErr != nil {
+			api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+			return
+		}
+		defer inf.Close()
+
+		req, err := readRequest(r)
+		if err != nil {
+			writeErrors(w, http.StatusBadRequest, []*Error{{Message: err.Error()}})
+			return
+		}
+
+		l := newLoader(inf.Tx, inf.User)
+		l.privLevels = privLevels
+		limits := Limits{MaxDepth: inf.Config.GraphQLMaxDepth, MaxCost: inf.Config.GraphQLMaxCost}
+		resp, err := schema.Execute(withLoader(r.Context(), l), req, limits)
+		if err != nil {
+			if reqErr, ok := err.(*RequestError); ok {
+				writeErrors(w, http.StatusBadRequest, reqErr.Errors)
+			} else {
+				writeErrors(w, http.StatusForbidden, []*Error{{Message: err.Error()}})
+			}
+			return
+		}
+		api.WriteRespRaw(w, r, resp)
+	}
+}
+
+func readRequest(r *http.Request) (Request, error) {
+	req := Request{}
+	if r.Method == http.MethodGet {
+		req.Query = r.URL.Query().Get("query")
+		req.OperationName = r.URL.Query().Get("operationName")
+		if vars := r.URL.Query().Get("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+				return req, errors.New("variables must be a JSON object")
+			}
+		}
+	} else {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return req, errors.New("reading request body: " + err.Error())
+		}
+		if mediaType, _, _ := mime.ParseMediaType(r.Header.Get(rfc.ContentType)); mediaType == ContentTypeGraphQL {
+			req.Query = string(body)
+		} else if err := json.Unmarshal(body, &req); err != nil {
+			return req, errors.New("request body must be a JSON object with a query, or a query with the content type " + ContentTypeGraphQL)
+		}
+	}
+	if req.Query == "" {
+		return req, errors.New("a query is required")
+	}
+	return req, nil
+}
+
+// writeErrors writes a response of errors, which executing the query didn't get as far as.
+func writeErrors(w http.ResponseWriter, code int, errs []*Error) {
+	bts, _ := json.Marshal(Response{Errors: errs})
+	w.Header().Set(rfc.ContentType, rfc.ApplicationJSON)
+	w.WriteHeader(code)
+	w.Write(append(bts, '\n'))
+}
+
+// SchemaHandler serves the schema of the GraphQL API, in the GraphQL schema definition language.
+func SchemaHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(rfc.ContentType, "text/plain; charset=utf-8")
+	w.Write([]byte(schema.SDL()))
+}
@@ -0,0 +1,403 @@
+package graphql
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/parameter"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/server"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Time is a time, serialized in RFC 3339 format.
+var Time = &Scalar{
+	Name:        "Time",
+	Description: "A time, in RFC 3339 format.",
+	Serialize: func(v interface{}) (interface{}, bool) {
+		t, ok := v.(time.Time)
+		return t.Format(time.RFC3339Nano), ok
+	},
+	Parse: func(v interface{}) (interface{}, bool) {
+		s, ok := v.(string)
+		if !ok {
+			return nil, false
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		return t, err == nil
+	},
+}
+
+type cdn struct {
+	ID            int       `json:"id" db:"id"`
+	Name          string    `json:"name" db:"name"`
+	DomainName    string    `json:"domainName" db:"domain_name"`
+	DNSSECEnabled bool      `json:"dnssecEnabled" db:"dnssec_enabled"`
+	LastUpdated   time.Time `json:"lastUpdated" db:"last_updated"`
+}
+
+type cacheGroup struct {
+	ID                          int       `json:"id" db:"id"`
+	Name                        string    `json:"name" db:"name"`
+	ShortName                   string    `json:"shortName" db:"short_name"`
+	Latitude                    *float64  `json:"latitude" db:"latitude"`
+	Longitude                   *float64  `json:"longitude" db:"longitude"`
+	TypeName                    string    `json:"typeName" db:"type_name"`
+	FallbackToClosest           *bool     `json:"fallbackToClosest" db:"fallback_to_closest"`
+	ParentCachegroupID          *int      `json:"parentCachegroupId" db:"parent_cachegroup_id"`
+	SecondaryParentCachegroupID *int      `json:"secondaryParentCachegroupId" db:"secondary_parent_cachegroup_id"`
+	LastUpdated                 time.Time `json:"lastUpdated" db:"last_updated"`
+}
+
+type profile struct {
+	ID              int       `json:"id" db:"id"`
+	Name            string    `json:"name" db:"name"`
+	Description     *string   `json:"description" db:"description"`
+	Type            string    `json:"type" db:"type"`
+	RoutingDisabled bool      `json:"routingDisabled" db:"routing_disabled"`
+	CDNID           int       `json:"cdnId" db:"cdn_id"`
+	LastUpdated     time.Time `json:"lastUpdated" db:"last_updated"`
+}
+
+type param struct {
+	ID          int       `json:"id" db:"id"`
+	Name        string    `json:"name" db:"name"`
+	ConfigFile  *string   `json:"configFile" db:"config_file"`
+	Value       string    `json:"value" db:"value"`
+	Secure      bool      `json:"secure" db:"secure"`
+	LastUpdated time.Time `json:"lastUpdated" db:"last_updated"`
+}
+
+type srv struct {
+	ID            int       `json:"id" db:"id"`
+	HostName      string    `json:"hostName" db:"host_name"`
+	DomainName    string    `json:"domainName" db:"domain_name"`
+	TCPPort       *int      `json:"tcpPort" db:"tcp_port"`
+	HTTPSPort     *int      `json:"httpsPort" db:"https_port"`
+	IPAddress     *string   `json:"ipAddress" db:"ip_address"`
+	IP6Address    *string   `json:"ip6Address" db:"ip6_address"`
+	InterfaceName string    `json:"interfaceName" db:"interface_name"`
+	InterfaceMtu  int       `json:"interfaceMtu" db:"interface_mtu"`
+	Type          string    `json:"type" db:"type"`
+	Status        string    `json:"status" db:"status"`
+	PhysLocation  string    `json:"physLocation" db:"phys_location"`
+	Rack          *string   `json:"rack" db:"rack"`
+	OfflineReason *string   `json:"offlineReason" db:"offline_reason"`
+	UpdPending    bool      `json:"updPending" db:"upd_pending"`
+	RevalPending  bool      `json:"revalPending" db:"reval_pending"`
+	XMPPID        *string   `json:"xmppId" db:"xmpp_id"`
+	CDNID         int       `json:"cdnId" db:"cdn_id"`
+	CachegroupID  int       `json:"cachegroupId" db:"cachegroup_id"`
+	ProfileID     int       `json:"profileId" db:"profile_id"`
+	LastUpdated   time.Time `json:"lastUpdated" db:"last_updated"`
+}
+
+type deliveryService struct {
+	ID                 int       `json:"id" db:"id"`
+	XMLID              string    `json:"xmlId" db:"xml_id"`
+	DisplayName        string    `json:"displayName" db:"display_name"`
+	Active             bool      `json:"active" db:"active"`
+	Type               string    `json:"type" db:"type"`
+	RoutingName        string    `json:"routingName" db:"routing_name"`
+	Protocol           *int      `json:"protocol" db:"protocol"`
+	QStringIgnore      *int      `json:"qstringIgnore" db:"qstring_ignore"`
+	IPV6RoutingEnabled *bool     `json:"ipv6RoutingEnabled" db:"ipv6_routing_enabled"`
+	LongDesc           *string   `json:"longDesc" db:"long_desc"`
+	InfoURL            *string   `json:"infoUrl" db:"info_url"`
+	TenantID           *int      `json:"tenantId" db:"tenant_id"`
+	Tenant             *string   `json:"tenant" db:"tenant"`
+	CDNID              int       `json:"cdnId" db:"cdn_id"`
+	ProfileID          *int      `json:"profileId" db:"profile_id"`
+	LastUpdated        time.Time `json:"lastUpdated" db:"last_updated"`
+}
+
+const selectCDNs = `
+SELECT cdn.id, cdn.name, cdn.domain_name, cdn.dnssec_enabled, cdn.last_updated
+FROM cdn`
+
+const selectCacheGroups = `
+SELECT cg.id, cg.name, cg.short_name, co.latitude, co.longitude, t.name AS type_name, cg.fallback_to_closest,
+  cg.parent_cachegroup_id, cg.secondary_parent_cachegroup_id, cg.last_updated
+FROM cachegroup cg
+JOIN type t ON cg.type = t.id
+LEFT JOIN coordinate co ON cg.coordinate = co.id`
+
+const selectProfiles = `
+SELECT p.id, p.name, p.description, p.type, p.routing_disabled, p.cdn AS cdn_id, p.last_updated
+FROM profile p
+JOIN cdn ON p.cdn = cdn.id`
+
+const selectParameters = `
+SELECT pa.id, pa.name, pa.config_file, pa.value, pa.secure, pa.last_updated
+FROM parameter pa`
+
+var selectServers = `
+SELECT s.id, s.host_name, s.domain_name, s.tcp_port, s.https_port, s.ip_address, s.ip6_address, s.interface_name,
+  COALESCE(s.interface_mtu, ` + strconv.Itoa(server.JumboFrameBPS) + `) AS interface_mtu, t.name AS type, st.name AS status,
+  pl.name AS phys_location, s.rack, s.offline_reason, s.upd_pending, s.reval_pending, s.xmpp_id, s.cdn_id,
+  s.cachegroup AS cachegroup_id, s.profile AS profile_id, s.last_updated
+FROM server s
+JOIN type t ON s.type = t.id
+JOIN status st ON s.status = st.id
+JOIN phys_location pl ON s.phys_location = pl.id
+JOIN cdn ON s.cdn_id = cdn.id
+JOIN cachegroup cg ON s.cachegroup = cg.id
+JOIN profile p ON s.profile = p.id`
+
+const selectDeliveryServices = `
+SELECT ds.id, ds.xml_id, ds.display_name, ds.active, t.name AS type, ds.routing_name, ds.protocol, ds.qstring_ignore,
+  ds.ipv6_routing_enabled, ds.long_desc, ds.info_url, ds.tenant_id, tn.name AS tenant, ds.cdn_id,
+  ds.profile AS profile_id, ds.last_updated
+FROM deliveryservice ds
+JOIN type t ON ds.type = t.id
+JOIN cdn ON ds.cdn_id = cdn.id
+LEFT JOIN tenant tn ON ds.tenant_id = tn.id`
+
+// conditions is the conditions of a WHERE clause, and the values of their placeholders.
+type conditions struct {
+	clauses []string
+	vals    []interface{}
+}
+
+// add adds a condition, whose "?" is the placeholder of val.
+func (c *conditions) add(clause string, val interface{}) *conditions {
+	c.vals = append(c.vals, val)
+	c.clauses = append(c.clauses, strings.Replace(clause, "?", "$"+strconv.Itoa(len(c.vals)), 1))
+	return c
+}
+
+// filter adds a condition that each column equals the value of the argument it's keyed on, for each
+// argument which was given.
+func (c *conditions) filter(args map[string]interface{}, columns map[string]string) *conditions {
+	names := []string{}
+	for name := range columns {
+		if args[name] != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		c.add(columns[name]+" = ?", args[name])
+	}
+	return c
+}
+
+func (c *conditions) where() string {
+	if len(c.clauses) == 0 {
+		return ""
+	}
+	return "\nWHERE " + strings.Join(c.clauses, " AND ")
+}
+
+// loader loads the objects of a query, as the user querying them may see them.
+type loader struct {
+	tx   *sqlx.Tx
+	user *auth.CurrentUser
+	// privLevels is the priv level the user must have to query each object type.
+	privLevels map[string]int
+	// tenantIDs is the IDs of the tenants the user may see the Delivery Services of, once they've
+	// been loaded.
+	tenantIDs []int
+
+	cdns        map[int]*cdn
+	cacheGroups map[int]*cacheGroup
+	profiles    map[int]*profile
+}
+
+func newLoader(tx *sqlx.Tx, user *auth.CurrentUser) *loader {
+	return &loader{tx: tx, user: user, cdns: map[int]*cdn{}, cacheGroups: map[int]*cacheGroup{}, profiles: map[int]*profile{}}
+}
+
+type loaderKey struct{}
+
+func withLoader(ctx context.Context, l *loader) context.Context {
+	return context.WithValue(ctx, loaderKey{}, l)
+}
+
+func loaderFrom(ctx context.Context) *loader {
+	return ctx.Value(loaderKey{}).(*loader)
+}
+
+// query selects the rows of the query with the conditions into dest, a pointer to a slice of
+// pointers to structs. limit is the maximum number of rows, if it's given.
+func (l *loader) query(dest interface{}, query string, c *conditions, orderBy string, limit interface{}) error {
+	query += c.where() + "\nORDER BY " + orderBy
+	if limit != nil {
+		if limit.(int) < 1 {
+			return &UserError{Message: "limit must be at least 1"}
+		}
+		query += "\nLIMIT " + strconv.Itoa(limit.(int))
+	}
+	return l.tx.Select(dest, query, c.vals...)
+}
+
+// tenancy adds a condition that the tenant column is one of the user's tenants.
+func (l *loader) tenancy(c *conditions, column string) (*conditions, error) {
+	if l.tenantIDs == nil {
+		ids, err := tenant.GetUserTenantIDListTx(l.tx.Tx, l.user.TenantID)
+		if err != nil {
+			return nil, errors.New("getting user tenants: " + err.Error())
+		}
+		l.tenantIDs = ids
+	}
+	return c.add(column+" = ANY(?)", pq.Array(l.tenantIDs)), nil
+}
+
+func (l *loader) loadCDNs(c *conditions, limit interface{}) ([]*cdn, error) {
+	cdns := []*cdn{}
+	if err := l.query(&cdns, selectCDNs, c, "cdn.name", limit); err != nil {
+		return nil, errors.New("querying cdns: " + err.Error())
+	}
+	for _, cdn := range cdns {
+		l.cdns[cdn.ID] = cdn
+	}
+	return cdns, nil
+}
+
+func (l *loader) cdn(id int) (*cdn, error) {
+	if cdn, ok := l.cdns[id]; ok {
+		return cdn, nil
+	}
+	cdns, err := l.loadCDNs((&conditions{}).add("cdn.id = ?", id), nil)
+	if err != nil || len(cdns) == 0 {
+		return nil, err
+	}
+	return cdns[0], nil
+}
+
+func (l *loader) loadCacheGroups(c *conditions, limit interface{}) ([]*cacheGroup, error) {
+	cgs := []*cacheGroup{}
+	if err := l.query(&cgs, selectCacheGroups, c, "cg.name", limit); err != nil {
+		return nil, errors.New("querying cachegroups: " + err.Error())
+	}
+	for _, cg := range cgs {
+		l.cacheGroups[cg.ID] = cg
+	}
+	return cgs, nil
+}
+
+func (l *loader) cacheGroup(id *int) (*cacheGroup, error) {
+	if id == nil {
+		return nil, nil
+	}
+	if cg, ok := l.cacheGroups[*id]; ok {
+		return cg, nil
+	}
+	cgs, err := l.loadCacheGroups((&conditions{}).add("cg.id = ?", *id), nil)
+	if err != nil || len(cgs) == 0 {
+		return nil, err
+	}
+	return cgs[0], nil
+}
+
+func (l *loader) loadProfiles(c *conditions, limit interface{}) ([]*profile, error) {
+	profiles := []*profile{}
+	if err := l.query(&profiles, selectProfiles, c, "p.name", limit); err != nil {
+		return nil, errors.New("querying profiles: " + err.Error())
+	}
+	for _, p := range profiles {
+		l.profiles[p.ID] = p
+	}
+	return profiles, nil
+}
+
+func (l *loader) profile(id *int) (*profile, error) {
+	if id == nil {
+		return nil, nil
+	}
+	if p, ok := l.profiles[*id]; ok {
+		return p, nil
+	}
+	profiles, err := l.loadProfiles((&conditions{}).add("p.id = ?", *id), nil)
+	if err != nil || len(profiles) == 0 {
+		return nil, err
+	}
+	return profiles[0], nil
+}
+
+// loadParameters loads Parameters, hiding the values of secure Parameters from users who aren't
+// admins, as the parameters endpoint does.
+func (l *loader) loadParameters(query string, c *conditions, limit interface{}) ([]*param, error) {
+	params := []*param{}
+	if err := l.query(&params, query, c, "pa.config_file, pa.name, pa.value", limit); err != nil {
+		return nil, errors.New("querying parameters: " + err.Error())
+	}
+	for _, p := range params {
+		if p.Secure && l.user.PrivLevel < auth.PrivLevelAdmin {
+			p.Value = parameter.HiddenField
+		}
+	}
+	return params, nil
+}
+
+func (l *loader) loadServers(query string, c *conditions, limit interface{}) ([]*srv, error) {
+	servers := []*srv{}
+	if err := l.query(&servers, query, c, "s.host_name", limit); err != nil {
+		return nil, errors.New("querying servers: " + err.Error())
+	}
+	return servers, nil
+}
+
+// loadDeliveryServices loads the Delivery Services of the user's tenants.
+func (l *loader) loadDeliveryServices(query string, c *conditions, limit interface{}) ([]*deliveryService, error) {
+	c, err := l.tenancy(c, "ds.tenant_id")
+	if err != nil {
+		return nil, err
+	}
+	dses := []*deliveryService{}
+	if err := l.query(&dses, query, c, "ds.xml_id", limit); err != nil {
+		return nil, errors.New("querying deliveryservices: " + err.Error())
+	}
+	return dses, nil
+}
+
+// deliveryService loads the Delivery Service with the given ID or XMLID, and returns an error if
+// it isn't of a tenant the user may see.
+func (l *loader) deliveryService(c *conditions) (*deliveryService, error) {
+	dses := []*deliveryService{}
+	if err := l.query(&dses, selectDeliveryServices, c, "ds.xml_id", nil); err != nil {
+		return nil, errors.New("querying deliveryservices: " + err.Error())
+	}
+	if len(dses) == 0 {
+		return nil, nil
+	}
+	ds := dses[0]
+	authorized := false
+	if ds.TenantID != nil {
+		ok, err := tenant.IsResourceAuthorizedToUserTx(*ds.TenantID, l.user, l.tx.Tx)
+		if err != nil {
+			return nil, errors.New("checking tenant: " + err.Error())
+		}
+		authorized = ok
+	}
+	if !authorized {
+		return nil, &UserError{Message: "not authorized on this tenant"}
+	}
+	return ds, nil
+}
@@ -0,0 +1,660 @@
+package graphql
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// location is a position in a GraphQL document, as reported in errors.
+type location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// syntaxError is an error in the syntax of a GraphQL document.
+type syntaxError struct {
+	msg string
+	loc location
+}
+
+func (e *syntaxError) Error() string {
+	return fmt.Sprintf("syntax error at %d:%d: %s", e.loc.Line, e.loc.Column, e.msg)
+}
+
+// document is a parsed GraphQL executable document.
+type document struct {
+	operations []*operation
+	fragments  map[string]*fragment
+}
+
+// operation is an operation definition, e.g. "query Servers($cdn: String) { ... }".
+type operation struct {
+	typ        string
+	name       string
+	variables  []*variableDefinition
+	directives []*directive
+	selections []selection
+	loc        location
+}
+
+type variableDefinition struct {
+	name         string
+	typ          *typeRef
+	defaultValue value
+	loc          location
+}
+
+// typeRef is a reference to a type in a variable definition, e.g. "[String!]".
+type typeRef struct {
+	name    string
+	elem    *typeRef
+	nonNull bool
+}
+
+func (t *typeRef) String() string {
+	s := t.name
+	if t.elem != nil {
+		s = "[" + t.elem.String() + "]"
+	}
+	if t.nonNull {
+		s += "!"
+	}
+	return s
+}
+
+type fragment struct {
+	name          string
+	typeCondition string
+	directives    []*directive
+	selections    []selection
+	loc           location
+}
+
+// selection is a *field, *fragmentSpread or *inlineFragment.
+type selection interface{}
+
+type field struct {
+	alias      string
+	name       string
+	args       []*argument
+	directives []*directive
+	selections []selection
+	loc        location
+}
+
+// responseKey is the key of the field's value in the response: its alias, or its name.
+func (f *field) responseKey() string {
+	if f.alias != "" {
+		return f.alias
+	}
+	return f.name
+}
+
+type fragmentSpread struct {
+	name       string
+	directives []*directive
+	loc        location
+}
+
+type inlineFragment struct {
+	typeCondition string
+	directives    []*directive
+	selections    []selection
+	loc           location
+}
+
+type argument struct {
+	name string
+	val  value
+	loc  location
+}
+
+type directive struct {
+	name string
+	args []*argument
+	loc  location
+}
+
+// value is a literal or variable in a document: a variable, int, float, string, bool, null, enum,
+// list or object.
+type value interface{}
+
+type (
+	variable    string
+	intValue    string
+	floatValue  string
+	stringValue string
+	boolValue   bool
+	nullValue   struct{}
+	enumValue   string
+	listValue   []value
+	objectValue []*objectField
+)
+
+type objectField struct {
+	name string
+	val  value
+}
+
+// tokenKind is the kind of a lexical token of a GraphQL document.
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunctuator
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind tokenKind
+	// val is the token's text; for strings, their value.
+	val string
+	loc location
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of document"
+	case tokenString:
+		return strconv.Quote(t.val)
+	}
+	return "'" + t.val + "'"
+}
+
+// parser is a recursive descent parser of GraphQL executable documents.
+type parser struct {
+	src  string
+	pos  int
+	line int
+	// lineStart is the position of the start of the current line.
+	lineStart int
+	tok       token
+}
+
+// parse parses the GraphQL executable document src, i.e. its operations and fragments.
+func parse(src string) (doc *document, err error) {
+	p := &parser{src: src, line: 1}
+	defer func() {
+		if r := recover(); r != nil {
+			se, ok := r.(*syntaxError)
+			if !ok {
+				panic(r)
+			}
+			doc, err = nil, se
+		}
+	}()
+	p.next()
+	doc = &document{fragments: map[string]*fragment{}}
+	for p.tok.kind != tokenEOF {
+		switch {
+		case p.peek(tokenPunctuator, "{"):
+			doc.operations = append(doc.operations, &operation{typ: "query", loc: p.tok.loc, selections: p.parseSelectionSet()})
+		case p.peek(tokenName, "fragment"):
+			f := p.parseFragment()
+			if _, ok := doc.fragments[f.name]; ok {
+				p.fail(f.loc, "there can only be one fragment named '"+f.name+"'")
+			}
+			doc.fragments[f.name] = f
+		case p.peek(tokenName, "query"), p.peek(tokenName, "mutation"), p.peek(tokenName, "subscription"):
+			doc.operations = append(doc.operations, p.parseOperation())
+		default:
+			p.unexpected()
+		}
+	}
+	if len(doc.operations) == 0 {
+		return nil, errors.New("document has no operations")
+	}
+	return doc, nil
+}
+
+func (p *parser) fail(loc location, msg string) {
+	panic(&syntaxError{msg: msg, loc: loc})
+}
+
+func (p *parser) unexpected() {
+	p.fail(p.tok.loc, "unexpected "+p.tok.String())
+}
+
+func (p *parser) peek(kind tokenKind, val string) bool {
+	return p.tok.kind == kind && p.tok.val == val
+}
+
+// skip consumes the current token and returns true if it's the given punctuator.
+func (p *parser) skip(punctuator string) bool {
+	if p.peek(tokenPunctuator, punctuator) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(punctuator string) location {
+	loc := p.tok.loc
+	if !p.skip(punctuator) {
+		p.fail(loc, "expected '"+punctuator+"', found "+p.tok.String())
+	}
+	return loc
+}
+
+func (p *parser) expectName() string {
+	if p.tok.kind != tokenName {
+		p.fail(p.tok.loc, "expected a name, found "+p.tok.String())
+	}
+	name := p.tok.val
+	p.next()
+	return name
+}
+
+func (p *parser) parseOperation() *operation {
+	op := &operation{loc: p.tok.loc, typ: p.expectName()}
+	if p.tok.kind == tokenName {
+		op.name = p.expectName()
+	}
+	if p.skip("(") {
+		for !p.skip(")") {
+			v := &variableDefinition{loc: p.expect("$")}
+			v.name = p.expectName()
+			p.expect(":")
+			v.typ = p.parseType()
+			if p.skip("=") {
+				v.defaultValue = p.parseValue(true)
+			}
+			op.variables = append(op.variables, v)
+		}
+	}
+	op.directives = p.parseDirectives()
+	op.selections = p.parseSelectionSet()
+	return op
+}
+
+func (p *parser) parseType() *typeRef {
+	t := &typeRef{}
+	if p.skip("[") {
+		t.elem = p.parseType()
+		p.expect("]")
+	} else {
+		t.name = p.expectName()
+	}
+	t.nonNull = p.skip("!")
+	return t
+}
+
+func (p *parser) parseFragment() *fragment {
+	f := &fragment{loc: p.tok.loc}
+	p.next()
+	f.name = p.expectName()
+	if f.name == "on" {
+		p.fail(f.loc, "a fragment can't be named 'on'")
+	}
+	if !p.peek(tokenName, "on") {
+		p.fail(p.tok.loc, "expected 'on', found "+p.tok.String())
+	}
+	p.next()
+	f.typeCondition = p.expectName()
+	f.directives = p.parseDirectives()
+	f.selections = p.parseSelectionSet()
+	return f
+}
+
+func (p *parser) parseSelectionSet() []selection {
+	p.expect("{")
+	selections := []selection{}
+	for !p.skip("}") {
+		selections = append(selections, p.parseSelection())
+	}
+	if len(selections) == 0 {
+		p.fail(p.tok.loc, "a selection set can't be empty")
+	}
+	return selections
+}
+
+func (p *parser) parseSelection() selection {
+	loc := p.tok.loc
+	if p.skip("...") {
+		if p.tok.kind == tokenName && p.tok.val != "on" {
+			return &fragmentSpread{name: p.expectName(), directives: p.parseDirectives(), loc: loc}
+		}
+		f := &inlineFragment{loc: loc}
+		if p.peek(tokenName, "on") {
+			p.next()
+			f.typeCondition = p.expectName()
+		}
+		f.directives = p.parseDirectives()
+		f.selections = p.parseSelectionSet()
+		return f
+	}
+
+	f := &field{loc: loc, name: p.expectName()}
+	if p.skip(":") {
+		f.alias, f.name = f.name, p.expectName()
+	}
+	f.args = p.parseArguments()
+	f.directives = p.parseDirectives()
+	if p.peek(tokenPunctuator, "{") {
+		f.selections = p.parseSelectionSet()
+	}
+	return f
+}
+
+func (p *parser) parseArguments() []*argument {
+	args := []*argument{}
+	if !p.skip("(") {
+		return args
+	}
+	for !p.skip(")") {
+		arg := &argument{loc: p.tok.loc, name: p.expectName()}
+		p.expect(":")
+		arg.val = p.parseValue(false)
+		args = append(args, arg)
+	}
+	return args
+}
+
+func (p *parser) parseDirectives() []*directive {
+	directives := []*directive{}
+	for p.peek(tokenPunctuator, "@") {
+		d := &directive{loc: p.tok.loc}
+		p.next()
+		d.name = p.expectName()
+		d.args = p.parseArguments()
+		directives = append(directives, d)
+	}
+	return directives
+}
+
+// parseValue parses a value; if constant is true, it may not be or contain a variable.
+func (p *parser) parseValue(constant bool) value {
+	tok := p.tok
+	switch tok.kind {
+	case tokenInt:
+		p.next()
+		return intValue(tok.val)
+	case tokenFloat:
+		p.next()
+		return floatValue(tok.val)
+	case tokenString:
+		p.next()
+		return stringValue(tok.val)
+	case tokenName:
+		p.next()
+		switch tok.val {
+		case "true":
+			return boolValue(true)
+		case "false":
+			return boolValue(false)
+		case "null":
+			return nullValue{}
+		}
+		return enumValue(tok.val)
+	}
+	switch {
+	case p.skip("$"):
+		if constant {
+			p.fail(tok.loc, "a default value can't be a variable")
+		}
+		return variable(p.expectName())
+	case p.skip("["):
+		list := listValue{}
+		for !p.skip("]") {
+			list = append(list, p.parseValue(constant))
+		}
+		return list
+	case p.skip("{"):
+		obj := objectValue{}
+		for !p.skip("}") {
+			f := &objectField{name: p.expectName()}
+			p.expect(":")
+			f.val = p.parseValue(constant)
+			obj = append(obj, f)
+		}
+		return obj
+	}
+	p.unexpected()
+	return nil
+}
+
+// next lexes the next token into p.tok.
+func (p *parser) next() {
+	p.skipIgnored()
+	loc := location{Line: p.line, Column: p.pos - p.lineStart + 1}
+	if p.pos >= len(p.src) {
+		p.tok = token{kind: tokenEOF, loc: loc}
+		return
+	}
+	start := p.pos
+	c := p.src[p.pos]
+	switch {
+	case strings.HasPrefix(p.src[p.pos:], "..."):
+		p.pos += 3
+		p.tok = token{kind: tokenPunctuator, val: "...", loc: loc}
+	case strings.IndexByte("!$&()[]{}:=@|", c) >= 0:
+		p.pos++
+		p.tok = token{kind: tokenPunctuator, val: string(c), loc: loc}
+	case c == '_' || isLetter(c):
+		for p.pos < len(p.src) && (p.src[p.pos] == '_' || isLetter(p.src[p.pos]) || isDigit(p.src[p.pos])) {
+			p.pos++
+		}
+		p.tok = token{kind: tokenName, val: p.src[start:p.pos], loc: loc}
+	case c == '-' || isDigit(c):
+		p.tok = p.lexNumber(loc)
+	case c == '"':
+		p.tok = token{kind: tokenString, val: p.lexString(loc), loc: loc}
+	default:
+		r, _ := utf8.DecodeRuneInString(p.src[p.pos:])
+		p.fail(loc, "unexpected character "+strconv.QuoteRune(r))
+	}
+}
+
+// skipIgnored skips white space, line terminators, commas and comments, which are insignificant.
+func (p *parser) skipIgnored() {
+	for p.pos < len(p.src) {
+		switch c := p.src[p.pos]; c {
+		case ' ', '\t', ',':
+			p.pos++
+		case '\n', '\r':
+			p.pos++
+			if c == '\r' && p.pos < len(p.src) && p.src[p.pos] == '\n' {
+				p.pos++
+			}
+			p.newLine()
+		case '#':
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' && p.src[p.pos] != '\r' {
+				p.pos++
+			}
+		default:
+			if strings.HasPrefix(p.src[p.pos:], "\ufeff") {
+				p.pos += len("\ufeff")
+				continue
+			}
+			return
+		}
+	}
+}
+
+func (p *parser) newLine() {
+	p.line++
+	p.lineStart = p.pos
+}
+
+func (p *parser) lexNumber(loc location) token {
+	start := p.pos
+	kind := tokenInt
+	if p.src[p.pos] == '-' {
+		p.pos++
+	}
+	digits := func() {
+		if p.pos >= len(p.src) || !isDigit(p.src[p.pos]) {
+			p.fail(loc, "invalid number")
+		}
+		for p.pos < len(p.src) && isDigit(p.src[p.pos]) {
+			p.pos++
+		}
+	}
+	digits()
+	if p.src[start] == '-' && p.src[start+1] == '0' && p.pos-start > 2 || p.src[start] == '0' && p.pos-start > 1 {
+		p.fail(loc, "invalid number: leading zero")
+	}
+	if p.pos < len(p.src) && p.src[p.pos] == '.' {
+		kind = tokenFloat
+		p.pos++
+		digits()
+	}
+	if p.pos < len(p.src) && (p.src[p.pos] == 'e' || p.src[p.pos] == 'E') {
+		kind = tokenFloat
+		p.pos++
+		if p.pos < len(p.src) && (p.src[p.pos] == '+' || p.src[p.pos] == '-') {
+			p.pos++
+		}
+		digits()
+	}
+	if p.pos < len(p.src) && (p.src[p.pos] == '_' || p.src[p.pos] == '.' || isLetter(p.src[p.pos])) {
+		p.fail(loc, "invalid number")
+	}
+	return token{kind: kind, val: p.src[start:p.pos], loc: loc}
+}
+
+// lexString lexes a string or block string, and returns its value.
+func (p *parser) lexString(loc location) string {
+	if strings.HasPrefix(p.src[p.pos:], `"""`) {
+		return p.lexBlockString(loc)
+	}
+	p.pos++
+	b := strings.Builder{}
+	for {
+		if p.pos >= len(p.src) || p.src[p.pos] == '\n' || p.src[p.pos] == '\r' {
+			p.fail(loc, "unterminated string")
+		}
+		c := p.src[p.pos]
+		switch {
+		case c == '"':
+			p.pos++
+			return b.String()
+		case c != '\\':
+			b.WriteByte(c)
+			p.pos++
+			continue
+		}
+		if p.pos+1 >= len(p.src) {
+			p.fail(loc, "unterminated string")
+		}
+		esc := p.src[p.pos+1]
+		p.pos += 2
+		switch esc {
+		case '"', '\\', '/':
+			b.WriteByte(esc)
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'u':
+			if p.pos+4 > len(p.src) {
+				p.fail(loc, "invalid unicode escape")
+			}
+			r, err := strconv.ParseUint(p.src[p.pos:p.pos+4], 16, 32)
+			if err != nil {
+				p.fail(loc, "invalid unicode escape")
+			}
+			b.WriteRune(rune(r))
+			p.pos += 4
+		default:
+			p.fail(loc, "invalid escape sequence \\"+string(esc))
+		}
+	}
+}
+
+// lexBlockString lexes a """block string""", and returns its value with its common indentation and
+// leading and trailing blank lines removed.
+func (p *parser) lexBlockString(loc location) string {
+	p.pos += 3
+	raw := strings.Builder{}
+	for {
+		if p.pos >= len(p.src) {
+			p.fail(loc, "unterminated block string")
+		}
+		switch {
+		case strings.HasPrefix(p.src[p.pos:], `"""`):
+			p.pos += 3
+			return blockStringValue(raw.String())
+		case strings.HasPrefix(p.src[p.pos:], `\"""`):
+			raw.WriteString(`"""`)
+			p.pos += 4
+		default:
+			c := p.src[p.pos]
+			raw.WriteByte(c)
+			p.pos++
+			if c == '\n' || c == '\r' && (p.pos >= len(p.src) || p.src[p.pos] != '\n') {
+				p.newLine()
+			}
+		}
+	}
+}
+
+func blockStringValue(raw string) string {
+	lines := strings.Split(strings.Replace(strings.Replace(raw, "\r\n", "\n", -1), "\r", "\n", -1), "\n")
+	indent := -1
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if n := len(line) - len(trimmed); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	if indent > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= indent {
+				lines[i] = lines[i][indent:]
+			} else {
+				lines[i] = ""
+			}
+		}
+	}
+	for len(lines) > 0 && strings.TrimLeft(lines[0], " \t") == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimLeft(lines[len(lines)-1], " \t") == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
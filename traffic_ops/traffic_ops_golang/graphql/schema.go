@@ -0,0 +1,327 @@
+package graphql
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Type is a GraphQL type: a *Scalar, *Object, *List or *NonNull.
+type Type interface {
+	String() string
+}
+
+// Scalar is a leaf type, whose values are serialized as JSON.
+type Scalar struct {
+	Name        string
+	Description string
+	// Serialize returns the value a resolver returned as a JSON value, and false if it isn't a
+	// valid value of the type.
+	Serialize func(v interface{}) (interface{}, bool)
+	// Parse returns the value of an argument or variable, as decoded from a query or JSON, and false
+	// if it isn't a valid value of the type.
+	Parse func(v interface{}) (interface{}, bool)
+}
+
+func (t *Scalar) String() string {
+	return t.Name
+}
+
+// Object is a type with fields.
+type Object struct {
+	Name        string
+	Description string
+	Fields      []*Field
+}
+
+func (t *Object) String() string {
+	return t.Name
+}
+
+// Field returns the field of the Object with the given name, or nil if it has none.
+func (t *Object) Field(name string) *Field {
+	for _, f := range t.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// List is a list of values of the type Of.
+type List struct {
+	Of Type
+}
+
+func (t *List) String() string {
+	return "[" + t.Of.String() + "]"
+}
+
+// NonNull is a value of the type Of which is never null.
+type NonNull struct {
+	Of Type
+}
+
+func (t *NonNull) String() string {
+	return t.Of.String() + "!"
+}
+
+// Field is a field of an Object.
+type Field struct {
+	Name        string
+	Description string
+	Type        Type
+	Args        []*Argument
+	// Resolve returns the value of the field of the Source object. If it's nil, the value is the
+	// Source's value with the same name, if it's a map, or its struct field with that JSON name.
+	Resolve func(p ResolveParams) (interface{}, error)
+	// ListSize is the estimated number of values of a list field, which the cost of the values'
+	// fields is multiplied by; a "limit" argument takes precedence. Defaults to 1.
+	ListSize int
+}
+
+// Arg returns the argument of the Field with the given name, or nil if it has none.
+func (f *Field) Arg(name string) *Argument {
+	for _, a := range f.Args {
+		if a.Name == name {
+			return a
+		}
+	}
+	return nil
+}
+
+// Argument is an argument of a Field.
+type Argument struct {
+	Name        string
+	Description string
+	Type        Type
+	// DefaultValue is the argument's value when it isn't given, if it isn't nil.
+	DefaultValue interface{}
+}
+
+// ResolveParams is what a Field's Resolve func is passed.
+type ResolveParams struct {
+	Context context.Context
+	// Source is the value of the object the field is of, which is nil for fields of the query type.
+	Source interface{}
+	// Args is the arguments of the field which were given, or have default values, as ints,
+	// float64s, strings, bools, []interface{}s and nils.
+	Args map[string]interface{}
+}
+
+// Schema is a GraphQL schema, which only supports queries.
+type Schema struct {
+	Query *Object
+	// Authorize, if not nil, returns an error if the context isn't authorized to query the object
+	// type. It's called for each type a query selects, before it's executed.
+	Authorize func(ctx context.Context, t *Object) error
+}
+
+// Int is a signed 32-bit integer.
+var Int = &Scalar{
+	Name:        "Int",
+	Description: "The Int scalar type represents non-fractional signed whole numeric values.",
+	Serialize: func(v interface{}) (interface{}, bool) {
+		switch i := v.(type) {
+		case int:
+			return i, i >= math.MinInt32 && i <= math.MaxInt32
+		case int64:
+			return i, i >= math.MinInt32 && i <= math.MaxInt32
+		case uint64:
+			return i, i <= math.MaxInt32
+		}
+		return nil, false
+	},
+	Parse: func(v interface{}) (interface{}, bool) {
+		switch i := v.(type) {
+		case int:
+			return i, i >= math.MinInt32 && i <= math.MaxInt32
+		case float64:
+			return int(i), i == math.Trunc(i) && i >= math.MinInt32 && i <= math.MaxInt32
+		}
+		return nil, false
+	},
+}
+
+// Float is a double-precision floating point number.
+var Float = &Scalar{
+	Name:        "Float",
+	Description: "The Float scalar type represents signed double-precision fractional values.",
+	Serialize: func(v interface{}) (interface{}, bool) {
+		switch f := v.(type) {
+		case float64:
+			return f, true
+		case float32:
+			return float64(f), true
+		case int:
+			return float64(f), true
+		}
+		return nil, false
+	},
+	Parse: func(v interface{}) (interface{}, bool) {
+		switch f := v.(type) {
+		case float64:
+			return f, true
+		case int:
+			return float64(f), true
+		}
+		return nil, false
+	},
+}
+
+// String is a UTF-8 string.
+var String = &Scalar{
+	Name:        "String",
+	Description: "The String scalar type represents textual data.",
+	Serialize: func(v interface{}) (interface{}, bool) {
+		s, ok := v.(string)
+		return s, ok
+	},
+	Parse: func(v interface{}) (interface{}, bool) {
+		s, ok := v.(string)
+		return s, ok
+	},
+}
+
+// Boolean is true or false.
+var Boolean = &Scalar{
+	Name:        "Boolean",
+	Description: "The Boolean scalar type represents true or false.",
+	Serialize: func(v interface{}) (interface{}, bool) {
+		b, ok := v.(bool)
+		return b, ok
+	},
+	Parse: func(v interface{}) (interface{}, bool) {
+		b, ok := v.(bool)
+		return b, ok
+	},
+}
+
+// ID is a unique identifier, serialized as a string.
+var ID = &Scalar{
+	Name:        "ID",
+	Description: "The ID scalar type represents a unique identifier.",
+	Serialize: func(v interface{}) (interface{}, bool) {
+		switch id := v.(type) {
+		case string:
+			return id, true
+		case int:
+			return strconv.Itoa(id), true
+		}
+		return nil, false
+	},
+	Parse: func(v interface{}) (interface{}, bool) {
+		switch id := v.(type) {
+		case string:
+			return id, true
+		case int:
+			return strconv.Itoa(id), true
+		case float64:
+			return strconv.FormatFloat(id, 'f', -1, 64), id == math.Trunc(id)
+		}
+		return nil, false
+	},
+}
+
+// namedType returns the Scalar or Object a type is, or is a list of.
+func namedType(t Type) Type {
+	for {
+		switch wrapper := t.(type) {
+		case *List:
+			t = wrapper.Of
+		case *NonNull:
+			t = wrapper.Of
+		default:
+			return t
+		}
+	}
+}
+
+// SDL returns the schema in the GraphQL schema definition language.
+func (s *Schema) SDL() string {
+	objects := []*Object{}
+	scalars := map[string]bool{}
+	seen := map[string]bool{}
+	var visit func(t *Object)
+	visit = func(t *Object) {
+		if seen[t.Name] {
+			return
+		}
+		seen[t.Name] = true
+		objects = append(objects, t)
+		for _, f := range t.Fields {
+			for _, a := range f.Args {
+				scalars[namedType(a.Type).String()] = true
+			}
+			switch ft := namedType(f.Type).(type) {
+			case *Object:
+				visit(ft)
+			case *Scalar:
+				scalars[ft.Name] = true
+			}
+		}
+	}
+	visit(s.Query)
+
+	b := strings.Builder{}
+	b.WriteString("schema {\n  query: " + s.Query.Name + "\n}\n")
+	for _, t := range objects {
+		b.WriteString("\n")
+		writeDescription(&b, "", t.Description)
+		b.WriteString("type " + t.Name + " {\n")
+		for _, f := range t.Fields {
+			writeDescription(&b, "  ", f.Description)
+			b.WriteString("  " + f.Name)
+			if len(f.Args) > 0 {
+				args := []string{}
+				for _, a := range f.Args {
+					args = append(args, a.Name+": "+a.Type.String())
+				}
+				b.WriteString("(" + strings.Join(args, ", ") + ")")
+			}
+			b.WriteString(": " + f.Type.String() + "\n")
+		}
+		b.WriteString("}\n")
+	}
+	names := []string{}
+	for name := range scalars {
+		switch name {
+		case "Int", "Float", "String", "Boolean", "ID":
+		default:
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b.WriteString("\nscalar " + name + "\n")
+	}
+	return b.String()
+}
+
+func writeDescription(b *strings.Builder, indent string, description string) {
+	if description == "" {
+		return
+	}
+	b.WriteString(indent + `"""` + strings.Replace(description, `"""`, `\"""`, -1) + `"""` + "\n")
+}
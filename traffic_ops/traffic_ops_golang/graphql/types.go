@@ -0,0 +1,332 @@
+package graphql
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+)
+
+// Estimated numbers of the values of list fields, which the costs of queries are calculated with;
+// see Field.ListSize.
+const (
+	rootListSize          = 100
+	cdnServersSize        = 100
+	cdnDeliveryServices   = 100
+	cdnProfilesSize       = 20
+	cacheGroupServersSize = 20
+	profileParametersSize = 50
+	profileServersSize    = 50
+	profileDSesSize       = 20
+	parameterProfilesSize = 10
+	serverDSesSize        = 20
+	dsServersSize         = 50
+)
+
+// The object types of the schema, whose fields are set by init, because they refer to each other.
+var (
+	cdnType             = &Object{Name: "CDN", Description: "A CDN."}
+	cacheGroupType      = &Object{Name: "CacheGroup", Description: "A Cache Group."}
+	profileType         = &Object{Name: "Profile", Description: "A Profile."}
+	parameterType       = &Object{Name: "Parameter", Description: "A Parameter."}
+	serverType          = &Object{Name: "Server", Description: "A server."}
+	deliveryServiceType = &Object{Name: "DeliveryService", Description: "A Delivery Service. Only those of the user's tenants can be queried."}
+	queryType           = &Object{Name: "Query"}
+)
+
+// schema is the schema of the Traffic Ops GraphQL API.
+var schema = &Schema{Query: queryType, Authorize: authorize}
+
+func nonNull(t Type) Type {
+	return &NonNull{Of: t}
+}
+
+func listOf(t Type) Type {
+	return &NonNull{Of: &List{Of: &NonNull{Of: t}}}
+}
+
+func arg(name string, t Type, description string) *Argument {
+	return &Argument{Name: name, Type: t, Description: description}
+}
+
+var limitArg = arg("limit", Int, "The maximum number of objects to return.")
+
+// filterArgs returns the arguments of a list field which filter it by the given columns, which
+// may be compared to their values.
+func filterArgs(args map[string]Type) []*Argument {
+	defs := []*Argument{}
+	for _, name := range sortedKeys(args) {
+		defs = append(defs, arg(name, args[name], ""))
+	}
+	return append(defs, limitArg)
+}
+
+func sortedKeys(m map[string]Type) []string {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// oneOf returns the conditions of a field which looks up one object by one of its arguments, or an
+// error if not exactly one is given.
+func oneOf(args map[string]interface{}, columns map[string]string) (*conditions, error) {
+	c := (&conditions{}).filter(args, columns)
+	if len(c.clauses) != 1 {
+		return nil, errors.New("exactly one argument is required")
+	}
+	return c, nil
+}
+
+// first returns the first of a slice of objects, or nil if it's empty.
+func first(objs interface{}, err error) (interface{}, error) {
+	if err != nil {
+		return nil, err
+	}
+	if v := reflect.ValueOf(objs); v.Len() > 0 {
+		return v.Index(0).Interface(), nil
+	}
+	return nil, nil
+}
+
+var (
+	cdnColumns             = map[string]string{"id": "cdn.id", "name": "cdn.name", "domainName": "cdn.domain_name"}
+	cacheGroupColumns      = map[string]string{"id": "cg.id", "name": "cg.name", "shortName": "cg.short_name", "type": "t.name"}
+	profileColumns         = map[string]string{"id": "p.id", "name": "p.name", "type": "p.type", "cdn": "cdn.name"}
+	parameterColumns       = map[string]string{"id": "pa.id", "name": "pa.name", "configFile": "pa.config_file"}
+	serverColumns          = map[string]string{"id": "s.id", "hostName": "s.host_name", "cdn": "cdn.name", "cachegroup": "cg.name", "profile": "p.name", "type": "t.name", "status": "st.name"}
+	deliveryServiceColumns = map[string]string{"id": "ds.id", "xmlId": "ds.xml_id", "cdn": "cdn.name", "type": "t.name", "tenant": "tn.name", "active": "ds.active"}
+)
+
+func init() {
+	cdnType.Fields = []*Field{
+		{Name: "id", Type: nonNull(Int)},
+		{Name: "name", Type: nonNull(String)},
+		{Name: "domainName", Type: nonNull(String)},
+		{Name: "dnssecEnabled", Type: nonNull(Boolean)},
+		{Name: "lastUpdated", Type: nonNull(Time)},
+		{Name: "servers", Type: listOf(serverType), ListSize: cdnServersSize, Resolve: func(p ResolveParams) (interface{}, error) {
+			return loaderFrom(p.Context).loadServers(selectServers, (&conditions{}).add("s.cdn_id = ?", p.Source.(*cdn).ID), nil)
+		}},
+		{Name: "deliveryServices", Type: listOf(deliveryServiceType), ListSize: cdnDeliveryServices, Resolve: func(p ResolveParams) (interface{}, error) {
+			return loaderFrom(p.Context).loadDeliveryServices(selectDeliveryServices, (&conditions{}).add("ds.cdn_id = ?", p.Source.(*cdn).ID), nil)
+		}},
+		{Name: "profiles", Type: listOf(profileType), ListSize: cdnProfilesSize, Resolve: func(p ResolveParams) (interface{}, error) {
+			return loaderFrom(p.Context).loadProfiles((&conditions{}).add("p.cdn = ?", p.Source.(*cdn).ID), nil)
+		}},
+	}
+
+	cacheGroupType.Fields = []*Field{
+		{Name: "id", Type: nonNull(Int)},
+		{Name: "name", Type: nonNull(String)},
+		{Name: "shortName", Type: nonNull(String)},
+		{Name: "latitude", Type: Float},
+		{Name: "longitude", Type: Float},
+		{Name: "typeName", Type: nonNull(String)},
+		{Name: "fallbackToClosest", Type: Boolean},
+		{Name: "lastUpdated", Type: nonNull(Time)},
+		{Name: "parentCachegroup", Type: cacheGroupType, Resolve: func(p ResolveParams) (interface{}, error) {
+			return loaderFrom(p.Context).cacheGroup(p.Source.(*cacheGroup).ParentCachegroupID)
+		}},
+		{Name: "secondaryParentCachegroup", Type: cacheGroupType, Resolve: func(p ResolveParams) (interface{}, error) {
+			return loaderFrom(p.Context).cacheGroup(p.Source.(*cacheGroup).SecondaryParentCachegroupID)
+		}},
+		{Name: "servers", Type: listOf(serverType), ListSize: cacheGroupServersSize, Resolve: func(p ResolveParams) (interface{}, error) {
+			return loaderFrom(p.Context).loadServers(selectServers, (&conditions{}).add("s.cachegroup = ?", p.Source.(*cacheGroup).ID), nil)
+		}},
+	}
+
+	profileType.Fields = []*Field{
+		{Name: "id", Type: nonNull(Int)},
+		{Name: "name", Type: nonNull(String)},
+		{Name: "description", Type: String},
+		{Name: "type", Type: nonNull(String)},
+		{Name: "routingDisabled", Type: nonNull(Boolean)},
+		{Name: "lastUpdated", Type: nonNull(Time)},
+		{Name: "cdn", Type: nonNull(cdnType), Resolve: func(p ResolveParams) (interface{}, error) {
+			return loaderFrom(p.Context).cdn(p.Source.(*profile).CDNID)
+		}},
+		{Name: "parameters", Type: listOf(parameterType), ListSize: profileParametersSize, Resolve: func(p ResolveParams) (interface{}, error) {
+			query := selectParameters + "\nJOIN profile_parameter pp ON pp.parameter = pa.id"
+			return loaderFrom(p.Context).loadParameters(query, (&conditions{}).add("pp.profile = ?", p.Source.(*profile).ID), nil)
+		}},
+		{Name: "servers", Type: listOf(serverType), ListSize: profileServersSize, Resolve: func(p ResolveParams) (interface{}, error) {
+			return loaderFrom(p.Context).loadServers(selectServers, (&conditions{}).add("s.profile = ?", p.Source.(*profile).ID), nil)
+		}},
+		{Name: "deliveryServices", Type: listOf(deliveryServiceType), ListSize: profileDSesSize, Resolve: func(p ResolveParams) (interface{}, error) {
+			return loaderFrom(p.Context).loadDeliveryServices(selectDeliveryServices, (&conditions{}).add("ds.profile = ?", p.Source.(*profile).ID), nil)
+		}},
+	}
+
+	parameterType.Fields = []*Field{
+		{Name: "id", Type: nonNull(Int)},
+		{Name: "name", Type: nonNull(String)},
+		{Name: "configFile", Type: String},
+		{Name: "value", Type: nonNull(String), Description: "The value, which is hidden if the Parameter is secure and the user isn't an admin."},
+		{Name: "secure", Type: nonNull(Boolean)},
+		{Name: "lastUpdated", Type: nonNull(Time)},
+		{Name: "profiles", Type: listOf(profileType), ListSize: parameterProfilesSize, Resolve: func(p ResolveParams) (interface{}, error) {
+			c := (&conditions{}).add("p.id IN (SELECT pp.profile FROM profile_parameter pp WHERE pp.parameter = ?)", p.Source.(*param).ID)
+			return loaderFrom(p.Context).loadProfiles(c, nil)
+		}},
+	}
+
+	serverType.Fields = []*Field{
+		{Name: "id", Type: nonNull(Int)},
+		{Name: "hostName", Type: nonNull(String)},
+		{Name: "domainName", Type: nonNull(String)},
+		{Name: "tcpPort", Type: Int},
+		{Name: "httpsPort", Type: Int},
+		{Name: "ipAddress", Type: String},
+		{Name: "ip6Address", Type: String},
+		{Name: "interfaceName", Type: nonNull(String)},
+		{Name: "interfaceMtu", Type: nonNull(Int)},
+		{Name: "type", Type: nonNull(String)},
+		{Name: "status", Type: nonNull(String)},
+		{Name: "physLocation", Type: nonNull(String)},
+		{Name: "rack", Type: String},
+		{Name: "offlineReason", Type: String},
+		{Name: "updPending", Type: nonNull(Boolean)},
+		{Name: "revalPending", Type: nonNull(Boolean)},
+		{Name: "xmppId", Type: String},
+		{Name: "lastUpdated", Type: nonNull(Time)},
+		{Name: "cdn", Type: nonNull(cdnType), Resolve: func(p ResolveParams) (interface{}, error) {
+			return loaderFrom(p.Context).cdn(p.Source.(*srv).CDNID)
+		}},
+		{Name: "cachegroup", Type: nonNull(cacheGroupType), Resolve: func(p ResolveParams) (interface{}, error) {
+			return loaderFrom(p.Context).cacheGroup(&p.Source.(*srv).CachegroupID)
+		}},
+		{Name: "profile", Type: nonNull(profileType), Resolve: func(p ResolveParams) (interface{}, error) {
+			return loaderFrom(p.Context).profile(&p.Source.(*srv).ProfileID)
+		}},
+		{Name: "deliveryServices", Type: listOf(deliveryServiceType), ListSize: serverDSesSize, Description: "The Delivery Services the server is assigned to.", Resolve: func(p ResolveParams) (interface{}, error) {
+			query := selectDeliveryServices + "\nJOIN deliveryservice_server dss ON dss.deliveryservice = ds.id"
+			return loaderFrom(p.Context).loadDeliveryServices(query, (&conditions{}).add("dss.server = ?", p.Source.(*srv).ID), nil)
+		}},
+	}
+
+	deliveryServiceType.Fields = []*Field{
+		{Name: "id", Type: nonNull(Int)},
+		{Name: "xmlId", Type: nonNull(String)},
+		{Name: "displayName", Type: nonNull(String)},
+		{Name: "active", Type: nonNull(Boolean)},
+		{Name: "type", Type: nonNull(String)},
+		{Name: "routingName", Type: nonNull(String)},
+		{Name: "protocol", Type: Int},
+		{Name: "qstringIgnore", Type: Int},
+		{Name: "ipv6RoutingEnabled", Type: Boolean},
+		{Name: "longDesc", Type: String},
+		{Name: "infoUrl", Type: String},
+		{Name: "tenantId", Type: Int},
+		{Name: "tenant", Type: String},
+		{Name: "lastUpdated", Type: nonNull(Time)},
+		{Name: "cdn", Type: nonNull(cdnType), Resolve: func(p ResolveParams) (interface{}, error) {
+			return loaderFrom(p.Context).cdn(p.Source.(*deliveryService).CDNID)
+		}},
+		{Name: "profile", Type: profileType, Resolve: func(p ResolveParams) (interface{}, error) {
+			return loaderFrom(p.Context).profile(p.Source.(*deliveryService).ProfileID)
+		}},
+		{Name: "servers", Type: listOf(serverType), ListSize: dsServersSize, Description: "The servers assigned to the Delivery Service.", Resolve: func(p ResolveParams) (interface{}, error) {
+			query := selectServers + "\nJOIN deliveryservice_server dss ON dss.server = s.id"
+			return loaderFrom(p.Context).loadServers(query, (&conditions{}).add("dss.deliveryservice = ?", p.Source.(*deliveryService).ID), nil)
+		}},
+	}
+
+	queryType.Fields = []*Field{
+		{Name: "cdns", Type: listOf(cdnType), ListSize: rootListSize,
+			Args: filterArgs(map[string]Type{"id": Int, "name": String, "domainName": String}),
+			Resolve: func(p ResolveParams) (interface{}, error) {
+				return loaderFrom(p.Context).loadCDNs((&conditions{}).filter(p.Args, cdnColumns), p.Args["limit"])
+			}},
+		{Name: "cdn", Type: cdnType,
+			Args: []*Argument{arg("id", Int, ""), arg("name", String, "")},
+			Resolve: func(p ResolveParams) (interface{}, error) {
+				c, err := oneOf(p.Args, cdnColumns)
+				if err != nil {
+					return nil, err
+				}
+				return first(loaderFrom(p.Context).loadCDNs(c, nil))
+			}},
+		{Name: "cacheGroups", Type: listOf(cacheGroupType), ListSize: rootListSize,
+			Args: filterArgs(map[string]Type{"id": Int, "name": String, "shortName": String, "type": String}),
+			Resolve: func(p ResolveParams) (interface{}, error) {
+				return loaderFrom(p.Context).loadCacheGroups((&conditions{}).filter(p.Args, cacheGroupColumns), p.Args["limit"])
+			}},
+		{Name: "cacheGroup", Type: cacheGroupType,
+			Args: []*Argument{arg("id", Int, ""), arg("name", String, "")},
+			Resolve: func(p ResolveParams) (interface{}, error) {
+				c, err := oneOf(p.Args, cacheGroupColumns)
+				if err != nil {
+					return nil, err
+				}
+				return first(loaderFrom(p.Context).loadCacheGroups(c, nil))
+			}},
+		{Name: "profiles", Type: listOf(profileType), ListSize: rootListSize,
+			Args: filterArgs(map[string]Type{"id": Int, "name": String, "type": String, "cdn": String}),
+			Resolve: func(p ResolveParams) (interface{}, error) {
+				return loaderFrom(p.Context).loadProfiles((&conditions{}).filter(p.Args, profileColumns), p.Args["limit"])
+			}},
+		{Name: "profile", Type: profileType,
+			Args: []*Argument{arg("id", Int, ""), arg("name", String, "")},
+			Resolve: func(p ResolveParams) (interface{}, error) {
+				c, err := oneOf(p.Args, profileColumns)
+				if err != nil {
+					return nil, err
+				}
+				return first(loaderFrom(p.Context).loadProfiles(c, nil))
+			}},
+		{Name: "parameters", Type: listOf(parameterType), ListSize: rootListSize,
+			Args: filterArgs(map[string]Type{"id": Int, "name": String, "configFile": String}),
+			Resolve: func(p ResolveParams) (interface{}, error) {
+				return loaderFrom(p.Context).loadParameters(selectParameters, (&conditions{}).filter(p.Args, parameterColumns), p.Args["limit"])
+			}},
+		{Name: "servers", Type: listOf(serverType), ListSize: rootListSize,
+			Args: filterArgs(map[string]Type{"id": Int, "hostName": String, "cdn": String, "cachegroup": String, "profile": String, "type": String, "status": String}),
+			Resolve: func(p ResolveParams) (interface{}, error) {
+				return loaderFrom(p.Context).loadServers(selectServers, (&conditions{}).filter(p.Args, serverColumns), p.Args["limit"])
+			}},
+		{Name: "server", Type: serverType,
+			Args: []*Argument{arg("id", Int, ""), arg("hostName", String, "")},
+			Resolve: func(p ResolveParams) (interface{}, error) {
+				c, err := oneOf(p.Args, serverColumns)
+				if err != nil {
+					return nil, err
+				}
+				return first(loaderFrom(p.Context).loadServers(selectServers, c, nil))
+			}},
+		{Name: "deliveryServices", Type: listOf(deliveryServiceType), ListSize: rootListSize,
+			Args: filterArgs(map[string]Type{"id": Int, "xmlId": String, "cdn": String, "type": String, "tenant": String, "active": Boolean}),
+			Resolve: func(p ResolveParams) (interface{}, error) {
+				return loaderFrom(p.Context).loadDeliveryServices(selectDeliveryServices, (&conditions{}).filter(p.Args, deliveryServiceColumns), p.Args["limit"])
+			}},
+		{Name: "deliveryService", Type: deliveryServiceType,
+			Args: []*Argument{arg("id", Int, ""), arg("xmlId", String, "")},
+			Resolve: func(p ResolveParams) (interface{}, error) {
+				c, err := oneOf(p.Args, deliveryServiceColumns)
+				if err != nil {
+					return nil, err
+				}
+				return loaderFrom(p.Context).deliveryService(c)
+			}},
+	}
+}
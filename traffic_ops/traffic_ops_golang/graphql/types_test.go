@@ -0,0 +1,116 @@
+package graphql
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/parameter"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/test"
+	"github.com/jmoiron/sqlx"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+var testPrivLevels = map[string]int{
+	"CacheGroup":      auth.PrivLevelReadOnly,
+	"CDN":             auth.PrivLevelReadOnly,
+	"DeliveryService": auth.PrivLevelReadOnly,
+	"Parameter":       auth.PrivLevelOperations,
+	"Profile":         auth.PrivLevelReadOnly,
+	"Server":          auth.PrivLevelReadOnly,
+}
+
+func executeWithMock(t *testing.T, user *auth.CurrentUser, query string, expect func(sqlmock.Sqlmock)) (*Response, error) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	mock.ExpectBegin()
+	expect(mock)
+
+	l := newLoader(db.MustBegin(), user)
+	l.privLevels = testPrivLevels
+	resp, err := schema.Execute(withLoader(context.Background(), l), Request{Query: query}, Limits{MaxDepth: 10, MaxCost: 10000})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expected all queries to be made, actual: %v", err)
+	}
+	return resp, err
+}
+
+func TestParametersHideSecureValues(t *testing.T) {
+	for _, user := range []*auth.CurrentUser{
+		{UserName: "operator", PrivLevel: auth.PrivLevelOperations},
+		{UserName: "admin", PrivLevel: auth.PrivLevelAdmin},
+	} {
+		resp, err := executeWithMock(t, user, `{ parameters(name: "key") { name value secure } }`, func(mock sqlmock.Sqlmock) {
+			rows := sqlmock.NewRows(test.ColsFromStructByTag("db", param{}))
+			rows.AddRow(1, "key", "url_sig_a.config", "secret", true, time.Now())
+			mock.ExpectQuery("SELECT .* FROM parameter pa\\s+WHERE pa.name = \\$1").WithArgs("key").WillReturnRows(rows)
+		})
+		if err != nil {
+			t.Fatalf("expected no error, actual: %v", err)
+		}
+		expected := "secret"
+		if user.PrivLevel < auth.PrivLevelAdmin {
+			expected = parameter.HiddenField
+		}
+		bts, _ := json.Marshal(resp.Data)
+		if !strings.Contains(string(bts), `"value":"`+expected+`"`) {
+			t.Errorf("expected %s to see the value %s, actual: %s", user.UserName, expected, bts)
+		}
+	}
+}
+
+func TestDeliveryServicesTenancy(t *testing.T) {
+	user := &auth.CurrentUser{UserName: "tenant-user", PrivLevel: auth.PrivLevelReadOnly, TenantID: 2}
+	resp, err := executeWithMock(t, user, `{ a: deliveryServices { xmlId } b: deliveryServices(active: true) { xmlId } }`, func(mock sqlmock.Sqlmock) {
+		mock.ExpectQuery("WITH RECURSIVE").WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2).AddRow(3))
+		for i := 0; i < 2; i++ {
+			rows := sqlmock.NewRows(test.ColsFromStructByTag("db", deliveryService{}))
+			rows.AddRow(1, "ds1", "DS 1", true, "HTTP", "cdn", nil, nil, nil, nil, nil, 3, "tenant3", 1, nil, time.Now())
+			mock.ExpectQuery("SELECT .* FROM deliveryservice ds.*ds.tenant_id = ANY\\(\\$\\d\\)").WillReturnRows(rows)
+		}
+	})
+	if err != nil {
+		t.Fatalf("expected no error, actual: %v", err)
+	}
+	if len(resp.Errors) != 0 {
+		t.Errorf("expected no errors, actual: %v", resp.Errors[0].Message)
+	}
+}
+
+func TestAuthorizePrivLevels(t *testing.T) {
+	user := &auth.CurrentUser{UserName: "read-only", PrivLevel: auth.PrivLevelReadOnly}
+	_, err := executeWithMock(t, user, `{ profiles { name parameters { value } } }`, func(sqlmock.Sqlmock) {})
+	expected := "forbidden: insufficient privileges to query Parameter"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected error %s, actual: %v", expected, err)
+	}
+}
@@ -0,0 +1,263 @@
+package invalidationjobs
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
+
+	"github.com/lib/pq"
+)
+
+// primaryOriginsQuery selects only the Primary Origins of the Delivery Services of the given
+// Tenants, so that URLs are never matched to Delivery Services outside the user's tenancy.
+const primaryOriginsQuery = `
+SELECT origin.deliveryservice,
+       origin.protocol::text,
+       origin.fqdn,
+       origin.port
+FROM origin
+JOIN deliveryservice ds ON origin.deliveryservice = ds.id
+WHERE origin.is_primary
+AND ds.tenant_id = ANY($1)
+`
+
+// bulkJob is a single content invalidation job requested through CreateBulk.
+type bulkJob struct {
+	dsid  uint
+	regex string
+}
+
+// primaryOrigin is the scheme and authority of the Primary Origin of a Delivery Service.
+type primaryOrigin struct {
+	dsid   uint
+	scheme string
+	host   string
+	port   string
+}
+
+// Used by POST requests to `/jobs/bulk`, creates many content invalidation jobs at once, either as
+// a set of regular expressions applied to each of a set of Delivery Services, or from full URLs
+// that are matched to Delivery Services by their Primary Origins.
+//
+// Either every job is created, or none of them are.
+func CreateBulk(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, nil, nil)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	input := tc.InvalidationJobBulkInput{}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, errors.New("Unable to parse Invalidation Jobs"), fmt.Errorf("parsing jobs/bulk POST: %v", err))
+		return
+	}
+
+	if err := input.Validate(); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, err, nil)
+		return
+	}
+
+	ttl, err := (&tc.InvalidationJobInput{TTL: input.TTL}).TTLHours()
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("retrieving parsed TTL: %v", err))
+		return
+	}
+
+	dsids := make([]uint, 0, len(input.DeliveryServices))
+	for _, ds := range input.DeliveryServices {
+		ds := ds
+		dsid, err := (&tc.InvalidationJobInput{DeliveryService: &ds}).DSID(inf.Tx.Tx)
+		if err != nil {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, errors.New("deliveryServices: "+err.Error()), nil)
+			return
+		}
+		dsids = append(dsids, dsid)
+	}
+
+	jobs := make([]bulkJob, 0, len(input.Regexes)*len(dsids)+len(input.URLs))
+	for _, dsid := range dsids {
+		for _, regex := range input.Regexes {
+			jobs = append(jobs, bulkJob{dsid, regex})
+		}
+	}
+
+	if len(input.URLs) > 0 {
+		tenantIDs, err := tenant.GetUserTenantIDListTx(inf.Tx.Tx, inf.User.TenantID)
+		if err != nil {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("getting accessible tenants for user: %v", err))
+			return
+		}
+		origins, err := getPrimaryOrigins(inf.Tx.Tx, tenantIDs)
+		if err != nil {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("getting primary origins: %v", err))
+			return
+		}
+		urlJobs, err := urlsToJobs(input.URLs, origins, dsids)
+		if err != nil {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, err, nil)
+			return
+		}
+		jobs = append(jobs, urlJobs...)
+	}
+
+	authorized := map[uint]struct{}{}
+	for _, job := range jobs {
+		if _, ok := authorized[job.dsid]; ok {
+			continue
+		}
+		if ok, err := IsUserAuthorizedToModifyDSID(inf, job.dsid); err != nil {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("Checking current user permissions for DS #%d: %v", job.dsid, err))
+			return
+		} else if !ok {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("No such Delivery Service!"), nil)
+			return
+		}
+		authorized[job.dsid] = struct{}{}
+	}
+
+	results := make([]tc.InvalidationJob, 0, len(jobs))
+	for _, job := range jobs {
+		result, err := insertJob(inf.Tx.Tx, job.dsid, job.regex, ttl, input.StartTime.Time, inf.User.ID)
+		if err != nil {
+			userErr, sysErr, errCode = api.ParseDBError(err)
+			api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+			return
+		}
+		results = append(results, result)
+	}
+
+	for dsid := range authorized {
+		if userErr, sysErr, errCode := tenant.CheckDeliveryServiceQuota(inf.Tx.Tx, int(dsid), tenant.QuotaInvalidationJobsPerDay); userErr != nil || sysErr != nil {
+			api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+			return
+		}
+		if err := setRevalFlags(dsid, inf.Tx.Tx); err != nil {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("setting reval flags: %v", err))
+			return
+		}
+	}
+
+	for _, result := range results {
+		api.CreateChangeLogRawTx(api.ApiChange, api.Created+" content invalidation job: #"+strconv.FormatUint(*result.ID, 10), inf.User, inf.Tx.Tx)
+		if err := api.CreateEvent(inf.Tx.Tx, tc.EventTypeInvalidationJobCreated, inf.User, result); err != nil {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("creating event: %v", err))
+			return
+		}
+	}
+
+	api.WriteRespAlertObj(w, r, tc.SuccessLevel, fmt.Sprintf("Created %d content invalidation jobs", len(results)), results)
+}
+
+// getPrimaryOrigins returns the Primary Origins of the Delivery Services of the given Tenants.
+func getPrimaryOrigins(tx *sql.Tx, tenantIDs []int) ([]primaryOrigin, error) {
+	rows, err := tx.Query(primaryOriginsQuery, pq.Array(tenantIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	origins := []primaryOrigin{}
+	for rows.Next() {
+		o := primaryOrigin{}
+		port := sql.NullInt64{}
+		if err := rows.Scan(&o.dsid, &o.scheme, &o.host, &port); err != nil {
+			return nil, err
+		}
+		if port.Valid {
+			o.port = strconv.FormatInt(port.Int64, 10)
+		}
+		origins = append(origins, o)
+	}
+	return origins, rows.Err()
+}
+
+// urlsToJobs matches each of the given URLs to the Delivery Service(s) with a Primary Origin
+// serving it, returning a job invalidating exactly that URL on each. If dsids is not empty, only
+// those Delivery Services are considered. It is an error for a URL to match no Delivery Service.
+func urlsToJobs(urls []string, origins []primaryOrigin, dsids []uint) ([]bulkJob, error) {
+	allowed := map[uint]struct{}{}
+	for _, dsid := range dsids {
+		allowed[dsid] = struct{}{}
+	}
+
+	jobs := []bulkJob{}
+	errs := []string{}
+	for _, u := range urls {
+		regex, _, err := tc.URLRegex(u)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("urls: '%s' is not a valid URL: %v", u, err))
+			continue
+		}
+		parsed, _ := url.Parse(u) // URLRegex already checked this parses
+		matched := false
+		for _, o := range origins {
+			if len(allowed) > 0 {
+				if _, ok := allowed[o.dsid]; !ok {
+					continue
+				}
+			}
+			if !o.serves(parsed) {
+				continue
+			}
+			jobs = append(jobs, bulkJob{o.dsid, regex})
+			matched = true
+		}
+		if !matched {
+			errs = append(errs, fmt.Sprintf("urls: no Delivery Service has a Primary Origin matching '%s'", u))
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.New(strings.Join(errs, ", "))
+	}
+	return jobs, nil
+}
+
+// serves returns whether or not u has the same scheme and authority as the origin, treating a
+// missing port as the default port for the scheme.
+func (o primaryOrigin) serves(u *url.URL) bool {
+	if !strings.EqualFold(o.scheme, u.Scheme) || !strings.EqualFold(o.host, u.Hostname()) {
+		return false
+	}
+	return defaultPort(o.scheme, o.port) == defaultPort(u.Scheme, u.Port())
+}
+
+func defaultPort(scheme, port string) string {
+	if port != "" {
+		return port
+	}
+	if strings.EqualFold(scheme, "https") {
+		return "443"
+	}
+	return "80"
+}
@@ -0,0 +1,98 @@
+package invalidationjobs
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
+)
+
+func TestURLsToJobs(t *testing.T) {
+	origins := []primaryOrigin{
+		{dsid: 1, scheme: "http", host: "origin.example.net"},
+		{dsid: 2, scheme: "https", host: "origin.example.net", port: "443"},
+		{dsid: 3, scheme: "http", host: "other.example.net", port: "8080"},
+		{dsid: 4, scheme: "http", host: "origin.example.net", port: "80"},
+	}
+
+	jobs, err := urlsToJobs([]string{
+		"http://origin.example.net/foo/bar.png",
+		"https://origin.example.net/a?b=c",
+		"http://other.example.net:8080/",
+	}, origins, nil)
+	if err != nil {
+		t.Fatalf("urlsToJobs unexpected error: %v", err)
+	}
+	expected := []bulkJob{
+		{1, `/foo/bar\.png`},
+		{4, `/foo/bar\.png`},
+		{2, `/a\?b=c`},
+		{3, `/`},
+	}
+	if !reflect.DeepEqual(jobs, expected) {
+		t.Errorf("urlsToJobs expected: %+v, actual: %+v", expected, jobs)
+	}
+
+	jobs, err = urlsToJobs([]string{"http://origin.example.net/foo"}, origins, []uint{4})
+	if err != nil {
+		t.Fatalf("urlsToJobs unexpected error: %v", err)
+	}
+	if expected := []bulkJob{{4, `/foo`}}; !reflect.DeepEqual(jobs, expected) {
+		t.Errorf("urlsToJobs restricted to DS #4 expected: %+v, actual: %+v", expected, jobs)
+	}
+
+	if _, err := urlsToJobs([]string{"http://other.example.net/foo"}, origins, nil); err == nil {
+		t.Error("urlsToJobs with a URL matching no origin expected: error, actual: nil")
+	}
+	if _, err := urlsToJobs([]string{"http://other.example.net:8080/foo"}, origins, []uint{1}); err == nil {
+		t.Error("urlsToJobs with a URL matching an origin of an unlisted DS expected: error, actual: nil")
+	}
+}
+
+func TestJobState(t *testing.T) {
+	now := time.Now()
+	status := func(start time.Time, ttl string, assigned, applied int) tc.InvalidationJobStatus {
+		s := tc.InvalidationJobStatus{AssignedCaches: assigned, AppliedCaches: applied}
+		s.StartTime = &tc.Time{Time: start, Valid: true}
+		s.Parameters = util.StrPtr(ttl)
+		return s
+	}
+
+	type testCase struct {
+		status   tc.InvalidationJobStatus
+		expected string
+	}
+	testCases := []testCase{
+		{status(now.Add(time.Hour), "TTL:24h", 2, 0), tc.InvalidationJobStatePending},
+		{status(now.Add(-time.Hour), "TTL:24h", 2, 1), tc.InvalidationJobStateInProgress},
+		{status(now.Add(-time.Hour), "TTL:24h", 2, 2), tc.InvalidationJobStateComplete},
+		{status(now.Add(-time.Hour), "TTL:24h", 0, 0), tc.InvalidationJobStateComplete},
+		{status(now.Add(-25*time.Hour), "TTL:24h", 2, 1), tc.InvalidationJobStateExpired},
+	}
+	for i, c := range testCases {
+		if actual := jobState(c.status, now); actual != c.expected {
+			t.Errorf("case %d: jobState expected: %s, actual: %s", i, c.expected, actual)
+		}
+	}
+}
@@ -100,29 +100,35 @@ RETURNING
 	start_time
 `
 
+// revalServersWhere selects the servers that must apply content invalidation jobs for a
+// Delivery Service. It must be formatted with the name of the deliveryservice column used to
+// identify the Delivery Service, which is then given as the first query parameter.
+const revalServersWhere = `
+server.status NOT IN (
+                       SELECT status.id
+                       FROM status
+                       WHERE name IN ('OFFLINE', 'PRE_PROD')
+                     )
+AND server.profile IN (
+                       SELECT profile_parameter.profile
+                       FROM profile_parameter
+                       WHERE profile_parameter.parameter IN (
+                                                              SELECT parameter.id
+                                                              FROM parameter
+                                                              WHERE parameter.name='location'
+                                                               AND parameter.config_file='regex_revalidate.config'
+                                                            )
+                     )
+AND server.cdn_id  =  (
+                       SELECT deliveryservice.cdn_id
+                       FROM deliveryservice
+                       WHERE deliveryservice.%s=$1
+                     )
+`
+
 const revalQuery = `
 UPDATE server SET %s=TRUE
-WHERE server.status NOT IN (
-                             SELECT status.id
-                             FROM status
-                             WHERE name IN ('OFFLINE', 'PRE_PROD')
-                           )
-     AND server.profile IN (
-                             SELECT profile_parameter.profile
-                             FROM profile_parameter
-                             WHERE profile_parameter.parameter IN (
-                                                                    SELECT parameter.id
-                                                                    FROM parameter
-                                                                    WHERE parameter.name='location'
-                                                                     AND parameter.config_file='regex_revalidate.config'
-                                                                  )
-                           )
-     AND server.cdn_id  =  (
-                             SELECT deliveryservice.cdn_id
-                             FROM deliveryservice
-                             WHERE deliveryservice.%s=$1
-                           )
-`
+WHERE ` + revalServersWhere
 
 const updateQuery = `
 UPDATE job
@@ -329,34 +335,28 @@ func Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	row := inf.Tx.Tx.QueryRow(insertQuery,
-		dsid,
-		*job.Regex,
-		time.Now(),
-		dsid,
-		inf.User.ID,
-		fmt.Sprintf("TTL:%dh", ttl),
-		(*job.StartTime).Time)
-
-	result := tc.InvalidationJob{}
-	err = row.Scan(&result.AssetURL,
-		&result.DeliveryService,
-		&result.ID,
-		&result.CreatedBy,
-		&result.Keyword,
-		&result.Parameters,
-		&result.StartTime)
+	result, err := insertJob(inf.Tx.Tx, dsid, *job.Regex, ttl, (*job.StartTime).Time, inf.User.ID)
 	if err != nil {
 		userErr, sysErr, errCode = api.ParseDBError(err)
 		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
 		return
 	}
 
+	if userErr, sysErr, errCode := tenant.CheckDeliveryServiceQuota(inf.Tx.Tx, int(dsid), tenant.QuotaInvalidationJobsPerDay); userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+
 	if err := setRevalFlags(dsid, inf.Tx.Tx); err != nil {
 		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("setting reval flags: %v", err))
 		return
 	}
 
+	if err := api.CreateEvent(inf.Tx.Tx, tc.EventTypeInvalidationJobCreated, inf.User, result); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("creating event: %v", err))
+		return
+	}
+
 	resp, err := json.Marshal(apiResponse{[]tc.Alert{{"Invalidation Job creation was successful", tc.SuccessLevel.String()}}, result})
 	if err != nil {
 		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("Marshaling JSON: %v", err))
@@ -616,18 +616,9 @@ func Delete(w http.ResponseWriter, r *http.Request) {
 }
 
 func setRevalFlags(d interface{}, tx *sql.Tx) error {
-	var useReval string
-	row := tx.QueryRow(`SELECT value FROM parameter WHERE name='use_reval_pending' AND config_file='global'`)
-	if err := row.Scan(&useReval); err != nil {
-		if err != sql.ErrNoRows {
-			return err
-		}
-		useReval = "0"
-	}
-
-	col := "reval_pending"
-	if useReval == "0" {
-		col = "upd_pending"
+	col, err := revalColumn(tx)
+	if err != nil {
+		return err
 	}
 
 	var q string
@@ -640,13 +631,55 @@ func setRevalFlags(d interface{}, tx *sql.Tx) error {
 		return fmt.Errorf("Invalid type passed to 'setRevalFlags': %v", t)
 	}
 
-	row = tx.QueryRow(q, d)
+	row := tx.QueryRow(q, d)
 	if err := row.Scan(); err != nil && err != sql.ErrNoRows {
 		return err
 	}
 	return nil
 }
 
+// revalColumn returns the name of the server column used to queue revalidation, which is
+// "reval_pending" unless the global 'use_reval_pending' Parameter is "0", in which case it is
+// "upd_pending".
+func revalColumn(tx *sql.Tx) (string, error) {
+	var useReval string
+	row := tx.QueryRow(`SELECT value FROM parameter WHERE name='use_reval_pending' AND config_file='global'`)
+	if err := row.Scan(&useReval); err != nil {
+		if err != sql.ErrNoRows {
+			return "", err
+		}
+		useReval = "0"
+	}
+
+	if useReval == "0" {
+		return "upd_pending", nil
+	}
+	return "reval_pending", nil
+}
+
+// insertJob creates a new content invalidation job for the Delivery Service identified by dsid,
+// owned by the user identified by userID, returning the job as it was stored.
+func insertJob(tx *sql.Tx, dsid uint, regex string, ttlHours uint, startTime time.Time, userID int) (tc.InvalidationJob, error) {
+	row := tx.QueryRow(insertQuery,
+		dsid,
+		regex,
+		time.Now(),
+		dsid,
+		userID,
+		fmt.Sprintf("TTL:%dh", ttlHours),
+		startTime)
+
+	result := tc.InvalidationJob{}
+	err := row.Scan(&result.AssetURL,
+		&result.DeliveryService,
+		&result.ID,
+		&result.CreatedBy,
+		&result.Keyword,
+		&result.Parameters,
+		&result.StartTime)
+	return result, err
+}
+
 // Checks if the current user's (identified in the APIInfo) tenant has permissions to
 // edit a Delivery Service. `ds` is expected to be the integral, unique identifer of the
 // Delivery Service in question.
@@ -0,0 +1,214 @@
+package invalidationjobs
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Schedules that are being run are locked, so that any number of Traffic Ops instances may run
+// the scheduler at once without creating duplicate jobs. At most 100 are run at a time; any more
+// that are due are run on the next tick.
+const dueSchedulesQuery = `
+SELECT job_schedule.id,
+       job_schedule.deliveryservice,
+       job_schedule.regex,
+       job_schedule.ttl_hours,
+       job_schedule.cron,
+       job_schedule.job_user,
+       tm_user.username
+FROM job_schedule
+JOIN tm_user ON job_schedule.job_user = tm_user.id
+WHERE job_schedule.next_run <= now()
+ORDER BY job_schedule.next_run
+LIMIT 100
+FOR UPDATE OF job_schedule SKIP LOCKED
+`
+
+type dueSchedule struct {
+	id       uint64
+	dsid     uint
+	regex    string
+	ttlHours uint
+	cron     *string
+	user     auth.CurrentUser
+}
+
+// RunScheduler creates content invalidation jobs from deferred and recurring job schedules as they
+// come due, checking for due schedules every interval. It never returns, and is meant to be run in
+// its own goroutine.
+func RunScheduler(db *sqlx.DB, interval time.Duration) {
+	for range time.Tick(interval) {
+		if err := runDueSchedules(db); err != nil {
+			log.Errorln("running content invalidation job schedules: " + err.Error())
+		}
+	}
+}
+
+func runDueSchedules(db *sqlx.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.New("beginning transaction: " + err.Error())
+	}
+	commit := false
+	defer func() {
+		if !commit {
+			tx.Rollback()
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			log.Errorln("committing content invalidation job schedules: " + err.Error())
+		}
+	}()
+
+	schedules, err := getDueSchedules(tx)
+	if err != nil {
+		return errors.New("getting due schedules: " + err.Error())
+	}
+
+	now := time.Now()
+	for _, s := range schedules {
+		if err := runScheduleOrSkip(tx, s, now); err != nil {
+			return fmt.Errorf("running schedule #%d: %v", s.id, err)
+		}
+	}
+	commit = true
+	return nil
+}
+
+// runScheduleOrSkip runs the schedule in a savepoint of its own. If running it fails, the failure
+// is logged, the run is rolled back and the schedule is advanced anyway, so that one failing
+// schedule doesn't roll back the others, or block them by being selected again on every tick.
+// An error is only returned if the transaction can't continue.
+func runScheduleOrSkip(tx *sql.Tx, s dueSchedule, now time.Time) error {
+	if _, err := tx.Exec(`SAVEPOINT job_schedule`); err != nil {
+		return errors.New("creating savepoint: " + err.Error())
+	}
+	err := runSchedule(tx, s, now)
+	if err == nil {
+		return nil
+	}
+	log.Errorf("content invalidation job schedule #%d: skipping run: %v\n", s.id, err)
+	if _, err := tx.Exec(`ROLLBACK TO SAVEPOINT job_schedule`); err != nil {
+		return errors.New("rolling back to savepoint: " + err.Error())
+	}
+	return advanceSchedule(tx, s, now)
+}
+
+func getDueSchedules(tx *sql.Tx) ([]dueSchedule, error) {
+	rows, err := tx.Query(dueSchedulesQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schedules := []dueSchedule{}
+	for rows.Next() {
+		s := dueSchedule{}
+		if err := rows.Scan(&s.id, &s.dsid, &s.regex, &s.ttlHours, &s.cron, &s.user.ID, &s.user.UserName); err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, s)
+	}
+	return schedules, rows.Err()
+}
+
+// runSchedule creates a content invalidation job from the schedule, starting at now, then either
+// advances the schedule to its next run or, if it does not recur, removes it.
+//
+// Runs missed while no Traffic Ops was running the scheduler are not made up; a recurring schedule
+// only ever creates one job at a time. A run that would exceed the Delivery Service's Tenant's
+// invalidation job quota is skipped, but the schedule still advances.
+func runSchedule(tx *sql.Tx, s dueSchedule, now time.Time) error {
+	if err := createScheduledJob(tx, s, now); err != nil {
+		return err
+	}
+	return advanceSchedule(tx, s, now)
+}
+
+// advanceSchedule advances the schedule to its next run after now or, if it does not recur, removes
+// it.
+func advanceSchedule(tx *sql.Tx, s dueSchedule, now time.Time) error {
+	next := time.Time{}
+	if s.cron != nil {
+		sched, err := util.ParseCron(*s.cron)
+		if err != nil {
+			log.Errorf("content invalidation job schedule #%d has invalid cron '%s', removing: %v\n", s.id, *s.cron, err)
+		} else {
+			next = sched.Next(now)
+		}
+	}
+
+	if next.IsZero() {
+		if _, err := tx.Exec(deleteScheduleQuery, s.id); err != nil {
+			return errors.New("removing finished schedule: " + err.Error())
+		}
+		return nil
+	}
+
+	if _, err := tx.Exec(`UPDATE job_schedule SET last_run=$1, next_run=$2 WHERE id=$3`, now, next, s.id); err != nil {
+		return errors.New("advancing schedule: " + err.Error())
+	}
+	return nil
+}
+
+// createScheduledJob creates the content invalidation job for one run of the schedule. If the job
+// would exceed a quota, it is removed again and the run is logged as skipped.
+func createScheduledJob(tx *sql.Tx, s dueSchedule, now time.Time) error {
+	if _, err := tx.Exec(`SAVEPOINT scheduled_job`); err != nil {
+		return errors.New("creating savepoint: " + err.Error())
+	}
+	job, err := insertJob(tx, s.dsid, s.regex, s.ttlHours, now, s.user.ID)
+	if err != nil {
+		return errors.New("inserting job: " + err.Error())
+	}
+	userErr, sysErr, _ := tenant.CheckDeliveryServiceQuota(tx, int(s.dsid), tenant.QuotaInvalidationJobsPerDay)
+	if sysErr != nil {
+		return errors.New("checking quota: " + sysErr.Error())
+	}
+	if userErr != nil {
+		if _, err := tx.Exec(`ROLLBACK TO SAVEPOINT scheduled_job`); err != nil {
+			return errors.New("rolling back to savepoint: " + err.Error())
+		}
+		log.Warnf("content invalidation job schedule #%d: skipping run: %v\n", s.id, userErr)
+		return nil
+	}
+	if err := setRevalFlags(s.dsid, tx); err != nil {
+		return errors.New("setting reval flags: " + err.Error())
+	}
+	api.CreateChangeLogRawTx(api.ApiChange, api.Created+" content invalidation job: #"+strconv.FormatUint(*job.ID, 10)+" from schedule #"+strconv.FormatUint(s.id, 10), &s.user, tx)
+	if err := api.CreateEvent(tx, tc.EventTypeInvalidationJobCreated, &s.user, job); err != nil {
+		return errors.New("creating event: " + err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package invalidationjobs
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestRunDueSchedulesSkipsFailingSchedules(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	cron := "0 * * * *"
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT job_schedule.id").WillReturnRows(sqlmock.NewRows([]string{"id", "deliveryservice", "regex", "ttl_hours", "cron", "job_user", "username"}).
+		AddRow(1, 1, "/a", 24, nil, 1, "admin").
+		AddRow(2, 2, "/b", 24, cron, 1, "admin"))
+
+	// the failure of each schedule's run is rolled back on its own, and the schedule still advances
+	mock.ExpectExec("SAVEPOINT job_schedule").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SAVEPOINT scheduled_job").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("INSERT INTO job").WillReturnError(errors.New("bad job"))
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT job_schedule").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM job_schedule").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectExec("SAVEPOINT job_schedule").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SAVEPOINT scheduled_job").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("INSERT INTO job").WillReturnError(errors.New("bad job"))
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT job_schedule").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE job_schedule SET last_run").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), 2).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := runDueSchedules(db); err != nil {
+		t.Fatalf("expected no error, actual: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expected all schedules to be advanced and committed, actual: %v", err)
+	}
+}
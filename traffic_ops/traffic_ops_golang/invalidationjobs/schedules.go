@@ -0,0 +1,237 @@
+package invalidationjobs
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/lib/pq"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
+)
+
+const readScheduleQuery = `
+SELECT job_schedule.id,
+       (
+        SELECT o.protocol::text || '://' || o.fqdn || rtrim(concat(':', o.port::text), ':')
+        FROM origin o
+        WHERE o.deliveryservice = job_schedule.deliveryservice
+        AND o.is_primary
+       ) || job_schedule.regex AS asset_url,
+       tm_user.username AS created_by,
+       deliveryservice.xml_id AS deliveryservice,
+       job_schedule.cron,
+       job_schedule.last_run,
+       job_schedule.last_updated,
+       job_schedule.next_run,
+       'TTL:' || job_schedule.ttl_hours || 'h' AS parameters
+FROM job_schedule
+JOIN tm_user ON job_schedule.job_user = tm_user.id
+JOIN deliveryservice ON job_schedule.deliveryservice = deliveryservice.id
+`
+
+const insertScheduleQuery = `
+INSERT INTO job_schedule (
+	deliveryservice,
+	regex,
+	ttl_hours,
+	cron,
+	next_run,
+	job_user)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id
+`
+
+const deleteScheduleQuery = `
+DELETE FROM job_schedule
+WHERE id=$1
+`
+
+// Used by GET requests to `/jobs/schedules`, returns a filtered list of the deferred and recurring
+// content invalidation jobs on Delivery Services the user may see.
+func GetSchedules(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, nil, nil)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	queryParamsToSQLCols := map[string]dbhelpers.WhereColumnInfo{
+		"id":              dbhelpers.WhereColumnInfo{"job_schedule.id", api.IsInt},
+		"userId":          dbhelpers.WhereColumnInfo{"job_schedule.job_user", api.IsInt},
+		"createdBy":       dbhelpers.WhereColumnInfo{"tm_user.username", nil},
+		"deliveryService": dbhelpers.WhereColumnInfo{"deliveryservice.xml_id", nil},
+		"dsId":            dbhelpers.WhereColumnInfo{"job_schedule.deliveryservice", api.IsInt},
+	}
+	where, orderBy, pagination, queryValues, errs := dbhelpers.BuildWhereAndOrderByAndPagination(inf.Params, queryParamsToSQLCols)
+	if len(errs) > 0 {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, util.JoinErrs(errs), nil)
+		return
+	}
+
+	accessibleTenants, err := tenant.GetUserTenantIDListTx(inf.Tx.Tx, inf.User.TenantID)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("getting accessible tenants for user: %v", err))
+		return
+	}
+	if where == "" {
+		where = dbhelpers.BaseWhere + " deliveryservice.tenant_id = ANY(:tenants) "
+	} else {
+		where += " AND deliveryservice.tenant_id = ANY(:tenants) "
+	}
+	queryValues["tenants"] = pq.Array(accessibleTenants)
+
+	rows, err := inf.Tx.NamedQuery(readScheduleQuery+where+orderBy+pagination, queryValues)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("querying job schedules: %v", err))
+		return
+	}
+	defer rows.Close()
+
+	schedules := []tc.InvalidationJobSchedule{}
+	for rows.Next() {
+		s := tc.InvalidationJobSchedule{}
+		if err := rows.StructScan(&s); err != nil {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("scanning job schedules: %v", err))
+			return
+		}
+		schedules = append(schedules, s)
+	}
+	if err := rows.Err(); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("scanning job schedules: %v", err))
+		return
+	}
+
+	api.WriteResp(w, r, schedules)
+}
+
+// Used by POST requests to `/jobs/schedules`, creates a new deferred or recurring content
+// invalidation job. Traffic Ops creates the actual content invalidation job(s) when they come due.
+func CreateSchedule(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, nil, nil)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	job := tc.InvalidationJobScheduleInput{}
+	if err := json.NewDecoder(r.Body).Decode(&job); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, errors.New("Unable to parse Invalidation Job Schedule"), fmt.Errorf("parsing jobs/schedules POST: %v", err))
+		return
+	}
+
+	if err := job.Validate(inf.Tx.Tx); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, err, nil)
+		return
+	}
+
+	dsid, err := job.DSID(nil)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("retrieving parsed DSID: %v", err))
+		return
+	}
+	ttl, err := job.TTLHours()
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("retrieving parsed TTL: %v", err))
+		return
+	}
+
+	if ok, err := IsUserAuthorizedToModifyDSID(inf, dsid); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("Checking current user permissions for DS #%d: %v", dsid, err))
+		return
+	} else if !ok {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("No such Delivery Service!"), nil)
+		return
+	}
+
+	var id uint64
+	if err := inf.Tx.Tx.QueryRow(insertScheduleQuery, dsid, *job.Regex, ttl, job.Cron, job.StartTime.Time, inf.User.ID).Scan(&id); err != nil {
+		userErr, sysErr, errCode = api.ParseDBError(err)
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+
+	result := tc.InvalidationJobSchedule{}
+	if err := inf.Tx.QueryRowx(readScheduleQuery+`WHERE job_schedule.id=$1`, id).StructScan(&result); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("reading created job schedule: %v", err))
+		return
+	}
+
+	api.CreateChangeLogRawTx(api.ApiChange, api.Created+" content invalidation job schedule: #"+strconv.FormatUint(id, 10), inf.User, inf.Tx.Tx)
+	api.WriteRespAlertObj(w, r, tc.SuccessLevel, "Invalidation Job schedule creation was successful", result)
+}
+
+// Used by DELETE requests to `/jobs/schedules`, deletes an existing deferred or recurring content
+// invalidation job. Content invalidation jobs it has already created are unaffected.
+func DeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"id"}, []string{"id"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	var dsid uint
+	var createdBy uint
+	row := inf.Tx.Tx.QueryRow(`SELECT deliveryservice, job_user FROM job_schedule WHERE id=$1`, inf.IntParams["id"])
+	if err := row.Scan(&dsid, &createdBy); err != nil {
+		if err == sql.ErrNoRows {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, fmt.Errorf("No job schedule by id '%s'!", inf.Params["id"]), nil)
+		} else {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("Getting info for job schedule #%s: %v", inf.Params["id"], err))
+		}
+		return
+	}
+
+	if ok, err := IsUserAuthorizedToModifyDSID(inf, dsid); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("Checking user permissions on DS #%d: %v", dsid, err))
+		return
+	} else if !ok {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("No such Delivery Service!"), nil)
+		return
+	}
+
+	if ok, err := IsUserAuthorizedToModifyJobsMadeByUserID(inf, createdBy); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("Checking user permissions against user %v: %v", createdBy, err))
+		return
+	} else if !ok {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, fmt.Errorf("No job schedule by id '%s'!", inf.Params["id"]), nil)
+		return
+	}
+
+	if _, err := inf.Tx.Tx.Exec(deleteScheduleQuery, inf.IntParams["id"]); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("deleting job schedule #%s: %v", inf.Params["id"], err))
+		return
+	}
+
+	api.CreateChangeLogRawTx(api.ApiChange, api.Deleted+" content invalidation job schedule: #"+strconv.Itoa(inf.IntParams["id"]), inf.User, inf.Tx.Tx)
+	api.WriteRespAlert(w, r, tc.SuccessLevel, "Content invalidation job schedule was deleted")
+}
@@ -0,0 +1,166 @@
+package invalidationjobs
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+)
+
+const statusJobQuery = `
+SELECT job.id,
+       job.keyword,
+       job.parameters,
+       job.asset_url,
+       job.start_time,
+       tm_user.username,
+       deliveryservice.xml_id,
+       deliveryservice.id,
+       deliveryservice.tenant_id
+FROM job
+JOIN tm_user ON job.job_user = tm_user.id
+JOIN deliveryservice ON job.job_deliveryservice = deliveryservice.id
+WHERE job.id = $1
+`
+
+const statusServersQuery = `
+SELECT server.host_name,
+       server.%s
+FROM server
+WHERE ` + revalServersWhere + `
+ORDER BY server.host_name
+`
+
+// Used by GET requests to `/jobs/{id}/status`, reports how far the identified content
+// invalidation job has propagated to the cache servers that must apply it.
+func GetStatus(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"id"}, []string{"id"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	status := tc.InvalidationJobStatus{}
+	var dsid uint
+	var tenantID int
+	err := inf.Tx.Tx.QueryRow(statusJobQuery, inf.IntParams["id"]).Scan(&status.ID,
+		&status.Keyword,
+		&status.Parameters,
+		&status.AssetURL,
+		&status.StartTime,
+		&status.CreatedBy,
+		&status.DeliveryService,
+		&dsid,
+		&tenantID)
+	if err == sql.ErrNoRows {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, fmt.Errorf("No job by id '%d'!", inf.IntParams["id"]), nil)
+		return
+	} else if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("fetching job for status: %v", err))
+		return
+	}
+
+	if ok, err := inf.IsResourceAuthorizedToCurrentUser(tenantID); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("Checking current user permissions for DS #%d: %v", dsid, err))
+		return
+	} else if !ok {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, fmt.Errorf("No job by id '%d'!", inf.IntParams["id"]), nil)
+		return
+	}
+
+	col, err := revalColumn(inf.Tx.Tx)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("getting reval column: %v", err))
+		return
+	}
+
+	rows, err := inf.Tx.Tx.Query(fmt.Sprintf(statusServersQuery, col, "id"), dsid)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("querying job servers: %v", err))
+		return
+	}
+	defer rows.Close()
+
+	status.PendingCaches = []string{}
+	for rows.Next() {
+		var hostName string
+		var pending bool
+		if err := rows.Scan(&hostName, &pending); err != nil {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("scanning job servers: %v", err))
+			return
+		}
+		status.AssignedCaches++
+		if pending {
+			status.PendingCaches = append(status.PendingCaches, hostName)
+		} else {
+			status.AppliedCaches++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("scanning job servers: %v", err))
+		return
+	}
+
+	status.State = jobState(status, time.Now())
+	api.WriteResp(w, r, status)
+}
+
+// jobState determines the State of an InvalidationJobStatus at the given time, from its start
+// time, its TTL, and the number of caches which have applied it.
+//
+// Because cache servers only track whether or not they have *any* revalidation pending, a job is
+// only considered complete once every assigned cache server has no revalidations pending at all.
+func jobState(status tc.InvalidationJobStatus, now time.Time) string {
+	if status.StartTime != nil {
+		if status.StartTime.After(now) {
+			return tc.InvalidationJobStatePending
+		}
+		if status.Parameters != nil {
+			if ttl, err := parseTTLParameter(*status.Parameters); err == nil && status.StartTime.Add(ttl).Before(now) {
+				return tc.InvalidationJobStateExpired
+			}
+		}
+	}
+	if status.AppliedCaches < status.AssignedCaches {
+		return tc.InvalidationJobStateInProgress
+	}
+	return tc.InvalidationJobStateComplete
+}
+
+// parseTTLParameter parses the TTL out of job parameters of the form "TTL:%dh".
+func parseTTLParameter(params string) (time.Duration, error) {
+	if !strings.HasPrefix(params, "TTL:") || !strings.HasSuffix(params, "h") {
+		return 0, errors.New("parameters not of the form 'TTL:%dh'")
+	}
+	hours, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(params, "TTL:"), "h"), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(hours) * time.Hour, nil
+}
@@ -29,6 +29,7 @@ import "time"
 import "github.com/apache/trafficcontrol/lib/go-tc"
 import "github.com/apache/trafficcontrol/lib/go-log"
 import "github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+import "github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
 
 const userReadQuery = `
 SELECT job.agent,
@@ -123,6 +124,16 @@ func CreateUserJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if userErr, sysErr, code := tenant.CheckDeliveryServiceQuota(inf.Tx.Tx, int(*job.DSID), tenant.QuotaInvalidationJobsPerDay); userErr != nil || sysErr != nil {
+		userErr = api.LogErr(r, code, userErr, sysErr)
+		if err := inf.Tx.Tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Errorln("rolling back transaction: " + err.Error())
+		}
+		alerts.AddNewAlert(tc.ErrorLevel, userErr.Error())
+		api.WriteAlerts(w, r, code, alerts)
+		return
+	}
+
 	if err := setRevalFlags(*job.DSID, inf.Tx.Tx); err != nil {
 		errCode = http.StatusInternalServerError
 		alerts.AddNewAlert(tc.ErrorLevel, api.LogErr(r, errCode, nil, fmt.Errorf("setting reval flags: %v", err)).Error())
@@ -133,6 +144,16 @@ func CreateUserJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := api.CreateEvent(inf.Tx.Tx, tc.EventTypeInvalidationJobCreated, inf.User, result); err != nil {
+		errCode = http.StatusInternalServerError
+		alerts.AddNewAlert(tc.ErrorLevel, api.LogErr(r, errCode, nil, fmt.Errorf("creating event: %v", err)).Error())
+		if err := inf.Tx.Tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Errorln("rolling back transaction: " + err.Error())
+		}
+		api.WriteAlerts(w, r, errCode, alerts)
+		return
+	}
+
 	alerts.AddNewAlert(tc.SuccessLevel, "Invalidation Job creation was successful")
 	w.Header().Set(http.CanonicalHeaderKey("location"), inf.Config.URL.Scheme+"://"+r.Host+"/api/1.4/jobs?id="+strconv.FormatUint(uint64(*result.ID), 10))
 	api.WriteAlertsObj(w, r, http.StatusOK, alerts, result)
@@ -61,7 +61,7 @@ func get(w http.ResponseWriter, r *http.Request, a tc.Alerts) {
 	}
 
 	setLastSeenCookie(w)
-	logs, err := getLog(inf.Tx.Tx, days, limit)
+	logs, err := getLog(inf.Tx.Tx, days, limit, inf.Params["correlationId"])
 	if err != nil {
 		a.AddNewAlert(tc.ErrorLevel, err.Error())
 		api.WriteAlerts(w, r, http.StatusInternalServerError, a)
@@ -121,21 +121,24 @@ func getLastSeenCookie(r *http.Request) (time.Time, bool) {
 	return lastSeen, true
 }
 
-func getLog(tx *sql.Tx, days int, limit int) ([]tc.Log, error) {
+// getLog returns the logs of the last days, most recent first. If correlationID isn't empty, only
+// the logs of the request with that correlation ID are returned.
+func getLog(tx *sql.Tx, days int, limit int, correlationID string) ([]tc.Log, error) {
 	rows, err := tx.Query(`
-SELECT l.id, l.level, l.message, u.username as user, l.ticketnum, l.last_updated
+SELECT l.id, l.level, l.message, u.username as user, l.ticketnum, l.last_updated, l.correlation_id
 FROM "log" as l JOIN tm_user as u ON l.tm_user = u.id
 WHERE l.last_updated > now() - ($1 || ' DAY')::INTERVAL
+AND ($3 = '' OR l.correlation_id = $3)
 ORDER BY l.last_updated DESC
 LIMIT $2
-`, days, limit)
+`, days, limit, correlationID)
 	if err != nil {
 		return nil, errors.New("querying logs: " + err.Error())
 	}
 	ls := []tc.Log{}
 	for rows.Next() {
 		l := tc.Log{}
-		if err = rows.Scan(&l.ID, &l.Level, &l.Message, &l.User, &l.TicketNum, &l.LastUpdated); err != nil {
+		if err = rows.Scan(&l.ID, &l.Level, &l.Message, &l.User, &l.TicketNum, &l.LastUpdated, &l.CorrelationID); err != nil {
 			return nil, errors.New("scanning logs: " + err.Error())
 		}
 		ls = append(ls, l)
@@ -0,0 +1,350 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+// Package openapi describes HTTP APIs with OpenAPI 3 documents, generating the schemas of request and response bodies from Go types and their JSON tags.
+// See https://spec.openapis.org/oas/v3.0.3
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Version is the version of the OpenAPI Specification of generated documents.
+const Version = "3.0.3"
+
+// Document is an OpenAPI document.
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Servers    []Server              `json:"servers,omitempty"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components Components            `json:"components"`
+	Security   []SecurityRequirement `json:"security,omitempty"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Server struct {
+	URL string `json:"url"`
+}
+
+// PathItem is the operations of a path, keyed by lower-case HTTP method.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string              `json:"operationId"`
+	Tags        []string            `json:"tags,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+	// Security overrides the Document Security. A pointer to an empty slice is an operation which requires no authentication.
+	Security *[]SecurityRequirement `json:"security,omitempty"`
+	// RouteID is the Traffic Ops route ID of the operation, which can be used to disable it.
+	RouteID int `json:"x-traffic-ops-route-id,omitempty"`
+	// PrivLevel is the Traffic Ops privilege level required to use the operation.
+	PrivLevel int `json:"x-traffic-ops-priv-level,omitempty"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// JSONContent returns the content of a JSON request or response body with the given schema.
+func JSONContent(schema *Schema) map[string]MediaType {
+	return map[string]MediaType{"application/json": MediaType{Schema: schema}}
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas,omitempty"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type string `json:"type"`
+	In   string `json:"in,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+// SecurityRequirement is the names of the security schemes which an operation requires, and their scopes.
+type SecurityRequirement map[string][]string
+
+// Schema is a JSON Schema, of the subset used by OpenAPI 3.
+// The empty Schema allows any value.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+}
+
+// Object returns an object Schema with the given properties.
+func Object(properties map[string]*Schema, required ...string) *Schema {
+	return &Schema{Type: "object", Properties: properties, Required: required}
+}
+
+// Schemas generates the Schemas of Go types, as they are encoded by encoding/json.
+// Named struct types are added to Components, and referenced by the Schemas of types which use them. Because names are given in the order types are first seen, generating the same types in the same order always generates the same Components.
+type Schemas struct {
+	Components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+func NewSchemas() *Schemas {
+	return &Schemas{Components: map[string]*Schema{}, names: map[reflect.Type]string{}}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+var rawMessageType = reflect.TypeOf(json.RawMessage{})
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// Of returns the Schema of values of the given type.
+func (s *Schemas) Of(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Ptr {
+		schema := s.Of(t.Elem())
+		if schema.Ref == "" {
+			schema.Nullable = true
+		}
+		return schema
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == rawMessageType:
+		return &Schema{}
+	case t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType):
+		if marshalsToString(t) {
+			return &Schema{Type: "string"}
+		}
+	case t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType):
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"} // encoding/json encodes []byte as base64
+		}
+		return &Schema{Type: "array", Items: s.Of(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.Of(t.Elem())}
+	case reflect.Struct:
+		return s.structSchema(t)
+	}
+	return &Schema{} // interfaces, and anything else encoding/json can encode as anything
+}
+
+// structSchema returns the Schema of a struct type, which is a reference to its component if it's named.
+func (s *Schemas) structSchema(t reflect.Type) *Schema {
+	if wrapped, ok := wrappedStruct(t); ok {
+		// a struct which only wraps another, like a handler type embedding a lib/go-tc type, is encoded as the struct it wraps
+		return s.Of(wrapped)
+	}
+	if t.Name() == "" {
+		return s.fieldsSchema(t)
+	}
+
+	name, ok := s.names[t]
+	if !ok {
+		name = s.newName(t)
+		s.names[t] = name
+		s.Components[name] = &Schema{} // placeholder, so recursive types reference it rather than recursing forever
+		s.Components[name] = s.fieldsSchema(t)
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// newName returns a component name for the given type which isn't yet used, which is the type's name, prefixed with its package if another type has that name.
+func (s *Schemas) newName(t reflect.Type) string {
+	name := t.Name()
+	if _, taken := s.Components[name]; !taken {
+		return name
+	}
+	pkg := t.PkgPath()
+	if i := strings.LastIndex(pkg, "/"); i >= 0 {
+		pkg = pkg[i+1:]
+	}
+	name = strings.Replace(pkg, "-", "", -1) + "." + name
+	if _, taken := s.Components[name]; !taken {
+		return name
+	}
+	for i := 2; ; i++ {
+		if _, taken := s.Components[name+strconv.Itoa(i)]; !taken {
+			return name + strconv.Itoa(i)
+		}
+	}
+}
+
+func (s *Schemas) fieldsSchema(t reflect.Type) *Schema {
+	properties := map[string]*Schema{}
+	for _, field := range jsonFields(t) {
+		if field.asString {
+			properties[field.name] = &Schema{Type: "string"}
+			continue
+		}
+		properties[field.name] = s.Of(field.typ)
+	}
+	return Object(properties)
+}
+
+// jsonField is a field of a struct as it's encoded by encoding/json.
+type jsonField struct {
+	name     string
+	typ      reflect.Type
+	asString bool
+	depth    int
+}
+
+// jsonFields returns the fields of the given struct type which encoding/json encodes, in order, with the fields of embedded structs in place of the embedded struct.
+func jsonFields(t reflect.Type) []jsonField {
+	fields := []jsonField{}
+	index := map[string]int{}
+	var addFields func(t reflect.Type, depth int)
+	addFields = func(t reflect.Type, depth int) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, opts, ok := jsonTag(f)
+			if !ok {
+				continue
+			}
+			if embedded, ok := embeddedStruct(f, name); ok {
+				addFields(embedded, depth+1)
+				continue
+			}
+			if f.PkgPath != "" { // unexported embedded non-struct
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			field := jsonField{name: name, typ: f.Type, asString: strings.Contains(","+opts+",", ",string,"), depth: depth}
+			if i, ok := index[name]; ok {
+				if fields[i].depth > depth {
+					fields[i] = field // per encoding/json, the shallowest field wins
+				}
+				continue
+			}
+			index[name] = len(fields)
+			fields = append(fields, field)
+		}
+	}
+	addFields(t, 0)
+	return fields
+}
+
+// wrappedStruct returns the struct embedded in the given struct type, if that's its only field which encoding/json encodes.
+func wrappedStruct(t reflect.Type) (reflect.Type, bool) {
+	wrapped := reflect.Type(nil)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, ok := jsonTag(f)
+		if !ok {
+			continue
+		}
+		embedded, ok := embeddedStruct(f, name)
+		if !ok || wrapped != nil {
+			return nil, false
+		}
+		wrapped = embedded
+	}
+	return wrapped, wrapped != nil
+}
+
+// jsonTag returns the name and options in the JSON tag of the given field, and false if encoding/json doesn't encode it.
+func jsonTag(f reflect.StructField) (string, string, bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", "", false
+	}
+	if f.PkgPath != "" && !f.Anonymous { // unexported
+		return "", "", false
+	}
+	name, opts := tag, ""
+	if comma := strings.Index(tag, ","); comma >= 0 {
+		name, opts = tag[:comma], tag[comma+1:]
+	}
+	return name, opts, true
+}
+
+// embeddedStruct returns the struct type of the given field, if it's an embedded struct whose fields encoding/json encodes in its parent.
+func embeddedStruct(f reflect.StructField, jsonName string) (reflect.Type, bool) {
+	if !f.Anonymous || jsonName != "" {
+		return nil, false
+	}
+	t := f.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t, t.Kind() == reflect.Struct
+}
+
+// marshalsToString returns whether the zero value of the given json.Marshaler type is encoded as a JSON string.
+func marshalsToString(t reflect.Type) (isString bool) {
+	defer func() {
+		if recover() != nil {
+			isString = false
+		}
+	}()
+	bts, err := json.Marshal(reflect.New(t).Interface())
+	return err == nil && len(bts) > 0 && bts[0] == '"'
+}
@@ -0,0 +1,111 @@
+package openapi
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testBase struct {
+	ID          *int      `json:"id"`
+	Name        string    `json:"name"`
+	LastUpdated time.Time `json:"lastUpdated"`
+}
+
+type testObject struct {
+	testBase
+	Name     string            `json:"displayName"`
+	Count    int64             `json:"count,string"`
+	Tags     []string          `json:"tags,omitempty"`
+	Labels   map[string]string `json:"labels"`
+	Child    *testObject       `json:"child"`
+	Ignored  string            `json:"-"`
+	Untagged bool
+	private  string
+}
+
+type testWrapper struct {
+	testObject
+	Info interface{} `json:"-"`
+}
+
+func TestSchemasOf(t *testing.T) {
+	schemas := NewSchemas()
+	schema := schemas.Of(reflect.TypeOf(&testWrapper{}))
+	if schema.Ref != "#/components/schemas/testObject" {
+		t.Fatalf("Of wrapper struct expected a reference to the struct it wraps, actual %+v", schema)
+	}
+
+	expected := map[string]Schema{
+		"id":          {Type: "integer", Nullable: true},
+		"name":        {Type: "string"},
+		"lastUpdated": {Type: "string", Format: "date-time"},
+		"displayName": {Type: "string"},
+		"count":       {Type: "string"},
+		"tags":        {Type: "array", Items: &Schema{Type: "string"}},
+		"labels":      {Type: "object", AdditionalProperties: &Schema{Type: "string"}},
+		"child":       {Ref: "#/components/schemas/testObject"},
+		"Untagged":    {Type: "boolean"},
+	}
+	obj := schemas.Components["testObject"]
+	if obj == nil {
+		t.Fatalf("Of struct expected component testObject, actual components %+v", schemas.Components)
+	}
+	if len(obj.Properties) != len(expected) {
+		t.Errorf("Of struct expected %d properties, actual %d", len(expected), len(obj.Properties))
+	}
+	for name, expectedSchema := range expected {
+		if actual, ok := obj.Properties[name]; !ok || !reflect.DeepEqual(*actual, expectedSchema) {
+			t.Errorf("Of struct property '%s' expected %+v, actual %+v", name, expectedSchema, actual)
+		}
+	}
+	if _, ok := schemas.Components["testBase"]; ok {
+		t.Errorf("Of struct expected embedded struct fields to be in the struct, actual component testBase")
+	}
+}
+
+type testNamedString string
+
+type testStringMarshaler struct{ t time.Time }
+
+func (m testStringMarshaler) MarshalJSON() ([]byte, error) { return json.Marshal(m.t.Format(time.RFC1123)) }
+
+func TestSchemasOfScalars(t *testing.T) {
+	tests := []struct {
+		val      interface{}
+		expected Schema
+	}{
+		{testNamedString(""), Schema{Type: "string"}},
+		{testStringMarshaler{}, Schema{Type: "string"}},
+		{[]byte{}, Schema{Type: "string", Format: "byte"}},
+		{json.RawMessage{}, Schema{}},
+		{float64(0), Schema{Type: "number", Format: "double"}},
+		{[]interface{}{}, Schema{Type: "array", Items: &Schema{}}},
+	}
+	for _, test := range tests {
+		actual := NewSchemas().Of(reflect.TypeOf(test.val))
+		if !reflect.DeepEqual(*actual, test.expected) {
+			t.Errorf("Of %T expected %+v, actual %+v", test.val, test.expected, *actual)
+		}
+	}
+}
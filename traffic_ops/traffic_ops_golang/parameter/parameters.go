@@ -99,6 +99,11 @@ func (param *TOParameter) GetType() string {
 	return "param"
 }
 
+// LastModifiedTables implements api.HasLastModifiedTables.
+func (param *TOParameter) LastModifiedTables() []string {
+	return []string{"parameter", "profile_parameter", "profile"}
+}
+
 // Validate fulfills the api.Validator interface
 func (param TOParameter) Validate() error {
 	// Test
@@ -88,6 +88,11 @@ func (prof *TOProfile) GetType() string {
 	return "profile"
 }
 
+// LastModifiedTables implements api.HasLastModifiedTables.
+func (prof *TOProfile) LastModifiedTables() []string {
+	return []string{"profile", "cdn", "profile_parameter", "parameter"}
+}
+
 func (prof *TOProfile) Validate() error {
 	errs := validation.Errors{
 		NameQueryParam:        validation.Validate(prof.Name, validation.Required),
@@ -57,10 +57,17 @@ func getProfileName(w http.ResponseWriter, r *http.Request, deprecated bool) {
 		}
 		api.WriteAlertsObj(w, r, http.StatusOK, api.CreateDeprecationAlerts(deprecation), profiles)
 	} else {
+		if err := api.SetLastModified(w, inf.Tx.Tx, profileNameParametersTables); err != nil {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, err)
+			return
+		}
 		api.RespWriter(w, r, inf.Tx.Tx)(getParametersByProfileName(inf.Tx.Tx, name))
 	}
 }
 
+// profileNameParametersTables are the tables getParametersByProfileName reads, for the Last-Modified header.
+var profileNameParametersTables = []string{"parameter", "profile_parameter", "profile"}
+
 func getParametersByProfileName(tx *sql.Tx, profileName string) ([]tc.ProfileParameterByName, error) {
 	q := `
 SELECT
@@ -134,7 +134,7 @@ func Ping(tx *sql.Tx, authOpts *riak.AuthOptions, riakPort *uint) (tc.RiakPingRe
 			log.Errorln("starting Riak cluster (for ping): " + err.Error())
 			continue
 		}
-		if err := PingCluster(cluster); err != nil {
+		if err := PingCluster(traceCluster(tx, cluster)); err != nil {
 			if err := cluster.Stop(); err != nil {
 				log.Errorln("stopping Riak cluster (after ping error): " + err.Error())
 			}
@@ -20,6 +20,7 @@ package riaksvc
  */
 
 import (
+	"context"
 	"crypto/tls"
 	"database/sql"
 	"encoding/json"
@@ -35,6 +36,7 @@ import (
 
 	"github.com/apache/trafficcontrol/lib/go-log"
 	"github.com/apache/trafficcontrol/lib/go-util"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/trace"
 
 	"github.com/basho/riak-go-client"
 )
@@ -84,6 +86,31 @@ func (ri RiakStorageCluster) Execute(command riak.Command) error {
 	return ri.Cluster.Execute(command)
 }
 
+// tracedCluster is a StorageCluster which traces the commands it executes as children of the span of ctx.
+type tracedCluster struct {
+	StorageCluster
+	ctx context.Context
+}
+
+// Execute implements StorageCluster.
+func (c tracedCluster) Execute(command riak.Command) error {
+	_, span := trace.StartChildSpan(c.ctx, "Riak "+command.Name(), trace.KindClient)
+	span.SetAttribute("db.system", "riak")
+	err := c.StorageCluster.Execute(command)
+	span.SetError(err)
+	span.End()
+	return err
+}
+
+// traceCluster returns the cluster, tracing its commands as part of the request the transaction is for, if it's traced.
+func traceCluster(tx *sql.Tx, cluster StorageCluster) StorageCluster {
+	ctx := trace.TxContext(tx)
+	if trace.FromContext(ctx) == nil {
+		return cluster
+	}
+	return tracedCluster{StorageCluster: cluster, ctx: ctx}
+}
+
 func GetRiakConfig(riakConfigFile string) (bool, *riak.AuthOptions, error) {
 	riakConfString, err := ioutil.ReadFile(riakConfigFile)
 	if err != nil {
@@ -357,7 +384,7 @@ func WithCluster(tx *sql.Tx, authOpts *riak.AuthOptions, riakPort *uint, f func(
 	if err != nil {
 		return errors.New("getting riak pooled cluster: " + err.Error())
 	}
-	return f(cluster)
+	return f(traceCluster(tx, cluster))
 }
 
 // Search searches Riak for the given query. Returns nil and a nil error if no object was found.
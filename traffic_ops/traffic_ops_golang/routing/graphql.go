@@ -0,0 +1,49 @@
+package routing
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"net/http"
+
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+)
+
+// graphQLTypeRoutes are the paths of the 2.0 GET routes which read the objects of each GraphQL object type. Users must have the priv level of a type's route to query the type.
+var graphQLTypeRoutes = map[string]string{
+	"CacheGroup":      `cachegroups/?$`,
+	"CDN":             `cdns/?$`,
+	"DeliveryService": `deliveryservices/?$`,
+	"Parameter":       `parameters/?$`,
+	"Profile":         `profiles/?$`,
+	"Server":          `servers/?$`,
+}
+
+// graphQLPrivLevels returns the priv level users must have to query each GraphQL object type, by name: that of the route which reads the same objects.
+func graphQLPrivLevels(routes []Route) map[string]int {
+	levels := map[string]int{}
+	for typeName, path := range graphQLTypeRoutes {
+		for _, r := range routes {
+			if r.Version == (api.Version{2, 0}) && r.Method == http.MethodGet && r.Path == path {
+				levels[typeName] = r.RequiredPrivLevel
+			}
+		}
+	}
+	return levels
+}
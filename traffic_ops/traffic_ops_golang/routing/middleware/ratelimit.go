@@ -0,0 +1,193 @@
+package middleware
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/config"
+)
+
+// RetryAfterHeader is the header of a response to a rate limited request, which is the number of seconds after which the request will be within its limits.
+const RetryAfterHeader = "Retry-After"
+
+// rateLimitPruneInterval is how often the buckets of clients which haven't made requests for long enough to have full buckets are removed.
+const rateLimitPruneInterval = time.Minute
+
+// RateLimiter limits the rate of requests of each user with token buckets, as configured by a config.ConfigRateLimit.
+// The limits are of each Traffic Ops instance: a user could make as many requests as their limits allow to each instance behind a load balancer.
+type RateLimiter struct {
+	limits      config.ConfigRateLimit
+	exemptUsers map[string]struct{}
+	exemptRoles map[string]struct{}
+	now         func() time.Time
+
+	m         sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastPrune time.Time
+}
+
+// NewRateLimiter returns a RateLimiter of the given limits, or nil if there are none.
+func NewRateLimiter(limits config.ConfigRateLimit) *RateLimiter {
+	if !limits.Enabled() {
+		return nil
+	}
+	l := &RateLimiter{
+		limits:      limits,
+		exemptUsers: make(map[string]struct{}, len(limits.ExemptUsers)),
+		exemptRoles: make(map[string]struct{}, len(limits.ExemptRoles)),
+		now:         time.Now,
+		buckets:     map[string]*tokenBucket{},
+	}
+	for _, name := range limits.ExemptUsers {
+		l.exemptUsers[name] = struct{}{}
+	}
+	for _, name := range limits.ExemptRoles {
+		l.exemptRoles[name] = struct{}{}
+	}
+	l.lastPrune = l.now()
+	return l
+}
+
+// GetWrapper returns a Middleware which limits the rate of requests to the route with the given ID, which is 0 for routes without an ID.
+// It must come after the authentication Middleware of authenticated routes, because it limits requests by the user that Middleware adds to the request.
+// Requests over their limits are given a 429 Too Many Requests response, with the RetryAfterHeader.
+func (l *RateLimiter) GetWrapper(routeID int) Middleware {
+	return func(h http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			wait := l.take(r, routeID)
+			if wait <= 0 {
+				h(w, r)
+				return
+			}
+			seconds := int(math.Ceil(wait.Seconds()))
+			w.Header().Set(RetryAfterHeader, strconv.Itoa(seconds))
+			api.HandleErr(w, r, nil, http.StatusTooManyRequests, errors.New("too many requests, retry after "+strconv.Itoa(seconds)+" seconds"), nil)
+		}
+	}
+}
+
+// take takes a request from the buckets of the request's client, and returns 0 if it's within the client's limits.
+// Otherwise, it takes nothing, and returns how long the client must wait for the request to be within its limits.
+func (l *RateLimiter) take(r *http.Request, routeID int) time.Duration {
+	client := ""
+	userLimit := l.limits.Default
+	if user, err := auth.GetCurrentUser(r.Context()); err == nil {
+		if _, ok := l.exemptUsers[user.UserName]; ok {
+			return 0
+		}
+		if _, ok := l.exemptRoles[user.RoleName]; ok {
+			return 0
+		}
+		client = "user " + user.UserName
+		if limit, ok := l.limits.Users[user.UserName]; ok {
+			userLimit = &limit
+		} else if limit, ok := l.limits.Roles[user.RoleName]; ok {
+			userLimit = &limit
+		}
+	} else {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		client = "address " + host
+	}
+
+	l.m.Lock()
+	defer l.m.Unlock()
+	now := l.now()
+	l.prune(now)
+
+	buckets := make([]*tokenBucket, 0, 2)
+	if limit, ok := l.limits.Routes[routeID]; ok && routeID != 0 {
+		buckets = append(buckets, l.bucket(client+" route "+strconv.Itoa(routeID), limit, now))
+	}
+	if userLimit != nil {
+		buckets = append(buckets, l.bucket(client, *userLimit, now))
+	}
+
+	wait := time.Duration(0)
+	for _, b := range buckets {
+		if w := b.wait(); w > wait {
+			wait = w
+		}
+	}
+	if wait > 0 {
+		return wait
+	}
+	for _, b := range buckets {
+		b.tokens--
+	}
+	return 0
+}
+
+// bucket returns the refilled bucket of the given key, creating a full one if it doesn't exist.
+func (l *RateLimiter) bucket(key string, limit config.RateLimit, now time.Time) *tokenBucket {
+	b, ok := l.buckets[key]
+	if !ok || b.limit != limit {
+		b = &tokenBucket{limit: limit, tokens: float64(limit.Burst), last: now}
+		l.buckets[key] = b
+	}
+	b.refill(now)
+	return b
+}
+
+// prune removes the buckets which would be full by now, which are the same as the new buckets which would replace them, so the buckets of clients which have stopped making requests don't accumulate.
+func (l *RateLimiter) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < rateLimitPruneInterval {
+		return
+	}
+	l.lastPrune = now
+	for key, b := range l.buckets {
+		if b.refill(now); b.tokens >= float64(b.limit.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// tokenBucket holds up to its limit's burst of tokens, and is refilled at its limit's rate of tokens per second. Each request takes a token.
+type tokenBucket struct {
+	limit  config.RateLimit
+	tokens float64
+	last   time.Time
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(float64(b.limit.Burst), b.tokens+elapsed*b.limit.Rate)
+	}
+	b.last = now
+}
+
+// wait returns how long until the bucket has a token, which is 0 if it has one now.
+func (b *tokenBucket) wait() time.Duration {
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.limit.Rate * float64(time.Second))
+}
@@ -0,0 +1,192 @@
+package middleware
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/config"
+)
+
+// rateLimitTest is a RateLimiter with a clock the test can advance, and a handler of a route with it.
+type rateLimitTest struct {
+	t       *testing.T
+	limiter *RateLimiter
+	now     time.Time
+}
+
+func newRateLimitTest(t *testing.T, limits config.ConfigRateLimit) *rateLimitTest {
+	rt := &rateLimitTest{t: t, now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	rt.limiter = NewRateLimiter(limits)
+	if rt.limiter == nil {
+		t.Fatal("expected a rate limiter, actual: nil")
+	}
+	rt.limiter.now = func() time.Time { return rt.now }
+	rt.limiter.lastPrune = rt.now
+	return rt
+}
+
+// request makes a request to the route with the given ID as the given user, or from 192.0.2.1 if user is nil, and returns its response.
+func (rt *rateLimitTest) request(routeID int, user *auth.CurrentUser) *httptest.ResponseRecorder {
+	h := WrapHeaders(rt.limiter.GetWrapper(routeID)(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "192.0.2.1:12345"
+	if user != nil {
+		r = r.WithContext(context.WithValue(r.Context(), auth.CurrentUserKey, *user))
+	}
+	w := httptest.NewRecorder()
+	h(w, r)
+	return w
+}
+
+// expect makes n requests, and fails if any of them don't have the expected response code.
+func (rt *rateLimitTest) expect(n int, routeID int, user *auth.CurrentUser, code int) {
+	rt.t.Helper()
+	for i := 0; i < n; i++ {
+		if w := rt.request(routeID, user); w.Code != code {
+			rt.t.Fatalf("request %d to route %d: expected code %d, actual: %d", i+1, routeID, code, w.Code)
+		}
+	}
+}
+
+func TestRateLimiterBurstAndRefill(t *testing.T) {
+	rt := newRateLimitTest(t, config.ConfigRateLimit{Default: &config.RateLimit{Rate: 0.5, Burst: 3}})
+	user := &auth.CurrentUser{UserName: "alice", RoleName: "operations"}
+
+	rt.expect(3, 1, user, http.StatusOK)
+	w := rt.request(1, user)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected request over the burst to be %d, actual: %d", http.StatusTooManyRequests, w.Code)
+	}
+	if retryAfter := w.Header().Get(RetryAfterHeader); retryAfter != "2" {
+		t.Errorf("expected %s 2, actual: '%s'", RetryAfterHeader, retryAfter)
+	}
+
+	rt.now = rt.now.Add(time.Second)
+	w = rt.request(1, user)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected request before refill to be %d, actual: %d", http.StatusTooManyRequests, w.Code)
+	}
+	if retryAfter := w.Header().Get(RetryAfterHeader); retryAfter != "1" {
+		t.Errorf("expected %s 1, actual: '%s'", RetryAfterHeader, retryAfter)
+	}
+
+	rt.now = rt.now.Add(time.Second)
+	rt.expect(1, 1, user, http.StatusOK)
+	rt.expect(1, 1, user, http.StatusTooManyRequests)
+
+	// other users have their own buckets
+	rt.expect(3, 1, &auth.CurrentUser{UserName: "bob", RoleName: "operations"}, http.StatusOK)
+
+	// buckets never hold more than the burst
+	rt.now = rt.now.Add(time.Hour)
+	rt.expect(3, 1, user, http.StatusOK)
+	rt.expect(1, 1, user, http.StatusTooManyRequests)
+}
+
+func TestRateLimiterRouteLimits(t *testing.T) {
+	rt := newRateLimitTest(t, config.ConfigRateLimit{
+		Default: &config.RateLimit{Rate: 1, Burst: 5},
+		Routes:  map[int]config.RateLimit{42: {Rate: 1, Burst: 2}},
+	})
+	user := &auth.CurrentUser{UserName: "alice"}
+
+	rt.expect(2, 42, user, http.StatusOK)
+	rt.expect(1, 42, user, http.StatusTooManyRequests)
+
+	// the rejected request didn't count against the user's overall limit
+	rt.expect(3, 7, user, http.StatusOK)
+	rt.expect(1, 7, user, http.StatusTooManyRequests)
+
+	// the route's limit is per user
+	rt.expect(2, 42, &auth.CurrentUser{UserName: "bob"}, http.StatusOK)
+}
+
+func TestRateLimiterUserAndRoleLimits(t *testing.T) {
+	rt := newRateLimitTest(t, config.ConfigRateLimit{
+		Default: &config.RateLimit{Rate: 1, Burst: 1},
+		Users:   map[string]config.RateLimit{"alice": {Rate: 1, Burst: 4}},
+		Roles:   map[string]config.RateLimit{"operations": {Rate: 1, Burst: 3}, "admin": {Rate: 1, Burst: 10}},
+	})
+
+	// a user's own limit takes precedence over their role's
+	rt.expect(4, 1, &auth.CurrentUser{UserName: "alice", RoleName: "admin"}, http.StatusOK)
+	rt.expect(1, 1, &auth.CurrentUser{UserName: "alice", RoleName: "admin"}, http.StatusTooManyRequests)
+
+	rt.expect(3, 1, &auth.CurrentUser{UserName: "bob", RoleName: "operations"}, http.StatusOK)
+	rt.expect(1, 1, &auth.CurrentUser{UserName: "bob", RoleName: "operations"}, http.StatusTooManyRequests)
+
+	rt.expect(1, 1, &auth.CurrentUser{UserName: "carol", RoleName: "read-only"}, http.StatusOK)
+	rt.expect(1, 1, &auth.CurrentUser{UserName: "carol", RoleName: "read-only"}, http.StatusTooManyRequests)
+}
+
+func TestRateLimiterExemptions(t *testing.T) {
+	rt := newRateLimitTest(t, config.ConfigRateLimit{
+		Default:     &config.RateLimit{Rate: 1, Burst: 1},
+		Routes:      map[int]config.RateLimit{42: {Rate: 1, Burst: 1}},
+		ExemptUsers: []string{"ort"},
+		ExemptRoles: []string{"atstccfg"},
+	})
+
+	rt.expect(10, 42, &auth.CurrentUser{UserName: "ort", RoleName: "operations"}, http.StatusOK)
+	rt.expect(10, 42, &auth.CurrentUser{UserName: "cache", RoleName: "atstccfg"}, http.StatusOK)
+	rt.expect(1, 42, &auth.CurrentUser{UserName: "alice", RoleName: "operations"}, http.StatusOK)
+	rt.expect(1, 42, &auth.CurrentUser{UserName: "alice", RoleName: "operations"}, http.StatusTooManyRequests)
+}
+
+func TestRateLimiterUnauthenticated(t *testing.T) {
+	rt := newRateLimitTest(t, config.ConfigRateLimit{
+		Default: &config.RateLimit{Rate: 1, Burst: 2},
+		Roles:   map[string]config.RateLimit{"": {Rate: 1, Burst: 10}},
+	})
+
+	// unauthenticated requests are limited by address, and only by the default and route limits
+	rt.expect(2, 1, nil, http.StatusOK)
+	rt.expect(1, 1, nil, http.StatusTooManyRequests)
+
+	// an address's bucket isn't that of any user
+	rt.expect(2, 1, &auth.CurrentUser{UserName: "192.0.2.1", RoleName: "read-only"}, http.StatusOK)
+}
+
+func TestRateLimiterPrune(t *testing.T) {
+	rt := newRateLimitTest(t, config.ConfigRateLimit{Default: &config.RateLimit{Rate: 1, Burst: 100}})
+	rt.expect(1, 1, &auth.CurrentUser{UserName: "alice"}, http.StatusOK)
+	rt.now = rt.now.Add(rateLimitPruneInterval)
+	rt.expect(1, 1, &auth.CurrentUser{UserName: "bob"}, http.StatusOK)
+	if _, ok := rt.limiter.buckets["user alice"]; ok {
+		t.Error("expected the full bucket of a user who stopped making requests to be pruned, actual: not pruned")
+	}
+	if _, ok := rt.limiter.buckets["user bob"]; !ok {
+		t.Error("expected the bucket of a user making requests to exist, actual: missing")
+	}
+}
+
+func TestNewRateLimiterDisabled(t *testing.T) {
+	if l := NewRateLimiter(config.ConfigRateLimit{ExemptUsers: []string{"ort"}}); l != nil {
+		t.Error("expected no rate limiter without any limits, actual: not nil")
+	}
+}
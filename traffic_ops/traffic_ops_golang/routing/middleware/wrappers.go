@@ -37,6 +37,7 @@ import (
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/about"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/tocookie"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/trace"
 )
 
 // DefaultRequestTimeout is the default request timeout, if no timeout is configured.
@@ -57,6 +58,12 @@ func GetDefault(secret string, requestTimeout time.Duration) []Middleware {
 	return []Middleware{GetWrapAccessLog(secret), TimeOutWrapper(requestTimeout), WrapHeaders, WrapPanicRecover}
 }
 
+// GetStreaming returns the middleware for Traffic Ops endpoints which stream their responses, such as the event stream.
+// This is the default middleware without the request timeout or the default headers and compression, all of which require the entire response to be written before any of it is sent.
+func GetStreaming(secret string) []Middleware {
+	return []Middleware{GetWrapAccessLog(secret), WrapPanicRecover}
+}
+
 // Use takes a slice of middlewares, and applies them in reverse order (which is the intuitive behavior) to the given HandlerFunc h.
 // It returns a HandlerFunc which will call all middlewares, and then h.
 func Use(h http.HandlerFunc, middlewares []Middleware) http.HandlerFunc {
@@ -153,6 +160,7 @@ func GetWrapAccessLog(secret string) Middleware {
 }
 
 // WrapAccessLog takes the cookie secret and a http.Handler, and returns a HandlerFunc which writes to the Access Log (which is the lib/go-log EventLog) after the HandlerFunc finishes.
+// Each line ends with the correlation ID of the request, which the router sets in its tc.CorrelationIDHeader, or "-" if it has none.
 // This is not a Middleware, because it needs the secret as a parameter. For a Middleware, see GetWrapAccessLog.
 func WrapAccessLog(secret string, h http.Handler) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -165,14 +173,40 @@ func WrapAccessLog(secret string, h http.Handler) http.HandlerFunc {
 				user = cookie.AuthData
 			}
 		}
+		correlationID := r.Header.Get(tc.CorrelationIDHeader)
+		if correlationID == "" {
+			correlationID = "-"
+		}
 		start := time.Now()
 		defer func() {
-			log.EventfRaw(`%s - %s [%s] "%v %v?%v %s" %v %v %v "%v"`, r.RemoteAddr, user, time.Now().Format(AccessLogTimeFormat), r.Method, r.URL.Path, r.URL.RawQuery, r.Proto, iw.Code, iw.ByteCount, int(time.Now().Sub(start)/time.Millisecond), r.UserAgent())
+			log.EventfRaw(`%s - %s [%s] "%v %v?%v %s" %v %v %v "%v" %s`, r.RemoteAddr, user, time.Now().Format(AccessLogTimeFormat), r.Method, r.URL.Path, r.URL.RawQuery, r.Proto, iw.Code, iw.ByteCount, int(time.Now().Sub(start)/time.Millisecond), r.UserAgent(), correlationID)
 		}()
 		h.ServeHTTP(iw, r)
 	}
 }
 
+// WrapTrace returns a HandlerFunc which traces the handling of each request in a span of the given name, the parent of the spans of the database queries and other calls made while handling it. The route is the path of the route the handler serves, which is the span's http.route attribute.
+// If tracing is disabled, h is returned unwrapped.
+func WrapTrace(name string, route string, h http.HandlerFunc) http.HandlerFunc {
+	if !trace.Enabled() {
+		return h
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := trace.StartSpan(trace.RequestContext(r), name, trace.KindServer)
+		span.SetAttribute("http.method", r.Method)
+		span.SetAttribute("http.target", r.URL.RequestURI())
+		span.SetAttribute("http.route", route)
+		span.SetAttribute("traffic_ops.correlation_id", r.Header.Get(tc.CorrelationIDHeader))
+		iw := &util.Interceptor{W: w}
+		h(iw, r.WithContext(ctx))
+		span.SetAttribute("http.status_code", iw.Code)
+		if iw.Code >= http.StatusInternalServerError {
+			span.SetError(errors.New(http.StatusText(iw.Code)))
+		}
+		span.End()
+	}
+}
+
 // GzipResponse takes a function which cannot error and returns only bytes, and wraps it as a http.HandlerFunc. The errContext is logged if the write fails, and should be enough information to trace the problem (function name, endpoint, request parameters, etc).
 // It gzips the given bytes and writes them to w, as well as writing the appropriate 'Content-Encoding: gzip' header, if the request included an 'Accept-Encoding: gzip' header.
 // If the request doesn't accept gzip, the bytes are written to w unmodified.
@@ -0,0 +1,331 @@
+package routing
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/apache/trafficcontrol/lib/go-rfc"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/apitenant"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/asn"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/cachegroup"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/cachegroupparameter"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/cdn"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/cdnfederation"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/coordinate"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/deliveryservice"
+	dsrequest "github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/deliveryservice/request"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/deliveryservice/request/comment"
+	dsserver "github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/deliveryservice/servers"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/division"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/federations"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/invalidationjobs"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/openapi"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/origin"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/parameter"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/physlocation"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/profile"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/profileparameter"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/region"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/role"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/server"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/servercapability"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/staticdnsentry"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/status"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/steeringtargets"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/tocookie"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/types"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/user"
+)
+
+// openAPIRoute is the version and path of a Route, as in the Routes table.
+type openAPIRoute struct {
+	Version api.Version
+	Path    string
+}
+
+// openAPIObjects are the objects which routes create, read, update and delete, which the OpenAPI document uses to describe the routes' request and response bodies. Routes which aren't here are described without their bodies.
+// Objects which are api.Validators also have the fields their Validate requires described.
+var openAPIObjects = map[openAPIRoute]interface{}{
+	{api.Version{2, 0}, `asns/?$`}:                                            &asn.TOASNV11{},
+	{api.Version{2, 0}, `asns/{id}$`}:                                         &asn.TOASNV11{},
+	{api.Version{2, 0}, `cachegroupparameters/{cachegroupID}/{parameterId}$`}: &cachegroupparameter.TOCacheGroupParameter{},
+	{api.Version{2, 0}, `cachegroups/?$`}:                                     &cachegroup.TOCacheGroup{},
+	{api.Version{2, 0}, `cachegroups/{id}$`}:                                  &cachegroup.TOCacheGroup{},
+	{api.Version{2, 0}, `cachegroups/{id}/parameters/?$`}:                     &cachegroupparameter.TOCacheGroupParameter{},
+	{api.Version{2, 0}, `cdns/?$`}:                                            &cdn.TOCDN{},
+	{api.Version{2, 0}, `cdns/{id}$`}:                                         &cdn.TOCDN{},
+	{api.Version{2, 0}, `cdns/{name}/federations/?$`}:                         &cdnfederation.TOCDNFederation{},
+	{api.Version{2, 0}, `cdns/{name}/federations/{id}$`}:                      &cdnfederation.TOCDNFederation{},
+	{api.Version{2, 0}, `coordinates/?$`}:                                     &coordinate.TOCoordinate{},
+	{api.Version{2, 0}, `deliveryservice_request_comments/?$`}:                &comment.TODeliveryServiceRequestComment{},
+	{api.Version{2, 0}, `deliveryservice_requests/?$`}:                        &dsrequest.TODeliveryServiceRequest{},
+	{api.Version{2, 0}, `deliveryservices/?$`}:                                &tc.DeliveryServiceNullable{}, // TODeliveryService marshals as its DeliveryServiceNullable
+	{api.Version{2, 0}, `deliveryservices/{id}/?$`}:                           &tc.DeliveryServiceNullable{}, // TODeliveryService marshals as its DeliveryServiceNullable
+	{api.Version{2, 0}, `deliveryservices_required_capabilities/?$`}:          &deliveryservice.RequiredCapability{},
+	{api.Version{2, 0}, `divisions/?$`}:                                       &division.TODivision{},
+	{api.Version{2, 0}, `divisions/{id}$`}:                                    &division.TODivision{},
+	{api.Version{2, 0}, `federations/{id}/deliveryservices/?$`}:               &federations.TOFedDSes{},
+	{api.Version{2, 0}, `federations/{id}/deliveryservices/{dsID}/?$`}:        &federations.TOFedDSes{},
+	{api.Version{2, 0}, `federations/{id}/users/?$`}:                          &federations.TOUsers{},
+	{api.Version{2, 0}, `federations/{id}/users/{userID}/?$`}:                 &federations.TOUsers{},
+	{api.Version{2, 0}, `jobs/?$`}:                                            &invalidationjobs.InvalidationJob{},
+	{api.Version{2, 0}, `origins/?$`}:                                         &origin.TOOrigin{},
+	{api.Version{2, 0}, `parameters/?$`}:                                      &parameter.TOParameter{},
+	{api.Version{2, 0}, `parameters/{id}$`}:                                   &parameter.TOParameter{},
+	{api.Version{2, 0}, `phys_locations/?$`}:                                  &physlocation.TOPhysLocation{},
+	{api.Version{2, 0}, `phys_locations/{id}$`}:                               &physlocation.TOPhysLocation{},
+	{api.Version{2, 0}, `profileparameters/?$`}:                               &profileparameter.TOProfileParameter{},
+	{api.Version{2, 0}, `profileparameters/{profileId}/{parameterId}$`}:       &profileparameter.TOProfileParameter{},
+	{api.Version{2, 0}, `profiles/?$`}:                                        &profile.TOProfile{},
+	{api.Version{2, 0}, `profiles/{id}$`}:                                     &profile.TOProfile{},
+	{api.Version{2, 0}, `regions/?$`}:                                         &region.TORegion{},
+	{api.Version{2, 0}, `regions/{id}$`}:                                      &region.TORegion{},
+	{api.Version{2, 0}, `roles/?$`}:                                           &role.TORole{},
+	{api.Version{2, 0}, `server_capabilities$`}:                               &servercapability.TOServerCapability{},
+	{api.Version{2, 0}, `server_server_capabilities/?$`}:                      &server.TOServerServerCapability{},
+	{api.Version{2, 0}, `servers/?$`}:                                         &tc.ServerNullableV2{},
+	{api.Version{2, 0}, `servers/{id}$`}:                                      &tc.ServerNullableV2{},
+	{api.Version{2, 0}, `servers/{id}/deliveryservices$`}:                     &dsserver.TODSSDeliveryService{},
+	{api.Version{2, 0}, `staticdnsentries/?$`}:                                &staticdnsentry.TOStaticDNSEntry{},
+	{api.Version{2, 0}, `statuses/?$`}:                                        &status.TOStatus{},
+	{api.Version{2, 0}, `statuses/{id}$`}:                                     &status.TOStatus{},
+	{api.Version{2, 0}, `steering/{deliveryservice}/targets/?$`}:              &steeringtargets.TOSteeringTargetV11{},
+	{api.Version{2, 0}, `steering/{deliveryservice}/targets/{target}/?$`}:     &steeringtargets.TOSteeringTargetV11{},
+	{api.Version{2, 0}, `tenants/?$`}:                                         &apitenant.TOTenant{},
+	{api.Version{2, 0}, `tenants/{id}$`}:                                      &apitenant.TOTenant{},
+	{api.Version{2, 0}, `types/?$`}:                                           &types.TOType{},
+	{api.Version{2, 0}, `types/{id}$`}:                                        &types.TOType{},
+	{api.Version{2, 0}, `users/?$`}:                                           &user.TOUser{},
+	{api.Version{2, 0}, `users/{id}$`}:                                        &user.TOUser{},
+	{api.Version{2, 1}, `servers/?$`}:                                         &server.TOServer{},
+	{api.Version{2, 1}, `servers/{id}$`}:                                      &server.TOServer{},
+}
+
+// openAPISecurityScheme is the name of the security scheme of the Traffic Ops login cookie.
+const openAPISecurityScheme = "cookie"
+
+// OpenAPIDocument returns the OpenAPI document of the API of the given version, which is the routes of its major version with the same or an older minor version.
+func OpenAPIDocument(routes []Route, version api.Version) *openapi.Document {
+	versionStr := strconv.FormatUint(version.Major, 10) + "." + strconv.FormatUint(version.Minor, 10)
+	doc := &openapi.Document{
+		OpenAPI: openapi.Version,
+		Info: openapi.Info{
+			Title:       "Traffic Ops API",
+			Description: "This document is generated from the Traffic Ops routes. Request and response bodies are only described for routes which create, read, update and delete objects.",
+			Version:     versionStr,
+		},
+		Servers: []openapi.Server{{URL: "/api/" + versionStr}},
+		Paths:   map[string]openapi.PathItem{},
+		Components: openapi.Components{
+			SecuritySchemes: map[string]openapi.SecurityScheme{
+				openAPISecurityScheme: {Type: "apiKey", In: "cookie", Name: tocookie.Name},
+			},
+		},
+		Security: []openapi.SecurityRequirement{{openAPISecurityScheme: []string{}}},
+	}
+
+	schemas := openapi.NewSchemas()
+	alerts := schemas.Of(reflect.TypeOf([]tc.Alert{}))
+	operationIDs := map[string]struct{}{}
+	for _, r := range latestRoutes(routes, version) {
+		path := openAPIPath(r.Path)
+		op := &openapi.Operation{
+			OperationID: openAPIOperationID(r.Method, path),
+			Tags:        []string{strings.SplitN(strings.TrimPrefix(path, "/"), "/", 2)[0]},
+			Parameters:  openAPIPathParams(path),
+			Responses:   map[string]openapi.Response{},
+			RouteID:     r.ID,
+			PrivLevel:   r.RequiredPrivLevel,
+		}
+		if _, ok := operationIDs[op.OperationID]; ok {
+			op.OperationID += strconv.Itoa(r.ID)
+		}
+		operationIDs[op.OperationID] = struct{}{}
+		if !r.Authenticated {
+			op.Security = &[]openapi.SecurityRequirement{}
+		}
+
+		success := openapi.Response{Description: "Success", Content: openapi.JSONContent(&openapi.Schema{})}
+		if obj, ok := openAPIObjects[openAPIRoute{r.Version, r.Path}]; ok {
+			objSchema := schemas.Of(reflect.TypeOf(obj))
+			response := map[string]*openapi.Schema{"alerts": alerts}
+			switch r.Method {
+			case http.MethodGet:
+				response["response"] = &openapi.Schema{Type: "array", Items: objSchema}
+			case http.MethodPost, http.MethodPut:
+				reqSchema := objSchema
+				if required := requiredFields(obj); len(required) > 0 {
+					reqSchema = &openapi.Schema{AllOf: []*openapi.Schema{objSchema, {Required: required}}}
+				}
+				op.RequestBody = &openapi.RequestBody{Required: true, Content: openapi.JSONContent(reqSchema)}
+				response["response"] = objSchema
+			}
+			success.Content = openapi.JSONContent(openapi.Object(response))
+		}
+		op.Responses[strconv.Itoa(http.StatusOK)] = success
+		op.Responses["default"] = openapi.Response{Description: "Error", Content: openapi.JSONContent(openapi.Object(map[string]*openapi.Schema{"alerts": alerts}))}
+
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = openapi.PathItem{}
+		}
+		doc.Paths[path][strings.ToLower(r.Method)] = op
+	}
+	doc.Components.Schemas = schemas.Components
+	return doc
+}
+
+// latestRoutes returns the routes of the given API version, in order. Where a method and path has routes of multiple minor versions, the latest is returned.
+func latestRoutes(routes []Route, version api.Version) []Route {
+	latest := []Route{}
+	indices := map[string]int{}
+	for _, r := range routes {
+		if r.Version.Major != version.Major || r.Version.Minor > version.Minor {
+			continue
+		}
+		key := r.Method + " " + openAPIPath(r.Path)
+		if i, ok := indices[key]; ok {
+			if latest[i].Version.Minor < r.Version.Minor {
+				latest[i] = r
+			}
+			continue
+		}
+		indices[key] = len(latest)
+		latest = append(latest, r)
+	}
+	return latest
+}
+
+// openAPIPath returns the OpenAPI path of a Route path, which is a regular expression. Route paths are all literal, except for optional trailing characters like slashes and '.json', which are removed.
+func openAPIPath(routePath string) string {
+	path := strings.TrimSuffix(routePath, "$")
+	path = strings.TrimSuffix(path, `(\.json)?`)
+	path = strings.TrimSuffix(path, "/?")
+	path = strings.TrimSuffix(path, "?")
+	return "/" + path
+}
+
+var openAPIPathParam = regexp.MustCompile(`{([^}]+)}`)
+
+// openAPIPathParams returns the parameters of the given OpenAPI path. Parameters which are IDs are integers, and the rest are strings.
+func openAPIPathParams(path string) []openapi.Parameter {
+	params := []openapi.Parameter{}
+	for _, match := range openAPIPathParam.FindAllStringSubmatch(path, -1) {
+		name := strings.ToLower(match[1])
+		schema := &openapi.Schema{Type: "string"}
+		if strings.HasSuffix(name, "id") && !strings.HasPrefix(name, "xml") {
+			schema = &openapi.Schema{Type: "integer"}
+		}
+		params = append(params, openapi.Parameter{Name: match[1], In: "path", Required: true, Schema: schema})
+	}
+	return params
+}
+
+// openAPIOperationID returns the operation ID of a method and OpenAPI path, like getCDNsByID for GET /cdns/{id}.
+func openAPIOperationID(method string, path string) string {
+	id := strings.ToLower(method)
+	for _, segment := range strings.Split(strings.TrimPrefix(path, "/"), "/") {
+		if strings.HasPrefix(segment, "{") {
+			id += "By"
+			segment = strings.Trim(segment, "{}")
+		}
+		for _, word := range strings.FieldsFunc(segment, func(r rune) bool { return r == '_' || r == '-' || r == '.' }) {
+			id += strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return id
+}
+
+var requiredFieldErr = regexp.MustCompile(`'([^']+)' (?:cannot be blank|is required)`)
+
+// requiredFields returns the fields which the given object's Validate requires, which are found by validating an empty object.
+// Objects whose validation needs a transaction can't be validated without one, so only fields checked without a transaction are found.
+func requiredFields(obj interface{}) (fields []string) {
+	t := reflect.TypeOf(obj)
+	if t.Kind() != reflect.Ptr {
+		return nil
+	}
+	validator, ok := reflect.New(t.Elem()).Interface().(api.Validator)
+	if !ok {
+		return nil
+	}
+	defer func() {
+		if recover() != nil {
+			fields = nil
+		}
+	}()
+	err := validator.Validate()
+	if err == nil {
+		return nil
+	}
+	fieldSet := map[string]struct{}{}
+	for _, match := range requiredFieldErr.FindAllStringSubmatch(err.Error(), -1) {
+		fieldSet[match[1]] = struct{}{}
+	}
+	for field := range fieldSet {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// openAPIHandler serves the OpenAPI document of the requested API version, describing the given routes.
+func openAPIHandler(routes *[]Route) http.HandlerFunc {
+	docs := map[api.Version][]byte{}
+	docsMutex := sync.Mutex{}
+	return func(w http.ResponseWriter, r *http.Request) {
+		pathParts := strings.Split(r.URL.Path, "/")
+		if len(pathParts) < 3 {
+			api.HandleErr(w, r, nil, http.StatusNotFound, nil, nil)
+			return
+		}
+		version, err := stringVersionToApiVersion(pathParts[2])
+		if err != nil {
+			api.HandleErr(w, r, nil, http.StatusNotFound, errors.New("unknown API version"), nil)
+			return
+		}
+
+		docsMutex.Lock()
+		bts, ok := docs[version]
+		if !ok {
+			bts, err = json.MarshalIndent(OpenAPIDocument(*routes, version), "", "\t")
+			if err == nil { // failures aren't cached, so they're retried on the next request
+				docs[version] = bts
+			}
+		}
+		docsMutex.Unlock()
+		if err != nil {
+			api.HandleErr(w, r, nil, http.StatusInternalServerError, nil, errors.New("marshalling OpenAPI document: "+err.Error()))
+			return
+		}
+		w.Header().Set(rfc.ContentType, rfc.ApplicationJSON)
+		w.Write(bts)
+	}
+}
@@ -0,0 +1,127 @@
+package routing
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/config"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/openapi"
+)
+
+// openAPIDocPath is the OpenAPI document of the latest 2.x API, which is published with the documentation.
+const openAPIDocPath = "../../../docs/source/api/v2/openapi.json"
+
+var updateOpenAPI = flag.Bool("update-openapi", false, "write the generated OpenAPI document to "+openAPIDocPath+", rather than checking it's current")
+
+func latestAPIVersion(routes []Route, major uint64) api.Version {
+	latest := api.Version{}
+	for _, version := range getSortedRouteVersions(routes) {
+		if version.Major == major {
+			latest = version
+		}
+	}
+	return latest
+}
+
+// TestOpenAPIDocument checks that the published OpenAPI document is the one generated from the routes, so routes can't be changed without updating it.
+func TestOpenAPIDocument(t *testing.T) {
+	routes, _, _, err := Routes(ServerData{Config: config.NewFakeConfig()})
+	if err != nil {
+		t.Fatalf("getting routes: %v", err)
+	}
+	bts, err := json.MarshalIndent(OpenAPIDocument(routes, latestAPIVersion(routes, 2)), "", "\t")
+	if err != nil {
+		t.Fatalf("marshalling OpenAPI document: %v", err)
+	}
+	bts = append(bts, '\n')
+
+	if *updateOpenAPI {
+		if err := ioutil.WriteFile(openAPIDocPath, bts, 0644); err != nil {
+			t.Fatalf("writing OpenAPI document: %v", err)
+		}
+		return
+	}
+	published, err := ioutil.ReadFile(openAPIDocPath)
+	if err != nil {
+		t.Fatalf("reading OpenAPI document: %v", err)
+	}
+	if !bytes.Equal(published, bts) {
+		t.Errorf("%s is not the document generated from the routes, regenerate it with: go test ./traffic_ops/traffic_ops_golang/routing -run TestOpenAPIDocument -update-openapi", openAPIDocPath)
+	}
+}
+
+func TestOpenAPIObjects(t *testing.T) {
+	routes, _, _, err := Routes(ServerData{Config: config.NewFakeConfig()})
+	if err != nil {
+		t.Fatalf("getting routes: %v", err)
+	}
+	routePaths := map[openAPIRoute]struct{}{}
+	for _, r := range routes {
+		routePaths[openAPIRoute{r.Version, r.Path}] = struct{}{}
+	}
+	for route := range openAPIObjects {
+		if _, ok := routePaths[route]; !ok {
+			t.Errorf("OpenAPI object of %d.%d %s expected to be for a route, actual no route with that path", route.Version.Major, route.Version.Minor, route.Path)
+		}
+	}
+
+	doc := OpenAPIDocument(routes, api.Version{2, 0})
+	op := doc.Paths["/cdns"]["post"]
+	if op == nil || op.RequestBody == nil {
+		t.Fatalf("OpenAPI document expected a POST /cdns request body, actual %+v", op)
+	}
+	schema := op.RequestBody.Content["application/json"].Schema
+	if len(schema.AllOf) != 2 || schema.AllOf[0].Ref != "#/components/schemas/CDNNullable" || len(schema.AllOf[1].Required) != 2 {
+		t.Errorf("OpenAPI document expected POST /cdns to require the name and domainName of a CDNNullable, actual %+v", schema)
+	}
+	if _, ok := doc.Paths["/servers"]; !ok || doc.Servers[0].URL != "/api/2.0" {
+		t.Errorf("OpenAPI document for 2.0 expected /servers served at /api/2.0, actual %+v", doc.Servers)
+	}
+	if op := doc.Paths["/ping"]["get"]; op == nil || op.Security == nil || len(*op.Security) != 0 {
+		t.Errorf("OpenAPI document expected GET /ping to require no authentication, actual %+v", op)
+	}
+}
+
+func TestOpenAPIHandler(t *testing.T) {
+	routes, _, _, err := Routes(ServerData{Config: config.NewFakeConfig()})
+	if err != nil {
+		t.Fatalf("getting routes: %v", err)
+	}
+	w := httptest.NewRecorder()
+	openAPIHandler(&routes)(w, httptest.NewRequest(http.MethodGet, "/api/2.0/openapi.json", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET openapi.json expected status %d, actual %d", http.StatusOK, w.Code)
+	}
+	doc := openapi.Document{}
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("GET openapi.json expected a JSON document, actual error: %v", err)
+	}
+	if doc.Info.Version != "2.0" || doc.Paths["/openapi.json"]["get"] == nil {
+		t.Errorf("GET openapi.json expected the 2.0 document including itself, actual version %s", doc.Info.Version)
+	}
+}
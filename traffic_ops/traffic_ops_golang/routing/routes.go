@@ -62,8 +62,10 @@ import (
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/deliveryservicerequests"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/deliveryservicesregexes"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/division"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/event"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/federation_resolvers"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/federations"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/graphql"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/hwinfo"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/invalidationjobs"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/iso"
@@ -88,6 +90,7 @@ import (
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/steering"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/steeringtargets"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/systeminfo"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/topology"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/trafficstats"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/types"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/urisigning"
@@ -194,6 +197,10 @@ func Routes(d ServerData) ([]Route, []RawRoute, http.Handler, error) {
 		//CDN: CRUD
 		{api.Version{2, 0}, http.MethodDelete, `cdns/name/{name}$`, cdn.DeleteName, auth.PrivLevelOperations, Authenticated, nil, 208804959, noPerlBypass},
 
+		//CDN: export and import
+		{api.Version{2, 0}, http.MethodGet, `cdns/{id}/export/?$`, cdn.Export, auth.PrivLevelOperations, Authenticated, nil, 1077916805, noPerlBypass},
+		{api.Version{2, 0}, http.MethodPost, `cdns/import/?$`, cdn.Import, auth.PrivLevelOperations, Authenticated, nil, 3786274868, noPerlBypass},
+
 		//CDN: queue updates
 		{api.Version{2, 0}, http.MethodPost, `cdns/{id}/queue_update$`, cdn.Queue, auth.PrivLevelOperations, Authenticated, nil, 221515980, noPerlBypass},
 		{api.Version{2, 0}, http.MethodPost, `cdns/dnsseckeys/generate?$`, cdn.CreateDNSSECKeys, auth.PrivLevelAdmin, Authenticated, nil, 275336, noPerlBypass},
@@ -221,8 +228,20 @@ func Routes(d ServerData) ([]Route, []RawRoute, http.Handler, error) {
 		{api.Version{2, 0}, http.MethodGet, `jobs/?$`, api.ReadHandler(&invalidationjobs.InvalidationJob{}), auth.PrivLevelReadOnly, Authenticated, nil, 2966782041, noPerlBypass},
 		{api.Version{2, 0}, http.MethodDelete, `jobs/?$`, invalidationjobs.Delete, auth.PrivLevelPortal, Authenticated, nil, 216780776, noPerlBypass},
 		{api.Version{2, 0}, http.MethodPut, `jobs/?$`, invalidationjobs.Update, auth.PrivLevelPortal, Authenticated, nil, 286134226, noPerlBypass},
+		{api.Version{2, 0}, http.MethodPost, `jobs/bulk/?$`, invalidationjobs.CreateBulk, auth.PrivLevelPortal, Authenticated, nil, 2446769209, noPerlBypass},
+		{api.Version{2, 0}, http.MethodGet, `jobs/{id}/status/?$`, invalidationjobs.GetStatus, auth.PrivLevelReadOnly, Authenticated, nil, 471836602, noPerlBypass},
+		{api.Version{2, 0}, http.MethodGet, `jobs/schedules/?$`, invalidationjobs.GetSchedules, auth.PrivLevelReadOnly, Authenticated, nil, 2144900474, noPerlBypass},
+		{api.Version{2, 0}, http.MethodPost, `jobs/schedules/?$`, invalidationjobs.CreateSchedule, auth.PrivLevelPortal, Authenticated, nil, 996688271, noPerlBypass},
+		{api.Version{2, 0}, http.MethodDelete, `jobs/schedules/?$`, invalidationjobs.DeleteSchedule, auth.PrivLevelPortal, Authenticated, nil, 853795474, noPerlBypass},
 		{api.Version{2, 0}, http.MethodPost, `jobs/?`, invalidationjobs.Create, auth.PrivLevelPortal, Authenticated, nil, 20450955, noPerlBypass},
 
+		//Events
+		{api.Version{2, 0}, http.MethodGet, `events/stream/?$`, event.StreamHandler(event.NewHub(d.DB)), auth.PrivLevelAdmin, Authenticated, middleware.GetStreaming(d.Config.Secrets[0]), 1948673174, noPerlBypass},
+		{api.Version{2, 0}, http.MethodGet, `webhooks/?$`, event.GetWebhooks, auth.PrivLevelAdmin, Authenticated, nil, 911710418, noPerlBypass},
+		{api.Version{2, 0}, http.MethodPost, `webhooks/?$`, event.CreateWebhook, auth.PrivLevelAdmin, Authenticated, nil, 3376163199, noPerlBypass},
+		{api.Version{2, 0}, http.MethodPut, `webhooks/{id}/?$`, event.UpdateWebhook, auth.PrivLevelAdmin, Authenticated, nil, 3851653273, noPerlBypass},
+		{api.Version{2, 0}, http.MethodDelete, `webhooks/{id}/?$`, event.DeleteWebhook, auth.PrivLevelAdmin, Authenticated, nil, 2506808692, noPerlBypass},
+
 		//Login
 		{api.Version{2, 0}, http.MethodPost, `user/login/?$`, login.LoginHandler(d.DB, d.Config), 0, NoAuth, nil, 2392670821, noPerlBypass},
 		{api.Version{2, 0}, http.MethodPost, `user/logout/?$`, login.LogoutHandler(d.Config.Secrets[0]), 0, Authenticated, nil, 243434825, noPerlBypass},
@@ -298,15 +317,26 @@ func Routes(d ServerData) ([]Route, []RawRoute, http.Handler, error) {
 		{api.Version{2, 0}, http.MethodPost, `servercheck/extensions$`, extensions.Create, auth.PrivLevelReadOnly, Authenticated, nil, 280498599, noPerlBypass},
 		{api.Version{2, 0}, http.MethodGet, `servercheck/extensions$`, extensions.Get, auth.PrivLevelReadOnly, Authenticated, nil, 283498599, noPerlBypass},
 		{api.Version{2, 0}, http.MethodDelete, `servercheck/extensions/{id}$`, extensions.Delete, auth.PrivLevelReadOnly, Authenticated, nil, 280498299, noPerlBypass},
+		{api.Version{2, 0}, http.MethodGet, `servercheck/rules/?$`, servercheck.GetRules, auth.PrivLevelReadOnly, Authenticated, nil, 1412816978, noPerlBypass},
+		{api.Version{2, 0}, http.MethodPost, `servercheck/rules/?$`, servercheck.CreateRule, auth.PrivLevelOperations, Authenticated, nil, 2278944857, noPerlBypass},
+		{api.Version{2, 0}, http.MethodPut, `servercheck/rules/{id}/?$`, servercheck.UpdateRule, auth.PrivLevelOperations, Authenticated, nil, 3442427264, noPerlBypass},
+		{api.Version{2, 0}, http.MethodDelete, `servercheck/rules/{id}/?$`, servercheck.DeleteRule, auth.PrivLevelOperations, Authenticated, nil, 933647025, noPerlBypass},
 
 		//Server Details
 		{api.Version{2, 0}, http.MethodGet, `servers/details/?$`, server.GetDetailParamHandler, auth.PrivLevelReadOnly, Authenticated, nil, 2261264714, noPerlBypass},
 
 		//Server status
 		{api.Version{2, 0}, http.MethodPut, `servers/{id}/status$`, server.UpdateStatusHandler, auth.PrivLevelOperations, Authenticated, nil, 276663851, noPerlBypass},
+		{api.Version{2, 0}, http.MethodGet, `servers/{id}/checks/?$`, servercheck.GetHistory, auth.PrivLevelReadOnly, Authenticated, nil, 1055859031, noPerlBypass},
 		{api.Version{2, 0}, http.MethodPost, `servers/{id}/queue_update$`, server.QueueUpdateHandler, auth.PrivLevelOperations, Authenticated, nil, 2189471, noPerlBypass},
 
 		//Server: CRUD
+		// Servers with a list of network interfaces
+		{api.Version{2, 1}, http.MethodGet, `servers/?$`, api.ReadHandler(&server.TOServer{}), auth.PrivLevelReadOnly, Authenticated, nil, 1812761097, noPerlBypass},
+		{api.Version{2, 1}, http.MethodPut, `servers/{id}$`, api.UpdateHandler(&server.TOServer{}), auth.PrivLevelOperations, Authenticated, nil, 3009705173, noPerlBypass},
+		{api.Version{2, 1}, http.MethodPost, `servers/?$`, api.CreateHandler(&server.TOServer{}), auth.PrivLevelOperations, Authenticated, nil, 3444271799, noPerlBypass},
+		{api.Version{2, 1}, http.MethodDelete, `servers/{id}$`, api.DeleteHandler(&server.TOServer{}), auth.PrivLevelOperations, Authenticated, nil, 2528745998, noPerlBypass},
+
 		{api.Version{2, 0}, http.MethodGet, `servers/?$`, api.ReadHandler(&server.TOServer{}), auth.PrivLevelReadOnly, Authenticated, nil, 2720959285, noPerlBypass},
 		{api.Version{2, 0}, http.MethodPut, `servers/{id}$`, api.UpdateHandler(&server.TOServer{}), auth.PrivLevelOperations, Authenticated, nil, 258634103, noPerlBypass},
 		{api.Version{2, 0}, http.MethodPost, `servers/?$`, api.CreateHandler(&server.TOServer{}), auth.PrivLevelOperations, Authenticated, nil, 2225558061, noPerlBypass},
@@ -433,6 +463,18 @@ func Routes(d ServerData) ([]Route, []RawRoute, http.Handler, error) {
 		{api.Version{2, 0}, http.MethodPost, `tenants/?$`, api.CreateHandler(&apitenant.TOTenant{}), auth.PrivLevelOperations, Authenticated, nil, 217248013, noPerlBypass},
 		{api.Version{2, 0}, http.MethodDelete, `tenants/{id}$`, api.DeleteHandler(&apitenant.TOTenant{}), auth.PrivLevelOperations, Authenticated, nil, 216365558, noPerlBypass},
 
+		//Tenant: quotas and usage
+		{api.Version{2, 0}, http.MethodGet, `tenants/{id}/quota/?$`, apitenant.GetQuota, auth.PrivLevelReadOnly, Authenticated, nil, 842431863, noPerlBypass},
+		{api.Version{2, 0}, http.MethodPut, `tenants/{id}/quota/?$`, apitenant.UpdateQuota, auth.PrivLevelOperations, Authenticated, nil, 2784995141, noPerlBypass},
+		{api.Version{2, 0}, http.MethodDelete, `tenants/{id}/quota/?$`, apitenant.DeleteQuota, auth.PrivLevelOperations, Authenticated, nil, 2156706582, noPerlBypass},
+		{api.Version{2, 0}, http.MethodGet, `tenants/usage/?$`, apitenant.GetUsage, auth.PrivLevelReadOnly, Authenticated, nil, 2954376836, noPerlBypass},
+
+		//Topologies
+		{api.Version{2, 0}, http.MethodGet, `topologies/?$`, topology.Get, auth.PrivLevelReadOnly, Authenticated, nil, 3976338068, noPerlBypass},
+		{api.Version{2, 0}, http.MethodPost, `topologies/?$`, topology.Create, auth.PrivLevelOperations, Authenticated, nil, 2448984352, noPerlBypass},
+		{api.Version{2, 0}, http.MethodPut, `topologies/?$`, topology.Update, auth.PrivLevelOperations, Authenticated, nil, 252673206, noPerlBypass},
+		{api.Version{2, 0}, http.MethodDelete, `topologies/?$`, topology.Delete, auth.PrivLevelOperations, Authenticated, nil, 1488166739, noPerlBypass},
+
 		//CRConfig
 		{api.Version{2, 0}, http.MethodGet, `cdns/{cdn}/snapshot/?$`, crconfig.SnapshotGetHandler, auth.PrivLevelReadOnly, Authenticated, nil, 2957273695, noPerlBypass},
 		{api.Version{2, 0}, http.MethodGet, `cdns/{cdn}/snapshot/new/?$`, crconfig.Handler, auth.PrivLevelReadOnly, Authenticated, nil, 276716889, noPerlBypass},
@@ -976,6 +1018,17 @@ func Routes(d ServerData) ([]Route, []RawRoute, http.Handler, error) {
 		{api.Version{1, 4}, http.MethodGet, `steering/?(\.json)?$`, steering.Get, auth.PrivLevelSteering, Authenticated, nil, 1174852457, noPerlBypass},
 	}
 
+	// GraphQL queries are authorized with the priv levels of the routes which read the same objects
+	graphQLHandler := graphql.Handler(graphQLPrivLevels(routes))
+	routes = append(routes,
+		Route{api.Version{2, 0}, http.MethodGet, `graphql/?$`, graphQLHandler, auth.PrivLevelReadOnly, Authenticated, nil, 2725758455, noPerlBypass},
+		Route{api.Version{2, 0}, http.MethodPost, `graphql/?$`, graphQLHandler, auth.PrivLevelReadOnly, Authenticated, nil, 2584987702, noPerlBypass},
+		Route{api.Version{2, 0}, http.MethodGet, `graphql/schema.graphql$`, graphql.SchemaHandler, auth.PrivLevelReadOnly, Authenticated, nil, 1535641527, noPerlBypass},
+	)
+
+	// the OpenAPI document describes all of the routes, including its own
+	routes = append(routes, Route{api.Version{2, 0}, http.MethodGet, `openapi.json$`, openAPIHandler(&routes), 0, NoAuth, nil, 1597776705, noPerlBypass})
+
 	// sanity check to make sure all Route IDs are unique
 	knownRouteIDs := make(map[int]struct{}, len(routes))
 	for _, r := range routes {
@@ -1012,6 +1065,11 @@ func Routes(d ServerData) ([]Route, []RawRoute, http.Handler, error) {
 			return nil, nil, nil, errors.New(msg)
 		}
 	}
+	for routeID := range d.RateLimit.Routes {
+		if _, known := knownRouteIDs[routeID]; !known {
+			log.Warnf("unknown route ID %d in rate_limit routes", routeID)
+		}
+	}
 
 	// rawRoutes are served at the root path. These should be almost exclusively old Perl pre-API routes, which have yet to be converted in all clients. New routes should be in the versioned API path.
 	rawRoutes := []RawRoute{
@@ -34,6 +34,7 @@ import (
 	"time"
 
 	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-tc"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/config"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/plugin"
@@ -145,8 +146,9 @@ type PathHandler struct {
 }
 
 // CreateRouteMap returns a map of methods to a slice of paths and handlers; wrapping the handlers in the appropriate middleware. Uses Semantic Versioning: routes are added to every subsequent minor version, but not subsequent major versions. For example, a 1.2 route is added to 1.3 but not 2.1. Also truncates '2.0' to '2', creating succinct major versions.
+// If rateLimiter isn't nil, it limits the rate of requests to every route.
 // Returns the map of routes, and a map of API versions served.
-func CreateRouteMap(rs []Route, rawRoutes []RawRoute, perlRouteIDs, disabledRouteIDs []int, perlHandler http.HandlerFunc, authBase middleware.AuthBase, reqTimeOutSeconds int) (map[string][]PathHandler, map[api.Version]struct{}) {
+func CreateRouteMap(rs []Route, rawRoutes []RawRoute, perlRouteIDs, disabledRouteIDs []int, perlHandler http.HandlerFunc, authBase middleware.AuthBase, reqTimeOutSeconds int, rateLimiter *middleware.RateLimiter) (map[string][]PathHandler, map[api.Version]struct{}) {
 	// TODO strong types for method, path
 	versions := getSortedRouteVersions(rs)
 	requestTimeout := middleware.DefaultRequestTimeout
@@ -167,21 +169,23 @@ func CreateRouteMap(rs []Route, rawRoutes []RawRoute, perlRouteIDs, disabledRout
 			}
 			vstr := strconv.FormatUint(version.Major, 10) + "." + strconv.FormatUint(version.Minor, 10)
 			path := RoutePrefix + "/" + vstr + "/" + r.Path
-			middlewares := getRouteMiddleware(r.Middlewares, authBase, r.Authenticated, r.RequiredPrivLevel, requestTimeout)
+			middlewares := getRouteMiddleware(r.Middlewares, authBase, r.Authenticated, r.RequiredPrivLevel, requestTimeout, rateLimiter, r.ID)
 
 			if isPerlRoute {
 				m[r.Method] = append(m[r.Method], PathHandler{Path: path, Handler: perlHandler})
 			} else if isDisabledRoute {
 				m[r.Method] = append(m[r.Method], PathHandler{Path: path, Handler: middleware.WrapAccessLog(authBase.Secret, middleware.DisabledRouteHandler())})
 			} else {
-				m[r.Method] = append(m[r.Method], PathHandler{Path: path, Handler: middleware.Use(r.Handler, middlewares)})
+				handler := middleware.WrapTrace("route "+strconv.Itoa(r.ID), "api/"+vstr+"/"+r.Path, middleware.Use(r.Handler, middlewares))
+				m[r.Method] = append(m[r.Method], PathHandler{Path: path, Handler: handler})
 			}
 			log.Infof("adding route %v %v\n", r.Method, path)
 		}
 	}
 	for _, r := range rawRoutes {
-		middlewares := getRouteMiddleware(r.Middlewares, authBase, r.Authenticated, r.RequiredPrivLevel, requestTimeout)
-		m[r.Method] = append(m[r.Method], PathHandler{Path: r.Path, Handler: middleware.Use(r.Handler, middlewares)})
+		middlewares := getRouteMiddleware(r.Middlewares, authBase, r.Authenticated, r.RequiredPrivLevel, requestTimeout, rateLimiter, 0)
+		handler := middleware.WrapTrace(r.Method+" "+r.Path, r.Path, middleware.Use(r.Handler, middlewares))
+		m[r.Method] = append(m[r.Method], PathHandler{Path: r.Path, Handler: handler})
 		log.Infof("adding raw route %v %v\n", r.Method, r.Path)
 	}
 
@@ -193,7 +197,7 @@ func CreateRouteMap(rs []Route, rawRoutes []RawRoute, perlRouteIDs, disabledRout
 	return m, versionSet
 }
 
-func getRouteMiddleware(middlewares []middleware.Middleware, authBase middleware.AuthBase, authenticated bool, privLevel int, requestTimeout time.Duration) []middleware.Middleware {
+func getRouteMiddleware(middlewares []middleware.Middleware, authBase middleware.AuthBase, authenticated bool, privLevel int, requestTimeout time.Duration, rateLimiter *middleware.RateLimiter, routeID int) []middleware.Middleware {
 	if middlewares == nil {
 		middlewares = middleware.GetDefault(authBase.Secret, requestTimeout)
 	}
@@ -201,6 +205,9 @@ func getRouteMiddleware(middlewares []middleware.Middleware, authBase middleware
 		authWrapper := authBase.GetWrapper(privLevel)
 		middlewares = append(middlewares, authWrapper)
 	}
+	if rateLimiter != nil { // after authentication, to limit requests by user
+		middlewares = append(middlewares, rateLimiter.GetWrapper(routeID))
+	}
 	return middlewares
 }
 
@@ -243,17 +250,23 @@ func Handler(
 ) {
 	reqID := getReqID()
 
-	reqIDStr := strconv.FormatUint(reqID, 10)
-	log.Infoln(r.Method + " " + r.URL.Path + "?" + r.URL.RawQuery + " handling (reqid " + reqIDStr + ")")
+	// The correlation ID is set on the request too, so the access log and the Perl proxy have it.
+	correlationID := api.RequestCorrelationID(r)
+	r.Header.Set(tc.CorrelationIDHeader, correlationID)
+	w.Header().Set(tc.CorrelationIDHeader, correlationID)
+
+	ids := "reqid " + strconv.FormatUint(reqID, 10) + ", correlation ID " + correlationID
+	log.Infoln(r.Method + " " + r.URL.Path + "?" + r.URL.RawQuery + " handling (" + ids + ")")
 	start := time.Now()
 	defer func() {
-		log.Infoln(r.Method + " " + r.URL.Path + "?" + r.URL.RawQuery + " handled (reqid " + reqIDStr + ") in " + time.Since(start).String())
+		log.Infoln(r.Method + " " + r.URL.Path + "?" + r.URL.RawQuery + " handled (" + ids + ") in " + time.Since(start).String())
 	}()
 
 	ctx := r.Context()
 	ctx = context.WithValue(ctx, api.DBContextKey, db)
 	ctx = context.WithValue(ctx, api.ConfigContextKey, cfg)
 	ctx = context.WithValue(ctx, api.ReqIDContextKey, reqID)
+	ctx = context.WithValue(ctx, api.CorrelationIDContextKey, correlationID)
 
 	// plugins have no pre-parsed path params, but add an empty map so they can use the api helper funcs that require it.
 	pluginCtx := context.WithValue(ctx, api.PathParamsKey, map[string]string{})
@@ -343,7 +356,7 @@ func RegisterRoutes(d ServerData) error {
 	}
 
 	authBase := middleware.AuthBase{Secret: d.Config.Secrets[0], Override: nil} //we know d.Config.Secrets is a slice of at least one or start up would fail.
-	routes, versions := CreateRouteMap(routeSlice, rawRoutes, d.PerlRoutes, d.DisabledRoutes, handlerToFunc(catchall), authBase, d.RequestTimeout)
+	routes, versions := CreateRouteMap(routeSlice, rawRoutes, d.PerlRoutes, d.DisabledRoutes, handlerToFunc(catchall), authBase, d.RequestTimeout, middleware.NewRateLimiter(d.RateLimit))
 
 	compiledRoutes := CompileRoutes(routes)
 	getReqID := nextReqIDGetter()
@@ -20,10 +20,12 @@ package routing
  */
 
 import (
+	"github.com/apache/trafficcontrol/lib/go-tc"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
 	"net/http"
 	"net/url"
 	"reflect"
+	"strings"
 	"testing"
 
 	"fmt"
@@ -34,6 +36,8 @@ import (
 
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/config"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/graphql"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/plugin"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/routing/middleware"
 )
 
@@ -149,7 +153,7 @@ func TestCompileRoutes(t *testing.T) {
 	}
 
 	authBase := middleware.AuthBase{Secret: d.Secrets[0], Override: nil}
-	routes, versions := CreateRouteMap(routeSlice, nil, nil, nil, nil, authBase, 1)
+	routes, versions := CreateRouteMap(routeSlice, nil, nil, nil, nil, authBase, 1, nil)
 	if len(routes) == 0 {
 		t.Error("no routes handler defined")
 	}
@@ -202,6 +206,55 @@ func TestRoutes(t *testing.T) {
 	}
 }
 
+func TestGraphQLPrivLevels(t *testing.T) {
+	routes, _, _, err := Routes(ServerData{Config: config.NewFakeConfig()})
+	if err != nil {
+		t.Fatalf("expected: no error getting Routes, actual: %v", err)
+	}
+	levels := graphQLPrivLevels(routes)
+
+	w := httptest.NewRecorder()
+	graphql.SchemaHandler(w, httptest.NewRequest(http.MethodGet, "/api/2.0/graphql/schema.graphql", nil))
+	for _, line := range strings.Split(w.Body.String(), "\n") {
+		if !strings.HasPrefix(line, "type ") || strings.HasPrefix(line, "type Query ") {
+			continue
+		}
+		typeName := strings.Fields(line)[1]
+		if _, ok := levels[typeName]; !ok {
+			t.Errorf("expected: a priv level for the GraphQL type %s, actual: no 2.0 GET route read its objects", typeName)
+		}
+	}
+}
+
+func TestHandlerCorrelationID(t *testing.T) {
+	cfg := config.NewFakeConfig()
+	handlerID := ""
+	routes := CompileRoutes(map[string][]PathHandler{http.MethodGet: {{Path: "api/2.0/cdns$", Handler: func(w http.ResponseWriter, r *http.Request) {
+		handlerID = api.GetCorrelationID(r.Context())
+	}}}})
+	getReqID := func() uint64 { return 1 }
+
+	for _, clientID := range []string{"portal-1234", "", "in valid"} {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/2.0/cdns", nil)
+		if clientID != "" {
+			r.Header.Set(tc.CorrelationIDHeader, clientID)
+		}
+		Handler(routes, map[api.Version]struct{}{{2, 0}: {}}, http.NotFoundHandler(), nil, &cfg, getReqID, plugin.Get(cfg), w, r)
+
+		respID := w.Header().Get(tc.CorrelationIDHeader)
+		if !api.IsValidCorrelationID(respID) {
+			t.Errorf("expected: a valid correlation ID in the response, actual: %q", respID)
+		}
+		if clientID == "portal-1234" && respID != clientID {
+			t.Errorf("expected: the client's correlation ID %s in the response, actual: %s", clientID, respID)
+		}
+		if handlerID != respID {
+			t.Errorf("expected: the handler to get the correlation ID %s, actual: %s", respID, handlerID)
+		}
+	}
+}
+
 func TestCreateRouteMap(t *testing.T) {
 	authBase := middleware.AuthBase{"secret", func(handlerFunc http.HandlerFunc) http.HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
@@ -251,7 +304,7 @@ func TestCreateRouteMap(t *testing.T) {
 	disabledRoutesIDs := []int{4}
 
 	rawRoutes := []RawRoute{}
-	routeMap, _ := CreateRouteMap(routes, rawRoutes, perlRoutesIDs, disabledRoutesIDs, CatchallHandler, authBase, 60)
+	routeMap, _ := CreateRouteMap(routes, rawRoutes, perlRoutesIDs, disabledRoutesIDs, CatchallHandler, authBase, 60, nil)
 
 	route1Handler := routeMap["GET"][0].Handler
 
@@ -0,0 +1,318 @@
+package server
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+
+	"github.com/lib/pq"
+)
+
+// MinInterfaceMTU is the smallest MTU an interface may have, which is the minimum MTU of IPv6.
+const MinInterfaceMTU = 1280
+
+// supportsInterfaces returns whether the given API version represents servers with a list of
+// network interfaces, rather than the legacy single interface fields.
+func supportsInterfaces(version *api.Version) bool {
+	return version.Major > 2 || (version.Major == 2 && version.Minor >= 1)
+}
+
+// validateInterfaces checks the interfaces of a server. Every server must have exactly one
+// interface with service addresses, which has at most one IPv4 and one IPv6 service address.
+func validateInterfaces(interfaces []tc.ServerInterfaceInfo) []error {
+	if len(interfaces) == 0 {
+		return []error{errors.New("interfaces: a server must have at least one interface")}
+	}
+	errs := []error{}
+	names := map[string]struct{}{}
+	serviceInterface := ""
+	for _, iface := range interfaces {
+		if iface.Name == "" {
+			errs = append(errs, errors.New("interfaces: name cannot be blank"))
+			continue
+		}
+		if _, ok := names[iface.Name]; ok {
+			errs = append(errs, errors.New("interfaces: duplicate interface name '"+iface.Name+"'"))
+		}
+		names[iface.Name] = struct{}{}
+		if iface.MTU != nil && *iface.MTU < MinInterfaceMTU {
+			errs = append(errs, fmt.Errorf("interface '%s': mtu must be at least %d", iface.Name, MinInterfaceMTU))
+		}
+		if len(iface.IPAddresses) == 0 {
+			errs = append(errs, errors.New("interface '"+iface.Name+"': must have at least one IP address"))
+		}
+
+		addrs := map[string]struct{}{}
+		serviceV4 := 0
+		serviceV6 := 0
+		for _, addr := range iface.IPAddresses {
+			ip := addr.IP()
+			if ip == nil {
+				errs = append(errs, errors.New("interface '"+iface.Name+"': address '"+addr.Address+"' is not a valid IP address or CIDR"))
+				continue
+			}
+			if _, ok := addrs[ip.String()]; ok {
+				errs = append(errs, errors.New("interface '"+iface.Name+"': duplicate address '"+addr.Address+"'"))
+			}
+			addrs[ip.String()] = struct{}{}
+			if addr.Gateway != nil {
+				if gw := net.ParseIP(*addr.Gateway); gw == nil {
+					errs = append(errs, errors.New("interface '"+iface.Name+"': gateway '"+*addr.Gateway+"' is not a valid IP address"))
+				} else if (gw.To4() == nil) != (ip.To4() == nil) {
+					errs = append(errs, errors.New("interface '"+iface.Name+"': gateway '"+*addr.Gateway+"' is not the same IP version as address '"+addr.Address+"'"))
+				}
+			}
+			if !addr.ServiceAddress {
+				continue
+			}
+			if ip.To4() != nil {
+				serviceV4++
+			} else {
+				serviceV6++
+			}
+		}
+		if serviceV4 > 1 || serviceV6 > 1 {
+			errs = append(errs, errors.New("interface '"+iface.Name+"': can have at most one IPv4 and one IPv6 service address"))
+		}
+		if serviceV4+serviceV6 == 0 {
+			continue
+		}
+		if serviceInterface != "" {
+			errs = append(errs, errors.New("interfaces: service addresses must all be on one interface, but '"+serviceInterface+"' and '"+iface.Name+"' both have them"))
+		}
+		serviceInterface = iface.Name
+	}
+	if serviceInterface == "" && len(errs) == 0 {
+		errs = append(errs, tc.NeedsAtLeastOneServiceAddressError)
+	}
+	return errs
+}
+
+// setLegacyInterfaceFields sets the legacy single interface fields of the server from its service
+// interface, so clients of older API versions see the service addresses. The interfaces must
+// already be valid.
+func (s *TOServer) setLegacyInterfaceFields() {
+	iface, ok := tc.GetServiceInterface(s.Interfaces)
+	if !ok {
+		return
+	}
+	name := iface.Name
+	s.InterfaceName = &name
+	mtu := JumboFrameBPS
+	if iface.MTU != nil {
+		mtu = int(*iface.MTU)
+	}
+	s.InterfaceMtu = &mtu
+
+	ipv4, ipv6 := iface.ServiceAddresses()
+	s.IPIsService = util.BoolPtr(ipv4 != nil)
+	s.IPAddress, s.IPNetmask, s.IPGateway = nil, nil, nil
+	if ipv4 != nil {
+		ip := ipv4.IP().String()
+		s.IPAddress = &ip
+		netmask := net.IP(net.CIDRMask(32, 32)).String()
+		if _, cidr, err := net.ParseCIDR(ipv4.Address); err == nil {
+			netmask = net.IP(cidr.Mask).String()
+		}
+		s.IPNetmask = &netmask
+		gateway := ""
+		if ipv4.Gateway != nil {
+			gateway = *ipv4.Gateway
+		}
+		s.IPGateway = &gateway
+	}
+
+	s.IP6IsService = util.BoolPtr(ipv6 != nil)
+	s.IP6Address, s.IP6Gateway = nil, nil
+	if ipv6 != nil {
+		ip6 := ipv6.Address
+		s.IP6Address = &ip6
+		s.IP6Gateway = ipv6.Gateway
+	}
+}
+
+// getInterfaces returns the interfaces of each of the given servers, keyed by server ID.
+func getInterfaces(tx *sql.Tx, serverIDs []int) (map[int][]tc.ServerInterfaceInfo, error) {
+	ids := make([]int64, 0, len(serverIDs))
+	for _, id := range serverIDs {
+		ids = append(ids, int64(id))
+	}
+
+	qry := `
+SELECT i.server, i.name, i.max_bandwidth, i.monitor, i.mtu
+FROM interface AS i
+WHERE i.server = ANY($1)
+ORDER BY i.server, i.name
+`
+	rows, err := tx.Query(qry, pq.Array(ids))
+	if err != nil {
+		return nil, errors.New("querying server interfaces: " + err.Error())
+	}
+	defer rows.Close()
+
+	interfaces := map[int][]tc.ServerInterfaceInfo{}
+	for rows.Next() {
+		serverID := 0
+		iface := tc.ServerInterfaceInfo{IPAddresses: []tc.ServerIPAddress{}}
+		maxBandwidth := sql.NullInt64{}
+		mtu := sql.NullInt64{}
+		if err := rows.Scan(&serverID, &iface.Name, &maxBandwidth, &iface.Monitor, &mtu); err != nil {
+			return nil, errors.New("scanning server interfaces: " + err.Error())
+		}
+		if maxBandwidth.Valid {
+			v := uint64(maxBandwidth.Int64)
+			iface.MaxBandwidth = &v
+		}
+		if mtu.Valid {
+			v := uint64(mtu.Int64)
+			iface.MTU = &v
+		}
+		interfaces[serverID] = append(interfaces[serverID], iface)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.New("iterating server interfaces: " + err.Error())
+	}
+
+	qry = `
+SELECT ip.server, ip.interface, ip.address, ip.gateway, ip.service_address
+FROM ip_address AS ip
+WHERE ip.server = ANY($1)
+ORDER BY ip.server, ip.interface, ip.address
+`
+	addrRows, err := tx.Query(qry, pq.Array(ids))
+	if err != nil {
+		return nil, errors.New("querying server IP addresses: " + err.Error())
+	}
+	defer addrRows.Close()
+
+	for addrRows.Next() {
+		serverID := 0
+		ifaceName := ""
+		addr := tc.ServerIPAddress{}
+		if err := addrRows.Scan(&serverID, &ifaceName, &addr.Address, &addr.Gateway, &addr.ServiceAddress); err != nil {
+			return nil, errors.New("scanning server IP addresses: " + err.Error())
+		}
+		ifaces := interfaces[serverID]
+		for i := range ifaces {
+			if ifaces[i].Name == ifaceName {
+				ifaces[i].IPAddresses = append(ifaces[i].IPAddresses, addr)
+				break
+			}
+		}
+	}
+	if err := addrRows.Err(); err != nil {
+		return nil, errors.New("iterating server IP addresses: " + err.Error())
+	}
+	return interfaces, nil
+}
+
+// replaceInterfaces replaces all interfaces and IP addresses of the given server.
+func replaceInterfaces(tx *sql.Tx, serverID int, interfaces []tc.ServerInterfaceInfo) error {
+	if _, err := tx.Exec(`DELETE FROM interface WHERE server = $1`, serverID); err != nil {
+		return errors.New("deleting server interfaces: " + err.Error())
+	}
+	for _, iface := range interfaces {
+		if _, err := tx.Exec(`INSERT INTO interface (server, name, max_bandwidth, monitor, mtu) VALUES ($1, $2, $3, $4, $5)`, serverID, iface.Name, nullableUint(iface.MaxBandwidth), iface.Monitor, nullableUint(iface.MTU)); err != nil {
+			return errors.New("inserting server interface '" + iface.Name + "': " + err.Error())
+		}
+		for _, addr := range iface.IPAddresses {
+			if err := insertIPAddress(tx, serverID, iface.Name, addr); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// syncLegacyInterface makes the interface named by the legacy fields of a server written through
+// an older API version hold exactly the legacy addresses, and makes it the service interface.
+// Other interfaces are kept, but their addresses are no longer service addresses. The oldName is
+// the legacy interface name before the write, or nil for a new server.
+func syncLegacyInterface(tx *sql.Tx, s *TOServer, oldName *string) error {
+	serverID := *s.ID
+	name := *s.InterfaceName
+	if oldName != nil && *oldName != name {
+		if _, err := tx.Exec(`UPDATE interface SET name = $3 WHERE server = $1 AND name = $2 AND NOT EXISTS (SELECT 1 FROM interface WHERE server = $1 AND name = $3)`, serverID, *oldName, name); err != nil {
+			return errors.New("renaming server interface: " + err.Error())
+		}
+	}
+	mtu := JumboFrameBPS
+	if s.InterfaceMtu != nil {
+		mtu = *s.InterfaceMtu
+	}
+	if _, err := tx.Exec(`INSERT INTO interface (server, name, monitor, mtu) VALUES ($1, $2, TRUE, $3) ON CONFLICT (server, name) DO UPDATE SET mtu = EXCLUDED.mtu`, serverID, name, mtu); err != nil {
+		return errors.New("upserting server interface: " + err.Error())
+	}
+	if _, err := tx.Exec(`UPDATE ip_address SET service_address = FALSE WHERE server = $1 AND interface <> $2 AND service_address`, serverID, name); err != nil {
+		return errors.New("clearing other interfaces' service addresses: " + err.Error())
+	}
+	if _, err := tx.Exec(`DELETE FROM ip_address WHERE server = $1 AND interface = $2`, serverID, name); err != nil {
+		return errors.New("deleting server interface addresses: " + err.Error())
+	}
+
+	if s.IPAddress != nil && *s.IPAddress != "" {
+		addr := tc.ServerIPAddress{Address: *s.IPAddress, ServiceAddress: s.IPIsService != nil && *s.IPIsService}
+		if s.IPNetmask != nil {
+			if mask := net.ParseIP(*s.IPNetmask).To4(); mask != nil {
+				if ones, bits := net.IPMask(mask).Size(); bits != 0 {
+					addr.Address += "/" + strconv.Itoa(ones)
+				}
+			}
+		}
+		if s.IPGateway != nil && *s.IPGateway != "" {
+			addr.Gateway = s.IPGateway
+		}
+		if err := insertIPAddress(tx, serverID, name, addr); err != nil {
+			return err
+		}
+	}
+	if s.IP6Address != nil && *s.IP6Address != "" {
+		addr := tc.ServerIPAddress{Address: *s.IP6Address, ServiceAddress: s.IP6IsService != nil && *s.IP6IsService}
+		if s.IP6Gateway != nil && *s.IP6Gateway != "" {
+			addr.Gateway = s.IP6Gateway
+		}
+		if err := insertIPAddress(tx, serverID, name, addr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func insertIPAddress(tx *sql.Tx, serverID int, ifaceName string, addr tc.ServerIPAddress) error {
+	if _, err := tx.Exec(`INSERT INTO ip_address (address, gateway, interface, server, service_address) VALUES ($1, $2, $3, $4, $5)`, addr.Address, addr.Gateway, ifaceName, serverID, addr.ServiceAddress); err != nil {
+		return errors.New("inserting server interface '" + ifaceName + "' address '" + addr.Address + "': " + err.Error())
+	}
+	return nil
+}
+
+func nullableUint(v *uint64) interface{} {
+	if v == nil {
+		return nil
+	}
+	return int64(*v)
+}
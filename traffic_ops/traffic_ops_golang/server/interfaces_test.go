@@ -0,0 +1,133 @@
+package server
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
+)
+
+func TestValidateInterfaces(t *testing.T) {
+	mtu := uint64(1500)
+	valid := []tc.ServerInterfaceInfo{
+		{
+			Name: "bond0",
+			MTU:  &mtu,
+			IPAddresses: []tc.ServerIPAddress{
+				{Address: "192.0.2.1/24", Gateway: util.StrPtr("192.0.2.254"), ServiceAddress: true},
+				{Address: "2001:db8::1/64", ServiceAddress: true},
+				{Address: "192.0.2.2"},
+			},
+		},
+		{
+			Name:        "eth1",
+			IPAddresses: []tc.ServerIPAddress{{Address: "198.51.100.1"}},
+		},
+	}
+	if errs := validateInterfaces(valid); len(errs) != 0 {
+		t.Errorf("validateInterfaces valid interfaces expected: no errors, actual: %v", errs)
+	}
+
+	smallMTU := uint64(576)
+	invalids := map[string][]tc.ServerInterfaceInfo{
+		"at least one interface": nil,
+		"must be marked as a service address": {
+			{Name: "bond0", IPAddresses: []tc.ServerIPAddress{{Address: "192.0.2.1"}}},
+		},
+		"mtu must be at least": {
+			{Name: "bond0", MTU: &smallMTU, IPAddresses: []tc.ServerIPAddress{{Address: "192.0.2.1", ServiceAddress: true}}},
+		},
+		"duplicate interface name": {
+			{Name: "bond0", IPAddresses: []tc.ServerIPAddress{{Address: "192.0.2.1", ServiceAddress: true}}},
+			{Name: "bond0", IPAddresses: []tc.ServerIPAddress{{Address: "192.0.2.2"}}},
+		},
+		"at most one IPv4 and one IPv6 service address": {
+			{Name: "bond0", IPAddresses: []tc.ServerIPAddress{{Address: "192.0.2.1", ServiceAddress: true}, {Address: "192.0.2.2", ServiceAddress: true}}},
+		},
+		"must all be on one interface": {
+			{Name: "bond0", IPAddresses: []tc.ServerIPAddress{{Address: "192.0.2.1", ServiceAddress: true}}},
+			{Name: "bond1", IPAddresses: []tc.ServerIPAddress{{Address: "2001:db8::1", ServiceAddress: true}}},
+		},
+		"not a valid IP address or CIDR": {
+			{Name: "bond0", IPAddresses: []tc.ServerIPAddress{{Address: "192.0.2.1", ServiceAddress: true}, {Address: "not-an-ip"}}},
+		},
+		"not the same IP version": {
+			{Name: "bond0", IPAddresses: []tc.ServerIPAddress{{Address: "192.0.2.1", Gateway: util.StrPtr("2001:db8::ffff"), ServiceAddress: true}}},
+		},
+	}
+	for expected, interfaces := range invalids {
+		errs := validateInterfaces(interfaces)
+		if len(errs) == 0 {
+			t.Errorf("validateInterfaces expected: error containing '%s', actual: no errors", expected)
+			continue
+		}
+		if !strings.Contains(util.JoinErrs(errs).Error(), expected) {
+			t.Errorf("validateInterfaces expected: error containing '%s', actual: %v", expected, errs)
+		}
+	}
+}
+
+func TestSetLegacyInterfaceFields(t *testing.T) {
+	mtu := uint64(1500)
+	s := TOServer{}
+	s.Interfaces = []tc.ServerInterfaceInfo{
+		{
+			Name:        "eth1",
+			IPAddresses: []tc.ServerIPAddress{{Address: "198.51.100.1"}},
+		},
+		{
+			Name: "bond0",
+			MTU:  &mtu,
+			IPAddresses: []tc.ServerIPAddress{
+				{Address: "192.0.2.2"},
+				{Address: "192.0.2.1/24", Gateway: util.StrPtr("192.0.2.254"), ServiceAddress: true},
+			},
+		},
+	}
+	s.setLegacyInterfaceFields()
+
+	if s.InterfaceName == nil || *s.InterfaceName != "bond0" {
+		t.Errorf("interfaceName expected: bond0, actual: %v", s.InterfaceName)
+	}
+	if s.InterfaceMtu == nil || *s.InterfaceMtu != 1500 {
+		t.Errorf("interfaceMtu expected: 1500, actual: %v", s.InterfaceMtu)
+	}
+	if s.IPAddress == nil || *s.IPAddress != "192.0.2.1" {
+		t.Errorf("ipAddress expected: 192.0.2.1, actual: %v", s.IPAddress)
+	}
+	if s.IPNetmask == nil || *s.IPNetmask != "255.255.255.0" {
+		t.Errorf("ipNetmask expected: 255.255.255.0, actual: %v", s.IPNetmask)
+	}
+	if s.IPGateway == nil || *s.IPGateway != "192.0.2.254" {
+		t.Errorf("ipGateway expected: 192.0.2.254, actual: %v", s.IPGateway)
+	}
+	if s.IPIsService == nil || !*s.IPIsService {
+		t.Errorf("ipIsService expected: true, actual: %v", s.IPIsService)
+	}
+	if s.IP6Address != nil {
+		t.Errorf("ip6Address expected: nil, actual: %v", *s.IP6Address)
+	}
+	if s.IP6IsService == nil || *s.IP6IsService {
+		t.Errorf("ip6IsService expected: false, actual: %v", s.IP6IsService)
+	}
+}
@@ -25,10 +25,12 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/apache/trafficcontrol/lib/go-tc"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
 )
 
@@ -55,6 +57,11 @@ func UpdateStatusHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if userErr, sysErr, errCode := api.CheckPreconditions(r, inf, &TOServer{}, map[string]string{"id": strconv.Itoa(inf.IntParams["id"])}); userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+
 	status := tc.StatusNullable{}
 	statusExists := false
 	if reqObj.Status.Name != nil {
@@ -80,29 +87,50 @@ func UpdateStatusHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		*reqObj.OfflineReason = inf.User.UserName + ": " + *reqObj.OfflineReason
-	} else {
-		reqObj.OfflineReason = nil
 	}
-	if err := updateServerStatusAndOfflineReason(inf.IntParams["id"], *status.ID, reqObj.OfflineReason, inf.Tx.Tx); err != nil {
+	msg, err := SetStatus(inf.Tx.Tx, inf.User, inf.IntParams["id"], serverInfo, status, reqObj.OfflineReason)
+	if err != nil {
 		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, err)
 		return
 	}
-	offlineReason := ""
-	if reqObj.OfflineReason != nil {
-		offlineReason = *reqObj.OfflineReason
+	api.WriteRespAlert(w, r, tc.SuccessLevel, msg)
+}
+
+// SetStatus sets the status of the server with the given ID and info, as the given user, queueing
+// updates on its child caches if it is an EDGE or MID. The offlineReason is only kept for the
+// ADMIN_DOWN and OFFLINE statuses. The change is recorded in the change log and as an Event, and
+// the returned message describes it.
+func SetStatus(tx *sql.Tx, user *auth.CurrentUser, serverID int, serverInfo tc.ServerInfo, status tc.StatusNullable, offlineReason *string) (string, error) {
+	if *status.Name != tc.CacheStatusAdminDown.String() && *status.Name != tc.CacheStatusOffline.String() {
+		offlineReason = nil
+	}
+	if err := updateServerStatusAndOfflineReason(serverID, *status.ID, offlineReason, tx); err != nil {
+		return "", err
 	}
-	msg := "Updated status [ " + *status.Name + " ] for " + serverInfo.HostName + "." + serverInfo.DomainName + " [ " + offlineReason + " ]"
+	reason := ""
+	if offlineReason != nil {
+		reason = *offlineReason
+	}
+	msg := "Updated status [ " + *status.Name + " ] for " + serverInfo.HostName + "." + serverInfo.DomainName + " [ " + reason + " ]"
 
 	// queue updates on child servers if server is ^EDGE or ^MID
 	if strings.HasPrefix(serverInfo.Type, tc.CacheTypeEdge.String()) || strings.HasPrefix(serverInfo.Type, tc.CacheTypeMid.String()) {
-		if err := queueUpdatesOnChildCaches(inf.Tx.Tx, serverInfo.CDNID, serverInfo.CachegroupID); err != nil {
-			api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, err)
-			return
+		if err := queueUpdatesOnChildCaches(tx, serverInfo.CDNID, serverInfo.CachegroupID); err != nil {
+			return "", err
 		}
 		msg += " and queued updates on all child caches"
 	}
-	api.CreateChangeLogRawTx(api.ApiChange, msg, inf.User, inf.Tx.Tx)
-	api.WriteRespAlert(w, r, tc.SuccessLevel, msg)
+	api.CreateChangeLogRawTx(api.ApiChange, msg, user, tx)
+	event := map[string]interface{}{
+		"id":            serverID,
+		"hostName":      serverInfo.HostName,
+		"status":        *status.Name,
+		"offlineReason": offlineReason,
+	}
+	if err := api.CreateEvent(tx, tc.EventTypeServerStatusChanged, user, event); err != nil {
+		return "", errors.New("creating event: " + err.Error())
+	}
+	return msg, nil
 }
 
 // queueUpdatesOnChildCaches queues updates on child caches of the given cdnID and parentCachegroupID and returns an error (if one occurs).
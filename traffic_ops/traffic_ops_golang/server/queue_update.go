@@ -78,10 +78,20 @@ func QueueUpdateHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	api.WriteResp(w, r, tc.ServerQueueUpdate{
+	resp := tc.ServerQueueUpdate{
 		ServerID: util.JSONIntStr(serverID),
 		Action:   reqObj.Action,
-	})
+	}
+	eventType := tc.EventTypeUpdatesCleared
+	if queue {
+		eventType = tc.EventTypeUpdatesQueued
+	}
+	if err := api.CreateEvent(inf.Tx.Tx, eventType, inf.User, resp); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("creating event: %v", err))
+		return
+	}
+
+	api.WriteResp(w, r, resp)
 }
 
 // queueUpdate sets the upd_pending column of a server to the value of queue. It
@@ -85,6 +85,19 @@ func (s *TOServer) GetType() string {
 	return "server"
 }
 
+// EventData implements api.HasEventData, leaving out the server's iLO and XMPP passwords.
+func (s *TOServer) EventData() interface{} {
+	sv := s.ServerNullable
+	sv.ILOPassword = nil
+	sv.XMPPPasswd = nil
+	return sv
+}
+
+// LastModifiedTables implements api.HasLastModifiedTables.
+func (s *TOServer) LastModifiedTables() []string {
+	return []string{"server", "cachegroup", "cdn", "phys_location", "profile", "status", "type", "interface", "ip_address", "deliveryservice", "deliveryservice_server"}
+}
+
 func (s *TOServer) Sanitize() {
 	if s.IP6Address != nil && *s.IP6Address == "" {
 		s.IP6Address = nil
@@ -97,6 +110,13 @@ func (s *TOServer) Validate() error {
 	noSpaces := validation.NewStringRule(tovalidate.NoSpaces, "cannot contain spaces")
 
 	errs := []error{}
+	if supportsInterfaces(version) {
+		if interfaceErrs := validateInterfaces(s.Interfaces); len(interfaceErrs) > 0 {
+			return util.JoinErrs(interfaceErrs)
+		}
+		s.setLegacyInterfaceFields()
+	}
+
 	if (s.IPAddress == nil || *s.IPAddress == "") && (s.IP6Address == nil || *s.IP6Address == "") {
 		errs = append(errs, tc.NeedsAtLeastOneIPError)
 	}
@@ -211,11 +231,30 @@ func (s *TOServer) Read() ([]interface{}, error, error, int) {
 		return nil, userErr, sysErr, errCode
 	}
 
+	interfaces := map[int][]tc.ServerInterfaceInfo{}
+	if supportsInterfaces(version) && len(servers) > 0 {
+		ids := make([]int, 0, len(servers))
+		for _, server := range servers {
+			ids = append(ids, *server.ID)
+		}
+		serverInterfaces, err := getInterfaces(s.ReqInfo.Tx.Tx, ids)
+		if err != nil {
+			return nil, nil, err, http.StatusInternalServerError
+		}
+		interfaces = serverInterfaces
+	}
+
 	for _, server := range servers {
 		switch {
 		// NOTE: it's required to handle minor version cases in a descending >= manner
-		case version.Major >= 2:
+		case supportsInterfaces(version):
+			server.Interfaces = interfaces[*server.ID]
+			if server.Interfaces == nil {
+				server.Interfaces = []tc.ServerInterfaceInfo{}
+			}
 			returnable = append(returnable, server)
+		case version.Major >= 2:
+			returnable = append(returnable, server.ServerNullableV2)
 		case version.Major == 1 && version.Minor >= 1:
 			returnable = append(returnable, server.ServerNullableV11)
 		default:
@@ -408,7 +447,10 @@ func (s *TOServer) Update() (error, error, int) {
 		}
 	}
 
-	return api.GenericUpdate(s)
+	if userErr, sysErr, errCode := api.GenericUpdate(s); userErr != nil || sysErr != nil {
+		return userErr, sysErr, errCode
+	}
+	return s.writeInterfaces(current.InterfaceName)
 }
 
 func (s *TOServer) Create() (error, error, int) {
@@ -430,21 +472,44 @@ func (s *TOServer) Create() (error, error, int) {
 		s.IP6IsService = &defaultIsService
 	}
 
-	return api.GenericCreate(s)
+	if userErr, sysErr, errCode := api.GenericCreate(s); userErr != nil || sysErr != nil {
+		return userErr, sysErr, errCode
+	}
+	return s.writeInterfaces(nil)
+}
+
+// writeInterfaces stores the interfaces of a server which was just created or updated. Requests
+// of older API versions only have the legacy interface fields, which are synced to the interface
+// they name. The oldInterfaceName is the legacy interface name before an update.
+func (s *TOServer) writeInterfaces(oldInterfaceName *string) (error, error, int) {
+	tx := s.APIInfo().Tx.Tx
+	if supportsInterfaces(s.APIInfo().Version) {
+		if err := replaceInterfaces(tx, *s.ID, s.Interfaces); err != nil {
+			return nil, err, http.StatusInternalServerError
+		}
+		return nil, nil, http.StatusOK
+	}
+	if err := syncLegacyInterface(tx, s, oldInterfaceName); err != nil {
+		return nil, err, http.StatusInternalServerError
+	}
+	return nil, nil, http.StatusOK
 }
 
 func (s *TOServer) Delete() (error, error, int) { return api.GenericDelete(s) }
 
 func selectV20UpdatesQuery() string {
 	return `SELECT 
+sv.interface_name,
 sv.ip_address_is_service, 
 sv.ip6_address_is_service 
 FROM 
 	server sv`
 }
 
+// JumboFrameBPS is the legacy interface MTU of servers which have none.
+const JumboFrameBPS = 9000
+
 func selectQuery() string {
-	const JumboFrameBPS = 9000
 	return `SELECT
 cg.name as cachegroup,
 s.cachegroup as cachegroup_id,
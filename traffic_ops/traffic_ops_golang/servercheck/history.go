@@ -0,0 +1,93 @@
+package servercheck
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+)
+
+const readHistoryQuery = `
+SELECT servercheck_result.check_name,
+       servercheck_result.value,
+       servercheck_result.created
+FROM servercheck_result
+`
+
+// GetHistory is the handler for GET requests to /servers/{id}/checks, which returns the retained
+// check results of a server, most recent first unless another order is requested.
+func GetHistory(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"id"}, []string{"id"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	if _, ok, err := dbhelpers.GetServerNameFromID(inf.Tx.Tx, inf.IntParams["id"]); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("getting server name: %v", err))
+		return
+	} else if !ok {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("no server with id "+inf.Params["id"]), nil)
+		return
+	}
+
+	queryParamsToSQLCols := map[string]dbhelpers.WhereColumnInfo{
+		"id":   dbhelpers.WhereColumnInfo{"servercheck_result.server", api.IsInt},
+		"name": dbhelpers.WhereColumnInfo{"servercheck_result.check_name", nil},
+		"time": dbhelpers.WhereColumnInfo{"servercheck_result.created", nil},
+	}
+	where, orderBy, pagination, queryValues, errs := dbhelpers.BuildWhereAndOrderByAndPagination(inf.Params, queryParamsToSQLCols)
+	if len(errs) > 0 {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, util.JoinErrs(errs), nil)
+		return
+	}
+	if orderBy == "" {
+		orderBy = "\nORDER BY servercheck_result.created DESC, servercheck_result.id DESC"
+	}
+
+	rows, err := inf.Tx.NamedQuery(readHistoryQuery+where+orderBy+pagination, queryValues)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("querying servercheck history: %v", err))
+		return
+	}
+	defer rows.Close()
+
+	results := []tc.ServercheckResult{}
+	for rows.Next() {
+		result := tc.ServercheckResult{}
+		if err := rows.StructScan(&result); err != nil {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("scanning servercheck history: %v", err))
+			return
+		}
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("scanning servercheck history: %v", err))
+		return
+	}
+	api.WriteResp(w, r, results)
+}
@@ -0,0 +1,370 @@
+package servercheck
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/server"
+)
+
+const readRulesQuery = `
+SELECT servercheck_rule.id,
+       servercheck_rule.check_name,
+       servercheck_rule.comparison,
+       servercheck_rule.threshold,
+       servercheck_rule.consecutive,
+       status.name AS status,
+       servercheck_rule.alert,
+       servercheck_rule.description,
+       servercheck_rule.last_updated
+FROM servercheck_rule
+LEFT JOIN status ON servercheck_rule.status = status.id
+`
+
+const insertRuleQuery = `
+INSERT INTO servercheck_rule (
+	check_name,
+	comparison,
+	threshold,
+	consecutive,
+	status,
+	alert,
+	description)
+VALUES ($1, $2, $3, $4, (SELECT id FROM status WHERE name = $5), $6, $7)
+RETURNING id, last_updated
+`
+
+const updateRuleQuery = `
+UPDATE servercheck_rule SET
+	check_name=$1,
+	comparison=$2,
+	threshold=$3,
+	consecutive=$4,
+	status=(SELECT id FROM status WHERE name = $5),
+	alert=$6,
+	description=$7
+WHERE id=$8
+RETURNING last_updated
+`
+
+const insertResultQuery = `
+INSERT INTO servercheck_result (server, check_name, value)
+VALUES ($1, $2, $3)
+`
+
+// Results are pruned as new ones are recorded, so a server which stops being checked keeps its
+// last results.
+const pruneResultsQuery = `
+DELETE FROM servercheck_result
+WHERE server = $1
+AND check_name = $2
+AND created < now() - $3 * interval '1 second'
+`
+
+const recentResultsQuery = `
+SELECT value
+FROM servercheck_result
+WHERE server = $1
+AND check_name = $2
+ORDER BY created DESC, id DESC
+LIMIT $3
+`
+
+// GetRules is the handler for GET requests to /servercheck/rules.
+func GetRules(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, nil, nil)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	queryParamsToSQLCols := map[string]dbhelpers.WhereColumnInfo{
+		"id":        dbhelpers.WhereColumnInfo{"servercheck_rule.id", api.IsInt},
+		"checkName": dbhelpers.WhereColumnInfo{"servercheck_rule.check_name", nil},
+		"status":    dbhelpers.WhereColumnInfo{"status.name", nil},
+	}
+	where, orderBy, pagination, queryValues, errs := dbhelpers.BuildWhereAndOrderByAndPagination(inf.Params, queryParamsToSQLCols)
+	if len(errs) > 0 {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, util.JoinErrs(errs), nil)
+		return
+	}
+
+	rows, err := inf.Tx.NamedQuery(readRulesQuery+where+orderBy+pagination, queryValues)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("querying servercheck rules: %v", err))
+		return
+	}
+	defer rows.Close()
+
+	rules := []tc.ServercheckRule{}
+	for rows.Next() {
+		rule := tc.ServercheckRule{}
+		if err := rows.StructScan(&rule); err != nil {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("scanning servercheck rules: %v", err))
+			return
+		}
+		rules = append(rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("scanning servercheck rules: %v", err))
+		return
+	}
+	api.WriteResp(w, r, rules)
+}
+
+// CreateRule is the handler for POST requests to /servercheck/rules.
+func CreateRule(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, nil, nil)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	rule := tc.ServercheckRule{}
+	if err := api.Parse(r.Body, inf.Tx.Tx, &rule); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, err, nil)
+		return
+	}
+
+	if err := inf.Tx.Tx.QueryRow(insertRuleQuery, *rule.CheckName, *rule.Comparison, *rule.Threshold, *rule.Consecutive, rule.Status, *rule.Alert, rule.Description).Scan(&rule.ID, &rule.LastUpdated); err != nil {
+		userErr, sysErr, errCode = api.ParseDBError(err)
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+
+	api.CreateChangeLogRawTx(api.ApiChange, "SERVERCHECK RULE: "+strconv.Itoa(*rule.ID)+", ACTION: Created rule for check "+*rule.CheckName, inf.User, inf.Tx.Tx)
+	api.WriteRespAlertObj(w, r, tc.SuccessLevel, "Servercheck rule was created.", rule)
+}
+
+// UpdateRule is the handler for PUT requests to /servercheck/rules/{id}.
+func UpdateRule(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"id"}, []string{"id"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	rule := tc.ServercheckRule{}
+	if err := api.Parse(r.Body, inf.Tx.Tx, &rule); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, err, nil)
+		return
+	}
+	id := inf.IntParams["id"]
+	rule.ID = &id
+
+	if err := inf.Tx.Tx.QueryRow(updateRuleQuery, *rule.CheckName, *rule.Comparison, *rule.Threshold, *rule.Consecutive, rule.Status, *rule.Alert, rule.Description, id).Scan(&rule.LastUpdated); err != nil {
+		if err == sql.ErrNoRows {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("no servercheck rule with id "+inf.Params["id"]), nil)
+			return
+		}
+		userErr, sysErr, errCode = api.ParseDBError(err)
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+
+	api.CreateChangeLogRawTx(api.ApiChange, "SERVERCHECK RULE: "+inf.Params["id"]+", ACTION: Updated rule for check "+*rule.CheckName, inf.User, inf.Tx.Tx)
+	api.WriteRespAlertObj(w, r, tc.SuccessLevel, "Servercheck rule was updated.", rule)
+}
+
+// DeleteRule is the handler for DELETE requests to /servercheck/rules/{id}.
+func DeleteRule(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"id"}, []string{"id"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	checkName := ""
+	if err := inf.Tx.Tx.QueryRow(`DELETE FROM servercheck_rule WHERE id=$1 RETURNING check_name`, inf.IntParams["id"]).Scan(&checkName); err != nil {
+		if err == sql.ErrNoRows {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("no servercheck rule with id "+inf.Params["id"]), nil)
+			return
+		}
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, fmt.Errorf("deleting servercheck rule: %v", err))
+		return
+	}
+
+	api.CreateChangeLogRawTx(api.ApiChange, "SERVERCHECK RULE: "+inf.Params["id"]+", ACTION: Deleted rule for check "+checkName, inf.User, inf.Tx.Tx)
+	api.WriteRespAlert(w, r, tc.SuccessLevel, "Servercheck rule was deleted.")
+}
+
+// recordResult adds a check result to the server's history, and removes its results for the same
+// check which are older than retention.
+func recordResult(tx *sql.Tx, serverID int, checkName string, value int, retention time.Duration) error {
+	if _, err := tx.Exec(insertResultQuery, serverID, checkName, value); err != nil {
+		return errors.New("inserting servercheck result: " + err.Error())
+	}
+	if _, err := tx.Exec(pruneResultsQuery, serverID, checkName, retention.Seconds()); err != nil {
+		return errors.New("pruning servercheck results: " + err.Error())
+	}
+	return nil
+}
+
+// applyRules triggers the rules for the given check which are matched by the server's most recent
+// results, as the given user, returning warnings for those which alert.
+func applyRules(tx *sql.Tx, user *auth.CurrentUser, serverID int, checkName string) (tc.Alerts, error) {
+	alerts := tc.Alerts{}
+	rules, err := getRules(tx, checkName)
+	if err != nil {
+		return alerts, errors.New("getting rules: " + err.Error())
+	}
+	if len(rules) == 0 {
+		return alerts, nil
+	}
+
+	maxConsecutive := 0
+	for _, rule := range rules {
+		if *rule.Consecutive > maxConsecutive {
+			maxConsecutive = *rule.Consecutive
+		}
+	}
+	values, err := getRecentResults(tx, serverID, checkName, maxConsecutive+1)
+	if err != nil {
+		return alerts, errors.New("getting recent results: " + err.Error())
+	}
+
+	for _, rule := range rules {
+		if !ruleTriggered(rule, values) {
+			continue
+		}
+		msg := fmt.Sprintf("servercheck rule #%d triggered: %s %s %d %d time(s) in a row", *rule.ID, checkName, *rule.Comparison, *rule.Threshold, *rule.Consecutive)
+		if rule.Status != nil {
+			changed, err := setStatus(tx, user, serverID, *rule.Status, msg)
+			if err != nil {
+				return alerts, fmt.Errorf("setting status for rule #%d: %v", *rule.ID, err)
+			}
+			if changed {
+				msg += ", set status to " + *rule.Status
+			}
+		}
+		if !*rule.Alert {
+			continue
+		}
+		log.Warnf("server #%d: %s\n", serverID, msg)
+		alerts.AddNewAlert(tc.WarnLevel, msg)
+		event := map[string]interface{}{
+			"serverId": serverID,
+			"rule":     rule,
+			"values":   values[:*rule.Consecutive],
+		}
+		if err := api.CreateEvent(tx, tc.EventTypeServercheckTriggered, user, event); err != nil {
+			return alerts, errors.New("creating event: " + err.Error())
+		}
+	}
+	return alerts, nil
+}
+
+// ruleTriggered returns whether the given results, most recent first, trigger the rule: whether
+// the most recent result makes exactly the rule's Consecutive results in a row match it.
+func ruleTriggered(rule tc.ServercheckRule, values []int) bool {
+	n := *rule.Consecutive
+	if len(values) < n {
+		return false
+	}
+	for _, v := range values[:n] {
+		if !rule.Matches(v) {
+			return false
+		}
+	}
+	return len(values) == n || !rule.Matches(values[n])
+}
+
+func getRules(tx *sql.Tx, checkName string) ([]tc.ServercheckRule, error) {
+	rows, err := tx.Query(readRulesQuery+`WHERE servercheck_rule.check_name = $1 ORDER BY servercheck_rule.id`, checkName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []tc.ServercheckRule{}
+	for rows.Next() {
+		rule := tc.ServercheckRule{}
+		if err := rows.Scan(&rule.ID, &rule.CheckName, &rule.Comparison, &rule.Threshold, &rule.Consecutive, &rule.Status, &rule.Alert, &rule.Description, &rule.LastUpdated); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+func getRecentResults(tx *sql.Tx, serverID int, checkName string, limit int) ([]int, error) {
+	rows, err := tx.Query(recentResultsQuery, serverID, checkName, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := []int{}
+	for rows.Next() {
+		v := 0
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, rows.Err()
+}
+
+// setStatus sets the server's status to the one with the given name, with the given reason, unless
+// it already has that status. It returns whether or not the status was changed.
+func setStatus(tx *sql.Tx, user *auth.CurrentUser, serverID int, statusName string, reason string) (bool, error) {
+	current := ""
+	if err := tx.QueryRow(`SELECT status.name FROM server JOIN status ON server.status = status.id WHERE server.id = $1`, serverID).Scan(&current); err != nil {
+		return false, errors.New("getting current status: " + err.Error())
+	}
+	if current == statusName {
+		return false, nil
+	}
+
+	serverInfo, ok, err := dbhelpers.GetServerInfo(serverID, tx)
+	if err != nil {
+		return false, errors.New("getting server info: " + err.Error())
+	} else if !ok {
+		return false, fmt.Errorf("server #%d not found", serverID)
+	}
+	status, ok, err := dbhelpers.GetStatusByName(statusName, tx)
+	if err != nil {
+		return false, errors.New("getting status: " + err.Error())
+	} else if !ok {
+		return false, errors.New("status '" + statusName + "' not found")
+	}
+
+	reason = user.UserName + ": " + reason
+	if _, err := server.SetStatus(tx, user, serverID, serverInfo, status, &reason); err != nil {
+		return false, err
+	}
+	return true, nil
+}
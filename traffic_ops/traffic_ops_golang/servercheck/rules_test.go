@@ -0,0 +1,60 @@
+package servercheck
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
+)
+
+func TestRuleTriggered(t *testing.T) {
+	rule := tc.ServercheckRule{
+		Comparison:  util.StrPtr(tc.ServercheckComparisonLess),
+		Threshold:   util.IntPtr(1),
+		Consecutive: util.IntPtr(3),
+	}
+	tests := []struct {
+		values   []int
+		expected bool
+	}{
+		{[]int{}, false},
+		{[]int{0, 0}, false},
+		{[]int{0, 0, 0}, true},
+		{[]int{0, 0, 0, 1}, true},
+		{[]int{0, 0, 0, 0}, false},
+		{[]int{0, 1, 0, 0}, false},
+		{[]int{1, 0, 0, 0}, false},
+	}
+	for _, test := range tests {
+		if actual := ruleTriggered(rule, test.values); actual != test.expected {
+			t.Errorf("ruleTriggered with results %v expected %v, actual %v", test.values, test.expected, actual)
+		}
+	}
+
+	rule.Consecutive = util.IntPtr(1)
+	if !ruleTriggered(rule, []int{0, 1}) {
+		t.Error("expected rule with 1 consecutive to trigger on the first matching result")
+	}
+	if ruleTriggered(rule, []int{0, 0}) {
+		t.Error("expected rule with 1 consecutive not to trigger again on a second matching result")
+	}
+}
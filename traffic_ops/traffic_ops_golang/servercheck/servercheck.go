@@ -24,6 +24,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
 
@@ -149,9 +150,22 @@ func CreateUpdateServercheck(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	retention := time.Duration(inf.Config.ServercheckHistoryRetentionDays) * 24 * time.Hour
+	if err := recordResult(inf.Tx.Tx, id, *serverCheckReq.Name, *serverCheckReq.Value, retention); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("recording servercheck history: "+err.Error()))
+		return
+	}
+	ruleAlerts, err := applyRules(inf.Tx.Tx, inf.User, id, *serverCheckReq.Name)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("applying servercheck rules: "+err.Error()))
+		return
+	}
+
 	successMsg := "Server Check was successfully updated"
 	api.CreateChangeLogRawTx(api.ApiChange, successMsg, inf.User, inf.Tx.Tx)
-	api.WriteRespAlert(w, r, tc.SuccessLevel, successMsg)
+	alerts := tc.CreateAlerts(tc.SuccessLevel, successMsg)
+	alerts.AddAlerts(ruleAlerts)
+	api.WriteAlerts(w, r, http.StatusOK, alerts)
 }
 
 func getServerID(id *int, hostname *string, tx *sql.Tx) (int, bool, error) {
@@ -0,0 +1,243 @@
+package tenant
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+// QuotaResource is a resource limited by Tenant quotas.
+type QuotaResource struct {
+	description string
+	limit       func(tc.TenantQuota) *int
+	used        func(tc.TenantUsage) int
+	// perDeliveryService resources are limited for each Delivery Service separately.
+	perDeliveryService bool
+}
+
+// These are the resources limited by Tenant quotas.
+var (
+	QuotaDeliveryServices = QuotaResource{
+		description: "delivery services",
+		limit:       func(q tc.TenantQuota) *int { return q.MaxDeliveryServices },
+		used:        func(u tc.TenantUsage) int { return u.DeliveryServices.Used },
+	}
+	QuotaEdgeServers = QuotaResource{
+		description: "edge server assignments",
+		limit:       func(q tc.TenantQuota) *int { return q.MaxEdgeServers },
+		used:        func(u tc.TenantUsage) int { return u.EdgeServers.Used },
+	}
+	QuotaInvalidationJobsPerDay = QuotaResource{
+		description: "invalidation jobs per day",
+		limit:       func(q tc.TenantQuota) *int { return q.MaxInvalidationJobsPerDay },
+		used:        func(u tc.TenantUsage) int { return u.InvalidationJobsPerDay.Used },
+	}
+	QuotaRegexesPerDeliveryService = QuotaResource{
+		description:        "regexes per delivery service",
+		limit:              func(q tc.TenantQuota) *int { return q.MaxRegexesPerDeliveryService },
+		used:               func(u tc.TenantUsage) int { return u.RegexesPerDeliveryService.Used },
+		perDeliveryService: true,
+	}
+)
+
+// tenantUsageQuery counts the resources used by a Tenant and all of its descendants.
+const tenantUsageQuery = `
+WITH RECURSIVE subtree AS (
+	SELECT id FROM tenant WHERE id = $1
+	UNION
+	SELECT tenant.id FROM tenant JOIN subtree ON tenant.parent_id = subtree.id
+), dses AS (
+	SELECT id FROM deliveryservice WHERE tenant_id IN (SELECT id FROM subtree)
+)
+SELECT
+	(SELECT count(*) FROM dses),
+	(SELECT count(*)
+		FROM deliveryservice_server
+		JOIN server ON deliveryservice_server.server = server.id
+		JOIN type ON server.type = type.id
+		WHERE deliveryservice_server.deliveryservice IN (SELECT id FROM dses)
+		AND type.name LIKE '` + tc.EdgeTypePrefix + `%'),
+	(SELECT count(*)
+		FROM job
+		WHERE job.job_deliveryservice IN (SELECT id FROM dses)
+		AND job.entered_time > now() - interval '1 day'),
+	(SELECT COALESCE(max(regexes), 0) FROM (
+		SELECT count(*) AS regexes
+		FROM deliveryservice_regex
+		WHERE deliveryservice IN (SELECT id FROM dses)
+		GROUP BY deliveryservice
+	) AS counts)
+`
+
+const selectQuotaQuery = `
+SELECT tenant_quota.tenant,
+       tenant.name,
+       tenant_quota.max_delivery_services,
+       tenant_quota.max_edge_servers,
+       tenant_quota.max_invalidation_jobs_per_day,
+       tenant_quota.max_regexes_per_ds,
+       tenant_quota.last_updated
+FROM tenant_quota
+JOIN tenant ON tenant_quota.tenant = tenant.id
+`
+
+// ancestorQuotasQuery selects the quotas of a Tenant and all of its ancestors.
+const ancestorQuotasQuery = `
+WITH RECURSIVE ancestors AS (
+	SELECT id, parent_id FROM tenant WHERE id = $1
+	UNION
+	SELECT tenant.id, tenant.parent_id FROM tenant JOIN ancestors ON tenant.id = ancestors.parent_id
+)` + selectQuotaQuery + `
+WHERE tenant_quota.tenant IN (SELECT id FROM ancestors)
+`
+
+// lockAncestorQuotasQuery is ancestorQuotasQuery, locking the quotas until the transaction ends, so
+// that transactions adding resources limited by the same quota check it one at a time. Otherwise,
+// each could miss the resources added by the others, which aren't yet committed, and all could pass.
+// The quotas are locked in order of Tenant, so that transactions locking several can't deadlock.
+const lockAncestorQuotasQuery = ancestorQuotasQuery + `
+ORDER BY tenant_quota.tenant
+FOR UPDATE OF tenant_quota
+`
+
+// GetUsage returns the usage of the resources limited by quotas by the Tenant with the given ID and
+// all of its descendants. The limits of the usage are those of the Tenant's own quota, if it has one.
+func GetUsage(tx *sql.Tx, tenantID int) (tc.TenantUsage, error) {
+	usage := tc.TenantUsage{TenantID: tenantID}
+	if err := tx.QueryRow(`SELECT name FROM tenant WHERE id = $1`, tenantID).Scan(&usage.TenantName); err != nil {
+		return usage, errors.New("getting tenant name: " + err.Error())
+	}
+	if err := tx.QueryRow(tenantUsageQuery, tenantID).Scan(&usage.DeliveryServices.Used, &usage.EdgeServers.Used, &usage.InvalidationJobsPerDay.Used, &usage.RegexesPerDeliveryService.Used); err != nil {
+		return usage, errors.New("counting tenant usage: " + err.Error())
+	}
+	quota, ok, err := GetQuota(tx, tenantID)
+	if err != nil {
+		return usage, err
+	}
+	if ok {
+		usage.DeliveryServices.Limit = quota.MaxDeliveryServices
+		usage.EdgeServers.Limit = quota.MaxEdgeServers
+		usage.InvalidationJobsPerDay.Limit = quota.MaxInvalidationJobsPerDay
+		usage.RegexesPerDeliveryService.Limit = quota.MaxRegexesPerDeliveryService
+	}
+	return usage, nil
+}
+
+// GetQuota returns the quota of the Tenant with the given ID, and whether it has one.
+func GetQuota(tx *sql.Tx, tenantID int) (tc.TenantQuota, bool, error) {
+	quotas, err := queryQuotas(tx, selectQuotaQuery+`WHERE tenant_quota.tenant = $1`, tenantID)
+	if err != nil {
+		return tc.TenantQuota{}, false, errors.New("getting tenant quota: " + err.Error())
+	}
+	if len(quotas) == 0 {
+		return tc.TenantQuota{}, false, nil
+	}
+	return quotas[0], true, nil
+}
+
+func queryQuotas(tx *sql.Tx, query string, args ...interface{}) ([]tc.TenantQuota, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	quotas := []tc.TenantQuota{}
+	for rows.Next() {
+		q := tc.TenantQuota{}
+		if err := rows.Scan(&q.TenantID, &q.TenantName, &q.MaxDeliveryServices, &q.MaxEdgeServers, &q.MaxInvalidationJobsPerDay, &q.MaxRegexesPerDeliveryService, &q.LastUpdated); err != nil {
+			return nil, err
+		}
+		quotas = append(quotas, q)
+	}
+	return quotas, rows.Err()
+}
+
+// CheckQuota checks that the usage of the given resource by the Tenant with the given ID doesn't
+// exceed the quota of that Tenant or any of its ancestors. It's meant to be called after the resource
+// has been added, in the same transaction, so that the transaction can be rolled back if the quota is
+// exceeded. The quotas are locked until the transaction ends, before the usage is counted, so that
+// concurrent transactions adding the same resource can't all pass. Returns a user error, system error, and the HTTP status code to be returned to the user if
+// an error occurred.
+//
+// Resources limited per Delivery Service must be checked with CheckDeliveryServiceQuota instead.
+func CheckQuota(tx *sql.Tx, tenantID int, resource QuotaResource) (error, error, int) {
+	if resource.perDeliveryService {
+		return nil, errors.New("checking quota: " + resource.description + " must be checked per delivery service"), http.StatusInternalServerError
+	}
+	quotas, err := queryQuotas(tx, lockAncestorQuotasQuery, tenantID)
+	if err != nil {
+		return nil, errors.New("getting tenant quotas: " + err.Error()), http.StatusInternalServerError
+	}
+	for _, q := range quotas {
+		limit := resource.limit(q)
+		if limit == nil {
+			continue
+		}
+		usage, err := GetUsage(tx, *q.TenantID)
+		if err != nil {
+			return nil, errors.New("checking quota: " + err.Error()), http.StatusInternalServerError
+		}
+		if resource.used(usage) > *limit {
+			return quotaExceededErr(q, *limit, resource), nil, http.StatusForbidden
+		}
+	}
+	return nil, nil, http.StatusOK
+}
+
+// CheckDeliveryServiceQuota is like CheckQuota, but checks the quotas of the Tenant of the Delivery
+// Service with the given ID, for which - if the resource is limited per Delivery Service - the usage
+// is the Delivery Service's own. Delivery Services without a Tenant aren't limited.
+func CheckDeliveryServiceQuota(tx *sql.Tx, dsID int, resource QuotaResource) (error, error, int) {
+	tenantID, ok, err := getDSTenantIDByIDTx(tx, dsID)
+	if err != nil {
+		return nil, errors.New("checking quota: " + err.Error()), http.StatusInternalServerError
+	}
+	if !ok || tenantID == nil {
+		return nil, nil, http.StatusOK
+	}
+	if !resource.perDeliveryService {
+		return CheckQuota(tx, *tenantID, resource)
+	}
+
+	quotas, err := queryQuotas(tx, lockAncestorQuotasQuery, *tenantID)
+	if err != nil {
+		return nil, errors.New("getting tenant quotas: " + err.Error()), http.StatusInternalServerError
+	}
+	used := 0
+	if err := tx.QueryRow(`SELECT count(*) FROM deliveryservice_regex WHERE deliveryservice = $1`, dsID).Scan(&used); err != nil {
+		return nil, errors.New("checking quota: counting delivery service regexes: " + err.Error()), http.StatusInternalServerError
+	}
+	for _, q := range quotas {
+		if limit := resource.limit(q); limit != nil && used > *limit {
+			return quotaExceededErr(q, *limit, resource), nil, http.StatusForbidden
+		}
+	}
+	return nil, nil, http.StatusOK
+}
+
+func quotaExceededErr(q tc.TenantQuota, limit int, resource QuotaResource) error {
+	return fmt.Errorf("quota exceeded: tenant '%s' is limited to %d %s", *q.TenantName, limit, resource.description)
+}
@@ -0,0 +1,59 @@
+package tenant
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestCheckQuotaLocksQuotasBeforeCounting(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	quotaCols := []string{"tenant", "name", "max_delivery_services", "max_edge_servers", "max_invalidation_jobs_per_day", "max_regexes_per_ds", "last_updated"}
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT tenant_id FROM deliveryservice").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"tenant_id"}).AddRow(2))
+	mock.ExpectQuery("ORDER BY tenant_quota.tenant\\s+FOR UPDATE OF tenant_quota").WithArgs(2).WillReturnRows(sqlmock.NewRows(quotaCols).AddRow(2, "tenant2", nil, nil, 1, nil, time.Now()))
+	mock.ExpectQuery("SELECT name FROM tenant").WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("tenant2"))
+	mock.ExpectQuery("WITH RECURSIVE subtree").WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"dses", "edges", "jobs", "regexes"}).AddRow(1, 0, 2, 1))
+	mock.ExpectQuery("WHERE tenant_quota.tenant = ").WithArgs(2).WillReturnRows(sqlmock.NewRows(quotaCols).AddRow(2, "tenant2", nil, nil, 1, nil, time.Now()))
+
+	tx, err := mockDB.Begin()
+	if err != nil {
+		t.Fatalf("beginning transaction: %v", err)
+	}
+	userErr, sysErr, code := CheckDeliveryServiceQuota(tx, 1, QuotaInvalidationJobsPerDay)
+	if sysErr != nil {
+		t.Fatalf("expected no system error, actual: %v", sysErr)
+	}
+	if userErr == nil || code != http.StatusForbidden {
+		t.Errorf("expected quota exceeded with status %d, actual: %v with status %d", http.StatusForbidden, userErr, code)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expected the quotas to be locked before usage was counted, actual: %v", err)
+	}
+}
@@ -35,7 +35,7 @@ func ColsFromStructByTag(tagName string, thing interface{}) []string {
 		if (strings.Compare(tagName, "db") == 0) && (tagName != "") {
 			// Get the field tag value
 			tag := field.Tag.Get(tagName)
-			if tag != "" {
+			if tag != "" && tag != "-" {
 				cols = append(cols, tag)
 			}
 		}
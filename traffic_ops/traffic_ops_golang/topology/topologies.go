@@ -0,0 +1,372 @@
+package topology
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+
+	"github.com/lib/pq"
+)
+
+// EventObjectType is the object type of Topology Events, as used by tc.EventTypeFor.
+const EventObjectType = "topology"
+
+// Nodes are read in the order they were inserted, which is the order of the Topology's nodes, so
+// parents can be turned back into indices.
+const readQuery = `
+SELECT t.name,
+       t.description,
+       t.last_updated,
+       tc.id,
+       tc.cachegroup,
+       (SELECT ARRAY_AGG(p.parent ORDER BY p.rank)
+        FROM topology_cachegroup_parents p
+        WHERE p.child = tc.id) AS parents
+FROM topology t
+LEFT JOIN topology_cachegroup tc ON tc.topology = t.name
+WHERE ($1 = '' OR t.name = $1)
+ORDER BY t.name, tc.id
+`
+
+const insertNodeQuery = `
+INSERT INTO topology_cachegroup (topology, cachegroup)
+VALUES ($1, $2)
+RETURNING id
+`
+
+const insertParentQuery = `
+INSERT INTO topology_cachegroup_parents (child, parent, rank)
+VALUES ($1, $2, $3)
+`
+
+// Get is the handler for GET requests to /topologies, optionally filtered by the "name" query
+// parameter.
+func Get(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, nil, nil)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	topologies, err := GetTopologies(inf.Tx.Tx, inf.Params["name"])
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, err)
+		return
+	}
+	api.WriteResp(w, r, topologies)
+}
+
+// Create is the handler for POST requests to /topologies.
+func Create(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, nil, nil)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	topology, userErr, sysErr, errCode := decodeTopology(inf.Tx.Tx, r)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	if _, err := inf.Tx.Tx.Exec(`INSERT INTO topology (name, description) VALUES ($1, $2)`, topology.Name, topology.Description); err != nil {
+		userErr, sysErr, errCode = api.ParseDBError(err)
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	if err := insertNodes(inf.Tx.Tx, topology); err != nil {
+		userErr, sysErr, errCode = api.ParseDBError(err)
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+
+	written, userErr, sysErr, errCode := finishWrite(inf, topology.Name, tc.EventActionCreated, "Created")
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	api.WriteRespAlertObj(w, r, tc.SuccessLevel, "Topology was created.", written)
+}
+
+// Update is the handler for PUT requests to /topologies, which replace the Topology with the name
+// given in the "name" query parameter. The Topology may be renamed; Delivery Services using it
+// follow the new name.
+func Update(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"name"}, nil)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	topology, userErr, sysErr, errCode := decodeTopology(inf.Tx.Tx, r)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	result, err := inf.Tx.Tx.Exec(`UPDATE topology SET name = $1, description = $2 WHERE name = $3`, topology.Name, topology.Description, inf.Params["name"])
+	if err != nil {
+		userErr, sysErr, errCode = api.ParseDBError(err)
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	if rows, err := result.RowsAffected(); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("updating topology: getting rows affected: "+err.Error()))
+		return
+	} else if rows == 0 {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("no topology named "+inf.Params["name"]), nil)
+		return
+	}
+
+	// The nodes are replaced, rather than updated, because parents refer to them by position.
+	if _, err := inf.Tx.Tx.Exec(`DELETE FROM topology_cachegroup WHERE topology = $1`, topology.Name); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("deleting topology nodes: "+err.Error()))
+		return
+	}
+	if err := insertNodes(inf.Tx.Tx, topology); err != nil {
+		userErr, sysErr, errCode = api.ParseDBError(err)
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	// Touch the Topology, so its last_updated reflects changes to its nodes alone.
+	if _, err := inf.Tx.Tx.Exec(`UPDATE topology SET last_updated = now() WHERE name = $1`, topology.Name); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("updating topology last updated: "+err.Error()))
+		return
+	}
+
+	written, userErr, sysErr, errCode := finishWrite(inf, topology.Name, tc.EventActionUpdated, "Updated")
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	api.WriteRespAlertObj(w, r, tc.SuccessLevel, "Topology was updated.", written)
+}
+
+// Delete is the handler for DELETE requests to /topologies, which delete the Topology with the name
+// given in the "name" query parameter. Topologies used by Delivery Services cannot be deleted.
+func Delete(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"name"}, nil)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	name := inf.Params["name"]
+	dses := []string{}
+	if err := inf.Tx.Tx.QueryRow(`SELECT COALESCE(ARRAY_AGG(xml_id ORDER BY xml_id), '{}') FROM deliveryservice WHERE topology = $1`, name).Scan(pq.Array(&dses)); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting topology delivery services: "+err.Error()))
+		return
+	}
+	if len(dses) > 0 {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, errors.New("topology "+name+" is used by delivery services: "+strings.Join(dses, ", ")), nil)
+		return
+	}
+
+	result, err := inf.Tx.Tx.Exec(`DELETE FROM topology WHERE name = $1`, name)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("deleting topology: "+err.Error()))
+		return
+	}
+	if rows, err := result.RowsAffected(); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("deleting topology: getting rows affected: "+err.Error()))
+		return
+	} else if rows == 0 {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("no topology named "+name), nil)
+		return
+	}
+
+	api.CreateChangeLogRawTx(api.ApiChange, "TOPOLOGY: "+name+", ACTION: Deleted topology", inf.User, inf.Tx.Tx)
+	if err := api.CreateEvent(inf.Tx.Tx, tc.EventTypeFor(EventObjectType, tc.EventActionDeleted), inf.User, map[string]interface{}{"name": name}); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("creating event: "+err.Error()))
+		return
+	}
+	api.WriteRespAlert(w, r, tc.SuccessLevel, "Topology was deleted.")
+}
+
+// GetTopologies returns the Topology with the given name, or all Topologies if name is empty,
+// sorted by name.
+func GetTopologies(tx *sql.Tx, name string) ([]tc.Topology, error) {
+	rows, err := tx.Query(readQuery, name)
+	if err != nil {
+		return nil, errors.New("querying topologies: " + err.Error())
+	}
+	defer rows.Close()
+
+	topologies := []tc.Topology{}
+	nodeIDs := []map[int64]int{} // nodeIDs[i] maps the IDs of the nodes of topologies[i] to their indices
+	nodeParents := [][][]int64{}
+	for rows.Next() {
+		topology := tc.Topology{LastUpdated: &tc.TimeNoMod{}}
+		nodeID := sql.NullInt64{}
+		cachegroup := sql.NullString{}
+		parents := []int64{}
+		if err := rows.Scan(&topology.Name, &topology.Description, topology.LastUpdated, &nodeID, &cachegroup, pq.Array(&parents)); err != nil {
+			return nil, errors.New("scanning topologies: " + err.Error())
+		}
+		if len(topologies) == 0 || topologies[len(topologies)-1].Name != topology.Name {
+			topology.Nodes = []tc.TopologyNode{}
+			topologies = append(topologies, topology)
+			nodeIDs = append(nodeIDs, map[int64]int{})
+			nodeParents = append(nodeParents, [][]int64{})
+		}
+		if !nodeID.Valid {
+			continue
+		}
+		i := len(topologies) - 1
+		nodeIDs[i][nodeID.Int64] = len(topologies[i].Nodes)
+		topologies[i].Nodes = append(topologies[i].Nodes, tc.TopologyNode{Cachegroup: cachegroup.String, Parents: []int{}})
+		nodeParents[i] = append(nodeParents[i], parents)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.New("scanning topologies: " + err.Error())
+	}
+
+	for i, topology := range topologies {
+		for j, parents := range nodeParents[i] {
+			for _, parentID := range parents {
+				parent, ok := nodeIDs[i][parentID]
+				if !ok {
+					return nil, fmt.Errorf("topology %s node %d has parent %d in another topology", topology.Name, j, parentID)
+				}
+				topology.Nodes[j].Parents = append(topology.Nodes[j].Parents, parent)
+			}
+		}
+	}
+	return topologies, nil
+}
+
+// decodeTopology reads a Topology from the request body, and checks that it is valid, including
+// that its Cache Groups exist and are of types that may be used in it.
+func decodeTopology(tx *sql.Tx, r *http.Request) (tc.Topology, error, error, int) {
+	topology := tc.Topology{}
+	if err := json.NewDecoder(r.Body).Decode(&topology); err != nil {
+		return tc.Topology{}, errors.New("malformed JSON: " + err.Error()), nil, http.StatusBadRequest
+	}
+	if err := topology.Validate(); err != nil {
+		return tc.Topology{}, err, nil, http.StatusBadRequest
+	}
+
+	names := make([]string, 0, len(topology.Nodes))
+	for _, node := range topology.Nodes {
+		names = append(names, node.Cachegroup)
+	}
+	types, err := getCachegroupTypes(tx, names)
+	if err != nil {
+		return tc.Topology{}, nil, err, http.StatusInternalServerError
+	}
+	if err := checkCachegroupTypes(topology, types); err != nil {
+		return tc.Topology{}, err, nil, http.StatusBadRequest
+	}
+	return topology, nil, nil, http.StatusOK
+}
+
+// checkCachegroupTypes returns an error if any of the Topology's Cache Groups does not exist,
+// according to types, which maps Cache Group names to their type names, or may not be used where
+// it is. Only edge and mid Cache Groups may be used; edge Cache Groups cannot be parents, and every
+// node which is not a parent must be an edge Cache Group, to which clients can be routed.
+func checkCachegroupTypes(topology tc.Topology, types map[string]string) error {
+	errs := []string{}
+	for i, node := range topology.Nodes {
+		cgType, ok := types[node.Cachegroup]
+		if !ok {
+			errs = append(errs, "cachegroup '"+node.Cachegroup+"' does not exist")
+			continue
+		}
+		isParent := topology.IsParent(i)
+		switch {
+		case cgType != tc.CacheGroupEdgeTypeName && cgType != tc.CacheGroupMidTypeName:
+			errs = append(errs, "cachegroup '"+node.Cachegroup+"' has type "+cgType+", but only "+tc.CacheGroupEdgeTypeName+" and "+tc.CacheGroupMidTypeName+" cachegroups may be in a topology")
+		case isParent && cgType == tc.CacheGroupEdgeTypeName:
+			errs = append(errs, "cachegroup '"+node.Cachegroup+"' has type "+tc.CacheGroupEdgeTypeName+" and cannot be a parent")
+		case !isParent && cgType != tc.CacheGroupEdgeTypeName:
+			errs = append(errs, "cachegroup '"+node.Cachegroup+"' is not the parent of any node, so must have type "+tc.CacheGroupEdgeTypeName)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// getCachegroupTypes returns the type names of those of the named Cache Groups which exist.
+func getCachegroupTypes(tx *sql.Tx, names []string) (map[string]string, error) {
+	rows, err := tx.Query(`SELECT cachegroup.name, type.name FROM cachegroup JOIN type ON cachegroup.type = type.id WHERE cachegroup.name = ANY($1)`, pq.Array(names))
+	if err != nil {
+		return nil, errors.New("querying cachegroup types: " + err.Error())
+	}
+	defer rows.Close()
+
+	types := map[string]string{}
+	for rows.Next() {
+		name := ""
+		cgType := ""
+		if err := rows.Scan(&name, &cgType); err != nil {
+			return nil, errors.New("scanning cachegroup types: " + err.Error())
+		}
+		types[name] = cgType
+	}
+	return types, rows.Err()
+}
+
+// insertNodes inserts the nodes of the Topology, which must already exist, and their parents.
+func insertNodes(tx *sql.Tx, topology tc.Topology) error {
+	ids := make([]int64, len(topology.Nodes))
+	for i, node := range topology.Nodes {
+		if err := tx.QueryRow(insertNodeQuery, topology.Name, node.Cachegroup).Scan(&ids[i]); err != nil {
+			return err
+		}
+	}
+	for i, node := range topology.Nodes {
+		for rank, parent := range node.Parents {
+			if _, err := tx.Exec(insertParentQuery, ids[i], ids[parent], rank+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// finishWrite reads back the Topology with the given name after it has been written, and records
+// the change log entry and Event for the given action.
+func finishWrite(inf *api.APIInfo, name string, eventAction string, logAction string) (tc.Topology, error, error, int) {
+	topologies, err := GetTopologies(inf.Tx.Tx, name)
+	if err != nil {
+		return tc.Topology{}, nil, err, http.StatusInternalServerError
+	}
+	if len(topologies) != 1 {
+		return tc.Topology{}, nil, fmt.Errorf("reading topology %s after write: expected 1, got %d", name, len(topologies)), http.StatusInternalServerError
+	}
+	api.CreateChangeLogRawTx(api.ApiChange, "TOPOLOGY: "+name+", ACTION: "+logAction+" topology", inf.User, inf.Tx.Tx)
+	if err := api.CreateEvent(inf.Tx.Tx, tc.EventTypeFor(EventObjectType, eventAction), inf.User, topologies[0]); err != nil {
+		return tc.Topology{}, nil, errors.New("creating event: " + err.Error()), http.StatusInternalServerError
+	}
+	return topologies[0], nil, nil, http.StatusOK
+}
@@ -0,0 +1,294 @@
+package trace
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-rfc"
+)
+
+// TracesPath is the path of the OTLP/HTTP endpoint of a collector which receives spans.
+const TracesPath = "/v1/traces"
+
+// ScopeName is the name of the instrumentation scope of the spans Traffic Ops exports.
+const ScopeName = "github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/trace"
+
+const queueSize = 4096
+const maxBatchSize = 512
+const exportInterval = 5 * time.Second
+
+// Options are the options of tracing.
+type Options struct {
+	// Endpoint is the URL of the OTLP/HTTP receiver of the collector, e.g. http://localhost:4318.
+	// Spans are POSTed to its TracesPath, unless the URL's path already ends with it.
+	Endpoint string
+	// Headers are sent with each request to the collector, e.g. for authentication.
+	Headers map[string]string
+	// SampleRatio is the ratio of the traces started by Traffic Ops which are exported, from 0 to
+	// 1. Traces started by a client are exported if the client sampled them.
+	SampleRatio float64
+	// Timeout is the timeout of each request to the collector.
+	Timeout time.Duration
+	// Insecure is whether to skip verifying the certificate of a collector served over HTTPS.
+	Insecure bool
+	// ServiceName and ServiceVersion identify the service whose spans are exported.
+	ServiceName    string
+	ServiceVersion string
+}
+
+// Exporter exports ended spans to a collector, in batches.
+type Exporter struct {
+	url      string
+	headers  map[string]string
+	client   *http.Client
+	bound    uint64
+	resource resource
+	queue    chan *Span
+	dropped  uint64
+}
+
+// exporter is the exporter of all spans, or nil if tracing is disabled.
+var exporter *Exporter
+
+// Init enables tracing, and starts exporting spans. It must be called before any spans are
+// started, i.e. before the server starts serving requests.
+func Init(opts Options) error {
+	e, err := newExporter(opts)
+	if err != nil {
+		return err
+	}
+	exporter = e
+	go e.run()
+	return nil
+}
+
+// Enabled returns whether tracing has been enabled with Init.
+func Enabled() bool {
+	return exporter != nil
+}
+
+func newExporter(opts Options) (*Exporter, error) {
+	u, err := url.Parse(opts.Endpoint)
+	if err != nil {
+		return nil, errors.New("parsing endpoint: " + err.Error())
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, errors.New("endpoint '" + opts.Endpoint + "' must be an http or https URL")
+	}
+	if !strings.HasSuffix(u.Path, TracesPath) {
+		u.Path = strings.TrimSuffix(u.Path, "/") + TracesPath
+	}
+
+	attrs := []keyValue{stringKeyValue("service.name", opts.ServiceName)}
+	if opts.ServiceVersion != "" {
+		attrs = append(attrs, stringKeyValue("service.version", opts.ServiceVersion))
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		attrs = append(attrs, stringKeyValue("host.name", hostname))
+	}
+
+	return &Exporter{
+		url:     u.String(),
+		headers: opts.Headers,
+		client: &http.Client{
+			Timeout: opts.Timeout,
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: opts.Insecure},
+			},
+		},
+		bound:    sampleBound(opts.SampleRatio),
+		resource: resource{Attributes: attrs},
+		queue:    make(chan *Span, queueSize),
+	}, nil
+}
+
+func (e *Exporter) sample(id TraceID) bool {
+	return sampled(id, e.bound)
+}
+
+// export queues an ended span to be exported. Spans are dropped, rather than blocking requests,
+// if the collector can't keep up.
+func (e *Exporter) export(s *Span) {
+	select {
+	case e.queue <- s:
+	default:
+		atomic.AddUint64(&e.dropped, 1)
+	}
+}
+
+// run exports queued spans whenever there are enough for a batch, and at least every
+// exportInterval. It never returns.
+func (e *Exporter) run() {
+	ticker := time.NewTicker(exportInterval)
+	batch := make([]*Span, 0, maxBatchSize)
+	for {
+		select {
+		case s := <-e.queue:
+			batch = append(batch, s)
+			if len(batch) < maxBatchSize {
+				continue
+			}
+		case <-ticker.C:
+			if len(batch) == 0 {
+				continue
+			}
+		}
+		if dropped := atomic.SwapUint64(&e.dropped, 0); dropped > 0 {
+			log.Warnf("tracing: dropped %d spans because the export queue was full\n", dropped)
+		}
+		if err := e.post(batch); err != nil {
+			log.Errorf("tracing: exporting %d spans: %v\n", len(batch), err)
+		}
+		batch = batch[:0]
+	}
+}
+
+// post sends the spans to the collector.
+func (e *Exporter) post(spans []*Span) error {
+	body, err := json.Marshal(e.request(spans))
+	if err != nil {
+		return errors.New("marshalling spans: " + err.Error())
+	}
+	req, err := http.NewRequest(http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return errors.New("creating request: " + err.Error())
+	}
+	req.Header.Set(rfc.ContentType, rfc.ApplicationJSON)
+	for name, val := range e.headers {
+		req.Header.Set(name, val)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return errors.New("sending request: " + err.Error())
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("collector returned status %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+	return nil
+}
+
+// request returns the OTLP request which exports the spans.
+func (e *Exporter) request(spans []*Span) exportRequest {
+	otlpSpans := make([]otlpSpan, 0, len(spans))
+	for _, s := range spans {
+		otlpSpans = append(otlpSpans, s.otlp())
+	}
+	return exportRequest{ResourceSpans: []resourceSpans{{
+		Resource:   e.resource,
+		ScopeSpans: []scopeSpans{{Scope: scope{Name: ScopeName}, Spans: otlpSpans}},
+	}}}
+}
+
+func (s *Span) otlp() otlpSpan {
+	s.m.Lock()
+	defer s.m.Unlock()
+	span := otlpSpan{
+		TraceID:           s.TraceID.String(),
+		SpanID:            s.SpanID.String(),
+		Name:              s.name,
+		Kind:              s.kind,
+		StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+		Attributes:        s.attrs,
+		Status:            status{Code: s.status, Message: s.message},
+	}
+	if s.parentID != (SpanID{}) {
+		span.ParentSpanID = s.parentID.String()
+	}
+	return span
+}
+
+// The types below are the JSON encoding of the OTLP ExportTraceServiceRequest, which encodes IDs
+// in hex, and 64-bit integers as strings.
+// See https://github.com/open-telemetry/opentelemetry-proto/blob/main/docs/specification.md#json-protobuf-encoding.
+
+type exportRequest struct {
+	ResourceSpans []resourceSpans `json:"resourceSpans"`
+}
+
+type resourceSpans struct {
+	Resource   resource     `json:"resource"`
+	ScopeSpans []scopeSpans `json:"scopeSpans"`
+}
+
+type resource struct {
+	Attributes []keyValue `json:"attributes"`
+}
+
+type scopeSpans struct {
+	Scope scope      `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type scope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string     `json:"traceId"`
+	SpanID            string     `json:"spanId"`
+	ParentSpanID      string     `json:"parentSpanId,omitempty"`
+	Name              string     `json:"name"`
+	Kind              SpanKind   `json:"kind"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+	Attributes        []keyValue `json:"attributes,omitempty"`
+	Status            status     `json:"status"`
+}
+
+type status struct {
+	Code    statusCode `json:"code,omitempty"`
+	Message string     `json:"message,omitempty"`
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+type anyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+func stringKeyValue(key string, val string) keyValue {
+	return keyValue{Key: key, Value: anyValue{StringValue: &val}}
+}
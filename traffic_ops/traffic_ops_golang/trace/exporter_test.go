@@ -0,0 +1,121 @@
+package trace
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewExporter(t *testing.T) {
+	urls := map[string]string{
+		"http://collector:4318":                "http://collector:4318/v1/traces",
+		"https://collector/otlp/":              "https://collector/otlp/v1/traces",
+		"http://collector:4318/otlp/v1/traces": "http://collector:4318/otlp/v1/traces",
+	}
+	for endpoint, expected := range urls {
+		e, err := newExporter(Options{Endpoint: endpoint})
+		if err != nil {
+			t.Errorf("expected endpoint '%s' to be valid, actual error: %v", endpoint, err)
+		} else if e.url != expected {
+			t.Errorf("expected endpoint '%s' to export to '%s', actual: '%s'", endpoint, expected, e.url)
+		}
+	}
+	for _, endpoint := range []string{"", "localhost:4318", "grpc://collector:4317", "http://"} {
+		if _, err := newExporter(Options{Endpoint: endpoint}); err == nil {
+			t.Errorf("expected endpoint '%s' to be invalid, actual: valid", endpoint)
+		}
+	}
+}
+
+func TestExporterPost(t *testing.T) {
+	path := ""
+	auth := ""
+	body := []byte{}
+	code := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		auth = r.Header.Get("Authorization")
+		body, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(code)
+	}))
+	defer srv.Close()
+
+	e, err := newExporter(Options{Endpoint: srv.URL, Headers: map[string]string{"Authorization": "Bearer secret"}, SampleRatio: 1, ServiceName: "traffic_ops_golang", ServiceVersion: "4.0.0"})
+	if err != nil {
+		t.Fatalf("creating exporter: %v", err)
+	}
+	exporter = e
+	defer func() { exporter = nil }()
+
+	ctx, root := StartSpan(context.Background(), "route 42", KindServer)
+	_, child := StartSpan(ctx, "SELECT", KindClient)
+	child.SetAttribute("db.statement", "SELECT 1")
+	child.SetAttribute("rows", 3)
+	child.End()
+	root.SetAttribute("http.status_code", 500)
+	root.SetError(errors.New("failed"))
+	root.End()
+
+	if err := e.post(exported(e)); err != nil {
+		t.Fatalf("expected export to succeed, actual error: %v", err)
+	}
+	if path != TracesPath || auth != "Bearer secret" {
+		t.Errorf("expected POST to %s with the configured headers, actual: POST to %s with Authorization '%s'", TracesPath, path, auth)
+	}
+
+	req := exportRequest{}
+	if err := json.Unmarshal(body, &req); err != nil {
+		t.Fatalf("expected an OTLP JSON request, actual error decoding: %v", err)
+	}
+	if len(req.ResourceSpans) != 1 || len(req.ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("expected one resource with one scope, actual: %s", body)
+	}
+	res := req.ResourceSpans[0]
+	if len(res.Resource.Attributes) < 2 || res.Resource.Attributes[0].Key != "service.name" || *res.Resource.Attributes[0].Value.StringValue != "traffic_ops_golang" || *res.Resource.Attributes[1].Value.StringValue != "4.0.0" {
+		t.Errorf("expected resource of service traffic_ops_golang version 4.0.0, actual: %s", body)
+	}
+	spans := res.ScopeSpans[0].Spans
+	if res.ScopeSpans[0].Scope.Name != ScopeName || len(spans) != 2 {
+		t.Fatalf("expected 2 spans in scope %s, actual: %s", ScopeName, body)
+	}
+	if spans[0].Name != "SELECT" || spans[0].Kind != KindClient || spans[0].ParentSpanID != root.SpanID.String() || spans[0].TraceID != root.TraceID.String() {
+		t.Errorf("expected client span SELECT child of the root span, actual: %+v", spans[0])
+	}
+	if len(spans[0].Attributes) != 2 || *spans[0].Attributes[1].Value.IntValue != "3" {
+		t.Errorf("expected integer attribute encoded as a string, actual: %s", body)
+	}
+	if spans[1].ParentSpanID != "" || spans[1].Status.Code != statusError || spans[1].Status.Message != "failed" {
+		t.Errorf("expected failed root span, actual: %+v", spans[1])
+	}
+	if spans[1].StartTimeUnixNano == "" || spans[1].StartTimeUnixNano > spans[1].EndTimeUnixNano {
+		t.Errorf("expected span to end after it started, actual: %s to %s", spans[1].StartTimeUnixNano, spans[1].EndTimeUnixNano)
+	}
+
+	code = http.StatusBadRequest
+	if err := e.post([]*Span{child}); err == nil {
+		t.Error("expected an error when the collector rejects the spans, actual: nil")
+	}
+}
@@ -0,0 +1,100 @@
+package trace
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// RequestContext returns the context of a request Traffic Ops received, whose spans are children
+// of the span of the client given in the request's TraceParentHeader, if it has a valid one.
+func RequestContext(r *http.Request) context.Context {
+	ctx := r.Context()
+	if exporter == nil {
+		return ctx
+	}
+	if sc, err := ParseTraceParent(r.Header.Get(TraceParentHeader)); err == nil {
+		ctx = ContextWithRemoteParent(ctx, sc)
+	}
+	return ctx
+}
+
+// Client returns a copy of the client which traces each request it sends as a child of the span of
+// ctx, and sends the request's span in its TraceParentHeader, so the service it's sent to can add
+// its own spans to the trace. If ctx has no span, the client is returned unchanged.
+//
+// A span of a request ends when the response headers are received, before the body is read.
+func Client(ctx context.Context, client *http.Client) *http.Client {
+	if FromContext(ctx) == nil {
+		return client
+	}
+	transport := client.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	traced := *client
+	traced.Transport = tracedTransport{ctx: ctx, transport: transport}
+	return &traced
+}
+
+type tracedTransport struct {
+	ctx       context.Context
+	transport http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper. It sends a copy of the request with the
+// TraceParentHeader, because a RoundTripper mustn't modify the request it's given.
+func (t tracedTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	ctx := r.Context()
+	if FromContext(ctx) == nil {
+		ctx = t.ctx
+	}
+	_, span := StartChildSpan(ctx, "HTTP "+r.Method, KindClient)
+	if span == nil {
+		return t.transport.RoundTrip(r)
+	}
+	defer span.End()
+
+	u := *r.URL
+	u.User = nil
+	span.SetAttribute("http.method", r.Method)
+	span.SetAttribute("http.url", u.String())
+	span.SetAttribute("net.peer.name", u.Hostname())
+
+	traced := *r
+	traced.Header = make(http.Header, len(r.Header)+1)
+	for name, vals := range r.Header {
+		traced.Header[name] = vals
+	}
+	traced.Header.Set(TraceParentHeader, span.Context().TraceParent())
+
+	resp, err := t.transport.RoundTrip(&traced)
+	if err != nil {
+		span.SetError(err)
+		return resp, err
+	}
+	span.SetAttribute("http.status_code", resp.StatusCode)
+	if resp.StatusCode >= http.StatusInternalServerError {
+		span.SetError(errors.New(resp.Status))
+	}
+	return resp, err
+}
@@ -0,0 +1,225 @@
+package trace
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+)
+
+// WrapDriver returns a driver which opens connections with the given driver, and traces their
+// queries. Queries are only traced as part of a request: those given a context with a span, or run
+// in a transaction begun with one.
+//
+// A span of a query ends when the query returns, before its rows are read.
+func WrapDriver(d driver.Driver) driver.Driver {
+	return tracedDriver{Driver: d}
+}
+
+type tracedDriver struct {
+	driver.Driver
+}
+
+// Open implements driver.Driver.
+func (d tracedDriver) Open(name string) (driver.Conn, error) {
+	c, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{Conn: c}, nil
+}
+
+// conn is a traced connection. The database/sql package never uses a connection concurrently, so
+// it needs no lock.
+type conn struct {
+	driver.Conn
+	// txCtx is the context the connection's current transaction was begun with, whose span is the
+	// parent of the spans of its queries, because the queries of a *sql.Tx are given an empty
+	// context unless their own is given.
+	txCtx context.Context
+}
+
+func (c *conn) startSpan(ctx context.Context, query string) *Span {
+	if FromContext(ctx) == nil && c.txCtx != nil {
+		ctx = c.txCtx
+	}
+	_, span := StartChildSpan(ctx, Operation(query), KindClient)
+	span.SetAttribute("db.system", "postgresql")
+	span.SetAttribute("db.statement", query)
+	return span
+}
+
+// Begin implements driver.Conn.
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+// BeginTx implements driver.ConnBeginTx.
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	var t driver.Tx
+	var err error
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		t, err = b.BeginTx(ctx, opts)
+	} else if opts != (driver.TxOptions{}) {
+		return nil, errors.New("driver doesn't support non-default transaction options")
+	} else {
+		t, err = c.Conn.Begin()
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.txCtx = ctx
+	return &tx{Tx: t, conn: c}, nil
+}
+
+// Prepare implements driver.Conn.
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+// PrepareContext implements driver.ConnPrepareContext.
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var s driver.Stmt
+	var err error
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		s, err = p.PrepareContext(ctx, query)
+	} else {
+		s, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &stmt{Stmt: s, conn: c, query: query}, nil
+}
+
+// QueryContext implements driver.QueryerContext.
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	span := c.startSpan(ctx, query)
+	rows, err := q.QueryContext(ctx, query, args)
+	span.SetError(err)
+	span.End()
+	return rows, err
+}
+
+// ExecContext implements driver.ExecerContext.
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	e, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	span := c.startSpan(ctx, query)
+	result, err := e.ExecContext(ctx, query, args)
+	span.SetError(err)
+	span.End()
+	return result, err
+}
+
+// Ping implements driver.Pinger.
+func (c *conn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// ResetSession implements driver.SessionResetter.
+func (c *conn) ResetSession(ctx context.Context) error {
+	c.txCtx = nil
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+// CheckNamedValue implements driver.NamedValueChecker.
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	if n, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return n.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+type tx struct {
+	driver.Tx
+	conn *conn
+}
+
+func (t *tx) end(name string, f func() error) error {
+	_, span := StartChildSpan(t.conn.txCtx, name, KindClient)
+	span.SetAttribute("db.system", "postgresql")
+	err := f()
+	span.SetError(err)
+	span.End()
+	t.conn.txCtx = nil
+	return err
+}
+
+// Commit implements driver.Tx.
+func (t *tx) Commit() error {
+	return t.end("COMMIT", t.Tx.Commit)
+}
+
+// Rollback implements driver.Tx.
+func (t *tx) Rollback() error {
+	return t.end("ROLLBACK", t.Tx.Rollback)
+}
+
+// stmt is a traced prepared statement. Its queries are traced as part of the transaction it was
+// prepared in, if any.
+type stmt struct {
+	driver.Stmt
+	conn  *conn
+	query string
+}
+
+// Exec implements driver.Stmt.
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	span := s.conn.startSpan(context.Background(), s.query)
+	result, err := s.Stmt.Exec(args)
+	span.SetError(err)
+	span.End()
+	return result, err
+}
+
+// Query implements driver.Stmt.
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	span := s.conn.startSpan(context.Background(), s.query)
+	rows, err := s.Stmt.Query(args)
+	span.SetError(err)
+	span.End()
+	return rows, err
+}
+
+// Operation returns the name of the span of a query: its first keyword, such as SELECT or INSERT,
+// which keeps the number of span names low, unlike the query itself, which is the span's
+// db.statement attribute.
+func Operation(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return "SQL"
+	}
+	return strings.ToUpper(strings.TrimLeft(fields[0], "("))
+}
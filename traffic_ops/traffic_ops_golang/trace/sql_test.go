@@ -0,0 +1,107 @@
+package trace
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestWrapDriver(t *testing.T) {
+	mockDB, mock, err := sqlmock.NewWithDSN("trace_test")
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	sql.Register("sqlmock-traced", WrapDriver(mockDB.Driver()))
+	db, err := sql.Open("sqlmock-traced", "trace_test")
+	if err != nil {
+		t.Fatalf("opening traced database: %v", err)
+	}
+
+	e, restore := testExporter(t, 1)
+	defer restore()
+
+	mock.ExpectQuery("SELECT name FROM cdn").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("cdn1"))
+	if _, err := db.Query("SELECT name FROM cdn"); err != nil {
+		t.Fatalf("querying: %v", err)
+	}
+	if spans := exported(e); len(spans) != 0 {
+		t.Errorf("expected queries outside of any request not to be traced, actual: %d spans", len(spans))
+	}
+
+	ctx, root := StartSpan(context.Background(), "route 42", KindServer)
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT name FROM cdn").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("cdn1"))
+	mock.ExpectExec("UPDATE cdn").WillReturnError(errors.New("permission denied"))
+	mock.ExpectCommit()
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("beginning transaction: %v", err)
+	}
+	rows, err := tx.Query("SELECT name FROM cdn")
+	if err != nil {
+		t.Fatalf("querying: %v", err)
+	}
+	rows.Close()
+	if _, err := tx.Exec("UPDATE cdn SET name = 'cdn2'"); err == nil {
+		t.Fatal("expected exec error, actual: nil")
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("committing: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	spans := exported(e)
+	if len(spans) != 3 {
+		t.Fatalf("expected spans of the query, exec and commit of the transaction, actual: %d spans", len(spans))
+	}
+	for i, name := range []string{"SELECT", "UPDATE", "COMMIT"} {
+		if spans[i].name != name || spans[i].kind != KindClient || spans[i].parentID != root.SpanID {
+			t.Errorf("expected client span %s child of the request's span, actual: %s child of %v", name, spans[i].name, spans[i].parentID)
+		}
+	}
+	if *spans[0].attrs[1].Value.StringValue != "SELECT name FROM cdn" {
+		t.Errorf("expected db.statement of the query, actual: %+v", spans[0].attrs)
+	}
+	if spans[0].status != statusUnset || spans[1].status != statusError || spans[1].message != "permission denied" {
+		t.Errorf("expected only the failed exec span to have an error, actual: %v, %v '%s'", spans[0].status, spans[1].status, spans[1].message)
+	}
+}
+
+func TestOperation(t *testing.T) {
+	queries := map[string]string{
+		"\nSELECT s.id\nFROM server AS s": "SELECT",
+		"insert into cdn (name)":          "INSERT",
+		"(SELECT 1) UNION (SELECT 2)":     "SELECT",
+		"  ":                              "SQL",
+	}
+	for query, expected := range queries {
+		if actual := Operation(query); actual != expected {
+			t.Errorf("expected operation of '%s' to be %s, actual: %s", query, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,317 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+// Package trace traces Traffic Ops requests with OpenTelemetry spans: one for each route, and one
+// for each database query, Traffic Monitor request, InfluxDB query and Riak command made while
+// handling it. Spans are exported to an OpenTelemetry collector with OTLP over HTTP, in JSON.
+//
+// Tracing is off unless Init is called. Until then, starting a span returns a nil *Span, whose
+// methods all do nothing, so code which traces doesn't need to check whether tracing is enabled.
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TraceParentHeader is the W3C Trace Context header which carries the trace and span a request is
+// part of, between services. See https://www.w3.org/TR/trace-context/.
+const TraceParentHeader = "traceparent"
+
+// SpanKind is the kind of operation a span represents, with the values of the OTLP SpanKind enum.
+type SpanKind int
+
+const (
+	KindInternal SpanKind = 1
+	KindServer   SpanKind = 2
+	KindClient   SpanKind = 3
+)
+
+// TraceID identifies a trace, which is all the spans of a request.
+type TraceID [16]byte
+
+// SpanID identifies a span within its trace.
+type SpanID [8]byte
+
+func (id TraceID) String() string { return hex.EncodeToString(id[:]) }
+func (id SpanID) String() string  { return hex.EncodeToString(id[:]) }
+
+// SpanContext is what identifies a span to another service: its trace, its own ID, and whether
+// the trace is being sampled.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// TraceParent returns the value of the TraceParentHeader which makes the span the parent of the
+// spans of the service a request is sent to.
+func (sc SpanContext) TraceParent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + flags
+}
+
+// ParseTraceParent parses the value of a TraceParentHeader. Versions after 00 are parsed as
+// version 00, as the specification requires.
+func ParseTraceParent(s string) (SpanContext, error) {
+	sc := SpanContext{}
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return sc, errors.New("malformed traceparent '" + s + "'")
+	}
+	if _, err := hex.DecodeString(parts[0]); err != nil || parts[0] == "ff" {
+		return sc, errors.New("invalid traceparent version in '" + s + "'")
+	}
+	if parts[0] == "00" && len(parts) != 4 {
+		return sc, errors.New("malformed traceparent '" + s + "'")
+	}
+	if _, err := hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil || sc.TraceID == (TraceID{}) {
+		return sc, errors.New("invalid traceparent trace ID in '" + s + "'")
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil || sc.SpanID == (SpanID{}) {
+		return sc, errors.New("invalid traceparent parent ID in '" + s + "'")
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return sc, errors.New("malformed traceparent flags in '" + s + "'")
+	}
+	sc.Sampled = flags[0]&1 == 1
+	return sc, nil
+}
+
+// Span is an operation in a trace. A nil *Span is valid, and all its methods do nothing; it's
+// what starting a span returns when tracing is disabled, or when there's no trace to add it to.
+type Span struct {
+	SpanContext
+	parentID SpanID
+	name     string
+	kind     SpanKind
+	start    time.Time
+	exporter *Exporter
+
+	m       sync.Mutex
+	end     time.Time
+	attrs   []keyValue
+	status  statusCode
+	message string
+	ended   bool
+}
+
+type statusCode int
+
+const (
+	statusUnset statusCode = 0
+	statusError statusCode = 2
+)
+
+// SetAttribute sets an attribute of the span. Values which are strings, bools, integers or
+// float64s keep their type, and others are stored as strings.
+func (s *Span) SetAttribute(key string, val interface{}) {
+	if s == nil {
+		return
+	}
+	kv := keyValue{Key: key}
+	switch v := val.(type) {
+	case string:
+		kv.Value.StringValue = &v
+	case bool:
+		kv.Value.BoolValue = &v
+	case int:
+		i := strconv.Itoa(v)
+		kv.Value.IntValue = &i
+	case int64:
+		i := strconv.FormatInt(v, 10)
+		kv.Value.IntValue = &i
+	case uint64:
+		i := strconv.FormatUint(v, 10)
+		kv.Value.IntValue = &i
+	case float64:
+		kv.Value.DoubleValue = &v
+	default:
+		str := fmt.Sprint(v)
+		kv.Value.StringValue = &str
+	}
+	s.m.Lock()
+	defer s.m.Unlock()
+	for i, attr := range s.attrs {
+		if attr.Key == key {
+			s.attrs[i] = kv
+			return
+		}
+	}
+	s.attrs = append(s.attrs, kv)
+}
+
+// SetError marks the span as failed with the given error. It does nothing if err is nil.
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.status = statusError
+	s.message = err.Error()
+}
+
+// End ends the span, and queues it to be exported if its trace is sampled. Ending a span more than
+// once does nothing.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.m.Lock()
+	if s.ended {
+		s.m.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = time.Now()
+	s.m.Unlock()
+	if s.Sampled {
+		s.exporter.export(s)
+	}
+}
+
+// Context returns the SpanContext of the span, or the zero SpanContext if s is nil.
+func (s *Span) Context() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.SpanContext
+}
+
+type contextKey int
+
+const (
+	spanKey contextKey = iota
+	remoteKey
+)
+
+// ContextWithSpan returns a copy of ctx with the span as the parent of the spans started with it.
+func ContextWithSpan(ctx context.Context, s *Span) context.Context {
+	return context.WithValue(ctx, spanKey, s)
+}
+
+// ContextWithRemoteParent returns a copy of ctx whose spans are children of the span of another
+// service, such as the span of a client given in a request's TraceParentHeader.
+func ContextWithRemoteParent(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, remoteKey, sc)
+}
+
+// FromContext returns the span of ctx, or nil if it has none.
+func FromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(spanKey).(*Span)
+	return s
+}
+
+// StartSpan starts a span, as a child of the span of ctx or of its remote parent, or else as the
+// root of a new trace. It returns a copy of ctx with the new span, which must be ended with End.
+//
+// If tracing is disabled, it returns ctx and a nil *Span.
+func StartSpan(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	e := exporter
+	if e == nil {
+		return ctx, nil
+	}
+	s := &Span{name: name, kind: kind, start: time.Now(), exporter: e}
+	if parent := FromContext(ctx); parent != nil {
+		s.TraceID = parent.TraceID
+		s.parentID = parent.SpanID
+		s.Sampled = parent.Sampled
+	} else if remote, ok := ctx.Value(remoteKey).(SpanContext); ok {
+		s.TraceID = remote.TraceID
+		s.parentID = remote.SpanID
+		s.Sampled = remote.Sampled
+	} else {
+		rand.Read(s.TraceID[:])
+		s.Sampled = e.sample(s.TraceID)
+	}
+	rand.Read(s.SpanID[:])
+	return ContextWithSpan(ctx, s), s
+}
+
+// StartChildSpan starts a span as a child of the span of ctx, like StartSpan, but only if ctx has
+// a span. Otherwise it returns ctx and a nil *Span, so operations outside of any request, such as
+// those of the server's own background jobs, aren't traced.
+func StartChildSpan(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	if FromContext(ctx) == nil {
+		return ctx, nil
+	}
+	return StartSpan(ctx, name, kind)
+}
+
+// txContexts is the context of the request each transaction is for, by *sql.Tx.
+var txContexts = sync.Map{}
+
+// BindTx associates a transaction with the context of the request it's for, so that operations
+// which are given the transaction, but not the context, can trace their spans as part of the
+// request. UnbindTx must be called when the transaction is finished.
+func BindTx(ctx context.Context, tx *sql.Tx) {
+	if exporter == nil || tx == nil {
+		return
+	}
+	txContexts.Store(tx, ctx)
+}
+
+// UnbindTx removes the association of a transaction with a context made by BindTx.
+func UnbindTx(tx *sql.Tx) {
+	if exporter == nil || tx == nil {
+		return
+	}
+	txContexts.Delete(tx)
+}
+
+// TxContext returns the context the transaction was bound to with BindTx, or else an empty
+// context, which has no span to be the parent of others.
+func TxContext(tx *sql.Tx) context.Context {
+	if tx != nil {
+		if ctx, ok := txContexts.Load(tx); ok {
+			return ctx.(context.Context)
+		}
+	}
+	return context.Background()
+}
+
+// sampleBound returns the upper bound of the trace IDs sampled with the given ratio, comparing the
+// last 63 bits of the ID as OpenTelemetry's TraceIDRatioBased sampler does.
+func sampleBound(ratio float64) uint64 {
+	if ratio >= 1 {
+		return 1 << 63
+	}
+	if ratio <= 0 {
+		return 0
+	}
+	return uint64(ratio * (1 << 63))
+}
+
+func sampled(id TraceID, bound uint64) bool {
+	return binary.BigEndian.Uint64(id[8:])>>1 < bound
+}
@@ -0,0 +1,238 @@
+package trace
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// testExporter enables tracing with an exporter whose spans are read with exported, rather than
+// sent to a collector, and returns the func which disables it again.
+func testExporter(t *testing.T, sampleRatio float64) (*Exporter, func()) {
+	e, err := newExporter(Options{Endpoint: "http://localhost:4318", SampleRatio: sampleRatio})
+	if err != nil {
+		t.Fatalf("creating exporter: %v", err)
+	}
+	exporter = e
+	return e, func() { exporter = nil }
+}
+
+// exported returns the spans the exporter has queued.
+func exported(e *Exporter) []*Span {
+	spans := []*Span{}
+	for {
+		select {
+		case s := <-e.queue:
+			spans = append(spans, s)
+		default:
+			return spans
+		}
+	}
+}
+
+func TestParseTraceParent(t *testing.T) {
+	sc, err := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if err != nil {
+		t.Fatalf("expected valid traceparent, actual error: %v", err)
+	}
+	if sc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || sc.SpanID.String() != "00f067aa0ba902b7" || !sc.Sampled {
+		t.Errorf("expected trace 4bf92f3577b34da6a3ce929d0e0e4736 span 00f067aa0ba902b7 sampled, actual: %+v", sc)
+	}
+	if tp := sc.TraceParent(); tp != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" {
+		t.Errorf("expected TraceParent to be the parsed traceparent, actual: %s", tp)
+	}
+
+	sc, err = ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	if err != nil || sc.Sampled {
+		t.Errorf("expected unsampled traceparent, actual: %+v error %v", sc, err)
+	}
+	if _, err := ParseTraceParent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-future"); err != nil {
+		t.Errorf("expected a later version with more fields to be parsed, actual error: %v", err)
+	}
+
+	invalid := []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473x-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-zz",
+	}
+	for _, tp := range invalid {
+		if _, err := ParseTraceParent(tp); err == nil {
+			t.Errorf("expected traceparent '%s' to be invalid, actual: valid", tp)
+		}
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	ctx, span := StartSpan(context.Background(), "disabled", KindServer)
+	if span != nil {
+		t.Fatalf("expected no span when tracing is disabled, actual: %+v", span)
+	}
+	if FromContext(ctx) != nil {
+		t.Error("expected context without a span when tracing is disabled, actual: with a span")
+	}
+	// the methods of a nil span must do nothing, rather than panic
+	span.SetAttribute("key", "val")
+	span.SetError(errors.New("error"))
+	span.End()
+	if sc := span.Context(); sc != (SpanContext{}) {
+		t.Errorf("expected the zero SpanContext of a nil span, actual: %+v", sc)
+	}
+
+	tx := &sql.Tx{}
+	BindTx(ContextWithSpan(context.Background(), &Span{}), tx)
+	if FromContext(TxContext(tx)) != nil {
+		t.Error("expected BindTx to do nothing when tracing is disabled, actual: transaction bound")
+	}
+	client := &http.Client{}
+	if Client(ctx, client) != client {
+		t.Error("expected Client to return the client unchanged when tracing is disabled")
+	}
+}
+
+func TestStartSpan(t *testing.T) {
+	e, restore := testExporter(t, 1)
+	defer restore()
+
+	if _, span := StartChildSpan(context.Background(), "orphan", KindInternal); span != nil {
+		t.Errorf("expected StartChildSpan without a parent to start no span, actual: %+v", span)
+	}
+
+	ctx, root := StartSpan(context.Background(), "root", KindServer)
+	if root == nil || !root.Sampled || root.parentID != (SpanID{}) {
+		t.Fatalf("expected sampled root span without a parent, actual: %+v", root)
+	}
+	_, child := StartChildSpan(ctx, "child", KindClient)
+	if child == nil {
+		t.Fatal("expected child span, actual: nil")
+	}
+	if child.TraceID != root.TraceID || child.parentID != root.SpanID || child.SpanID == root.SpanID {
+		t.Errorf("expected child of root span %v in trace %v, actual: parent %v in trace %v", root.SpanID, root.TraceID, child.parentID, child.TraceID)
+	}
+
+	child.SetAttribute("db.statement", "SELECT 1")
+	child.SetAttribute("http.status_code", 200)
+	child.SetAttribute("http.status_code", 500)
+	child.SetError(errors.New("failed"))
+	child.End()
+	child.End()
+	spans := exported(e)
+	if len(spans) != 1 || spans[0] != child {
+		t.Fatalf("expected the ended child span to be exported once, actual: %d spans", len(spans))
+	}
+	if len(child.attrs) != 2 || *child.attrs[1].Value.IntValue != "500" {
+		t.Errorf("expected setting an attribute again to replace it, actual: %+v", child.attrs)
+	}
+	if child.status != statusError || child.message != "failed" {
+		t.Errorf("expected error status 'failed', actual: %v '%s'", child.status, child.message)
+	}
+
+	remote := SpanContext{TraceID: TraceID{1}, SpanID: SpanID{2}, Sampled: true}
+	_, span := StartSpan(ContextWithRemoteParent(context.Background(), remote), "remote child", KindServer)
+	if span.TraceID != remote.TraceID || span.parentID != remote.SpanID || !span.Sampled {
+		t.Errorf("expected sampled child of remote span %v in trace %v, actual: %+v", remote.SpanID, remote.TraceID, span)
+	}
+}
+
+func TestSampling(t *testing.T) {
+	e, restore := testExporter(t, 0)
+	defer restore()
+
+	ctx, root := StartSpan(context.Background(), "root", KindServer)
+	_, child := StartChildSpan(ctx, "child", KindClient)
+	if root.Sampled || child.Sampled {
+		t.Errorf("expected a sample ratio of 0 to sample no traces, actual: root sampled %t child sampled %t", root.Sampled, child.Sampled)
+	}
+	child.End()
+	root.End()
+	if spans := exported(e); len(spans) != 0 {
+		t.Errorf("expected unsampled spans not to be exported, actual: %d spans", len(spans))
+	}
+
+	remote := SpanContext{TraceID: TraceID{1}, SpanID: SpanID{2}, Sampled: true}
+	if _, span := StartSpan(ContextWithRemoteParent(context.Background(), remote), "remote child", KindServer); !span.Sampled {
+		t.Error("expected a trace sampled by the client to be sampled, actual: unsampled")
+	}
+
+	half := sampleBound(0.5)
+	if !sampled(TraceID{8: 0x7f, 15: 0xff}, half) || sampled(TraceID{8: 0x80}, half) {
+		t.Error("expected a sample ratio of 0.5 to sample the trace IDs whose last 63 bits are in the lower half")
+	}
+}
+
+func TestTxContext(t *testing.T) {
+	_, restore := testExporter(t, 1)
+	defer restore()
+
+	ctx, span := StartSpan(context.Background(), "root", KindServer)
+	tx := &sql.Tx{}
+	BindTx(ctx, tx)
+	if FromContext(TxContext(tx)) != span {
+		t.Error("expected the context of a bound transaction to have the span it was bound with")
+	}
+	UnbindTx(tx)
+	if FromContext(TxContext(tx)) != nil {
+		t.Error("expected the context of an unbound transaction to have no span")
+	}
+}
+
+func TestClient(t *testing.T) {
+	e, restore := testExporter(t, 1)
+	defer restore()
+
+	traceParent := ""
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceParent = r.Header.Get(TraceParentHeader)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	ctx, root := StartSpan(context.Background(), "root", KindServer)
+	resp, err := Client(ctx, &http.Client{}).Get(srv.URL + "/publish/CrStates")
+	if err != nil {
+		t.Fatalf("expected request to succeed, actual error: %v", err)
+	}
+	resp.Body.Close()
+
+	spans := exported(e)
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span of the request, actual: %d", len(spans))
+	}
+	span := spans[0]
+	if span.name != "HTTP GET" || span.kind != KindClient || span.parentID != root.SpanID {
+		t.Errorf("expected client span 'HTTP GET' child of the root span, actual: %+v", span)
+	}
+	if traceParent != span.Context().TraceParent() {
+		t.Errorf("expected request traceparent '%s', actual: '%s'", span.Context().TraceParent(), traceParent)
+	}
+	if span.status != statusError {
+		t.Errorf("expected a 503 response to be an error, actual status: %v", span.status)
+	}
+}
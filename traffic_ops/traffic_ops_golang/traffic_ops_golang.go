@@ -21,6 +21,7 @@ package main
 
 import (
 	"crypto/tls"
+	"database/sql"
 	"flag"
 	"fmt"
 	"net/http"
@@ -37,17 +38,23 @@ import (
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/about"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/config"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/event"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/invalidationjobs"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/plugin"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/routing"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/trace"
 
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"golang.org/x/sys/unix"
 )
 
 // set the version at build time: `go build -X "main.version=..."`
 var version = "development"
 
+// tracedDriverName is the name of the PostgreSQL driver which traces queries, used when tracing is enabled.
+const tracedDriverName = "postgres-traced"
+
 func init() {
 	about.SetAbout(version)
 }
@@ -129,11 +136,32 @@ func main() {
 		sslStr = "disable"
 	}
 
-	db, err := sqlx.Open("postgres", fmt.Sprintf("postgres://%s:%s@%s/%s?sslmode=%s&fallback_application_name=trafficops", cfg.DB.User, cfg.DB.Password, cfg.DB.Hostname, cfg.DB.DBName, sslStr))
+	driverName := "postgres"
+	if cfg.Tracing != nil && cfg.Tracing.Enabled {
+		err := trace.Init(trace.Options{
+			Endpoint:       cfg.Tracing.Endpoint,
+			Headers:        cfg.Tracing.Headers,
+			SampleRatio:    *cfg.Tracing.SampleRatio,
+			Timeout:        time.Duration(cfg.Tracing.TimeoutSeconds) * time.Second,
+			Insecure:       cfg.Tracing.Insecure,
+			ServiceName:    "traffic_ops_golang",
+			ServiceVersion: version,
+		})
+		if err != nil {
+			log.Errorf("initializing tracing: %v\n", err)
+			os.Exit(1)
+		}
+		log.Infof("exporting traces to %s with a sample ratio of %v\n", cfg.Tracing.Endpoint, *cfg.Tracing.SampleRatio)
+		driverName = tracedDriverName
+		sql.Register(driverName, trace.WrapDriver(&pq.Driver{}))
+	}
+
+	sqlDB, err := sql.Open(driverName, fmt.Sprintf("postgres://%s:%s@%s/%s?sslmode=%s&fallback_application_name=trafficops", cfg.DB.User, cfg.DB.Password, cfg.DB.Hostname, cfg.DB.DBName, sslStr))
 	if err != nil {
 		log.Errorf("opening database: %v\n", err)
 		os.Exit(1)
 	}
+	db := sqlx.NewDb(sqlDB, "postgres")
 	defer db.Close()
 
 	db.SetMaxOpenConns(cfg.MaxDBConnections)
@@ -164,6 +192,14 @@ func main() {
 
 	plugins.OnStartup(plugin.StartupData{Data: plugin.Data{SharedCfg: cfg.PluginSharedConfig, AppCfg: cfg}})
 
+	if cfg.InvalidationJobScheduleIntervalSeconds > 0 {
+		go invalidationjobs.RunScheduler(db, time.Duration(cfg.InvalidationJobScheduleIntervalSeconds)*time.Second)
+	}
+	go event.RunPruner(db, time.Duration(cfg.EventRetentionHours)*time.Hour)
+	if cfg.EventWebhookMaxAttempts > 0 {
+		go event.RunDispatcher(db, cfg.EventWebhookMaxAttempts)
+	}
+
 	log.Infof("Listening on " + cfg.Port)
 
 	server := &http.Server{
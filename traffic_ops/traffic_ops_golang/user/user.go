@@ -67,6 +67,14 @@ func (user TOUser) GetType() string {
 	return "user"
 }
 
+// EventData implements api.HasEventData, leaving out the user's passwords.
+func (user *TOUser) EventData() interface{} {
+	u := user.User
+	u.LocalPassword = nil
+	u.ConfirmLocalPassword = nil
+	return u
+}
+
 func (user *TOUser) SetKeys(keys map[string]interface{}) {
 	i, _ := keys["id"].(int) // non-panicking type assertion
 	user.ID = &i
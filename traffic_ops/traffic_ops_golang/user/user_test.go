@@ -0,0 +1,81 @@
+package user
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+// eventData is a sqlmock.Argument which records the data of an inserted Event.
+type eventData struct {
+	data string
+}
+
+func (e *eventData) Match(v driver.Value) bool {
+	s, ok := v.(string)
+	e.data = s
+	return ok
+}
+
+func TestUserCreateEventHasNoPasswords(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	mock.ExpectBegin()
+	data := &eventData{}
+	mock.ExpectExec("INSERT INTO event").WithArgs("user.created", "admin", data).WillReturnResult(sqlmock.NewResult(1, 1))
+	tx, err := mockDB.Begin()
+	if err != nil {
+		t.Fatalf("beginning transaction: %v", err)
+	}
+
+	user := &TOUser{User: tc.User{
+		Username:             util.StrPtr("alice"),
+		LocalPassword:        util.StrPtr("hunter22hunter22"),
+		ConfirmLocalPassword: util.StrPtr("hunter22hunter22"),
+	}}
+	user.ID = util.IntPtr(1)
+	if err := api.CreateObjectEvent(tx, tc.EventActionCreated, user, &auth.CurrentUser{UserName: "admin"}); err != nil {
+		t.Fatalf("creating event: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expected an event to be inserted, actual: %v", err)
+	}
+	if !strings.Contains(data.data, `"username":"alice"`) {
+		t.Errorf("expected event data to contain the user, actual: %s", data.data)
+	}
+	for _, field := range []string{"localPasswd", "confirmLocalPasswd", "hunter22"} {
+		if strings.Contains(data.data, field) {
+			t.Errorf("expected event data without %s, actual: %s", field, data.data)
+		}
+	}
+}
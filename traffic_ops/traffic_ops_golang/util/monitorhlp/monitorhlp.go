@@ -32,7 +32,9 @@ import (
 
 	"github.com/apache/trafficcontrol/lib/go-tc"
 	tmcache "github.com/apache/trafficcontrol/traffic_monitor/cache"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/trace"
 )
 
 const MonitorProxyParameter = "tm.traffic_mon_fwd_proxy"
@@ -40,7 +42,8 @@ const MonitorRequestTimeout = time.Second * 10
 const MonitorOnlineStatus = "ONLINE"
 
 // GetClient returns the http.Client for making requests to the Traffic Monitor. This should always be used, rather than creating a default http.Client, to ensure any monitor forward proxy parameter is used correctly.
-func GetClient(tx *sql.Tx) (*http.Client, error) {
+// The client sends the given correlation ID of the Traffic Ops request it's used for, if it isn't empty, and traces its requests as part of the request the transaction is for.
+func GetClient(tx *sql.Tx, correlationID string) (*http.Client, error) {
 	monitorForwardProxy, monitorForwardProxyExists, err := dbhelpers.GetGlobalParam(tx, MonitorProxyParameter)
 	if err != nil {
 		return nil, errors.New("getting global monitor proxy parameter: " + err.Error())
@@ -58,7 +61,7 @@ func GetClient(tx *sql.Tx) (*http.Client, error) {
 		clientTransport.TLSNextProto = make(map[string]func(authority string, c *tls.Conn) http.RoundTripper)
 		client = &http.Client{Timeout: MonitorRequestTimeout, Transport: clientTransport}
 	}
-	return client, nil
+	return api.CorrelatedClient(trace.Client(trace.TxContext(tx), client), correlationID), nil
 }
 
 // GetURLs returns an FQDN, including port, of an online monitor for each CDN. If a CDN has no online monitors, that CDN will not have an entry in the map. If a CDN has multiple online monitors, an arbitrary one will be returned.